@@ -2,7 +2,9 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -91,6 +93,13 @@ func (h *Handler) Login(c *gin.Context) {
 	userAgent := c.GetHeader("User-Agent")
 
 	tokens, user, err := h.authService.Login(c.Request.Context(), req, deviceInfo, ipAddress, userAgent)
+	var locked *auth.AccountLockedError
+	if errors.As(err, &locked) {
+		h.logger.Warn("Login locked out", zap.String("email", req.Email), zap.Duration("retry_after", locked.RetryAfter))
+		c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds()+0.5)))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.logger.Info("Login failed", zap.String("email", req.Email), zap.Error(err))
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
@@ -185,8 +194,19 @@ func (h *Handler) VerifyEmail(c *gin.Context) {
 	}
 
 	err := h.authService.VerifyEmail(c.Request.Context(), req.Token)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	switch {
+	case errors.Is(err, auth.ErrVerificationTokenExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error(), "code": "token_expired"})
+		return
+	case errors.Is(err, auth.ErrVerificationTokenUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "token_used"})
+		return
+	case errors.Is(err, auth.ErrInvalidVerificationToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "token_invalid"})
+		return
+	case err != nil:
+		h.logger.Error("Email verification failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify email"})
 		return
 	}
 
@@ -2,22 +2,25 @@
 package bookings
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
 	"github.com/BillyRonksGlobal/vendorplatform/internal/booking"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/middleware"
 )
 
 // Handler handles booking HTTP requests
 type Handler struct {
 	bookingService *booking.Service
 	logger         *zap.Logger
+	auth           gin.HandlerFunc
 }
 
 // NewHandler creates a new booking handler
@@ -28,6 +31,13 @@ func NewHandler(bookingService *booking.Service, logger *zap.Logger) *Handler {
 	}
 }
 
+// SetAuthMiddleware sets the middleware that authenticates callers of
+// protected routes; it must be set before RegisterRoutes, and without it
+// those routes reject every request
+func (h *Handler) SetAuthMiddleware(mw gin.HandlerFunc) {
+	h.auth = mw
+}
+
 // RegisterRoutes registers booking routes
 func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	bookings := router.Group("/bookings")
@@ -38,7 +48,7 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 		bookings.GET("/code/:code", h.GetBookingByCode)
 		bookings.PUT("/:id", h.UpdateBooking)
 		bookings.PUT("/:id/status", h.UpdateBookingStatus)
-		bookings.PUT("/:id/cancel", h.CancelBooking)
+		bookings.PUT("/:id/cancel", middleware.RequireAuth(h.auth), h.CancelBooking)
 		bookings.PUT("/:id/payment", h.UpdatePaymentStatus)
 		bookings.POST("/:id/confirm", h.ConfirmBooking)
 		bookings.POST("/:id/start", h.StartBooking)
@@ -52,6 +62,7 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 type CreateBookingRequest struct {
 	ServiceID       string  `json:"service_id" binding:"required"`
 	ProjectID       *string `json:"project_id,omitempty"`
+	LifeEventID     *string `json:"life_event_id,omitempty"`
 	ScheduledDate   string  `json:"scheduled_date" binding:"required"`
 	ScheduledStart  *string `json:"scheduled_start_time,omitempty"`
 	ScheduledEnd    *string `json:"scheduled_end_time,omitempty"`
@@ -154,6 +165,15 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 		}
 	}
 
+	if req.LifeEventID != nil {
+		lifeEventID, err := uuid.Parse(*req.LifeEventID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid life_event_id"})
+			return
+		}
+		serviceReq.LifeEventID = &lifeEventID
+	}
+
 	if req.AddressID != nil {
 		addressID, err := uuid.Parse(*req.AddressID)
 		if err == nil {
@@ -177,6 +197,10 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 
 	// Create booking
 	bookingResult, err := h.bookingService.CreateBooking(c.Request.Context(), serviceReq)
+	if errors.Is(err, booking.ErrLifeEventNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "life event not found"})
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to create booking", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create booking"})
@@ -442,12 +466,27 @@ func (h *Handler) CancelBooking(c *gin.Context) {
 		return
 	}
 
-	err = h.bookingService.CancelBooking(c.Request.Context(), id, req.Reason)
+	userID, err := auth.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	role, _ := auth.GetRoleFromContext(c)
+	canceller := booking.Canceller{
+		UserID: userID,
+		Admin:  role == auth.RoleAdmin || role == auth.RoleSuperAdmin,
+	}
+
+	cancelled, err := h.bookingService.Cancel(c.Request.Context(), id, canceller, req.Reason)
 	if err != nil {
 		if err == booking.ErrBookingNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
 			return
 		}
+		if err == booking.ErrUnauthorized {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only the booking's customer, its vendor or an admin can cancel it"})
+			return
+		}
 		if err == booking.ErrBookingNotCancellable {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "booking cannot be cancelled"})
 			return
@@ -457,9 +496,14 @@ func (h *Handler) CancelBooking(c *gin.Context) {
 		return
 	}
 
+	message := "booking cancelled successfully"
+	if cancelled.RefundPending {
+		message = "booking cancelled; the refund is pending and will be retried"
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "booking cancelled successfully",
+		"success":      true,
+		"message":      message,
+		"cancellation": cancelled,
 	})
 }
 
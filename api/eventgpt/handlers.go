@@ -4,59 +4,89 @@
 package eventgpt
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
 	"github.com/BillyRonksGlobal/vendorplatform/internal/eventgpt"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/middleware"
 )
 
 // Handler handles EventGPT HTTP requests
 type Handler struct {
-	service *eventgpt.Service
-	logger  *zap.Logger
+	service      *eventgpt.Service
+	chat         *EventGPTAPI
+	savedVendors SavedVendorStore
+	handoffs     HandoffStore
+	optionalAuth gin.HandlerFunc
+	logger       *zap.Logger
 }
 
-// NewHandler creates a new EventGPT handler
-func NewHandler(service *eventgpt.Service, logger *zap.Logger) *Handler {
+// NewHandler creates a new EventGPT handler. With chat set, messages are
+// answered by its DialogManager; otherwise by the service.
+func NewHandler(service *eventgpt.Service, chat *EventGPTAPI, savedVendors SavedVendorStore, handoffs HandoffStore, logger *zap.Logger) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:      service,
+		chat:         chat,
+		savedVendors: savedVendors,
+		handoffs:     handoffs,
+		logger:       logger,
 	}
 }
 
+// SetOptionalAuthMiddleware sets the middleware that identifies logged-in
+// users on the conversation routes, which anonymous users may also use
+func (h *Handler) SetOptionalAuthMiddleware(mw gin.HandlerFunc) {
+	h.optionalAuth = mw
+}
+
 // RegisterRoutes registers EventGPT routes
 func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	eventgptGroup := router.Group("/eventgpt")
 	{
-		eventgptGroup.POST("/conversations", h.StartConversation)
-		eventgptGroup.POST("/conversations/:id/messages", h.SendMessage)
-		eventgptGroup.GET("/conversations/:id", h.GetConversation)
-		eventgptGroup.DELETE("/conversations/:id", h.EndConversation)
+		optionalAuth := middleware.OptionalAuth(h.optionalAuth)
+		eventgptGroup.POST("/conversations", optionalAuth, h.StartConversation)
+		eventgptGroup.POST("/conversations/:id/messages", middleware.UUIDParams("id"), optionalAuth, h.SendMessage)
+		eventgptGroup.GET("/conversations/:id", middleware.UUIDParams("id"), optionalAuth, h.GetConversation)
+		eventgptGroup.DELETE("/conversations/:id", middleware.UUIDParams("id"), optionalAuth, h.EndConversation)
+		eventgptGroup.GET("/users/:userId/saved-vendors", middleware.UUIDParams("userId"), h.GetSavedVendors)
+		eventgptGroup.GET("/handoffs", h.GetHandoffs)
 	}
 }
 
-// StartConversation creates a new conversation
+// StartConversation creates a new conversation, opening with the welcome
+// flow for the page or campaign the user came from. The conversation
+// belongs to the authenticated user, or to the anonymous user.
 // POST /api/v1/eventgpt/conversations
 func (h *Handler) StartConversation(c *gin.Context) {
 	var req struct {
-		UserID string `json:"user_id" binding:"required"`
+		Source  string                 `json:"source"`
+		Context *eventgpt.EntryContext `json:"context"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
-	userID, err := uuid.Parse(req.UserID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
-		return
+	userID := callerID(c)
+
+	entry := req.Context
+	if entry == nil {
+		entry = &eventgpt.EntryContext{}
+	}
+	if req.Source != "" {
+		entry.Source = req.Source
+	}
+	if entry.Source == "" {
+		entry.Source = eventgpt.EntryDirect
 	}
 
-	conversation, err := h.service.StartConversation(c.Request.Context(), userID)
+	conversation, err := h.service.StartConversation(c.Request.Context(), userID, entry)
 	if err != nil {
 		h.logger.Error("Failed to start conversation", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start conversation"})
@@ -67,6 +97,7 @@ func (h *Handler) StartConversation(c *gin.Context) {
 	response := gin.H{
 		"conversation_id": conversation.ID.String(),
 		"state":          conversation.State,
+		"slots":          conversation.Slots,
 		"message": gin.H{
 			"role":      conversation.Messages[0].Role,
 			"content":   conversation.Messages[0].Content,
@@ -87,12 +118,7 @@ func (h *Handler) StartConversation(c *gin.Context) {
 // SendMessage processes a user message
 // POST /api/v1/eventgpt/conversations/:id/messages
 func (h *Handler) SendMessage(c *gin.Context) {
-	conversationIDStr := c.Param("id")
-	conversationID, err := uuid.Parse(conversationIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
-		return
-	}
+	conversationID := middleware.ParamUUID(c, "id")
 
 	var req struct {
 		Message string `json:"message" binding:"required"`
@@ -103,6 +129,18 @@ func (h *Handler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	// A conversation the welcome flow started is the caller's only if they
+	// started it; chat-only conversations are checked by Chat
+	if started, err := h.service.GetConversation(c.Request.Context(), conversationID); err == nil && started.UserID != callerID(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	if h.chat != nil {
+		h.chatMessage(c, conversationID, req.Message)
+		return
+	}
+
 	// Process message through service
 	responseMsg, err := h.service.ProcessMessage(c.Request.Context(), conversationID, req.Message)
 	if err != nil {
@@ -152,16 +190,48 @@ func (h *Handler) SendMessage(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// GetConversation retrieves conversation history
-// GET /api/v1/eventgpt/conversations/:id
-func (h *Handler) GetConversation(c *gin.Context) {
-	conversationIDStr := c.Param("id")
-	conversationID, err := uuid.Parse(conversationIDStr)
+// chatMessage answers a message with the DialogManager, returning the full
+// ChatResponse with any cards and quick replies. Requests without an
+// authenticated user are handled as the anonymous user.
+func (h *Handler) chatMessage(c *gin.Context, conversationID uuid.UUID, message string) {
+	response, err := h.chat.Chat(c.Request.Context(), callerID(c), ChatRequest{
+		ConversationID: &conversationID,
+		Message:        message,
+		Channel:        ChannelWeb,
+	})
+	if errors.Is(err, ErrConversationForbidden) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		h.logger.Error("Failed to process message",
+			zap.Error(err),
+			zap.String("conversation_id", conversationID.String()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process message"})
 		return
 	}
 
+	c.JSON(http.StatusOK, response)
+}
+
+// AnonymousUserID stands in for the user when a message arrives without one
+var AnonymousUserID = uuid.Nil
+
+// callerID returns the authenticated user, or AnonymousUserID
+func callerID(c *gin.Context) uuid.UUID {
+	userID, err := auth.GetUserFromContext(c)
+	if err != nil {
+		return AnonymousUserID
+	}
+	return userID
+}
+
+// GetConversation retrieves conversation history
+// GET /api/v1/eventgpt/conversations/:id
+func (h *Handler) GetConversation(c *gin.Context) {
+	conversationID := middleware.ParamUUID(c, "id")
+
 	conversation, err := h.service.GetConversation(c.Request.Context(), conversationID)
 	if err != nil {
 		h.logger.Error("Failed to get conversation",
@@ -171,6 +241,10 @@ func (h *Handler) GetConversation(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
 		return
 	}
+	if conversation.UserID != callerID(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
 
 	// Format messages
 	messages := make([]gin.H, len(conversation.Messages))
@@ -211,10 +285,11 @@ func (h *Handler) GetConversation(c *gin.Context) {
 // EndConversation marks a conversation as ended
 // DELETE /api/v1/eventgpt/conversations/:id
 func (h *Handler) EndConversation(c *gin.Context) {
-	conversationIDStr := c.Param("id")
-	conversationID, err := uuid.Parse(conversationIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+	conversationID := middleware.ParamUUID(c, "id")
+
+	conversation, err := h.service.GetConversation(c.Request.Context(), conversationID)
+	if err != nil || conversation.UserID != callerID(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
 		return
 	}
 
@@ -233,3 +308,55 @@ func (h *Handler) EndConversation(c *gin.Context) {
 		"conversation_id": conversationID.String(),
 	})
 }
+
+// GetSavedVendors lists the vendors a user saved from chat, newest first
+// GET /api/v1/eventgpt/users/:userId/saved-vendors
+func (h *Handler) GetSavedVendors(c *gin.Context) {
+	userID := middleware.ParamUUID(c, "userId")
+
+	saved, err := h.savedVendors.SavedVendors(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list saved vendors",
+			zap.Error(err),
+			zap.String("user_id", userID.String()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list saved vendors"})
+		return
+	}
+	if saved == nil {
+		saved = []SavedVendor{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"saved_vendors": saved,
+		"count":         len(saved),
+	})
+}
+
+// GetHandoffs lists conversations queued for a support agent, oldest first
+// GET /api/v1/eventgpt/handoffs?status=open
+func (h *Handler) GetHandoffs(c *gin.Context) {
+	status, err := ParseHandoffStatus(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open, assigned or resolved"})
+		return
+	}
+
+	handoffs, err := h.handoffs.Handoffs(c.Request.Context(), status)
+	if err != nil {
+		h.logger.Error("Failed to list handoffs",
+			zap.Error(err),
+			zap.String("status", string(status)),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list handoffs"})
+		return
+	}
+	if handoffs == nil {
+		handoffs = []Handoff{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"handoffs": handoffs,
+		"count":    len(handoffs),
+	})
+}
@@ -9,14 +9,27 @@ package eventgpt
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/availability"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/tracing"
 )
 
 /*
@@ -83,10 +96,16 @@ type Conversation struct {
 	// History
 	Messages        []Message              `json:"messages"`
 	TurnCount       int                    `json:"turn_count"`
+	IntentHistory   []string               `json:"intent_history,omitempty"`
+	SlotHistory     []SlotChange           `json:"slot_history,omitempty"`
 	
 	// Memory
 	ShortTermMemory map[string]interface{} `json:"short_term_memory"`
 	
+	// Feature flags resolved for this conversation, pinned on first use so
+	// behaviour doesn't change mid-conversation
+	Features        map[DialogFeature]bool `json:"features,omitempty"`
+	
 	// Metadata
 	Language        string                 `json:"language"`
 	Channel         Channel                `json:"channel"`
@@ -97,6 +116,15 @@ type Conversation struct {
 	EndedAt         *time.Time             `json:"ended_at,omitempty"`
 }
 
+// SlotChange records a slot being corrected or reset mid-conversation
+type SlotChange struct {
+	Slot     string      `json:"slot"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+	Reason   string      `json:"reason"` // corrected, reset
+	Turn     int         `json:"turn"`
+}
+
 type SessionType string
 const (
 	SessionNewEvent       SessionType = "new_event"
@@ -219,6 +247,9 @@ type Intent struct {
 	Name       string  `json:"name"`
 	Confidence float64 `json:"confidence"`
 	Slots      map[string]SlotValue `json:"slots,omitempty"`
+	
+	// Next-best candidates from the classifier, highest confidence first
+	Alternatives []Intent `json:"alternatives,omitempty"`
 }
 
 // Entity represents extracted entities
@@ -229,6 +260,9 @@ type Entity struct {
 	StartPos   int         `json:"start_pos"`
 	EndPos     int         `json:"end_pos"`
 	Confidence float64     `json:"confidence"`
+	
+	// Set on date entities resolved to a time.Time value
+	DateFlexibility DateFlexibility `json:"date_flexibility,omitempty"`
 }
 
 // SlotValue represents a filled conversation slot
@@ -254,8 +288,21 @@ type IntentClassifier struct {
 	// Model configuration
 	modelEndpoint string
 	fallbackRules []IntentRule
+	
+	// Below this top-intent confidence the user is asked to clarify;
+	// zero uses DefaultMinIntentConfidence
+	minConfidence float64
 }
 
+// IntentClarify is the intent returned in place of a guess when the best
+// candidate is under the minimum confidence. Its Alternatives are the
+// candidates to offer, best first.
+const IntentClarify = "clarify"
+
+// DefaultMinIntentConfidence is the top-intent confidence below which the
+// user is asked what they meant
+const DefaultMinIntentConfidence = 0.6
+
 // IntentRule for rule-based fallback
 type IntentRule struct {
 	IntentName string
@@ -383,43 +430,138 @@ var EventPlanningIntents = []IntentRule{
 	},
 }
 
-func (c *IntentClassifier) ClassifyIntent(ctx context.Context, text string, conversationContext *ConversationContext) (*Intent, error) {
-	// First try rule-based classification for common patterns
-	for _, rule := range c.fallbackRules {
-		for _, pattern := range rule.Patterns {
-			matched, _ := regexp.MatchString(pattern, text)
-			if matched {
-				return &Intent{
-					Name:       rule.IntentName,
-					Confidence: 0.9,
-				}, nil
+// NewIntentClassifier creates a rule-based classifier; nil rules use
+// EventPlanningIntents
+func NewIntentClassifier(rules []IntentRule) *IntentClassifier {
+	if rules == nil {
+		rules = EventPlanningIntents
+	}
+	return &IntentClassifier{fallbackRules: rules}
+}
+
+// ClassifyIntent scores every intent rule against the message and returns
+// the candidates ranked by confidence, best first. The list always ends in
+// a general-inquiry fallback so it is never empty.
+func (c *IntentClassifier) ClassifyIntent(ctx context.Context, text string, conversationContext *ConversationContext) ([]Intent, error) {
+	// A card's Save button is unambiguous
+	if _, ok := ParseSaveVendorPayload(text); ok {
+		return []Intent{{Name: "save_vendor", Confidence: 1.0}}, nil
+	}
+	
+	if strings.TrimSpace(text) == HandoffRequestPayload {
+		return []Intent{{Name: IntentHandoff, Confidence: 1.0}}, nil
+	}
+	
+	if strings.TrimSpace(text) == BookingConfirmPayload {
+		return []Intent{{Name: IntentConfirmBooking, Confidence: 1.0}}, nil
+	}
+	
+	// Quick reply payloads from a clarifying question name the intent directly
+	if name := strings.TrimPrefix(strings.TrimSpace(text), "intent:"); name != text {
+		for _, rule := range c.fallbackRules {
+			if rule.IntentName == name {
+				return []Intent{{Name: name, Confidence: 1.0}}, nil
 			}
 		}
 	}
 	
-	// Keyword-based fallback
 	textLower := strings.ToLower(text)
+	var ranked []Intent
+	hasFallback := false
 	for _, rule := range c.fallbackRules {
-		matchCount := 0
-		for _, keyword := range rule.Keywords {
-			if strings.Contains(textLower, keyword) {
-				matchCount++
+		confidence := 0.0
+		
+		// Pattern match is the strongest rule-based signal
+		for _, pattern := range rule.Patterns {
+			if matched, _ := regexp.MatchString(pattern, text); matched {
+				confidence = 0.9
+				break
+			}
+		}
+		
+		// Otherwise score by the share of the rule's keywords present
+		if confidence == 0 {
+			matchCount := 0
+			for _, keyword := range rule.Keywords {
+				if strings.Contains(textLower, keyword) {
+					matchCount++
+				}
+			}
+			if matchCount > 0 {
+				confidence = float64(matchCount) / float64(len(rule.Keywords)) * 0.8
 			}
 		}
-		if matchCount >= 2 || (matchCount == 1 && len(rule.Keywords) <= 2) {
-			confidence := float64(matchCount) / float64(len(rule.Keywords)) * 0.8
-			return &Intent{
-				Name:       rule.IntentName,
-				Confidence: confidence,
-			}, nil
+		
+		if confidence > 0 {
+			ranked = append(ranked, Intent{Name: rule.IntentName, Confidence: confidence})
+			hasFallback = hasFallback || rule.IntentName == "ask_question"
 		}
 	}
 	
 	// Default to general inquiry
-	return &Intent{
-		Name:       "ask_question",
-		Confidence: 0.5,
-	}, nil
+	if !hasFallback {
+		ranked = append(ranked, Intent{Name: "ask_question", Confidence: 0.5})
+	}
+	
+	// Stable so that ties keep rule order
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Confidence > ranked[j].Confidence
+	})
+	return ranked, nil
+}
+
+// SetMinIntentConfidence sets the top-intent confidence below which
+// SelectIntent asks for clarification
+func (c *IntentClassifier) SetMinIntentConfidence(min float64) {
+	c.minConfidence = min
+}
+
+// MinIntentConfidence is the threshold SelectIntent applies
+func (c *IntentClassifier) MinIntentConfidence() float64 {
+	if c.minConfidence <= 0 {
+		return DefaultMinIntentConfidence
+	}
+	return c.minConfidence
+}
+
+// SelectIntent picks the intent to act on from a ranking. A confident top
+// intent is returned with the runner-up and its score as its alternative.
+// Below MinIntentConfidence it returns IntentClarify carrying the two best
+// actionable candidates; when fewer than two are plausible the top intent
+// is returned as before.
+func (c *IntentClassifier) SelectIntent(ranked []Intent) *Intent {
+	top := TopIntent(ranked, maxClarifyChoices-1)
+	if top.Confidence >= c.MinIntentConfidence() {
+		return top
+	}
+	
+	var candidates []Intent
+	for _, candidate := range ranked {
+		if _, ok := clarifyLabels[candidate.Name]; !ok {
+			continue
+		}
+		candidates = append(candidates, Intent{Name: candidate.Name, Confidence: candidate.Confidence})
+		if len(candidates) == maxClarifyChoices {
+			break
+		}
+	}
+	if len(candidates) < maxClarifyChoices {
+		return top
+	}
+	return &Intent{Name: IntentClarify, Confidence: top.Confidence, Alternatives: candidates}
+}
+
+// TopIntent returns the best-ranked intent carrying up to maxAlternatives of
+// the runners-up
+func TopIntent(ranked []Intent, maxAlternatives int) *Intent {
+	if len(ranked) == 0 {
+		return &Intent{Name: "ask_question", Confidence: 0.5}
+	}
+	top := ranked[0]
+	if rest := ranked[1:]; len(rest) > 0 {
+		top.Alternatives = append([]Intent{}, rest[:min(maxAlternatives, len(rest))]...)
+	}
+	return &top
 }
 
 // EntityExtractor extracts entities from text
@@ -432,10 +574,12 @@ func NewEntityExtractor() *EntityExtractor {
 		patterns: map[string]*regexp.Regexp{
 			"date": regexp.MustCompile(`(?i)(\d{1,2}[\/\-]\d{1,2}[\/\-]\d{2,4}|` +
 				`(january|february|march|april|may|june|july|august|september|october|november|december)\s+\d{1,2}(st|nd|rd|th)?,?\s*\d{0,4}|` +
-				`(next|this)\s+(week|month|year|saturday|sunday|monday|tuesday|wednesday|thursday|friday)|` +
+				`(next|this)\s+(weekend|week|month|year|saturday|sunday|monday|tuesday|wednesday|thursday|friday|` +
+				`january|february|march|april|may|june|july|august|september|october|november|december)\b|` +
+				`\bin\s+\d+\s+(days?|weeks?|months?|years?)\b|` +
 				`(tomorrow|today|weekend))`),
 			"number": regexp.MustCompile(`(\d+)\s*(people|guests|persons|attendees|pax)`),
-			"budget": regexp.MustCompile(`(?i)(₦|ngn|naira)?\s*(\d{1,3}(?:,?\d{3})*(?:\.\d{2})?)\s*(million|m|k|thousand)?`),
+			"budget": budgetPattern,
 			"location": regexp.MustCompile(`(?i)(in|at|around|near)\s+([A-Za-z\s]+?)(?:\s*,|\s*$|\s+(?:on|for|with))`),
 			"event_type": regexp.MustCompile(`(?i)(wedding|birthday|party|funeral|graduation|anniversary|baby shower|naming ceremony|corporate event|conference|product launch)`),
 			"vendor_type": regexp.MustCompile(`(?i)(photographer|videographer|caterer|decorator|dj|mc|planner|florist|makeup artist|hair stylist|cake baker|venue)`),
@@ -445,27 +589,344 @@ func NewEntityExtractor() *EntityExtractor {
 	}
 }
 
+// budgetPattern matches an amount with an optional currency symbol or code in
+// front, an optional multiplier, and an optional currency word after it
+var budgetPattern = regexp.MustCompile(`(?i)(?:(c\$|\$|£|€|₦|₵|\b(?:ngn|usd|gbp|eur|cad|ghs|kes|zar|naira)\b)\s*)?` +
+	`((?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d{1,2})?)` +
+	`(?:\s*(million|thousand|m|k)\b)?` +
+	`(?:\s*\b(naira|dollars?|pounds?|quid|euros?|cedis?|rand|shillings?|ngn|usd|gbp|eur|cad|ghs|kes|zar)\b)?`)
+
+// DefaultBudgetCurrency is assumed when a budget names no currency
+const DefaultBudgetCurrency = "NGN"
+
+// budgetCurrencies maps currency symbols, codes and words to ISO codes
+var budgetCurrencies = map[string]string{
+	"₦": "NGN", "ngn": "NGN", "naira": "NGN",
+	"$": "USD", "usd": "USD", "dollar": "USD", "dollars": "USD",
+	"£": "GBP", "gbp": "GBP", "pound": "GBP", "pounds": "GBP", "quid": "GBP",
+	"€": "EUR", "eur": "EUR", "euro": "EUR", "euros": "EUR",
+	"c$": "CAD", "cad": "CAD",
+	"₵": "GHS", "ghs": "GHS", "cedi": "GHS", "cedis": "GHS",
+	"kes": "KES", "shilling": "KES", "shillings": "KES",
+	"zar": "ZAR", "rand": "ZAR",
+}
+
+// currencySymbols is used when displaying a budget back to the user
+var currencySymbols = map[string]string{
+	"NGN": "₦", "USD": "$", "GBP": "£", "EUR": "€", "CAD": "C$", "GHS": "₵",
+}
+
+// BudgetAmount is a parsed budget in the currency the user gave it in
+type BudgetAmount struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// String renders the budget for response templates, e.g. "$5,000"
+func (b BudgetAmount) String() string {
+	amount := groupThousands(b.Amount)
+	if symbol, ok := currencySymbols[b.Currency]; ok {
+		return symbol + amount
+	}
+	return b.Currency + " " + amount
+}
+
+// ParseBudget reads an amount and its currency from text such as "$5,000",
+// "5k dollars" or "₦2.5 million". A trailing currency word or code wins over
+// a leading symbol, so "$5000 CAD" is Canadian dollars.
+func ParseBudget(text string) (BudgetAmount, bool) {
+	m := budgetPattern.FindStringSubmatch(text)
+	if m == nil {
+		return BudgetAmount{}, false
+	}
+	prefix, number, multiplier, suffix := strings.ToLower(m[1]), m[2], strings.ToLower(m[3]), strings.ToLower(m[4])
+	
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", ""), 64)
+	if err != nil {
+		return BudgetAmount{}, false
+	}
+	switch multiplier {
+	case "million", "m":
+		amount *= 1000000
+	case "thousand", "k":
+		amount *= 1000
+	}
+	
+	currency := DefaultBudgetCurrency
+	if code, ok := budgetCurrencies[suffix]; ok {
+		currency = code
+	} else if code, ok := budgetCurrencies[prefix]; ok {
+		currency = code
+	}
+	
+	return BudgetAmount{Amount: amount, Currency: currency}, true
+}
+
+func groupThousands(amount float64) string {
+	whole := strconv.FormatFloat(math.Trunc(amount), 'f', 0, 64)
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if frac := amount - math.Trunc(amount); frac != 0 {
+		b.WriteString(strings.TrimPrefix(strconv.FormatFloat(frac, 'f', 2, 64), "0"))
+	}
+	return b.String()
+}
+
+// DateFlexibility is how loosely a resolved date was stated
+type DateFlexibility string
+
+const (
+	DateFixed    DateFlexibility = "fixed"    // A specific day, e.g. "tomorrow"
+	DateFlexible DateFlexibility = "flexible" // A rough time, e.g. "next December"
+)
+
+// ErrUnparseableDate is returned for date text that cannot be resolved
+var ErrUnparseableDate = errors.New("unparseable date")
+
+var (
+	relativeInPattern   = regexp.MustCompile(`^in (\d+) (day|week|month|year)s?$`)
+	relativeNextPattern = regexp.MustCompile(`^(next|this) ([a-z]+)$`)
+	numericDatePattern  = regexp.MustCompile(`^(\d{1,2})[\/\-](\d{1,2})[\/\-](\d{2,4})$`)
+	monthDayPattern     = regexp.MustCompile(`^([a-z]+) (\d{1,2})(?:st|nd|rd|th)?,? ?(\d{4})?$`)
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+var monthNames = map[string]time.Month{
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+}
+
+// ResolveRelativeDate turns date text such as "tomorrow", "next weekend",
+// "next December", "in 6 months" or "12/05/2026" into a day relative to now.
+// Numeric dates are read day first. Phrases naming a day are DateFixed;
+// anything looser is DateFlexible, and a named week, weekend, month or year
+// resolves to its first day.
+func ResolveRelativeDate(text string, now time.Time) (time.Time, DateFlexibility, error) {
+	phrase := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	
+	switch phrase {
+	case "today":
+		return today, DateFixed, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), DateFixed, nil
+	case "weekend":
+		return thisWeekend(today), DateFlexible, nil
+	}
+	
+	if m := relativeInPattern.FindStringSubmatch(phrase); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "day":
+			return today.AddDate(0, 0, n), DateFixed, nil
+		case "week":
+			return today.AddDate(0, 0, 7*n), DateFlexible, nil
+		case "month":
+			return today.AddDate(0, n, 0), DateFlexible, nil
+		default:
+			return today.AddDate(n, 0, 0), DateFlexible, nil
+		}
+	}
+	
+	if m := relativeNextPattern.FindStringSubmatch(phrase); m != nil {
+		next := m[1] == "next"
+		switch unit := m[2]; unit {
+		case "weekend":
+			if next {
+				return thisWeekend(today).AddDate(0, 0, 7), DateFlexible, nil
+			}
+			return thisWeekend(today), DateFlexible, nil
+		case "week":
+			if next {
+				return today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7), DateFlexible, nil
+			}
+			return today, DateFlexible, nil
+		case "month":
+			if next {
+				return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), DateFlexible, nil
+			}
+			return today, DateFlexible, nil
+		case "year":
+			if next {
+				return time.Date(today.Year()+1, time.January, 1, 0, 0, 0, 0, today.Location()), DateFlexible, nil
+			}
+			return today, DateFlexible, nil
+		default:
+			if day, ok := weekdayNames[unit]; ok {
+				days := (int(day) - int(today.Weekday()) + 7) % 7
+				if next && days == 0 {
+					days = 7
+				}
+				return today.AddDate(0, 0, days), DateFixed, nil
+			}
+			if month, ok := monthNames[unit]; ok {
+				if month == today.Month() && !next {
+					return today, DateFlexible, nil
+				}
+				year := today.Year()
+				if month <= today.Month() {
+					year++
+				}
+				return time.Date(year, month, 1, 0, 0, 0, 0, today.Location()), DateFlexible, nil
+			}
+		}
+		return time.Time{}, "", ErrUnparseableDate
+	}
+	
+	if m := numericDatePattern.FindStringSubmatch(phrase); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		year, _ := strconv.Atoi(m[3])
+		if len(m[3]) == 2 {
+			year += 2000
+		}
+		return exactDate(year, time.Month(month), day, today.Location())
+	}
+	
+	if m := monthDayPattern.FindStringSubmatch(phrase); m != nil {
+		month, ok := monthNames[m[1]]
+		if !ok {
+			return time.Time{}, "", ErrUnparseableDate
+		}
+		day, _ := strconv.Atoi(m[2])
+		if m[3] != "" {
+			year, _ := strconv.Atoi(m[3])
+			return exactDate(year, month, day, today.Location())
+		}
+		date, flex, err := exactDate(today.Year(), month, day, today.Location())
+		if err == nil && date.Before(today) {
+			date = date.AddDate(1, 0, 0)
+		}
+		return date, flex, err
+	}
+	
+	return time.Time{}, "", ErrUnparseableDate
+}
+
+// thisWeekend is the Saturday of the current weekend, or the coming one
+// during the week
+func thisWeekend(today time.Time) time.Time {
+	if today.Weekday() == time.Sunday {
+		return today.AddDate(0, 0, -1)
+	}
+	return today.AddDate(0, 0, int(time.Saturday-today.Weekday()))
+}
+
+// exactDate rejects days that do not exist rather than letting time.Date
+// roll them over, so "31/02/2026" is not read as March 3rd
+func exactDate(year int, month time.Month, day int, loc *time.Location) (time.Time, DateFlexibility, error) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if date.Year() != year || date.Month() != month || date.Day() != day {
+		return time.Time{}, "", ErrUnparseableDate
+	}
+	return date, DateFixed, nil
+}
+
+// defaultEntityConfidence is the confidence of a pattern match
+const defaultEntityConfidence = 0.85
+
+// entityConfidence lowers the confidence of patterns that match loosely, so
+// that a location phrase never swallows the vendor or style named inside it
+var entityConfidence = map[string]float64{
+	"location": 0.6,
+}
+
+// ExtractEntities runs every pattern over the text and resolves the
+// overlapping matches, returning entities ordered by StartPos
 func (e *EntityExtractor) ExtractEntities(text string) []Entity {
 	var entities []Entity
 	
 	for entityType, pattern := range e.patterns {
+		confidence, ok := entityConfidence[entityType]
+		if !ok {
+			confidence = defaultEntityConfidence
+		}
 		matches := pattern.FindAllStringSubmatchIndex(text, -1)
 		for _, match := range matches {
 			if len(match) >= 2 {
 				value := text[match[0]:match[1]]
-				entities = append(entities, Entity{
+				entity := Entity{
 					Type:       entityType,
 					Value:      e.parseEntityValue(entityType, value),
 					Text:       value,
 					StartPos:   match[0],
 					EndPos:     match[1],
-					Confidence: 0.85,
-				})
+					Confidence: confidence,
+				}
+				if entityType == "date" {
+					if date, flexibility, err := ResolveRelativeDate(value, time.Now()); err == nil {
+						entity.Value, entity.DateFlexibility = date, flexibility
+					}
+				}
+				entities = append(entities, entity)
 			}
 		}
 	}
 	
-	return entities
+	return ResolveEntityOverlaps(entities)
+}
+
+// ResolveEntityOverlaps drops entities that another entity makes redundant.
+// Entities with identical spans are merged into the most confident one, and
+// an entity fully inside a span of a different type is dropped when that
+// span is more confident or, at equal confidence, longer. So the "2026" in
+// "12/05/2026" is not also a budget. The result is ordered by StartPos.
+func ResolveEntityOverlaps(entities []Entity) []Entity {
+	sorted := append([]Entity{}, entities...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.StartPos != b.StartPos {
+			return a.StartPos < b.StartPos
+		}
+		if a.EndPos != b.EndPos {
+			return a.EndPos > b.EndPos
+		}
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		return a.Type < b.Type
+	})
+	
+	resolved := make([]Entity, 0, len(sorted))
+	for i, entity := range sorted {
+		// The most confident entity on a span sorts first
+		if i > 0 && entity.StartPos == sorted[i-1].StartPos && entity.EndPos == sorted[i-1].EndPos {
+			continue
+		}
+		if entityCovered(entity, sorted) {
+			continue
+		}
+		resolved = append(resolved, entity)
+	}
+	return resolved
+}
+
+// entityCovered reports whether a different-typed entity contains entity's
+// span and outranks it
+func entityCovered(entity Entity, entities []Entity) bool {
+	for _, other := range entities {
+		if other.Type == entity.Type || other.StartPos > entity.StartPos || other.EndPos < entity.EndPos {
+			continue
+		}
+		if other.StartPos == entity.StartPos && other.EndPos == entity.EndPos {
+			continue
+		}
+		if other.Confidence > entity.Confidence ||
+			(other.Confidence == entity.Confidence && other.EndPos-other.StartPos > entity.EndPos-entity.StartPos) {
+			return true
+		}
+	}
+	return false
 }
 
 func (e *EntityExtractor) parseEntityValue(entityType string, text string) interface{} {
@@ -479,24 +940,12 @@ func (e *EntityExtractor) parseEntityValue(entityType string, text string) inter
 		return num
 		
 	case "budget":
-		// Parse budget with multipliers
-		text = strings.ToLower(text)
-		text = strings.ReplaceAll(text, "₦", "")
-		text = strings.ReplaceAll(text, "ngn", "")
-		text = strings.ReplaceAll(text, "naira", "")
-		text = strings.ReplaceAll(text, ",", "")
-		text = strings.TrimSpace(text)
-		
-		var amount float64
-		fmt.Sscanf(text, "%f", &amount)
-		
-		if strings.Contains(text, "million") || strings.Contains(text, "m") {
-			amount *= 1000000
-		} else if strings.Contains(text, "thousand") || strings.Contains(text, "k") {
-			amount *= 1000
+		// Parse budget with multipliers and currency
+		budget, ok := ParseBudget(text)
+		if !ok {
+			return text
 		}
-		
-		return amount
+		return budget
 		
 	default:
 		return text
@@ -506,6 +955,9 @@ func (e *EntityExtractor) parseEntityValue(entityType string, text string) inter
 // SlotFiller manages conversation slots
 type SlotFiller struct {
 	slotDefinitions map[string]SlotDefinition
+	
+	// Slot sets that replace EventCreationSlots once event_type is known
+	eventTypeSlots map[string]map[string]SlotDefinition
 }
 
 type SlotDefinition struct {
@@ -514,6 +966,8 @@ type SlotDefinition struct {
 	Required    bool
 	Prompts     []string
 	Validators  []SlotValidator
+	Priority    int    // Lower is asked first
+	Template    string // Response template used to ask; defaults to ask_<name>
 }
 
 type SlotValidator func(value interface{}) (bool, string)
@@ -522,6 +976,7 @@ type SlotValidator func(value interface{}) (bool, string)
 var EventCreationSlots = map[string]SlotDefinition{
 	"event_type": {
 		Name:        "event_type",
+		Priority:    1,
 		EntityTypes: []string{"event_type"},
 		Required:    true,
 		Prompts: []string{
@@ -531,6 +986,7 @@ var EventCreationSlots = map[string]SlotDefinition{
 	},
 	"event_date": {
 		Name:        "event_date",
+		Priority:    2,
 		EntityTypes: []string{"date"},
 		Required:    true,
 		Prompts: []string{
@@ -540,6 +996,7 @@ var EventCreationSlots = map[string]SlotDefinition{
 	},
 	"guest_count": {
 		Name:        "guest_count",
+		Priority:    3,
 		EntityTypes: []string{"number"},
 		Required:    true,
 		Prompts: []string{
@@ -549,6 +1006,7 @@ var EventCreationSlots = map[string]SlotDefinition{
 	},
 	"location": {
 		Name:        "location",
+		Priority:    4,
 		EntityTypes: []string{"location"},
 		Required:    true,
 		Prompts: []string{
@@ -558,6 +1016,7 @@ var EventCreationSlots = map[string]SlotDefinition{
 	},
 	"budget": {
 		Name:        "budget",
+		Priority:    5,
 		EntityTypes: []string{"budget"},
 		Required:    false,
 		Prompts: []string{
@@ -567,19 +1026,121 @@ var EventCreationSlots = map[string]SlotDefinition{
 	},
 }
 
+// FuneralSlots lead with when and where, leave headcount optional and don't
+// ask about budget or frame the event as a celebration
+var FuneralSlots = map[string]SlotDefinition{
+	"event_type": EventCreationSlots["event_type"],
+	"event_date": {
+		Name:        "event_date",
+		EntityTypes: []string{"date"},
+		Required:    true,
+		Priority:    2,
+		Template:    "ask_event_date_funeral",
+		Prompts: []string{
+			"When will the service be held?",
+		},
+	},
+	"location": {
+		Name:        "location",
+		EntityTypes: []string{"location"},
+		Required:    true,
+		Priority:    3,
+		Template:    "ask_location_funeral",
+		Prompts: []string{
+			"Where will the service take place?",
+		},
+	},
+	"guest_count": {
+		Name:        "guest_count",
+		EntityTypes: []string{"number"},
+		Required:    false,
+		Priority:    4,
+		Template:    "ask_guest_count_funeral",
+		Prompts: []string{
+			"Roughly how many people do you expect to attend?",
+		},
+	},
+}
+
+// CorporateSlots make budget required since vendors quote against it
+var CorporateSlots = map[string]SlotDefinition{
+	"event_type":  EventCreationSlots["event_type"],
+	"event_date":  EventCreationSlots["event_date"],
+	"guest_count": EventCreationSlots["guest_count"],
+	"location":    EventCreationSlots["location"],
+	"budget": {
+		Name:        "budget",
+		EntityTypes: []string{"budget"},
+		Required:    true,
+		Priority:    5,
+		Template:    "ask_budget_corporate",
+		Prompts: []string{
+			"What budget has been approved for this event?",
+		},
+	},
+}
+
+// EventTypeSlots are the slot sets registered by default, keyed by event type
+var EventTypeSlots = map[string]map[string]SlotDefinition{
+	"funeral":         FuneralSlots,
+	"burial":          FuneralSlots,
+	"memorial":        FuneralSlots,
+	"corporate":       CorporateSlots,
+	"corporate event": CorporateSlots,
+	"conference":      CorporateSlots,
+}
+
+// NewSlotFiller creates a slot filler with the default event type slot sets
+func NewSlotFiller() *SlotFiller {
+	sf := &SlotFiller{
+		slotDefinitions: EventCreationSlots,
+		eventTypeSlots:  make(map[string]map[string]SlotDefinition),
+	}
+	for eventType, slots := range EventTypeSlots {
+		sf.RegisterEventTypeSlots(eventType, slots)
+	}
+	return sf
+}
+
+// RegisterEventTypeSlots sets the slots gathered for an event type. The set
+// must include event_type so it stays filled after the switch.
+func (sf *SlotFiller) RegisterEventTypeSlots(eventType string, slots map[string]SlotDefinition) {
+	if sf.eventTypeSlots == nil {
+		sf.eventTypeSlots = make(map[string]map[string]SlotDefinition)
+	}
+	sf.eventTypeSlots[strings.ToLower(eventType)] = slots
+}
+
+// SlotsFor returns the slot definitions for an intent, switching to the
+// event type's slot set once event_type has been filled
+func (sf *SlotFiller) SlotsFor(intent string, currentSlots map[string]SlotValue) map[string]SlotDefinition {
+	if intent != "create_event" {
+		return EventCreationSlots
+	}
+	
+	eventType, ok := currentSlots["event_type"]
+	if !ok {
+		return EventCreationSlots
+	}
+	key := strings.ToLower(fmt.Sprintf("%v", eventType.Value))
+	
+	registry := EventTypeSlots
+	if sf != nil && sf.eventTypeSlots != nil {
+		registry = sf.eventTypeSlots
+	}
+	if slots, ok := registry[key]; ok {
+		return slots
+	}
+	return EventCreationSlots
+}
+
 func (sf *SlotFiller) FillSlots(entities []Entity, currentSlots map[string]SlotValue, intent string) map[string]SlotValue {
 	if currentSlots == nil {
 		currentSlots = make(map[string]SlotValue)
 	}
 	
-	// Get slot definitions based on intent
-	var relevantSlots map[string]SlotDefinition
-	switch intent {
-	case "create_event":
-		relevantSlots = EventCreationSlots
-	default:
-		relevantSlots = EventCreationSlots // Default to event slots
-	}
+	// Get slot definitions based on intent and, once known, event type
+	relevantSlots := sf.SlotsFor(intent, currentSlots)
 	
 	// Fill slots from entities
 	for _, entity := range entities {
@@ -608,22 +1169,29 @@ func (sf *SlotFiller) FillSlots(entities []Entity, currentSlots map[string]SlotV
 func (sf *SlotFiller) GetMissingRequiredSlots(currentSlots map[string]SlotValue, intent string) []SlotDefinition {
 	var missing []SlotDefinition
 	
-	var relevantSlots map[string]SlotDefinition
-	switch intent {
-	case "create_event":
-		relevantSlots = EventCreationSlots
-	default:
+	if intent != "create_event" {
 		return missing
 	}
 	
-	for name, slotDef := range relevantSlots {
+	for name, slotDef := range sf.SlotsFor(intent, currentSlots) {
 		if slotDef.Required {
 			if _, exists := currentSlots[name]; !exists {
+				if slotDef.Template == "" {
+					slotDef.Template = fmt.Sprintf("ask_%s", slotDef.Name)
+				}
 				missing = append(missing, slotDef)
 			}
 		}
 	}
 	
+	// Ask in priority order
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Priority != missing[j].Priority {
+			return missing[i].Priority < missing[j].Priority
+		}
+		return missing[i].Name < missing[j].Name
+	})
+	
 	return missing
 }
 
@@ -639,6 +1207,98 @@ type DialogManager struct {
 	memoryManager  *MemoryManager
 	db             *pgxpool.Pool
 	cache          *redis.Client
+	
+	// Minimum intent confidence per action type before it runs unconfirmed
+	confidenceThresholds ConfidenceThresholds
+	
+	// Meters model calls and actions, and enforces tier caps when set
+	usage *UsageMeter
+	
+	// Records response processing times for latency reporting when set
+	metrics *ProcessingMetrics
+	
+	// Gates new dialog behaviours per conversation; nil leaves them all off
+	flags *FeatureFlags
+	
+	// Persists vendors users save from cards; nil disables saving
+	savedVendors SavedVendorStore
+	
+	// Support queue for conversations handed to a person; nil only moves
+	// the conversation to StateHandoff
+	handoffs HandoffStore
+	
+	// Keeps conversations between turns
+	conversations ConversationStore
+	
+	// Runs turns for the same conversation one at a time
+	turns conversationTurns
+}
+
+// NewDialogManager wires the rule-based NLU pipeline, templated responses
+// and action executor. A nil db keeps conversations in memory only.
+func NewDialogManager(db *pgxpool.Pool, cache *redis.Client) *DialogManager {
+	dm := &DialogManager{
+		nlu: &NLUEngine{
+			db:               db,
+			intentClassifier: NewIntentClassifier(nil),
+			entityExtractor:  NewEntityExtractor(),
+			slotFiller:       NewSlotFiller(),
+		},
+		responseGen:    NewResponseGenerator(db),
+		actionExecutor: &ActionExecutor{db: db, cache: cache},
+		memoryManager:  &MemoryManager{cache: cache, db: db},
+		db:             db,
+		cache:          cache,
+	}
+	if db != nil {
+		dm.SetConversationStore(NewPostgresConversationStore(db))
+		dm.SetKnowledgeBase(NewPostgresKnowledgeBase(db))
+		dm.SetHandoffStore(NewPostgresHandoffStore(db))
+	} else {
+		dm.SetConversationStore(NewMemoryConversationStore())
+	}
+	return dm
+}
+
+// conversationTurns hands out a lock per conversation ID. A client that
+// double-sends would otherwise have two turns mutating the same slots and
+// short-term memory at once, and the later save would drop the earlier
+// turn. Locks are dropped once nobody holds or waits on them. This only
+// serializes turns within one process.
+type conversationTurns struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*turnLock
+}
+
+type turnLock struct {
+	mu      sync.Mutex
+	holders int // holding or waiting
+}
+
+// acquire blocks until the conversation is free and returns its release
+func (t *conversationTurns) acquire(convID uuid.UUID) (release func()) {
+	t.mu.Lock()
+	if t.locks == nil {
+		t.locks = make(map[uuid.UUID]*turnLock)
+	}
+	lock := t.locks[convID]
+	if lock == nil {
+		lock = &turnLock{}
+		t.locks[convID] = lock
+	}
+	lock.holders++
+	t.mu.Unlock()
+	
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		t.mu.Lock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(t.locks, convID)
+		}
+		t.mu.Unlock()
+	}
 }
 
 // ConversationContext provides context for dialog decisions
@@ -651,6 +1311,7 @@ type ConversationContext struct {
 	Slots           map[string]SlotValue
 	TurnCount       int
 	LastMessages    []Message
+	Summary         string
 	UserProfile     *UserProfile
 }
 
@@ -668,10 +1329,24 @@ type PastEvent struct {
 	Satisfaction int
 }
 
-// ProcessMessage is the main entry point for handling user messages
+// ProcessMessage is the main entry point for handling user messages. Turns
+// for the same conversation run one at a time.
 func (dm *DialogManager) ProcessMessage(ctx context.Context, conv *Conversation, userMessage string) (*Message, error) {
+	defer dm.turns.acquire(conv.ID)()
+	return dm.processMessage(ctx, conv, userMessage)
+}
+
+// processMessage runs a single turn; the caller holds the conversation's
+// turn lock
+func (dm *DialogManager) processMessage(ctx context.Context, conv *Conversation, userMessage string) (_ *Message, err error) {
 	startTime := time.Now()
 	
+	ctx, span := tracing.Start(ctx, "eventgpt.ProcessMessage",
+		attribute.String("conversation.id", conv.ID.String()),
+		attribute.Int("conversation.turn", conv.TurnCount),
+	)
+	defer func() { tracing.End(span, err) }()
+	
 	// 1. Add user message to conversation
 	userMsg := Message{
 		ID:        uuid.New(),
@@ -680,21 +1355,38 @@ func (dm *DialogManager) ProcessMessage(ctx context.Context, conv *Conversation,
 		Timestamp: time.Now(),
 	}
 	
-	// 2. Build conversation context
-	convContext := dm.buildContext(conv)
+	// 2. Pin feature flags, condense older turns, then build conversation context
+	dm.flags.Resolve(ctx, conv)
+	dm.memoryManager.SummarizeConversation(conv)
+	convContext := dm.BuildContext(conv)
 	
 	// 3. Run NLU pipeline
-	intent, err := dm.nlu.intentClassifier.ClassifyIntent(ctx, userMessage, convContext)
+	nluCtx, nluSpan := tracing.Start(ctx, "eventgpt.nlu.classify_intent")
+	rankedIntents, err := dm.nlu.intentClassifier.ClassifyIntent(nluCtx, userMessage, convContext)
 	if err != nil {
+		tracing.End(nluSpan, err)
 		return nil, fmt.Errorf("intent classification failed: %w", err)
 	}
+	intent := dm.nlu.intentClassifier.SelectIntent(rankedIntents)
+	nluSpan.SetAttributes(
+		attribute.String("intent.name", intent.Name),
+		attribute.Float64("intent.confidence", intent.Confidence),
+	)
+	nluSpan.End()
 	userMsg.Intent = intent
+	dm.usage.RecordModelCall(ctx, conv, ModelUsage{Model: intentModelRules, Purpose: "classify_intent"})
 	
+	_, nluSpan = tracing.Start(ctx, "eventgpt.nlu.extract_entities")
 	entities := dm.nlu.entityExtractor.ExtractEntities(userMessage)
+	nluSpan.SetAttributes(attribute.Int("entities.count", len(entities)))
+	nluSpan.End()
 	userMsg.Entities = entities
 	
 	// 4. Fill slots with extracted entities
+	_, nluSpan = tracing.Start(ctx, "eventgpt.nlu.fill_slots")
 	conv.SlotValues = dm.nlu.slotFiller.FillSlots(entities, conv.SlotValues, intent.Name)
+	nluSpan.SetAttributes(attribute.Int("slots.count", len(conv.SlotValues)))
+	nluSpan.End()
 	
 	// 5. Update conversation state
 	conv.CurrentIntent = *intent
@@ -702,14 +1394,40 @@ func (dm *DialogManager) ProcessMessage(ctx context.Context, conv *Conversation,
 	conv.TurnCount++
 	conv.LastMessageAt = time.Now()
 	
-	// 6. Determine response strategy
-	responseStrategy := dm.determineResponseStrategy(conv, intent)
+	// Apply explicit corrections ("actually, a birthday not a wedding")
+	dm.ApplyCorrection(conv, userMessage, entities)
+	
+	// Resolve references to earlier saves ("my saved photographer")
+	dm.RecallSavedVendor(ctx, conv, userMessage)
+	
+	// 6. Determine response strategy, asking for confirmation instead of
+	// acting when the intent is too uncertain for the actions it triggers
+	intent = dm.ResolvePendingIntent(conv, userMessage, intent)
+	conv.CurrentIntent = *intent
+	conv.IntentHistory = append(conv.IntentHistory, intent.Name)
+	responseStrategy := dm.ApplyConfidenceGate(conv, intent, dm.determineResponseStrategy(conv, intent))
+	responseStrategy = dm.enforceUsageLimits(ctx, conv, responseStrategy)
 	
 	// 7. Execute any required actions
-	actionResults, err := dm.actionExecutor.ExecuteActions(ctx, responseStrategy.Actions, conv)
+	actionCtx, actionSpan := tracing.Start(ctx, "eventgpt.execute_actions",
+		attribute.Int("actions.count", len(responseStrategy.Actions)),
+	)
+	actionResults, err := dm.actionExecutor.ExecuteActions(actionCtx, responseStrategy.Actions, conv)
+	tracing.End(actionSpan, err)
 	if err != nil {
 		// Log but don't fail
 	}
+	for _, action := range responseStrategy.Actions {
+		dm.usage.RecordAction(ctx, conv, action.Type)
+	}
+	responseStrategy = ApplyVendorSearchOutcome(responseStrategy, actionResults)
+	responseStrategy = ApplySaveVendorOutcome(responseStrategy, actionResults)
+	responseStrategy = ApplyKnowledgeBaseOutcome(responseStrategy, actionResults)
+	responseStrategy = ApplyBookingOutcome(responseStrategy, actionResults)
+	responseStrategy = dm.ApplyFailedTurns(conv, responseStrategy)
+	if err := dm.RecordHandoff(ctx, conv, responseStrategy); err != nil {
+		// Left out of StateHandoff so the next turn queues it again
+	}
 	
 	// 8. Generate response
 	response, err := dm.responseGen.GenerateResponse(ctx, conv, responseStrategy, actionResults)
@@ -721,6 +1439,7 @@ func (dm *DialogManager) ProcessMessage(ctx context.Context, conv *Conversation,
 	response.Role = RoleAssistant
 	response.Timestamp = time.Now()
 	response.ProcessingTime = time.Since(startTime).Milliseconds()
+	dm.metrics.Record(ctx, conv, intent.Name, responseStrategy.Actions, response.ProcessingTime)
 	
 	// 9. Update conversation state based on response
 	conv.ConversationState = responseStrategy.NextState
@@ -732,7 +1451,10 @@ func (dm *DialogManager) ProcessMessage(ctx context.Context, conv *Conversation,
 	return response, nil
 }
 
-func (dm *DialogManager) buildContext(conv *Conversation) *ConversationContext {
+// BuildContext assembles the dialog context for the next turn. When older
+// turns have been summarized, the summary is prepended as a system message
+// ahead of the most recent messages.
+func (dm *DialogManager) BuildContext(conv *Conversation) *ConversationContext {
 	ctx := &ConversationContext{
 		UserID:         conv.UserID,
 		ConversationID: conv.ID,
@@ -742,16 +1464,254 @@ func (dm *DialogManager) buildContext(conv *Conversation) *ConversationContext {
 		TurnCount:      conv.TurnCount,
 	}
 	
-	// Get last N messages for context
-	if len(conv.Messages) > 10 {
-		ctx.LastMessages = conv.Messages[len(conv.Messages)-10:]
-	} else {
-		ctx.LastMessages = conv.Messages
+	// Get last N messages for context, skipping anything already summarized
+	start := 0
+	if len(conv.Messages) > summaryRecentMessages {
+		start = len(conv.Messages) - summaryRecentMessages
+	}
+	if through := memoryInt(conv.ShortTermMemory, memorySummarizedThrough); through > start {
+		start = min(through, len(conv.Messages))
 	}
+	recent := conv.Messages[start:]
+	
+	summary, _ := conv.ShortTermMemory[memoryConversationSummary].(string)
+	if summary == "" {
+		ctx.LastMessages = recent
+		return ctx
+	}
+	
+	ctx.Summary = summary
+	ctx.LastMessages = make([]Message, 0, len(recent)+1)
+	ctx.LastMessages = append(ctx.LastMessages, Message{
+		Role:      RoleSystem,
+		Content:   "Summary of earlier conversation:\n" + summary,
+		Timestamp: conv.StartedAt,
+	})
+	ctx.LastMessages = append(ctx.LastMessages, recent...)
 	
 	return ctx
 }
 
+// ConfidenceThresholds maps an action type to the minimum intent confidence
+// needed to execute it without asking the user first
+type ConfidenceThresholds map[string]float64
+
+const memoryPendingIntent = "pending_intent"
+
+// DefaultConfidenceThresholds gates side-effecting actions hardest; read-only
+// lookups run on anything above the keyword-fallback level
+func DefaultConfidenceThresholds() ConfidenceThresholds {
+	return ConfidenceThresholds{
+		"prepare_booking":    0.8,
+		"get_vendor_quote":   0.7,
+		"check_availability": 0.6,
+		"search_vendors":     0.6,
+	}
+}
+
+// Allows reports whether an action may run at the given confidence. Action
+// types without a threshold are always allowed.
+func (t ConfidenceThresholds) Allows(actionType string, confidence float64) bool {
+	threshold, ok := t[actionType]
+	return !ok || confidence >= threshold
+}
+
+// SetConfidenceThresholds overrides the per-action confidence thresholds
+func (dm *DialogManager) SetConfidenceThresholds(thresholds ConfidenceThresholds) {
+	dm.confidenceThresholds = thresholds
+}
+
+// SetMinIntentConfidence sets the intent confidence below which the user is
+// asked to choose between the top candidates
+func (dm *DialogManager) SetMinIntentConfidence(min float64) {
+	dm.nlu.intentClassifier.SetMinIntentConfidence(min)
+}
+
+func (dm *DialogManager) thresholds() ConfidenceThresholds {
+	if dm.confidenceThresholds == nil {
+		return DefaultConfidenceThresholds()
+	}
+	return dm.confidenceThresholds
+}
+
+// ApplyConfidenceGate replaces a strategy with a confirmation question when
+// any of its actions needs more confidence than the intent carries. The
+// intent is remembered so a "yes" on the next turn runs it in full.
+func (dm *DialogManager) ApplyConfidenceGate(conv *Conversation, intent *Intent, strategy *ResponseStrategy) *ResponseStrategy {
+	thresholds := dm.thresholds()
+	for _, action := range strategy.Actions {
+		if thresholds.Allows(action.Type, intent.Confidence) {
+			continue
+		}
+		
+		conv.ShortTermMemory[memoryPendingIntent] = intent.Name
+		return &ResponseStrategy{
+			Type:          ResponseConfirm,
+			Template:      "confirm_intent",
+			NextState:     conv.ConversationState,
+			ShouldConfirm: true,
+			QuickReplies: []QuickReply{
+				{Title: "Yes, go ahead", Payload: "intent:confirm"},
+				{Title: "No", Payload: "intent:reject"},
+			},
+		}
+	}
+	return strategy
+}
+
+// ResolvePendingIntent turns a "yes" reply to a confidence check into the
+// remembered intent at full confidence. Any other reply drops the pending
+// intent and is handled as a fresh message.
+func (dm *DialogManager) ResolvePendingIntent(conv *Conversation, userMessage string, intent *Intent) *Intent {
+	pending, _ := conv.ShortTermMemory[memoryPendingIntent].(string)
+	if pending == "" {
+		return intent
+	}
+	delete(conv.ShortTermMemory, memoryPendingIntent)
+	
+	reply := strings.ToLower(strings.TrimSpace(userMessage))
+	switch reply {
+	case "intent:confirm", "yes", "yes please", "yeah", "yep", "sure", "ok", "okay", "confirm", "go ahead":
+		return &Intent{Name: pending, Confidence: 1.0}
+	}
+	return intent
+}
+
+// correctionCue marks a message that revises something said earlier
+var correctionCue = regexp.MustCompile(`(?i)\b(actually|instead|i meant|rather than|change it to|make it|not an?|not the|no longer)\b`)
+
+// negatedBefore matches the value being replaced, as in "not a wedding"
+var negatedBefore = regexp.MustCompile(`(?i)\b(not|instead of|rather than|no longer)\s+(an?\s+|the\s+)?$`)
+
+// correctableSlots maps entity types to the slot a correction updates
+var correctableSlots = map[string]string{
+	"event_type": "event_type",
+	"date":       "event_date",
+	"number":     "guest_count",
+	"location":   "location",
+	"budget":     "budget",
+}
+
+// DetectCorrection finds the slot an explicit correction revises and its
+// new value. Values introduced by "not", "instead of" or "rather than" are
+// the ones being replaced and are skipped, as is a value the slot already
+// holds.
+func DetectCorrection(message string, entities []Entity, currentSlots map[string]SlotValue) (string, interface{}, bool) {
+	if !correctionCue.MatchString(message) {
+		return "", nil, false
+	}
+	
+	sorted := append([]Entity(nil), entities...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartPos < sorted[j].StartPos })
+	
+	for _, entity := range sorted {
+		slot, ok := correctableSlots[entity.Type]
+		if !ok {
+			continue
+		}
+		existing, filled := currentSlots[slot]
+		if !filled {
+			continue
+		}
+		if entity.StartPos <= len(message) && negatedBefore.MatchString(message[:entity.StartPos]) {
+			continue
+		}
+		if strings.EqualFold(fmt.Sprintf("%v", existing.Value), fmt.Sprintf("%v", entity.Value)) {
+			continue
+		}
+		return slot, entity.Value, true
+	}
+	return "", nil, false
+}
+
+// ApplyCorrection updates the slot an explicit correction names and records
+// the change. Changing event_type also clears slots the new event type no
+// longer gathers, vendor results found for the old event type, and any
+// pending confirmation of the old details. It returns the corrected slot,
+// or "" when the message is not a correction.
+func (dm *DialogManager) ApplyCorrection(conv *Conversation, userMessage string, entities []Entity) string {
+	slot, value, ok := DetectCorrection(userMessage, entities, conv.SlotValues)
+	if !ok {
+		return ""
+	}
+	
+	var slotFiller *SlotFiller
+	if dm != nil && dm.nlu != nil {
+		slotFiller = dm.nlu.slotFiller
+	}
+	before := slotFiller.SlotsFor("create_event", conv.SlotValues)
+	
+	old := conv.SlotValues[slot]
+	conv.SlotValues[slot] = SlotValue{
+		Value:      value,
+		Source:     "user",
+		Confidence: 1.0,
+		Timestamp:  time.Now(),
+	}
+	conv.SlotHistory = append(conv.SlotHistory, SlotChange{
+		Slot:     slot,
+		OldValue: old.Value,
+		NewValue: value,
+		Reason:   "corrected",
+		Turn:     conv.TurnCount,
+	})
+	
+	if slot == "event_type" {
+		after := slotFiller.SlotsFor("create_event", conv.SlotValues)
+		for name := range before {
+			if _, stillGathered := after[name]; stillGathered {
+				continue
+			}
+			if previous, filled := conv.SlotValues[name]; filled {
+				delete(conv.SlotValues, name)
+				conv.SlotHistory = append(conv.SlotHistory, SlotChange{
+					Slot:     name,
+					OldValue: previous.Value,
+					Reason:   "reset",
+					Turn:     conv.TurnCount,
+				})
+			}
+		}
+		delete(conv.ShortTermMemory, "vendor_results")
+	}
+	
+	if conv.ConversationState == StateConfirming {
+		conv.ConversationState = StateGatheringInfo
+	}
+	return slot
+}
+
+// correctedThisTurn reports whether a correction was applied on the
+// current turn
+func (conv *Conversation) correctedThisTurn() bool {
+	for i := len(conv.SlotHistory) - 1; i >= 0; i-- {
+		change := conv.SlotHistory[i]
+		if change.Turn != conv.TurnCount {
+			return false
+		}
+		if change.Reason == "corrected" {
+			return true
+		}
+	}
+	return false
+}
+
+// PreviousTaskIntent returns the most recent intent before the current turn
+// that drives a task, so a correction can resume it, or "" if there is none
+func PreviousTaskIntent(conv *Conversation) string {
+	history := conv.IntentHistory
+	if len(history) > 0 {
+		history = history[:len(history)-1]
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		switch history[i] {
+		case "create_event", "find_vendor", "get_quote", "book_service", "check_availability", "get_recommendation":
+			return history[i]
+		}
+	}
+	return ""
+}
+
 // ResponseStrategy defines how to respond
 type ResponseStrategy struct {
 	Type           ResponseType
@@ -780,6 +1740,44 @@ type ActionDefinition struct {
 	Parameters map[string]interface{}
 }
 
+// maxClarifyChoices is the number of candidate intents offered in a
+// clarifying question
+const maxClarifyChoices = 2
+
+// clarifyLabels are the quick reply titles for intents worth offering when
+// clarifying. Small-talk intents are never offered.
+var clarifyLabels = map[string]string{
+	"create_event":       "Plan an event",
+	"find_vendor":        "Find a vendor",
+	"get_quote":          "Get a quote",
+	"book_service":       "Book a service",
+	"compare_options":    "Compare options",
+	"check_availability": "Check availability",
+	"get_recommendation": "Get recommendations",
+	"view_plan":          "View my plan",
+	"update_preference":  "Change my details",
+}
+
+// ClarifyIntent handles IntentClarify, asking "Did you mean ...?" with the
+// candidate intents as quick replies. It returns nil for any other intent.
+func (dm *DialogManager) ClarifyIntent(conv *Conversation, intent *Intent) *ResponseStrategy {
+	if intent.Name != IntentClarify {
+		return nil
+	}
+	
+	var choices []QuickReply
+	for _, candidate := range intent.Alternatives {
+		choices = append(choices, QuickReply{Title: clarifyLabels[candidate.Name], Payload: "intent:" + candidate.Name})
+	}
+	
+	return &ResponseStrategy{
+		Type:         ResponseQuestion,
+		Template:     "clarify_intent",
+		NextState:    conv.ConversationState,
+		QuickReplies: choices,
+	}
+}
+
 func (dm *DialogManager) determineResponseStrategy(conv *Conversation, intent *Intent) *ResponseStrategy {
 	strategy := &ResponseStrategy{
 		Type:      ResponseText,
@@ -787,6 +1785,9 @@ func (dm *DialogManager) determineResponseStrategy(conv *Conversation, intent *I
 	}
 	
 	switch intent.Name {
+	case IntentClarify:
+		return dm.ClarifyIntent(conv, intent)
+		
 	case "greeting":
 		return dm.handleGreeting(conv)
 		
@@ -802,6 +1803,9 @@ func (dm *DialogManager) determineResponseStrategy(conv *Conversation, intent *I
 	case "book_service":
 		return dm.handleBookService(conv)
 		
+	case IntentConfirmBooking:
+		return dm.handleConfirmBooking(conv)
+		
 	case "compare_options":
 		return dm.handleCompareOptions(conv)
 		
@@ -823,6 +1827,12 @@ func (dm *DialogManager) determineResponseStrategy(conv *Conversation, intent *I
 	case "thanks":
 		return dm.handleThanks(conv)
 		
+	case "save_vendor":
+		return dm.handleSaveVendor(conv)
+		
+	case IntentHandoff:
+		return dm.handleHandoff(conv, HandoffReasonRequested)
+		
 	default:
 		return dm.handleGeneralQuestion(conv, intent)
 	}
@@ -858,14 +1868,7 @@ func (dm *DialogManager) handleCreateEvent(conv *Conversation) *ResponseStrategy
 	missingSlots := dm.nlu.slotFiller.GetMissingRequiredSlots(conv.SlotValues, "create_event")
 	
 	if len(missingSlots) > 0 {
-		// Ask for the first missing slot
-		slot := missingSlots[0]
-		return &ResponseStrategy{
-			Type:      ResponseQuestion,
-			Template:  fmt.Sprintf("ask_%s", slot.Name),
-			NextState: StateGatheringInfo,
-			DataNeeded: []string{slot.Name},
-		}
+		return dm.AskForMissingSlots(conv, missingSlots)
 	}
 	
 	// All required slots filled - confirm before creating
@@ -886,6 +1889,42 @@ func (dm *DialogManager) handleCreateEvent(conv *Conversation) *ResponseStrategy
 	}
 }
 
+// BatchedSlotTemplates ask for two slots in one question, keyed by the
+// templates that would otherwise ask for each separately. Pairs without an
+// entry, such as the funeral prompts, are always asked one at a time.
+var BatchedSlotTemplates = map[[2]string]string{
+	{"ask_event_date", "ask_guest_count"}: "ask_event_date_and_guest_count",
+	{"ask_guest_count", "ask_location"}:   "ask_guest_count_and_location",
+	{"ask_event_date", "ask_location"}:    "ask_event_date_and_location",
+	{"ask_location", "ask_budget"}:        "ask_location_and_budget",
+}
+
+// AskForMissingSlots asks for the first missing slot. With batched questions
+// enabled for the conversation and the event type known, the next two slots
+// are asked together when a combined prompt exists for them.
+func (dm *DialogManager) AskForMissingSlots(conv *Conversation, missing []SlotDefinition) *ResponseStrategy {
+	first := missing[0]
+	strategy := &ResponseStrategy{
+		Type:       ResponseQuestion,
+		Template:   first.Template,
+		NextState:  StateGatheringInfo,
+		DataNeeded: []string{first.Name},
+	}
+	
+	if !conv.FeatureEnabled(FeatureBatchedQuestions) || len(missing) < 2 {
+		return strategy
+	}
+	if _, known := conv.SlotValues["event_type"]; !known {
+		return strategy
+	}
+	second := missing[1]
+	if template, ok := BatchedSlotTemplates[[2]string{first.Template, second.Template}]; ok {
+		strategy.Template = template
+		strategy.DataNeeded = []string{first.Name, second.Name}
+	}
+	return strategy
+}
+
 func (dm *DialogManager) handleFindVendor(conv *Conversation) *ResponseStrategy {
 	// Check if we know what type of vendor
 	vendorType, hasVendor := conv.SlotValues["vendor_type"]
@@ -933,6 +1972,46 @@ func (dm *DialogManager) handleFindVendor(conv *Conversation) *ResponseStrategy
 	}
 }
 
+// ApplyVendorSearchOutcome switches a vendor results response to say so
+// when the search came back empty or only matched after broadening
+func ApplyVendorSearchOutcome(strategy *ResponseStrategy, actionResults map[string]interface{}) *ResponseStrategy {
+	if strategy.Template != "vendor_results" {
+		return strategy
+	}
+	count, searched := actionResults["vendor_count"].(int)
+	if !searched {
+		return strategy
+	}
+	
+	adjusted := *strategy
+	switch {
+	case count == 0:
+		adjusted.Type = ResponseText
+		adjusted.Template = "no_vendors_found"
+		adjusted.NextState = StateGatheringInfo
+	case actionResults["broadened"] == true:
+		adjusted.Template = "vendor_results_broadened"
+	case actionResults["location_unresolved"] == true:
+		adjusted.Template = "vendor_results_unlocated"
+	}
+	return &adjusted
+}
+
+// ApplySaveVendorOutcome reports a failed save instead of confirming it
+func ApplySaveVendorOutcome(strategy *ResponseStrategy, actionResults map[string]interface{}) *ResponseStrategy {
+	if strategy.Template != "vendor_saved" {
+		return strategy
+	}
+	if _, saved := actionResults["saved_vendor"]; saved {
+		return strategy
+	}
+	
+	adjusted := *strategy
+	adjusted.Template = "vendor_save_failed"
+	adjusted.QuickReplies = nil
+	return &adjusted
+}
+
 func (dm *DialogManager) handleGetQuote(conv *Conversation) *ResponseStrategy {
 	// Check if we have a specific vendor in context
 	if vendorID, ok := conv.ShortTermMemory["selected_vendor_id"].(uuid.UUID); ok {
@@ -1009,7 +2088,7 @@ func (dm *DialogManager) handleBookService(conv *Conversation) *ResponseStrategy
 			},
 		},
 		QuickReplies: []QuickReply{
-			{Title: "Confirm Booking", Payload: "booking:confirm"},
+			{Title: "Confirm Booking", Payload: BookingConfirmPayload},
 			{Title: "Change Date", Payload: "booking:change_date"},
 			{Title: "Cancel", Payload: "booking:cancel"},
 		},
@@ -1137,6 +2216,15 @@ func (dm *DialogManager) handleViewPlan(conv *Conversation) *ResponseStrategy {
 }
 
 func (dm *DialogManager) handleUpdatePreference(conv *Conversation) *ResponseStrategy {
+	// A correction was already applied; carry on with the task it revised
+	// so required slots are re-derived from the corrected values
+	if conv.correctedThisTurn() {
+		if previous := PreviousTaskIntent(conv); previous != "" {
+			return dm.determineResponseStrategy(conv, &Intent{Name: previous, Confidence: 1.0})
+		}
+		return dm.handleCreateEvent(conv)
+	}
+	
 	return &ResponseStrategy{
 		Type:      ResponseText,
 		Template:  "what_to_change",
@@ -1174,6 +2262,28 @@ func (dm *DialogManager) handleThanks(conv *Conversation) *ResponseStrategy {
 	}
 }
 
+// handleSaveVendor saves the vendor named by a card's save_vendor postback
+func (dm *DialogManager) handleSaveVendor(conv *Conversation) *ResponseStrategy {
+	vendorID, _ := ParseSaveVendorPayload(conv.Messages[len(conv.Messages)-1].Content)
+	return &ResponseStrategy{
+		Type:      ResponseText,
+		Template:  "vendor_saved",
+		NextState: conv.ConversationState,
+		Actions: []ActionDefinition{
+			{
+				Type: "save_vendor",
+				Parameters: map[string]interface{}{
+					"vendor_id": vendorID,
+				},
+			},
+		},
+		QuickReplies: []QuickReply{
+			{Title: "Get Quote", Payload: fmt.Sprintf("quote_vendor:%s", vendorID)},
+			{Title: "Keep browsing", Payload: "intent:find_vendor"},
+		},
+	}
+}
+
 func (dm *DialogManager) handleGeneralQuestion(conv *Conversation, intent *Intent) *ResponseStrategy {
 	return &ResponseStrategy{
 		Type:      ResponseText,
@@ -1191,7 +2301,43 @@ func (dm *DialogManager) handleGeneralQuestion(conv *Conversation, intent *Inten
 	}
 }
 
+// ErrConversationNotFound is returned for an unknown conversation ID
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// ErrConversationForbidden is returned when a user sends a message to a
+// conversation that belongs to someone else
+var ErrConversationForbidden = errors.New("conversation belongs to another user")
+
+// ConversationStore persists dialog state between turns
+type ConversationStore interface {
+	SaveConversation(ctx context.Context, conv *Conversation) error
+	LoadConversation(ctx context.Context, convID uuid.UUID) (*Conversation, error)
+}
+
+// SetConversationStore sets where conversations are kept between turns
+func (dm *DialogManager) SetConversationStore(store ConversationStore) {
+	dm.conversations = store
+}
+
 func (dm *DialogManager) saveConversation(ctx context.Context, conv *Conversation) error {
+	if dm.conversations == nil {
+		return nil
+	}
+	return dm.conversations.SaveConversation(ctx, conv)
+}
+
+// PostgresConversationStore keeps dialog state in the conversations table
+type PostgresConversationStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresConversationStore creates a database-backed conversation store
+func NewPostgresConversationStore(db *pgxpool.Pool) *PostgresConversationStore {
+	return &PostgresConversationStore{db: db}
+}
+
+// SaveConversation upserts the conversation after a turn
+func (s *PostgresConversationStore) SaveConversation(ctx context.Context, conv *Conversation) error {
 	messagesJSON, _ := json.Marshal(conv.Messages)
 	slotsJSON, _ := json.Marshal(conv.SlotValues)
 	memoryJSON, _ := json.Marshal(conv.ShortTermMemory)
@@ -1213,11 +2359,9 @@ func (dm *DialogManager) saveConversation(ctx context.Context, conv *Conversatio
 			last_message_at = $14
 	`
 	
-	intentJSON, _ := json.Marshal(conv.CurrentIntent)
-	
-	_, err := dm.db.Exec(ctx, query,
+	_, err := s.db.Exec(ctx, query,
 		conv.ID, conv.UserID, conv.EventID, conv.SessionType,
-		intentJSON, conv.ConversationState, slotsJSON,
+		conv.CurrentIntent.Name, conv.ConversationState, slotsJSON,
 		messagesJSON, conv.TurnCount, memoryJSON,
 		conv.Language, conv.Channel, conv.StartedAt, conv.LastMessageAt,
 	)
@@ -1225,6 +2369,110 @@ func (dm *DialogManager) saveConversation(ctx context.Context, conv *Conversatio
 	return err
 }
 
+// LoadConversation reads a conversation back, including one started by the
+// welcome flow that has no dialog state yet
+func (s *PostgresConversationStore) LoadConversation(ctx context.Context, convID uuid.UUID) (*Conversation, error) {
+	query := `
+		SELECT id, user_id, event_id, session_type,
+		       COALESCE(current_intent, ''), COALESCE(conversation_state, 'welcome'), slot_values,
+		       messages, turn_count, short_term_memory,
+		       COALESCE(language, 'en'), COALESCE(channel, 'web'), started_at,
+		       COALESCE(last_message_at, started_at)
+		FROM conversations
+		WHERE id = $1
+	`
+	
+	var conv Conversation
+	var slotsJSON, messagesJSON, memoryJSON []byte
+	
+	err := s.db.QueryRow(ctx, query, convID).Scan(
+		&conv.ID, &conv.UserID, &conv.EventID, &conv.SessionType,
+		&conv.CurrentIntent.Name, &conv.ConversationState, &slotsJSON,
+		&messagesJSON, &conv.TurnCount, &memoryJSON,
+		&conv.Language, &conv.Channel, &conv.StartedAt, &conv.LastMessageAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	decodeConversationState(&conv, slotsJSON, messagesJSON, memoryJSON)
+	return &conv, nil
+}
+
+// decodeConversationState fills the JSON columns in, leaving empty maps
+// rather than nil where a column is missing or holds something else
+func decodeConversationState(conv *Conversation, slotsJSON, messagesJSON, memoryJSON []byte) {
+	json.Unmarshal(slotsJSON, &conv.SlotValues)
+	json.Unmarshal(messagesJSON, &conv.Messages)
+	json.Unmarshal(memoryJSON, &conv.ShortTermMemory)
+	if conv.SlotValues == nil {
+		conv.SlotValues = make(map[string]SlotValue)
+	}
+	if conv.ShortTermMemory == nil {
+		conv.ShortTermMemory = make(map[string]interface{})
+	}
+}
+
+// MemoryConversationStore keeps conversations in process. They go through
+// the same JSON encoding as the database columns, so a loaded conversation
+// matches what Postgres would give back.
+type MemoryConversationStore struct {
+	mu            sync.Mutex
+	conversations map[uuid.UUID]memoryConversation
+}
+
+type memoryConversation struct {
+	conversation                        Conversation
+	slotsJSON, messagesJSON, memoryJSON []byte
+}
+
+// NewMemoryConversationStore creates an empty in-process store
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{conversations: make(map[uuid.UUID]memoryConversation)}
+}
+
+// SaveConversation stores a copy of the conversation
+func (s *MemoryConversationStore) SaveConversation(ctx context.Context, conv *Conversation) error {
+	slotsJSON, err := json.Marshal(conv.SlotValues)
+	if err != nil {
+		return err
+	}
+	messagesJSON, err := json.Marshal(conv.Messages)
+	if err != nil {
+		return err
+	}
+	memoryJSON, err := json.Marshal(conv.ShortTermMemory)
+	if err != nil {
+		return err
+	}
+	
+	stored := memoryConversation{conversation: *conv, slotsJSON: slotsJSON, messagesJSON: messagesJSON, memoryJSON: memoryJSON}
+	stored.conversation.SlotValues, stored.conversation.Messages, stored.conversation.ShortTermMemory = nil, nil, nil
+	stored.conversation.CurrentIntent = Intent{Name: conv.CurrentIntent.Name}
+	
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conversations[conv.ID] = stored
+	return nil
+}
+
+// LoadConversation returns a copy of a stored conversation
+func (s *MemoryConversationStore) LoadConversation(ctx context.Context, convID uuid.UUID) (*Conversation, error) {
+	s.mu.Lock()
+	stored, ok := s.conversations[convID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrConversationNotFound
+	}
+	
+	conv := stored.conversation
+	decodeConversationState(&conv, stored.slotsJSON, stored.messagesJSON, stored.memoryJSON)
+	return &conv, nil
+}
+
 // =============================================================================
 // 2.4 RESPONSE GENERATION
 // =============================================================================
@@ -1233,6 +2481,10 @@ func (dm *DialogManager) saveConversation(ctx context.Context, conv *Conversatio
 type ResponseGenerator struct {
 	templates map[string]ResponseTemplate
 	db        *pgxpool.Pool
+	
+	// Breaks ties between equally fresh variations
+	rngMu sync.Mutex
+	rng   *rand.Rand
 }
 
 type ResponseTemplate struct {
@@ -1293,6 +2545,54 @@ var ResponseTemplates = map[string]ResponseTemplate{
 			"What's your approximate budget for this event? Don't worry, you can always adjust this later.",
 		},
 	},
+	"ask_event_date_and_guest_count": {
+		Name: "ask_event_date_and_guest_count",
+		Variations: []string{
+			"When is your {event_type}, and roughly how many guests are you expecting?",
+		},
+	},
+	"ask_guest_count_and_location": {
+		Name: "ask_guest_count_and_location",
+		Variations: []string{
+			"How many guests are you expecting, and which city or area will your {event_type} be in?",
+		},
+	},
+	"ask_event_date_and_location": {
+		Name: "ask_event_date_and_location",
+		Variations: []string{
+			"When and where will your {event_type} take place? A rough date and the city are enough for now.",
+		},
+	},
+	"ask_location_and_budget": {
+		Name: "ask_location_and_budget",
+		Variations: []string{
+			"Where will your {event_type} take place, and do you have a budget in mind? (You can skip the budget if you prefer)",
+		},
+	},
+	"ask_event_date_funeral": {
+		Name: "ask_event_date_funeral",
+		Variations: []string{
+			"I'm sorry for your loss. When will the service be held? An approximate date is fine for now.",
+		},
+	},
+	"ask_location_funeral": {
+		Name: "ask_location_funeral",
+		Variations: []string{
+			"Where will the service take place? The city or area is enough for me to find vendors nearby.",
+		},
+	},
+	"ask_guest_count_funeral": {
+		Name: "ask_guest_count_funeral",
+		Variations: []string{
+			"If you have a sense of it, roughly how many people do you expect to attend? It's fine to skip this.",
+		},
+	},
+	"ask_budget_corporate": {
+		Name: "ask_budget_corporate",
+		Variations: []string{
+			"What budget has been approved for this {event_type}? I'll only suggest vendors that fit it.",
+		},
+	},
 	"confirm_event_details": {
 		Name: "confirm_event_details",
 		Variations: []string{
@@ -1313,6 +2613,20 @@ var ResponseTemplates = map[string]ResponseTemplate{
 			"Here are the best {vendor_type}s I found for your {event_type}:",
 		},
 	},
+	"vendor_results_broadened": {
+		Name: "vendor_results_broadened",
+		Variations: []string{
+			"I couldn't find an exact match, so I broadened the search ({broadened_by}). Here are {vendor_count} {vendor_type}s you might like:",
+			"No exact matches yet, but widening the search ({broadened_by}) turned up {vendor_count} options:",
+		},
+	},
+	"vendor_results_unlocated": {
+		Name: "vendor_results_unlocated",
+		Variations: []string{
+			"I couldn't place {location} on the map, so these {vendor_count} {vendor_type}s are matched by city name rather than distance:",
+			"Here are {vendor_count} {vendor_type}s listed under {location}. I couldn't pinpoint the area, so they may not all be nearby:",
+		},
+	},
 	"no_vendors_found": {
 		Name: "no_vendors_found",
 		Variations: []string{
@@ -1320,50 +2634,201 @@ var ResponseTemplates = map[string]ResponseTemplate{
 			"No exact matches found. Should I show you similar options or adjust the search?",
 		},
 	},
+	"vendor_saved": {
+		Name: "vendor_saved",
+		Variations: []string{
+			"Saved {vendor_name} to your list. Just mention your saved {vendor_name} whenever you want to pick up where you left off.",
+			"Done, {vendor_name} is saved. Ask about your saved vendors any time and I'll bring them back up.",
+		},
+	},
+	"vendor_save_failed": {
+		Name: "vendor_save_failed",
+		Variations: []string{
+			"Sorry, I couldn't save that vendor just now. Please try again in a moment.",
+		},
+	},
 	"availability_result": {
 		Name: "availability_result",
 		Variations: []string{
 			"{vendor_name} is {availability_status} on {date}. {additional_info}",
 		},
 	},
-	"confirm_booking": {
-		Name: "confirm_booking",
+	"general_answer": {
+		Name: "general_answer",
 		Variations: []string{
-			"Ready to book {service_name} with {vendor_name} for {date}?\n\n💰 Total: {price}\n\nShall I proceed with the booking?",
+			"{kb_answer}",
+			"Here's what I found: {kb_answer}",
 		},
 	},
-	"booking_confirmed": {
-		Name: "booking_confirmed",
+	"handoff_queued": {
+		Name: "handoff_queued",
 		Variations: []string{
-			"🎉 Excellent! Your booking with {vendor_name} is confirmed!\n\n**Booking Details:**\n📅 Date: {date}\n💰 Amount: {price}\n📧 Confirmation sent to your email\n\nWhat else can I help you with?",
+			"I've passed this conversation to our support team. Someone will pick it up here shortly.",
+			"I'm bringing in a member of our team to help. They'll reply here as soon as they can.",
 		},
 	},
-	"you_are_welcome": {
-		Name: "you_are_welcome",
+	"kb_no_answer": {
+		Name: "kb_no_answer",
 		Variations: []string{
-			"You're welcome! Is there anything else I can help you with?",
-			"Happy to help! Let me know if you need anything else. 😊",
+			"I'm not sure about that one. Would you like me to connect you with someone from our team?",
+			"I couldn't find an answer to that. I can put you in touch with a member of our team if you'd like.",
 		},
 	},
-	"no_event_yet": {
-		Name: "no_event_yet",
+	"clarify_intent": {
+		Name: "clarify_intent",
 		Variations: []string{
-			"You haven't started planning an event yet. Would you like to create one?",
+			"I want to make sure I help with the right thing. Did you mean:",
+			"Sorry, I'm not quite sure what you need. Did you mean:",
 		},
 	},
-	"general_pricing": {
-		Name: "general_pricing",
+	"confirm_intent": {
+		Name: "confirm_intent",
 		Variations: []string{
-			"Here are typical price ranges for {event_type} services in {location}:\n\n{pricing_breakdown}\n\nWould you like specific quotes from vendors?",
+			"Just to make sure I understood you correctly - would you like me to go ahead with that?",
+			"I want to get this right. Should I go ahead with that?",
 		},
 	},
-}
-
-func NewResponseGenerator(db *pgxpool.Pool) *ResponseGenerator {
-	return &ResponseGenerator{
+	"confirm_booking": {
+		Name: "confirm_booking",
+		Variations: []string{
+			"Ready to book {service_name} with {vendor_name} for {date}?\n\n💰 Total: {price}\n\nShall I proceed with the booking?",
+		},
+	},
+	"booking_confirmed": {
+		Name: "booking_confirmed",
+		Variations: []string{
+			"🎉 Excellent! Your booking with {vendor_name} is confirmed!\n\n**Booking Details:**\n🔖 Reference: {booking_reference}\n📅 Date: {date}\n💰 Amount: {price}\n📧 Confirmation sent to your email\n\nWhat else can I help you with?",
+		},
+	},
+	"booking_draft_missing": {
+		Name: "booking_draft_missing",
+		Variations: []string{
+			"I don't have a booking ready to confirm. Pick a vendor and a date and I'll set one up for you.",
+		},
+	},
+	"booking_sign_in_required": {
+		Name: "booking_sign_in_required",
+		Variations: []string{
+			"Please sign in to confirm this booking. I'll keep it ready for you, so you can confirm as soon as you're signed in.",
+		},
+	},
+	"booking_failed": {
+		Name: "booking_failed",
+		Variations: []string{
+			"Sorry, I couldn't complete that booking just now. Would you like to try again or talk to someone from our team?",
+		},
+	},
+	"you_are_welcome": {
+		Name: "you_are_welcome",
+		Variations: []string{
+			"You're welcome! Is there anything else I can help you with?",
+			"Happy to help! Let me know if you need anything else. 😊",
+		},
+	},
+	"no_event_yet": {
+		Name: "no_event_yet",
+		Variations: []string{
+			"You haven't started planning an event yet. Would you like to create one?",
+		},
+	},
+	"general_pricing": {
+		Name: "general_pricing",
+		Variations: []string{
+			"Here are typical price ranges for {event_type} services in {location}:\n\n{pricing_breakdown}\n\nWould you like specific quotes from vendors?",
+		},
+	},
+	"upgrade_required": {
+		Name: "upgrade_required",
+		Variations: []string{
+			"You've reached today's free limit for that. ✨ Upgrade to Premium for unlimited vendor comparisons, priority matching and price negotiation help - or pick it up again tomorrow when your limit resets.",
+		},
+	},
+}
+
+func NewResponseGenerator(db *pgxpool.Pool) *ResponseGenerator {
+	return &ResponseGenerator{
 		templates: ResponseTemplates,
 		db:        db,
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// variationResetTurns is how many turns a template can go unused before its
+// variation history is forgotten and any variation may open again
+const variationResetTurns = 10
+
+const (
+	memoryRecentVariations = "recent_variations:"      // + template name
+	memoryVariationTurn    = "recent_variations_turn:" // + template name
+)
+
+// SetRandSeed makes variation tie-breaks reproducible
+func (rg *ResponseGenerator) SetRandSeed(seed int64) {
+	rg.rngMu.Lock()
+	defer rg.rngMu.Unlock()
+	rg.rng = rand.New(rand.NewSource(seed))
+}
+
+// selectVariation picks the least recently used variation of a template for
+// this conversation, breaking ties at random, so a template never repeats a
+// line until every variation has been used. Indices are kept in short-term
+// memory, least recent first.
+func (rg *ResponseGenerator) selectVariation(conv *Conversation, name string, template ResponseTemplate) string {
+	if len(template.Variations) == 1 {
+		return template.Variations[0]
+	}
+	if conv.ShortTermMemory == nil {
+		conv.ShortTermMemory = make(map[string]interface{})
+	}
+	
+	var recent []int
+	turnKey := memoryVariationTurn + name
+	if _, ok := conv.ShortTermMemory[turnKey]; ok && conv.TurnCount-memoryInt(conv.ShortTermMemory, turnKey) < variationResetTurns {
+		recent = memoryInts(conv.ShortTermMemory, memoryRecentVariations+name)
+	}
+	
+	used := make(map[int]bool, len(recent))
+	valid := recent[:0]
+	for _, i := range recent {
+		if i >= 0 && i < len(template.Variations) && !used[i] {
+			used[i] = true
+			valid = append(valid, i)
+		}
+	}
+	recent = valid
+	
+	var unused []int
+	for i := range template.Variations {
+		if !used[i] {
+			unused = append(unused, i)
+		}
 	}
+	
+	var choice int
+	if len(unused) > 0 {
+		choice = unused[rg.intn(len(unused))]
+	} else {
+		choice = recent[0]
+	}
+	
+	next := make([]int, 0, len(template.Variations))
+	for _, i := range recent {
+		if i != choice {
+			next = append(next, i)
+		}
+	}
+	conv.ShortTermMemory[memoryRecentVariations+name] = append(next, choice)
+	conv.ShortTermMemory[turnKey] = conv.TurnCount
+	return template.Variations[choice]
+}
+
+func (rg *ResponseGenerator) intn(n int) int {
+	rg.rngMu.Lock()
+	defer rg.rngMu.Unlock()
+	if rg.rng == nil {
+		rg.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rg.rng.Intn(n)
 }
 
 func (rg *ResponseGenerator) GenerateResponse(ctx context.Context, conv *Conversation, strategy *ResponseStrategy, actionResults map[string]interface{}) (*Message, error) {
@@ -1379,8 +2844,7 @@ func (rg *ResponseGenerator) GenerateResponse(ctx context.Context, conv *Convers
 		}
 	}
 	
-	// Select a variation (could use more sophisticated selection)
-	variation := template.Variations[conv.TurnCount%len(template.Variations)]
+	variation := rg.selectVariation(conv, strategy.Template, template)
 	
 	// Fill in variables
 	responseText := rg.fillVariables(variation, conv.SlotValues, actionResults)
@@ -1408,13 +2872,22 @@ func (rg *ResponseGenerator) GenerateResponse(ctx context.Context, conv *Convers
 	return response, nil
 }
 
+// slotText renders a slot value for a response, writing resolved dates the
+// way a user would, e.g. "12 December 2026"
+func slotText(value interface{}) string {
+	if date, ok := value.(time.Time); ok {
+		return date.Format("2 January 2006")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
 func (rg *ResponseGenerator) fillVariables(template string, slots map[string]SlotValue, actionResults map[string]interface{}) string {
 	result := template
 	
 	// Fill from slots
 	for name, slot := range slots {
 		placeholder := fmt.Sprintf("{%s}", name)
-		result = strings.ReplaceAll(result, placeholder, fmt.Sprintf("%v", slot.Value))
+		result = strings.ReplaceAll(result, placeholder, slotText(slot.Value))
 	}
 	
 	// Fill from action results
@@ -1448,10 +2921,12 @@ func (rg *ResponseGenerator) vendorsToCards(vendors []VendorResult) []Card {
 				{Type: "postback", Title: "View Profile", Payload: fmt.Sprintf("view_vendor:%s", v.VendorID)},
 				{Type: "postback", Title: "Get Quote", Payload: fmt.Sprintf("quote_vendor:%s", v.VendorID), Style: "primary"},
 				{Type: "postback", Title: "Book Now", Payload: fmt.Sprintf("book_vendor:%s", v.VendorID), Style: "primary"},
+				{Type: "postback", Title: "Save", Payload: saveVendorPrefix + v.VendorID.String()},
 			},
 			Metadata: map[string]interface{}{
 				"vendor_id":  v.VendorID,
 				"service_id": v.ServiceID,
+				"broadened":  v.Broadened,
 			},
 		}
 		cards = append(cards, card)
@@ -1485,8 +2960,11 @@ type ActionExecutor struct {
 	db              *pgxpool.Pool
 	cache           *redis.Client
 	vendorService   *VendorService
-	bookingService  *BookingService
+	bookingService  BookingService
 	pricingService  *PricingService
+	savedVendors    SavedVendorStore
+	knowledgeBase   KnowledgeBase
+	availability    AvailabilityChecker
 }
 
 type VendorResult struct {
@@ -1500,6 +2978,9 @@ type VendorResult struct {
 	Rating           float64
 	ReviewCount      int
 	MatchScore       float64
+	Broadened        bool     // found only after relaxing the user's criteria
+	GeoRestricted    bool     // filtered by distance from the search location
+	DistanceKm       *float64 // from the search location, when known
 }
 
 type VendorComparison struct {
@@ -1515,12 +2996,19 @@ func (ae *ActionExecutor) ExecuteActions(ctx context.Context, actions []ActionDe
 	for _, action := range actions {
 		switch action.Type {
 		case "search_vendors":
-			vendors, err := ae.searchVendors(ctx, action.Parameters)
+			vendors, matched, err := SearchVendorsWithFallback(ctx, VendorCriteriaFromParams(action.Parameters), ae.searchVendors)
 			if err != nil {
 				continue
 			}
 			results["vendors"] = vendors
 			results["vendor_count"] = len(vendors)
+			if relaxed := matched.Relaxations(); len(vendors) > 0 && len(relaxed) > 0 {
+				results["broadened"] = true
+				results["broadened_by"] = strings.Join(relaxed, ", ")
+			}
+			if len(vendors) > 0 && matched.FiltersLocation() && !vendors[0].GeoRestricted {
+				results["location_unresolved"] = true
+			}
 			// Store in conversation memory
 			conv.ShortTermMemory["vendor_results"] = vendors
 			
@@ -1546,6 +3034,23 @@ func (ae *ActionExecutor) ExecuteActions(ctx context.Context, actions []ActionDe
 			}
 			results["booking"] = booking
 			results["price"] = booking.TotalAmount
+			RememberBookingDraft(conv, *booking)
+			
+		case "confirm_booking":
+			confirmed, draft, err := ae.confirmBooking(ctx, conv)
+			if errors.Is(err, ErrNoBookingDraft) {
+				results["booking_draft_missing"] = true
+				continue
+			}
+			if errors.Is(err, ErrBookingSignInRequired) {
+				results["booking_sign_in_required"] = true
+				continue
+			}
+			if err != nil {
+				results["booking_failed"] = true
+				continue
+			}
+			bookingConfirmedResults(results, conv, confirmed, draft)
 			
 		case "generate_comparison":
 			comparison, err := ae.generateComparison(ctx, action.Parameters)
@@ -1575,15 +3080,250 @@ func (ae *ActionExecutor) ExecuteActions(ctx context.Context, actions []ActionDe
 			}
 			results["vendors"] = recs
 			results["vendor_count"] = len(recs)
+			
+		case "save_vendor":
+			vendorID, _ := action.Parameters["vendor_id"].(uuid.UUID)
+			saved, err := SaveVendor(ctx, ae.savedVendors, conv, vendorID)
+			if err != nil {
+				continue
+			}
+			results["saved_vendor"] = saved
+			results["vendor_name"] = saved.Label()
+			
+		case "search_knowledge_base":
+			query, _ := action.Parameters["query"].(string)
+			match, err := SearchKnowledgeBase(ctx, ae.knowledgeBase, query, DefaultKBMinRank)
+			if err != nil || match == nil {
+				results["kb_no_answer"] = true
+				continue
+			}
+			results["kb_answer"] = match.Snippet
+			results["kb_article"] = match.Title
 		}
 	}
 	
 	return results, nil
 }
 
-func (ae *ActionExecutor) searchVendors(ctx context.Context, params map[string]interface{}) ([]VendorResult, error) {
-	vendorType := params["vendor_type"].(string)
-	location := params["location"]
+// VendorSearchCriteria describes a vendor search. With every relaxation
+// flag unset it is the strict search: the category named by VendorType,
+// vendors in Location, verified vendors only.
+type VendorSearchCriteria struct {
+	VendorType        string
+	Location          string
+	Coordinates       *GeoPoint // Location as coordinates, when the user shared them
+	RadiusKm          float64   // distance filter around the location; 0 uses the default
+	AnyLocation       bool // widened: location no longer filters
+	IncludeUnverified bool // widened: unverified vendors are included
+	FuzzyCategory     bool // widened: related category and service names match
+}
+
+// VendorCriteriaFromParams builds strict criteria from search_vendors
+// action parameters
+func VendorCriteriaFromParams(params map[string]interface{}) VendorSearchCriteria {
+	criteria := VendorSearchCriteria{}
+	if vendorType, ok := params["vendor_type"]; ok && vendorType != nil {
+		criteria.VendorType = fmt.Sprintf("%v", vendorType)
+	}
+	switch location := params["location"].(type) {
+	case nil:
+	case GeoPoint:
+		criteria.Coordinates = &location
+		criteria.Location = location.String()
+	case *GeoPoint:
+		criteria.Coordinates = location
+		criteria.Location = location.String()
+	default:
+		criteria.Location = strings.TrimSpace(fmt.Sprintf("%v", location))
+		if point, ok := ParseGeoPoint(criteria.Location); ok {
+			criteria.Coordinates = point
+		}
+	}
+	return criteria
+}
+
+// FiltersLocation reports whether the search is limited to the location
+func (c VendorSearchCriteria) FiltersLocation() bool {
+	return (c.Location != "" || c.Coordinates != nil) && !c.AnyLocation
+}
+
+// DefaultVendorSearchRadiusKm is how far from a resolved location vendors
+// are searched
+const DefaultVendorSearchRadiusKm = 50.0
+
+// proximityWeight is the share of a geo-restricted vendor's rank that comes
+// from closeness rather than rating
+const proximityWeight = 0.5
+
+// GeoPoint is a latitude/longitude pair
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (p GeoPoint) String() string {
+	return fmt.Sprintf("%.6f,%.6f", p.Latitude, p.Longitude)
+}
+
+// ParseGeoPoint reads "latitude,longitude", the form clients use when the
+// user shares their location
+func ParseGeoPoint(s string) (*GeoPoint, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return nil, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, false
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return nil, false
+	}
+	return &GeoPoint{Latitude: lat, Longitude: lng}, true
+}
+
+// RankVendorsByProximity orders geo-restricted results by a blend of
+// rating and closeness, so a nearby vendor beats an equally rated one
+// across town. Vendors without a known distance rank as if at the edge of
+// the radius.
+func RankVendorsByProximity(vendors []VendorResult, radiusKm float64) {
+	if radiusKm <= 0 {
+		radiusKm = DefaultVendorSearchRadiusKm
+	}
+	score := func(v VendorResult) float64 {
+		closeness := 0.0
+		if v.DistanceKm != nil {
+			closeness = 1 - math.Min(*v.DistanceKm/radiusKm, 1)
+		}
+		return (1-proximityWeight)*v.Rating/5 + proximityWeight*closeness
+	}
+	sort.SliceStable(vendors, func(i, j int) bool {
+		return score(vendors[i]) > score(vendors[j])
+	})
+}
+
+// Broadenings returns progressively relaxed versions of the criteria,
+// widening the location first, then admitting unverified vendors, then
+// matching similar categories
+func (c VendorSearchCriteria) Broadenings() []VendorSearchCriteria {
+	var steps []VendorSearchCriteria
+	next := c
+	if next.Location != "" && !next.AnyLocation {
+		next.AnyLocation = true
+		steps = append(steps, next)
+	}
+	if !next.IncludeUnverified {
+		next.IncludeUnverified = true
+		steps = append(steps, next)
+	}
+	if !next.FuzzyCategory {
+		next.FuzzyCategory = true
+		steps = append(steps, next)
+	}
+	return steps
+}
+
+// Relaxations names how the criteria differ from the strict search, for
+// labelling broadened results
+func (c VendorSearchCriteria) Relaxations() []string {
+	var relaxed []string
+	if c.AnyLocation && c.Location != "" {
+		relaxed = append(relaxed, "beyond "+c.Location)
+	}
+	if c.IncludeUnverified {
+		relaxed = append(relaxed, "including unverified vendors")
+	}
+	if c.FuzzyCategory {
+		relaxed = append(relaxed, "similar services")
+	}
+	return relaxed
+}
+
+// VendorSearchFunc runs a single vendor search
+type VendorSearchFunc func(ctx context.Context, criteria VendorSearchCriteria) ([]VendorResult, error)
+
+// SearchVendorsWithFallback runs the strict search and, while it comes back
+// empty, each broadening in turn. It returns the first non-empty results,
+// marked as broadened when relaxed criteria found them, along with the
+// criteria that matched.
+func SearchVendorsWithFallback(ctx context.Context, strict VendorSearchCriteria, search VendorSearchFunc) ([]VendorResult, VendorSearchCriteria, error) {
+	vendors, err := search(ctx, strict)
+	if err != nil || len(vendors) > 0 {
+		return vendors, strict, err
+	}
+	
+	for _, criteria := range strict.Broadenings() {
+		vendors, err := search(ctx, criteria)
+		if err != nil {
+			return nil, criteria, err
+		}
+		if len(vendors) == 0 {
+			continue
+		}
+		for i := range vendors {
+			vendors[i].Broadened = true
+		}
+		return vendors, criteria, nil
+	}
+	return nil, strict, nil
+}
+
+// CategorySearchPatterns returns LIKE patterns for a vendor type. Fuzzy
+// patterns match on the word stem so "photographer" also finds
+// "Photography" and "caterer" finds "Catering".
+func CategorySearchPatterns(vendorType string, fuzzy bool) []string {
+	term := strings.ToLower(strings.TrimSpace(vendorType))
+	patterns := []string{"%" + term + "%"}
+	if !fuzzy {
+		return patterns
+	}
+	for _, suffix := range []string{"ers", "er", "ors", "or", "ists", "ist", "s"} {
+		if stem := strings.TrimSuffix(term, suffix); stem != term && len(stem) >= 3 {
+			return append(patterns, "%"+stem+"%")
+		}
+	}
+	return patterns
+}
+
+// geocodeCity looks a place name up in the cities table
+func (ae *ActionExecutor) geocodeCity(ctx context.Context, name string) *GeoPoint {
+	var point GeoPoint
+	err := ae.db.QueryRow(ctx, `
+		SELECT latitude, longitude FROM cities
+		WHERE LOWER(name) = LOWER($1)
+		LIMIT 1
+	`, strings.TrimSpace(name)).Scan(&point.Latitude, &point.Longitude)
+	if err != nil {
+		return nil
+	}
+	return &point
+}
+
+// searchVendors runs one vendor search. A location that resolves to
+// coordinates, given directly or through the cities table, filters by
+// distance and ranks partly by closeness; an unknown place name falls back
+// to matching the vendor's city or state.
+func (ae *ActionExecutor) searchVendors(ctx context.Context, criteria VendorSearchCriteria) ([]VendorResult, error) {
+	var point *GeoPoint
+	if criteria.FiltersLocation() {
+		point = criteria.Coordinates
+		if point == nil {
+			point = ae.geocodeCity(ctx, criteria.Location)
+		}
+	}
+	radiusKm := criteria.RadiusKm
+	if radiusKm <= 0 {
+		radiusKm = DefaultVendorSearchRadiusKm
+	}
+	
+	patterns := CategorySearchPatterns(criteria.VendorType, criteria.FuzzyCategory)
+	args := []interface{}{patterns}
+	distance := "NULL::float8"
+	if point != nil {
+		args = append(args, point.Longitude, point.Latitude, radiusKm*1000)
+		distance = "ST_Distance(v.service_location, ST_MakePoint($2, $3)::geography) / 1000"
+	}
 	
 	query := `
 		SELECT 
@@ -1595,18 +3335,42 @@ func (ae *ActionExecutor) searchVendors(ctx context.Context, params map[string]i
 			s.short_description,
 			s.base_price,
 			v.rating_average,
-			v.rating_count
+			v.rating_count,
+			` + distance + ` as distance_km
 		FROM services s
 		JOIN vendors v ON v.id = s.vendor_id
 		JOIN service_categories sc ON sc.id = s.category_id
-		WHERE LOWER(sc.name) LIKE $1
-		  AND v.is_active = TRUE
+		WHERE v.is_active = TRUE
 		  AND s.is_available = TRUE
+	`
+	if criteria.FuzzyCategory {
+		query += ` AND (LOWER(sc.name) LIKE ANY($1) OR LOWER(sc.slug) LIKE ANY($1) OR LOWER(s.name) LIKE ANY($1))`
+	} else {
+		query += ` AND LOWER(sc.name) LIKE ANY($1)`
+	}
+	if !criteria.IncludeUnverified {
+		query += ` AND v.is_verified = TRUE`
+	}
+	switch {
+	case point != nil:
+		query += ` AND (v.covers_nationwide OR ST_DWithin(v.service_location, ST_MakePoint($2, $3)::geography, $4))`
+		// Take a wider candidate set and rank it by rating and distance below
+		query += `
+		ORDER BY distance_km ASC NULLS LAST, v.rating_average DESC
+		LIMIT 30
+	`
+	case criteria.FiltersLocation():
+		args = append(args, criteria.Location)
+		query += fmt.Sprintf(` AND (v.covers_nationwide OR LOWER(v.city) = LOWER($%d) OR LOWER(v.state) = LOWER($%d))`, len(args), len(args))
+		fallthrough
+	default:
+		query += `
 		ORDER BY v.rating_average DESC, v.rating_count DESC
 		LIMIT 10
 	`
+	}
 	
-	rows, err := ae.db.Query(ctx, query, "%"+vendorType+"%")
+	rows, err := ae.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1616,14 +3380,19 @@ func (ae *ActionExecutor) searchVendors(ctx context.Context, params map[string]i
 	for rows.Next() {
 		var v VendorResult
 		if err := rows.Scan(&v.VendorID, &v.VendorName, &v.ServiceID, &v.ServiceName,
-			&v.ImageURL, &v.ShortDescription, &v.Price, &v.Rating, &v.ReviewCount); err != nil {
+			&v.ImageURL, &v.ShortDescription, &v.Price, &v.Rating, &v.ReviewCount, &v.DistanceKm); err != nil {
 			continue
 		}
+		v.GeoRestricted = point != nil
 		vendors = append(vendors, v)
 	}
 	
-	_ = location // Would use for geo filtering
-	
+	if point != nil {
+		RankVendorsByProximity(vendors, radiusKm)
+		if len(vendors) > 10 {
+			vendors = vendors[:10]
+		}
+	}
 	return vendors, nil
 }
 
@@ -1658,16 +3427,43 @@ func (ae *ActionExecutor) getVendorQuote(ctx context.Context, params map[string]
 
 func (ae *ActionExecutor) checkAvailability(ctx context.Context, params map[string]interface{}) (string, string, error) {
 	vendorID := params["vendor_id"].(uuid.UUID)
-	date := params["date"]
+	if ae.availability == nil {
+		return ae.checkBookingCalendar(ctx, vendorID, params["date"])
+	}
+	
+	// Ask about the whole day; the booking service narrows it to the
+	// vendor's working hours
+	var day time.Time
+	switch v := params["date"].(type) {
+	case time.Time:
+		day = time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, v.Location())
+	case string:
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid date %q: %w", v, err)
+		}
+		day = parsed
+	default:
+		return "", "", errors.New("no date to check")
+	}
 	
-	// Check booking calendar
+	result, err := ae.availability.CheckAvailability(ctx, vendorID, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return "", "", err
+	}
+	status, message := DescribeAvailability(result)
+	return status, message, nil
+}
+
+// checkBookingCalendar counts the vendor's bookings on a date against their
+// max concurrent bookings, for when no availability checker is set
+func (ae *ActionExecutor) checkBookingCalendar(ctx context.Context, vendorID uuid.UUID, date interface{}) (string, string, error) {
 	var bookingCount int
 	ae.db.QueryRow(ctx, `
 		SELECT COUNT(*) FROM bookings
 		WHERE vendor_id = $1 AND scheduled_date = $2 AND status NOT IN ('cancelled')
 	`, vendorID, date).Scan(&bookingCount)
 	
-	// Get vendor's max concurrent bookings
 	var maxBookings int
 	ae.db.QueryRow(ctx, `SELECT max_concurrent_bookings FROM vendors WHERE id = $1`, vendorID).Scan(&maxBookings)
 	
@@ -1681,10 +3477,10 @@ func (ae *ActionExecutor) checkAvailability(ctx context.Context, params map[stri
 }
 
 type BookingDraft struct {
-	VendorID    uuid.UUID
-	ServiceID   uuid.UUID
-	Date        time.Time
-	TotalAmount float64
+	VendorID    uuid.UUID `json:"vendor_id"`
+	ServiceID   uuid.UUID `json:"service_id"`
+	Date        time.Time `json:"date"`
+	TotalAmount float64   `json:"total_amount"`
 }
 
 func (ae *ActionExecutor) prepareBooking(ctx context.Context, params map[string]interface{}) (*BookingDraft, error) {
@@ -1776,9 +3572,8 @@ func (ae *ActionExecutor) loadEventPlan(ctx context.Context, params map[string]i
 
 func (ae *ActionExecutor) getPersonalizedRecommendations(ctx context.Context, params map[string]interface{}) ([]VendorResult, error) {
 	// Get top-rated vendors for the event type
-	return ae.searchVendors(ctx, map[string]interface{}{
-		"vendor_type": "photographer", // Default to common service
-		"limit":       5,
+	return ae.searchVendors(ctx, VendorSearchCriteria{
+		VendorType: "photographer", // Default to common service
 	})
 }
 
@@ -1792,67 +3587,1719 @@ type MemoryManager struct {
 	db    *pgxpool.Pool
 }
 
-// ContextManager manages conversation context
-type ContextManager struct {
-	db    *pgxpool.Pool
-	cache *redis.Client
-}
-
-// VendorService placeholder
-type VendorService struct{}
+const (
+	// summarizeAfterTurns is the turn count past which older turns are
+	// condensed into the running summary
+	summarizeAfterTurns = 20
+	// summaryRecentMessages is how many of the latest messages stay verbatim
+	summaryRecentMessages = 10
+	// summaryMaxLines caps the running summary so it cannot grow unbounded;
+	// see compactSummary for what gives way first
+	summaryMaxLines = 30
+	
+	memoryConversationSummary = "conversation_summary"
+	memorySummarizedThrough   = "summarized_through"
+)
 
-// BookingService placeholder
-type BookingService struct{}
+// SummarizeConversation condenses turns older than the recent window into a
+// running summary kept in short-term memory. It only runs once the
+// conversation is past summarizeAfterTurns and returns whether anything new
+// was summarized.
+func (mm *MemoryManager) SummarizeConversation(conv *Conversation) bool {
+	if conv.TurnCount < summarizeAfterTurns || len(conv.Messages) <= summaryRecentMessages {
+		return false
+	}
+	if conv.ShortTermMemory == nil {
+		conv.ShortTermMemory = make(map[string]interface{})
+	}
+	
+	from := memoryInt(conv.ShortTermMemory, memorySummarizedThrough)
+	through := len(conv.Messages) - summaryRecentMessages
+	if from >= through {
+		return false
+	}
+	
+	var lines []string
+	if existing, _ := conv.ShortTermMemory[memoryConversationSummary].(string); existing != "" {
+		lines = strings.Split(existing, "\n")
+	}
+	for _, msg := range conv.Messages[from:through] {
+		if line := summarizeMessage(msg); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	lines = compactSummary(lines)
+	
+	conv.ShortTermMemory[memoryConversationSummary] = strings.Join(lines, "\n")
+	conv.ShortTermMemory[memorySummarizedThrough] = through
+	return true
+}
 
-// PricingService placeholder
-type PricingService struct{}
+// compactSummary brings a summary over summaryMaxLines back under it without
+// losing its earliest decisions. Decisions of the same intent are merged
+// into the line of the first, in order and without repeats. If that is not
+// enough, the oldest free-text lines go, and only then the middle of the
+// summary, keeping its opening and latest lines.
+func compactSummary(lines []string) []string {
+	if len(lines) <= summaryMaxLines {
+		return lines
+	}
+	lines = mergeDecisionLines(lines)
+	
+	if excess := len(lines) - summaryMaxLines; excess > 0 {
+		kept := make([]string, 0, summaryMaxLines)
+		for _, line := range lines {
+			if excess > 0 && !isDecisionLine(line) {
+				excess--
+				continue
+			}
+			kept = append(kept, line)
+		}
+		lines = kept
+	}
+	
+	if len(lines) > summaryMaxLines {
+		head := summaryMaxLines / 2
+		lines = append(lines[:head:head], lines[len(lines)-(summaryMaxLines-head):]...)
+	}
+	return lines
+}
 
-/*
-================================================================================
-SECTION 3: API SPECIFICATION
-================================================================================
-*/
+// mergeDecisionLines folds every decision line into the first line of its
+// intent, keeping each detail once in the order it was first given
+func mergeDecisionLines(lines []string) []string {
+	merged := make([]string, 0, len(lines))
+	at := make(map[string]int)           // intent -> index in merged
+	details := make(map[string][]string) // intent -> its details
+	seen := make(map[string]bool)        // intent + detail
+	for _, line := range lines {
+		if !isDecisionLine(line) {
+			merged = append(merged, line)
+			continue
+		}
+		intent, rest, _ := splitSummaryLine(line)
+		if _, ok := at[intent]; !ok {
+			at[intent] = len(merged)
+			merged = append(merged, line)
+		}
+		for _, detail := range splitSummaryDetails(rest) {
+			if !seen[intent+"\x00"+detail] {
+				seen[intent+"\x00"+detail] = true
+				details[intent] = append(details[intent], detail)
+			}
+		}
+	}
+	for intent, i := range at {
+		merged[i] = fmt.Sprintf("- %s: %s", intent, strings.Join(details[intent], ", "))
+	}
+	return merged
+}
 
-// EventGPTAPI provides the API for EventGPT
-type EventGPTAPI struct {
-	dialogManager *DialogManager
-	db            *pgxpool.Pool
-	cache         *redis.Client
+// splitSummaryLine splits a line written by summarizeMessage into its intent
+// and what follows
+func splitSummaryLine(line string) (intent, rest string, ok bool) {
+	line, ok = strings.CutPrefix(line, "- ")
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(line, ": ")
 }
 
-// ChatRequest for sending a message
-type ChatRequest struct {
-	ConversationID *uuid.UUID `json:"conversation_id,omitempty"`
-	Message        string     `json:"message"`
-	Channel        Channel    `json:"channel"`
-	Attachments    []Attachment `json:"attachments,omitempty"`
+// isDecisionLine reports whether a summary line carries entities rather
+// than a quoted message
+func isDecisionLine(line string) bool {
+	_, rest, ok := splitSummaryLine(line)
+	return ok && rest != "" && !strings.HasPrefix(rest, `"`)
 }
 
-// ChatResponse from EventGPT
-type ChatResponse struct {
-	ConversationID uuid.UUID `json:"conversation_id"`
-	Message        Message   `json:"message"`
-	EventID        *uuid.UUID `json:"event_id,omitempty"`
-	SessionType    SessionType `json:"session_type"`
+// splitSummaryDetails splits "type=text, type=text" back into its details;
+// a piece without "=" is a comma inside the previous entity's text
+func splitSummaryDetails(rest string) []string {
+	var details []string
+	for _, part := range strings.Split(rest, ", ") {
+		if !strings.Contains(part, "=") && len(details) > 0 {
+			details[len(details)-1] += ", " + part
+			continue
+		}
+		details = append(details, part)
+	}
+	return details
 }
 
-// Chat handles a chat message
-func (api *EventGPTAPI) Chat(ctx context.Context, userID uuid.UUID, req ChatRequest) (*ChatResponse, error) {
-	// Get or create conversation
-	var conv *Conversation
-	var err error
+// summarizeMessage reduces a user turn to the decision it carried. Assistant
+// turns and small talk are dropped since they are reconstructable from slots.
+func summarizeMessage(msg Message) string {
+	if msg.Role != RoleUser {
+		return ""
+	}
 	
-	if req.ConversationID != nil {
-		conv, err = api.loadConversation(ctx, *req.ConversationID)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		conv = api.createConversation(userID, req.Channel)
+	var details []string
+	for _, entity := range msg.Entities {
+		details = append(details, fmt.Sprintf("%s=%s", entity.Type, strings.TrimSpace(entity.Text)))
+	}
+	
+	intent := ""
+	if msg.Intent != nil {
+		intent = msg.Intent.Name
+	}
+	switch intent {
+	case "greeting", "thanks":
+		return ""
+	}
+	if len(details) == 0 && intent == "" {
+		return ""
+	}
+	if intent == "" {
+		intent = "said"
+	}
+	if len(details) == 0 {
+		return fmt.Sprintf("- %s: %q", intent, msg.Content)
+	}
+	return fmt.Sprintf("- %s: %s", intent, strings.Join(details, ", "))
+}
+
+// memoryInt reads an integer from short-term memory, which comes back as a
+// float64 once the conversation has been through JSON
+func memoryInt(memory map[string]interface{}, key string) int {
+	switch v := memory[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// memoryInts reads an integer list from short-term memory, accepting the
+// []interface{} of float64 that JSON decoding produces
+func memoryInts(memory map[string]interface{}, key string) []int {
+	switch v := memory[key].(type) {
+	case []int:
+		return append([]int{}, v...)
+	case []interface{}:
+		ints := make([]int, 0, len(v))
+		for _, item := range v {
+			if f, ok := item.(float64); ok {
+				ints = append(ints, int(f))
+			}
+		}
+		return ints
+	default:
+		return nil
+	}
+}
+
+// ContextManager manages conversation context
+type ContextManager struct {
+	db    *pgxpool.Pool
+	cache *redis.Client
+}
+
+// VendorService placeholder
+type VendorService struct{}
+
+// PricingService placeholder
+type PricingService struct{}
+
+// =============================================================================
+// 2.7 USAGE ACCOUNTING
+// =============================================================================
+
+// PlanTier is the user's EventGPT subscription tier
+type PlanTier string
+const (
+	TierFree    PlanTier = "free"
+	TierPremium PlanTier = "premium"
+	TierPro     PlanTier = "pro"
+)
+
+// intentModelRules names the rule-based classifier in usage records until an
+// LLM classifier is in place
+const intentModelRules = "rules"
+
+// UsageLimits caps how many times per day each action may run on a tier.
+// Tiers or actions without an entry are unlimited.
+type UsageLimits map[PlanTier]map[string]int
+
+// DefaultUsageLimits mirrors the published plans: free users get three
+// vendor comparisons a day, paid tiers are uncapped
+func DefaultUsageLimits() UsageLimits {
+	return UsageLimits{
+		TierFree: {
+			"generate_comparison": 3,
+		},
+	}
+}
+
+// Exceeded returns the first action that would go over the tier's daily cap
+// given what has already been used today
+func (l UsageLimits) Exceeded(tier PlanTier, usedToday map[string]int, actions []ActionDefinition) (string, bool) {
+	caps := l[tier]
+	for _, action := range actions {
+		if limit, ok := caps[action.Type]; ok && usedToday[action.Type] >= limit {
+			return action.Type, true
+		}
+	}
+	return "", false
+}
+
+// ModelUsage describes one model call
+type ModelUsage struct {
+	Model            string
+	Purpose          string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// ConversationUsage totals the metered usage of a single conversation
+type ConversationUsage struct {
+	ConversationID   uuid.UUID      `json:"conversation_id"`
+	ModelCalls       int            `json:"model_calls"`
+	PromptTokens     int            `json:"prompt_tokens"`
+	CompletionTokens int            `json:"completion_tokens"`
+	Actions          map[string]int `json:"actions"`
+}
+
+// UsageMeter records model calls and action executions per conversation and
+// reports a user's usage for the current day. A nil meter records nothing.
+type UsageMeter struct {
+	db     *pgxpool.Pool
+	limits UsageLimits
+}
+
+// NewUsageMeter creates a usage meter; nil limits use DefaultUsageLimits
+func NewUsageMeter(db *pgxpool.Pool, limits UsageLimits) *UsageMeter {
+	if limits == nil {
+		limits = DefaultUsageLimits()
+	}
+	return &UsageMeter{db: db, limits: limits}
+}
+
+// RecordModelCall logs a model call against the conversation
+func (m *UsageMeter) RecordModelCall(ctx context.Context, conv *Conversation, call ModelUsage) {
+	if m == nil {
+		return
+	}
+	m.db.Exec(ctx, `
+		INSERT INTO eventgpt_usage_events (
+			conversation_id, user_id, kind, name, model,
+			prompt_tokens, completion_tokens
+		) VALUES ($1, $2, 'model_call', $3, $4, $5, $6)
+	`, conv.ID, conv.UserID, call.Purpose, call.Model, call.PromptTokens, call.CompletionTokens)
+}
+
+// RecordAction logs an action execution against the conversation
+func (m *UsageMeter) RecordAction(ctx context.Context, conv *Conversation, actionType string) {
+	if m == nil {
+		return
+	}
+	m.db.Exec(ctx, `
+		INSERT INTO eventgpt_usage_events (conversation_id, user_id, kind, name)
+		VALUES ($1, $2, 'action', $3)
+	`, conv.ID, conv.UserID, actionType)
+}
+
+// Tier returns the user's active EventGPT tier, defaulting to free
+func (m *UsageMeter) Tier(ctx context.Context, userID uuid.UUID) PlanTier {
+	var tier string
+	err := m.db.QueryRow(ctx, `
+		SELECT tier FROM subscriptions
+		WHERE user_id = $1 AND status = 'active'
+		  AND (current_period_end IS NULL OR current_period_end > NOW())
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID).Scan(&tier)
+	if err != nil || tier == "" {
+		return TierFree
+	}
+	return PlanTier(tier)
+}
+
+// ActionsToday counts the user's action executions since midnight UTC
+func (m *UsageMeter) ActionsToday(ctx context.Context, userID uuid.UUID) (map[string]int, error) {
+	rows, err := m.db.Query(ctx, `
+		SELECT name, COUNT(*)
+		FROM eventgpt_usage_events
+		WHERE user_id = $1 AND kind = 'action'
+		  AND created_at >= date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+		GROUP BY name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	counts := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var count int
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, err
+		}
+		counts[name] = count
+	}
+	return counts, rows.Err()
+}
+
+// ConversationUsage totals everything metered for a conversation
+func (m *UsageMeter) ConversationUsage(ctx context.Context, conversationID uuid.UUID) (*ConversationUsage, error) {
+	usage := &ConversationUsage{ConversationID: conversationID, Actions: make(map[string]int)}
+	
+	rows, err := m.db.Query(ctx, `
+		SELECT kind, name, COUNT(*), COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0)
+		FROM eventgpt_usage_events
+		WHERE conversation_id = $1
+		GROUP BY kind, name
+	`, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var kind, name string
+		var count, prompt, completion int
+		if err := rows.Scan(&kind, &name, &count, &prompt, &completion); err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "model_call":
+			usage.ModelCalls += count
+			usage.PromptTokens += prompt
+			usage.CompletionTokens += completion
+		case "action":
+			usage.Actions[name] += count
+		}
+	}
+	return usage, rows.Err()
+}
+
+// SetUsageMeter enables usage metering and tier caps
+func (dm *DialogManager) SetUsageMeter(meter *UsageMeter) {
+	dm.usage = meter
+}
+
+// ApplyUsageLimits replaces a strategy with an upgrade prompt when any of its
+// actions is over the tier's daily cap
+func (dm *DialogManager) ApplyUsageLimits(conv *Conversation, tier PlanTier, usedToday map[string]int, strategy *ResponseStrategy) *ResponseStrategy {
+	limits := DefaultUsageLimits()
+	if dm.usage != nil {
+		limits = dm.usage.limits
+	}
+	
+	if _, exceeded := limits.Exceeded(tier, usedToday, strategy.Actions); !exceeded {
+		return strategy
+	}
+	return &ResponseStrategy{
+		Type:      ResponseText,
+		Template:  "upgrade_required",
+		NextState: conv.ConversationState,
+		QuickReplies: []QuickReply{
+			{Title: "Upgrade to Premium", Payload: "upgrade:premium"},
+			{Title: "Maybe later", Payload: "upgrade:later"},
+		},
+	}
+}
+
+func (dm *DialogManager) enforceUsageLimits(ctx context.Context, conv *Conversation, strategy *ResponseStrategy) *ResponseStrategy {
+	if dm.usage == nil || len(strategy.Actions) == 0 {
+		return strategy
+	}
+	
+	tier := dm.usage.Tier(ctx, conv.UserID)
+	if len(dm.usage.limits[tier]) == 0 {
+		return strategy
+	}
+	usedToday, err := dm.usage.ActionsToday(ctx, conv.UserID)
+	if err != nil {
+		// Don't block the user on a metering failure
+		return strategy
+	}
+	return dm.ApplyUsageLimits(conv, tier, usedToday, strategy)
+}
+
+// =============================================================================
+// 2.8 PROCESSING TIME METRICS
+// =============================================================================
+
+// ProcessingSample is the processing time of one assistant response
+type ProcessingSample struct {
+	Intent           string
+	Actions          []string
+	ProcessingTimeMs int64
+}
+
+// LatencyStats summarises processing times for one intent or action type
+type LatencyStats struct {
+	Count int   `json:"count"`
+	P50Ms int64 `json:"p50_ms"`
+	P95Ms int64 `json:"p95_ms"`
+}
+
+// ProcessingTimeReport breaks response processing time down by intent and
+// by the action types the responses ran
+type ProcessingTimeReport struct {
+	From    time.Time               `json:"from"`
+	To      time.Time               `json:"to"`
+	Intents map[string]LatencyStats `json:"intents"`
+	Actions map[string]LatencyStats `json:"actions"`
+}
+
+// Percentile returns the nearest-rank p-th percentile (0-100) of values,
+// or 0 when there are none
+func Percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// SummarizeProcessingTimes computes p50/p95 per intent and per action type.
+// A response that ran several actions counts towards each of them.
+func SummarizeProcessingTimes(samples []ProcessingSample) (intents, actions map[string]LatencyStats) {
+	byIntent := make(map[string][]int64)
+	byAction := make(map[string][]int64)
+	for _, sample := range samples {
+		byIntent[sample.Intent] = append(byIntent[sample.Intent], sample.ProcessingTimeMs)
+		for _, action := range sample.Actions {
+			byAction[action] = append(byAction[action], sample.ProcessingTimeMs)
+		}
+	}
+	return latencyStats(byIntent), latencyStats(byAction)
+}
+
+func latencyStats(groups map[string][]int64) map[string]LatencyStats {
+	stats := make(map[string]LatencyStats, len(groups))
+	for name, values := range groups {
+		stats[name] = LatencyStats{
+			Count: len(values),
+			P50Ms: Percentile(values, 50),
+			P95Ms: Percentile(values, 95),
+		}
+	}
+	return stats
+}
+
+// ProcessingMetrics persists response processing times. A nil recorder
+// records nothing.
+type ProcessingMetrics struct {
+	db *pgxpool.Pool
+}
+
+// NewProcessingMetrics creates a processing time recorder
+func NewProcessingMetrics(db *pgxpool.Pool) *ProcessingMetrics {
+	return &ProcessingMetrics{db: db}
+}
+
+// Record stores the processing time of a response to the given intent
+func (m *ProcessingMetrics) Record(ctx context.Context, conv *Conversation, intent string, actions []ActionDefinition, processingTimeMs int64) {
+	if m == nil {
+		return
+	}
+	actionTypes := make([]string, 0, len(actions))
+	for _, action := range actions {
+		actionTypes = append(actionTypes, action.Type)
+	}
+	m.db.Exec(ctx, `
+		INSERT INTO eventgpt_message_metrics (conversation_id, intent, action_types, processing_time_ms)
+		VALUES ($1, $2, $3, $4)
+	`, conv.ID, intent, actionTypes, processingTimeMs)
+}
+
+// Report summarises processing times recorded in [from, to)
+func (m *ProcessingMetrics) Report(ctx context.Context, from, to time.Time) (*ProcessingTimeReport, error) {
+	rows, err := m.db.Query(ctx, `
+		SELECT intent, action_types, processing_time_ms
+		FROM eventgpt_message_metrics
+		WHERE created_at >= $1 AND created_at < $2
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var samples []ProcessingSample
+	for rows.Next() {
+		var sample ProcessingSample
+		if err := rows.Scan(&sample.Intent, &sample.Actions, &sample.ProcessingTimeMs); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	
+	report := &ProcessingTimeReport{From: from, To: to}
+	report.Intents, report.Actions = SummarizeProcessingTimes(samples)
+	return report, nil
+}
+
+// SetProcessingMetrics enables recording of response processing times
+func (dm *DialogManager) SetProcessingMetrics(metrics *ProcessingMetrics) {
+	dm.metrics = metrics
+}
+
+// =============================================================================
+// 2.9 FEATURE FLAGS
+// =============================================================================
+
+// DialogFeature names a dialog behaviour that is being rolled out
+type DialogFeature string
+
+const (
+	// FeatureBatchedQuestions asks for two missing slots in one question
+	FeatureBatchedQuestions DialogFeature = "batched_questions"
+)
+
+// FlagRule decides who gets a feature: everyone when Enabled, otherwise the
+// listed users plus a stable Percent cohort of the rest
+type FlagRule struct {
+	Enabled bool        `json:"enabled"`
+	Percent int         `json:"percent"` // 0-100
+	UserIDs []uuid.UUID `json:"user_ids,omitempty"`
+}
+
+// Allows reports whether the rule enables the feature for a user. Cohorts
+// are bucketed by a hash of the feature and user, so a user stays in or out
+// of a rollout as its percentage grows.
+func (r FlagRule) Allows(feature DialogFeature, userID uuid.UUID) bool {
+	if r.Enabled {
+		return true
+	}
+	for _, id := range r.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	if r.Percent <= 0 {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(string(feature) + ":" + userID.String()))
+	return int(h.Sum32()%100) < r.Percent
+}
+
+// FlagSource supplies the current flag rules
+type FlagSource interface {
+	FlagRules(ctx context.Context) (map[DialogFeature]FlagRule, error)
+}
+
+// StaticFlags are flag rules fixed by configuration
+type StaticFlags map[DialogFeature]FlagRule
+
+// FlagRules returns the configured rules
+func (f StaticFlags) FlagRules(ctx context.Context) (map[DialogFeature]FlagRule, error) {
+	return f, nil
+}
+
+// flagStoreKey is the Redis hash holding one JSON FlagRule per feature
+const flagStoreKey = "eventgpt:feature_flags"
+
+// RedisFlagStore reads flag rules from Redis so rollouts can change
+// without a deploy
+type RedisFlagStore struct {
+	cache *redis.Client
+}
+
+// NewRedisFlagStore creates a flag store backed by Redis
+func NewRedisFlagStore(cache *redis.Client) *RedisFlagStore {
+	return &RedisFlagStore{cache: cache}
+}
+
+// FlagRules loads every rule in the store, skipping any that don't parse
+func (s *RedisFlagStore) FlagRules(ctx context.Context) (map[DialogFeature]FlagRule, error) {
+	raw, err := s.cache.HGetAll(ctx, flagStoreKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[DialogFeature]FlagRule, len(raw))
+	for name, value := range raw {
+		var rule FlagRule
+		if err := json.Unmarshal([]byte(value), &rule); err != nil {
+			continue
+		}
+		rules[DialogFeature(name)] = rule
+	}
+	return rules, nil
+}
+
+// SetFlagRule stores the rule for a feature
+func (s *RedisFlagStore) SetFlagRule(ctx context.Context, feature DialogFeature, rule FlagRule) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	return s.cache.HSet(ctx, flagStoreKey, string(feature), data).Err()
+}
+
+// FeatureFlags evaluates flag rules for conversations. Features are off
+// unless a rule turns them on, including when the source fails.
+type FeatureFlags struct {
+	source FlagSource
+}
+
+// NewFeatureFlags creates a flag evaluator over source
+func NewFeatureFlags(source FlagSource) *FeatureFlags {
+	return &FeatureFlags{source: source}
+}
+
+// Resolve pins the flag values for the conversation's user on first call;
+// later calls leave them as they are. A nil evaluator resolves nothing.
+func (f *FeatureFlags) Resolve(ctx context.Context, conv *Conversation) {
+	if f == nil || f.source == nil || conv.Features != nil {
+		return
+	}
+	rules, err := f.source.FlagRules(ctx)
+	if err != nil {
+		// Leave unresolved so the next turn tries again; features stay off
+		return
+	}
+	conv.Features = make(map[DialogFeature]bool, len(rules))
+	for feature, rule := range rules {
+		conv.Features[feature] = rule.Allows(feature, conv.UserID)
+	}
+}
+
+// FeatureEnabled reports whether a feature is on for this conversation
+func (conv *Conversation) FeatureEnabled(feature DialogFeature) bool {
+	return conv.Features[feature]
+}
+
+// SetFeatureFlags enables per-conversation gating of new dialog behaviours
+func (dm *DialogManager) SetFeatureFlags(flags *FeatureFlags) {
+	dm.flags = flags
+}
+
+// =============================================================================
+// 2.10 SAVED VENDORS
+// =============================================================================
+
+// saveVendorPrefix starts the postback payload of a vendor card's Save button
+const saveVendorPrefix = "save_vendor:"
+
+// ErrSavedVendorsUnavailable is returned when no saved vendor store is set
+var ErrSavedVendorsUnavailable = errors.New("saved vendors are not available")
+
+// SavedVendor is a vendor card a user bookmarked during a chat to revisit
+type SavedVendor struct {
+	UserID         uuid.UUID `json:"user_id"`
+	VendorID       uuid.UUID `json:"vendor_id"`
+	ServiceID      uuid.UUID `json:"service_id"`
+	VendorName     string    `json:"vendor_name"`
+	ServiceName    string    `json:"service_name,omitempty"`
+	VendorType     string    `json:"vendor_type,omitempty"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SavedAt        time.Time `json:"saved_at"`
+}
+
+// Label names the saved vendor in a response
+func (v SavedVendor) Label() string {
+	switch {
+	case v.VendorName != "":
+		return v.VendorName
+	case v.VendorType != "":
+		return v.VendorType
+	default:
+		return "this vendor"
+	}
+}
+
+// SavedVendorStore persists each user's saved vendors. Saving a vendor the
+// user already saved refreshes it instead of adding a duplicate, and lists
+// come back newest first.
+type SavedVendorStore interface {
+	SaveVendor(ctx context.Context, saved SavedVendor) error
+	SavedVendors(ctx context.Context, userID uuid.UUID) ([]SavedVendor, error)
+}
+
+// ParseSaveVendorPayload extracts the vendor ID from a save_vendor postback
+func ParseSaveVendorPayload(text string) (uuid.UUID, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, saveVendorPrefix) {
+		return uuid.Nil, false
+	}
+	vendorID, err := uuid.Parse(strings.TrimPrefix(text, saveVendorPrefix))
+	return vendorID, err == nil
+}
+
+// SavedVendorFor builds the saved entry for a vendor, taking the card
+// details from the latest search results when the vendor was among them
+func SavedVendorFor(conv *Conversation, vendorID uuid.UUID, now time.Time) SavedVendor {
+	saved := SavedVendor{
+		UserID:         conv.UserID,
+		VendorID:       vendorID,
+		ConversationID: conv.ID,
+		SavedAt:        now,
+	}
+	if slot, ok := conv.SlotValues["vendor_type"]; ok && slot.Value != nil {
+		saved.VendorType = strings.ToLower(fmt.Sprintf("%v", slot.Value))
+	}
+	vendors, _ := conv.ShortTermMemory["vendor_results"].([]VendorResult)
+	for _, v := range vendors {
+		if v.VendorID == vendorID {
+			saved.ServiceID = v.ServiceID
+			saved.VendorName = v.VendorName
+			saved.ServiceName = v.ServiceName
+			break
+		}
+	}
+	return saved
+}
+
+// SaveVendor saves a vendor for the conversation's user and selects it, so
+// the next turn can quote or book it without searching again
+func SaveVendor(ctx context.Context, store SavedVendorStore, conv *Conversation, vendorID uuid.UUID) (*SavedVendor, error) {
+	if store == nil {
+		return nil, ErrSavedVendorsUnavailable
+	}
+	if vendorID == uuid.Nil {
+		return nil, fmt.Errorf("vendor ID is required")
+	}
+	saved := SavedVendorFor(conv, vendorID, time.Now())
+	if err := store.SaveVendor(ctx, saved); err != nil {
+		return nil, err
+	}
+	SelectSavedVendor(conv, saved)
+	return &saved, nil
+}
+
+// SelectSavedVendor makes a saved vendor the conversation's selected vendor
+func SelectSavedVendor(conv *Conversation, saved SavedVendor) {
+	if conv.ShortTermMemory == nil {
+		conv.ShortTermMemory = make(map[string]interface{})
+	}
+	conv.ShortTermMemory["selected_vendor_id"] = saved.VendorID
+	if saved.ServiceID != uuid.Nil {
+		conv.ShortTermMemory["selected_service_id"] = saved.ServiceID
+	}
+	conv.ShortTermMemory["selected_vendor_name"] = saved.Label()
+}
+
+// ResolveSavedVendor finds the saved vendor a message refers to, such as
+// "my saved photographer" or "the caterer I saved". Names are matched
+// before categories, newest save first; a bare "my saved vendor" means the
+// latest save.
+func ResolveSavedVendor(text string, saved []SavedVendor) (*SavedVendor, bool) {
+	lower := strings.ToLower(text)
+	if !strings.Contains(lower, "saved") {
+		return nil, false
+	}
+	for i := range saved {
+		if name := strings.ToLower(saved[i].VendorName); name != "" && strings.Contains(lower, name) {
+			return &saved[i], true
+		}
+	}
+	for i := range saved {
+		if category := saved[i].VendorType; category != "" && strings.Contains(lower, category) {
+			return &saved[i], true
+		}
+	}
+	if len(saved) > 0 && (strings.Contains(lower, "saved vendor") || strings.Contains(lower, "saved one")) {
+		return &saved[0], true
+	}
+	return nil, false
+}
+
+// RecallSavedVendor selects the saved vendor a message refers to, if any,
+// so quoting, availability and booking act on it
+func (dm *DialogManager) RecallSavedVendor(ctx context.Context, conv *Conversation, userMessage string) bool {
+	if dm.savedVendors == nil || !strings.Contains(strings.ToLower(userMessage), "saved") {
+		return false
+	}
+	saved, err := dm.savedVendors.SavedVendors(ctx, conv.UserID)
+	if err != nil {
+		return false
+	}
+	vendor, ok := ResolveSavedVendor(userMessage, saved)
+	if !ok {
+		return false
+	}
+	SelectSavedVendor(conv, *vendor)
+	return true
+}
+
+// SetSavedVendors enables the Save button on vendor cards
+func (dm *DialogManager) SetSavedVendors(store SavedVendorStore) {
+	dm.savedVendors = store
+	if dm.actionExecutor != nil {
+		dm.actionExecutor.savedVendors = store
+	}
+}
+
+// MemorySavedVendors keeps saved vendors in process
+type MemorySavedVendors struct {
+	mu     sync.Mutex
+	byUser map[uuid.UUID][]SavedVendor
+}
+
+// NewMemorySavedVendors creates an empty in-process store
+func NewMemorySavedVendors() *MemorySavedVendors {
+	return &MemorySavedVendors{byUser: make(map[uuid.UUID][]SavedVendor)}
+}
+
+// SaveVendor stores a saved vendor, moving an earlier save of it to the front
+func (s *MemorySavedVendors) SaveVendor(ctx context.Context, saved SavedVendor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	list := []SavedVendor{saved}
+	for _, existing := range s.byUser[saved.UserID] {
+		if existing.VendorID != saved.VendorID {
+			list = append(list, existing)
+		}
+	}
+	s.byUser[saved.UserID] = list
+	return nil
+}
+
+// SavedVendors lists a user's saved vendors, newest first
+func (s *MemorySavedVendors) SavedVendors(ctx context.Context, userID uuid.UUID) ([]SavedVendor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SavedVendor(nil), s.byUser[userID]...), nil
+}
+
+// PostgresSavedVendors keeps saved vendors in eventgpt_saved_vendors
+type PostgresSavedVendors struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresSavedVendors creates a database-backed saved vendor store
+func NewPostgresSavedVendors(db *pgxpool.Pool) *PostgresSavedVendors {
+	return &PostgresSavedVendors{db: db}
+}
+
+// SaveVendor upserts a saved vendor
+func (s *PostgresSavedVendors) SaveVendor(ctx context.Context, saved SavedVendor) error {
+	var serviceID *uuid.UUID
+	if saved.ServiceID != uuid.Nil {
+		serviceID = &saved.ServiceID
+	}
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO eventgpt_saved_vendors (
+			user_id, vendor_id, service_id, vendor_name, service_name,
+			vendor_type, conversation_id, saved_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, vendor_id) DO UPDATE SET
+			service_id = COALESCE(EXCLUDED.service_id, eventgpt_saved_vendors.service_id),
+			vendor_name = COALESCE(NULLIF(EXCLUDED.vendor_name, ''), eventgpt_saved_vendors.vendor_name),
+			service_name = COALESCE(NULLIF(EXCLUDED.service_name, ''), eventgpt_saved_vendors.service_name),
+			vendor_type = COALESCE(NULLIF(EXCLUDED.vendor_type, ''), eventgpt_saved_vendors.vendor_type),
+			conversation_id = EXCLUDED.conversation_id,
+			saved_at = EXCLUDED.saved_at
+	`, saved.UserID, saved.VendorID, serviceID, saved.VendorName, saved.ServiceName,
+		saved.VendorType, saved.ConversationID, saved.SavedAt)
+	return err
+}
+
+// SavedVendors lists a user's saved vendors, newest first
+func (s *PostgresSavedVendors) SavedVendors(ctx context.Context, userID uuid.UUID) ([]SavedVendor, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT user_id, vendor_id, service_id, vendor_name, service_name,
+		       vendor_type, conversation_id, saved_at
+		FROM eventgpt_saved_vendors
+		WHERE user_id = $1
+		ORDER BY saved_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var saved []SavedVendor
+	for rows.Next() {
+		var v SavedVendor
+		var serviceID, conversationID *uuid.UUID
+		if err := rows.Scan(&v.UserID, &v.VendorID, &serviceID, &v.VendorName, &v.ServiceName,
+			&v.VendorType, &conversationID, &v.SavedAt); err != nil {
+			return nil, err
+		}
+		if serviceID != nil {
+			v.ServiceID = *serviceID
+		}
+		if conversationID != nil {
+			v.ConversationID = *conversationID
+		}
+		saved = append(saved, v)
+	}
+	return saved, rows.Err()
+}
+
+// =============================================================================
+// 2.11 KNOWLEDGE BASE
+// =============================================================================
+
+// DefaultKBMinRank is the rank an article needs before its snippet is given
+// as the answer to a general question
+const DefaultKBMinRank = 0.05
+
+// kbSearchLimit is how many ranked articles a search returns
+const kbSearchLimit = 3
+
+// ErrKnowledgeBaseUnavailable is returned when no knowledge base is set
+var ErrKnowledgeBaseUnavailable = errors.New("knowledge base is not available")
+
+// KBArticle is a help article general questions are answered from
+type KBArticle struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Body  string    `json:"body"`
+	Tags  []string  `json:"tags,omitempty"`
+}
+
+// KBMatch is an article ranked against a question, with the part of its
+// body that best answers it
+type KBMatch struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+}
+
+// KnowledgeBase searches help articles, best match first
+type KnowledgeBase interface {
+	Search(ctx context.Context, query string, limit int) ([]KBMatch, error)
+}
+
+// SearchKnowledgeBase returns the best article for a question, or nil when
+// none ranks at least minRank
+func SearchKnowledgeBase(ctx context.Context, kb KnowledgeBase, query string, minRank float64) (*KBMatch, error) {
+	if kb == nil {
+		return nil, ErrKnowledgeBaseUnavailable
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	matches, err := kb.Search(ctx, query, kbSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 || matches[0].Rank < minRank {
+		return nil, nil
+	}
+	return &matches[0], nil
+}
+
+// ApplyKnowledgeBaseOutcome swaps a general answer for an offer of human
+// help when the knowledge base had nothing good enough
+func ApplyKnowledgeBaseOutcome(strategy *ResponseStrategy, actionResults map[string]interface{}) *ResponseStrategy {
+	if strategy.Template != "general_answer" || actionResults["kb_no_answer"] != true {
+		return strategy
+	}
+	
+	adjusted := *strategy
+	adjusted.Template = "kb_no_answer"
+	adjusted.QuickReplies = []QuickReply{
+		{Title: "Talk to a person", Payload: HandoffRequestPayload},
+		{Title: "Ask something else", Payload: "ask_question"},
+	}
+	return &adjusted
+}
+
+// SetKnowledgeBase sets where general questions are answered from
+func (dm *DialogManager) SetKnowledgeBase(kb KnowledgeBase) {
+	if dm.actionExecutor != nil {
+		dm.actionExecutor.knowledgeBase = kb
+	}
+}
+
+// PostgresKnowledgeBase ranks kb_articles with Postgres full-text search.
+// Any question word can match, title and tags weigh more than the body, and
+// the snippet is the body passage around the matched words.
+type PostgresKnowledgeBase struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresKnowledgeBase creates a database-backed knowledge base
+func NewPostgresKnowledgeBase(db *pgxpool.Pool) *PostgresKnowledgeBase {
+	return &PostgresKnowledgeBase{db: db}
+}
+
+// Search ranks published articles against the query with ts_rank
+func (kb *PostgresKnowledgeBase) Search(ctx context.Context, query string, limit int) ([]KBMatch, error) {
+	rows, err := kb.db.Query(ctx, `
+		WITH q AS (
+			SELECT NULLIF(replace(plainto_tsquery('english', $1)::text, '&', '|'), '')::tsquery AS query
+		)
+		SELECT a.id, a.title,
+		       ts_headline('english', a.body, q.query, 'MaxWords=40, MinWords=15, StartSel="", StopSel=""'),
+		       ts_rank(d.document, q.query) AS rank
+		FROM kb_articles a
+		CROSS JOIN q
+		CROSS JOIN LATERAL (
+			SELECT setweight(to_tsvector('english', a.title), 'A') ||
+			       setweight(to_tsvector('english', array_to_string(a.tags, ' ')), 'A') ||
+			       setweight(to_tsvector('english', a.body), 'B') AS document
+		) d
+		WHERE a.published AND d.document @@ q.query
+		ORDER BY rank DESC
+		LIMIT $2
+	`, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var matches []KBMatch
+	for rows.Next() {
+		var m KBMatch
+		var rank float32
+		if err := rows.Scan(&m.ArticleID, &m.Title, &m.Snippet, &rank); err != nil {
+			return nil, err
+		}
+		m.Rank = float64(rank)
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// MemoryKnowledgeBase searches articles held in process. An article ranks by
+// the share of the question's words it contains, and its snippet is the
+// first sentence of the body naming any of them.
+type MemoryKnowledgeBase struct {
+	mu       sync.Mutex
+	articles []KBArticle
+}
+
+// NewMemoryKnowledgeBase creates an in-process knowledge base
+func NewMemoryKnowledgeBase(articles ...KBArticle) *MemoryKnowledgeBase {
+	return &MemoryKnowledgeBase{articles: articles}
+}
+
+// AddArticle adds an article to the knowledge base
+func (kb *MemoryKnowledgeBase) AddArticle(article KBArticle) {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+	kb.articles = append(kb.articles, article)
+}
+
+// Search ranks the articles against the query
+func (kb *MemoryKnowledgeBase) Search(ctx context.Context, query string, limit int) ([]KBMatch, error) {
+	terms := kbTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+	
+	var matches []KBMatch
+	for _, article := range kb.articles {
+		words := make(map[string]bool)
+		for _, word := range kbTerms(article.Title + " " + strings.Join(article.Tags, " ") + " " + article.Body) {
+			words[word] = true
+		}
+		found := 0
+		for _, term := range terms {
+			if words[term] {
+				found++
+			}
+		}
+		if found == 0 {
+			continue
+		}
+		matches = append(matches, KBMatch{
+			ArticleID: article.ID,
+			Title:     article.Title,
+			Snippet:   kbSnippet(article.Body, terms),
+			Rank:      float64(found) / float64(len(terms)),
+		})
+	}
+	
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Rank > matches[j].Rank
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// kbStopWords are left out of matching, as Postgres' english configuration
+// does
+var kbStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "can": true, "you": true,
+	"how": true, "what": true, "does": true, "with": true, "your": true, "have": true,
+	"this": true, "that": true, "when": true, "who": true, "why": true, "where": true,
+}
+
+// kbTerms splits text into lower-case words worth matching on
+func kbTerms(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) > 2 && !kbStopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// kbSnippet is the first sentence of body that names one of the terms
+func kbSnippet(body string, terms []string) string {
+	sentences := strings.SplitAfter(body, ". ")
+	for _, sentence := range sentences {
+		words := kbTerms(sentence)
+		for _, term := range terms {
+			for _, word := range words {
+				if word == term {
+					return strings.TrimSpace(sentence)
+				}
+			}
+		}
+	}
+	return strings.TrimSpace(sentences[0])
+}
+
+// =============================================================================
+// 2.12 SUPPORT HANDOFF
+// =============================================================================
+
+// IntentHandoff is a request to talk to a person
+const IntentHandoff = "handoff"
+
+// HandoffRequestPayload is the quick reply that asks for a person
+const HandoffRequestPayload = "handoff:request"
+
+// MaxFailedTurns is how many turns in a row can go unanswered before the
+// conversation is handed to a person
+const MaxFailedTurns = 3
+
+const (
+	memoryFailedTurns   = "failed_turns"
+	memoryHandoffReason = "handoff_reason"
+)
+
+// Reasons a conversation was handed off
+const (
+	HandoffReasonRequested   = "requested"
+	HandoffReasonFailedTurns = "repeated_failed_intents"
+)
+
+// HandoffStatus tracks a handoff through the support queue
+type HandoffStatus string
+
+const (
+	HandoffOpen     HandoffStatus = "open"
+	HandoffAssigned HandoffStatus = "assigned"
+	HandoffResolved HandoffStatus = "resolved"
+)
+
+// ErrInvalidHandoffStatus is returned when listing handoffs by an unknown status
+var ErrInvalidHandoffStatus = errors.New("invalid handoff status")
+
+// ParseHandoffStatus validates a status filter; empty means open
+func ParseHandoffStatus(s string) (HandoffStatus, error) {
+	switch status := HandoffStatus(s); status {
+	case "":
+		return HandoffOpen, nil
+	case HandoffOpen, HandoffAssigned, HandoffResolved:
+		return status, nil
+	default:
+		return "", ErrInvalidHandoffStatus
+	}
+}
+
+// Handoff is a conversation queued for a human agent
+type Handoff struct {
+	ID             uuid.UUID     `json:"id"`
+	ConversationID uuid.UUID     `json:"conversation_id"`
+	UserID         uuid.UUID     `json:"user_id"`
+	Reason         string        `json:"reason"`
+	LastIntent     string        `json:"last_intent,omitempty"`
+	Status         HandoffStatus `json:"status"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// HandoffStore is the support queue. A conversation is queued at most once.
+type HandoffStore interface {
+	// CreateHandoff queues a handoff, reporting false when the conversation
+	// was already queued
+	CreateHandoff(ctx context.Context, handoff Handoff) (bool, error)
+	// Handoffs lists handoffs in a status, oldest first
+	Handoffs(ctx context.Context, status HandoffStatus) ([]Handoff, error)
+}
+
+// SetHandoffStore sets the support queue handoffs are recorded in
+func (dm *DialogManager) SetHandoffStore(store HandoffStore) {
+	dm.handoffs = store
+}
+
+// failedTurnTemplates are responses that mean the turn went unanswered
+var failedTurnTemplates = map[string]bool{
+	"kb_no_answer":   true,
+	"clarify_intent": true,
+}
+
+func (dm *DialogManager) handleHandoff(conv *Conversation, reason string) *ResponseStrategy {
+	conv.ShortTermMemory[memoryHandoffReason] = reason
+	return &ResponseStrategy{
+		Type:      ResponseHandoff,
+		Template:  "handoff_queued",
+		NextState: StateHandoff,
+	}
+}
+
+// ApplyFailedTurns counts consecutive unanswered turns and hands the
+// conversation off once MaxFailedTurns is reached
+func (dm *DialogManager) ApplyFailedTurns(conv *Conversation, strategy *ResponseStrategy) *ResponseStrategy {
+	if conv.ConversationState == StateHandoff || strategy.NextState == StateHandoff {
+		return strategy
+	}
+	if !failedTurnTemplates[strategy.Template] {
+		delete(conv.ShortTermMemory, memoryFailedTurns)
+		return strategy
+	}
+	
+	failed := memoryInt(conv.ShortTermMemory, memoryFailedTurns) + 1
+	conv.ShortTermMemory[memoryFailedTurns] = failed
+	if failed < MaxFailedTurns {
+		return strategy
+	}
+	delete(conv.ShortTermMemory, memoryFailedTurns)
+	return dm.handleHandoff(conv, HandoffReasonFailedTurns)
+}
+
+// RecordHandoff queues the conversation for an agent when the strategy hands
+// it off, and moves it to StateHandoff. A conversation already handed off
+// is not queued again.
+func (dm *DialogManager) RecordHandoff(ctx context.Context, conv *Conversation, strategy *ResponseStrategy) error {
+	if strategy.NextState != StateHandoff || conv.ConversationState == StateHandoff {
+		return nil
+	}
+	
+	reason, _ := conv.ShortTermMemory[memoryHandoffReason].(string)
+	if reason == "" {
+		reason = HandoffReasonRequested
+	}
+	if dm.handoffs != nil {
+		_, err := dm.handoffs.CreateHandoff(ctx, Handoff{
+			ID:             uuid.New(),
+			ConversationID: conv.ID,
+			UserID:         conv.UserID,
+			Reason:         reason,
+			LastIntent:     lastSubstantiveIntent(conv),
+			Status:         HandoffOpen,
+			CreatedAt:      time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	delete(conv.ShortTermMemory, memoryHandoffReason)
+	conv.ConversationState = StateHandoff
+	return nil
+}
+
+// lastSubstantiveIntent is the latest intent other than asking for a person
+func lastSubstantiveIntent(conv *Conversation) string {
+	for i := len(conv.IntentHistory) - 1; i >= 0; i-- {
+		if name := conv.IntentHistory[i]; name != IntentHandoff && name != IntentClarify {
+			return name
+		}
+	}
+	return ""
+}
+
+// PostgresHandoffStore keeps the support queue in support_handoffs
+type PostgresHandoffStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresHandoffStore creates a database-backed support queue
+func NewPostgresHandoffStore(db *pgxpool.Pool) *PostgresHandoffStore {
+	return &PostgresHandoffStore{db: db}
+}
+
+// CreateHandoff inserts a handoff unless the conversation already has one
+func (s *PostgresHandoffStore) CreateHandoff(ctx context.Context, handoff Handoff) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+		INSERT INTO support_handoffs (
+			id, conversation_id, user_id, reason, last_intent, status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (conversation_id) DO NOTHING
+	`, handoff.ID, handoff.ConversationID, handoff.UserID, handoff.Reason,
+		handoff.LastIntent, handoff.Status, handoff.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Handoffs lists handoffs in a status, oldest first
+func (s *PostgresHandoffStore) Handoffs(ctx context.Context, status HandoffStatus) ([]Handoff, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, conversation_id, user_id, reason, last_intent, status, created_at
+		FROM support_handoffs
+		WHERE status = $1
+		ORDER BY created_at
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var handoffs []Handoff
+	for rows.Next() {
+		var h Handoff
+		if err := rows.Scan(&h.ID, &h.ConversationID, &h.UserID, &h.Reason,
+			&h.LastIntent, &h.Status, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		handoffs = append(handoffs, h)
+	}
+	return handoffs, rows.Err()
+}
+
+// MemoryHandoffStore keeps the support queue in process
+type MemoryHandoffStore struct {
+	mu       sync.Mutex
+	handoffs []Handoff
+}
+
+// NewMemoryHandoffStore creates an empty in-process support queue
+func NewMemoryHandoffStore() *MemoryHandoffStore {
+	return &MemoryHandoffStore{}
+}
+
+// CreateHandoff queues a handoff unless the conversation already has one
+func (s *MemoryHandoffStore) CreateHandoff(ctx context.Context, handoff Handoff) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, h := range s.handoffs {
+		if h.ConversationID == handoff.ConversationID {
+			return false, nil
+		}
+	}
+	s.handoffs = append(s.handoffs, handoff)
+	return true, nil
+}
+
+// Handoffs lists handoffs in a status, oldest first
+func (s *MemoryHandoffStore) Handoffs(ctx context.Context, status HandoffStatus) ([]Handoff, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var handoffs []Handoff
+	for _, h := range s.handoffs {
+		if h.Status == status {
+			handoffs = append(handoffs, h)
+		}
+	}
+	return handoffs, nil
+}
+
+// =============================================================================
+// 2.13 BOOKING CONFIRMATION
+// =============================================================================
+
+// IntentConfirmBooking is the user accepting a prepared booking
+const IntentConfirmBooking = "confirm_booking"
+
+// BookingConfirmPayload is the quick reply that accepts a booking draft
+const BookingConfirmPayload = "booking:confirm"
+
+// Short-term memory keys for the booking flow
+const (
+	memoryBookingDraft = "booking_draft"
+	memoryBookingID    = "booking_id"
+)
+
+var (
+	// ErrNoBookingDraft is returned when a booking is confirmed before one
+	// was prepared, or the draft is missing its vendor, service or date
+	ErrNoBookingDraft = errors.New("no booking draft to confirm")
+	// ErrBookingUnavailable is returned when no booking service is set
+	ErrBookingUnavailable = errors.New("booking is not available")
+	// ErrBookingSignInRequired is returned when an anonymous user confirms
+	// a booking, since there is no one to book it for
+	ErrBookingSignInRequired = errors.New("sign in required to book")
+)
+
+// BookingRequest is a confirmed draft on its way to the booking service
+type BookingRequest struct {
+	UserID         uuid.UUID
+	VendorID       uuid.UUID
+	ServiceID      uuid.UUID
+	Date           time.Time
+	ConversationID uuid.UUID
+}
+
+// ConfirmedBooking is a booking the booking service created
+type ConfirmedBooking struct {
+	ID          uuid.UUID `json:"id"`
+	Reference   string    `json:"reference,omitempty"`
+	TotalAmount float64   `json:"total_amount"`
+}
+
+// BookingService creates bookings from confirmed drafts. The server backs it
+// with the internal booking service.
+type BookingService interface {
+	CreateBooking(ctx context.Context, req BookingRequest) (*ConfirmedBooking, error)
+}
+
+// SetBookingService sets where accepted booking drafts are booked
+func (dm *DialogManager) SetBookingService(bookings BookingService) {
+	if dm.actionExecutor != nil {
+		dm.actionExecutor.bookingService = bookings
+	}
+}
+
+// AvailabilityChecker decides whether a vendor can take a booking. The server
+// backs it with the internal booking service.
+type AvailabilityChecker interface {
+	CheckAvailability(ctx context.Context, vendorID uuid.UUID, start, end time.Time) (availability.Result, error)
+}
+
+// SetAvailabilityChecker sets where vendor availability is checked
+func (dm *DialogManager) SetAvailabilityChecker(checker AvailabilityChecker) {
+	if dm.actionExecutor != nil {
+		dm.actionExecutor.availability = checker
+	}
+}
+
+// DescribeAvailability turns an availability result into the
+// availability_status (available, limited or unavailable) and the message
+// EventGPT replies with. Unavailable results offer the next open slot.
+func DescribeAvailability(result availability.Result) (string, string) {
+	if result.Available {
+		if result.Remaining == 1 {
+			return "limited", "Only 1 slot remaining! I'd recommend booking soon."
+		}
+		return "available", "Great news! They have availability."
+	}
+
+	message := "They're fully booked on this date."
+	if result.Reason == availability.ReasonOutsideHours {
+		message = "They don't work at that time."
+	}
+	if len(result.Slots) == 0 {
+		return "unavailable", message + " Would you like to see alternative dates?"
+	}
+	next := result.Slots[0].Start.Format("Monday 2 Jan at 15:04")
+	return "unavailable", fmt.Sprintf("%s Their next opening is %s. Would you like that instead?", message, next)
+}
+
+// RememberBookingDraft keeps a prepared draft for the turn that confirms it
+func RememberBookingDraft(conv *Conversation, draft BookingDraft) {
+	if conv.ShortTermMemory == nil {
+		conv.ShortTermMemory = make(map[string]interface{})
+	}
+	conv.ShortTermMemory[memoryBookingDraft] = draft
+}
+
+// BookingDraftFromMemory reads the draft prepared on an earlier turn,
+// accepting the map that a JSON round trip of short-term memory produces.
+// A draft without a vendor, service or date is treated as missing.
+func BookingDraftFromMemory(memory map[string]interface{}) (*BookingDraft, bool) {
+	var draft BookingDraft
+	switch v := memory[memoryBookingDraft].(type) {
+	case BookingDraft:
+		draft = v
+	case *BookingDraft:
+		if v == nil {
+			return nil, false
+		}
+		draft = *v
+	case map[string]interface{}:
+		raw, err := json.Marshal(v)
+		if err != nil || json.Unmarshal(raw, &draft) != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	if draft.VendorID == uuid.Nil || draft.ServiceID == uuid.Nil || draft.Date.IsZero() {
+		return nil, false
+	}
+	return &draft, true
+}
+
+// handleConfirmBooking books the draft the user just accepted
+func (dm *DialogManager) handleConfirmBooking(conv *Conversation) *ResponseStrategy {
+	return &ResponseStrategy{
+		Type:      ResponseText,
+		Template:  "booking_confirmed",
+		NextState: StateCompleted,
+		Actions: []ActionDefinition{
+			{Type: "confirm_booking"},
+		},
+	}
+}
+
+// confirmBooking books the conversation's draft, keeping the new booking's
+// ID in memory and dropping the draft so it cannot be booked twice
+func (ae *ActionExecutor) confirmBooking(ctx context.Context, conv *Conversation) (*ConfirmedBooking, *BookingDraft, error) {
+	draft, ok := BookingDraftFromMemory(conv.ShortTermMemory)
+	if !ok {
+		return nil, nil, ErrNoBookingDraft
+	}
+	if conv.UserID == AnonymousUserID {
+		return nil, draft, ErrBookingSignInRequired
+	}
+	if ae.bookingService == nil {
+		return nil, draft, ErrBookingUnavailable
+	}
+	
+	confirmed, err := ae.bookingService.CreateBooking(ctx, BookingRequest{
+		UserID:         conv.UserID,
+		VendorID:       draft.VendorID,
+		ServiceID:      draft.ServiceID,
+		Date:           draft.Date,
+		ConversationID: conv.ID,
+	})
+	if err != nil {
+		return nil, draft, err
+	}
+	
+	conv.ShortTermMemory[memoryBookingID] = confirmed.ID
+	delete(conv.ShortTermMemory, memoryBookingDraft)
+	return confirmed, draft, nil
+}
+
+// bookingConfirmedResults fills the booking_confirmed template
+func bookingConfirmedResults(results map[string]interface{}, conv *Conversation, confirmed *ConfirmedBooking, draft *BookingDraft) {
+	vendorName, _ := conv.ShortTermMemory["selected_vendor_name"].(string)
+	if vendorName == "" {
+		vendorName = "your vendor"
+	}
+	reference := confirmed.Reference
+	if reference == "" {
+		reference = confirmed.ID.String()
+	}
+	amount := confirmed.TotalAmount
+	if amount <= 0 {
+		amount = draft.TotalAmount
+	}
+	
+	results["booking_id"] = confirmed.ID
+	results["booking_reference"] = reference
+	results["vendor_name"] = vendorName
+	results["date"] = slotText(draft.Date)
+	results["price"] = BudgetAmount{Amount: amount, Currency: "NGN"}.String()
+}
+
+// ApplyBookingOutcome explains a booking that could not be made instead of
+// confirming it. Without a draft the user is sent back to choosing a
+// vendor; a failed booking can be retried.
+func ApplyBookingOutcome(strategy *ResponseStrategy, actionResults map[string]interface{}) *ResponseStrategy {
+	if strategy.Template != "booking_confirmed" {
+		return strategy
+	}
+	
+	adjusted := *strategy
+	switch {
+	case actionResults["booking_draft_missing"] == true:
+		adjusted.Template = "booking_draft_missing"
+		adjusted.NextState = StateRecommending
+		adjusted.QuickReplies = []QuickReply{
+			{Title: "Find a vendor", Payload: "intent:find_vendor"},
+			{Title: "Talk to a person", Payload: HandoffRequestPayload},
+		}
+	case actionResults["booking_sign_in_required"] == true:
+		adjusted.Template = "booking_sign_in_required"
+		adjusted.NextState = StateBooking
+		adjusted.QuickReplies = []QuickReply{
+			{Title: "I've signed in", Payload: BookingConfirmPayload},
+			{Title: "Talk to a person", Payload: HandoffRequestPayload},
+		}
+	case actionResults["booking_failed"] == true:
+		adjusted.Template = "booking_failed"
+		adjusted.NextState = StateBooking
+		adjusted.QuickReplies = []QuickReply{
+			{Title: "Try again", Payload: BookingConfirmPayload},
+			{Title: "Talk to a person", Payload: HandoffRequestPayload},
+		}
+	default:
+		return strategy
+	}
+	return &adjusted
+}
+
+/*
+================================================================================
+SECTION 3: API SPECIFICATION
+================================================================================
+*/
+
+// EventGPTAPI provides the API for EventGPT
+type EventGPTAPI struct {
+	dialogManager *DialogManager
+	db            *pgxpool.Pool
+	cache         *redis.Client
+}
+
+// NewEventGPTAPI wires a DialogManager with the full NLU pipeline, response
+// generator and action executor. A nil db keeps conversations in memory.
+func NewEventGPTAPI(db *pgxpool.Pool, cache *redis.Client) *EventGPTAPI {
+	return &EventGPTAPI{
+		dialogManager: NewDialogManager(db, cache),
+		db:            db,
+		cache:         cache,
+	}
+}
+
+// DialogManager exposes the dialog manager for configuration
+func (api *EventGPTAPI) DialogManager() *DialogManager {
+	return api.dialogManager
+}
+
+// ChatRequest for sending a message
+type ChatRequest struct {
+	ConversationID *uuid.UUID `json:"conversation_id,omitempty"`
+	Message        string     `json:"message"`
+	Channel        Channel    `json:"channel"`
+	Attachments    []Attachment `json:"attachments,omitempty"`
+}
+
+// ChatResponse from EventGPT
+type ChatResponse struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	Message        Message   `json:"message"`
+	EventID        *uuid.UUID `json:"event_id,omitempty"`
+	SessionType    SessionType `json:"session_type"`
+	State          ConversationState `json:"state"`
+}
+
+// Chat handles a chat message. A conversation ID with no dialog state yet,
+// such as one the welcome flow started, begins a new dialog under that ID;
+// one belonging to another user is refused with ErrConversationForbidden.
+func (api *EventGPTAPI) Chat(ctx context.Context, userID uuid.UUID, req ChatRequest) (*ChatResponse, error) {
+	// Get or create conversation
+	var conv *Conversation
+	var err error
+	
+	if req.ConversationID != nil {
+		// Hold the turn from load to save, so a double-sent message sees
+		// this turn's state rather than overwriting it
+		defer api.dialogManager.turns.acquire(*req.ConversationID)()
+		conv, err = api.loadConversation(ctx, *req.ConversationID)
+		if errors.Is(err, ErrConversationNotFound) {
+			conv = api.createConversation(userID, req.Channel)
+			conv.ID = *req.ConversationID
+		} else if err != nil {
+			return nil, err
+		} else if conv.UserID != userID {
+			return nil, ErrConversationForbidden
+		}
+	} else {
+		conv = api.createConversation(userID, req.Channel)
 	}
 	
 	// Process message
-	response, err := api.dialogManager.ProcessMessage(ctx, conv, req.Message)
+	response, err := api.dialogManager.processMessage(ctx, conv, req.Message)
 	if err != nil {
 		return nil, err
 	}
@@ -1862,9 +5309,50 @@ func (api *EventGPTAPI) Chat(ctx context.Context, userID uuid.UUID, req ChatRequ
 		Message:        *response,
 		EventID:        conv.EventID,
 		SessionType:    conv.SessionType,
+		State:          conv.ConversationState,
 	}, nil
 }
 
+// GetConversationUsage returns the metered usage for a conversation
+func (api *EventGPTAPI) GetConversationUsage(ctx context.Context, convID uuid.UUID) (*ConversationUsage, error) {
+	meter := api.dialogManager.usage
+	if meter == nil {
+		meter = NewUsageMeter(api.db, nil)
+	}
+	return meter.ConversationUsage(ctx, convID)
+}
+
+// GetProcessingTimeMetrics reports p50/p95 response processing time per
+// intent and action type over a period
+func (api *EventGPTAPI) GetProcessingTimeMetrics(ctx context.Context, from, to time.Time) (*ProcessingTimeReport, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("period start must be before its end")
+	}
+	metrics := api.dialogManager.metrics
+	if metrics == nil {
+		metrics = NewProcessingMetrics(api.db)
+	}
+	return metrics.Report(ctx, from, to)
+}
+
+// GetSavedVendors lists the vendors a user saved from chat, newest first
+func (api *EventGPTAPI) GetSavedVendors(ctx context.Context, userID uuid.UUID) ([]SavedVendor, error) {
+	store := api.dialogManager.savedVendors
+	if store == nil {
+		store = NewPostgresSavedVendors(api.db)
+	}
+	return store.SavedVendors(ctx, userID)
+}
+
+// GetHandoffs lists conversations queued for a support agent, oldest first
+func (api *EventGPTAPI) GetHandoffs(ctx context.Context, status HandoffStatus) ([]Handoff, error) {
+	store := api.dialogManager.handoffs
+	if store == nil {
+		store = NewPostgresHandoffStore(api.db)
+	}
+	return store.Handoffs(ctx, status)
+}
+
 func (api *EventGPTAPI) createConversation(userID uuid.UUID, channel Channel) *Conversation {
 	return &Conversation{
 		ID:                uuid.New(),
@@ -1883,35 +5371,10 @@ func (api *EventGPTAPI) createConversation(userID uuid.UUID, channel Channel) *C
 }
 
 func (api *EventGPTAPI) loadConversation(ctx context.Context, convID uuid.UUID) (*Conversation, error) {
-	query := `
-		SELECT id, user_id, event_id, session_type,
-		       current_intent, conversation_state, slot_values,
-		       messages, turn_count, short_term_memory,
-		       language, channel, started_at, last_message_at
-		FROM conversations
-		WHERE id = $1
-	`
-	
-	var conv Conversation
-	var intentJSON, slotsJSON, messagesJSON, memoryJSON []byte
-	
-	err := api.db.QueryRow(ctx, query, convID).Scan(
-		&conv.ID, &conv.UserID, &conv.EventID, &conv.SessionType,
-		&intentJSON, &conv.ConversationState, &slotsJSON,
-		&messagesJSON, &conv.TurnCount, &memoryJSON,
-		&conv.Language, &conv.Channel, &conv.StartedAt, &conv.LastMessageAt,
-	)
-	
-	if err != nil {
-		return nil, err
+	if api.dialogManager.conversations == nil {
+		return nil, ErrConversationNotFound
 	}
-	
-	json.Unmarshal(intentJSON, &conv.CurrentIntent)
-	json.Unmarshal(slotsJSON, &conv.SlotValues)
-	json.Unmarshal(messagesJSON, &conv.Messages)
-	json.Unmarshal(memoryJSON, &conv.ShortTermMemory)
-	
-	return &conv, nil
+	return api.dialogManager.conversations.LoadConversation(ctx, convID)
 }
 
 /*
@@ -134,6 +134,7 @@ type GetRecommendationsRequest struct {
 	Limit             int      `json:"limit,omitempty"`
 	ExcludeIDs        []string `json:"exclude_ids,omitempty"`
 	DiversityFactor   float64  `json:"diversity_factor,omitempty"`
+	Explain           bool     `json:"explain,omitempty"`
 }
 
 func (s *Server) getRecommendations(w http.ResponseWriter, r *http.Request) {
@@ -148,6 +149,7 @@ func (s *Server) getRecommendations(w http.ResponseWriter, r *http.Request) {
 		Limit:           req.Limit,
 		DiversityFactor: req.DiversityFactor,
 		EventType:       req.EventType,
+		Explain:         req.Explain,
 	}
 
 	// Parse UUIDs
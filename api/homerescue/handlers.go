@@ -2,28 +2,110 @@
 package homerescue
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
 	"github.com/BillyRonksGlobal/vendorplatform/internal/homerescue"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/middleware"
 )
 
 // Handler handles HomeRescue HTTP requests
 type Handler struct {
-	service *homerescue.Service
-	logger  *zap.Logger
+	service  *homerescue.Service
+	dispatch *DispatchEngine
+	logger   *zap.Logger
+	auth     gin.HandlerFunc
+
+	// Emergency creation
+	creator     EmergencyCreator
+	idempotency *redis.Client
+
+	// Live tracking streams
+	emergencies EmergencyLookup
+	tracking    TrackingSubscriber
+	locations   LocationPublisher
+}
+
+// EmergencyCreator creates emergencies and starts matching technicians to
+// them
+type EmergencyCreator interface {
+	CreateEmergency(ctx context.Context, req *homerescue.CreateEmergencyRequest) (*homerescue.Emergency, error)
+}
+
+// EmergencyLookup finds an emergency to check who may follow it
+type EmergencyLookup interface {
+	GetEmergency(ctx context.Context, id uuid.UUID) (*homerescue.Emergency, error)
+}
+
+// TrackingSubscriber streams an emergency's tracking updates until ctx ends
+type TrackingSubscriber interface {
+	SubscribeToTracking(ctx context.Context, requestID uuid.UUID) (<-chan TrackingUpdate, error)
+}
+
+// LocationPublisher publishes a technician's reported location to the
+// emergency's tracking stream
+type LocationPublisher interface {
+	PublishEmergencyLocation(ctx context.Context, emergency *homerescue.Emergency, lat, lon float64)
 }
 
 // NewHandler creates a new HomeRescue handler
-func NewHandler(service *homerescue.Service, logger *zap.Logger) *Handler {
-	return &Handler{
-		service: service,
-		logger:  logger,
+func NewHandler(service *homerescue.Service, dispatch *DispatchEngine, logger *zap.Logger) *Handler {
+	h := &Handler{
+		service:  service,
+		dispatch: dispatch,
+		logger:   logger,
+	}
+	if service != nil {
+		h.creator = service
+		h.emergencies = service
 	}
+	return h
+}
+
+// SetAuthMiddleware sets the middleware that authenticates callers of
+// protected routes; it must be set before RegisterRoutes, and without it
+// those routes reject every request
+func (h *Handler) SetAuthMiddleware(mw gin.HandlerFunc) {
+	h.auth = mw
+}
+
+// SetEmergencyCreator replaces what creates new emergencies
+func (h *Handler) SetEmergencyCreator(creator EmergencyCreator) {
+	h.creator = creator
+}
+
+// SetIdempotencyCache sets where Idempotency-Key claims for new emergencies
+// are kept; without one the header is ignored
+func (h *Handler) SetIdempotencyCache(cache *redis.Client) {
+	h.idempotency = cache
+}
+
+// SetEmergencyLookup replaces where tracking streams and idempotent retries
+// look up emergencies
+func (h *Handler) SetEmergencyLookup(lookup EmergencyLookup) {
+	h.emergencies = lookup
+}
+
+// SetTrackingSubscriber sets where tracking streams get their updates;
+// without one the stream endpoint is unavailable
+func (h *Handler) SetTrackingSubscriber(tracking TrackingSubscriber) {
+	h.tracking = tracking
+}
+
+// SetLocationPublisher sets where technician location updates are published
+// for tracking streams; without one they are only saved
+func (h *Handler) SetLocationPublisher(locations LocationPublisher) {
+	h.locations = locations
 }
 
 // RegisterRoutes registers emergency routes
@@ -31,39 +113,53 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	emergency := router.Group("/homerescue")
 	{
 		// Emergency creation and management
+		emergency.GET("/intake/:category", h.GetIntakeForm)
 		emergency.POST("/emergencies", h.CreateEmergency)
-		emergency.GET("/emergencies/:id", h.GetEmergency)
-		emergency.GET("/emergencies/:id/status", h.GetEmergencyStatus)
-		emergency.GET("/emergencies/:id/tracking", h.GetTracking)
-		emergency.GET("/emergencies/:id/sla", h.GetSLAMetrics)
+		emergency.POST("/emergencies/reorder/:previousId", middleware.RequireAuth(h.auth), middleware.UUIDParams("previousId"), h.ReorderEmergency)
+		emergency.GET("/emergencies/:id", middleware.UUIDParams("id"), h.GetEmergency)
+		emergency.GET("/emergencies/:id/status", middleware.UUIDParams("id"), h.GetEmergencyStatus)
+		emergency.GET("/emergencies/:id/tracking", middleware.UUIDParams("id"), h.GetTracking)
+		emergency.GET("/emergencies/:id/tracking/stream", middleware.RequireAuth(h.auth), middleware.UUIDParams("id"), h.StreamTracking)
+		emergency.GET("/emergencies/:id/sla", middleware.UUIDParams("id"), h.GetSLAMetrics)
 
 		// Technician actions (in production, requires auth)
 		emergency.POST("/technicians/location", h.UpdateTechLocation)
-		emergency.PUT("/emergencies/:id/accept", h.AcceptEmergency)
-		emergency.PUT("/emergencies/:id/complete", h.CompleteEmergency)
+		emergency.PUT("/emergencies/:id/accept", middleware.UUIDParams("id"), h.AcceptEmergency)
+		emergency.PUT("/emergencies/:id/decline", middleware.RequireAuth(h.auth), middleware.UUIDParams("id"), h.DeclineEmergency)
+		emergency.PUT("/emergencies/:id/complete", middleware.UUIDParams("id"), h.CompleteEmergency)
 
 		// Technician availability management
-		emergency.PUT("/technicians/:id/availability", h.UpdateTechAvailability)
+		emergency.PUT("/technicians/:id/availability", middleware.UUIDParams("id"), h.UpdateTechAvailability)
+
+		// Support escalations, worked by support agents and admins
+		support := emergency.Group("/support", middleware.RequireAuth(h.auth), auth.RequireRole(auth.RoleSupport, auth.RoleAdmin))
+		support.GET("/queue", h.GetSupportQueue)
+		support.POST("/queue/:id/claim", middleware.UUIDParams("id"), h.ClaimEscalation)
+		support.POST("/queue/:id/assign", middleware.UUIDParams("id"), h.AssignEscalation)
 	}
 }
 
-// CreateEmergency handles POST /homerescue/emergencies
+// CreateEmergency handles POST /homerescue/emergencies. A repeat of a user's
+// Idempotency-Key returns the emergency the first request created instead of
+// creating and dispatching another.
 func (h *Handler) CreateEmergency(c *gin.Context) {
 	var req struct {
-		UserID             string  `json:"user_id" binding:"required"`
-		Category           string  `json:"category" binding:"required"`
-		Subcategory        string  `json:"subcategory"`
-		Urgency            string  `json:"urgency" binding:"required"`
-		Title              string  `json:"title" binding:"required"`
-		Description        string  `json:"description" binding:"required"`
-		Address            string  `json:"address" binding:"required"`
-		Unit               string  `json:"unit"`
-		City               string  `json:"city" binding:"required"`
-		State              string  `json:"state" binding:"required"`
-		PostalCode         string  `json:"postal_code" binding:"required"`
-		Latitude           float64 `json:"latitude" binding:"required"`
-		Longitude          float64 `json:"longitude" binding:"required"`
-		AccessInstructions string  `json:"access_instructions"`
+		UserID             string            `json:"user_id" binding:"required"`
+		Category           string            `json:"category" binding:"required"`
+		Subcategory        string            `json:"subcategory"`
+		Urgency            string            `json:"urgency" binding:"required"`
+		Title              string            `json:"title" binding:"required"`
+		Description        string            `json:"description" binding:"required"`
+		Address            string            `json:"address" binding:"required"`
+		Unit               string            `json:"unit"`
+		City               string            `json:"city" binding:"required"`
+		State              string            `json:"state" binding:"required"`
+		PostalCode         string            `json:"postal_code" binding:"required"`
+		Latitude           float64           `json:"latitude" binding:"required"`
+		Longitude          float64           `json:"longitude" binding:"required"`
+		AccessInstructions string            `json:"access_instructions"`
+		ContactPhone       string            `json:"contact_phone"`
+		IntakeAnswers      map[string]string `json:"intake_answers"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -112,29 +208,182 @@ func (h *Handler) CreateEmergency(c *gin.Context) {
 		Latitude:           req.Latitude,
 		Longitude:          req.Longitude,
 		AccessInstructions: req.AccessInstructions,
+		ContactPhone:       req.ContactPhone,
+		IntakeAnswers:      req.IntakeAnswers,
 	}
 
-	emergency, err := h.service.CreateEmergency(c.Request.Context(), createReq)
+	ctx := c.Request.Context()
+	idempotencyKey := ""
+	if h.idempotency != nil {
+		idempotencyKey = c.GetHeader(IdempotencyKeyHeader)
+	}
+	if idempotencyKey != "" {
+		existing, claimed, err := h.claimIdempotencyKey(ctx, userID, idempotencyKey)
+		if errors.Is(err, ErrIdempotencyInFlight) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			h.logger.Error("Failed to claim idempotency key", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create emergency"})
+			return
+		}
+		if !claimed {
+			c.JSON(http.StatusCreated, gin.H{
+				"emergency": existing,
+				"message":   "Emergency created. Searching for available technicians...",
+			})
+			return
+		}
+	}
+
+	emergency, err := h.creator.CreateEmergency(ctx, createReq)
 	if err != nil {
+		// Free the key so the client's retry can try again
+		if idempotencyKey != "" {
+			h.idempotency.Del(ctx, idempotencyCacheKey(userID, idempotencyKey))
+		}
+		if err == homerescue.ErrInvalidContactPhone {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact phone number"})
+			return
+		}
+		if errors.Is(err, homerescue.ErrInvalidIntake) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to create emergency", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create emergency"})
 		return
 	}
 
+	if idempotencyKey != "" {
+		err := h.idempotency.Set(ctx, idempotencyCacheKey(userID, idempotencyKey), emergency.ID.String(), IdempotencyKeyTTL).Err()
+		if err != nil {
+			h.logger.Warn("Failed to record idempotency key",
+				zap.String("emergency_id", emergency.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"emergency": emergency,
 		"message":   "Emergency created. Searching for available technicians...",
 	})
 }
 
-// GetEmergency handles GET /homerescue/emergencies/:id
-func (h *Handler) GetEmergency(c *gin.Context) {
-	emergencyID, err := uuid.Parse(c.Param("id"))
+// IdempotencyKeyHeader carries the client's key for a create, so a retried
+// POST returns the emergency it already made
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyKeyTTL is how long a key keeps pointing at its emergency
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyClaimTTL bounds how long a key stays claimed by a create that
+// never finished, such as one on a server that crashed mid-request
+const idempotencyClaimTTL = time.Minute
+
+// idempotencyPending marks a key whose emergency is still being created
+const idempotencyPending = "pending"
+
+// ErrIdempotencyInFlight is returned when a repeat of a key arrives while
+// the first create with it is still running
+var ErrIdempotencyInFlight = errors.New("an emergency with this idempotency key is still being created")
+
+// idempotencyCacheKey scopes a client key to the user who sent it
+func idempotencyCacheKey(userID uuid.UUID, key string) string {
+	return fmt.Sprintf("homerescue:idempotency:%s:%s", userID, key)
+}
+
+// claimIdempotencyKey claims the user's key for a new emergency. If the key
+// was already used it returns the emergency it points at instead.
+func (h *Handler) claimIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) (*homerescue.Emergency, bool, error) {
+	cacheKey := idempotencyCacheKey(userID, key)
+	claimed, err := h.idempotency.SetNX(ctx, cacheKey, idempotencyPending, idempotencyClaimTTL).Result()
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid emergency ID"})
+		return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if claimed {
+		return nil, true, nil
+	}
+
+	value, err := h.idempotency.Get(ctx, cacheKey).Result()
+	if errors.Is(err, redis.Nil) || value == idempotencyPending {
+		// Still being created, or the first create just failed and freed it
+		return nil, false, ErrIdempotencyInFlight
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+	emergencyID, err := uuid.Parse(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("corrupt idempotency key: %w", err)
+	}
+	existing, err := h.emergencies.GetEmergency(ctx, emergencyID)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// GetIntakeForm handles GET /homerescue/intake/:category
+func (h *Handler) GetIntakeForm(c *gin.Context) {
+	category := c.Param("category")
+
+	form, ok := homerescue.IntakeForms[category]
+	if !ok {
+		// Categories without structured intake rely on the description alone
+		form = homerescue.IntakeForm{Category: category, Questions: []homerescue.IntakeQuestion{}}
+	}
+
+	c.JSON(http.StatusOK, form)
+}
+
+// ReorderEmergency handles POST /homerescue/emergencies/reorder/:previousId.
+// Only the authenticated owner of the previous emergency can reorder it.
+func (h *Handler) ReorderEmergency(c *gin.Context) {
+	previousID := middleware.ParamUUID(c, "previousId")
+
+	userID, err := auth.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var overrides homerescue.ReorderOverrides
+	if err := c.ShouldBindJSON(&overrides); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	emergency, err := h.service.ReorderEmergency(c.Request.Context(), previousID, userID, overrides)
+	if err != nil {
+		switch err {
+		case homerescue.ErrEmergencyNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Emergency not found"})
+		case homerescue.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Emergency belongs to another user"})
+		case homerescue.ErrNotReorderable:
+			c.JSON(http.StatusConflict, gin.H{"error": "Only completed emergencies can be reordered"})
+		case homerescue.ErrInvalidUrgency:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid urgency level"})
+		default:
+			h.logger.Error("Failed to reorder emergency", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create emergency"})
+		}
 		return
 	}
 
+	c.JSON(http.StatusCreated, gin.H{
+		"emergency": emergency,
+		"message":   "Emergency created from your previous request. Searching for available technicians...",
+	})
+}
+
+// GetEmergency handles GET /homerescue/emergencies/:id
+func (h *Handler) GetEmergency(c *gin.Context) {
+	emergencyID := middleware.ParamUUID(c, "id")
+
 	emergency, err := h.service.GetEmergency(c.Request.Context(), emergencyID)
 	if err != nil {
 		if err == homerescue.ErrEmergencyNotFound {
@@ -151,11 +400,7 @@ func (h *Handler) GetEmergency(c *gin.Context) {
 
 // GetEmergencyStatus handles GET /homerescue/emergencies/:id/status
 func (h *Handler) GetEmergencyStatus(c *gin.Context) {
-	emergencyID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid emergency ID"})
-		return
-	}
+	emergencyID := middleware.ParamUUID(c, "id")
 
 	status, err := h.service.GetEmergencyStatus(c.Request.Context(), emergencyID)
 	if err != nil {
@@ -173,11 +418,7 @@ func (h *Handler) GetEmergencyStatus(c *gin.Context) {
 
 // GetTracking handles GET /homerescue/emergencies/:id/tracking
 func (h *Handler) GetTracking(c *gin.Context) {
-	emergencyID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid emergency ID"})
-		return
-	}
+	emergencyID := middleware.ParamUUID(c, "id")
 
 	tracking, err := h.service.GetEmergencyTracking(c.Request.Context(), emergencyID)
 	if err != nil {
@@ -193,13 +434,110 @@ func (h *Handler) GetTracking(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"tracking": tracking})
 }
 
-// GetSLAMetrics handles GET /homerescue/emergencies/:id/sla
-func (h *Handler) GetSLAMetrics(c *gin.Context) {
-	emergencyID, err := uuid.Parse(c.Param("id"))
+// StreamTracking handles GET /homerescue/emergencies/:id/tracking/stream. It
+// upgrades to a WebSocket and sends the request owner each TrackingUpdate as
+// a JSON frame until they disconnect or the request completes.
+func (h *Handler) StreamTracking(c *gin.Context) {
+	emergencyID := middleware.ParamUUID(c, "id")
+
+	userID, err := auth.GetUserFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid emergency ID"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	if h.emergencies == nil || h.tracking == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Live tracking is unavailable"})
+		return
+	}
+
+	emergency, err := h.emergencies.GetEmergency(c.Request.Context(), emergencyID)
+	if err != nil {
+		if err == homerescue.ErrEmergencyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Emergency not found"})
+			return
+		}
+		h.logger.Error("Failed to get emergency", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve emergency"})
 		return
 	}
+	if emergency.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Emergency belongs to another user"})
+		return
+	}
+	if trackingFinished(emergency.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Emergency is already " + emergency.Status})
+		return
+	}
+
+	// Cancelling unsubscribes, so both ends of the stream stop it
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	updates, err := h.tracking.SubscribeToTracking(ctx, emergencyID)
+	if err != nil {
+		h.logger.Error("Failed to subscribe to tracking", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start tracking"})
+		return
+	}
+
+	// The default origin check accepts clients that send no Origin, such as
+	// the mobile apps, and rejects browser pages served from other hosts
+	ws, err := trackingUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written the error response
+		return
+	}
+	defer ws.Close()
+
+	// Clients only ever close the stream; a failed read means they did
+	go func() {
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(trackingWriteTimeout))
+			if err := ws.WriteJSON(update); err != nil {
+				return
+			}
+			if trackingFinished(update.Status) {
+				ws.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, update.Status),
+					time.Now().Add(trackingWriteTimeout))
+				return
+			}
+		}
+	}
+}
+
+// trackingWriteTimeout bounds how long a slow client can hold up a frame
+const trackingWriteTimeout = 10 * time.Second
+
+var trackingUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// trackingFinished reports whether a request in this status has no more
+// tracking to stream
+func trackingFinished(status string) bool {
+	return status == "completed" || status == "cancelled"
+}
+
+// GetSLAMetrics handles GET /homerescue/emergencies/:id/sla
+func (h *Handler) GetSLAMetrics(c *gin.Context) {
+	emergencyID := middleware.ParamUUID(c, "id")
 
 	metrics, err := h.service.GetSLAMetrics(c.Request.Context(), emergencyID)
 	if err != nil {
@@ -244,17 +582,28 @@ func (h *Handler) UpdateTechLocation(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update location"})
 		return
 	}
+	h.publishLocation(c.Request.Context(), emergencyID, req.Latitude, req.Longitude)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Location updated successfully"})
 }
 
-// AcceptEmergency handles PUT /homerescue/emergencies/:id/accept
-func (h *Handler) AcceptEmergency(c *gin.Context) {
-	emergencyID, err := uuid.Parse(c.Param("id"))
+// publishLocation sends a saved location update to the emergency's tracking
+// stream. The update is already saved, so a failure here is only logged.
+func (h *Handler) publishLocation(ctx context.Context, emergencyID uuid.UUID, lat, lon float64) {
+	if h.locations == nil || h.emergencies == nil {
+		return
+	}
+	emergency, err := h.emergencies.GetEmergency(ctx, emergencyID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid emergency ID"})
+		h.logger.Warn("Failed to load emergency for tracking", zap.Error(err))
 		return
 	}
+	h.locations.PublishEmergencyLocation(ctx, emergency, lat, lon)
+}
+
+// AcceptEmergency handles PUT /homerescue/emergencies/:id/accept
+func (h *Handler) AcceptEmergency(c *gin.Context) {
+	emergencyID := middleware.ParamUUID(c, "id")
 
 	var req struct {
 		TechnicianID     string `json:"technician_id" binding:"required"`
@@ -285,20 +634,59 @@ func (h *Handler) AcceptEmergency(c *gin.Context) {
 		return
 	}
 
+	// Wake the dispatcher waiting on this offer; if it misses the message the
+	// offer times out and moves on
+	if err := h.dispatch.PublishTechResponse(c.Request.Context(), emergencyID, techID, TechResponseAccepted); err != nil {
+		h.logger.Warn("Failed to publish tech response", zap.Error(err))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":           "Emergency accepted",
 		"estimated_arrival": estimatedArrival,
 	})
 }
 
-// CompleteEmergency handles PUT /homerescue/emergencies/:id/complete
-func (h *Handler) CompleteEmergency(c *gin.Context) {
-	emergencyID, err := uuid.Parse(c.Param("id"))
+// DeclineEmergency handles PUT /homerescue/emergencies/:id/decline. Only the
+// technician holding the offer, as the authenticated caller, can decline it.
+func (h *Handler) DeclineEmergency(c *gin.Context) {
+	emergencyID := middleware.ParamUUID(c, "id")
+
+	techID, err := auth.GetUserFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid emergency ID"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	err = h.dispatch.DeclineAssignment(c.Request.Context(), emergencyID, techID, req.Reason)
+	switch {
+	case errors.Is(err, ErrRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Emergency not found"})
+		return
+	case errors.Is(err, ErrNotAssignedTech):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.logger.Error("Failed to decline emergency", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline emergency"})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"message": "Emergency declined"})
+}
+
+// CompleteEmergency handles PUT /homerescue/emergencies/:id/complete
+func (h *Handler) CompleteEmergency(c *gin.Context) {
+	emergencyID := middleware.ParamUUID(c, "id")
+
 	var req struct {
 		TechnicianID string  `json:"technician_id" binding:"required"`
 		WorkNotes    string  `json:"work_notes" binding:"required"`
@@ -336,11 +724,7 @@ func (h *Handler) CompleteEmergency(c *gin.Context) {
 
 // UpdateTechAvailability handles PUT /homerescue/technicians/:id/availability
 func (h *Handler) UpdateTechAvailability(c *gin.Context) {
-	techID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid technician ID"})
-		return
-	}
+	techID := middleware.ParamUUID(c, "id")
 
 	var req struct {
 		IsAvailable bool `json:"is_available"`
@@ -351,7 +735,7 @@ func (h *Handler) UpdateTechAvailability(c *gin.Context) {
 		return
 	}
 
-	err = h.service.UpdateTechnicianAvailability(c.Request.Context(), techID, req.IsAvailable)
+	err := h.service.UpdateTechnicianAvailability(c.Request.Context(), techID, req.IsAvailable)
 	if err != nil {
 		h.logger.Error("Failed to update tech availability", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability"})
@@ -363,3 +747,82 @@ func (h *Handler) UpdateTechAvailability(c *gin.Context) {
 		"is_available": req.IsAvailable,
 	})
 }
+
+// GetSupportQueue handles GET /homerescue/support/queue
+func (h *Handler) GetSupportQueue(c *gin.Context) {
+	tickets, err := h.dispatch.SupportQueue(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to load support queue", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load support queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tickets": tickets})
+}
+
+// ClaimEscalation handles POST /homerescue/support/queue/:id/claim for the
+// calling agent
+func (h *Handler) ClaimEscalation(c *gin.Context) {
+	requestID := middleware.ParamUUID(c, "id")
+
+	agentID, err := auth.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	ticket, err := h.dispatch.ClaimEscalation(c.Request.Context(), requestID, agentID)
+	if err != nil {
+		h.respondEscalationError(c, err, "Failed to claim escalation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ticket": ticket})
+}
+
+// AssignEscalation handles POST /homerescue/support/queue/:id/assign for the
+// calling agent, who must hold the claim
+func (h *Handler) AssignEscalation(c *gin.Context) {
+	requestID := middleware.ParamUUID(c, "id")
+
+	agentID, err := auth.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var req struct {
+		TechnicianID string `json:"technician_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	techID, err := uuid.Parse(req.TechnicianID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid technician ID"})
+		return
+	}
+
+	result, err := h.dispatch.AssignFromSupport(c.Request.Context(), requestID, agentID, techID)
+	if err != nil {
+		h.respondEscalationError(c, err, "Failed to assign technician")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) respondEscalationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrTicketNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Escalated request not found"})
+	case errors.Is(err, ErrTicketClaimed), errors.Is(err, ErrTicketNotClaimed), errors.Is(err, ErrTicketAssigned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -9,6 +9,7 @@ package homerescue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -16,8 +17,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/homerescue"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/geo"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/lock"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/phone"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/tracing"
 )
 
 /*
@@ -89,6 +98,7 @@ type EmergencyRequest struct {
 	Category            EmergencyCategory      `json:"category"`
 	Subcategory         string                 `json:"subcategory"`
 	Urgency             UrgencyLevel           `json:"urgency"`
+	IntakeAnswers       map[string]string      `json:"intake_answers,omitempty"` // question id -> answer
 	
 	// Description
 	Title               string                 `json:"title"`
@@ -99,6 +109,7 @@ type EmergencyRequest struct {
 	// Location
 	Location            EmergencyLocation      `json:"location"`
 	AccessInstructions  string                 `json:"access_instructions"`
+	ContactPhone        string                 `json:"contact_phone"`
 	
 	// Status & Timeline
 	Status              RequestStatus          `json:"status"`
@@ -114,6 +125,7 @@ type EmergencyRequest struct {
 	ArrivalDeadline     time.Time              `json:"arrival_deadline"`
 	ActualResponseTime  *time.Time             `json:"actual_response_time,omitempty"`
 	ActualArrivalTime   *time.Time             `json:"actual_arrival_time,omitempty"`
+	SLABreached         bool                   `json:"sla_breached"`
 	
 	// Work Details
 	DiagnosisNotes      string                 `json:"diagnosis_notes,omitempty"`
@@ -228,10 +240,7 @@ type StatusUpdate struct {
 	Location   *GeoPoint     `json:"location,omitempty"`
 }
 
-type GeoPoint struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-}
+type GeoPoint = geo.GeoPoint
 
 type Assignment struct {
 	VendorID    uuid.UUID  `json:"vendor_id"`
@@ -268,6 +277,8 @@ type FinalPrice struct {
 	LaborHours      float64 `json:"labor_hours"`
 	PartsCost       float64 `json:"parts_cost"`
 	EmergencyPremium float64 `json:"emergency_premium"`
+	DemandMultiplier float64 `json:"demand_multiplier"`
+	DemandSurcharge float64 `json:"demand_surcharge,omitempty"`
 	Subtotal        float64 `json:"subtotal"`
 	Tax             float64 `json:"tax"`
 	Discount        float64 `json:"discount"`
@@ -294,6 +305,7 @@ type EmergencyTechnician struct {
 	Categories          []EmergencyCategory    `json:"categories"`
 	Certifications      []Certification        `json:"certifications"`
 	EquipmentList       []string               `json:"equipment_list"`
+	Skills              []string               `json:"skills"` // e.g. "water_heater", "panel_upgrade"
 	
 	// Availability
 	IsOnline            bool                   `json:"is_online"`
@@ -370,6 +382,19 @@ type DispatchEngine struct {
 	notificationSvc  *NotificationService
 	pricingEngine    *EmergencyPricingEngine
 	
+	// Keeps escalation to one instance per request
+	locker           lock.Locker
+	
+	// Where escalated requests wait for a support agent
+	supportQueue     SupportQueue
+	
+	// Arrival SLA breach detection and refunds
+	slaStore         ArrivalSLAStore
+	paymentSvc       PaymentService
+	
+	// Where dispatch progress survives restarts
+	stateStore       DispatchStateStore
+	
 	// Configuration
 	config           *DispatchConfig
 	
@@ -386,6 +411,8 @@ type DispatchConfig struct {
 	MaxAssignmentAttempts int
 	AssignmentTimeout   time.Duration
 	AutoEscalateAfter   time.Duration
+	EscalationLockTTL   time.Duration // how long one escalation holds off another for the same request
+	ResumeLockTTL       time.Duration // how long a restarted instance owns the requests it resumes
 }
 
 type TechState struct {
@@ -404,9 +431,10 @@ type RequestState struct {
 
 // NewDispatchEngine creates a new dispatch engine
 func NewDispatchEngine(db *pgxpool.Pool, cache *redis.Client) *DispatchEngine {
-	return &DispatchEngine{
-		db:    db,
-		cache: cache,
+	e := &DispatchEngine{
+		db:            db,
+		cache:         cache,
+		pricingEngine: NewEmergencyPricingEngine(db, cache),
 		config: &DispatchConfig{
 			MaxSearchRadius:     50.0,
 			InitialSearchRadius: 5.0,
@@ -414,10 +442,32 @@ func NewDispatchEngine(db *pgxpool.Pool, cache *redis.Client) *DispatchEngine {
 			MaxAssignmentAttempts: 10,
 			AssignmentTimeout:   2 * time.Minute,
 			AutoEscalateAfter:   5 * time.Minute,
+			EscalationLockTTL:   30 * time.Minute,
+			ResumeLockTTL:       5 * time.Minute,
 		},
 		activeTechs:    make(map[uuid.UUID]*TechState),
 		activeRequests: make(map[uuid.UUID]*RequestState),
 	}
+	if cache != nil {
+		e.locker = lock.NewRedisLocker(cache)
+	}
+	if db != nil {
+		e.supportQueue = NewPostgresSupportQueue(db)
+		e.stateStore = NewPostgresDispatchStateStore(db)
+	}
+	return e
+}
+
+// SetEscalationLocker replaces the lock used to keep escalation, and resuming
+// dispatch after a restart, to a single instance per request
+func (e *DispatchEngine) SetEscalationLocker(locker lock.Locker) {
+	e.locker = locker
+}
+
+// SetSupportQueue replaces where escalated requests wait for support; nil
+// leaves escalations as alerts only
+func (e *DispatchEngine) SetSupportQueue(queue SupportQueue) {
+	e.supportQueue = queue
 }
 
 // DispatchResult represents the outcome of a dispatch attempt
@@ -432,25 +482,47 @@ type DispatchResult struct {
 
 type TechCandidate struct {
 	TechID          uuid.UUID `json:"tech_id"`
+	VendorID        uuid.UUID `json:"vendor_id"`
 	TechName        string    `json:"tech_name"`
 	Distance        float64   `json:"distance_km"`
 	EstimatedArrival int      `json:"estimated_arrival_minutes"`
 	Rating          float64   `json:"rating"`
 	Price           float64   `json:"estimated_price"`
+	Skills          []string  `json:"skills,omitempty"`
+	SkillMatch      float64   `json:"skill_match"` // share of the request's skills the tech has
+	OnCall          bool      `json:"on_call,omitempty"`
+	OnCallPremium   float64   `json:"on_call_premium,omitempty"` // percent, included in Price
+	
+	// Declared coverage, used to drop techs who don't serve the request area
+	HomeBase        *GeoPoint `json:"-"`
+	ServiceRadius   float64   `json:"-"`
+	
+	// Schedule, used to drop techs who are off shift
+	WorkingHours    []WorkingHours `json:"-"`
+	OnCallSchedule  []OnCallPeriod `json:"-"`
 }
 
 // Dispatch attempts to assign a technician to an emergency request
 func (e *DispatchEngine) Dispatch(ctx context.Context, request *EmergencyRequest) (*DispatchResult, error) {
+	ctx, span := tracing.Start(ctx, "homerescue.Dispatch",
+		attribute.String("request.id", request.ID.String()),
+		attribute.String("request.category", string(request.Category)),
+		attribute.String("request.urgency", string(request.Urgency)),
+	)
+	defer span.End()
+	
 	result := &DispatchResult{
 		RequestID: request.ID,
 	}
 	
-	// Track request state
+	// Track request state, keeping the progress of an earlier pass
 	e.mu.Lock()
-	e.activeRequests[request.ID] = &RequestState{
-		Request:             request,
-		AssignmentAttempts:  0,
-		CurrentSearchRadius: e.config.InitialSearchRadius,
+	if e.activeRequests[request.ID] == nil {
+		e.activeRequests[request.ID] = &RequestState{
+			Request:             request,
+			AssignmentAttempts:  0,
+			CurrentSearchRadius: e.config.InitialSearchRadius,
+		}
 	}
 	e.mu.Unlock()
 	
@@ -461,8 +533,11 @@ func (e *DispatchEngine) Dispatch(ctx context.Context, request *EmergencyRequest
 	// Find candidates
 	candidates, err := e.findCandidates(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find candidates: %w", err)
+		err = fmt.Errorf("failed to find candidates: %w", err)
+		tracing.End(span, err)
+		return nil, err
 	}
+	span.SetAttributes(attribute.Int("candidates.count", len(candidates)))
 	
 	if len(candidates) == 0 {
 		result.Success = false
@@ -481,11 +556,13 @@ func (e *DispatchEngine) Dispatch(ctx context.Context, request *EmergencyRequest
 		}
 		
 		if assigned {
+			span.SetAttributes(attribute.String("tech.id", candidate.TechID.String()))
 			eta := time.Now().Add(time.Duration(candidate.EstimatedArrival) * time.Minute)
 			result.Success = true
 			result.AssignedTechID = &candidate.TechID
 			result.EstimatedArrival = &eta
 			result.Message = fmt.Sprintf("%s is on the way! ETA: %d minutes", candidate.TechName, candidate.EstimatedArrival)
+			request.EstimatedCost = e.pricingEngine.Estimate(ctx, request, candidate.VendorID, candidate.Distance)
 			
 			// Store alternatives for customer visibility
 			if len(candidates) > 1 {
@@ -507,16 +584,32 @@ func (e *DispatchEngine) Dispatch(ctx context.Context, request *EmergencyRequest
 	return result, nil
 }
 
-func (e *DispatchEngine) findCandidates(ctx context.Context, request *EmergencyRequest) ([]TechCandidate, error) {
+func (e *DispatchEngine) findCandidates(ctx context.Context, request *EmergencyRequest) (candidates []TechCandidate, err error) {
 	e.mu.RLock()
 	state := e.activeRequests[request.ID]
 	searchRadius := state.CurrentSearchRadius
 	e.mu.RUnlock()
 	
+	// Nothing to search without a database
+	if e.db == nil {
+		return nil, nil
+	}
+	
+	ctx, span := tracing.Start(ctx, "homerescue.find_candidates",
+		attribute.String("request.id", request.ID.String()),
+		attribute.String("request.category", string(request.Category)),
+		attribute.Float64("search.radius_km", searchRadius),
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("candidates.count", len(candidates)))
+		tracing.End(span, err)
+	}()
+	
 	// Query available technicians within radius
 	query := `
 		SELECT 
 			et.id,
+			et.vendor_id,
 			et.name,
 			et.current_location,
 			et.rating,
@@ -524,7 +617,13 @@ func (e *DispatchEngine) findCandidates(ctx context.Context, request *EmergencyR
 			ST_Distance(
 				et.current_location::geography,
 				ST_MakePoint($2, $3)::geography
-			) / 1000 as distance_km
+			) / 1000 as distance_km,
+			ST_Y(et.home_base::geometry),
+			ST_X(et.home_base::geometry),
+			COALESCE(et.service_radius_km, 0),
+			COALESCE(et.skills, '{}'),
+			COALESCE(et.working_hours, '[]'),
+			COALESCE(et.on_call_schedule, '[]')
 		FROM emergency_technicians et
 		WHERE et.is_online = TRUE
 		  AND et.current_status = 'available'
@@ -535,6 +634,15 @@ func (e *DispatchEngine) findCandidates(ctx context.Context, request *EmergencyR
 			  ST_MakePoint($2, $3)::geography,
 			  $4 * 1000
 		  )
+		  AND (
+			  et.home_base IS NULL
+			  OR COALESCE(et.service_radius_km, 0) <= 0
+			  OR ST_DWithin(
+				  et.home_base::geography,
+				  ST_MakePoint($2, $3)::geography,
+				  et.service_radius_km * 1000
+			  )
+		  )
 		ORDER BY distance_km ASC, et.rating DESC
 		LIMIT 20
 	`
@@ -550,25 +658,38 @@ func (e *DispatchEngine) findCandidates(ctx context.Context, request *EmergencyR
 	}
 	defer rows.Close()
 	
-	var candidates []TechCandidate
 	for rows.Next() {
 		var c TechCandidate
 		var locationJSON []byte
 		var avgArrival int
+		var homeLat, homeLng *float64
+		var hoursJSON, onCallJSON []byte
 		
-		if err := rows.Scan(&c.TechID, &c.TechName, &locationJSON, &c.Rating, &avgArrival, &c.Distance); err != nil {
+		if err := rows.Scan(&c.TechID, &c.VendorID, &c.TechName, &locationJSON, &c.Rating, &avgArrival, &c.Distance,
+			&homeLat, &homeLng, &c.ServiceRadius, &c.Skills, &hoursJSON, &onCallJSON); err != nil {
 			continue
 		}
+		json.Unmarshal(hoursJSON, &c.WorkingHours)
+		json.Unmarshal(onCallJSON, &c.OnCallSchedule)
+		if homeLat != nil && homeLng != nil {
+			c.HomeBase = &GeoPoint{Latitude: *homeLat, Longitude: *homeLng}
+		}
 		
 		// Calculate ETA based on distance and historical data
 		c.EstimatedArrival = e.calculateETA(c.Distance, avgArrival)
 		
-		// Estimate price
-		c.Price = e.pricingEngine.EstimatePrice(request.Category, request.Urgency, c.Distance)
+		// Estimate price at the tech's vendor's rates
+		c.Price = e.pricingEngine.EstimatePrice(ctx, request.Category, request.Urgency, c.Distance, c.VendorID, request.Location)
 		
 		candidates = append(candidates, c)
 	}
 	
+	// Only offer techs whose declared coverage includes the request, who
+	// have the skills the job needs and who are working now
+	candidates = FilterCoveredCandidates(candidates, request.Location)
+	candidates = FilterSkilledCandidates(candidates, RequiredSkills(request))
+	candidates = FilterOnShiftCandidates(candidates, time.Now(), request.Urgency)
+	
 	// Sort by composite score (distance + rating + ETA)
 	sort.Slice(candidates, func(i, j int) bool {
 		scoreI := e.calculateCandidateScore(candidates[i], request.Urgency)
@@ -579,6 +700,255 @@ func (e *DispatchEngine) findCandidates(ctx context.Context, request *EmergencyR
 	return candidates, nil
 }
 
+// CoversLocation reports whether a tech's service area includes a point. A
+// tech with no home base or service radius is treated as uncapped, so the
+// dispatch search radius alone applies.
+func (c TechCandidate) CoversLocation(lat, lng float64) bool {
+	if c.HomeBase == nil || c.ServiceRadius <= 0 {
+		return true
+	}
+	return geo.Distance(*c.HomeBase, GeoPoint{Latitude: lat, Longitude: lng}) <= c.ServiceRadius
+}
+
+// FilterCoveredCandidates drops candidates who are inside the search radius
+// but whose own service area does not reach the request location
+func FilterCoveredCandidates(candidates []TechCandidate, loc EmergencyLocation) []TechCandidate {
+	covered := candidates[:0]
+	for _, c := range candidates {
+		if c.CoversLocation(loc.Latitude, loc.Longitude) {
+			covered = append(covered, c)
+		}
+	}
+	return covered
+}
+
+
+// SkillRequirement lists the technician skills a request calls for.
+// Techs lacking a required skill are not offered the job; preferred skills
+// only improve a tech's ranking.
+type SkillRequirement struct {
+	Required  []string `json:"required,omitempty"`
+	Preferred []string `json:"preferred,omitempty"`
+}
+
+// SubcategorySkills maps emergency subcategories to the skills they need
+var SubcategorySkills = map[EmergencyCategory]map[string]SkillRequirement{
+	CategoryPlumbing: {
+		"water_heater":  {Required: []string{"water_heater"}},
+		"gas_line":      {Required: []string{"gas_fitting"}},
+		"sewage":        {Required: []string{"sewer_line"}, Preferred: []string{"drain_cleaning"}},
+		"blocked_drain": {Preferred: []string{"drain_cleaning"}},
+	},
+	CategoryElectrical: {
+		"panel":     {Required: []string{"panel_upgrade"}},
+		"generator": {Required: []string{"generator"}},
+		"solar":     {Required: []string{"solar_inverter"}},
+	},
+	CategoryLocksmith: {
+		"smart_lock": {Required: []string{"smart_locks"}},
+		"safe":       {Required: []string{"safe_opening"}},
+	},
+	CategoryHVAC: {
+		"central_ac": {Required: []string{"central_ac"}},
+		"split_ac":   {Preferred: []string{"split_ac"}},
+	},
+}
+
+// IntakeSkills maps intake answers (question id, then answer) to skills
+var IntakeSkills = map[string]map[string]SkillRequirement{
+	"source": {
+		"water_heater": {Required: []string{"water_heater"}},
+		"drain":        {Preferred: []string{"drain_cleaning"}},
+	},
+	"near_electrics": {
+		"yes": {Preferred: []string{"electrical_safety"}},
+	},
+}
+
+// RequiredSkills derives the skills a request needs from its subcategory
+// and intake answers. A skill required by either source is required.
+func RequiredSkills(request *EmergencyRequest) SkillRequirement {
+	var reqs []SkillRequirement
+	if req, ok := SubcategorySkills[request.Category][request.Subcategory]; ok {
+		reqs = append(reqs, req)
+	}
+	for question, answer := range request.IntakeAnswers {
+		if req, ok := IntakeSkills[question][answer]; ok {
+			reqs = append(reqs, req)
+		}
+	}
+
+	required := make(map[string]bool)
+	preferred := make(map[string]bool)
+	for _, req := range reqs {
+		for _, skill := range req.Required {
+			required[skill] = true
+		}
+		for _, skill := range req.Preferred {
+			preferred[skill] = true
+		}
+	}
+
+	var merged SkillRequirement
+	for skill := range required {
+		merged.Required = append(merged.Required, skill)
+	}
+	for skill := range preferred {
+		if !required[skill] {
+			merged.Preferred = append(merged.Preferred, skill)
+		}
+	}
+	sort.Strings(merged.Required)
+	sort.Strings(merged.Preferred)
+	return merged
+}
+
+// SkillMatchScore returns the share of a requirement's skills a tech has,
+// and whether they have every required one. A request with no skill
+// requirements matches everyone fully.
+func SkillMatchScore(skills []string, req SkillRequirement) (float64, bool) {
+	has := make(map[string]bool, len(skills))
+	for _, skill := range skills {
+		has[skill] = true
+	}
+
+	qualified := true
+	for _, skill := range req.Required {
+		if !has[skill] {
+			qualified = false
+		}
+	}
+
+	total := len(req.Required) + len(req.Preferred)
+	if total == 0 {
+		return 1, qualified
+	}
+	matched := 0
+	for _, skill := range append(append([]string{}, req.Required...), req.Preferred...) {
+		if has[skill] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(total), qualified
+}
+
+// FilterSkilledCandidates drops candidates missing a required skill and
+// records each remaining candidate's skill match for ranking
+func FilterSkilledCandidates(candidates []TechCandidate, req SkillRequirement) []TechCandidate {
+	skilled := candidates[:0]
+	for _, c := range candidates {
+		score, qualified := SkillMatchScore(c.Skills, req)
+		if !qualified {
+			continue
+		}
+		c.SkillMatch = score
+		skilled = append(skilled, c)
+	}
+	return skilled
+}
+
+// ShiftTimezone is the local time technicians' working hours are kept in
+var ShiftTimezone = time.FixedZone("WAT", 60*60)
+
+// IsEmergencyUrgency reports whether a request of this urgency can only go
+// to techs whose working hours accept emergency calls
+func IsEmergencyUrgency(urgency UrgencyLevel) bool {
+	return urgency == UrgencyCritical || urgency == UrgencyUrgent
+}
+
+// Covers reports whether t, in the tech's local time, falls within these
+// hours. Hours that end at or before they start run past midnight.
+func (w WorkingHours) Covers(t time.Time) bool {
+	start, ok := shiftClock(w.StartTime)
+	if !ok {
+		return false
+	}
+	end, ok := shiftClock(w.EndTime)
+	if !ok {
+		return false
+	}
+
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	day := int(t.Weekday())
+	if end > start {
+		return day == w.DayOfWeek && clock >= start && clock < end
+	}
+	// Overnight: the evening of DayOfWeek or the early hours after it
+	if day == w.DayOfWeek && clock >= start {
+		return true
+	}
+	return day == (w.DayOfWeek+1)%7 && clock < end
+}
+
+// shiftClock parses "15:04" into an offset from midnight; "24:00" is the end
+// of the day
+func shiftClock(value string) (time.Duration, bool) {
+	if value == "24:00" {
+		return 24 * time.Hour, true
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
+
+// Active reports whether the on-call period includes t
+func (p OnCallPeriod) Active(t time.Time) bool {
+	return !t.Before(p.StartTime) && t.Before(p.EndTime)
+}
+
+// Shift is whether a tech can take a job at a given time
+type Shift struct {
+	Available bool
+	OnCall    bool    // available only through an on-call period
+	Premium   float64 // on-call premium, percent
+}
+
+// ShiftAt reports whether the tech is working at t. Emergencies only count
+// working hours that accept emergency calls. Outside working hours an active
+// on-call period makes the tech available at its premium, the highest if
+// several overlap. A tech with no schedule at all is always available.
+func (c TechCandidate) ShiftAt(t time.Time, emergency bool) Shift {
+	if len(c.WorkingHours) == 0 && len(c.OnCallSchedule) == 0 {
+		return Shift{Available: true}
+	}
+
+	local := t.In(ShiftTimezone)
+	for _, w := range c.WorkingHours {
+		if (w.IsEmergency || !emergency) && w.Covers(local) {
+			return Shift{Available: true}
+		}
+	}
+
+	var shift Shift
+	for _, p := range c.OnCallSchedule {
+		if p.Active(t) {
+			shift = Shift{Available: true, OnCall: true, Premium: math.Max(shift.Premium, p.Premium)}
+		}
+	}
+	return shift
+}
+
+// FilterOnShiftCandidates drops candidates who aren't working at t and adds
+// the on-call premium to the estimate of those who are only on call
+func FilterOnShiftCandidates(candidates []TechCandidate, t time.Time, urgency UrgencyLevel) []TechCandidate {
+	onShift := candidates[:0]
+	for _, c := range candidates {
+		shift := c.ShiftAt(t, IsEmergencyUrgency(urgency))
+		if !shift.Available {
+			continue
+		}
+		if shift.OnCall {
+			c.OnCall = true
+			c.OnCallPremium = shift.Premium
+			c.Price *= 1 + shift.Premium/100
+		}
+		onShift = append(onShift, c)
+	}
+	return onShift
+}
+
 func (e *DispatchEngine) calculateETA(distance float64, avgArrival int) int {
 	// Base: 2 minutes per km in traffic
 	distanceMinutes := int(distance * 2)
@@ -615,9 +985,14 @@ func (e *DispatchEngine) calculateCandidateScore(c TechCandidate, urgency Urgenc
 	etaScore := 1.0 / (1.0 + float64(c.EstimatedArrival)/30.0)
 	ratingScore := c.Rating / 5.0
 	
-	return distanceScore*distanceWeight + etaScore*etaWeight + ratingScore*ratingWeight
+	// Preferred skills break ties between otherwise similar techs
+	return distanceScore*distanceWeight + etaScore*etaWeight + ratingScore*ratingWeight + c.SkillMatch*skillMatchWeight
 }
 
+// skillMatchWeight is added on top of the urgency weights so a tech with
+// the preferred skills ranks above an otherwise equal one
+const skillMatchWeight = 0.2
+
 func (e *DispatchEngine) attemptAssignment(ctx context.Context, request *EmergencyRequest, candidate TechCandidate) (bool, error) {
 	// Record assignment attempt
 	e.mu.Lock()
@@ -625,6 +1000,7 @@ func (e *DispatchEngine) attemptAssignment(ctx context.Context, request *Emergen
 	state.AssignmentAttempts++
 	state.LastAttemptAt = time.Now()
 	e.mu.Unlock()
+	e.saveState(ctx, request.ID)
 	
 	// Update request
 	request.AssignedTechID = &candidate.TechID
@@ -651,7 +1027,7 @@ func (e *DispatchEngine) attemptAssignment(ctx context.Context, request *Emergen
 	e.notificationSvc.NotifyTechnician(ctx, candidate.TechID, notification)
 	
 	// Wait for response with timeout
-	accepted := e.waitForTechResponse(ctx, request.ID, candidate.TechID, e.config.AssignmentTimeout)
+	accepted := e.WaitForTechResponse(ctx, request.ID, candidate.TechID, e.config.AssignmentTimeout)
 	
 	if accepted {
 		// Update assignment as accepted
@@ -668,67 +1044,236 @@ func (e *DispatchEngine) attemptAssignment(ctx context.Context, request *Emergen
 		return true, nil
 	}
 	
-	// Tech didn't accept, mark and try next
+	// Tech didn't accept, mark and try next. A decline has already been
+	// recorded and doesn't count as a missed offer.
+	timedOut := false
+	e.mu.Lock()
 	for i := range request.AssignmentHistory {
-		if request.AssignmentHistory[i].TechID == candidate.TechID {
+		if request.AssignmentHistory[i].TechID == candidate.TechID && request.AssignmentHistory[i].Response == "pending" {
 			now := time.Now()
 			request.AssignmentHistory[i].Response = "timeout"
 			request.AssignmentHistory[i].ResponseAt = &now
+			timedOut = true
 		}
 	}
+	e.mu.Unlock()
+	if timedOut {
+		e.recordMissedOffer(ctx, candidate.TechID)
+	}
 	
 	return false, nil
 }
 
-func (e *DispatchEngine) waitForTechResponse(ctx context.Context, requestID, techID uuid.UUID, timeout time.Duration) bool {
-	// In production, this would use a pub/sub mechanism
-	// For now, poll the database
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+// recordMissedOffer counts an unanswered offer against the technician's
+// acceptance rate
+func (e *DispatchEngine) recordMissedOffer(ctx context.Context, techID uuid.UUID) {
+	e.db.Exec(ctx, `
+		UPDATE emergency_technicians SET
+			missed_offers = missed_offers + 1,
+			acceptance_rate = completed_jobs::decimal / (completed_jobs + missed_offers + 1),
+			updated_at = NOW()
+		WHERE id = $1
+	`, techID)
+}
+
+// Responses a technician can give to an offer
+const (
+	TechResponseAccepted = "accepted"
+	TechResponseDeclined = "declined"
+)
+
+// TechResponseChannel is the Redis channel a technician's answer to an offer
+// is published on
+func TechResponseChannel(requestID, techID uuid.UUID) string {
+	return fmt.Sprintf("dispatch:response:%s:%s", requestID, techID)
+}
+
+// PublishTechResponse tells the dispatcher waiting on an offer how the
+// technician answered
+func (e *DispatchEngine) PublishTechResponse(ctx context.Context, requestID, techID uuid.UUID, response string) error {
+	if e.cache == nil {
+		return nil
+	}
+	return e.cache.Publish(ctx, TechResponseChannel(requestID, techID), response).Err()
+}
+
+// WaitForTechResponse waits up to timeout for the technician to answer an
+// offer and reports whether they accepted. If the subscription can't be set
+// up it checks the stored response once instead.
+func (e *DispatchEngine) WaitForTechResponse(ctx context.Context, requestID, techID uuid.UUID, timeout time.Duration) bool {
+	if e.cache == nil {
+		return e.storedTechResponse(ctx, requestID, techID) == TechResponseAccepted
+	}
+	
+	sub := e.cache.Subscribe(ctx, TechResponseChannel(requestID, techID))
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return e.storedTechResponse(ctx, requestID, techID) == TechResponseAccepted
+	}
+	
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	messages := sub.Channel()
 	
 	for {
 		select {
 		case <-ctx.Done():
 			return false
-		case <-ticker.C:
-			if time.Now().After(deadline) {
+		case <-timer.C:
+			return false
+		case msg, ok := <-messages:
+			if !ok {
 				return false
 			}
-			
-			// Check if tech accepted
-			var response string
-			e.db.QueryRow(ctx, `
-				SELECT ah.response 
-				FROM emergency_requests er,
-				     jsonb_array_elements(er.assignment_history) ah
-				WHERE er.id = $1 
-				  AND (ah->>'tech_id')::uuid = $2
-				ORDER BY (ah->>'assigned_at')::timestamp DESC
-				LIMIT 1
-			`, requestID, techID).Scan(&response)
-			
-			if response == "accepted" {
+			switch msg.Payload {
+			case TechResponseAccepted:
 				return true
-			} else if response == "declined" {
+			case TechResponseDeclined:
 				return false
 			}
 		}
 	}
 }
 
+// storedTechResponse reads the technician's latest answer from the
+// request's assignment history
+func (e *DispatchEngine) storedTechResponse(ctx context.Context, requestID, techID uuid.UUID) string {
+	if e.db == nil {
+		return ""
+	}
+	var response string
+	e.db.QueryRow(ctx, `
+		SELECT ah->>'response'
+		FROM emergency_requests er,
+		     jsonb_array_elements(er.assignment_history) ah
+		WHERE er.id = $1 
+		  AND (ah->>'tech_id')::uuid = $2
+		ORDER BY (ah->>'assigned_at')::timestamp DESC
+		LIMIT 1
+	`, requestID, techID).Scan(&response)
+	return response
+}
+
+var (
+	ErrRequestNotFound = errors.New("emergency request not found")
+	ErrNotAssignedTech = errors.New("technician does not hold the offer for this request")
+)
+
+// DeclineAssignment records a technician turning down the offer they hold
+// and wakes the dispatcher waiting on it, so dispatch moves on to the next
+// candidate instead of waiting out the assignment timeout
+func (e *DispatchEngine) DeclineAssignment(ctx context.Context, requestID, techID uuid.UUID, reason string) error {
+	e.mu.RLock()
+	state := e.activeRequests[requestID]
+	e.mu.RUnlock()
+	if state == nil {
+		return e.declineLiveOffer(ctx, requestID, techID, reason)
+	}
+	request := state.Request
+	
+	e.mu.Lock()
+	err := ApplyDecline(request, techID, reason, time.Now())
+	e.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	
+	notes := "Technician declined the request"
+	if reason != "" {
+		notes += ": " + reason
+	}
+	e.updateRequestStatus(ctx, request, "technician", notes)
+	return e.PublishTechResponse(ctx, requestID, techID, TechResponseDeclined)
+}
+
+// liveOfferKey is the set of technicians the live emergency flow in
+// internal/homerescue has offered an emergency to
+func liveOfferKey(requestID uuid.UUID) string {
+	return fmt.Sprintf("emergency:notified:%s", requestID)
+}
+
+// declineLiveOffer declines an emergency this engine isn't dispatching,
+// which the live flow offers to every technician it notifies. Removing the
+// technician from the offered set is what claims the decline, so the same
+// offer can't be declined twice.
+func (e *DispatchEngine) declineLiveOffer(ctx context.Context, requestID, techID uuid.UUID, reason string) error {
+	request, err := e.dispatchedRequest(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	
+	now := time.Now()
+	if err := ApplyDecline(request, techID, reason, now); err != nil {
+		offered := request.Status == StatusNew || request.Status == StatusSearching
+		if !offered || e.cache == nil {
+			return err
+		}
+		removed, err := e.cache.SRem(ctx, liveOfferKey(requestID), techID.String()).Result()
+		if err != nil {
+			return fmt.Errorf("failed to withdraw offer: %w", err)
+		}
+		if removed == 0 {
+			return ErrNotAssignedTech
+		}
+		request.AssignmentHistory = append(request.AssignmentHistory, Assignment{
+			TechID:     techID,
+			AssignedAt: now,
+			Response:   TechResponseDeclined,
+			ResponseAt: &now,
+			Reason:     reason,
+		})
+	}
+	
+	assignmentJSON, _ := json.Marshal(request.AssignmentHistory)
+	_, err = e.db.Exec(ctx, `
+		UPDATE emergencies
+		SET status = $2, assigned_tech_id = $3, assignment_history = $4, updated_at = $5
+		WHERE id = $1
+	`, request.ID, request.Status, request.AssignedTechID, assignmentJSON, now)
+	if err != nil {
+		return fmt.Errorf("failed to record decline: %w", err)
+	}
+	return e.PublishTechResponse(ctx, requestID, techID, TechResponseDeclined)
+}
+
+// ApplyDecline marks the technician's pending offer declined and puts the
+// request back to searching. Only the technician the request is assigned to
+// can decline it, and only before they accept.
+func ApplyDecline(request *EmergencyRequest, techID uuid.UUID, reason string, now time.Time) error {
+	if request.Status != StatusAssigned || request.AssignedTechID == nil || *request.AssignedTechID != techID {
+		return ErrNotAssignedTech
+	}
+	
+	for i := len(request.AssignmentHistory) - 1; i >= 0; i-- {
+		a := &request.AssignmentHistory[i]
+		if a.TechID != techID || a.Response != "pending" {
+			continue
+		}
+		a.Response = TechResponseDeclined
+		a.ResponseAt = &now
+		if reason != "" {
+			a.Reason = reason
+		}
+		break
+	}
+	request.AssignedTechID = nil
+	request.Status = StatusSearching
+	return nil
+}
+
 func (e *DispatchEngine) expandedSearch(ctx context.Context, request *EmergencyRequest) {
 	e.mu.Lock()
 	state := e.activeRequests[request.ID]
 	state.CurrentSearchRadius += e.config.SearchExpansionStep
 	e.mu.Unlock()
+	e.saveState(ctx, request.ID)
 	
 	if state.CurrentSearchRadius <= e.config.MaxSearchRadius {
 		// Retry dispatch with expanded radius
 		e.Dispatch(ctx, request)
 	} else {
 		// Max radius reached, escalate
-		e.escalateRequest(ctx, request)
+		e.Escalate(ctx, request)
 	}
 }
 
@@ -746,7 +1291,7 @@ func (e *DispatchEngine) backgroundDispatch(ctx context.Context, request *Emerge
 		
 		// Check if still needs assignment
 		if state.AssignmentAttempts >= e.config.MaxAssignmentAttempts {
-			e.escalateRequest(ctx, request)
+			e.Escalate(ctx, request)
 			return
 		}
 		
@@ -755,7 +1300,40 @@ func (e *DispatchEngine) backgroundDispatch(ctx context.Context, request *Emerge
 	}
 }
 
+// Escalate hands a request nobody accepted to the support team and tells the
+// customer. Only the instance holding the request's escalation lock does so;
+// it reports whether this call escalated. The lock is left to expire rather
+// than released, so the request is not escalated again, by this instance or
+// another, until support sends it back to dispatch or EscalationLockTTL
+// passes.
+func (e *DispatchEngine) Escalate(ctx context.Context, request *EmergencyRequest) bool {
+	if e.locker != nil {
+		acquired, err := e.locker.TryLock(ctx, escalationLockKey(request.ID), e.config.EscalationLockTTL)
+		// If the lock store is down, a duplicate alert beats a lost emergency
+		if err == nil && !acquired {
+			return false
+		}
+	}
+	
+	e.escalateRequest(ctx, request)
+	return true
+}
+
+func escalationLockKey(requestID uuid.UUID) string {
+	return fmt.Sprintf("homerescue:escalation:%s", requestID)
+}
+
 func (e *DispatchEngine) escalateRequest(ctx context.Context, request *EmergencyRequest) {
+	// Queue it for a support agent to claim and assign by hand
+	if e.supportQueue != nil {
+		e.mu.Lock()
+		if e.activeRequests[request.ID] == nil {
+			e.activeRequests[request.ID] = &RequestState{Request: request}
+		}
+		e.mu.Unlock()
+		e.supportQueue.Enqueue(ctx, NewSupportTicket(request, "Unable to find available technician after maximum attempts", time.Now()))
+	}
+	
 	// Notify support team
 	e.notificationSvc.NotifySupport(ctx, &SupportAlert{
 		Type:      "dispatch_failure",
@@ -783,6 +1361,9 @@ func (e *DispatchEngine) updateRequestStatus(ctx context.Context, request *Emerg
 	request.UpdatedAt = time.Now()
 	
 	// Persist to database
+	if e.db == nil {
+		return
+	}
 	historyJSON, _ := json.Marshal(request.StatusHistory)
 	assignmentJSON, _ := json.Marshal(request.AssignmentHistory)
 	
@@ -798,77 +1379,1058 @@ func (e *DispatchEngine) updateRequestStatus(ctx context.Context, request *Emerg
 }
 
 // =============================================================================
-// SECTION 4: REAL-TIME TRACKING
+// 3.1 SUPPORT ESCALATION QUEUE
 // =============================================================================
 
-// TrackingService provides real-time location tracking
-type TrackingService struct {
-	db       *pgxpool.Pool
-	cache    *redis.Client
-	pubsub   *PubSubService
-}
+var (
+	ErrTicketNotFound   = errors.New("request is not in the support queue")
+	ErrTicketClaimed    = errors.New("request is claimed by another agent")
+	ErrTicketNotClaimed = errors.New("claim the request before assigning it")
+	ErrTicketAssigned   = errors.New("request has already been assigned")
+)
 
-// TechLocationUpdate from mobile app
-type TechLocationUpdate struct {
-	TechID    uuid.UUID `json:"tech_id"`
-	Latitude  float64   `json:"latitude"`
-	Longitude float64   `json:"longitude"`
-	Accuracy  float64   `json:"accuracy_meters"`
-	Speed     float64   `json:"speed_kmh"`
-	Heading   float64   `json:"heading_degrees"`
-	Timestamp time.Time `json:"timestamp"`
+// urgencyRank orders urgency levels, most urgent first
+var urgencyRank = map[UrgencyLevel]int{
+	UrgencyCritical:  0,
+	UrgencyUrgent:    1,
+	UrgencySameDay:   2,
+	UrgencyScheduled: 3,
 }
 
-// TrackingUpdate sent to customer
-type TrackingUpdate struct {
-	RequestID        uuid.UUID `json:"request_id"`
-	TechID           uuid.UUID `json:"tech_id"`
-	TechName         string    `json:"tech_name"`
-	TechPhoto        string    `json:"tech_photo"`
-	CurrentLocation  GeoPoint  `json:"current_location"`
-	DistanceRemaining float64  `json:"distance_remaining_km"`
-	ETAMinutes       int       `json:"eta_minutes"`
-	Status           string    `json:"status"`
-	UpdatedAt        time.Time `json:"updated_at"`
+// SupportTicket is an escalated request waiting on the support team
+type SupportTicket struct {
+	RequestID        uuid.UUID         `json:"request_id"`
+	UserID           uuid.UUID         `json:"user_id"`
+	Category         EmergencyCategory `json:"category"`
+	Urgency          UrgencyLevel      `json:"urgency"`
+	Title            string            `json:"title"`
+	Reason           string            `json:"reason"`
+	CreatedAt        time.Time         `json:"created_at"` // when the customer raised it
+	EscalatedAt      time.Time         `json:"escalated_at"`
+	ResponseDeadline time.Time         `json:"response_deadline"`
+	
+	ClaimedBy      *uuid.UUID `json:"claimed_by,omitempty"`
+	ClaimedAt      *time.Time `json:"claimed_at,omitempty"`
+	AssignedTechID *uuid.UUID `json:"assigned_tech_id,omitempty"`
+	AssignedAt     *time.Time `json:"assigned_at,omitempty"`
+	
+	// Set by OrderSupportQueue
+	WaitingMinutes int  `json:"waiting_minutes"`
+	SLABreached    bool `json:"sla_breached"`
 }
 
-// UpdateTechLocation processes a location update from a technician
-func (s *TrackingService) UpdateTechLocation(ctx context.Context, update TechLocationUpdate) error {
-	// Update tech's current location in database
-	_, err := s.db.Exec(ctx, `
-		UPDATE emergency_technicians
-		SET current_location = ST_MakePoint($2, $3),
-		    last_location_update = $4
-		WHERE id = $1
-	`, update.TechID, update.Longitude, update.Latitude, update.Timestamp)
-	
-	if err != nil {
-		return err
+// NewSupportTicket opens a ticket for an escalated request. Requests without
+// a response deadline get one from their urgency's SLA.
+func NewSupportTicket(request *EmergencyRequest, reason string, now time.Time) SupportTicket {
+	created := request.CreatedAt
+	if created.IsZero() {
+		created = now
+	}
+	deadline := request.ResponseDeadline
+	if deadline.IsZero() {
+		deadline = created.Add(time.Duration(ResponseTimeSLA[request.Urgency]) * time.Minute)
 	}
 	
-	// Cache for real-time access
-	locationJSON, _ := json.Marshal(update)
-	s.cache.Set(ctx, fmt.Sprintf("tech:location:%s", update.TechID), locationJSON, 5*time.Minute)
-	
-	// Check if tech has an active request
-	var requestID uuid.UUID
-	var customerUserID uuid.UUID
-	var destLat, destLng float64
-	
-	err = s.db.QueryRow(ctx, `
-		SELECT er.id, er.user_id, er.location->>'latitude', er.location->>'longitude'
-		FROM emergency_requests er
-		JOIN emergency_technicians et ON et.id = er.assigned_tech_id
-		WHERE et.id = $1 AND er.status IN ('accepted', 'en_route')
-	`, update.TechID).Scan(&requestID, &customerUserID, &destLat, &destLng)
-	
-	if err != nil {
-		// No active request
-		return nil
+	return SupportTicket{
+		RequestID:        request.ID,
+		UserID:           request.UserID,
+		Category:         request.Category,
+		Urgency:          request.Urgency,
+		Title:            request.Title,
+		Reason:           reason,
+		CreatedAt:        created,
+		EscalatedAt:      now,
+		ResponseDeadline: deadline,
+	}
+}
+
+// OrderSupportQueue flags tickets past their response SLA and orders them
+// most urgent first, then longest waiting
+func OrderSupportQueue(tickets []SupportTicket, now time.Time) []SupportTicket {
+	for i := range tickets {
+		tickets[i].WaitingMinutes = int(now.Sub(tickets[i].CreatedAt).Minutes())
+		tickets[i].SLABreached = now.After(tickets[i].ResponseDeadline)
 	}
 	
-	// Calculate distance remaining
-	distance := s.calculateDistance(update.Latitude, update.Longitude, destLat, destLng)
+	sort.SliceStable(tickets, func(i, j int) bool {
+		ri, ok := urgencyRank[tickets[i].Urgency]
+		if !ok {
+			ri = len(urgencyRank)
+		}
+		rj, ok := urgencyRank[tickets[j].Urgency]
+		if !ok {
+			rj = len(urgencyRank)
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return tickets[i].CreatedAt.Before(tickets[j].CreatedAt)
+	})
+	return tickets
+}
+
+// SupportQueue holds escalated requests until an agent assigns a technician
+type SupportQueue interface {
+	// Enqueue opens a ticket, leaving one already open for the request as is
+	Enqueue(ctx context.Context, ticket SupportTicket) error
+	// Open lists tickets not yet assigned
+	Open(ctx context.Context) ([]SupportTicket, error)
+	// Claim gives an agent the ticket; claiming it again is a no-op
+	Claim(ctx context.Context, requestID, agentID uuid.UUID, at time.Time) (*SupportTicket, error)
+	// Assign closes a ticket the agent holds with the technician they chose
+	Assign(ctx context.Context, requestID, agentID, techID uuid.UUID, at time.Time) (*SupportTicket, error)
+}
+
+// checkClaim reports why an agent may not claim (or, with mustHold, assign)
+// the ticket, or nil if they may
+func checkClaim(t *SupportTicket, agentID uuid.UUID, mustHold bool) error {
+	switch {
+	case t.AssignedTechID != nil:
+		return ErrTicketAssigned
+	case t.ClaimedBy != nil && *t.ClaimedBy != agentID:
+		return ErrTicketClaimed
+	case t.ClaimedBy == nil && mustHold:
+		return ErrTicketNotClaimed
+	}
+	return nil
+}
+
+// MemorySupportQueue keeps the support queue in process
+type MemorySupportQueue struct {
+	mu      sync.Mutex
+	tickets map[uuid.UUID]*SupportTicket
+}
+
+// NewMemorySupportQueue creates an empty in-process queue
+func NewMemorySupportQueue() *MemorySupportQueue {
+	return &MemorySupportQueue{tickets: make(map[uuid.UUID]*SupportTicket)}
+}
+
+// Enqueue opens a ticket unless one is already open for the request
+func (q *MemorySupportQueue) Enqueue(ctx context.Context, ticket SupportTicket) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	
+	if existing, ok := q.tickets[ticket.RequestID]; ok && existing.AssignedTechID == nil {
+		return nil
+	}
+	q.tickets[ticket.RequestID] = &ticket
+	return nil
+}
+
+// Open lists unassigned tickets
+func (q *MemorySupportQueue) Open(ctx context.Context) ([]SupportTicket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	
+	var open []SupportTicket
+	for _, t := range q.tickets {
+		if t.AssignedTechID == nil {
+			open = append(open, *t)
+		}
+	}
+	return open, nil
+}
+
+// Claim gives the agent the ticket
+func (q *MemorySupportQueue) Claim(ctx context.Context, requestID, agentID uuid.UUID, at time.Time) (*SupportTicket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	
+	t, ok := q.tickets[requestID]
+	if !ok {
+		return nil, ErrTicketNotFound
+	}
+	if err := checkClaim(t, agentID, false); err != nil {
+		return nil, err
+	}
+	if t.ClaimedBy == nil {
+		t.ClaimedBy = &agentID
+		t.ClaimedAt = &at
+	}
+	claimed := *t
+	return &claimed, nil
+}
+
+// Assign closes the ticket with the agent's chosen technician
+func (q *MemorySupportQueue) Assign(ctx context.Context, requestID, agentID, techID uuid.UUID, at time.Time) (*SupportTicket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	
+	t, ok := q.tickets[requestID]
+	if !ok {
+		return nil, ErrTicketNotFound
+	}
+	if err := checkClaim(t, agentID, true); err != nil {
+		return nil, err
+	}
+	t.AssignedTechID = &techID
+	t.AssignedAt = &at
+	assigned := *t
+	return &assigned, nil
+}
+
+// PostgresSupportQueue keeps the support queue in emergency_support_queue
+type PostgresSupportQueue struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresSupportQueue creates a database-backed queue
+func NewPostgresSupportQueue(db *pgxpool.Pool) *PostgresSupportQueue {
+	return &PostgresSupportQueue{db: db}
+}
+
+const supportTicketColumns = `
+	request_id, user_id, category, urgency, title, reason,
+	created_at, escalated_at, response_deadline,
+	claimed_by, claimed_at, assigned_tech_id, assigned_at`
+
+func scanSupportTicket(row pgx.Row) (*SupportTicket, error) {
+	var t SupportTicket
+	err := row.Scan(
+		&t.RequestID, &t.UserID, &t.Category, &t.Urgency, &t.Title, &t.Reason,
+		&t.CreatedAt, &t.EscalatedAt, &t.ResponseDeadline,
+		&t.ClaimedBy, &t.ClaimedAt, &t.AssignedTechID, &t.AssignedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTicketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Enqueue opens a ticket; a request escalated again after being assigned
+// goes back in the queue unclaimed
+func (q *PostgresSupportQueue) Enqueue(ctx context.Context, t SupportTicket) error {
+	_, err := q.db.Exec(ctx, `
+		INSERT INTO emergency_support_queue (
+			request_id, user_id, category, urgency, title, reason,
+			created_at, escalated_at, response_deadline
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (request_id) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			escalated_at = EXCLUDED.escalated_at,
+			claimed_by = NULL,
+			claimed_at = NULL,
+			assigned_tech_id = NULL,
+			assigned_at = NULL
+		WHERE emergency_support_queue.assigned_tech_id IS NOT NULL
+	`, t.RequestID, t.UserID, t.Category, t.Urgency, t.Title, t.Reason,
+		t.CreatedAt, t.EscalatedAt, t.ResponseDeadline)
+	return err
+}
+
+// Open lists unassigned tickets
+func (q *PostgresSupportQueue) Open(ctx context.Context) ([]SupportTicket, error) {
+	rows, err := q.db.Query(ctx, `SELECT `+supportTicketColumns+`
+		FROM emergency_support_queue
+		WHERE assigned_tech_id IS NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var open []SupportTicket
+	for rows.Next() {
+		t, err := scanSupportTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		open = append(open, *t)
+	}
+	return open, rows.Err()
+}
+
+// Claim gives the agent the ticket
+func (q *PostgresSupportQueue) Claim(ctx context.Context, requestID, agentID uuid.UUID, at time.Time) (*SupportTicket, error) {
+	t, err := scanSupportTicket(q.db.QueryRow(ctx, `
+		UPDATE emergency_support_queue
+		SET claimed_by = $2, claimed_at = COALESCE(claimed_at, $3)
+		WHERE request_id = $1
+		  AND assigned_tech_id IS NULL
+		  AND (claimed_by IS NULL OR claimed_by = $2)
+		RETURNING `+supportTicketColumns, requestID, agentID, at))
+	if errors.Is(err, ErrTicketNotFound) {
+		return nil, q.refusal(ctx, requestID, agentID, false)
+	}
+	return t, err
+}
+
+// Assign closes the ticket with the agent's chosen technician
+func (q *PostgresSupportQueue) Assign(ctx context.Context, requestID, agentID, techID uuid.UUID, at time.Time) (*SupportTicket, error) {
+	t, err := scanSupportTicket(q.db.QueryRow(ctx, `
+		UPDATE emergency_support_queue
+		SET assigned_tech_id = $3, assigned_at = $4
+		WHERE request_id = $1
+		  AND assigned_tech_id IS NULL
+		  AND claimed_by = $2
+		RETURNING `+supportTicketColumns, requestID, agentID, techID, at))
+	if errors.Is(err, ErrTicketNotFound) {
+		return nil, q.refusal(ctx, requestID, agentID, true)
+	}
+	return t, err
+}
+
+// refusal explains why a conditional claim or assignment matched nothing
+func (q *PostgresSupportQueue) refusal(ctx context.Context, requestID, agentID uuid.UUID, mustHold bool) error {
+	t, err := scanSupportTicket(q.db.QueryRow(ctx, `SELECT `+supportTicketColumns+`
+		FROM emergency_support_queue WHERE request_id = $1
+	`, requestID))
+	if err != nil {
+		return err
+	}
+	if err := checkClaim(t, agentID, mustHold); err != nil {
+		return err
+	}
+	// Changed between the update and this read; let the agent retry
+	return ErrTicketClaimed
+}
+
+// SupportQueue lists escalated requests for the support team, most urgent
+// and longest waiting first
+func (e *DispatchEngine) SupportQueue(ctx context.Context) ([]SupportTicket, error) {
+	if e.supportQueue == nil {
+		return []SupportTicket{}, nil
+	}
+	tickets, err := e.supportQueue.Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load support queue: %w", err)
+	}
+	if tickets == nil {
+		tickets = []SupportTicket{}
+	}
+	return OrderSupportQueue(tickets, time.Now()), nil
+}
+
+// ClaimEscalation gives an escalated request to a support agent so two
+// agents don't work the same customer
+func (e *DispatchEngine) ClaimEscalation(ctx context.Context, requestID, agentID uuid.UUID) (*SupportTicket, error) {
+	if e.supportQueue == nil {
+		return nil, ErrTicketNotFound
+	}
+	return e.supportQueue.Claim(ctx, requestID, agentID, time.Now())
+}
+
+// AssignFromSupport dispatches an escalated request to the technician the
+// agent holding it has lined up
+func (e *DispatchEngine) AssignFromSupport(ctx context.Context, requestID, agentID, techID uuid.UUID) (*DispatchResult, error) {
+	if e.supportQueue == nil {
+		return nil, ErrTicketNotFound
+	}
+	request, err := e.dispatchedRequest(ctx, requestID)
+	if errors.Is(err, ErrRequestNotFound) {
+		return nil, ErrTicketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	now := time.Now()
+	if _, err := e.supportQueue.Assign(ctx, requestID, agentID, techID, now); err != nil {
+		return nil, err
+	}
+	
+	notification := ApplySupportAssignment(request, techID, now, e.config.AssignmentTimeout)
+	e.updateRequestStatus(ctx, request, "support:"+agentID.String(), "Technician assigned by support")
+	// Back in dispatch; if this technician falls through too, escalate afresh
+	if e.locker != nil {
+		e.locker.Unlock(ctx, escalationLockKey(requestID))
+	}
+	e.notificationSvc.NotifyTechnician(ctx, techID, notification)
+	e.notificationSvc.NotifyCustomer(ctx, request.UserID, &CustomerNotification{
+		Type:    "technician_assigned",
+		Title:   "Technician assigned",
+		Message: "Our support team has found a technician for your request. You'll be able to track them once they're on the way.",
+	})
+	
+	return &DispatchResult{
+		Success:        true,
+		RequestID:      request.ID,
+		AssignedTechID: &techID,
+		Message:        "Technician assigned by support",
+	}, nil
+}
+
+// ApplySupportAssignment assigns the request to a technician picked by
+// support and returns the offer to send them
+func ApplySupportAssignment(request *EmergencyRequest, techID uuid.UUID, now time.Time, timeout time.Duration) *TechNotification {
+	request.AssignedTechID = &techID
+	request.Status = StatusAssigned
+	request.AssignmentHistory = append(request.AssignmentHistory, Assignment{
+		TechID:     techID,
+		AssignedAt: now,
+		Response:   "pending",
+		Reason:     "assigned by support",
+	})
+	
+	return &TechNotification{
+		Type:      "support_assignment",
+		RequestID: request.ID,
+		Category:  request.Category,
+		Urgency:   request.Urgency,
+		Address:   request.Location.Address,
+		ExpiresAt: now.Add(timeout),
+	}
+}
+
+// dispatchedRequest finds a request this instance is dispatching, or loads
+// it from the emergencies the live flow creates
+func (e *DispatchEngine) dispatchedRequest(ctx context.Context, requestID uuid.UUID) (*EmergencyRequest, error) {
+	e.mu.RLock()
+	state := e.activeRequests[requestID]
+	e.mu.RUnlock()
+	if state != nil {
+		return state.Request, nil
+	}
+	if e.db == nil {
+		return nil, ErrRequestNotFound
+	}
+	
+	var request EmergencyRequest
+	err := scanDispatchRequest(e.db.QueryRow(ctx, `
+		SELECT `+emergencyColumns+`
+		FROM emergencies e
+		WHERE e.id = $1
+	`, requestID), &request)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load request: %w", err)
+	}
+	return &request, nil
+}
+
+// dispatchRequestColumns are the emergency_requests columns, aliased er,
+// that scanDispatchRequest reads
+const dispatchRequestColumns = `er.id, er.user_id, er.category, COALESCE(er.subcategory, ''), er.urgency, er.title,
+		       er.location, er.status, er.status_history, er.assignment_history,
+		       er.assigned_tech_id, er.response_deadline, er.arrival_deadline, er.created_at`
+
+// emergencyColumns are the same columns read from the live flow's
+// emergencies table, aliased e, which keeps its location in columns
+const emergencyColumns = `e.id, e.user_id, e.category, COALESCE(e.subcategory, ''), e.urgency, e.title,
+		       jsonb_build_object('address', e.address, 'latitude', e.latitude, 'longitude', e.longitude),
+		       e.status, e.status_history, e.assignment_history,
+		       e.assigned_tech_id, e.response_deadline, e.arrival_deadline, e.created_at`
+
+// scanDispatchRequest scans dispatchRequestColumns or emergencyColumns into
+// request, followed by any extra columns
+func scanDispatchRequest(row pgx.Row, request *EmergencyRequest, extra ...interface{}) error {
+	var (
+		locationJSON, historyJSON, assignJSON []byte
+		title                                 *string
+		responseDeadline, arrivalDeadline     *time.Time
+	)
+	dest := []interface{}{
+		&request.ID, &request.UserID, &request.Category, &request.Subcategory, &request.Urgency, &title,
+		&locationJSON, &request.Status, &historyJSON, &assignJSON,
+		&request.AssignedTechID, &responseDeadline, &arrivalDeadline, &request.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if title != nil {
+		request.Title = *title
+	}
+	if responseDeadline != nil {
+		request.ResponseDeadline = *responseDeadline
+	}
+	if arrivalDeadline != nil {
+		request.ArrivalDeadline = *arrivalDeadline
+	}
+	json.Unmarshal(locationJSON, &request.Location)
+	json.Unmarshal(historyJSON, &request.StatusHistory)
+	json.Unmarshal(assignJSON, &request.AssignmentHistory)
+	return nil
+}
+
+// =============================================================================
+// 3.2 ARRIVAL SLA BREACHES
+// =============================================================================
+
+// ErrNoPaymentService is returned when a breached request holds a payment
+// but no payment service is configured to refund it
+var ErrNoPaymentService = errors.New("no payment service configured")
+
+// PaymentService moves money held against emergency requests
+type PaymentService interface {
+	// RefundHeld releases the customer's pre-authorized payment
+	RefundHeld(ctx context.Context, request *EmergencyRequest, reason string) error
+}
+
+// ArrivalSLAStore finds requests whose technician missed the arrival deadline
+type ArrivalSLAStore interface {
+	// FlagSLABreaches marks every accepted or en-route request that is past
+	// its ArrivalDeadline without an arrival as breached, appends update to
+	// its history with the request's current status, and returns the
+	// requests it flagged. A request is only ever returned by one call.
+	FlagSLABreaches(ctx context.Context, now time.Time, update StatusUpdate) ([]EmergencyRequest, error)
+	// SetPaymentStatus records the outcome of a breach refund
+	SetPaymentStatus(ctx context.Context, requestID uuid.UUID, status PaymentStatus) error
+}
+
+// SetArrivalSLAStore replaces where SLA breaches are looked up and flagged
+func (e *DispatchEngine) SetArrivalSLAStore(store ArrivalSLAStore) {
+	e.slaStore = store
+}
+
+// SetPaymentService sets the service used to refund breached requests
+func (e *DispatchEngine) SetPaymentService(payments PaymentService) {
+	e.paymentSvc = payments
+}
+
+func (e *DispatchEngine) arrivalSLA() ArrivalSLAStore {
+	if e.slaStore != nil {
+		return e.slaStore
+	}
+	return &PostgresArrivalSLAStore{db: e.db}
+}
+
+// ArrivalSLABreached reports whether the technician on an accepted or
+// en-route request has missed its arrival deadline at now. Requests already
+// flagged are not breached again.
+func ArrivalSLABreached(request *EmergencyRequest, now time.Time) bool {
+	if request.SLABreached || request.ActualArrivalTime != nil || request.ArrivalDeadline.IsZero() {
+		return false
+	}
+	if request.Status != StatusAccepted && request.Status != StatusEnRoute {
+		return false
+	}
+	return now.After(request.ArrivalDeadline)
+}
+
+// CheckSLABreaches flags requests whose technician missed the arrival
+// deadline, refunds any payment held against them and tells the customer.
+// It returns the IDs of the requests it flagged; refunds that fail are
+// reported in the error but don't stop the rest of the sweep.
+func (e *DispatchEngine) CheckSLABreaches(ctx context.Context) ([]uuid.UUID, error) {
+	now := time.Now()
+	store := e.arrivalSLA()
+	update := StatusUpdate{
+		Timestamp: now,
+		UpdatedBy: "system",
+		Notes:     "Arrival SLA breached: technician missed the arrival deadline",
+	}
+	
+	breached, err := store.FlagSLABreaches(ctx, now, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag SLA breaches: %w", err)
+	}
+	
+	ids := make([]uuid.UUID, 0, len(breached))
+	var refundErrs []error
+	for i := range breached {
+		request := &breached[i]
+		ids = append(ids, request.ID)
+	
+		refunded := false
+		if request.PaymentStatus == PaymentHeld {
+			if err := e.refundBreach(ctx, store, request); err != nil {
+				refundErrs = append(refundErrs, fmt.Errorf("failed to refund request %s: %w", request.ID, err))
+			} else {
+				refunded = true
+			}
+		}
+	
+		e.notificationSvc.NotifyCustomer(ctx, request.UserID, slaBreachNotification(refunded))
+	}
+	return ids, errors.Join(refundErrs...)
+}
+
+func (e *DispatchEngine) refundBreach(ctx context.Context, store ArrivalSLAStore, request *EmergencyRequest) error {
+	if e.paymentSvc == nil {
+		return ErrNoPaymentService
+	}
+	if err := e.paymentSvc.RefundHeld(ctx, request, "Arrival SLA missed"); err != nil {
+		return err
+	}
+	request.PaymentStatus = PaymentRefunded
+	return store.SetPaymentStatus(ctx, request.ID, PaymentRefunded)
+}
+
+func slaBreachNotification(refunded bool) *CustomerNotification {
+	if refunded {
+		return &CustomerNotification{
+			Type:    "sla_breached",
+			Title:   "Your technician is running late",
+			Message: "Your technician missed the guaranteed arrival time, so we've refunded your payment. They're still on the way.",
+		}
+	}
+	return &CustomerNotification{
+		Type:    "sla_breached",
+		Title:   "Your technician is running late",
+		Message: "Your technician missed the guaranteed arrival time. Our support team will be in touch about your SLA refund.",
+	}
+}
+
+// PostgresArrivalSLAStore flags breaches in the live flow's emergencies with
+// a single UPDATE, so concurrent sweeps never claim the same request twice
+type PostgresArrivalSLAStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresArrivalSLAStore creates a database-backed SLA store
+func NewPostgresArrivalSLAStore(db *pgxpool.Pool) *PostgresArrivalSLAStore {
+	return &PostgresArrivalSLAStore{db: db}
+}
+
+// FlagSLABreaches flags requests past their arrival deadline and returns them
+func (p *PostgresArrivalSLAStore) FlagSLABreaches(ctx context.Context, now time.Time, update StatusUpdate) ([]EmergencyRequest, error) {
+	updateJSON, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+	
+	rows, err := p.db.Query(ctx, `
+		UPDATE emergencies SET
+			sla_breached = TRUE,
+			status_history = COALESCE(status_history, '[]'::jsonb)
+				|| jsonb_build_array($2::jsonb || jsonb_build_object('status', status)),
+			updated_at = $1
+		WHERE status IN ('accepted', 'en_route')
+		  AND NOT sla_breached
+		  AND actual_arrival_time IS NULL
+		  AND arrival_deadline < $1
+		RETURNING id, user_id, category, urgency, status, status_history,
+		          assigned_tech_id, arrival_deadline, payment_status, created_at
+	`, now, updateJSON)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var breached []EmergencyRequest
+	for rows.Next() {
+		var r EmergencyRequest
+		var historyJSON []byte
+		if err := rows.Scan(
+			&r.ID, &r.UserID, &r.Category, &r.Urgency, &r.Status, &historyJSON,
+			&r.AssignedTechID, &r.ArrivalDeadline, &r.PaymentStatus, &r.CreatedAt,
+		); err != nil {
+			return breached, err
+		}
+		json.Unmarshal(historyJSON, &r.StatusHistory)
+		r.SLABreached = true
+		breached = append(breached, r)
+	}
+	
+	return breached, rows.Err()
+}
+
+// SetPaymentStatus updates a request's payment status
+func (p *PostgresArrivalSLAStore) SetPaymentStatus(ctx context.Context, requestID uuid.UUID, status PaymentStatus) error {
+	_, err := p.db.Exec(ctx, `
+		UPDATE emergencies SET payment_status = $2, updated_at = NOW()
+		WHERE id = $1
+	`, requestID, status)
+	return err
+}
+
+// MemoryArrivalSLAStore keeps requests in process
+type MemoryArrivalSLAStore struct {
+	mu       sync.Mutex
+	requests map[uuid.UUID]*EmergencyRequest
+}
+
+// NewMemoryArrivalSLAStore creates an empty in-process store
+func NewMemoryArrivalSLAStore() *MemoryArrivalSLAStore {
+	return &MemoryArrivalSLAStore{requests: make(map[uuid.UUID]*EmergencyRequest)}
+}
+
+// Put stores a copy of a request
+func (m *MemoryArrivalSLAStore) Put(request EmergencyRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	request.StatusHistory = append([]StatusUpdate(nil), request.StatusHistory...)
+	m.requests[request.ID] = &request
+}
+
+// Get returns a copy of a stored request
+func (m *MemoryArrivalSLAStore) Get(requestID uuid.UUID) (EmergencyRequest, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.requests[requestID]
+	if !ok {
+		return EmergencyRequest{}, false
+	}
+	copied := *r
+	copied.StatusHistory = append([]StatusUpdate(nil), r.StatusHistory...)
+	return copied, true
+}
+
+// FlagSLABreaches flags requests past their arrival deadline and returns them
+func (m *MemoryArrivalSLAStore) FlagSLABreaches(ctx context.Context, now time.Time, update StatusUpdate) ([]EmergencyRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	var breached []EmergencyRequest
+	for _, r := range m.requests {
+		if !ArrivalSLABreached(r, now) {
+			continue
+		}
+		r.SLABreached = true
+		entry := update
+		entry.Status = r.Status
+		r.StatusHistory = append(r.StatusHistory, entry)
+		r.UpdatedAt = now
+		copied := *r
+		copied.StatusHistory = append([]StatusUpdate(nil), r.StatusHistory...)
+		breached = append(breached, copied)
+	}
+	return breached, nil
+}
+
+// SetPaymentStatus updates a stored request's payment status
+func (m *MemoryArrivalSLAStore) SetPaymentStatus(ctx context.Context, requestID uuid.UUID, status PaymentStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.requests[requestID]; ok {
+		r.PaymentStatus = status
+	}
+	return nil
+}
+
+// =============================================================================
+// 3.3 DISPATCH STATE PERSISTENCE
+// =============================================================================
+
+// DispatchStateStore keeps dispatch progress outside the process, so a
+// restart picks up where the last instance left off
+type DispatchStateStore interface {
+	// SaveRequestState records a request's search radius and attempts
+	SaveRequestState(ctx context.Context, state RequestState) error
+	// ActiveRequests loads every request still being dispatched or with a
+	// technician on the way, with its saved progress
+	ActiveRequests(ctx context.Context) ([]RequestState, error)
+}
+
+// rehydratedStatuses are the request statuses dispatch still tracks
+var rehydratedStatuses = []RequestStatus{StatusSearching, StatusAssigned, StatusAccepted, StatusEnRoute}
+
+// SetDispatchStateStore replaces where dispatch progress is saved; nil
+// keeps it in memory only
+func (e *DispatchEngine) SetDispatchStateStore(store DispatchStateStore) {
+	e.stateStore = store
+}
+
+// saveState records a request's progress, if there's somewhere to keep it
+func (e *DispatchEngine) saveState(ctx context.Context, requestID uuid.UUID) {
+	if e.stateStore == nil {
+		return
+	}
+	e.mu.RLock()
+	state := e.activeRequests[requestID]
+	var snapshot RequestState
+	if state != nil {
+		snapshot = *state
+	}
+	e.mu.RUnlock()
+	if state != nil {
+		e.stateStore.SaveRequestState(ctx, snapshot)
+	}
+}
+
+// NeedsAssignment reports whether a request is still waiting for a
+// technician to accept it
+func NeedsAssignment(request *EmergencyRequest) bool {
+	return request.Status == StatusSearching || request.Status == StatusAssigned
+}
+
+// RehydrateActiveState reloads in-flight requests at startup and resumes
+// dispatch for those nobody has accepted yet, from the search radius and
+// attempt count they had reached. Requests this instance already tracks are
+// left alone, as are those another instance took the resume lock for first,
+// so a rolling deploy or several replicas dispatch each request once.
+func (e *DispatchEngine) RehydrateActiveState(ctx context.Context) error {
+	if e.stateStore == nil {
+		return nil
+	}
+	states, err := e.stateStore.ActiveRequests(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load dispatch state: %w", err)
+	}
+	
+	for i := range states {
+		state := states[i]
+		if state.CurrentSearchRadius <= 0 {
+			state.CurrentSearchRadius = e.config.InitialSearchRadius
+		}
+		resume := NeedsAssignment(state.Request)
+		if resume && !e.claimResume(ctx, state.Request.ID) {
+			continue
+		}
+	
+		e.mu.Lock()
+		if e.activeRequests[state.Request.ID] != nil {
+			e.mu.Unlock()
+			continue
+		}
+		e.activeRequests[state.Request.ID] = &state
+		e.mu.Unlock()
+	
+		if resume {
+			go e.resumeDispatch(ctx, state.Request, state.AssignmentAttempts)
+		}
+	}
+	return nil
+}
+
+// claimResume takes the request's resume lock, reporting whether this
+// instance should resume its dispatch. The lock is left to expire rather
+// than released, so instances starting later in a rollout skip it too.
+func (e *DispatchEngine) claimResume(ctx context.Context, requestID uuid.UUID) bool {
+	if e.locker == nil {
+		return true
+	}
+	key := fmt.Sprintf("homerescue:resume:%s", requestID)
+	acquired, err := e.locker.TryLock(ctx, key, e.config.ResumeLockTTL)
+	// If the lock store is down, a duplicate dispatch beats a lost emergency
+	return acquired || err != nil
+}
+
+// resumeDispatch restarts dispatch for a request whose previous loop died
+// with the process that ran it
+func (e *DispatchEngine) resumeDispatch(ctx context.Context, request *EmergencyRequest, attempts int) {
+	if attempts >= e.config.MaxAssignmentAttempts {
+		e.Escalate(ctx, request)
+		return
+	}
+	e.Dispatch(ctx, request)
+}
+
+// PostgresDispatchStateStore keeps dispatch progress in the dispatch_state
+// table alongside emergency_requests
+type PostgresDispatchStateStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresDispatchStateStore creates a database-backed state store
+func NewPostgresDispatchStateStore(db *pgxpool.Pool) *PostgresDispatchStateStore {
+	return &PostgresDispatchStateStore{db: db}
+}
+
+// SaveRequestState upserts a request's progress
+func (p *PostgresDispatchStateStore) SaveRequestState(ctx context.Context, state RequestState) error {
+	var lastAttemptAt *time.Time
+	if !state.LastAttemptAt.IsZero() {
+		lastAttemptAt = &state.LastAttemptAt
+	}
+	_, err := p.db.Exec(ctx, `
+		INSERT INTO dispatch_state (
+			request_id, current_search_radius_km, assignment_attempts, last_attempt_at, updated_at
+		) VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (request_id) DO UPDATE SET
+			current_search_radius_km = EXCLUDED.current_search_radius_km,
+			assignment_attempts = EXCLUDED.assignment_attempts,
+			last_attempt_at = EXCLUDED.last_attempt_at,
+			updated_at = NOW()
+	`, state.Request.ID, state.CurrentSearchRadius, state.AssignmentAttempts, lastAttemptAt)
+	return err
+}
+
+// ActiveRequests loads in-flight requests with their saved progress;
+// requests that never reached an attempt come back with zero progress
+func (p *PostgresDispatchStateStore) ActiveRequests(ctx context.Context) ([]RequestState, error) {
+	statuses := make([]string, len(rehydratedStatuses))
+	for i, status := range rehydratedStatuses {
+		statuses[i] = string(status)
+	}
+	
+	rows, err := p.db.Query(ctx, `
+		SELECT `+dispatchRequestColumns+`,
+		       COALESCE(ds.current_search_radius_km, 0), COALESCE(ds.assignment_attempts, 0), ds.last_attempt_at
+		FROM emergency_requests er
+		LEFT JOIN dispatch_state ds ON ds.request_id = er.id
+		WHERE er.status = ANY($1)
+		ORDER BY er.created_at
+	`, statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var states []RequestState
+	for rows.Next() {
+		var request EmergencyRequest
+		var state RequestState
+		var lastAttemptAt *time.Time
+		if err := scanDispatchRequest(rows, &request, &state.CurrentSearchRadius, &state.AssignmentAttempts, &lastAttemptAt); err != nil {
+			return states, err
+		}
+		if lastAttemptAt != nil {
+			state.LastAttemptAt = *lastAttemptAt
+		}
+		state.Request = &request
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+// MemoryDispatchStateStore keeps requests and their progress in process
+type MemoryDispatchStateStore struct {
+	mu       sync.Mutex
+	requests map[uuid.UUID]EmergencyRequest
+	progress map[uuid.UUID]RequestState
+}
+
+// NewMemoryDispatchStateStore creates an empty in-process store
+func NewMemoryDispatchStateStore() *MemoryDispatchStateStore {
+	return &MemoryDispatchStateStore{
+		requests: make(map[uuid.UUID]EmergencyRequest),
+		progress: make(map[uuid.UUID]RequestState),
+	}
+}
+
+// PutRequest stores a copy of a request, as emergency_requests would
+func (m *MemoryDispatchStateStore) PutRequest(request EmergencyRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[request.ID] = request
+}
+
+// State returns a request's saved progress
+func (m *MemoryDispatchStateStore) State(requestID uuid.UUID) (RequestState, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.progress[requestID]
+	return state, ok
+}
+
+// SaveRequestState records a request's progress
+func (m *MemoryDispatchStateStore) SaveRequestState(ctx context.Context, state RequestState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := state.Request.ID
+	state.Request = nil
+	m.progress[id] = state
+	return nil
+}
+
+// ActiveRequests returns copies of in-flight requests with their progress
+func (m *MemoryDispatchStateStore) ActiveRequests(ctx context.Context) ([]RequestState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	var states []RequestState
+	for id, request := range m.requests {
+		active := false
+		for _, status := range rehydratedStatuses {
+			active = active || request.Status == status
+		}
+		if !active {
+			continue
+		}
+		state := m.progress[id]
+		copied := request
+		state.Request = &copied
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// =============================================================================
+// SECTION 4: REAL-TIME TRACKING
+// =============================================================================
+
+// TrackingService provides real-time location tracking
+type TrackingService struct {
+	db              *pgxpool.Pool
+	cache           *redis.Client
+	pubsub          *PubSubService
+	notificationSvc CustomerNotifier
+	proximity       *ProximityNotifier
+}
+
+// CustomerNotifier delivers notifications to customers
+type CustomerNotifier interface {
+	NotifyCustomer(ctx context.Context, userID uuid.UUID, notification *CustomerNotification)
+}
+
+// SetCustomerNotifier sets where proximity notifications are sent
+func (s *TrackingService) SetCustomerNotifier(notifier CustomerNotifier) {
+	s.notificationSvc = notifier
+}
+
+// NewTrackingService creates a tracking service with the default proximity
+// notification thresholds
+func NewTrackingService(db *pgxpool.Pool, cache *redis.Client) *TrackingService {
+	return &TrackingService{
+		db:              db,
+		cache:           cache,
+		pubsub:          NewPubSubService(cache),
+		notificationSvc: &NotificationService{},
+		proximity:       NewProximityNotifier(cache, DefaultProximityThresholds()),
+	}
+}
+
+// TechLocationUpdate from mobile app
+type TechLocationUpdate struct {
+	TechID    uuid.UUID `json:"tech_id"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Accuracy  float64   `json:"accuracy_meters"`
+	Speed     float64   `json:"speed_kmh"`
+	Heading   float64   `json:"heading_degrees"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TrackingUpdate sent to customer
+type TrackingUpdate struct {
+	RequestID        uuid.UUID `json:"request_id"`
+	TechID           uuid.UUID `json:"tech_id"`
+	TechName         string    `json:"tech_name"`
+	TechPhoto        string    `json:"tech_photo"`
+	CurrentLocation  GeoPoint  `json:"current_location"`
+	DistanceRemaining float64  `json:"distance_remaining_km"`
+	ETAMinutes       int       `json:"eta_minutes"`
+	Status           string    `json:"status"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// UpdateTechLocation processes a location update from a technician
+func (s *TrackingService) UpdateTechLocation(ctx context.Context, update TechLocationUpdate) error {
+	// Update tech's current location in database
+	_, err := s.db.Exec(ctx, `
+		UPDATE emergency_technicians
+		SET current_location = ST_MakePoint($2, $3),
+		    last_location_update = $4
+		WHERE id = $1
+	`, update.TechID, update.Longitude, update.Latitude, update.Timestamp)
+	
+	if err != nil {
+		return err
+	}
+	
+	// Cache for real-time access
+	locationJSON, _ := json.Marshal(update)
+	s.cache.Set(ctx, fmt.Sprintf("tech:location:%s", update.TechID), locationJSON, 5*time.Minute)
+	
+	// Check if tech has an active request
+	var requestID uuid.UUID
+	var customerUserID uuid.UUID
+	var destLat, destLng float64
+	
+	err = s.db.QueryRow(ctx, `
+		SELECT er.id, er.user_id, er.location->>'latitude', er.location->>'longitude'
+		FROM emergency_requests er
+		JOIN emergency_technicians et ON et.id = er.assigned_tech_id
+		WHERE et.id = $1 AND er.status IN ('accepted', 'en_route')
+	`, update.TechID).Scan(&requestID, &customerUserID, &destLat, &destLng)
+	
+	if err != nil {
+		// No active request
+		return nil
+	}
+	
+	// Calculate distance remaining
+	distance := geo.Distance(
+		GeoPoint{Latitude: update.Latitude, Longitude: update.Longitude},
+		GeoPoint{Latitude: destLat, Longitude: destLng},
+	)
 	
 	// Calculate ETA based on speed and distance
 	eta := s.calculateETA(distance, update.Speed)
@@ -891,7 +2453,9 @@ func (s *TrackingService) UpdateTechLocation(ctx context.Context, update TechLoc
 	}
 	
 	// Publish to customer's channel
-	s.pubsub.Publish(ctx, fmt.Sprintf("tracking:%s", requestID), trackingUpdate)
+	s.pubsub.Publish(ctx, TrackingChannel(requestID), trackingUpdate)
+	
+	s.notifyProximity(ctx, requestID, customerUserID, techName, eta)
 	
 	// Check for arrival
 	if distance < 0.1 { // Within 100 meters
@@ -901,20 +2465,164 @@ func (s *TrackingService) UpdateTechLocation(ctx context.Context, update TechLoc
 	return nil
 }
 
-func (s *TrackingService) calculateDistance(lat1, lng1, lat2, lng2 float64) float64 {
-	// Haversine formula
-	const R = 6371 // Earth's radius in km
+// PublishEmergencyLocation publishes a location reported through the live
+// emergency flow to the emergency's tracking stream, with the distance and
+// ETA left to the customer's address
+func (s *TrackingService) PublishEmergencyLocation(ctx context.Context, emergency *homerescue.Emergency, lat, lon float64) {
+	location := GeoPoint{Latitude: lat, Longitude: lon}
+	distance := geo.Distance(location, GeoPoint{Latitude: emergency.Latitude, Longitude: emergency.Longitude})
+	eta := s.calculateETA(distance, 0)
+	
+	update := TrackingUpdate{
+		RequestID:         emergency.ID,
+		CurrentLocation:   location,
+		DistanceRemaining: distance,
+		ETAMinutes:        eta,
+		Status:            emergency.Status,
+		UpdatedAt:         time.Now(),
+	}
+	if emergency.AssignedTechID != nil {
+		update.TechID = *emergency.AssignedTechID
+	}
+	s.pubsub.Publish(ctx, TrackingChannel(emergency.ID), update)
+	
+	s.notifyProximity(ctx, emergency.ID, emergency.UserID, "", eta)
+}
+
+// notifyProximity lets the customer know when the tech is getting close
+func (s *TrackingService) notifyProximity(ctx context.Context, requestID, customerUserID uuid.UUID, techName string, eta int) {
+	if s.proximity == nil || s.notificationSvc == nil {
+		return
+	}
+	if threshold := s.proximity.Check(ctx, requestID, eta); threshold != nil {
+		notification := threshold.Notification(techName, eta)
+		notification.RequestID = requestID
+		s.notificationSvc.NotifyCustomer(ctx, customerUserID, notification)
+	}
+}
+
+// ProximityThreshold is an ETA at which the customer gets a heads-up
+type ProximityThreshold struct {
+	Key        string
+	ETAMinutes int
+	Title      string
+	Message    string // may contain %s for the tech name and %d for minutes
+}
+
+// Notification renders the customer notification for this threshold
+func (t ProximityThreshold) Notification(techName string, eta int) *CustomerNotification {
+	if techName == "" {
+		techName = "Your technician"
+	}
+	message := t.Message
+	if strings.Contains(message, "%d") {
+		message = fmt.Sprintf(message, techName, eta)
+	} else if strings.Contains(message, "%s") {
+		message = fmt.Sprintf(message, techName)
+	}
+	return &CustomerNotification{
+		Type:    "tech_proximity_" + t.Key,
+		Title:   t.Title,
+		Message: message,
+	}
+}
+
+// DefaultProximityThresholds notify at 10 minutes, 5 minutes and on arrival.
+// calculateETA adds a 3 minute parking buffer, so 3 means "at the door".
+func DefaultProximityThresholds() []ProximityThreshold {
+	return []ProximityThreshold{
+		{Key: "10_min", ETAMinutes: 10, Title: "Technician on the way", Message: "%s is about %d minutes away"},
+		{Key: "5_min", ETAMinutes: 5, Title: "Almost there", Message: "%s is about %d minutes away"},
+		{Key: "arriving", ETAMinutes: 3, Title: "Technician arriving", Message: "%s is arriving now"},
+	}
+}
+
+// ProximityNotifier decides which proximity notification, if any, to send
+// for an ETA update. Each threshold fires at most once per request.
+type ProximityNotifier struct {
+	cache      *redis.Client
+	thresholds []ProximityThreshold
+	ttl        time.Duration
+	mu         sync.Mutex
+	fired      map[uuid.UUID]map[string]bool
+}
+
+// NewProximityNotifier creates a notifier; thresholds are checked from the
+// closest ETA outwards
+func NewProximityNotifier(cache *redis.Client, thresholds []ProximityThreshold) *ProximityNotifier {
+	sorted := make([]ProximityThreshold, len(thresholds))
+	copy(sorted, thresholds)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ETAMinutes < sorted[j].ETAMinutes
+	})
+	
+	return &ProximityNotifier{
+		cache:      cache,
+		thresholds: sorted,
+		ttl:        6 * time.Hour,
+		fired:      make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+// Check returns the threshold to notify for this ETA, or nil. When one update
+// crosses several thresholds only the closest is returned and the wider ones
+// are marked as sent, so a "10 minutes away" never follows "arriving".
+func (p *ProximityNotifier) Check(ctx context.Context, requestID uuid.UUID, etaMinutes int) *ProximityThreshold {
+	var notify *ProximityThreshold
+	
+	for i := range p.thresholds {
+		t := p.thresholds[i]
+		if etaMinutes > t.ETAMinutes {
+			continue
+		}
+		if !p.claim(ctx, requestID, t.Key) {
+			continue
+		}
+		if notify == nil {
+			notify = &t
+		}
+	}
 	
-	dLat := (lat2 - lat1) * math.Pi / 180
-	dLng := (lng2 - lng1) * math.Pi / 180
+	return notify
+}
+
+// Reset forgets the thresholds fired for a request, e.g. after reassignment
+func (p *ProximityNotifier) Reset(ctx context.Context, requestID uuid.UUID) {
+	p.mu.Lock()
+	delete(p.fired, requestID)
+	p.mu.Unlock()
 	
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*
-			math.Sin(dLng/2)*math.Sin(dLng/2)
+	if p.cache != nil {
+		for _, t := range p.thresholds {
+			p.cache.Del(ctx, p.key(requestID, t.Key))
+		}
+	}
+}
+
+func (p *ProximityNotifier) claim(ctx context.Context, requestID uuid.UUID, key string) bool {
+	if p.cache != nil {
+		claimed, err := p.cache.SetNX(ctx, p.key(requestID, key), time.Now().Unix(), p.ttl).Result()
+		if err == nil {
+			return claimed
+		}
+		// Fall through to in-memory tracking if Redis is unavailable
+	}
 	
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	
-	return R * c
+	if p.fired[requestID] == nil {
+		p.fired[requestID] = make(map[string]bool)
+	}
+	if p.fired[requestID][key] {
+		return false
+	}
+	p.fired[requestID][key] = true
+	return true
+}
+
+func (p *ProximityNotifier) key(requestID uuid.UUID, key string) string {
+	return fmt.Sprintf("tracking:proximity:%s:%s", requestID, key)
 }
 
 func (s *TrackingService) calculateETA(distance, speed float64) int {
@@ -947,7 +2655,7 @@ func (s *TrackingService) handleArrival(ctx context.Context, requestID, techID u
 	s.db.QueryRow(ctx, `SELECT user_id FROM emergency_requests WHERE id = $1`, requestID).Scan(&customerUserID)
 	
 	// Publish arrival event
-	s.pubsub.Publish(ctx, fmt.Sprintf("tracking:%s", requestID), TrackingUpdate{
+	s.pubsub.Publish(ctx, TrackingChannel(requestID), TrackingUpdate{
 		RequestID: requestID,
 		TechID:    techID,
 		Status:    "arrived",
@@ -955,9 +2663,15 @@ func (s *TrackingService) handleArrival(ctx context.Context, requestID, techID u
 	})
 }
 
+// TrackingChannel is the pub/sub channel a request's tracking updates are
+// published on
+func TrackingChannel(requestID uuid.UUID) string {
+	return fmt.Sprintf("tracking:%s", requestID)
+}
+
 // SubscribeToTracking allows customer to get real-time updates
 func (s *TrackingService) SubscribeToTracking(ctx context.Context, requestID uuid.UUID) (<-chan TrackingUpdate, error) {
-	return s.pubsub.Subscribe(ctx, fmt.Sprintf("tracking:%s", requestID))
+	return s.pubsub.Subscribe(ctx, TrackingChannel(requestID))
 }
 
 // =============================================================================
@@ -968,6 +2682,31 @@ func (s *TrackingService) SubscribeToTracking(ctx context.Context, requestID uui
 type EmergencyPricingEngine struct {
 	db    *pgxpool.Pool
 	cache *redis.Client
+	
+	// Vendor rates layered over the category defaults
+	overrides map[uuid.UUID]map[EmergencyCategory]PricingOverride
+	
+	// Public holidays by calendar day
+	holidays  map[string][]Holiday
+	
+	// Where demand surge is counted, and recent lookups
+	demand     DemandSource
+	demandMemo map[string]demandMemoEntry
+	mu         sync.RWMutex
+}
+
+// NewEmergencyPricingEngine creates a pricing engine with no vendor overrides.
+// With a database it prices in demand surge.
+func NewEmergencyPricingEngine(db *pgxpool.Pool, cache *redis.Client) *EmergencyPricingEngine {
+	e := &EmergencyPricingEngine{
+		db:        db,
+		cache:     cache,
+		overrides: make(map[uuid.UUID]map[EmergencyCategory]PricingOverride),
+	}
+	if db != nil {
+		e.demand = NewPostgresDemandSource(db)
+	}
+	return e
 }
 
 // PricingRules for different scenarios
@@ -1092,20 +2831,330 @@ var DefaultPricingRules = map[EmergencyCategory]PricingRules{
 	},
 }
 
-// EstimatePrice estimates the price for an emergency service
-func (e *EmergencyPricingEngine) EstimatePrice(category EmergencyCategory, urgency UrgencyLevel, distance float64) float64 {
+// PricingOverride holds the rates a vendor sets in place of the category
+// defaults. Unset fields keep the default.
+type PricingOverride struct {
+	CallOutFee      *float64 `json:"call_out_fee,omitempty"`
+	MinimumCharge   *float64 `json:"minimum_charge,omitempty"`
+	StandardRate    *float64 `json:"standard_rate,omitempty"`
+	AfterHoursRate  *float64 `json:"after_hours_rate,omitempty"`
+	HolidayRate     *float64 `json:"holiday_rate,omitempty"`
+	CriticalPremium *float64 `json:"critical_premium,omitempty"`
+	UrgentPremium   *float64 `json:"urgent_premium,omitempty"`
+	FreeDistanceKM  *float64 `json:"free_distance_km,omitempty"`
+	PerKMCharge     *float64 `json:"per_km_charge,omitempty"`
+}
+
+// PricingBounds keeps vendor rates within a band around the category default
+type PricingBounds struct {
+	MinFactor  float64 // Lowest fee or rate as a multiple of the default
+	MaxFactor  float64 // Highest fee or rate as a multiple of the default
+	MaxPremium float64 // Highest urgency premium, percent
+}
+
+// DefaultPricingBounds lets vendors price from half to double the default
+var DefaultPricingBounds = PricingBounds{
+	MinFactor:  0.5,
+	MaxFactor:  2.0,
+	MaxPremium: 100,
+}
+
+// ErrPricingOutOfBounds is returned for overrides outside platform bounds
+var ErrPricingOutOfBounds = errors.New("pricing override outside platform bounds")
+
+// defaultRulesFor returns the category defaults, falling back to general
+func defaultRulesFor(category EmergencyCategory) PricingRules {
 	rules, ok := DefaultPricingRules[category]
 	if !ok {
 		rules = DefaultPricingRules[CategoryGeneral]
 	}
+	return rules
+}
+
+// ValidatePricingOverride checks every set field stays within bounds of the
+// category default
+func ValidatePricingOverride(category EmergencyCategory, override PricingOverride, bounds PricingBounds) error {
+	defaults := defaultRulesFor(category)
+	
+	scaled := []struct {
+		name     string
+		value    *float64
+		baseline float64
+	}{
+		{"call_out_fee", override.CallOutFee, defaults.CallOutFee},
+		{"minimum_charge", override.MinimumCharge, defaults.MinimumCharge},
+		{"standard_rate", override.StandardRate, defaults.StandardRate},
+		{"after_hours_rate", override.AfterHoursRate, defaults.AfterHoursRate},
+		{"holiday_rate", override.HolidayRate, defaults.HolidayRate},
+		{"per_km_charge", override.PerKMCharge, defaults.PerKMCharge},
+	}
+	for _, f := range scaled {
+		if f.value == nil {
+			continue
+		}
+		if *f.value < f.baseline*bounds.MinFactor || *f.value > f.baseline*bounds.MaxFactor {
+			return fmt.Errorf("%w: %s must be between %.0f and %.0f", ErrPricingOutOfBounds,
+				f.name, f.baseline*bounds.MinFactor, f.baseline*bounds.MaxFactor)
+		}
+	}
+	
+	for name, premium := range map[string]*float64{
+		"critical_premium": override.CriticalPremium,
+		"urgent_premium":   override.UrgentPremium,
+	} {
+		if premium != nil && (*premium < 0 || *premium > bounds.MaxPremium) {
+			return fmt.Errorf("%w: %s must be between 0 and %.0f", ErrPricingOutOfBounds, name, bounds.MaxPremium)
+		}
+	}
+	
+	if override.FreeDistanceKM != nil && *override.FreeDistanceKM < 0 {
+		return fmt.Errorf("%w: free_distance_km must not be negative", ErrPricingOutOfBounds)
+	}
+	
+	return nil
+}
+
+// ApplyPricingOverride layers a vendor override on top of category rules
+func ApplyPricingOverride(rules PricingRules, override PricingOverride) PricingRules {
+	set := func(dst *float64, v *float64) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	set(&rules.CallOutFee, override.CallOutFee)
+	set(&rules.MinimumCharge, override.MinimumCharge)
+	set(&rules.StandardRate, override.StandardRate)
+	set(&rules.AfterHoursRate, override.AfterHoursRate)
+	set(&rules.HolidayRate, override.HolidayRate)
+	set(&rules.CriticalPremium, override.CriticalPremium)
+	set(&rules.UrgentPremium, override.UrgentPremium)
+	set(&rules.FreeDistanceKM, override.FreeDistanceKM)
+	set(&rules.PerKMCharge, override.PerKMCharge)
+	return rules
+}
+
+// SetVendorOverride validates and installs a vendor's rates for a category
+func (e *EmergencyPricingEngine) SetVendorOverride(vendorID uuid.UUID, category EmergencyCategory, override PricingOverride) error {
+	if err := ValidatePricingOverride(category, override, DefaultPricingBounds); err != nil {
+		return err
+	}
+	
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.overrides == nil {
+		e.overrides = make(map[uuid.UUID]map[EmergencyCategory]PricingOverride)
+	}
+	if e.overrides[vendorID] == nil {
+		e.overrides[vendorID] = make(map[EmergencyCategory]PricingOverride)
+	}
+	e.overrides[vendorID][category] = override
+	return nil
+}
+
+// SaveVendorOverride validates, persists and installs a vendor's rates
+func (e *EmergencyPricingEngine) SaveVendorOverride(ctx context.Context, vendorID uuid.UUID, category EmergencyCategory, override PricingOverride) error {
+	if err := ValidatePricingOverride(category, override, DefaultPricingBounds); err != nil {
+		return err
+	}
+	
+	overrideJSON, _ := json.Marshal(override)
+	_, err := e.db.Exec(ctx, `
+		INSERT INTO vendor_pricing_overrides (vendor_id, category, overrides, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (vendor_id, category) DO UPDATE SET overrides = $3, updated_at = NOW()
+	`, vendorID, category, overrideJSON)
+	if err != nil {
+		return err
+	}
+	
+	return e.SetVendorOverride(vendorID, category, override)
+}
+
+// LoadVendorOverrides installs every stored vendor override, skipping any
+// that no longer fit the platform bounds
+func (e *EmergencyPricingEngine) LoadVendorOverrides(ctx context.Context) error {
+	rows, err := e.db.Query(ctx, `SELECT vendor_id, category, overrides FROM vendor_pricing_overrides`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var vendorID uuid.UUID
+		var category EmergencyCategory
+		var overrideJSON []byte
+		if err := rows.Scan(&vendorID, &category, &overrideJSON); err != nil {
+			continue
+		}
+		var override PricingOverride
+		if err := json.Unmarshal(overrideJSON, &override); err != nil {
+			continue
+		}
+		e.SetVendorOverride(vendorID, category, override)
+	}
+	
+	return rows.Err()
+}
+
+// RulesFor returns the pricing rules for a category, with the vendor's
+// override applied when vendorID is known and has one
+func (e *EmergencyPricingEngine) RulesFor(category EmergencyCategory, vendorID uuid.UUID) PricingRules {
+	rules := defaultRulesFor(category)
+	if e == nil || vendorID == uuid.Nil {
+		return rules
+	}
+	
+	e.mu.RLock()
+	override, ok := e.overrides[vendorID][category]
+	e.mu.RUnlock()
+	if !ok {
+		return rules
+	}
+	return ApplyPricingOverride(rules, override)
+}
+
+// Demand surge: when open requests outnumber the technicians free to take
+// them, labour and call-out are priced up to MaxDemandMultiplier
+const (
+	MaxDemandMultiplier   = 2.0
+	DefaultDemandRadiusKm = 10.0
+	
+	// demandMemoTTL keeps one dispatch's candidate pricing to a single
+	// demand lookup
+	demandMemoTTL = 30 * time.Second
+)
+
+// Demand is the supply and demand for a category around a location
+type Demand struct {
+	AvailableTechs int `json:"available_techs"`
+	OpenRequests   int `json:"open_requests"`
+}
+
+// DemandSource counts available technicians and open requests nearby
+type DemandSource interface {
+	Demand(ctx context.Context, category EmergencyCategory, location EmergencyLocation, radiusKm float64) (Demand, error)
+}
+
+// SetDemandSource replaces where demand is counted; nil turns surge off
+func (e *EmergencyPricingEngine) SetDemandSource(source DemandSource) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.demand = source
+	e.demandMemo = nil
+}
+
+// SurgeMultiplier turns demand into a price multiplier between 1.0 and
+// MaxDemandMultiplier, with the reason to show the customer when it's above
+// 1.0
+func SurgeMultiplier(category EmergencyCategory, d Demand) (float64, string) {
+	if d.OpenRequests <= d.AvailableTechs {
+		return 1.0, ""
+	}
+	
+	multiplier := MaxDemandMultiplier
+	if d.AvailableTechs > 0 {
+		multiplier = math.Min(float64(d.OpenRequests)/float64(d.AvailableTechs), MaxDemandMultiplier)
+	}
+	multiplier = math.Round(multiplier*100) / 100
+	
+	reason := fmt.Sprintf("High demand: %d open %s requests nearby for %d available technicians (%.2fx)",
+		d.OpenRequests, category, d.AvailableTechs, multiplier)
+	return multiplier, reason
+}
+
+type demandMemoEntry struct {
+	multiplier float64
+	reason     string
+	expires    time.Time
+}
+
+// DemandMultiplier is the surge multiplier for a category at a location,
+// with its reason. Without a demand source, or if demand can't be counted,
+// it's 1.0 with no reason.
+func (e *EmergencyPricingEngine) DemandMultiplier(ctx context.Context, category EmergencyCategory, location EmergencyLocation) (float64, string) {
+	key := fmt.Sprintf("%s:%.2f:%.2f", category, location.Latitude, location.Longitude)
+	now := time.Now()
+	
+	e.mu.RLock()
+	source := e.demand
+	memo, ok := e.demandMemo[key]
+	e.mu.RUnlock()
+	if source == nil {
+		return 1.0, ""
+	}
+	if ok && now.Before(memo.expires) {
+		return memo.multiplier, memo.reason
+	}
+	
+	d, err := source.Demand(ctx, category, location, DefaultDemandRadiusKm)
+	if err != nil {
+		return 1.0, ""
+	}
+	multiplier, reason := SurgeMultiplier(category, d)
+	
+	e.mu.Lock()
+	if e.demandMemo == nil {
+		e.demandMemo = make(map[string]demandMemoEntry)
+	}
+	e.demandMemo[key] = demandMemoEntry{multiplier: multiplier, reason: reason, expires: now.Add(demandMemoTTL)}
+	e.mu.Unlock()
+	
+	return multiplier, reason
+}
+
+// PostgresDemandSource counts demand from the technician and request tables
+type PostgresDemandSource struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresDemandSource creates a database-backed demand source
+func NewPostgresDemandSource(db *pgxpool.Pool) *PostgresDemandSource {
+	return &PostgresDemandSource{db: db}
+}
+
+// Demand counts available technicians and unassigned requests within
+// radiusKm of the location
+func (p *PostgresDemandSource) Demand(ctx context.Context, category EmergencyCategory, location EmergencyLocation, radiusKm float64) (Demand, error) {
+	var d Demand
+	err := p.db.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*)
+			 FROM emergency_technicians et
+			 WHERE et.is_online = TRUE
+			   AND et.current_status = 'available'
+			   AND et.is_verified = TRUE
+			   AND $1 = ANY(et.categories)
+			   AND ST_DWithin(et.current_location::geography, ST_MakePoint($2, $3)::geography, $4 * 1000)),
+			(SELECT COUNT(*)
+			 FROM emergency_requests er
+			 WHERE er.category = $1
+			   AND er.status IN ('new', 'searching')
+			   AND ST_DWithin(
+				   ST_MakePoint((er.location->>'longitude')::float8, (er.location->>'latitude')::float8)::geography,
+				   ST_MakePoint($2, $3)::geography,
+				   $4 * 1000
+			   ))
+	`, category, location.Longitude, location.Latitude, radiusKm).Scan(&d.AvailableTechs, &d.OpenRequests)
+	return d, err
+}
+
+// EstimatePrice estimates the price for an emergency service. Pass
+// uuid.Nil for vendorID when the vendor isn't known yet; the location's
+// state picks up regional holidays and its surroundings any demand surge.
+func (e *EmergencyPricingEngine) EstimatePrice(ctx context.Context, category EmergencyCategory, urgency UrgencyLevel, distance float64, vendorID uuid.UUID, location EmergencyLocation) float64 {
+	rules := e.RulesFor(category, vendorID)
 	
 	// Start with call-out fee
 	price := rules.CallOutFee
 	
 	// Add labor estimate (assume 1 hour average)
-	laborRate := e.getLaborRate(rules)
+	laborRate := e.LaborRate(rules, time.Now(), location.State)
 	price += laborRate
 	
+	// Scale for scarce technicians
+	multiplier, _ := e.DemandMultiplier(ctx, category, location)
+	price *= multiplier
+	
 	// Add urgency premium
 	switch urgency {
 	case UrgencyCritical:
@@ -1128,13 +3177,94 @@ func (e *EmergencyPricingEngine) EstimatePrice(category EmergencyCategory, urgen
 	return price
 }
 
-func (e *EmergencyPricingEngine) getLaborRate(rules PricingRules) float64 {
-	now := time.Now()
-	hour := now.Hour()
-	weekday := now.Weekday()
+// Estimate quotes a request for the customer. The final price is guaranteed
+// to be within 20% of the estimate, so that's the range quoted; any demand
+// surge is explained in the notes.
+func (e *EmergencyPricingEngine) Estimate(ctx context.Context, request *EmergencyRequest, vendorID uuid.UUID, distance float64) *PriceEstimate {
+	price := e.EstimatePrice(ctx, request.Category, request.Urgency, distance, vendorID, request.Location)
+	_, reason := e.DemandMultiplier(ctx, request.Category, request.Location)
+	
+	return &PriceEstimate{
+		TotalMin: math.Round(price),
+		TotalMax: math.Round(price * 1.2),
+		Currency: "NGN",
+		ValidFor: 15, // surge moves quickly
+		Notes:    reason,
+	}
+}
+
+// Holiday is a public holiday. An empty Region applies nationwide; otherwise
+// only requests in that state are billed at the holiday rate.
+type Holiday struct {
+	Date   time.Time `json:"date"`
+	Name   string    `json:"name"`
+	Region string    `json:"region,omitempty"`
+}
+
+// holidayKey is the calendar day a time falls on, in its own location
+func holidayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// SetHolidays replaces the holiday calendar
+func (e *EmergencyPricingEngine) SetHolidays(holidays []Holiday) {
+	calendar := make(map[string][]Holiday, len(holidays))
+	for _, h := range holidays {
+		key := holidayKey(h.Date)
+		calendar[key] = append(calendar[key], h)
+	}
+	
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.holidays = calendar
+}
+
+// LoadHolidays reads the holiday calendar from the database
+func (e *EmergencyPricingEngine) LoadHolidays(ctx context.Context) error {
+	rows, err := e.db.Query(ctx, `SELECT holiday_date, name, COALESCE(region, '') FROM holidays`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	var holidays []Holiday
+	for rows.Next() {
+		var h Holiday
+		if err := rows.Scan(&h.Date, &h.Name, &h.Region); err != nil {
+			return err
+		}
+		holidays = append(holidays, h)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	
+	e.SetHolidays(holidays)
+	return nil
+}
+
+// isHoliday reports whether t falls on a national holiday or one observed
+// in region
+func (e *EmergencyPricingEngine) isHoliday(t time.Time, region string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, h := range e.holidays[holidayKey(t)] {
+		if h.Region == "" || strings.EqualFold(h.Region, region) {
+			return true
+		}
+	}
+	return false
+}
+
+// LaborRate is the hourly rate for work at the given time in region (the
+// state of the emergency). Holidays take precedence over after-hours.
+func (e *EmergencyPricingEngine) LaborRate(rules PricingRules, at time.Time, region string) float64 {
+	if e.isHoliday(at, region) {
+		return rules.HolidayRate
+	}
 	
-	// Check if holiday (would need holiday calendar)
-	// isHoliday := e.isHoliday(now)
+	hour := at.Hour()
+	weekday := at.Weekday()
 	
 	// After hours: before 8 AM, after 6 PM, or weekends
 	if hour < 8 || hour >= 18 || weekday == time.Saturday || weekday == time.Sunday {
@@ -1144,17 +3274,21 @@ func (e *EmergencyPricingEngine) getLaborRate(rules PricingRules) float64 {
 	return rules.StandardRate
 }
 
-// CalculateFinalPrice calculates the final price after work is done
+// CalculateFinalPrice calculates the final price after work is done, at the
+// rates of the vendor who did it
 func (e *EmergencyPricingEngine) CalculateFinalPrice(
+	ctx context.Context,
 	category EmergencyCategory,
 	urgency UrgencyLevel,
+	vendorID uuid.UUID,
 	laborHours float64,
 	parts []PartUsed,
 	distance float64,
 	discountCode string,
+	location EmergencyLocation,
 ) *FinalPrice {
-	rules, ok := DefaultPricingRules[category]
-	if !ok {
+	rules := e.RulesFor(category, vendorID)
+	if _, ok := DefaultPricingRules[category]; !ok {
 		rules = PricingRules{
 			CallOutFee:     15000,
 			StandardRate:   10000,
@@ -1170,10 +3304,14 @@ func (e *EmergencyPricingEngine) CalculateFinalPrice(
 	final.CallOutFee = rules.CallOutFee
 	
 	// Labor
-	laborRate := e.getLaborRate(rules)
+	laborRate := e.LaborRate(rules, time.Now(), location.State)
 	final.LaborHours = laborHours
 	final.LaborCost = laborRate * laborHours
 	
+	// Demand surge on call-out and labour
+	final.DemandMultiplier, _ = e.DemandMultiplier(ctx, category, location)
+	final.DemandSurcharge = (final.CallOutFee + final.LaborCost) * (final.DemandMultiplier - 1)
+	
 	// Parts
 	for _, part := range parts {
 		if !part.IsWarranty {
@@ -1184,13 +3322,13 @@ func (e *EmergencyPricingEngine) CalculateFinalPrice(
 	// Emergency premium
 	switch urgency {
 	case UrgencyCritical:
-		final.EmergencyPremium = (final.CallOutFee + final.LaborCost) * (rules.CriticalPremium / 100)
+		final.EmergencyPremium = (final.CallOutFee + final.LaborCost + final.DemandSurcharge) * (rules.CriticalPremium / 100)
 	case UrgencyUrgent:
-		final.EmergencyPremium = (final.CallOutFee + final.LaborCost) * (rules.UrgentPremium / 100)
+		final.EmergencyPremium = (final.CallOutFee + final.LaborCost + final.DemandSurcharge) * (rules.UrgentPremium / 100)
 	}
 	
 	// Subtotal
-	final.Subtotal = final.CallOutFee + final.LaborCost + final.PartsCost + final.EmergencyPremium
+	final.Subtotal = final.CallOutFee + final.LaborCost + final.DemandSurcharge + final.PartsCost + final.EmergencyPremium
 	
 	// Discount
 	if discountCode != "" {
@@ -1229,6 +3367,7 @@ type HomeRescueAPI struct {
 type CreateEmergencyRequest struct {
 	Category           EmergencyCategory `json:"category"`
 	Subcategory        string            `json:"subcategory,omitempty"`
+	IntakeAnswers      map[string]string `json:"intake_answers,omitempty"`
 	Description        string            `json:"description"`
 	Location           EmergencyLocation `json:"location"`
 	AccessInstructions string            `json:"access_instructions,omitempty"`
@@ -1238,6 +3377,11 @@ type CreateEmergencyRequest struct {
 
 // CreateEmergency handles emergency creation
 func (api *HomeRescueAPI) CreateEmergency(ctx context.Context, userID uuid.UUID, req CreateEmergencyRequest) (*EmergencyRequest, error) {
+	contactPhone, err := phone.Normalize(req.ContactPhone)
+	if err != nil {
+		return nil, fmt.Errorf("contact phone: %w", err)
+	}
+
 	// Determine urgency based on category and description
 	urgency := api.determineUrgency(req.Category, req.Description)
 	
@@ -1246,10 +3390,12 @@ func (api *HomeRescueAPI) CreateEmergency(ctx context.Context, userID uuid.UUID,
 		UserID:             userID,
 		Category:           req.Category,
 		Subcategory:        req.Subcategory,
+		IntakeAnswers:      req.IntakeAnswers,
 		Urgency:            urgency,
 		Description:        req.Description,
 		Location:           req.Location,
 		AccessInstructions: req.AccessInstructions,
+		ContactPhone:       contactPhone,
 		Status:             StatusNew,
 		StatusHistory: []StatusUpdate{
 			{Status: StatusNew, Timestamp: time.Now(), UpdatedBy: "customer"},
@@ -1323,23 +3469,24 @@ func (api *HomeRescueAPI) saveEmergency(ctx context.Context, e *EmergencyRequest
 	photosJSON, _ := json.Marshal(e.Photos)
 	historyJSON, _ := json.Marshal(e.StatusHistory)
 	locationJSON, _ := json.Marshal(e.Location)
+	intakeJSON, _ := json.Marshal(e.IntakeAnswers)
 	
 	query := `
 		INSERT INTO emergency_requests (
 			id, user_id, category, subcategory, urgency,
-			title, description, photos, location, access_instructions,
+			title, description, photos, location, access_instructions, contact_phone,
 			status, status_history,
 			response_deadline, arrival_deadline,
-			payment_status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			payment_status, created_at, updated_at, intake_answers
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 	
 	_, err := api.db.Exec(ctx, query,
 		e.ID, e.UserID, e.Category, e.Subcategory, e.Urgency,
-		e.Title, e.Description, photosJSON, locationJSON, e.AccessInstructions,
+		e.Title, e.Description, photosJSON, locationJSON, e.AccessInstructions, e.ContactPhone,
 		e.Status, historyJSON,
 		e.ResponseDeadline, e.ArrivalDeadline,
-		e.PaymentStatus, e.CreatedAt, e.UpdatedAt,
+		e.PaymentStatus, e.CreatedAt, e.UpdatedAt, intakeJSON,
 	)
 	
 	return err
@@ -1535,7 +3682,6 @@ PRICE GUARANTEE:
 // Placeholder services
 type GeoService struct{}
 type NotificationService struct{}
-type PubSubService struct{}
 
 type TechNotification struct {
 	Type      string
@@ -1556,18 +3702,80 @@ type SupportAlert struct {
 }
 
 type CustomerNotification struct {
-	Type    string
-	Title   string
-	Message string
+	Type      string
+	RequestID uuid.UUID
+	Title     string
+	Message   string
 }
 
 func (n *NotificationService) NotifyTechnician(ctx context.Context, techID uuid.UUID, notification *TechNotification) {}
 func (n *NotificationService) NotifySupport(ctx context.Context, alert *SupportAlert) {}
 func (n *NotificationService) NotifyCustomer(ctx context.Context, userID uuid.UUID, notification *CustomerNotification) {}
 
-func (p *PubSubService) Publish(ctx context.Context, channel string, data interface{}) {}
+// ErrPubSubUnavailable is returned when subscribing without Redis
+var ErrPubSubUnavailable = errors.New("pubsub unavailable")
+
+// PubSubService publishes tracking updates over Redis pub/sub. Without a
+// client publishing is a no-op and subscribing fails.
+type PubSubService struct {
+	client *redis.Client
+}
+
+// NewPubSubService creates a pub/sub service on the given Redis client
+func NewPubSubService(client *redis.Client) *PubSubService {
+	return &PubSubService{client: client}
+}
+
+// Publish sends data, encoded as JSON, to the channel's subscribers
+func (p *PubSubService) Publish(ctx context.Context, channel string, data interface{}) {
+	if p.client == nil {
+		return
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	p.client.Publish(ctx, channel, payload)
+}
+
+// Subscribe streams the tracking updates published on channel until ctx
+// ends, when the subscription is closed along with the returned channel
 func (p *PubSubService) Subscribe(ctx context.Context, channel string) (<-chan TrackingUpdate, error) {
-	return nil, nil
+	if p.client == nil {
+		return nil, ErrPubSubUnavailable
+	}
+	sub := p.client.Subscribe(ctx, channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	
+	updates := make(chan TrackingUpdate)
+	go func() {
+		defer close(updates)
+		defer sub.Close()
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var update TrackingUpdate
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					continue
+				}
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return updates, nil
 }
 
 // Helper
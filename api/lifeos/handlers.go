@@ -2,10 +2,14 @@
 package lifeos
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 
 	"github.com/BillyRonksGlobal/vendorplatform/internal/lifeos"
@@ -14,13 +18,15 @@ import (
 // Handler handles LifeOS HTTP requests
 type Handler struct {
 	service *lifeos.Service
+	events  *LifeOSAPI
 	logger  *zap.Logger
 }
 
 // NewHandler creates a new LifeOS handler
-func NewHandler(service *lifeos.Service, logger *zap.Logger) *Handler {
+func NewHandler(service *lifeos.Service, events *LifeOSAPI, logger *zap.Logger) *Handler {
 	return &Handler{
 		service: service,
+		events:  events,
 		logger:  logger,
 	}
 }
@@ -32,6 +38,8 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 		lifeos.POST("/events", h.CreateLifeEvent)
 		lifeos.GET("/events/:id", h.GetLifeEvent)
 		lifeos.GET("/events/:id/plan", h.GetEventPlan)
+		lifeos.GET("/events/:id/plan/versions", h.GetPlanVersions)
+		lifeos.GET("/events/:id/plan/diff", h.GetPlanDiff)
 		lifeos.POST("/events/:id/confirm", h.ConfirmDetectedEvent)
 		lifeos.GET("/detected", h.GetDetectedEvents)
 
@@ -40,6 +48,14 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 		lifeos.GET("/events/:id/bundles", h.GetBundleRecommendations)
 		lifeos.GET("/events/:id/risks", h.AssessEventRisks)
 		lifeos.POST("/events/:id/optimize", h.OptimizeBudgetAllocation)
+
+		// Plan checklist
+		lifeos.GET("/events/:id/tasks", h.GetEventTasks)
+		lifeos.POST("/events/:id/tasks/:taskId/complete", h.CompleteEventTask)
+		lifeos.POST("/events/:id/tasks/:taskId/reopen", h.ReopenEventTask)
+
+		// Budget tracking
+		lifeos.GET("/events/:id/budget", h.GetEventBudget)
 	}
 }
 
@@ -160,6 +176,79 @@ func (h *Handler) GetEventPlan(c *gin.Context) {
 	})
 }
 
+// GetPlanVersions handles GET /api/v1/lifeos/events/:id/plan/versions
+func (h *Handler) GetPlanVersions(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := uuid.Parse(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	versions, err := h.events.ListPlanVersions(c.Request.Context(), eventID)
+	if err != nil {
+		h.logger.Error("Failed to list plan versions",
+			zap.Error(err),
+			zap.String("event_id", eventIDStr),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list plan versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    versions,
+	})
+}
+
+// GetPlanDiff handles GET /api/v1/lifeos/events/:id/plan/diff?from=1&to=2
+func (h *Handler) GetPlanDiff(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := uuid.Parse(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil || from < 1 || to < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from and to must be plan version numbers",
+		})
+		return
+	}
+
+	diff, err := h.events.DiffPlanVersions(c.Request.Context(), eventID, from, to)
+	if err != nil {
+		if errors.Is(err, ErrPlanVersionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Plan version not found",
+			})
+			return
+		}
+		h.logger.Error("Failed to diff plan versions",
+			zap.Error(err),
+			zap.String("event_id", eventIDStr),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to compare plan versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    diff,
+	})
+}
+
 // ConfirmDetectedEvent handles POST /api/v1/lifeos/events/:id/confirm
 func (h *Handler) ConfirmDetectedEvent(c *gin.Context) {
 	eventIDStr := c.Param("id")
@@ -429,3 +518,145 @@ func (h *Handler) OptimizeBudgetAllocation(c *gin.Context) {
 		"data":    optimization,
 	})
 }
+
+// GetEventTasks handles GET /api/v1/lifeos/events/:id/tasks; refresh=true
+// regenerates the plan instead of serving it from cache
+func (h *Handler) GetEventTasks(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := uuid.Parse(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	plan, err := h.events.GetEventPlan(c.Request.Context(), eventID, PlanOptions{
+		ForceRefresh: c.Query("refresh") == "true",
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Life event not found",
+			})
+			return
+		}
+		h.logger.Error("Failed to get event tasks",
+			zap.Error(err),
+			zap.String("event_id", eventIDStr),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get event tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"event_id": plan.EventID,
+			"phases":   plan.Phases,
+		},
+	})
+}
+
+// GetEventBudget handles GET /api/v1/lifeos/events/:id/budget
+func (h *Handler) GetEventBudget(c *gin.Context) {
+	eventIDStr := c.Param("id")
+	eventID, err := uuid.Parse(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	status, err := h.events.GetBudgetStatus(c.Request.Context(), eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Life event not found",
+			})
+			return
+		}
+		h.logger.Error("Failed to get event budget",
+			zap.Error(err),
+			zap.String("event_id", eventIDStr),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get event budget",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// CompleteEventTask handles POST /api/v1/lifeos/events/:id/tasks/:taskId/complete
+func (h *Handler) CompleteEventTask(c *gin.Context) {
+	h.updateEventTask(c, h.events.CompleteTask)
+}
+
+// ReopenEventTask handles POST /api/v1/lifeos/events/:id/tasks/:taskId/reopen
+func (h *Handler) ReopenEventTask(c *gin.Context) {
+	h.updateEventTask(c, h.events.ReopenTask)
+}
+
+func (h *Handler) updateEventTask(c *gin.Context, update func(ctx context.Context, eventID, taskID uuid.UUID) (*TaskProgress, error)) {
+	eventIDStr := c.Param("id")
+	eventID, err := uuid.Parse(eventIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	taskIDStr := c.Param("taskId")
+	taskID, err := uuid.Parse(taskIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	progress, err := update(c.Request.Context(), eventID, taskID)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Life event not found",
+			})
+		case errors.Is(err, ErrTaskNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Task not found",
+			})
+		default:
+			h.logger.Error("Failed to update event task",
+				zap.Error(err),
+				zap.String("event_id", eventIDStr),
+				zap.String("task_id", taskIDStr),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to update task",
+			})
+		}
+		return
+	}
+
+	h.logger.Info("Event task updated",
+		zap.String("event_id", eventIDStr),
+		zap.String("task_id", taskIDStr),
+		zap.String("status", progress.Task.Status),
+		zap.Float64("completion_percentage", progress.CompletionPct),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    progress,
+	})
+}
@@ -9,14 +9,24 @@ package lifeos
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/discount"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/geo"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/tracing"
 )
 
 /*
@@ -94,6 +104,7 @@ type LifeEvent struct {
 	Status            EventStatus            `json:"status"`
 	Phase             EventPhase             `json:"phase"`
 	CompletionPct     float64                `json:"completion_percentage"`
+	PendingActions    []RecommendedAction    `json:"pending_actions,omitempty"` // From the last phase change
 	
 	// Service Graph
 	RequiredServices  []RequiredService      `json:"required_services"`
@@ -277,6 +288,89 @@ type BudgetItem struct {
 	Allocated    float64   `json:"allocated"`
 	Spent        float64   `json:"spent"`
 	Percentage   float64   `json:"percentage"`
+	OverBudget   bool      `json:"over_budget"`
+}
+
+// RecordSpend applies a booked amount to a category in the breakdown and to
+// the budget totals. Categories without an allocation are added on first
+// spend, so any spend against them counts as over budget.
+func (b *Budget) RecordSpend(categoryID uuid.UUID, categoryName string, amount float64) *BudgetItem {
+	var item *BudgetItem
+	for i := range b.Breakdown {
+		if b.Breakdown[i].CategoryID == categoryID {
+			item = &b.Breakdown[i]
+			break
+		}
+	}
+	if item == nil {
+		b.Breakdown = append(b.Breakdown, BudgetItem{
+			CategoryID:   categoryID,
+			CategoryName: categoryName,
+		})
+		item = &b.Breakdown[len(b.Breakdown)-1]
+	}
+
+	item.Spent += amount
+	item.OverBudget = item.Spent > item.Allocated
+	b.Spent += amount
+
+	return item
+}
+
+// ReverseSpend takes an amount recorded by RecordSpend back off a category
+// and the budget totals, as when the booking it was spent on is cancelled.
+// Spend never goes below zero.
+func (b *Budget) ReverseSpend(categoryID uuid.UUID, amount float64) {
+	for i := range b.Breakdown {
+		item := &b.Breakdown[i]
+		if item.CategoryID != categoryID {
+			continue
+		}
+		item.Spent = math.Max(item.Spent-amount, 0)
+		item.OverBudget = item.Spent > item.Allocated
+		break
+	}
+	b.Spent = math.Max(b.Spent-amount, 0)
+}
+
+// IsOverBudget reports whether spend has exceeded the total plus the
+// tolerated flex percentage
+func (b *Budget) IsOverBudget() bool {
+	return b.Spent > b.TotalAmount*(1+b.FlexPercentage/100)
+}
+
+// BudgetStatus summarises spend against an event budget
+type BudgetStatus struct {
+	EventID         uuid.UUID    `json:"event_id"`
+	TotalAmount     float64      `json:"total_amount"`
+	Allocated       float64      `json:"allocated"`
+	Spent           float64      `json:"spent"`
+	Remaining       float64      `json:"remaining"`
+	Currency        string       `json:"currency"`
+	OverBudget      bool         `json:"over_budget"`
+	OverBudgetItems []BudgetItem `json:"over_budget_items"`
+	Breakdown       []BudgetItem `json:"breakdown"`
+}
+
+// Status builds a BudgetStatus snapshot for the given event
+func (b *Budget) Status(eventID uuid.UUID) *BudgetStatus {
+	status := &BudgetStatus{
+		EventID:         eventID,
+		TotalAmount:     b.TotalAmount,
+		Allocated:       b.Allocated,
+		Spent:           b.Spent,
+		Remaining:       b.TotalAmount - b.Spent,
+		Currency:        b.Currency,
+		OverBudget:      b.IsOverBudget(),
+		OverBudgetItems: make([]BudgetItem, 0),
+		Breakdown:       b.Breakdown,
+	}
+	for _, item := range b.Breakdown {
+		if item.OverBudget {
+			status.OverBudgetItems = append(status.OverBudgetItems, item)
+		}
+	}
+	return status
 }
 
 type PriceRange struct {
@@ -331,10 +425,54 @@ type DetectionConfig struct {
 	MinConfidenceThreshold float64
 	SignalWindowDays       int
 	EnableMLPrediction     bool
+	MLBlendWeight          float64 // share of a probability taken from the model where it has weights
 	EnableCalendarSync     bool
 	EnablePartnerData      bool
 }
 
+// DefaultDetectionConfig is the detection configuration used by the API
+func DefaultDetectionConfig() *DetectionConfig {
+	return &DetectionConfig{
+		MinConfidenceThreshold: 0.6,
+		SignalWindowDays:       90,
+		MLBlendWeight:          0.5,
+	}
+}
+
+// NewEventDetectionEngine creates a detection engine with the signal
+// processors and predictor the config enables
+func NewEventDetectionEngine(db *pgxpool.Pool, cache *redis.Client, config *DetectionConfig) *EventDetectionEngine {
+	if config == nil {
+		config = DefaultDetectionConfig()
+	}
+	e := &EventDetectionEngine{
+		db:    db,
+		cache: cache,
+		signalProcessors: map[DetectionMethod]SignalProcessor{
+			DetectionBehavioral: &BehavioralSignalProcessor{db: db},
+		},
+		config: config,
+	}
+	if config.EnableCalendarSync {
+		e.signalProcessors[DetectionCalendar] = &CalendarSignalProcessor{db: db}
+	}
+	if config.EnableMLPrediction {
+		e.mlPredictor = NewMLEventPredictor(db)
+	}
+	return e
+}
+
+// SetSignalProcessor registers the processor for a detection method,
+// replacing any already registered
+func (e *EventDetectionEngine) SetSignalProcessor(method DetectionMethod, processor SignalProcessor) {
+	e.signalProcessors[method] = processor
+}
+
+// SetMLPredictor replaces the model consulted when ML prediction is enabled
+func (e *EventDetectionEngine) SetMLPredictor(predictor *MLEventPredictor) {
+	e.mlPredictor = predictor
+}
+
 // SignalProcessor processes specific types of detection signals
 type SignalProcessor interface {
 	ProcessSignals(ctx context.Context, userID uuid.UUID, window time.Duration) ([]DetectionSignal, error)
@@ -555,16 +693,8 @@ func (p *BehavioralSignalProcessor) GetEventProbabilities(signals []DetectionSig
 	eventScores := make(map[EventType]float64)
 	eventCounts := make(map[EventType]int)
 	
-	clusterToEvents := map[string][]EventType{
-		"celebrations": {EventTypeWedding, EventTypeBirthday, EventTypeGraduation},
-		"home":         {EventTypeRelocation, EventTypeRenovation},
-		"travel":       {EventTypeTravel},
-		"health":       {EventTypeChildbirth},
-		"business":     {EventTypeBusinessLaunch},
-	}
-	
 	for _, signal := range signals {
-		if events, ok := clusterToEvents[signal.Value]; ok {
+		if events, ok := clusterEvents[signal.Value]; ok {
 			for _, event := range events {
 				eventScores[event] += signal.Confidence
 				eventCounts[event]++
@@ -587,36 +717,223 @@ func (p *BehavioralSignalProcessor) GetEventProbabilities(signals []DetectionSig
 	return probabilities
 }
 
-// DetectEvents is the main detection entry point
-func (e *EventDetectionEngine) DetectEvents(ctx context.Context, userID uuid.UUID) ([]LifeEvent, error) {
+// CalendarSignalProcessor finds upcoming events in synced calendars
+type CalendarSignalProcessor struct {
+	db *pgxpool.Pool
+}
+
+// CalendarEntry is an event from a user's synced calendar
+type CalendarEntry struct {
+	Title         string
+	StartTime     time.Time
+	AttendeeCount int
+}
+
+// calendarKeywords recognise event titles, most specific first
+var calendarKeywords = []struct {
+	keyword string
+	event   EventType
+}{
+	{"baby shower", EventTypeChildbirth},
+	{"naming ceremony", EventTypeChildbirth},
+	{"wedding", EventTypeWedding},
+	{"traditional marriage", EventTypeWedding},
+	{"move out", EventTypeRelocation},
+	{"moving day", EventTypeRelocation},
+	{"house move", EventTypeRelocation},
+	{"renovation", EventTypeRenovation},
+	{"graduation", EventTypeGraduation},
+	{"convocation", EventTypeGraduation},
+	{"birthday", EventTypeBirthday},
+	{"funeral", EventTypeFuneral},
+	{"burial", EventTypeFuneral},
+	{"retirement", EventTypeRetirement},
+	{"grand opening", EventTypeBusinessLaunch},
+	{"business launch", EventTypeBusinessLaunch},
+}
+
+// calendarHorizon is how far ahead calendar entries count; confidence falls
+// off linearly to half at the horizon since far-off plans change
+const calendarHorizon = 365 * 24 * time.Hour
+
+// CalendarEventType recognises the life event a calendar title refers to
+func CalendarEventType(title string) (EventType, bool) {
+	title = strings.ToLower(title)
+	for _, k := range calendarKeywords {
+		if strings.Contains(title, k.keyword) {
+			return k.event, true
+		}
+	}
+	return "", false
+}
+
+// CalendarSignals turns recognisable upcoming calendar entries into signals.
+// Entries already past, beyond the horizon or with unrecognised titles are
+// ignored.
+func CalendarSignals(entries []CalendarEntry, now time.Time) []DetectionSignal {
+	var signals []DetectionSignal
+	for _, entry := range entries {
+		until := entry.StartTime.Sub(now)
+		if until <= 0 || until > calendarHorizon {
+			continue
+		}
+		event, ok := CalendarEventType(entry.Title)
+		if !ok {
+			continue
+		}
+		
+		// Invited guests make it more likely a real event than a reminder
+		base := 0.8
+		if entry.AttendeeCount >= 10 {
+			base = 0.9
+		}
+		confidence := base * (1 - 0.5*float64(until)/float64(calendarHorizon))
+		
+		signals = append(signals, DetectionSignal{
+			SignalType: "calendar_event",
+			Source:     "user_calendar_events",
+			Value:      string(event),
+			Confidence: confidence,
+			Timestamp:  entry.StartTime,
+		})
+	}
+	return signals
+}
+
+func (p *CalendarSignalProcessor) ProcessSignals(ctx context.Context, userID uuid.UUID, window time.Duration) ([]DetectionSignal, error) {
+	rows, err := p.db.Query(ctx, `
+		SELECT title, start_time, COALESCE(attendee_count, 0)
+		FROM user_calendar_events
+		WHERE user_id = $1
+		  AND start_time > NOW()
+		  AND start_time < NOW() + $2::interval
+		ORDER BY start_time
+	`, userID, fmt.Sprintf("%d days", int(calendarHorizon.Hours()/24)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var entries []CalendarEntry
+	for rows.Next() {
+		var entry CalendarEntry
+		if err := rows.Scan(&entry.Title, &entry.StartTime, &entry.AttendeeCount); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	
+	return CalendarSignals(entries, time.Now()), nil
+}
+
+// GetEventProbabilities takes the strongest calendar signal for each event
+// type, boosted 10% for each further entry pointing at it
+func (p *CalendarSignalProcessor) GetEventProbabilities(signals []DetectionSignal) map[EventType]float64 {
+	best := make(map[EventType]float64)
+	counts := make(map[EventType]int)
+	for _, signal := range signals {
+		if signal.SignalType != "calendar_event" {
+			continue
+		}
+		event := EventType(signal.Value)
+		if signal.Confidence > best[event] {
+			best[event] = signal.Confidence
+		}
+		counts[event]++
+	}
+	
+	probabilities := make(map[EventType]float64, len(best))
+	for event, confidence := range best {
+		probabilities[event] = math.Min(1, confidence*(1+float64(counts[event]-1)*0.1))
+	}
+	return probabilities
+}
+
+// ProcessorFailure is a signal processor that returned no signals during
+// detection
+type ProcessorFailure struct {
+	Method   DetectionMethod `json:"method"`
+	Error    string          `json:"error"`
+	TimedOut bool            `json:"timed_out"`
+}
+
+// DetectionDiagnostics reports which signal processors contributed to a
+// detection run
+type DetectionDiagnostics struct {
+	Succeeded []DetectionMethod  `json:"succeeded"`
+	Failed    []ProcessorFailure `json:"failed,omitempty"`
+}
+
+// Partial reports whether events were detected without every processor
+func (d DetectionDiagnostics) Partial() bool {
+	return len(d.Failed) > 0
+}
+
+// DetectEvents is the main detection entry point. Processors run
+// concurrently under ctx; one that fails or is still running when ctx ends
+// is recorded in the diagnostics and detection goes on with the signals the
+// others returned.
+func (e *EventDetectionEngine) DetectEvents(ctx context.Context, userID uuid.UUID) ([]LifeEvent, DetectionDiagnostics, error) {
 	window := time.Duration(e.config.SignalWindowDays) * 24 * time.Hour
 	
 	// Collect signals from all processors
-	var allSignals []DetectionSignal
+	type outcome struct {
+		signals []DetectionSignal
+		err     error
+	}
+	outcomes := make(map[DetectionMethod]outcome, len(e.signalProcessors))
 	var mu sync.Mutex
-	var wg sync.WaitGroup
+	g, gctx := errgroup.WithContext(ctx)
 	
 	for method, processor := range e.signalProcessors {
-		wg.Add(1)
-		go func(m DetectionMethod, p SignalProcessor) {
-			defer wg.Done()
-			signals, err := p.ProcessSignals(ctx, userID, window)
-			if err != nil {
-				return
-			}
+		method, processor := method, processor
+		g.Go(func() error {
+			signals, err := processSignals(gctx, processor, userID, window)
 			mu.Lock()
-			allSignals = append(allSignals, signals...)
+			outcomes[method] = outcome{signals: signals, err: err}
 			mu.Unlock()
-		}(method, processor)
+			// A failing processor must not cancel the others
+			return nil
+		})
+	}
+	g.Wait()
+	
+	methods := make([]DetectionMethod, 0, len(outcomes))
+	for method := range outcomes {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i] < methods[j] })
+	
+	var diagnostics DetectionDiagnostics
+	var allSignals []DetectionSignal
+	var errs []error
+	for _, method := range methods {
+		result := outcomes[method]
+		if result.err != nil {
+			diagnostics.Failed = append(diagnostics.Failed, ProcessorFailure{
+				Method:   method,
+				Error:    result.err.Error(),
+				TimedOut: errors.Is(result.err, context.DeadlineExceeded),
+			})
+			errs = append(errs, fmt.Errorf("%s: %w", method, result.err))
+			continue
+		}
+		diagnostics.Succeeded = append(diagnostics.Succeeded, method)
+		allSignals = append(allSignals, result.signals...)
 	}
-	wg.Wait()
 	
+	if len(diagnostics.Succeeded) == 0 && len(errs) > 0 {
+		return nil, diagnostics, fmt.Errorf("all signal processors failed: %w", errors.Join(errs...))
+	}
 	if len(allSignals) == 0 {
-		return nil, nil
+		return nil, diagnostics, nil
 	}
 	
 	// Get event probabilities
-	probabilities := e.aggregateProbabilities(allSignals)
+	probabilities := e.aggregateProbabilities(ctx, allSignals)
 	
 	// Create life events for high-confidence detections
 	var events []LifeEvent
@@ -632,10 +949,31 @@ func (e *EventDetectionEngine) DetectEvents(ctx context.Context, userID uuid.UUI
 		return events[i].DetectionConfidence > events[j].DetectionConfidence
 	})
 	
-	return events, nil
+	return events, diagnostics, nil
+}
+
+// processSignals runs a processor, giving up when ctx ends even if the
+// processor doesn't watch ctx itself
+func processSignals(ctx context.Context, processor SignalProcessor, userID uuid.UUID, window time.Duration) ([]DetectionSignal, error) {
+	type result struct {
+		signals []DetectionSignal
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		signals, err := processor.ProcessSignals(ctx, userID, window)
+		done <- result{signals: signals, err: err}
+	}()
+	
+	select {
+	case r := <-done:
+		return r.signals, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
-func (e *EventDetectionEngine) aggregateProbabilities(signals []DetectionSignal) map[EventType]float64 {
+func (e *EventDetectionEngine) aggregateProbabilities(ctx context.Context, signals []DetectionSignal) map[EventType]float64 {
 	// Use ensemble of processor probabilities
 	combined := make(map[EventType]float64)
 	counts := make(map[EventType]int)
@@ -653,7 +991,15 @@ func (e *EventDetectionEngine) aggregateProbabilities(signals []DetectionSignal)
 		combined[event] /= float64(counts[event])
 	}
 	
-	return combined
+	if !e.config.EnableMLPrediction || e.mlPredictor == nil {
+		return combined
+	}
+	predicted, err := e.mlPredictor.Predict(ctx, signals)
+	if err != nil {
+		// The heuristic alone still detects events
+		return combined
+	}
+	return BlendPredictions(combined, predicted, e.config.MLBlendWeight)
 }
 
 func (e *EventDetectionEngine) createDetectedEvent(userID uuid.UUID, eventType EventType, confidence float64, signals []DetectionSignal) LifeEvent {
@@ -697,6 +1043,172 @@ func (e *EventDetectionEngine) getClusterForEvent(eventType EventType) ClusterTy
 	return mapping[eventType]
 }
 
+// clusterEvents maps a service cluster seen in browse and interaction
+// signals to the events it suggests
+var clusterEvents = map[string][]EventType{
+	"celebrations": {EventTypeWedding, EventTypeBirthday, EventTypeGraduation},
+	"home":         {EventTypeRelocation, EventTypeRenovation},
+	"travel":       {EventTypeTravel},
+	"health":       {EventTypeChildbirth},
+	"business":     {EventTypeBusinessLaunch},
+}
+
+// EventWeights are the logistic regression weights for one event type
+type EventWeights struct {
+	Bias         float64 `json:"bias"`
+	Recency      float64 `json:"recency"`
+	Frequency    float64 `json:"frequency"`
+	ClusterCount float64 `json:"cluster_count"`
+}
+
+// EventFeatures summarise the signals pointing at one event type
+type EventFeatures struct {
+	Recency      float64 `json:"recency"`       // 1 for a signal now, halving every two weeks
+	Frequency    float64 `json:"frequency"`     // signals naming the event type directly
+	ClusterCount float64 `json:"cluster_count"` // signals for a cluster the event belongs to
+}
+
+// recencyHalfLife is how long it takes a signal's recency to halve
+const recencyHalfLife = 14 * 24 * time.Hour
+
+// SignalFeatures groups signals by the event types they point at. Search
+// signals name an event type; browse and interaction signals name a cluster
+// and count towards every event in it.
+func SignalFeatures(signals []DetectionSignal, now time.Time) map[EventType]EventFeatures {
+	features := make(map[EventType]EventFeatures)
+	add := func(event EventType, direct bool, at time.Time) {
+		f := features[event]
+		if direct {
+			f.Frequency++
+		} else {
+			f.ClusterCount++
+		}
+		age := now.Sub(at)
+		if age < 0 {
+			age = 0
+		}
+		if r := math.Pow(0.5, float64(age)/float64(recencyHalfLife)); r > f.Recency {
+			f.Recency = r
+		}
+		features[event] = f
+	}
+	
+	for _, signal := range signals {
+		if events, ok := clusterEvents[signal.Value]; ok {
+			for _, event := range events {
+				add(event, false, signal.Timestamp)
+			}
+			continue
+		}
+		if signal.Value != "" {
+			add(EventType(signal.Value), true, signal.Timestamp)
+		}
+	}
+	return features
+}
+
+// Score is the model's probability for an event type with these features
+func (w EventWeights) Score(f EventFeatures) float64 {
+	z := w.Bias + w.Recency*f.Recency + w.Frequency*f.Frequency + w.ClusterCount*f.ClusterCount
+	return 1 / (1 + math.Exp(-z))
+}
+
+// BlendPredictions mixes model probabilities into the heuristic ones, giving
+// the model weight share. Event types the model has no prediction for keep
+// their heuristic probability.
+func BlendPredictions(heuristic, predicted map[EventType]float64, weight float64) map[EventType]float64 {
+	weight = math.Max(0, math.Min(1, weight))
+	blended := make(map[EventType]float64, len(heuristic))
+	for event, p := range heuristic {
+		blended[event] = p
+	}
+	for event, p := range predicted {
+		blended[event] = (1-weight)*heuristic[event] + weight*p
+	}
+	return blended
+}
+
+// MLEventPredictor scores events with per-event-type logistic weights kept
+// in ml_event_weights
+type MLEventPredictor struct {
+	db       *pgxpool.Pool
+	ttl      time.Duration
+	
+	mu       sync.RWMutex
+	weights  map[EventType]EventWeights
+	loadedAt time.Time
+}
+
+// NewMLEventPredictor creates a predictor that reloads its weights every
+// ten minutes
+func NewMLEventPredictor(db *pgxpool.Pool) *MLEventPredictor {
+	return &MLEventPredictor{db: db, ttl: 10 * time.Minute}
+}
+
+// SetWeights replaces the model weights; with no database they are never
+// reloaded
+func (p *MLEventPredictor) SetWeights(weights map[EventType]EventWeights) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.weights = weights
+	p.loadedAt = time.Now()
+}
+
+// Predict scores each event type the signals point at and the model has
+// weights for. Event types without weights are left out, so callers fall
+// back to the heuristic for them.
+func (p *MLEventPredictor) Predict(ctx context.Context, signals []DetectionSignal) (map[EventType]float64, error) {
+	weights, err := p.currentWeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	predictions := make(map[EventType]float64)
+	for event, features := range SignalFeatures(signals, time.Now()) {
+		if w, ok := weights[event]; ok {
+			predictions[event] = w.Score(features)
+		}
+	}
+	return predictions, nil
+}
+
+func (p *MLEventPredictor) currentWeights(ctx context.Context) (map[EventType]EventWeights, error) {
+	p.mu.RLock()
+	weights, loadedAt := p.weights, p.loadedAt
+	p.mu.RUnlock()
+	if p.db == nil || (weights != nil && time.Since(loadedAt) < p.ttl) {
+		return weights, nil
+	}
+	
+	rows, err := p.db.Query(ctx, `
+		SELECT event_type, bias, recency_weight, frequency_weight, cluster_weight
+		FROM ml_event_weights
+	`)
+	if err != nil {
+		if weights != nil {
+			return weights, nil // keep the last good weights
+		}
+		return nil, fmt.Errorf("failed to load event weights: %w", err)
+	}
+	defer rows.Close()
+	
+	loaded := make(map[EventType]EventWeights)
+	for rows.Next() {
+		var event EventType
+		var w EventWeights
+		if err := rows.Scan(&event, &w.Bias, &w.Recency, &w.Frequency, &w.ClusterCount); err != nil {
+			return nil, fmt.Errorf("failed to read event weights: %w", err)
+		}
+		loaded[event] = w
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event weights: %w", err)
+	}
+	
+	p.SetWeights(loaded)
+	return loaded, nil
+}
+
 // =============================================================================
 // 2.3 ORCHESTRATION ENGINE
 // =============================================================================
@@ -710,6 +1222,9 @@ type OrchestrationEngine struct {
 	notificationSvc   *NotificationService
 	pricingEngine     *PricingEngine
 	scheduler         *EventScheduler
+	flexibleWindowPct float64
+	planCacheTTL      time.Duration
+	lifecycle         *EventLifecycle
 }
 
 // EventOrchestrationPlan represents the full plan for an event
@@ -743,6 +1258,8 @@ type PhasePlan struct {
 	Tasks            []PhaseTask              `json:"tasks"`
 	Dependencies     []uuid.UUID              `json:"dependencies"`
 	Status           string                   `json:"status"`
+	CompletionPct    float64                  `json:"completion_percentage"`
+	IsEstimated      bool                     `json:"is_estimated"`
 }
 
 type PhaseTask struct {
@@ -763,6 +1280,7 @@ type CriticalMilestone struct {
 	ServiceID        *uuid.UUID               `json:"service_id,omitempty"`
 	IsMet            bool                     `json:"is_met"`
 	BlocksEvent      bool                     `json:"blocks_event"`
+	IsEstimated      bool                     `json:"is_estimated"` // event date is not set yet
 }
 
 type PlannedService struct {
@@ -785,6 +1303,22 @@ type BundleOption struct {
 	TotalPrice       float64                  `json:"total_price"`
 	Savings          float64                  `json:"savings"`
 	SavingsPercent   float64                  `json:"savings_percent"`
+	
+	// Vendors chosen for the event date and optional categories left out
+	// because no assigned vendor is free then
+	Vendors               []BundleVendor `json:"vendors,omitempty"`
+	UnavailableCategories []uuid.UUID    `json:"unavailable_categories,omitempty"`
+}
+
+// BundleVendor is a vendor assigned to one category of a bundle
+type BundleVendor struct {
+	VendorID       uuid.UUID  `json:"vendor_id"`
+	CategoryID     uuid.UUID  `json:"category_id"`
+	BundlePrice    float64    `json:"bundle_price"`
+	IsRequired     bool       `json:"is_required"`
+	IsDefault      bool       `json:"is_default"`
+	DisplayOrder   int        `json:"-"`
+	SubstitutedFor *uuid.UUID `json:"substituted_for,omitempty"` // default vendor replaced for availability
 }
 
 type BudgetPlan struct {
@@ -845,13 +1379,46 @@ type VendorRecommendation struct {
 	MatchReasons     []string                 `json:"match_reasons"`
 	Availability     string                   `json:"availability"` // 'available', 'limited', 'unavailable'
 	ResponseTime     string                   `json:"response_time"`
+	DistanceKM       *float64                 `json:"distance_km,omitempty"` // from the event location, when both are known
+	Tags             []string                 `json:"tags,omitempty"`
 }
 
-// GeneratePlan creates a comprehensive orchestration plan for an event
-func (o *OrchestrationEngine) GeneratePlan(ctx context.Context, event *LifeEvent) (*EventOrchestrationPlan, error) {
-	plan := &EventOrchestrationPlan{
-		EventID:     event.ID,
-		GeneratedAt: time.Now(),
+// PlanOptions adjusts how a plan is produced
+type PlanOptions struct {
+	// ForceRefresh regenerates the plan even when a cached copy exists
+	ForceRefresh bool
+}
+
+// DefaultPlanCacheTTL is how long a generated plan is served from cache
+const DefaultPlanCacheTTL = 15 * time.Minute
+
+// NewOrchestrationEngine creates an orchestration engine. Plans are cached
+// in Redis when cache is set.
+func NewOrchestrationEngine(db *pgxpool.Pool, cache *redis.Client) *OrchestrationEngine {
+	return &OrchestrationEngine{
+		db:           db,
+		cache:        cache,
+		planCacheTTL: DefaultPlanCacheTTL,
+	}
+}
+
+// GeneratePlan creates a comprehensive orchestration plan for an event. The
+// plan is cached per event and last update, so any change to the event
+// yields a fresh plan.
+func (o *OrchestrationEngine) GeneratePlan(ctx context.Context, event *LifeEvent, opts PlanOptions) (_ *EventOrchestrationPlan, err error) {
+	ctx, span := tracing.Start(ctx, "lifeos.GeneratePlan",
+		attribute.String("event.id", event.ID.String()),
+		attribute.String("event.type", string(event.EventType)),
+		attribute.String("event.phase", string(event.Phase)),
+		attribute.Bool("plan.force_refresh", opts.ForceRefresh),
+	)
+	defer func() { tracing.End(span, err) }()
+	
+	if !opts.ForceRefresh {
+		if plan, ok := o.cachedPlan(ctx, event); ok {
+			span.SetAttributes(attribute.Bool("plan.cached", true))
+			return plan, nil
+		}
 	}
 	
 	// 1. Generate service requirements
@@ -859,42 +1426,109 @@ func (o *OrchestrationEngine) GeneratePlan(ctx context.Context, event *LifeEvent
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate service requirements: %w", err)
 	}
-	plan.ServicePlan = services
 	
 	// 2. Generate timeline and phases
-	phases, milestones, err := o.generateTimeline(ctx, event, services)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate timeline: %w", err)
-	}
-	plan.Phases = phases
-	plan.CriticalPath = milestones
-	
-	// 3. Generate budget plan
-	budgetPlan, err := o.generateBudgetPlan(ctx, event, services)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate budget plan: %w", err)
-	}
-	plan.BudgetPlan = budgetPlan
+	phases, milestones := o.GenerateTimeline(event, services, time.Now())
 	
-	// 4. Find bundle opportunities
+	// 3. Find bundle opportunities
 	bundles, err := o.findBundleOpportunities(ctx, event, services)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find bundles: %w", err)
 	}
-	plan.SuggestedBundles = bundles
 	
-	// 5. Assess risks
-	risks := o.assessRisks(event, plan)
-	plan.Risks = risks
+	// 4. Budget, risks and next actions
+	plan := o.AssemblePlan(event, services, phases, milestones, bundles)
 	
-	// 6. Generate next actions
-	actions := o.generateNextActions(event, plan)
-	plan.NextActions = actions
+	span.SetAttributes(
+		attribute.Int("services.count", len(plan.ServicePlan)),
+		attribute.Int("phases.count", len(plan.Phases)),
+		attribute.Int("bundles.count", len(plan.SuggestedBundles)),
+		attribute.Int("risks.count", len(plan.Risks)),
+		attribute.Bool("plan.cached", false),
+	)
 	
+	o.cachePlan(ctx, event, plan)
 	return plan, nil
 }
 
+// planCacheKey identifies a plan by event and the event's last update
+func planCacheKey(eventID uuid.UUID, updatedAt time.Time) string {
+	return fmt.Sprintf("lifeos:plan:%s:%d", eventID, updatedAt.Unix())
+}
+
+func (o *OrchestrationEngine) cachedPlan(ctx context.Context, event *LifeEvent) (*EventOrchestrationPlan, bool) {
+	if o.cache == nil {
+		return nil, false
+	}
+	data, err := o.cache.Get(ctx, planCacheKey(event.ID, event.UpdatedAt)).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	var plan EventOrchestrationPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, false
+	}
+	return &plan, true
+}
+
+// cachePlan stores a plan on a best-effort basis; a failed write only
+// means the next request regenerates it
+func (o *OrchestrationEngine) cachePlan(ctx context.Context, event *LifeEvent, plan *EventOrchestrationPlan) {
+	if o.cache == nil {
+		return
+	}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return
+	}
+	ttl := o.planCacheTTL
+	if ttl <= 0 {
+		ttl = DefaultPlanCacheTTL
+	}
+	_ = o.cache.Set(ctx, planCacheKey(event.ID, event.UpdatedAt), data, ttl).Err()
+}
+
+// InvalidatePlans drops every cached plan for an event
+func (o *OrchestrationEngine) InvalidatePlans(ctx context.Context, eventID uuid.UUID) error {
+	if o.cache == nil {
+		return nil
+	}
+	iter := o.cache.Scan(ctx, 0, fmt.Sprintf("lifeos:plan:%s:*", eventID), 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return o.cache.Del(ctx, keys...).Err()
+}
+
+// AssemblePlan builds the parts of a plan derived from the event and its
+// gathered services: the budget plan, risks and next actions
+func (o *OrchestrationEngine) AssemblePlan(event *LifeEvent, services []PlannedService, phases []PhasePlan, milestones []CriticalMilestone, bundles []BundleOption) *EventOrchestrationPlan {
+	plan := &EventOrchestrationPlan{
+		EventID:          event.ID,
+		Phases:           phases,
+		CriticalPath:     milestones,
+		ServicePlan:      services,
+		SuggestedBundles: bundles,
+		GeneratedAt:      time.Now(),
+	}
+	plan.BudgetPlan = o.generateBudgetPlan(event, services)
+	plan.Risks = o.assessRisks(event, plan)
+	plan.NextActions = o.generateNextActions(event, plan)
+	return plan
+}
+
 func (o *OrchestrationEngine) generateServiceRequirements(ctx context.Context, event *LifeEvent) ([]PlannedService, error) {
+	if o.db == nil {
+		return nil, nil
+	}
+	
 	// Get required categories for this event type
 	query := `
 		SELECT 
@@ -946,10 +1580,9 @@ func (o *OrchestrationEngine) generateServiceRequirements(ctx context.Context, e
 		s.Phase = EventPhase(phase)
 		s.BudgetAllocation = budgetPct
 		
-		// Calculate book-by date
-		if event.EventDate != nil {
-			s.BookByDate = event.EventDate.AddDate(0, 0, -bookingOffset)
-		}
+		// Calculate book-by date, against an estimated date for open events
+		eventDate, _ := TimelineAnchor(event, time.Now())
+		s.BookByDate = eventDate.AddDate(0, 0, -bookingOffset)
 		
 		// Get price estimates
 		s.EstimatedCost = o.estimateServiceCost(ctx, s.CategoryID, event)
@@ -997,6 +1630,7 @@ func (o *OrchestrationEngine) estimateServiceCost(ctx context.Context, categoryI
 }
 
 func (o *OrchestrationEngine) getVendorRecommendations(ctx context.Context, categoryID uuid.UUID, event *LifeEvent, limit int) []VendorRecommendation {
+	// Fetch extra candidates so filtering by constraints still fills the limit
 	query := `
 		SELECT 
 			v.id as vendor_id,
@@ -1006,7 +1640,10 @@ func (o *OrchestrationEngine) getVendorRecommendations(ctx context.Context, cate
 			v.rating_average,
 			v.rating_count,
 			s.base_price,
-			v.response_time_minutes
+			v.response_time_minutes,
+			COALESCE(s.tags, '{}'),
+			v.service_latitude,
+			v.service_longitude
 		FROM services s
 		JOIN vendors v ON v.id = s.vendor_id
 		WHERE s.category_id = $1
@@ -1017,35 +1654,58 @@ func (o *OrchestrationEngine) getVendorRecommendations(ctx context.Context, cate
 		LIMIT $2
 	`
 	
-	rows, err := o.db.Query(ctx, query, categoryID, limit)
+	rows, err := o.db.Query(ctx, query, categoryID, limit*3)
 	if err != nil {
 		return nil
 	}
 	defer rows.Close()
 	
 	var recs []VendorRecommendation
+	var vendorIDs []uuid.UUID
 	for rows.Next() {
 		var r VendorRecommendation
 		var responseMinutes int
-		
+		var lat, lng *float64
+	
 		if err := rows.Scan(&r.VendorID, &r.VendorName, &r.ServiceID, &r.ServiceName,
-			&r.Rating, &r.ReviewCount, &r.Price, &responseMinutes); err != nil {
+			&r.Rating, &r.ReviewCount, &r.Price, &responseMinutes, &r.Tags, &lat, &lng); err != nil {
 			continue
 		}
-		
+	
+		if lat != nil && lng != nil && event.Location != nil && (event.Location.Latitude != 0 || event.Location.Longitude != 0) {
+			distance := geo.Distance(
+				geo.GeoPoint{Latitude: event.Location.Latitude, Longitude: event.Location.Longitude},
+				geo.GeoPoint{Latitude: *lat, Longitude: *lng},
+			)
+			r.DistanceKM = &distance
+		}
+	
 		// Calculate match score
 		r.MatchScore = o.calculateVendorMatchScore(r, event)
 		r.MatchReasons = o.getMatchReasons(r, event)
-		r.Availability = "available" // Would check actual availability
+		r.Availability = "available"
 		r.ResponseTime = fmt.Sprintf("~%d min", responseMinutes)
-		
+	
 		recs = append(recs, r)
+		vendorIDs = append(vendorIDs, r.VendorID)
 	}
+	rows.Close()
 	
-	// Sort by match score
-	sort.Slice(recs, func(i, j int) bool {
-		return recs[i].MatchScore > recs[j].MatchScore
-	})
+	// Vendors already fully booked on the event date
+	unavailable, err := o.unavailableVendors(ctx, vendorIDs, event.EventDate)
+	if err == nil {
+		for i := range recs {
+			if unavailable[recs[i].VendorID] {
+				recs[i].Availability = "unavailable"
+			}
+		}
+	}
+	
+	// Drop blocked vendors and hard constraint violations, then rank
+	recs = ApplyConstraints(recs, event)
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
 	
 	return recs
 }
@@ -1104,27 +1764,269 @@ func (o *OrchestrationEngine) getMatchReasons(vendor VendorRecommendation, event
 	return reasons
 }
 
-func (o *OrchestrationEngine) generateTimeline(ctx context.Context, event *LifeEvent, services []PlannedService) ([]PhasePlan, []CriticalMilestone, error) {
-	if event.EventDate == nil {
-		return nil, nil, fmt.Errorf("event date required for timeline generation")
+// Constraint penalties and boosts applied to vendor match scores
+const (
+	SoftConstraintPenalty = 0.15 // per soft constraint a vendor violates
+	PreferredVendorBoost  = 0.2
+)
+
+// constraintFields is the vendor field a constraint of each type checks when
+// it doesn't name one
+var constraintFields = map[string]string{
+	"budget":   "price",
+	"date":     "availability",
+	"location": "distance_km",
+	"dietary":  "tags",
+	"rating":   "rating",
+}
+
+// SatisfiedBy reports whether a recommended vendor meets the constraint.
+// Numeric fields compare with eq, neq, gt, gte, lt and lte; text fields with
+// eq, neq, in and nin. For tags, in requires every listed value and nin
+// none of them. A constraint on a field the vendor has no value for, or with
+// an unknown field or operator, is treated as met.
+func (c Constraint) SatisfiedBy(vendor VendorRecommendation) bool {
+	field := c.Field
+	if field == "" {
+		field = constraintFields[c.Type]
 	}
 	
-	eventDate := *event.EventDate
-	
-	// Define phase durations based on event type and planning horizon
-	phases := []PhasePlan{
-		{
-			Phase:     PhaseDiscovery,
-			StartDate: time.Now(),
-			EndDate:   time.Now().AddDate(0, 0, 7),
-			Status:    "completed",
-		},
-		{
-			Phase:     PhasePlanning,
-			StartDate: time.Now().AddDate(0, 0, 7),
-			EndDate:   eventDate.AddDate(0, -3, 0),
-			Status:    "active",
-		},
+	switch field {
+	case "price":
+		return compareNumber(vendor.Price, c.Operator, c.Value)
+	case "rating":
+		return compareNumber(vendor.Rating, c.Operator, c.Value)
+	case "review_count":
+		return compareNumber(float64(vendor.ReviewCount), c.Operator, c.Value)
+	case "distance_km":
+		if vendor.DistanceKM == nil {
+			return true
+		}
+		return compareNumber(*vendor.DistanceKM, c.Operator, c.Value)
+	case "availability":
+		return compareText(vendor.Availability, c.Operator, c.Value)
+	case "tags":
+		return compareTags(vendor.Tags, c.Operator, c.Value)
+	}
+	return true
+}
+
+func compareNumber(actual float64, operator string, value interface{}) bool {
+	want, ok := constraintNumber(value)
+	if !ok {
+		return true
+	}
+	switch operator {
+	case "eq":
+		return actual == want
+	case "neq":
+		return actual != want
+	case "gt":
+		return actual > want
+	case "gte":
+		return actual >= want
+	case "lt":
+		return actual < want
+	case "lte":
+		return actual <= want
+	}
+	return true
+}
+
+func compareText(actual, operator string, value interface{}) bool {
+	switch operator {
+	case "eq":
+		return strings.EqualFold(actual, fmt.Sprint(value))
+	case "neq":
+		return !strings.EqualFold(actual, fmt.Sprint(value))
+	case "in", "nin":
+		found := false
+		for _, v := range constraintList(value) {
+			if strings.EqualFold(actual, v) {
+				found = true
+				break
+			}
+		}
+		return found == (operator == "in")
+	}
+	return true
+}
+
+func compareTags(tags []string, operator string, value interface{}) bool {
+	has := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		has[strings.ToLower(tag)] = true
+	}
+	switch operator {
+	case "eq", "in":
+		for _, v := range constraintList(value) {
+			if !has[strings.ToLower(v)] {
+				return false
+			}
+		}
+	case "neq", "nin":
+		for _, v := range constraintList(value) {
+			if has[strings.ToLower(v)] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// constraintNumber reads a numeric constraint value, as decoded from JSON or
+// set directly
+func constraintNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// constraintList reads a constraint value as a list of strings; a single
+// value is a list of one
+func constraintList(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	}
+	return []string{fmt.Sprint(value)}
+}
+
+// ApplyConstraints filters and re-ranks recommended vendors for an event.
+// Blocked vendors and those violating a hard constraint are dropped; each
+// soft constraint violated costs SoftConstraintPenalty of the match score,
+// and preferred vendors gain PreferredVendorBoost.
+func ApplyConstraints(recs []VendorRecommendation, event *LifeEvent) []VendorRecommendation {
+	prefs := event.Preferences.VendorPrefs
+	blocked := make(map[uuid.UUID]bool, len(prefs.BlockedVendors))
+	for _, id := range prefs.BlockedVendors {
+		blocked[id] = true
+	}
+	preferred := make(map[uuid.UUID]bool, len(prefs.PreferredVendors))
+	for _, id := range prefs.PreferredVendors {
+		preferred[id] = true
+	}
+	
+	kept := make([]VendorRecommendation, 0, len(recs))
+	for _, r := range recs {
+		if blocked[r.VendorID] {
+			continue
+		}
+	
+		violatesHard := false
+		for _, c := range event.Constraints {
+			if c.SatisfiedBy(r) {
+				continue
+			}
+			if c.IsHard {
+				violatesHard = true
+				break
+			}
+			r.MatchScore -= SoftConstraintPenalty
+		}
+		if violatesHard {
+			continue
+		}
+	
+		if preferred[r.VendorID] {
+			r.MatchScore += PreferredVendorBoost
+			r.MatchReasons = append(r.MatchReasons, "Preferred vendor")
+		}
+		r.MatchScore = math.Max(0, r.MatchScore)
+		kept = append(kept, r)
+	}
+	
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].MatchScore > kept[j].MatchScore
+	})
+	return kept
+}
+
+// DefaultFlexibleWindowPct is how much each phase window widens, as a
+// percentage of its length, when the event date is flexible
+const DefaultFlexibleWindowPct = 20.0
+
+// defaultPlanningHorizon is the assumed lead time, in days, for an event
+// with no date and no planning horizon of its own
+var defaultPlanningHorizon = map[EventType]int{
+	EventTypeWedding:        270,
+	EventTypeFuneral:        14,
+	EventTypeBirthday:       60,
+	EventTypeRelocation:     90,
+	EventTypeRenovation:     120,
+	EventTypeChildbirth:     180,
+	EventTypeTravel:         90,
+	EventTypeBusinessLaunch: 120,
+	EventTypeGraduation:     90,
+	EventTypeRetirement:     90,
+}
+
+// fallbackPlanningHorizon covers event types missing from defaultPlanningHorizon
+const fallbackPlanningHorizon = 180
+
+// SetFlexibleWindowPercent sets how much phase windows widen for events with
+// a flexible date; zero restores DefaultFlexibleWindowPct
+func (o *OrchestrationEngine) SetFlexibleWindowPercent(pct float64) {
+	o.flexibleWindowPct = pct
+}
+
+// TimelineAnchor is the date a timeline is planned against. Events with a
+// date use it; open events are anchored PlanningHorizon days after now, or
+// a default lead time for the event type, and the anchor is estimated.
+func TimelineAnchor(event *LifeEvent, now time.Time) (anchor time.Time, estimated bool) {
+	if event.EventDate != nil && event.EventDateFlex != DateOpen {
+		return *event.EventDate, false
+	}
+	
+	horizon := event.PlanningHorizon
+	if horizon <= 0 {
+		horizon = defaultPlanningHorizon[event.EventType]
+	}
+	if horizon <= 0 {
+		horizon = fallbackPlanningHorizon
+	}
+	return now.AddDate(0, 0, horizon), true
+}
+
+// GenerateTimeline lays out the plan phases and critical milestones against
+// the event's timeline anchor. Phases and milestones of an open event are
+// marked as estimated; for a flexible event each phase window is widened.
+func (o *OrchestrationEngine) GenerateTimeline(event *LifeEvent, services []PlannedService, now time.Time) ([]PhasePlan, []CriticalMilestone) {
+	eventDate, estimated := TimelineAnchor(event, now)
+	
+	// Define phase durations based on event type and planning horizon
+	phases := []PhasePlan{
+		{
+			Phase:     PhaseDiscovery,
+			StartDate: now,
+			EndDate:   now.AddDate(0, 0, 7),
+			Status:    "completed",
+		},
+		{
+			Phase:     PhasePlanning,
+			StartDate: now.AddDate(0, 0, 7),
+			EndDate:   eventDate.AddDate(0, -3, 0),
+			Status:    "active",
+		},
 		{
 			Phase:     PhaseVendorSelect,
 			StartDate: eventDate.AddDate(0, -3, 0),
@@ -1157,9 +2059,23 @@ func (o *OrchestrationEngine) generateTimeline(ctx context.Context, event *LifeE
 		},
 	}
 	
-	// Generate tasks for each phase
+	if event.EventDateFlex == DateFlexible && !estimated {
+		pct := o.flexibleWindowPct
+		if pct <= 0 {
+			pct = DefaultFlexibleWindowPct
+		}
+		for i := range phases {
+			widenPhase(&phases[i], pct, now)
+		}
+	}
+	
+	// Generate tasks for each phase, with IDs that survive regeneration
 	for i := range phases {
+		phases[i].IsEstimated = estimated
 		phases[i].Tasks = o.generatePhaseTasks(phases[i].Phase, services, eventDate)
+		for j := range phases[i].Tasks {
+			phases[i].Tasks[j].ID = PlanTaskID(event.ID, phases[i].Phase, phases[i].Tasks[j].Title)
+		}
 	}
 	
 	// Generate critical milestones
@@ -1172,6 +2088,7 @@ func (o *OrchestrationEngine) generateTimeline(ctx context.Context, event *LifeE
 				Date:        svc.BookByDate,
 				IsMet:       svc.Status == "booked",
 				BlocksEvent: true,
+				IsEstimated: estimated,
 			})
 		}
 	}
@@ -1181,7 +2098,21 @@ func (o *OrchestrationEngine) generateTimeline(ctx context.Context, event *LifeE
 		return milestones[i].Date.Before(milestones[j].Date)
 	})
 	
-	return phases, milestones, nil
+	return phases, milestones
+}
+
+// widenPhase stretches a phase window by pct of its length, split evenly
+// either side, without starting before now
+func widenPhase(phase *PhasePlan, pct float64, now time.Time) {
+	margin := time.Duration(float64(phase.EndDate.Sub(phase.StartDate)) * pct / 200)
+	if margin <= 0 {
+		return
+	}
+	phase.StartDate = phase.StartDate.Add(-margin)
+	if phase.StartDate.Before(now) {
+		phase.StartDate = now
+	}
+	phase.EndDate = phase.EndDate.Add(margin)
 }
 
 func (o *OrchestrationEngine) generatePhaseTasks(phase EventPhase, services []PlannedService, eventDate time.Time) []PhaseTask {
@@ -1190,7 +2121,6 @@ func (o *OrchestrationEngine) generatePhaseTasks(phase EventPhase, services []Pl
 	switch phase {
 	case PhaseDiscovery:
 		tasks = append(tasks, PhaseTask{
-			ID:          uuid.New(),
 			Title:       "Confirm event date",
 			Description: "Set the final date for your event",
 			Priority:    "high",
@@ -1198,7 +2128,6 @@ func (o *OrchestrationEngine) generatePhaseTasks(phase EventPhase, services []Pl
 			AssignedTo:  "user",
 		})
 		tasks = append(tasks, PhaseTask{
-			ID:          uuid.New(),
 			Title:       "Set budget",
 			Description: "Define your total budget for the event",
 			Priority:    "high",
@@ -1210,7 +2139,6 @@ func (o *OrchestrationEngine) generatePhaseTasks(phase EventPhase, services []Pl
 		for _, svc := range services {
 			if svc.Priority == PriorityCritical || svc.Priority == PriorityHigh {
 				tasks = append(tasks, PhaseTask{
-					ID:          uuid.New(),
 					Title:       fmt.Sprintf("Research %s options", svc.CategoryName),
 					Description: fmt.Sprintf("Review and shortlist %s vendors", svc.CategoryName),
 					CategoryID:  &svc.CategoryID,
@@ -1225,7 +2153,6 @@ func (o *OrchestrationEngine) generatePhaseTasks(phase EventPhase, services []Pl
 	case PhaseBooking:
 		for _, svc := range services {
 			tasks = append(tasks, PhaseTask{
-				ID:          uuid.New(),
 				Title:       fmt.Sprintf("Book %s", svc.CategoryName),
 				Description: fmt.Sprintf("Confirm booking with selected %s vendor", svc.CategoryName),
 				CategoryID:  &svc.CategoryID,
@@ -1238,7 +2165,6 @@ func (o *OrchestrationEngine) generatePhaseTasks(phase EventPhase, services []Pl
 		
 	case PhasePreEvent:
 		tasks = append(tasks, PhaseTask{
-			ID:          uuid.New(),
 			Title:       "Confirm all vendors",
 			Description: "Call each vendor to confirm details and timing",
 			DueDate:     eventDate.AddDate(0, 0, -3),
@@ -1247,7 +2173,6 @@ func (o *OrchestrationEngine) generatePhaseTasks(phase EventPhase, services []Pl
 			AssignedTo:  "user",
 		})
 		tasks = append(tasks, PhaseTask{
-			ID:          uuid.New(),
 			Title:       "Final payments",
 			Description: "Complete all outstanding vendor payments",
 			DueDate:     eventDate.AddDate(0, 0, -2),
@@ -1260,7 +2185,7 @@ func (o *OrchestrationEngine) generatePhaseTasks(phase EventPhase, services []Pl
 	return tasks
 }
 
-func (o *OrchestrationEngine) generateBudgetPlan(ctx context.Context, event *LifeEvent, services []PlannedService) (BudgetPlan, error) {
+func (o *OrchestrationEngine) generateBudgetPlan(event *LifeEvent, services []PlannedService) BudgetPlan {
 	plan := BudgetPlan{
 		TotalBudget:     0,
 		AllocatedAmount: 0,
@@ -1276,18 +2201,33 @@ func (o *OrchestrationEngine) generateBudgetPlan(ctx context.Context, event *Lif
 		plan.TotalBudget = o.estimateTotalBudget(event)
 	}
 	
+	// Carry recorded spend over from the event budget
+	spentByCategory := make(map[uuid.UUID]float64)
+	if event.Budget != nil {
+		plan.SpentAmount = event.Budget.Spent
+		for _, item := range event.Budget.Breakdown {
+			spentByCategory[item.CategoryID] = item.Spent
+		}
+	}
+	
 	// Allocate budget to categories
 	for _, svc := range services {
 		allocated := plan.TotalBudget * (svc.BudgetAllocation / 100.0)
 		plan.AllocatedAmount += allocated
 		
+		spent := spentByCategory[svc.CategoryID]
+		status := "on_track"
+		if spent > allocated {
+			status = "over_budget"
+		}
+		
 		plan.Categories = append(plan.Categories, CategoryBudget{
 			CategoryID:   svc.CategoryID,
 			CategoryName: svc.CategoryName,
 			Allocated:    allocated,
-			Spent:        0,
+			Spent:        spent,
 			Percentage:   svc.BudgetAllocation,
-			Status:       "on_track",
+			Status:       status,
 		})
 	}
 	
@@ -1301,7 +2241,7 @@ func (o *OrchestrationEngine) generateBudgetPlan(ctx context.Context, event *Lif
 		})
 	}
 	
-	return plan, nil
+	return plan
 }
 
 func (o *OrchestrationEngine) estimateTotalBudget(event *LifeEvent) float64 {
@@ -1331,6 +2271,10 @@ func (o *OrchestrationEngine) estimateTotalBudget(event *LifeEvent) float64 {
 }
 
 func (o *OrchestrationEngine) findBundleOpportunities(ctx context.Context, event *LifeEvent, services []PlannedService) ([]BundleOption, error) {
+	if o.db == nil {
+		return nil, nil
+	}
+	
 	// Get category IDs
 	var categoryIDs []uuid.UUID
 	for _, svc := range services {
@@ -1363,32 +2307,229 @@ func (o *OrchestrationEngine) findBundleOpportunities(ctx context.Context, event
 	var bundles []BundleOption
 	for rows.Next() {
 		var b BundleOption
-		var discountPct float64
+		if err := rows.Scan(&b.BundleID, &b.Name, &b.Description, &b.IncludedServices, &b.SavingsPercent); err != nil {
+			continue
+		}
+		bundles = append(bundles, b)
+	}
+	rows.Close()
+	
+	// Assemble each bundle from vendors free on the event date, dropping
+	// bundles whose required categories can't be covered
+	var available []BundleOption
+	for _, b := range bundles {
+		assignments, err := o.loadBundleVendors(ctx, b.BundleID)
+		if err != nil {
+			return nil, err
+		}
+		vendorIDs := make([]uuid.UUID, 0, len(assignments))
+		for _, a := range assignments {
+			vendorIDs = append(vendorIDs, a.VendorID)
+		}
+		unavailable, err := o.unavailableVendors(ctx, vendorIDs, event.EventDate)
+		if err != nil {
+			return nil, err
+		}
 		
-		if err := rows.Scan(&b.BundleID, &b.Name, &b.Description, &b.IncludedServices, &discountPct); err != nil {
+		vendors, missing, ok := ResolveBundleVendors(assignments, unavailable)
+		if !ok {
 			continue
 		}
+		b.Vendors = vendors
+		b.UnavailableCategories = missing
 		
-		// Calculate pricing
-		b.TotalPrice = o.calculateBundlePrice(ctx, b.BundleID)
-		regularPrice := b.TotalPrice / (1 - discountPct/100)
-		b.Savings = regularPrice - b.TotalPrice
-		b.SavingsPercent = discountPct
+		// Price from the vendors actually assigned, or from category
+		// estimates while the bundle has none, skipping bundles with nothing
+		// to price
+		priced, ok := PriceBundleOption(b, assignments, o.bundleCostEstimator(ctx, event, services))
+		if !ok {
+			continue
+		}
 		
-		bundles = append(bundles, b)
+		available = append(available, priced)
+	}
+	
+	return available, nil
+}
+
+// bundleCostEstimator estimates a category's cost from the planned service
+// for it, or from current service prices for categories not in the plan
+func (o *OrchestrationEngine) bundleCostEstimator(ctx context.Context, event *LifeEvent, services []PlannedService) func(uuid.UUID) PriceRange {
+	planned := make(map[uuid.UUID]PriceRange, len(services))
+	for _, svc := range services {
+		planned[svc.CategoryID] = svc.EstimatedCost
+	}
+	return func(categoryID uuid.UUID) PriceRange {
+		if cost, ok := planned[categoryID]; ok {
+			return cost
+		}
+		return o.estimateServiceCost(ctx, categoryID, event)
+	}
+}
+
+// CalculateBundlePrice is what a bundle costs. A bundle with vendor
+// assignments costs the sum of its chosen vendors' bundle prices; one with
+// none yet is the midpoint of each category's estimated cost, less the
+// bundle discount.
+func CalculateBundlePrice(b BundleOption, assignments []BundleVendor, estimate func(categoryID uuid.UUID) PriceRange) float64 {
+	total := 0.0
+	if len(assignments) > 0 {
+		for _, v := range b.Vendors {
+			total += v.BundlePrice
+		}
+		return total
+	}
+	
+	for _, categoryID := range b.IncludedServices {
+		cost := estimate(categoryID)
+		total += (cost.Min + cost.Max) / 2
+	}
+	if b.SavingsPercent > 0 && b.SavingsPercent < 100 {
+		total *= 1 - b.SavingsPercent/100
+	}
+	return total
+}
+
+// PriceBundleOption sets a bundle's total price and savings. It reports
+// false for a bundle that prices to nothing, which isn't worth offering.
+func PriceBundleOption(b BundleOption, assignments []BundleVendor, estimate func(categoryID uuid.UUID) PriceRange) (BundleOption, bool) {
+	b.TotalPrice = CalculateBundlePrice(b, assignments, estimate)
+	if b.TotalPrice <= 0 {
+		return b, false
+	}
+	b.Savings = b.RegularPrice() - b.TotalPrice
+	return b, true
+}
+
+// RegularPrice is what the bundle's services cost booked separately
+func (b BundleOption) RegularPrice() float64 {
+	if b.SavingsPercent <= 0 || b.SavingsPercent >= 100 {
+		return b.TotalPrice
+	}
+	return b.TotalPrice / (1 - b.SavingsPercent/100)
+}
+
+// BundleDiscountPolicy governs how a bundle's own discount stacks with the
+// subscription and promo discounts a user brings when accepting it
+var BundleDiscountPolicy = discount.DefaultPolicy
+
+// PriceBundle prices a bundle from its regular price, resolving the bundle
+// discount against any others the user qualifies for
+func PriceBundle(b BundleOption, others []discount.Discount, policy discount.Policy) discount.Result {
+	discounts := append([]discount.Discount{{
+		Source:  discount.SourceBundle,
+		Code:    b.Name,
+		Percent: b.SavingsPercent,
+	}}, others...)
+	return discount.Resolve(b.RegularPrice(), discounts, policy)
+}
+
+// ResolveBundleVendors picks one vendor per bundle category, preferring the
+// default assignment and substituting the next vendor in display order when
+// the default is unavailable. A required category with no available vendor
+// makes the bundle unusable; an optional one is left out and reported.
+func ResolveBundleVendors(assignments []BundleVendor, unavailable map[uuid.UUID]bool) ([]BundleVendor, []uuid.UUID, bool) {
+	var categories []uuid.UUID
+	byCategory := make(map[uuid.UUID][]BundleVendor)
+	for _, a := range assignments {
+		if _, seen := byCategory[a.CategoryID]; !seen {
+			categories = append(categories, a.CategoryID)
+		}
+		byCategory[a.CategoryID] = append(byCategory[a.CategoryID], a)
+	}
+	
+	var chosen []BundleVendor
+	var missing []uuid.UUID
+	for _, categoryID := range categories {
+		options := byCategory[categoryID]
+		sort.SliceStable(options, func(i, j int) bool {
+			if options[i].IsDefault != options[j].IsDefault {
+				return options[i].IsDefault
+			}
+			return options[i].DisplayOrder < options[j].DisplayOrder
+		})
+		
+		required := false
+		picked := false
+		for _, option := range options {
+			required = required || option.IsRequired
+			if picked || unavailable[option.VendorID] {
+				continue
+			}
+			if option.VendorID != options[0].VendorID {
+				replaced := options[0].VendorID
+				option.SubstitutedFor = &replaced
+			}
+			chosen = append(chosen, option)
+			picked = true
+		}
+		if picked {
+			continue
+		}
+		if required {
+			return nil, nil, false
+		}
+		missing = append(missing, categoryID)
 	}
 	
-	return bundles, nil
+	return chosen, missing, true
 }
 
-func (o *OrchestrationEngine) calculateBundlePrice(ctx context.Context, bundleID uuid.UUID) float64 {
-	var totalPrice float64
-	o.db.QueryRow(ctx, `
-		SELECT COALESCE(SUM(bva.bundle_price), 0)
-		FROM bundle_vendor_assignments bva
-		WHERE bva.bundle_id = $1
-	`, bundleID).Scan(&totalPrice)
-	return totalPrice
+func (o *OrchestrationEngine) loadBundleVendors(ctx context.Context, bundleID uuid.UUID) ([]BundleVendor, error) {
+	rows, err := o.db.Query(ctx, `
+		SELECT vendor_id, category_id, COALESCE(bundle_price, 0),
+		       is_required, is_default, display_order
+		FROM bundle_vendor_assignments
+		WHERE bundle_id = $1 AND is_active = TRUE
+		ORDER BY display_order
+	`, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var vendors []BundleVendor
+	for rows.Next() {
+		var v BundleVendor
+		if err := rows.Scan(&v.VendorID, &v.CategoryID, &v.BundlePrice,
+			&v.IsRequired, &v.IsDefault, &v.DisplayOrder); err != nil {
+			continue
+		}
+		vendors = append(vendors, v)
+	}
+	return vendors, rows.Err()
+}
+
+// unavailableVendors returns which of the vendors are already booked to capacity
+// on the event date. Without a date every vendor counts as available.
+func (o *OrchestrationEngine) unavailableVendors(ctx context.Context, vendorIDs []uuid.UUID, eventDate *time.Time) (map[uuid.UUID]bool, error) {
+	unavailable := make(map[uuid.UUID]bool)
+	if eventDate == nil || len(vendorIDs) == 0 {
+		return unavailable, nil
+	}
+	
+	rows, err := o.db.Query(ctx, `
+		SELECT v.id
+		FROM vendors v
+		WHERE v.id = ANY($1)
+		  AND (
+			SELECT COUNT(*) FROM bookings b
+			WHERE b.vendor_id = v.id AND b.scheduled_date = $2 AND b.status NOT IN ('cancelled')
+		  ) >= v.max_concurrent_bookings
+	`, vendorIDs, *eventDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		unavailable[id] = true
+	}
+	return unavailable, rows.Err()
 }
 
 func (o *OrchestrationEngine) assessRisks(event *LifeEvent, plan *EventOrchestrationPlan) []IdentifiedRisk {
@@ -1451,6 +2592,17 @@ func (o *OrchestrationEngine) assessRisks(event *LifeEvent, plan *EventOrchestra
 	return risks
 }
 
+// serviceSettled reports whether the event's required service in a category
+// has been booked or skipped
+func serviceSettled(event *LifeEvent, categoryID uuid.UUID) bool {
+	for _, svc := range event.RequiredServices {
+		if svc.CategoryID == categoryID {
+			return svc.Status == RequirementBooked || svc.Status == RequirementSkipped
+		}
+	}
+	return false
+}
+
 func (o *OrchestrationEngine) generateNextActions(event *LifeEvent, plan *EventOrchestrationPlan) []RecommendedAction {
 	var actions []RecommendedAction
 	
@@ -1470,6 +2622,15 @@ func (o *OrchestrationEngine) generateNextActions(event *LifeEvent, plan *EventO
 		}
 	}
 	
+	// Carry the actions from the event's last phase change, dropping bookings
+	// that have since been made
+	for _, action := range event.PendingActions {
+		if action.RelatedServiceID != nil && serviceSettled(event, *action.RelatedServiceID) {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	
 	// Add budget action if not set
 	if event.Budget == nil {
 		actions = append(actions, RecommendedAction{
@@ -1503,80 +2664,810 @@ func (o *OrchestrationEngine) generateNextActions(event *LifeEvent, plan *EventO
 }
 
 // =============================================================================
-// 2.4 API HANDLERS
+// 2.3.1 EVENT LIFECYCLE
 // =============================================================================
 
-// LifeOSAPI provides the REST API for LifeOS
-type LifeOSAPI struct {
-	detectionEngine     *EventDetectionEngine
-	orchestrationEngine *OrchestrationEngine
-	db                  *pgxpool.Pool
+// ErrInvalidTransition is returned when a phase or status change skips or
+// reverses the event lifecycle illegally
+var ErrInvalidTransition = errors.New("invalid event lifecycle transition")
+
+// phaseOrder is the canonical progression of an event's phases
+var phaseOrder = []EventPhase{
+	PhaseDiscovery,
+	PhasePlanning,
+	PhaseVendorSelect,
+	PhaseBooking,
+	PhasePreEvent,
+	PhaseEventDay,
+	PhasePostEvent,
 }
 
-// CreateEventRequest for manual event creation
-type CreateEventRequest struct {
-	EventType    EventType       `json:"event_type"`
-	EventSubtype string          `json:"event_subtype,omitempty"`
-	EventDate    *time.Time      `json:"event_date,omitempty"`
-	DateFlex     DateFlexibility `json:"date_flexibility"`
-	Location     *Location       `json:"location,omitempty"`
-	GuestCount   *int            `json:"guest_count,omitempty"`
-	Budget       *Budget         `json:"budget,omitempty"`
-	Preferences  *EventPreferences `json:"preferences,omitempty"`
+// phaseTransitions lists the legal next phases. Events may advance one phase
+// at a time or step back one phase while still planning.
+var phaseTransitions = map[EventPhase][]EventPhase{
+	PhaseDiscovery:    {PhasePlanning},
+	PhasePlanning:     {PhaseVendorSelect, PhaseDiscovery},
+	PhaseVendorSelect: {PhaseBooking, PhasePlanning},
+	PhaseBooking:      {PhasePreEvent, PhaseVendorSelect},
+	PhasePreEvent:     {PhaseEventDay, PhaseBooking},
+	PhaseEventDay:     {PhasePostEvent},
+	PhasePostEvent:    {},
 }
 
-// GetDetectedEvents returns events detected for a user
-func (api *LifeOSAPI) GetDetectedEvents(ctx context.Context, userID uuid.UUID) ([]LifeEvent, error) {
-	return api.detectionEngine.DetectEvents(ctx, userID)
+// statusTransitions lists the legal next statuses
+var statusTransitions = map[EventStatus][]EventStatus{
+	StatusDetected:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed:  {StatusPlanning, StatusCancelled},
+	StatusPlanning:   {StatusBooked, StatusCancelled},
+	StatusBooked:     {StatusPlanning, StatusInProgress, StatusCancelled},
+	StatusInProgress: {StatusCompleted},
+	StatusCompleted:  {},
+	StatusCancelled:  {},
 }
 
-// CreateEvent creates a new life event
-func (api *LifeOSAPI) CreateEvent(ctx context.Context, userID uuid.UUID, req CreateEventRequest) (*LifeEvent, error) {
-	event := &LifeEvent{
-		ID:              uuid.New(),
-		UserID:          userID,
-		EventType:       req.EventType,
-		EventSubtype:    req.EventSubtype,
-		ClusterType:     api.detectionEngine.getClusterForEvent(req.EventType),
-		DetectedAt:      time.Now(),
-		EventDate:       req.EventDate,
-		EventDateFlex:   req.DateFlex,
-		DetectionMethod: DetectionExplicit,
-		DetectionConfidence: 1.0,
-		GuestCount:      req.GuestCount,
-		Location:        req.Location,
-		Budget:          req.Budget,
-		Status:          StatusConfirmed,
-		Phase:           PhasePlanning,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+// LifecycleChange describes a single phase or status transition and the
+// follow-up actions generated for it
+type LifecycleChange struct {
+	EventID    uuid.UUID           `json:"event_id"`
+	FromPhase  EventPhase          `json:"from_phase"`
+	ToPhase    EventPhase          `json:"to_phase"`
+	FromStatus EventStatus         `json:"from_status"`
+	ToStatus   EventStatus         `json:"to_status"`
+	Actions    []RecommendedAction `json:"actions,omitempty"`
+	ChangedAt  time.Time           `json:"changed_at"`
+}
+
+// PhaseChanged reports whether the transition moved the event's phase
+func (c *LifecycleChange) PhaseChanged() bool {
+	return c.FromPhase != c.ToPhase
+}
+
+// StatusChanged reports whether the transition moved the event's status
+func (c *LifecycleChange) StatusChanged() bool {
+	return c.FromStatus != c.ToStatus
+}
+
+// LifecycleHook runs after a transition has been applied to the event
+type LifecycleHook func(ctx context.Context, event *LifeEvent, change *LifecycleChange)
+
+// EventLifecycle validates phase/status transitions and fires hooks on change.
+// Completion and action generation always run first; hooks registered with
+// OnTransition (e.g. notifications) run afterwards in registration order.
+type EventLifecycle struct {
+	mu    sync.RWMutex
+	hooks []LifecycleHook
+}
+
+// NewEventLifecycle creates a lifecycle with the built-in hooks
+func NewEventLifecycle() *EventLifecycle {
+	return &EventLifecycle{}
+}
+
+// OnTransition registers a hook fired after every successful transition
+func (l *EventLifecycle) OnTransition(hook LifecycleHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+// LifecycleNotifier tells an event's owner about a transition
+type LifecycleNotifier interface {
+	NotifyEventChange(ctx context.Context, event *LifeEvent, change *LifecycleChange)
+}
+
+// NotifyHook returns a hook sending every transition through notifier
+func NotifyHook(notifier LifecycleNotifier) LifecycleHook {
+	return func(ctx context.Context, event *LifeEvent, change *LifecycleChange) {
+		notifier.NotifyEventChange(ctx, event, change)
 	}
-	
-	if req.Preferences != nil {
-		event.Preferences = *req.Preferences
+}
+
+// Notification renders the message telling the event's owner about the
+// change, listing the actions it generated
+func (c *LifecycleChange) Notification(event *LifeEvent) (title, message string) {
+	name := strings.ReplaceAll(string(event.EventType), "_", " ")
+	if name == "" {
+		name = "event"
+	}
+	if c.StatusChanged() {
+		title = fmt.Sprintf("Your %s is now %s", name, strings.ReplaceAll(string(c.ToStatus), "_", " "))
+	}
+	if c.PhaseChanged() {
+		title = fmt.Sprintf("Your %s has moved to %s", name, strings.ReplaceAll(string(c.ToPhase), "_", " "))
 	}
 	
-	// Determine scale from guest count
-	if req.GuestCount != nil {
-		event.Scale = api.determineScale(*req.GuestCount)
+	var next []string
+	for _, action := range c.Actions {
+		next = append(next, action.Title)
+	}
+	if len(next) > 0 {
+		message = "Next: " + strings.Join(next, ", ")
 	} else {
-		event.Scale = ScaleMedium
+		message = "Open your event to see what's next."
 	}
-	
-	// Save to database
-	if err := api.saveEvent(ctx, event); err != nil {
-		return nil, err
+	return title, message
+}
+
+// CanAdvancePhase reports whether an event may move between the two phases
+func CanAdvancePhase(from, to EventPhase) bool {
+	if from == to {
+		return true
 	}
-	
-	return event, nil
+	for _, next := range phaseTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
 }
 
-func (api *LifeOSAPI) determineScale(guestCount int) EventScale {
-	switch {
-	case guestCount < 20:
-		return ScaleIntimate
-	case guestCount < 50:
-		return ScaleSmall
+// CanChangeStatus reports whether an event may move between the two statuses
+func CanChangeStatus(from, to EventStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AdvancePhase moves the event to a new phase
+func (l *EventLifecycle) AdvancePhase(ctx context.Context, event *LifeEvent, to EventPhase) (*LifecycleChange, error) {
+	return l.Transition(ctx, event, to, event.Status)
+}
+
+// SetStatus moves the event to a new status
+func (l *EventLifecycle) SetStatus(ctx context.Context, event *LifeEvent, to EventStatus) (*LifecycleChange, error) {
+	return l.Transition(ctx, event, event.Phase, to)
+}
+
+// Transition validates and applies a combined phase and status change. The
+// event is left untouched and no hooks fire if either move is illegal.
+func (l *EventLifecycle) Transition(ctx context.Context, event *LifeEvent, phase EventPhase, status EventStatus) (*LifecycleChange, error) {
+	if event.Status == StatusCompleted || event.Status == StatusCancelled {
+		if phase != event.Phase || status != event.Status {
+			return nil, fmt.Errorf("%w: event is %s", ErrInvalidTransition, event.Status)
+		}
+	}
+	if !CanAdvancePhase(event.Phase, phase) {
+		return nil, fmt.Errorf("%w: phase %s -> %s", ErrInvalidTransition, event.Phase, phase)
+	}
+	if !CanChangeStatus(event.Status, status) {
+		return nil, fmt.Errorf("%w: status %s -> %s", ErrInvalidTransition, event.Status, status)
+	}
+	
+	now := time.Now()
+	change := &LifecycleChange{
+		EventID:    event.ID,
+		FromPhase:  event.Phase,
+		ToPhase:    phase,
+		FromStatus: event.Status,
+		ToStatus:   status,
+		ChangedAt:  now,
+	}
+	if !change.PhaseChanged() && !change.StatusChanged() {
+		return change, nil
+	}
+	
+	event.Phase = phase
+	event.Status = status
+	event.UpdatedAt = now
+	switch status {
+	case StatusConfirmed:
+		if event.ConfirmedAt == nil {
+			event.ConfirmedAt = &now
+		}
+	case StatusCompleted:
+		event.CompletedAt = &now
+	}
+	
+	recomputeCompletion(ctx, event, change)
+	generatePhaseActions(ctx, event, change)
+	if change.PhaseChanged() {
+		// The old phase's actions no longer apply
+		event.PendingActions = change.Actions
+	}
+	
+	l.mu.RLock()
+	hooks := make([]LifecycleHook, len(l.hooks))
+	copy(hooks, l.hooks)
+	l.mu.RUnlock()
+	
+	for _, hook := range hooks {
+		hook(ctx, event, change)
+	}
+	
+	return change, nil
+}
+
+// recomputeCompletion refreshes completion for the event's new phase or
+// status. Callers holding the event's plan refresh it again with the plan.
+func recomputeCompletion(ctx context.Context, event *LifeEvent, change *LifecycleChange) {
+	event.CompletionPct = EventCompletion(event, nil)
+}
+
+// generatePhaseActions surfaces the actions a user needs on entering a phase.
+// Transition keeps them on the event as its PendingActions.
+func generatePhaseActions(ctx context.Context, event *LifeEvent, change *LifecycleChange) {
+	if !change.PhaseChanged() {
+		return
+	}
+	
+	switch event.Phase {
+	case PhaseBooking:
+		for _, svc := range event.RequiredServices {
+			if svc.Status == RequirementBooked || svc.Status == RequirementSkipped {
+				continue
+			}
+			categoryID := svc.CategoryID
+			action := RecommendedAction{
+				ID:               uuid.New(),
+				Title:            fmt.Sprintf("Book %s", svc.CategoryName),
+				Description:      fmt.Sprintf("Confirm booking with your selected %s vendor", svc.CategoryName),
+				Priority:         "medium",
+				ActionType:       "book",
+				RelatedServiceID: &categoryID,
+				DeepLink:         fmt.Sprintf("/events/%s/book?category=%s", event.ID, svc.CategoryID),
+			}
+			if svc.Priority == PriorityCritical || svc.Priority == PriorityHigh {
+				action.Priority = "high"
+			}
+			change.Actions = append(change.Actions, action)
+		}
+		
+	case PhasePreEvent:
+		change.Actions = append(change.Actions, RecommendedAction{
+			ID:          uuid.New(),
+			Title:       "Confirm all vendors",
+			Description: "Call each vendor to confirm details and timing",
+			Priority:    "high",
+			ActionType:  "confirm",
+			DeepLink:    fmt.Sprintf("/events/%s/vendors", event.ID),
+		})
+		
+	case PhasePostEvent:
+		change.Actions = append(change.Actions, RecommendedAction{
+			ID:          uuid.New(),
+			Title:       "Review your vendors",
+			Description: "Rate the vendors who worked on your event",
+			Priority:    "low",
+			ActionType:  "review",
+			DeepLink:    fmt.Sprintf("/events/%s/reviews", event.ID),
+		})
+	}
+}
+
+// =============================================================================
+// 2.3.2 PLAN TASKS
+// =============================================================================
+
+// ErrTaskNotFound is returned when a task ID isn't part of the event's plan
+var ErrTaskNotFound = errors.New("task not found in event plan")
+
+const (
+	TaskStatusPending   = "pending"
+	TaskStatusCompleted = "completed"
+)
+
+// TaskProgress is the result of completing or reopening a checklist task
+type TaskProgress struct {
+	EventID         uuid.UUID        `json:"event_id"`
+	Task            PhaseTask        `json:"task"`
+	TaskPhase       EventPhase       `json:"task_phase"`
+	PhaseCompletion float64          `json:"phase_completion"`
+	CompletionPct   float64          `json:"completion_percentage"`
+	CurrentPhase    EventPhase       `json:"current_phase"`
+	PhaseChange     *LifecycleChange `json:"phase_change,omitempty"`
+}
+
+// PlanTaskID derives a stable ID for a generated task. Plans are regenerated
+// on every read, so the ID must not change between generations for recorded
+// progress to keep applying.
+func PlanTaskID(eventID uuid.UUID, phase EventPhase, title string) uuid.UUID {
+	return uuid.NewSHA1(eventID, []byte(string(phase)+"/"+title))
+}
+
+// PhaseTaskCompletion returns the percentage of tasks that are completed. ok
+// is false when there are no tasks to measure.
+func PhaseTaskCompletion(tasks []PhaseTask) (pct float64, ok bool) {
+	if len(tasks) == 0 {
+		return 0, false
+	}
+	done := 0
+	for _, t := range tasks {
+		if t.Status == TaskStatusCompleted {
+			done++
+		}
+	}
+	return float64(done) / float64(len(tasks)) * 100, true
+}
+
+// ApplyTaskStatuses overlays recorded task statuses onto a freshly generated
+// plan and refreshes phase progress relative to the event's current phase
+func ApplyTaskStatuses(plan *EventOrchestrationPlan, current EventPhase, statuses map[uuid.UUID]string) {
+	for i := range plan.Phases {
+		for j := range plan.Phases[i].Tasks {
+			if status, ok := statuses[plan.Phases[i].Tasks[j].ID]; ok {
+				plan.Phases[i].Tasks[j].Status = status
+			}
+		}
+	}
+	refreshPhaseProgress(plan, current)
+}
+
+// refreshPhaseProgress recomputes per-phase completion and status. Phases
+// behind the current one, or with every task done, are completed.
+func refreshPhaseProgress(plan *EventOrchestrationPlan, current EventPhase) {
+	currentIdx := phaseIndex(current)
+	for i := range plan.Phases {
+		phase := &plan.Phases[i]
+		pct, hasTasks := PhaseTaskCompletion(phase.Tasks)
+		idx := phaseIndex(phase.Phase)
+		
+		switch {
+		case idx < currentIdx, hasTasks && pct == 100:
+			phase.Status = "completed"
+		case idx == currentIdx:
+			phase.Status = "active"
+		default:
+			phase.Status = "pending"
+		}
+		
+		if phase.Status == "completed" && !hasTasks {
+			pct = 100
+		}
+		phase.CompletionPct = pct
+	}
+}
+
+// EventCompletion is the event's completion percentage, and the only way
+// CompletionPct is set. It is the further along of two measures: how far
+// through the phase sequence the event is, with the current phase
+// contributing the share of its plan tasks that are done; and the
+// priority-weighted share of its critical and high priority services that
+// are booked (see ServiceCompletion). plan may be nil, in which case the
+// current phase counts from its start. A completed event is 100.
+func EventCompletion(event *LifeEvent, plan *EventOrchestrationPlan) float64 {
+	if event.Status == StatusCompleted {
+		return 100
+	}
+	idx := phaseIndex(event.Phase)
+	if idx < 0 {
+		return event.CompletionPct
+	}
+	
+	var fraction float64
+	if tasks := planTasks(plan, event.Phase); tasks != nil {
+		pct, _ := PhaseTaskCompletion(tasks)
+		fraction = pct / 100
+	}
+	pct := (float64(idx) + fraction) / float64(len(phaseOrder)-1) * 100
+	
+	if booked, ok := ServiceCompletion(event.RequiredServices); ok && booked > pct {
+		pct = booked
+	}
+	return math.Min(100, pct)
+}
+
+// RecordTaskStatus sets a task's status in the plan and refreshes the plan's
+// and event's completion. Completing the last open task of the event's
+// current phase advances the event to the next phase. Reopening a task never
+// moves the event backwards.
+func (l *EventLifecycle) RecordTaskStatus(ctx context.Context, event *LifeEvent, plan *EventOrchestrationPlan, taskID uuid.UUID, status string) (*TaskProgress, error) {
+	if status != TaskStatusPending && status != TaskStatusCompleted {
+		return nil, fmt.Errorf("invalid task status: %s", status)
+	}
+	
+	task, taskPhase := findPlanTask(plan, taskID)
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+	task.Status = status
+	
+	progress := &TaskProgress{
+		EventID:   event.ID,
+		TaskPhase: taskPhase,
+	}
+	
+	if status == TaskStatusCompleted && taskPhase == event.Phase {
+		pct, _ := PhaseTaskCompletion(planTasks(plan, taskPhase))
+		next := nextPhase(event.Phase)
+		if pct == 100 && next != "" && CanAdvancePhase(event.Phase, next) {
+			change, err := l.AdvancePhase(ctx, event, next)
+			if err != nil {
+				return nil, err
+			}
+			progress.PhaseChange = change
+		}
+	}
+	
+	refreshPhaseProgress(plan, event.Phase)
+	event.CompletionPct = EventCompletion(event, plan)
+	event.UpdatedAt = time.Now()
+	
+	progress.Task = *task
+	progress.PhaseCompletion, _ = PhaseTaskCompletion(planTasks(plan, taskPhase))
+	progress.CompletionPct = event.CompletionPct
+	progress.CurrentPhase = event.Phase
+	
+	return progress, nil
+}
+
+func findPlanTask(plan *EventOrchestrationPlan, taskID uuid.UUID) (*PhaseTask, EventPhase) {
+	for i := range plan.Phases {
+		for j := range plan.Phases[i].Tasks {
+			if plan.Phases[i].Tasks[j].ID == taskID {
+				return &plan.Phases[i].Tasks[j], plan.Phases[i].Phase
+			}
+		}
+	}
+	return nil, ""
+}
+
+func planTasks(plan *EventOrchestrationPlan, phase EventPhase) []PhaseTask {
+	if plan == nil {
+		return nil
+	}
+	for _, p := range plan.Phases {
+		if p.Phase == phase {
+			return p.Tasks
+		}
+	}
+	return nil
+}
+
+func phaseIndex(phase EventPhase) int {
+	for i, p := range phaseOrder {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+func nextPhase(phase EventPhase) EventPhase {
+	idx := phaseIndex(phase)
+	if idx < 0 || idx+1 >= len(phaseOrder) {
+		return ""
+	}
+	return phaseOrder[idx+1]
+}
+
+// =============================================================================
+// 2.3.3 PLAN VERSIONS
+// =============================================================================
+
+// ErrPlanVersionNotFound is returned when an event has no plan with the
+// requested version number
+var ErrPlanVersionNotFound = errors.New("plan version not found")
+
+// PlanVersion is a snapshot of a generated plan. A new version is stored
+// whenever regeneration changes the plan's services, budget or risks.
+type PlanVersion struct {
+	EventID   uuid.UUID               `json:"event_id"`
+	Version   int                     `json:"version"`
+	Plan      *EventOrchestrationPlan `json:"plan,omitempty"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+// PlanDiff describes what changed between two plan versions
+type PlanDiff struct {
+	EventID           uuid.UUID           `json:"event_id"`
+	FromVersion       int                 `json:"from_version"`
+	ToVersion         int                 `json:"to_version"`
+	AddedServices     []PlanServiceChange `json:"added_services"`
+	RemovedServices   []PlanServiceChange `json:"removed_services"`
+	TotalBudgetChange float64             `json:"total_budget_change"`
+	BudgetShifts      []BudgetShift       `json:"budget_shifts"`
+	NewRisks          []IdentifiedRisk    `json:"new_risks"`
+	ResolvedRisks     []IdentifiedRisk    `json:"resolved_risks"`
+}
+
+// PlanServiceChange identifies a service added to or dropped from a plan
+type PlanServiceChange struct {
+	CategoryID   uuid.UUID       `json:"category_id"`
+	CategoryName string          `json:"category_name"`
+	Priority     ServicePriority `json:"priority"`
+}
+
+// BudgetShift is a change in the amount allocated to a category
+type BudgetShift struct {
+	CategoryID   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	From         float64   `json:"from"`
+	To           float64   `json:"to"`
+	Change       float64   `json:"change"`
+}
+
+// IsEmpty reports whether the two plans differ in services, budget or risks
+func (d *PlanDiff) IsEmpty() bool {
+	return len(d.AddedServices) == 0 && len(d.RemovedServices) == 0 &&
+		d.TotalBudgetChange == 0 && len(d.BudgetShifts) == 0 &&
+		len(d.NewRisks) == 0 && len(d.ResolvedRisks) == 0
+}
+
+// DiffPlans compares two plans for the same event. Services and budget
+// categories are matched by category; risks are regenerated with fresh IDs,
+// so they are matched by type and description.
+func DiffPlans(from, to *EventOrchestrationPlan) *PlanDiff {
+	diff := &PlanDiff{
+		EventID:           to.EventID,
+		AddedServices:     []PlanServiceChange{},
+		RemovedServices:   []PlanServiceChange{},
+		TotalBudgetChange: roundAmount(to.BudgetPlan.TotalBudget - from.BudgetPlan.TotalBudget),
+		BudgetShifts:      []BudgetShift{},
+		NewRisks:          []IdentifiedRisk{},
+		ResolvedRisks:     []IdentifiedRisk{},
+	}
+	
+	fromServices := make(map[uuid.UUID]bool, len(from.ServicePlan))
+	for _, svc := range from.ServicePlan {
+		fromServices[svc.CategoryID] = true
+	}
+	toServices := make(map[uuid.UUID]bool, len(to.ServicePlan))
+	for _, svc := range to.ServicePlan {
+		toServices[svc.CategoryID] = true
+		if !fromServices[svc.CategoryID] {
+			diff.AddedServices = append(diff.AddedServices, PlanServiceChange{CategoryID: svc.CategoryID, CategoryName: svc.CategoryName, Priority: svc.Priority})
+		}
+	}
+	for _, svc := range from.ServicePlan {
+		if !toServices[svc.CategoryID] {
+			diff.RemovedServices = append(diff.RemovedServices, PlanServiceChange{CategoryID: svc.CategoryID, CategoryName: svc.CategoryName, Priority: svc.Priority})
+		}
+	}
+	
+	// Categories only on one side are covered by added/removed services
+	fromAllocated := make(map[uuid.UUID]float64, len(from.BudgetPlan.Categories))
+	for _, cat := range from.BudgetPlan.Categories {
+		fromAllocated[cat.CategoryID] = cat.Allocated
+	}
+	for _, cat := range to.BudgetPlan.Categories {
+		before, ok := fromAllocated[cat.CategoryID]
+		if !ok {
+			continue
+		}
+		if change := roundAmount(cat.Allocated - before); change != 0 {
+			diff.BudgetShifts = append(diff.BudgetShifts, BudgetShift{
+				CategoryID:   cat.CategoryID,
+				CategoryName: cat.CategoryName,
+				From:         before,
+				To:           cat.Allocated,
+				Change:       change,
+			})
+		}
+	}
+	
+	fromRisks := make(map[string]bool, len(from.Risks))
+	for _, r := range from.Risks {
+		fromRisks[riskKey(r)] = true
+	}
+	toRisks := make(map[string]bool, len(to.Risks))
+	for _, r := range to.Risks {
+		toRisks[riskKey(r)] = true
+		if !fromRisks[riskKey(r)] {
+			diff.NewRisks = append(diff.NewRisks, r)
+		}
+	}
+	for _, r := range from.Risks {
+		if !toRisks[riskKey(r)] {
+			diff.ResolvedRisks = append(diff.ResolvedRisks, r)
+		}
+	}
+	
+	return diff
+}
+
+func riskKey(r IdentifiedRisk) string {
+	return r.Type + "/" + r.Description
+}
+
+// roundAmount rounds to kobo so float noise doesn't register as a change
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// =============================================================================
+// 2.3.4 SERVICE COMPLETION
+// =============================================================================
+
+// completionWeights is how much booking a service of each priority counts
+// towards an event's completion. Medium and low priority services don't.
+var completionWeights = map[ServicePriority]float64{
+	PriorityCritical: 2,
+	PriorityHigh:     1,
+}
+
+// ServiceCompletion is the priority-weighted share of an event's critical and
+// high priority services that are booked. Skipped services don't count. It
+// reports false when the event has no such services to measure.
+func ServiceCompletion(services []RequiredService) (float64, bool) {
+	var booked, total float64
+	for _, svc := range services {
+		weight := completionWeights[svc.Priority]
+		if weight == 0 || svc.Status == RequirementSkipped {
+			continue
+		}
+		total += weight
+		if svc.Status == RequirementBooked {
+			booked += weight
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return booked / total * 100, true
+}
+
+// CompletionPhase is the phase an event's bookings have carried it to: the
+// booking phase once every critical service is booked, and final
+// preparations once every critical and high priority service is. It is
+// empty while a critical service is still open.
+func CompletionPhase(services []RequiredService) EventPhase {
+	pct, ok := ServiceCompletion(services)
+	if !ok {
+		return ""
+	}
+	if pct == 100 {
+		return PhasePreEvent
+	}
+	for _, svc := range services {
+		if svc.Priority == PriorityCritical && svc.Status != RequirementBooked && svc.Status != RequirementSkipped {
+			return ""
+		}
+	}
+	return PhaseBooking
+}
+
+// SetLifecycle sets the lifecycle that phase changes from RecomputeCompletion
+// go through, so its hooks fire
+func (o *OrchestrationEngine) SetLifecycle(lifecycle *EventLifecycle) {
+	o.lifecycle = lifecycle
+}
+
+// RecomputeCompletion refreshes the event's completion (see EventCompletion)
+// after its services' booking statuses change, and saves it. An event whose
+// bookings reach a later phase is advanced to it one phase at a time; it
+// never moves back.
+func (o *OrchestrationEngine) RecomputeCompletion(ctx context.Context, event *LifeEvent) (float64, error) {
+	return o.recomputeWithPlan(ctx, event, nil)
+}
+
+// recomputeWithPlan is RecomputeCompletion counting the plan's task progress
+// in the current phase; plan may be nil
+func (o *OrchestrationEngine) recomputeWithPlan(ctx context.Context, event *LifeEvent, plan *EventOrchestrationPlan) (float64, error) {
+	target := CompletionPhase(event.RequiredServices)
+	if target != "" && event.Status != StatusCompleted && event.Status != StatusCancelled {
+		lifecycle := o.lifecycle
+		if lifecycle == nil {
+			lifecycle = NewEventLifecycle()
+		}
+		for phaseIndex(event.Phase) < phaseIndex(target) {
+			if _, err := lifecycle.AdvancePhase(ctx, event, nextPhase(event.Phase)); err != nil {
+				return event.CompletionPct, err
+			}
+		}
+	}
+	
+	// Set after advancing, whose hooks recompute completion without the plan
+	event.CompletionPct = EventCompletion(event, plan)
+	
+	if o.db == nil {
+		return event.CompletionPct, nil
+	}
+	if err := o.updateEvent(ctx, event); err != nil {
+		return event.CompletionPct, err
+	}
+	return event.CompletionPct, nil
+}
+
+// =============================================================================
+// 2.4 API HANDLERS
+// =============================================================================
+
+// LifeOSAPI provides the REST API for LifeOS
+type LifeOSAPI struct {
+	detectionEngine     *EventDetectionEngine
+	orchestrationEngine *OrchestrationEngine
+	db                  *pgxpool.Pool
+	lifecycle           *EventLifecycle
+	lifecycleOnce       sync.Once
+}
+
+// NewLifeOSAPI creates the LifeOS API backed by the given stores
+func NewLifeOSAPI(db *pgxpool.Pool, cache *redis.Client) *LifeOSAPI {
+	return &LifeOSAPI{
+		detectionEngine: NewEventDetectionEngine(db, cache, DefaultDetectionConfig()),
+		orchestrationEngine: NewOrchestrationEngine(db, cache),
+		db: db,
+	}
+}
+
+// Lifecycle returns the state machine used for phase and status changes, so
+// callers can register hooks such as notifications
+func (api *LifeOSAPI) Lifecycle() *EventLifecycle {
+	api.lifecycleOnce.Do(func() {
+		if api.lifecycle == nil {
+			api.lifecycle = NewEventLifecycle()
+		}
+		api.orchestrationEngine.SetLifecycle(api.lifecycle)
+	})
+	return api.lifecycle
+}
+
+// SetNotifier tells event owners about every phase and status change
+func (api *LifeOSAPI) SetNotifier(notifier LifecycleNotifier) {
+	api.Lifecycle().OnTransition(NotifyHook(notifier))
+}
+
+// CreateEventRequest for manual event creation
+type CreateEventRequest struct {
+	EventType    EventType       `json:"event_type"`
+	EventSubtype string          `json:"event_subtype,omitempty"`
+	EventDate    *time.Time      `json:"event_date,omitempty"`
+	DateFlex     DateFlexibility `json:"date_flexibility"`
+	Location     *Location       `json:"location,omitempty"`
+	GuestCount   *int            `json:"guest_count,omitempty"`
+	Budget       *Budget         `json:"budget,omitempty"`
+	Preferences  *EventPreferences `json:"preferences,omitempty"`
+}
+
+// GetDetectedEvents returns events detected for a user
+func (api *LifeOSAPI) GetDetectedEvents(ctx context.Context, userID uuid.UUID) ([]LifeEvent, error) {
+	events, _, err := api.detectionEngine.DetectEvents(ctx, userID)
+	return events, err
+}
+
+// CreateEvent creates a new life event
+func (api *LifeOSAPI) CreateEvent(ctx context.Context, userID uuid.UUID, req CreateEventRequest) (*LifeEvent, error) {
+	event := &LifeEvent{
+		ID:              uuid.New(),
+		UserID:          userID,
+		EventType:       req.EventType,
+		EventSubtype:    req.EventSubtype,
+		ClusterType:     api.detectionEngine.getClusterForEvent(req.EventType),
+		DetectedAt:      time.Now(),
+		EventDate:       req.EventDate,
+		EventDateFlex:   req.DateFlex,
+		DetectionMethod: DetectionExplicit,
+		DetectionConfidence: 1.0,
+		GuestCount:      req.GuestCount,
+		Location:        req.Location,
+		Budget:          req.Budget,
+		Status:          StatusConfirmed,
+		Phase:           PhasePlanning,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	
+	if req.Preferences != nil {
+		event.Preferences = *req.Preferences
+	}
+	
+	// Determine scale from guest count
+	if req.GuestCount != nil {
+		event.Scale = api.determineScale(*req.GuestCount)
+	} else {
+		event.Scale = ScaleMedium
+	}
+	
+	// Save to database
+	if err := api.saveEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	
+	return event, nil
+}
+
+func (api *LifeOSAPI) determineScale(guestCount int) EventScale {
+	switch {
+	case guestCount < 20:
+		return ScaleIntimate
+	case guestCount < 50:
+		return ScaleSmall
 	case guestCount < 150:
 		return ScaleMedium
 	case guestCount < 500:
@@ -1587,7 +3478,7 @@ func (api *LifeOSAPI) determineScale(guestCount int) EventScale {
 }
 
 // GetEventPlan returns the orchestration plan for an event
-func (api *LifeOSAPI) GetEventPlan(ctx context.Context, eventID uuid.UUID) (*EventOrchestrationPlan, error) {
+func (api *LifeOSAPI) GetEventPlan(ctx context.Context, eventID uuid.UUID, opts PlanOptions) (*EventOrchestrationPlan, error) {
 	// Load event
 	event, err := api.loadEvent(ctx, eventID)
 	if err != nil {
@@ -1595,7 +3486,225 @@ func (api *LifeOSAPI) GetEventPlan(ctx context.Context, eventID uuid.UUID) (*Eve
 	}
 	
 	// Generate plan
-	return api.orchestrationEngine.GeneratePlan(ctx, event)
+	plan, err := api.orchestrationEngine.GeneratePlan(ctx, event, opts)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Overlay checklist progress
+	statuses, err := api.loadTaskStatuses(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	ApplyTaskStatuses(plan, event.Phase, statuses)
+	
+	if err := api.recordPlanVersion(ctx, plan); err != nil {
+		return nil, err
+	}
+	
+	return plan, nil
+}
+
+// ErrBundleNotFound is returned when accepting a bundle the event's plan
+// does not offer
+var ErrBundleNotFound = errors.New("bundle not offered for this event")
+
+// BundleAcceptance records a user taking up a bundle for an event, with the
+// discounts that applied
+type BundleAcceptance struct {
+	EventID    uuid.UUID       `json:"event_id"`
+	BundleID   uuid.UUID       `json:"bundle_id"`
+	Vendors    []BundleVendor  `json:"vendors"`
+	Pricing    discount.Result `json:"pricing"`
+	AcceptedAt time.Time       `json:"accepted_at"`
+}
+
+// AcceptBundle takes up one of the bundles offered in the event's plan. The
+// bundle discount stacks with the given discounts per BundleDiscountPolicy.
+func (api *LifeOSAPI) AcceptBundle(ctx context.Context, eventID, bundleID uuid.UUID, discounts []discount.Discount) (*BundleAcceptance, error) {
+	plan, err := api.GetEventPlan(ctx, eventID, PlanOptions{})
+	if err != nil {
+		return nil, err
+	}
+	
+	var bundle *BundleOption
+	for i := range plan.SuggestedBundles {
+		if plan.SuggestedBundles[i].BundleID == bundleID {
+			bundle = &plan.SuggestedBundles[i]
+			break
+		}
+	}
+	if bundle == nil {
+		return nil, ErrBundleNotFound
+	}
+	
+	acceptance := &BundleAcceptance{
+		EventID:    eventID,
+		BundleID:   bundleID,
+		Vendors:    bundle.Vendors,
+		Pricing:    PriceBundle(*bundle, discounts, BundleDiscountPolicy),
+		AcceptedAt: time.Now(),
+	}
+	vendorsJSON, _ := json.Marshal(acceptance.Vendors)
+	pricingJSON, _ := json.Marshal(acceptance.Pricing)
+	
+	_, err = api.db.Exec(ctx, `
+		INSERT INTO life_event_bundle_acceptances (
+			event_id, bundle_id, vendors, regular_price,
+			discount_amount, total_amount, pricing, accepted_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (event_id, bundle_id) DO UPDATE SET
+			vendors = EXCLUDED.vendors,
+			regular_price = EXCLUDED.regular_price,
+			discount_amount = EXCLUDED.discount_amount,
+			total_amount = EXCLUDED.total_amount,
+			pricing = EXCLUDED.pricing,
+			accepted_at = EXCLUDED.accepted_at
+	`, eventID, bundleID, vendorsJSON, acceptance.Pricing.Subtotal,
+		acceptance.Pricing.TotalDiscount, acceptance.Pricing.EffectiveTotal,
+		pricingJSON, acceptance.AcceptedAt)
+	if err != nil {
+		return nil, err
+	}
+	
+	return acceptance, nil
+}
+
+// ListPlanVersions returns the event's plan versions, newest first, without
+// the plans themselves
+func (api *LifeOSAPI) ListPlanVersions(ctx context.Context, eventID uuid.UUID) ([]PlanVersion, error) {
+	rows, err := api.db.Query(ctx, `
+		SELECT version, created_at FROM life_event_plan_versions
+		WHERE event_id = $1
+		ORDER BY version DESC
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	versions := []PlanVersion{}
+	for rows.Next() {
+		v := PlanVersion{EventID: eventID}
+		if err := rows.Scan(&v.Version, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	
+	return versions, rows.Err()
+}
+
+// DiffPlanVersions compares two stored versions of an event's plan
+func (api *LifeOSAPI) DiffPlanVersions(ctx context.Context, eventID uuid.UUID, fromVersion, toVersion int) (*PlanDiff, error) {
+	from, err := api.loadPlanVersion(ctx, eventID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.loadPlanVersion(ctx, eventID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+	
+	diff := DiffPlans(from.Plan, to.Plan)
+	diff.EventID = eventID
+	diff.FromVersion = from.Version
+	diff.ToVersion = to.Version
+	return diff, nil
+}
+
+// recordPlanVersion stores the plan as the next version unless it matches
+// the latest one
+func (api *LifeOSAPI) recordPlanVersion(ctx context.Context, plan *EventOrchestrationPlan) error {
+	latest, err := api.loadPlanVersion(ctx, plan.EventID, 0)
+	if err != nil && !errors.Is(err, ErrPlanVersionNotFound) {
+		return err
+	}
+	
+	version := 1
+	if latest != nil {
+		if DiffPlans(latest.Plan, plan).IsEmpty() {
+			return nil
+		}
+		version = latest.Version + 1
+	}
+	
+	planJSON, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+	
+	// A concurrent regeneration may have taken this version number; either
+	// snapshot is an accurate record
+	_, err = api.db.Exec(ctx, `
+		INSERT INTO life_event_plan_versions (event_id, version, plan, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id, version) DO NOTHING
+	`, plan.EventID, version, planJSON, plan.GeneratedAt)
+	
+	return err
+}
+
+// loadPlanVersion loads one version of an event's plan, or the latest when
+// version is 0
+func (api *LifeOSAPI) loadPlanVersion(ctx context.Context, eventID uuid.UUID, version int) (*PlanVersion, error) {
+	v := PlanVersion{EventID: eventID}
+	var planJSON []byte
+	
+	err := api.db.QueryRow(ctx, `
+		SELECT version, plan, created_at FROM life_event_plan_versions
+		WHERE event_id = $1 AND ($2 = 0 OR version = $2)
+		ORDER BY version DESC
+		LIMIT 1
+	`, eventID, version).Scan(&v.Version, &planJSON, &v.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPlanVersionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := json.Unmarshal(planJSON, &v.Plan); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// CompleteTask marks a plan task as done, advancing the event's phase once
+// every task in its current phase is complete
+func (api *LifeOSAPI) CompleteTask(ctx context.Context, eventID, taskID uuid.UUID) (*TaskProgress, error) {
+	return api.setTaskStatus(ctx, eventID, taskID, TaskStatusCompleted)
+}
+
+// ReopenTask undoes CompleteTask. The event's phase is left where it is.
+func (api *LifeOSAPI) ReopenTask(ctx context.Context, eventID, taskID uuid.UUID) (*TaskProgress, error) {
+	return api.setTaskStatus(ctx, eventID, taskID, TaskStatusPending)
+}
+
+func (api *LifeOSAPI) setTaskStatus(ctx context.Context, eventID, taskID uuid.UUID, status string) (*TaskProgress, error) {
+	event, err := api.loadEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	
+	plan, err := api.GetEventPlan(ctx, eventID, PlanOptions{})
+	if err != nil {
+		return nil, err
+	}
+	
+	progress, err := api.Lifecycle().RecordTaskStatus(ctx, event, plan, taskID, status)
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := api.saveTaskStatus(ctx, eventID, progress.TaskPhase, progress.Task); err != nil {
+		return nil, err
+	}
+	if err := api.updateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	
+	return progress, nil
 }
 
 // ConfirmDetectedEvent confirms a detected event
@@ -1623,11 +3732,9 @@ func (api *LifeOSAPI) ConfirmDetectedEvent(ctx context.Context, eventID uuid.UUI
 		event.Preferences = *updates.Preferences
 	}
 	
-	event.Status = StatusConfirmed
-	event.Phase = PhasePlanning
-	now := time.Now()
-	event.ConfirmedAt = &now
-	event.UpdatedAt = now
+	if _, err := api.Lifecycle().Transition(ctx, event, PhasePlanning, StatusConfirmed); err != nil {
+		return nil, err
+	}
 	
 	// Save updates
 	if err := api.updateEvent(ctx, event); err != nil {
@@ -1637,6 +3744,220 @@ func (api *LifeOSAPI) ConfirmDetectedEvent(ctx context.Context, eventID uuid.UUI
 	return event, nil
 }
 
+// AdvanceEventPhase moves an event to the given phase, rejecting illegal jumps
+func (api *LifeOSAPI) AdvanceEventPhase(ctx context.Context, eventID uuid.UUID, phase EventPhase) (*LifecycleChange, error) {
+	event, err := api.loadEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	
+	change, err := api.Lifecycle().AdvancePhase(ctx, event, phase)
+	if err != nil {
+		return nil, err
+	}
+	event.CompletionPct = EventCompletion(event, api.checklistPlan(ctx, event))
+	
+	if err := api.updateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	
+	return change, nil
+}
+
+// UpdateEventStatus moves an event to the given status, rejecting illegal jumps
+func (api *LifeOSAPI) UpdateEventStatus(ctx context.Context, eventID uuid.UUID, status EventStatus) (*LifecycleChange, error) {
+	event, err := api.loadEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	
+	change, err := api.Lifecycle().SetStatus(ctx, event, status)
+	if err != nil {
+		return nil, err
+	}
+	event.CompletionPct = EventCompletion(event, api.checklistPlan(ctx, event))
+	
+	if err := api.updateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	
+	return change, nil
+}
+
+// RecordServiceBooking tracks spend from a booking made against one of the
+// event's service categories
+func (api *LifeOSAPI) RecordServiceBooking(ctx context.Context, eventID, categoryID uuid.UUID, categoryName string, amount float64) (*BudgetStatus, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("booking amount must be positive")
+	}
+	
+	event, err := api.loadEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if event.Budget == nil {
+		event.Budget = &Budget{Currency: "NGN", Flexibility: BudgetModerate}
+	}
+	event.Budget.RecordSpend(categoryID, categoryName, amount)
+	
+	event.UpdatedAt = time.Now()
+	if err := api.updateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	
+	return event.Budget.Status(event.ID), nil
+}
+
+// ErrEventNotOwned is returned when a booking names a life event that isn't
+// its customer's
+var ErrEventNotOwned = errors.New("life event not found for this user")
+
+// RecordBookingSpend records a booking's amount against the event budget
+// category of the booked service, in the booking's transaction tx so the
+// spend is saved only with the booking. The event must belong to userID,
+// the booking's customer.
+func (api *LifeOSAPI) RecordBookingSpend(ctx context.Context, tx pgx.Tx, userID, eventID, serviceID uuid.UUID, amount float64) (*BudgetStatus, error) {
+	categoryID, categoryName, err := api.serviceCategory(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := updateBudget(ctx, tx, eventID, &userID, func(b *Budget) {
+		b.RecordSpend(categoryID, categoryName, amount)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return budget.Status(eventID), nil
+}
+
+// ReverseBookingSpend takes a cancelled booking's amount back off the event
+// budget, in the cancellation's transaction tx
+func (api *LifeOSAPI) ReverseBookingSpend(ctx context.Context, tx pgx.Tx, eventID, serviceID uuid.UUID, amount float64) error {
+	categoryID, _, err := api.serviceCategory(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+	_, err = updateBudget(ctx, tx, eventID, nil, func(b *Budget) {
+		b.ReverseSpend(categoryID, amount)
+	})
+	return err
+}
+
+// updateBudget applies change to an event's budget with the event row
+// locked, and writes back only the budget, so concurrent bookings for the
+// event don't lose each other's spend. When userID is set the event must be
+// theirs.
+func updateBudget(ctx context.Context, tx pgx.Tx, eventID uuid.UUID, userID *uuid.UUID, change func(*Budget)) (*Budget, error) {
+	var ownerID uuid.UUID
+	var budgetJSON []byte
+	err := tx.QueryRow(ctx, `
+		SELECT user_id, budget FROM life_events WHERE id = $1 FOR UPDATE
+	`, eventID).Scan(&ownerID, &budgetJSON)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && userID != nil && ownerID != *userID {
+		return nil, ErrEventNotOwned
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event budget: %w", err)
+	}
+	
+	var budget *Budget
+	json.Unmarshal(budgetJSON, &budget)
+	if budget == nil {
+		budget = &Budget{Currency: "NGN", Flexibility: BudgetModerate}
+	}
+	change(budget)
+	
+	budgetJSON, _ = json.Marshal(budget)
+	_, err = tx.Exec(ctx, `
+		UPDATE life_events SET budget = $2, updated_at = NOW() WHERE id = $1
+	`, eventID, budgetJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save event budget: %w", err)
+	}
+	return budget, nil
+}
+
+// ConfirmServiceBooking marks the event's required service for a confirmed
+// booking's service category as booked and recomputes the event's
+// completion. A booking outside the event's required services changes
+// nothing. Confirming the same booking again is harmless.
+func (api *LifeOSAPI) ConfirmServiceBooking(ctx context.Context, eventID, serviceID uuid.UUID) (*LifeEvent, error) {
+	event, err := api.loadEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	
+	categoryID, _, err := api.serviceCategory(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	
+	required := false
+	for i := range event.RequiredServices {
+		if event.RequiredServices[i].CategoryID == categoryID {
+			event.RequiredServices[i].Status = RequirementBooked
+			required = true
+		}
+	}
+	if !required {
+		return event, nil
+	}
+	
+	// Keep the lifecycle's hooks firing for any phase the bookings reach
+	api.Lifecycle()
+	event.UpdatedAt = time.Now()
+	if _, err := api.orchestrationEngine.recomputeWithPlan(ctx, event, api.checklistPlan(ctx, event)); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// serviceCategory returns the ID and name of a service's category
+func (api *LifeOSAPI) serviceCategory(ctx context.Context, serviceID uuid.UUID) (uuid.UUID, string, error) {
+	var categoryID uuid.UUID
+	var categoryName string
+	err := api.db.QueryRow(ctx, `
+		SELECT c.id, c.name
+		FROM services s
+		JOIN service_categories c ON c.id = s.category_id
+		WHERE s.id = $1
+	`, serviceID).Scan(&categoryID, &categoryName)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to load service category: %w", err)
+	}
+	return categoryID, categoryName, nil
+}
+
+// checklistPlan is the event's plan with its recorded task progress, for
+// counting the current phase's tasks towards completion. It is nil when the
+// plan can't be generated, which leaves completion to phases and bookings.
+func (api *LifeOSAPI) checklistPlan(ctx context.Context, event *LifeEvent) *EventOrchestrationPlan {
+	plan, err := api.orchestrationEngine.GeneratePlan(ctx, event, PlanOptions{})
+	if err != nil {
+		return nil
+	}
+	statuses, err := api.loadTaskStatuses(ctx, event.ID)
+	if err != nil {
+		return nil
+	}
+	ApplyTaskStatuses(plan, event.Phase, statuses)
+	return plan
+}
+
+// GetBudgetStatus returns spend against the event budget by category
+func (api *LifeOSAPI) GetBudgetStatus(ctx context.Context, eventID uuid.UUID) (*BudgetStatus, error) {
+	event, err := api.loadEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if event.Budget == nil {
+		return (&Budget{}).Status(event.ID), nil
+	}
+	return event.Budget.Status(event.ID), nil
+}
+
 func (api *LifeOSAPI) saveEvent(ctx context.Context, event *LifeEvent) error {
 	// Insert into database
 	query := `
@@ -1683,13 +4004,14 @@ func (api *LifeOSAPI) loadEvent(ctx context.Context, eventID uuid.UUID) (*LifeEv
 			scale, guest_count, location, budget,
 			status, phase, completion_percentage,
 			preferences, constraints, custom_attributes, tags,
-			created_at, updated_at, confirmed_at, completed_at
+			created_at, updated_at, confirmed_at, completed_at,
+			required_services, pending_actions
 		FROM life_events
 		WHERE id = $1
 	`
 	
 	var event LifeEvent
-	var locationJSON, budgetJSON, signalsJSON, prefsJSON, constraintsJSON, customJSON []byte
+	var locationJSON, budgetJSON, signalsJSON, prefsJSON, constraintsJSON, customJSON, servicesJSON, actionsJSON []byte
 	
 	err := api.db.QueryRow(ctx, query, eventID).Scan(
 		&event.ID, &event.UserID, &event.EventType, &event.EventSubtype, &event.ClusterType,
@@ -1699,6 +4021,7 @@ func (api *LifeOSAPI) loadEvent(ctx context.Context, eventID uuid.UUID) (*LifeEv
 		&event.Status, &event.Phase, &event.CompletionPct,
 		&prefsJSON, &constraintsJSON, &customJSON, &event.Tags,
 		&event.CreatedAt, &event.UpdatedAt, &event.ConfirmedAt, &event.CompletedAt,
+		&servicesJSON, &actionsJSON,
 	)
 	
 	if err != nil {
@@ -1711,11 +4034,63 @@ func (api *LifeOSAPI) loadEvent(ctx context.Context, eventID uuid.UUID) (*LifeEv
 	json.Unmarshal(prefsJSON, &event.Preferences)
 	json.Unmarshal(constraintsJSON, &event.Constraints)
 	json.Unmarshal(customJSON, &event.CustomAttributes)
+	json.Unmarshal(servicesJSON, &event.RequiredServices)
+	json.Unmarshal(actionsJSON, &event.PendingActions)
 	
 	return &event, nil
 }
 
+func (api *LifeOSAPI) loadTaskStatuses(ctx context.Context, eventID uuid.UUID) (map[uuid.UUID]string, error) {
+	rows, err := api.db.Query(ctx, `
+		SELECT task_id, status FROM life_event_tasks WHERE event_id = $1
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	statuses := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var taskID uuid.UUID
+		var status string
+		if err := rows.Scan(&taskID, &status); err != nil {
+			return nil, err
+		}
+		statuses[taskID] = status
+	}
+	
+	return statuses, rows.Err()
+}
+
+func (api *LifeOSAPI) saveTaskStatus(ctx context.Context, eventID uuid.UUID, phase EventPhase, task PhaseTask) error {
+	var completedAt *time.Time
+	if task.Status == TaskStatusCompleted {
+		now := time.Now()
+		completedAt = &now
+	}
+	
+	_, err := api.db.Exec(ctx, `
+		INSERT INTO life_event_tasks (event_id, task_id, phase, title, status, completed_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (event_id, task_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			completed_at = EXCLUDED.completed_at,
+			updated_at = NOW()
+	`, eventID, task.ID, phase, task.Title, task.Status, completedAt)
+	
+	return err
+}
+
+// updateEvent saves the event and drops its cached plans
 func (api *LifeOSAPI) updateEvent(ctx context.Context, event *LifeEvent) error {
+	return api.orchestrationEngine.updateEvent(ctx, event)
+}
+
+// updateEvent saves the event and bumps UpdatedAt, which moves its plan to
+// a new cache key; older cached plans are dropped as well
+func (o *OrchestrationEngine) updateEvent(ctx context.Context, event *LifeEvent) error {
+	event.UpdatedAt = time.Now()
+	
 	query := `
 		UPDATE life_events SET
 			event_date = $2,
@@ -1729,23 +4104,34 @@ func (api *LifeOSAPI) updateEvent(ctx context.Context, event *LifeEvent) error {
 			completion_percentage = $10,
 			preferences = $11,
 			updated_at = $12,
-			confirmed_at = $13
+			confirmed_at = $13,
+			completed_at = $14,
+			required_services = $15,
+			pending_actions = $16
 		WHERE id = $1
 	`
 	
 	locationJSON, _ := json.Marshal(event.Location)
 	budgetJSON, _ := json.Marshal(event.Budget)
 	prefsJSON, _ := json.Marshal(event.Preferences)
+	servicesJSON, _ := json.Marshal(event.RequiredServices)
+	actionsJSON, _ := json.Marshal(event.PendingActions)
 	
-	_, err := api.db.Exec(ctx, query,
+	_, err := o.db.Exec(ctx, query,
 		event.ID,
 		event.EventDate, event.EventDateFlex,
 		event.Scale, event.GuestCount, locationJSON, budgetJSON,
 		event.Status, event.Phase, event.CompletionPct,
-		prefsJSON, event.UpdatedAt, event.ConfirmedAt,
+		prefsJSON, event.UpdatedAt, event.ConfirmedAt, event.CompletedAt,
+		servicesJSON, actionsJSON,
 	)
+	if err != nil {
+		return err
+	}
 	
-	return err
+	// Best effort: plans under the old key expire with their TTL anyway
+	_ = o.InvalidatePlans(ctx, event.ID)
+	return nil
 }
 
 /*
@@ -1827,6 +4213,5 @@ type BookingService struct{}
 type NotificationService struct{}
 type PricingEngine struct{}
 type EventScheduler struct{}
-type MLEventPredictor struct{}
 type BookedService struct{}
 type SuggestedBundle struct{}
@@ -2,6 +2,7 @@
 package payments
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,13 +14,16 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
 	"github.com/BillyRonksGlobal/vendorplatform/internal/payment"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/middleware"
 )
 
 // Handler handles payment HTTP requests
 type Handler struct {
 	paymentService *payment.Service
 	logger         *zap.Logger
+	auth           gin.HandlerFunc
 }
 
 // NewHandler creates a new payment handler
@@ -30,14 +34,23 @@ func NewHandler(paymentService *payment.Service, logger *zap.Logger) *Handler {
 	}
 }
 
+// SetAuthMiddleware sets the middleware that authenticates callers of
+// protected routes; it must be set before RegisterRoutes, and without it
+// those routes reject every request
+func (h *Handler) SetAuthMiddleware(mw gin.HandlerFunc) {
+	h.auth = mw
+}
+
 // RegisterRoutes registers payment routes
 func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	payments := router.Group("/payments")
 	{
 		payments.POST("/initialize", h.InitializePayment)
 		payments.GET("/:id", h.GetTransaction)
+		payments.POST("/:id/refunds", middleware.RequireAuth(h.auth), h.RefundPayment)
 		payments.POST("/verify/:reference", h.VerifyPayment)
 		payments.POST("/webhook/paystack", h.PaystackWebhook)
+		payments.POST("/webhooks/:provider", h.HandleWebhook)
 	}
 
 	wallets := router.Group("/wallets")
@@ -52,7 +65,7 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 
 	escrow := router.Group("/escrow")
 	{
-		escrow.POST("/:booking_id/release", h.ReleaseEscrow)
+		escrow.POST("/:booking_id/release", middleware.RequireAuth(h.auth), h.ReleaseEscrow)
 		escrow.POST("/:booking_id/refund", h.RefundEscrow)
 	}
 }
@@ -290,6 +303,78 @@ func (h *Handler) PaystackWebhook(c *gin.Context) {
 	})
 }
 
+// HandleWebhook handles signed webhooks from any supported provider. Each
+// provider event is applied once; a redelivered event is acknowledged
+// without being applied again.
+func (h *Handler) HandleWebhook(c *gin.Context) {
+	provider := payment.PaymentProvider(c.Param("provider"))
+	header, ok := payment.WebhookSignatureHeader(provider)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Unsupported payment provider",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.logger.Error("Failed to read webhook body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to read request body",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	result, err := h.paymentService.HandleWebhook(ctx, provider, body, c.GetHeader(header))
+	switch {
+	case errors.Is(err, payment.ErrInvalidSignature):
+		h.logger.Warn("Rejected webhook with invalid signature",
+			zap.String("provider", string(provider)),
+		)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid signature",
+		})
+		return
+	case errors.Is(err, payment.ErrInvalidWebhookEvent):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid webhook event",
+		})
+		return
+	case err != nil:
+		// A non-2xx response makes the provider retry the event
+		h.logger.Error("Failed to process webhook",
+			zap.Error(err),
+			zap.String("provider", string(provider)),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to process webhook",
+		})
+		return
+	}
+
+	if result.Duplicate {
+		h.logger.Info("Webhook event already processed",
+			zap.String("provider", string(provider)),
+			zap.String("event_id", result.Event.ID),
+		)
+		c.JSON(http.StatusOK, gin.H{
+			"status": "already_processed",
+		})
+		return
+	}
+
+	h.logger.Info("Webhook processed",
+		zap.String("provider", string(provider)),
+		zap.String("event_id", result.Event.ID),
+		zap.String("event", result.Event.Name),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+	})
+}
+
 // GetWallet retrieves a user's wallet
 func (h *Handler) GetWallet(c *gin.Context) {
 	userIDStr := c.Param("user_id")
@@ -415,7 +500,8 @@ func (h *Handler) RequestPayout(c *gin.Context) {
 	c.JSON(http.StatusOK, txn)
 }
 
-// ReleaseEscrow releases held funds to vendor
+// ReleaseEscrow releases held funds to vendor. Only the booking's customer
+// or an admin may release them.
 func (h *Handler) ReleaseEscrow(c *gin.Context) {
 	bookingIDStr := c.Param("booking_id")
 
@@ -427,13 +513,36 @@ func (h *Handler) ReleaseEscrow(c *gin.Context) {
 		return
 	}
 
+	userID, err := auth.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication required",
+		})
+		return
+	}
+	role, _ := auth.GetRoleFromContext(c)
+	releasedBy := payment.EscrowReleaser{
+		UserID: userID,
+		Admin:  role == auth.RoleAdmin || role == auth.RoleSuperAdmin,
+	}
+
 	ctx := c.Request.Context()
-	if err := h.paymentService.ReleaseEscrow(ctx, bookingID); err != nil {
+	release, err := h.paymentService.ReleaseEscrow(ctx, bookingID, releasedBy)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, payment.ErrEscrowNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, payment.ErrEscrowForbidden):
+			status = http.StatusForbidden
+		case errors.Is(err, payment.ErrEscrowDisputed), errors.Is(err, payment.ErrEscrowNotHeld):
+			status = http.StatusConflict
+		}
 		h.logger.Error("Failed to release escrow",
 			zap.Error(err),
 			zap.String("booking_id", bookingID.String()),
 		)
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(status, gin.H{
 			"error": fmt.Sprintf("Failed to release escrow: %v", err),
 		})
 		return
@@ -441,15 +550,98 @@ func (h *Handler) ReleaseEscrow(c *gin.Context) {
 
 	h.logger.Info("Escrow released",
 		zap.String("booking_id", bookingID.String()),
+		zap.String("reference", release.Reference),
 	)
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":     "success",
 		"message":    "Escrow funds released to vendor",
 		"booking_id": bookingID.String(),
+		"release":    release,
 	})
 }
 
+// RefundPayment refunds some or all of a captured payment through its
+// provider. Refunds are made by admins.
+func (h *Handler) RefundPayment(c *gin.Context) {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid payment ID",
+		})
+		return
+	}
+
+	role, err := auth.GetRoleFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication required",
+		})
+		return
+	}
+	if role != auth.RoleAdmin && role != auth.RoleSuperAdmin {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins can refund payments",
+		})
+		return
+	}
+
+	var body struct {
+		Amount float64 `json:"amount" binding:"required,gt=0"` // In major currency units
+		Reason string  `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	refund, err := h.paymentService.RefundPayment(ctx, paymentID, body.Amount, body.Reason)
+	if err != nil && refund != nil {
+		// The provider made the refund; recording it is left to the
+		// pending refund reconciliation
+		h.logger.Error("Refund made but not recorded",
+			zap.Error(err),
+			zap.String("payment_id", paymentID.String()),
+			zap.String("refund_id", refund.ID.String()),
+		)
+		c.JSON(http.StatusAccepted, refund)
+		return
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, payment.ErrPaymentNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, payment.ErrInvalidRefundAmount):
+			status = http.StatusBadRequest
+		case errors.Is(err, payment.ErrPaymentNotRefundable), errors.Is(err, payment.ErrRefundExceedsPayment):
+			status = http.StatusConflict
+		case errors.Is(err, payment.ErrRefundPending):
+			// The pending refund reconciliation finds out whether it was made
+			status = http.StatusAccepted
+		}
+		h.logger.Error("Failed to refund payment",
+			zap.Error(err),
+			zap.String("payment_id", paymentID.String()),
+		)
+		c.JSON(status, gin.H{
+			"error": fmt.Sprintf("Failed to refund payment: %v", err),
+		})
+		return
+	}
+
+	h.logger.Info("Payment refunded",
+		zap.String("payment_id", paymentID.String()),
+		zap.String("refund_id", refund.ID.String()),
+		zap.Int64("amount", refund.Amount),
+	)
+
+	c.JSON(http.StatusOK, refund)
+}
+
 // RefundEscrow refunds held funds to customer
 func (h *Handler) RefundEscrow(c *gin.Context) {
 	bookingIDStr := c.Param("booking_id")
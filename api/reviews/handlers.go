@@ -2,6 +2,8 @@
 package reviews
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -36,6 +38,7 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 		reviews.PUT("/:id", h.UpdateReview)
 		reviews.DELETE("/:id", h.DeleteReview)
 		reviews.POST("/:id/response", h.AddVendorResponse)
+		reviews.PUT("/:id/response", h.EditVendorResponse)
 		reviews.POST("/:id/vote", h.VoteHelpful)
 	}
 
@@ -82,6 +85,11 @@ func (h *Handler) CreateReview(c *gin.Context) {
 				"error":   "booking_not_completed",
 				"message": "Booking must be completed before reviewing",
 			})
+		case review.ErrBookingNotFound, review.ErrBookingWrongVendor:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_booking",
+				"message": err.Error(),
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "creation_failed",
@@ -415,6 +423,17 @@ func (h *Handler) DeleteReview(c *gin.Context) {
 
 // AddVendorResponse handles POST /api/v1/reviews/:id/response
 func (h *Handler) AddVendorResponse(c *gin.Context) {
+	h.vendorResponse(c, h.reviewService.AddVendorResponse, "Response added successfully")
+}
+
+// EditVendorResponse handles PUT /api/v1/reviews/:id/response
+func (h *Handler) EditVendorResponse(c *gin.Context) {
+	h.vendorResponse(c, h.reviewService.EditVendorResponse, "Response updated successfully")
+}
+
+// vendorResponse adds or edits the authenticated vendor user's reply to a
+// review with respond
+func (h *Handler) vendorResponse(c *gin.Context, respond func(context.Context, uuid.UUID, uuid.UUID, string) error, message string) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
@@ -455,36 +474,58 @@ func (h *Handler) AddVendorResponse(c *gin.Context) {
 		return
 	}
 
-	err = h.reviewService.AddVendorResponse(c.Request.Context(), id, vendorUUID, req.Response)
-	if err == review.ErrReviewNotFound {
+	err = respond(c.Request.Context(), id, vendorUUID, req.Response)
+	switch {
+	case err == nil:
+	case errors.Is(err, review.ErrReviewNotFound):
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "not_found",
 			"message": "Review not found",
 		})
 		return
-	}
-
-	if err == review.ErrUnauthorized {
+	case errors.Is(err, review.ErrReplyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "You have not responded to this review",
+		})
+		return
+	case errors.Is(err, review.ErrUnauthorized):
 		c.JSON(http.StatusForbidden, gin.H{
 			"error":   "forbidden",
 			"message": "You can only respond to reviews for your vendor",
 		})
 		return
-	}
-
-	if err != nil {
-		h.logger.Error("Failed to add vendor response", zap.Error(err))
+	case errors.Is(err, review.ErrReplyExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "duplicate_response",
+			"message": "You have already responded to this review",
+		})
+		return
+	case errors.Is(err, review.ErrReplyEditClosed):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "response_locked",
+			"message": "Your response can no longer be edited",
+		})
+		return
+	case errors.Is(err, review.ErrInvalidReviewData):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Response text is required",
+		})
+		return
+	default:
+		h.logger.Error("Failed to save vendor response", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "response_failed",
-			"message": "Failed to add vendor response",
+			"message": "Failed to save vendor response",
 		})
 		return
 	}
 
-	h.logger.Info("Vendor response added", zap.String("review_id", id.String()))
+	h.logger.Info("Vendor response saved", zap.String("review_id", id.String()))
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Response added successfully",
+		"message": message,
 	})
 }
 
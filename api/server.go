@@ -200,6 +200,7 @@ type GetRecommendationsRequest struct {
 	Limit           int      `json:"limit,omitempty"`
 	DiversityFactor float64  `json:"diversity_factor,omitempty"`
 	ExcludeIDs      []string `json:"exclude_ids,omitempty"`
+	Explain         bool     `json:"explain,omitempty"`
 }
 
 type BundleSuggestionRequest struct {
@@ -262,6 +263,8 @@ type RecommendationItem struct {
 	Score           float64                `json:"score"`
 	Position        int                    `json:"position"`
 	Explanation     string                 `json:"explanation"`
+	Reasons         []string               `json:"reasons,omitempty"`
+	ScoreBreakdown  map[string]float64     `json:"score_breakdown,omitempty"`
 	Entity          interface{}            `json:"entity,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -366,14 +369,16 @@ func (s *Server) handleGetRecommendations(w http.ResponseWriter, r *http.Request
 	items := make([]RecommendationItem, len(resp.Recommendations))
 	for i, rec := range resp.Recommendations {
 		items[i] = RecommendationItem{
-			ID:          rec.ID.String(),
-			Type:        string(rec.Type),
-			EntityType:  string(rec.EntityType),
-			EntityID:    rec.EntityID.String(),
-			Score:       rec.Score,
-			Position:    rec.Position,
-			Explanation: rec.ExplanationCopy,
-			Metadata:    rec.Metadata,
+			ID:             rec.ID.String(),
+			Type:           string(rec.Type),
+			EntityType:     string(rec.EntityType),
+			EntityID:       rec.EntityID.String(),
+			Score:          rec.Score,
+			Position:       rec.Position,
+			Explanation:    rec.ExplanationCopy,
+			Reasons:        rec.Reasons,
+			ScoreBreakdown: rec.ScoreBreakdown,
+			Metadata:       rec.Metadata,
 		}
 		
 		// Enrich with entity details
@@ -1194,6 +1199,7 @@ func (s *Server) convertToEngineRequest(req *GetRecommendationsRequest) *recomme
 		EventType:       req.EventType,
 		Limit:           req.Limit,
 		DiversityFactor: req.DiversityFactor,
+		Explain:         req.Explain,
 	}
 	
 	if req.UserID != "" {
@@ -1239,14 +1245,16 @@ func (s *Server) convertRecommendations(ctx context.Context, recs []recommendati
 	items := make([]RecommendationItem, len(recs))
 	for i, rec := range recs {
 		items[i] = RecommendationItem{
-			ID:          rec.ID.String(),
-			Type:        string(rec.Type),
-			EntityType:  string(rec.EntityType),
-			EntityID:    rec.EntityID.String(),
-			Score:       rec.Score,
-			Position:    rec.Position,
-			Explanation: rec.ExplanationCopy,
-			Metadata:    rec.Metadata,
+			ID:             rec.ID.String(),
+			Type:           string(rec.Type),
+			EntityType:     string(rec.EntityType),
+			EntityID:       rec.EntityID.String(),
+			Score:          rec.Score,
+			Position:       rec.Position,
+			Explanation:    rec.ExplanationCopy,
+			Reasons:        rec.Reasons,
+			ScoreBreakdown: rec.ScoreBreakdown,
+			Metadata:       rec.Metadata,
 		}
 		items[i].Entity = s.getEntityDetails(ctx, rec.EntityType, rec.EntityID)
 	}
@@ -2,36 +2,59 @@
 package vendornet
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
 	"github.com/BillyRonksGlobal/vendorplatform/internal/vendornet"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/middleware"
 )
 
+// PartnerMatcher finds partnership matches for a vendor; it is satisfied by
+// PartnershipMatchingEngine
+type PartnerMatcher interface {
+	FindPartnerMatches(ctx context.Context, vendorID uuid.UUID, limit int) ([]PartnerMatch, error)
+}
+
 // Handler handles VendorNet HTTP requests
 type Handler struct {
 	service *vendornet.Service
+	matcher PartnerMatcher
 	logger  *zap.Logger
+	auth    gin.HandlerFunc
 }
 
 // NewHandler creates a new VendorNet handler
-func NewHandler(service *vendornet.Service, logger *zap.Logger) *Handler {
+func NewHandler(service *vendornet.Service, matcher PartnerMatcher, logger *zap.Logger) *Handler {
 	return &Handler{
 		service: service,
+		matcher: matcher,
 		logger:  logger,
 	}
 }
 
+// SetAuthMiddleware sets the middleware that authenticates callers of
+// protected routes; it must be set before RegisterRoutes, and without it
+// those routes reject every request
+func (h *Handler) SetAuthMiddleware(mw gin.HandlerFunc) {
+	h.auth = mw
+}
+
 // RegisterRoutes registers VendorNet routes
 func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	vendornet := router.Group("/vendornet")
 	{
 		// Partnership routes
 		vendornet.GET("/partners/matches", h.GetPartnerMatches)
+		vendornet.GET("/vendors/:id/matches", h.GetVendorPartnerMatches)
 		vendornet.POST("/partnerships", h.CreatePartnership)
 		vendornet.GET("/partnerships/:id", h.GetPartnership)
 
@@ -39,9 +62,13 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 		vendornet.POST("/referrals", h.CreateReferral)
 		vendornet.GET("/referrals/:id", h.GetReferral)
 		vendornet.PUT("/referrals/:id/status", h.UpdateReferralStatus)
+		vendornet.GET("/referrals/fee-recommendation", h.GetReferralFeeRecommendation)
 
 		// Analytics routes
 		vendornet.GET("/analytics", h.GetNetworkAnalytics)
+
+		// Export routes
+		vendornet.GET("/export", middleware.RequireAuth(h.auth), h.ExportNetwork)
 	}
 }
 
@@ -93,6 +120,67 @@ func (h *Handler) GetPartnerMatches(c *gin.Context) {
 	})
 }
 
+// Bounds for the limit query parameter on vendor matches
+const (
+	defaultMatchLimit = 10
+	maxMatchLimit     = 50
+)
+
+// GetVendorPartnerMatches handles GET /api/v1/vendornet/vendors/:id/matches
+func (h *Handler) GetVendorPartnerMatches(c *gin.Context) {
+	vendorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Invalid vendor ID format",
+		})
+		return
+	}
+
+	limit := defaultMatchLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxMatchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_request",
+				"message": fmt.Sprintf("limit must be between 1 and %d", maxMatchLimit),
+			})
+			return
+		}
+	}
+
+	matches, err := h.matcher.FindPartnerMatches(c.Request.Context(), vendorID, limit)
+	if errors.Is(err, ErrNoNetworkProfile) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Vendor has no network profile",
+		})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to find partner matches",
+			zap.Error(err),
+			zap.String("vendor_id", vendorID.String()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "fetch_failed",
+			"message": "Failed to fetch partner matches",
+		})
+		return
+	}
+	if matches == nil {
+		matches = []PartnerMatch{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"matches": matches,
+			"count":   len(matches),
+		},
+	})
+}
+
 // CreatePartnership handles POST /api/v1/vendornet/partnerships
 func (h *Handler) CreatePartnership(c *gin.Context) {
 	var req vendornet.CreatePartnershipRequest
@@ -222,7 +310,7 @@ func (h *Handler) CreateReferral(c *gin.Context) {
 		errorCode := "creation_failed"
 		message := "Failed to create referral"
 
-		if err == vendornet.ErrInvalidReferralData {
+		if errors.Is(err, vendornet.ErrInvalidReferralData) {
 			statusCode = http.StatusBadRequest
 			errorCode = "invalid_data"
 			message = err.Error()
@@ -355,6 +443,51 @@ func (h *Handler) UpdateReferralStatus(c *gin.Context) {
 	})
 }
 
+// GetReferralFeeRecommendation handles GET /api/v1/vendornet/referrals/fee-recommendation
+func (h *Handler) GetReferralFeeRecommendation(c *gin.Context) {
+	vendorID, err := uuid.Parse(c.Query("vendor_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "A valid vendor_id query parameter is required",
+		})
+		return
+	}
+
+	stats, err := h.service.GetReferralFeeStats(c.Request.Context(), vendorID)
+	if err != nil {
+		h.logger.Error("Failed to get referral fee stats",
+			zap.Error(err),
+			zap.String("vendor_id", vendorID.String()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "fetch_failed",
+			"message": "Failed to recommend a referral fee",
+		})
+		return
+	}
+
+	norms := CategoryReferralNorms{MedianFeePercent: stats.CategoryMedianFee}
+	if stats.CategoryReceived > 0 {
+		norms.AcceptanceRate = float64(stats.CategoryAccepted) / float64(stats.CategoryReceived)
+	}
+	if stats.CategoryAccepted > 0 {
+		norms.ConversionRate = float64(stats.CategoryConverted) / float64(stats.CategoryAccepted)
+	}
+	recommendation := RecommendReferralFee(ReferralPerformance{
+		Received:  stats.Received,
+		Accepted:  stats.Accepted,
+		Converted: stats.Converted,
+	}, norms)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"recommendation": recommendation,
+		},
+	})
+}
+
 // GetNetworkAnalytics handles GET /api/v1/vendornet/analytics
 func (h *Handler) GetNetworkAnalytics(c *gin.Context) {
 	vendorIDStr := c.Query("vendor_id")
@@ -395,3 +528,86 @@ func (h *Handler) GetNetworkAnalytics(c *gin.Context) {
 		},
 	})
 }
+
+// ExportNetwork handles GET /api/v1/vendornet/export
+func (h *Handler) ExportNetwork(c *gin.Context) {
+	exportType := c.DefaultQuery("type", vendornet.ExportReferrals)
+	format := c.DefaultQuery("format", vendornet.ExportFormatCSV)
+
+	if _, err := vendornet.ExportColumns(exportType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "type must be one of referrals, partnerships, connections",
+		})
+		return
+	}
+	if format != vendornet.ExportFormatCSV && format != vendornet.ExportFormatJSON {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "format must be csv or json",
+		})
+		return
+	}
+
+	userID, err := auth.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "Authentication required",
+		})
+		return
+	}
+
+	account, err := h.service.GetExportAccount(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, vendornet.ErrVendorNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "No vendor account for this user",
+			})
+			return
+		}
+		h.logger.Error("Failed to load vendor for export", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "fetch_failed",
+			"message": "Failed to start export",
+		})
+		return
+	}
+	if !account.CanExport(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "forbidden",
+			"message": vendornet.ErrExportNotInPlan.Error(),
+		})
+		return
+	}
+	vendorID := account.VendorID
+
+	// Exports are always scoped to the caller's own vendor account
+	if requested := c.Query("vendor_id"); requested != "" && requested != vendorID.String() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "forbidden",
+			"message": "Cannot export another vendor's network",
+		})
+		return
+	}
+
+	contentType := "text/csv"
+	if format == vendornet.ExportFormatJSON {
+		contentType = "application/json"
+	}
+	filename := fmt.Sprintf("vendornet-%s-%s.%s", exportType, time.Now().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	writer, _ := vendornet.NewExportWriter(format, c.Writer)
+	if err := h.service.ExportNetwork(c.Request.Context(), vendorID, exportType, writer); err != nil {
+		// Headers are already sent, so the stream is simply truncated
+		h.logger.Error("Failed to export vendor network",
+			zap.Error(err),
+			zap.String("vendor_id", vendorID.String()),
+			zap.String("type", exportType),
+		)
+	}
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -44,6 +46,7 @@ import (
 	"github.com/BillyRonksGlobal/vendorplatform/internal/vendor"
 	"github.com/BillyRonksGlobal/vendorplatform/internal/vendornet"
 	"github.com/BillyRonksGlobal/vendorplatform/internal/worker"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/tracing"
 	"github.com/BillyRonksGlobal/vendorplatform/recommendation-engine"
 )
 
@@ -54,6 +57,9 @@ type Config struct {
 	RedisURL          string
 	ElasticsearchURL  string
 	Environment       string
+	OTLPEndpoint      string
+	OTLPInsecure      bool
+	TraceSampleRatio  float64
 }
 
 // App holds the application dependencies
@@ -75,6 +81,18 @@ func main() {
 	logger := initLogger(config.Environment)
 	defer logger.Sync()
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), &tracing.Config{
+		ServiceName: "vendorplatform",
+		Environment: config.Environment,
+		Endpoint:    config.OTLPEndpoint,
+		Insecure:    config.OTLPInsecure,
+		SampleRatio: config.TraceSampleRatio,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+
 	// Initialize database connection
 	db, err := initDatabase(config.DatabaseURL)
 	if err != nil {
@@ -146,6 +164,11 @@ func main() {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Flush any buffered spans
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Failed to flush traces", zap.Error(err))
+	}
+
 	logger.Info("Server exited gracefully")
 }
 
@@ -156,6 +179,9 @@ func loadConfig() *Config {
 		RedisURL:         getEnv("REDIS_URL", "redis://localhost:6379"),
 		ElasticsearchURL: getEnv("ELASTICSEARCH_URL", "http://localhost:9200"),
 		Environment:      getEnv("ENV", "development"),
+		OTLPEndpoint:     getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		OTLPInsecure:     getEnv("OTEL_EXPORTER_OTLP_INSECURE", "false") == "true",
+		TraceSampleRatio: getEnvFloat("OTEL_TRACES_SAMPLER_RATIO", 1.0),
 	}
 }
 
@@ -166,6 +192,15 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
 func initLogger(env string) *zap.Logger {
 	var logger *zap.Logger
 	var err error
@@ -281,6 +316,136 @@ func initWorkerService(db *pgxpool.Pool, cache *redis.Client, logger *zap.Logger
 	return service
 }
 
+// eventgptBookings books EventGPT's confirmed booking drafts through the
+// booking service
+type eventgptBookings struct {
+	service *booking.Service
+}
+
+func (b eventgptBookings) CreateBooking(ctx context.Context, req eventgptAPI.BookingRequest) (*eventgptAPI.ConfirmedBooking, error) {
+	created, err := b.service.CreateBooking(ctx, &booking.CreateBookingRequest{
+		UserID:        req.UserID,
+		ServiceID:     req.ServiceID,
+		ScheduledDate: req.Date,
+		Quantity:      1,
+		SourceType:    "eventgpt",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &eventgptAPI.ConfirmedBooking{
+		ID:          created.ID,
+		Reference:   created.BookingNumber,
+		TotalAmount: created.TotalAmount,
+	}, nil
+}
+
+// lifeosBookings keeps LifeOS events in step with the bookings made for them
+type lifeosBookings struct {
+	api *lifeosAPI.LifeOSAPI
+}
+
+func (b lifeosBookings) BookingCreated(ctx context.Context, tx pgx.Tx, created *booking.Booking) error {
+	_, err := b.api.RecordBookingSpend(ctx, tx, created.UserID, *created.LifeEventID, created.ServiceID, created.TotalAmount)
+	if errors.Is(err, lifeosAPI.ErrEventNotOwned) {
+		return booking.ErrLifeEventNotFound
+	}
+	return err
+}
+
+func (b lifeosBookings) BookingConfirmed(ctx context.Context, confirmed *booking.Booking) error {
+	_, err := b.api.ConfirmServiceBooking(ctx, *confirmed.LifeEventID, confirmed.ServiceID)
+	return err
+}
+
+func (b lifeosBookings) BookingCancelled(ctx context.Context, tx pgx.Tx, cancelled *booking.Booking) error {
+	return b.api.ReverseBookingSpend(ctx, tx, *cancelled.LifeEventID, cancelled.ServiceID, cancelled.TotalAmount)
+}
+
+// bookingRefunds refunds cancelled bookings through the payment service
+type bookingRefunds struct {
+	service *payment.Service
+}
+
+func (b bookingRefunds) RefundBooking(ctx context.Context, bookingID, refundID uuid.UUID, amount float64, reason string) error {
+	// Retries reuse refundID, so a refund already made is not made again
+	_, err := b.service.RefundBooking(ctx, bookingID, refundID, amount, reason)
+	return err
+}
+
+func (b bookingRefunds) SettleEscrow(ctx context.Context, bookingID uuid.UUID) error {
+	_, err := b.service.SettleCancelledEscrow(ctx, bookingID)
+	return err
+}
+
+// homerescueRefunds refunds emergency requests through the payment service
+type homerescueRefunds struct {
+	service *payment.Service
+}
+
+func (h homerescueRefunds) RefundHeld(ctx context.Context, request *homerescueAPI.EmergencyRequest, reason string) error {
+	refund, err := h.service.RefundEmergency(ctx, request.ID, reason)
+	if refund != nil {
+		// The provider made the refund; ReconcilePendingRefunds records it
+		return nil
+	}
+	return err
+}
+
+// homerescueNotifications sends tracking's proximity notifications through
+// the notification service
+type homerescueNotifications struct {
+	service *notification.Service
+	logger  *zap.Logger
+}
+
+func (h homerescueNotifications) NotifyCustomer(ctx context.Context, userID uuid.UUID, n *homerescueAPI.CustomerNotification) {
+	_, err := h.service.Send(ctx, notification.SendRequest{
+		UserID:   userID,
+		Type:     notification.TypeTechEnRoute,
+		Title:    n.Title,
+		Body:     n.Message,
+		Priority: notification.PriorityHigh,
+		Data: map[string]interface{}{
+			"emergency_id": n.RequestID.String(),
+			"kind":         n.Type,
+		},
+		// One of each kind per emergency
+		TargetID: n.RequestID.String() + ":" + n.Type,
+	})
+	if err != nil {
+		h.logger.Warn("Failed to notify customer", zap.Stringer("emergency_id", n.RequestID), zap.Error(err))
+	}
+}
+
+// lifeosNotifications tells event owners about lifecycle changes through the
+// notification service
+type lifeosNotifications struct {
+	service *notification.Service
+	logger  *zap.Logger
+}
+
+func (l lifeosNotifications) NotifyEventChange(ctx context.Context, event *lifeosAPI.LifeEvent, change *lifeosAPI.LifecycleChange) {
+	title, message := change.Notification(event)
+	_, err := l.service.Send(ctx, notification.SendRequest{
+		UserID:   event.UserID,
+		Type:     notification.TypeEventUpdate,
+		Title:    title,
+		Body:     message,
+		Priority: notification.PriorityNormal,
+		Data: map[string]interface{}{
+			"event_id": event.ID.String(),
+			"phase":    string(change.ToPhase),
+			"status":   string(change.ToStatus),
+		},
+		// One per state the event reaches
+		TargetID: fmt.Sprintf("%s:%s:%s", event.ID, change.ToPhase, change.ToStatus),
+	})
+	if err != nil {
+		l.logger.Warn("Failed to notify event owner", zap.Stringer("event_id", event.ID), zap.Error(err))
+	}
+}
+
 func (app *App) setupRouter() {
 	if app.config.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -290,6 +455,7 @@ func (app *App) setupRouter() {
 
 	// Middleware
 	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware())
 	router.Use(app.loggingMiddleware())
 	router.Use(app.corsMiddleware())
 
@@ -321,6 +487,10 @@ func (app *App) setupRouter() {
 		BCryptCost:         12,
 		MaxSessionsPerUser: 5,
 		VerificationExpiry: 24 * time.Hour,
+		MaxFailedLogins:    5,
+		FailedLoginWindow:  15 * time.Minute,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
 	}
 	authService := auth.NewService(app.db, app.cache, authConfig)
 	// Wire notification service to auth service for email sending via adapter
@@ -338,6 +508,17 @@ func (app *App) setupRouter() {
 		EscrowExpiryDays:     30,   // 30 days escrow expiry
 	}
 	paymentService := payment.NewService(app.db, app.cache, paymentConfig)
+	paymentService.SetRefundNotifier(payment.NewNotificationAdapter(notificationService))
+	app.workerService.RegisterHandler(worker.JobReleaseEscrow, func(ctx context.Context, job *worker.Job) error {
+		_, err := paymentService.SweepExpiredEscrows(ctx)
+		return err
+	})
+	app.workerService.ScheduleCron("0 0 6 * * *", worker.JobReleaseEscrow, nil)
+	app.workerService.RegisterHandler(worker.JobReconcilePayments, func(ctx context.Context, job *worker.Job) error {
+		_, err := paymentService.ReconcilePendingRefunds(ctx)
+		return err
+	})
+	app.workerService.ScheduleCron("0 */15 * * * *", worker.JobReconcilePayments, nil)
 
 	vendorService := vendor.NewService(app.db, app.cache)
 	serviceManager := service.NewServiceManager(app.db, app.cache)
@@ -345,6 +526,13 @@ func (app *App) setupRouter() {
 	homerescueService := homerescue.NewService(app.db, app.cache, app.logger)
 	lifeosService := lifeos.NewService(app.db, app.cache)
 	bookingService := booking.NewService(app.db, app.cache)
+	bookingService.SetRescheduleNotifier(booking.NewNotificationAdapter(notificationService))
+	bookingService.SetRefunder(bookingRefunds{service: paymentService})
+	app.workerService.RegisterHandler(worker.JobRefundPayment, func(ctx context.Context, job *worker.Job) error {
+		_, err := bookingService.SettlePendingRefunds(ctx)
+		return err
+	})
+	app.workerService.ScheduleCron("0 */10 * * * *", worker.JobRefundPayment, nil)
 	reviewService := review.NewService(app.db, app.cache)
 
 	// Initialize EventGPT service
@@ -361,20 +549,46 @@ func (app *App) setupRouter() {
 	searchConfig := &search.Config{
 		ElasticsearchURL: app.config.ElasticsearchURL,
 		IndexPrefix:      getEnv("SEARCH_INDEX_PREFIX", "vendorplatform_"),
-		CacheTTL:         5 * time.Minute,
+		CacheTTL:         search.DefaultCacheTTL,
 	}
 	searchService := search.NewService(app.db, app.cache, searchConfig)
 
 	// Initialize handlers
 	authHandler := apiauth.NewHandler(authService, app.logger)
 	paymentHandler := payments.NewHandler(paymentService, app.logger)
+	paymentHandler.SetAuthMiddleware(authService.AuthMiddleware())
 	vendorHandler := vendors.NewHandler(vendorService, serviceManager, app.logger)
-	vendornetHandler := vendornetAPI.NewHandler(vendornetService, app.logger)
-	homerescueHandler := homerescueAPI.NewHandler(homerescueService, app.logger)
-	lifeosHandler := lifeosAPI.NewHandler(lifeosService, app.logger)
+	vendornetHandler := vendornetAPI.NewHandler(vendornetService, vendornetAPI.NewPartnershipMatchingEngine(app.db, app.cache), app.logger)
+	vendornetHandler.SetAuthMiddleware(authService.AuthMiddleware())
+	dispatchEngine := homerescueAPI.NewDispatchEngine(app.db, app.cache)
+	if err := dispatchEngine.RehydrateActiveState(context.Background()); err != nil {
+		app.logger.Error("Failed to resume in-flight dispatches", zap.Error(err))
+	}
+	dispatchEngine.SetPaymentService(homerescueRefunds{service: paymentService})
+	app.workerService.RegisterHandler(worker.JobCheckSLABreaches, func(ctx context.Context, job *worker.Job) error {
+		_, err := dispatchEngine.CheckSLABreaches(ctx)
+		return err
+	})
+	app.workerService.ScheduleCron("0 * * * * *", worker.JobCheckSLABreaches, nil)
+	homerescueHandler := homerescueAPI.NewHandler(homerescueService, dispatchEngine, app.logger)
+	homerescueHandler.SetAuthMiddleware(authService.AuthMiddleware())
+	homerescueHandler.SetIdempotencyCache(app.cache)
+	trackingService := homerescueAPI.NewTrackingService(app.db, app.cache)
+	homerescueHandler.SetTrackingSubscriber(trackingService)
+	trackingService.SetCustomerNotifier(homerescueNotifications{service: notificationService, logger: app.logger})
+	homerescueHandler.SetLocationPublisher(trackingService)
+	lifeosPlatform := lifeosAPI.NewLifeOSAPI(app.db, app.cache)
+	lifeosPlatform.SetNotifier(lifeosNotifications{service: notificationService, logger: app.logger})
+	bookingService.SetEventRecorder(lifeosBookings{api: lifeosPlatform})
+	lifeosHandler := lifeosAPI.NewHandler(lifeosService, lifeosPlatform, app.logger)
 	bookingHandler := bookings.NewHandler(bookingService, app.logger)
+	bookingHandler.SetAuthMiddleware(authService.AuthMiddleware())
 	reviewHandler := reviews.NewHandler(reviewService, app.logger)
-	eventgptHandler := eventgptAPI.NewHandler(eventgptService, app.logger)
+	eventgptChat := eventgptAPI.NewEventGPTAPI(app.db, app.cache)
+	eventgptChat.DialogManager().SetBookingService(eventgptBookings{service: bookingService})
+	eventgptChat.DialogManager().SetAvailabilityChecker(bookingService)
+	eventgptHandler := eventgptAPI.NewHandler(eventgptService, eventgptChat, eventgptAPI.NewPostgresSavedVendors(app.db), eventgptAPI.NewPostgresHandoffStore(app.db), app.logger)
+	eventgptHandler.SetOptionalAuthMiddleware(authService.OptionalAuthMiddleware())
 	searchHandler := searchAPI.NewHandler(searchService, app.logger)
 	workerHandler := workerAPI.NewHandler(app.workerService, app.logger)
 
@@ -384,9 +598,6 @@ func (app *App) setupRouter() {
 		// Authentication (public)
 		authHandler.RegisterRoutes(v1)
 
-		// Payment Processing
-		paymentHandler.RegisterRoutes(v1)
-
 		// Vendor Management
 		vendorHandler.RegisterRoutes(v1)
 
@@ -433,6 +644,9 @@ func (app *App) setupRouter() {
 			recommendations.GET("/services", app.getServiceRecommendations)
 			recommendations.GET("/vendors", app.getVendorRecommendations)
 			recommendations.GET("/bundles", app.getBundleRecommendations)
+			recommendations.POST("/batch", app.getBatchRecommendations)
+			recommendations.GET("/:id/explain", app.explainRecommendation)
+			recommendations.POST("/feedback", app.recordRecommendationFeedback)
 		}
 	}
 
@@ -524,56 +738,36 @@ func (app *App) getServiceRecommendations(c *gin.Context) {
 	categoryID := c.Query("category_id")
 	serviceID := c.Query("service_id")
 	eventType := c.Query("event_type")
-	userID := c.Query("user_id")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 10
-	}
+	verr := &recommendation.ValidationError{}
 
 	// Build recommendation request
 	req := &recommendation.RecommendationRequest{
+		UserID:    queryUUID(c, verr, "user_id"),
 		EventType: eventType,
-		Limit:     limit,
+		Limit:     queryLimit(c, verr, recommendation.DefaultLimit),
+		Location:  queryLocation(c, verr),
+		Cursor:    c.Query("cursor"),
 		RequestedTypes: []recommendation.RecommendationType{
 			recommendation.AdjacentService,
 			recommendation.EventBasedSuggest,
 		},
 	}
 
-	// Parse user ID if provided
-	if userID != "" {
-		if uid, err := uuid.Parse(userID); err == nil {
-			req.UserID = uid
-		}
-	}
-
 	// Parse current entity context
 	if serviceID != "" {
-		if sid, err := uuid.Parse(serviceID); err == nil {
+		if sid := queryUUID(c, verr, "service_id"); sid != uuid.Nil {
 			req.CurrentEntityID = sid
 			req.CurrentEntityType = recommendation.EntityService
 		}
 	} else if categoryID != "" {
-		if cid, err := uuid.Parse(categoryID); err == nil {
+		if cid := queryUUID(c, verr, "category_id"); cid != uuid.Nil {
 			req.CurrentEntityID = cid
 			req.CurrentEntityType = recommendation.EntityCategory
 		}
 	}
 
-	// Parse location if provided
-	latStr := c.Query("latitude")
-	lonStr := c.Query("longitude")
-	if latStr != "" && lonStr != "" {
-		if lat, errLat := strconv.ParseFloat(latStr, 64); errLat == nil {
-			if lon, errLon := strconv.ParseFloat(lonStr, 64); errLon == nil {
-				req.Location = &recommendation.GeoPoint{
-					Latitude:  lat,
-					Longitude: lon,
-				}
-			}
-		}
+	if rejectInvalidRecommendationRequest(c, req, verr) {
+		return
 	}
 
 	// Get recommendations from engine
@@ -598,6 +792,177 @@ func (app *App) getServiceRecommendations(c *gin.Context) {
 		"total_candidates": resp.TotalCandidates,
 		"processing_time_ms": resp.ProcessingTimeMs,
 		"algorithm_version": resp.AlgorithmVersion,
+		"next_cursor": resp.NextCursor,
+	})
+}
+
+// queryUUID parses an optional UUID query parameter, recording a field error
+// if it is present but malformed
+func queryUUID(c *gin.Context, verr *recommendation.ValidationError, name string) uuid.UUID {
+	raw := c.Query(name)
+	if raw == "" {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		verr.Add(name, "must be a valid UUID")
+		return uuid.Nil
+	}
+	return id
+}
+
+// queryLimit parses the limit query parameter, falling back to def when it
+// is absent
+func queryLimit(c *gin.Context, verr *recommendation.ValidationError, def int) int {
+	raw := c.Query("limit")
+	if raw == "" {
+		return def
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil {
+		verr.Add("limit", "must be a whole number")
+		return def
+	}
+	return limit
+}
+
+// queryLocation parses the latitude and longitude query parameters, which
+// must be given together
+func queryLocation(c *gin.Context, verr *recommendation.ValidationError) *recommendation.GeoPoint {
+	latStr, lonStr := c.Query("latitude"), c.Query("longitude")
+	if latStr == "" && lonStr == "" {
+		return nil
+	}
+	if latStr == "" || lonStr == "" {
+		verr.Add("location", "latitude and longitude must be given together")
+		return nil
+	}
+
+	lat, errLat := strconv.ParseFloat(latStr, 64)
+	if errLat != nil {
+		verr.Add("location.latitude", "must be a number")
+	}
+	lon, errLon := strconv.ParseFloat(lonStr, 64)
+	if errLon != nil {
+		verr.Add("location.longitude", "must be a number")
+	}
+	if errLat != nil || errLon != nil {
+		return nil
+	}
+	return &recommendation.GeoPoint{Latitude: lat, Longitude: lon}
+}
+
+// rejectInvalidRecommendationRequest validates req on top of any parse errors
+// already in verr, and answers 400 with every failing field if there are any
+func rejectInvalidRecommendationRequest(c *gin.Context, req *recommendation.RecommendationRequest, verr *recommendation.ValidationError) bool {
+	var invalid *recommendation.ValidationError
+	if errors.As(req.Validate(), &invalid) {
+		verr.Errors = append(verr.Errors, invalid.Errors...)
+	}
+	if !verr.HasErrors() {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, verr)
+	return true
+}
+
+// explainRecommendation breaks down why a previously served recommendation
+// appeared, from the score factors logged with its impression
+func (app *App) explainRecommendation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recommendation ID"})
+		return
+	}
+
+	explanation, err := app.recommendationEngine.ExplainRecommendation(c.Request.Context(), id)
+	if errors.Is(err, recommendation.ErrExplanationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No recorded impression for this recommendation"})
+		return
+	}
+	if err != nil {
+		app.logger.Error("Failed to explain recommendation",
+			zap.Error(err),
+			zap.String("recommendation_id", id.String()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to explain recommendation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+// recordRecommendationFeedback records a view, click, dismissal or
+// conversion on a served recommendation
+func (app *App) recordRecommendationFeedback(c *gin.Context) {
+	var req recommendation.FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	feedback, err := app.recommendationEngine.RecordFeedback(c.Request.Context(), req)
+	switch {
+	case errors.Is(err, recommendation.ErrInvalidFeedbackAction),
+		errors.Is(err, recommendation.ErrFeedbackEntityMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, recommendation.ErrRecommendationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No recorded impression for this recommendation"})
+		return
+	case err != nil:
+		app.logger.Error("Failed to record recommendation feedback",
+			zap.Error(err),
+			zap.String("recommendation_id", req.RecommendationID.String()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record feedback"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, feedback)
+}
+
+// getBatchRecommendations runs several recommendation requests in one call
+func (app *App) getBatchRecommendations(c *gin.Context) {
+	var body struct {
+		Requests []*recommendation.RecommendationRequest `json:"requests" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	// All sub-requests share a single deadline
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	results, err := app.recommendationEngine.GetBatchRecommendations(ctx, body.Requests)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		app.logger.Warn("Batch recommendations had failed sub-requests",
+			zap.Int("failed", failed),
+			zap.Int("total", len(results)),
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"total":   len(results),
+		"failed":  failed,
 	})
 }
 
@@ -605,54 +970,34 @@ func (app *App) getServiceRecommendations(c *gin.Context) {
 func (app *App) getVendorRecommendations(c *gin.Context) {
 	vendorID := c.Query("vendor_id")
 	categoryID := c.Query("category_id")
-	userID := c.Query("user_id")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 10
-	}
+	verr := &recommendation.ValidationError{}
 
 	// Build recommendation request
 	req := &recommendation.RecommendationRequest{
-		Limit: limit,
+		UserID:   queryUUID(c, verr, "user_id"),
+		Limit:    queryLimit(c, verr, recommendation.DefaultLimit),
+		Location: queryLocation(c, verr),
+		Cursor:   c.Query("cursor"),
 		RequestedTypes: []recommendation.RecommendationType{
 			recommendation.SimilarVendor,
 		},
 	}
 
-	// Parse user ID if provided
-	if userID != "" {
-		if uid, err := uuid.Parse(userID); err == nil {
-			req.UserID = uid
-		}
-	}
-
 	// Parse vendor context
 	if vendorID != "" {
-		if vid, err := uuid.Parse(vendorID); err == nil {
+		if vid := queryUUID(c, verr, "vendor_id"); vid != uuid.Nil {
 			req.CurrentEntityID = vid
 			req.CurrentEntityType = recommendation.EntityVendor
 		}
 	} else if categoryID != "" {
-		if cid, err := uuid.Parse(categoryID); err == nil {
+		if cid := queryUUID(c, verr, "category_id"); cid != uuid.Nil {
 			req.CurrentEntityID = cid
 			req.CurrentEntityType = recommendation.EntityCategory
 		}
 	}
 
-	// Parse location if provided
-	latStr := c.Query("latitude")
-	lonStr := c.Query("longitude")
-	if latStr != "" && lonStr != "" {
-		if lat, errLat := strconv.ParseFloat(latStr, 64); errLat == nil {
-			if lon, errLon := strconv.ParseFloat(lonStr, 64); errLon == nil {
-				req.Location = &recommendation.GeoPoint{
-					Latitude:  lat,
-					Longitude: lon,
-				}
-			}
-		}
+	if rejectInvalidRecommendationRequest(c, req, verr) {
+		return
 	}
 
 	// Get recommendations from engine
@@ -676,16 +1021,14 @@ func (app *App) getVendorRecommendations(c *gin.Context) {
 		"total_candidates": resp.TotalCandidates,
 		"processing_time_ms": resp.ProcessingTimeMs,
 		"algorithm_version": resp.AlgorithmVersion,
+		"next_cursor": resp.NextCursor,
 	})
 }
 
 // getBundleRecommendations returns service bundle recommendations for events
 func (app *App) getBundleRecommendations(c *gin.Context) {
 	eventType := c.Query("event_type")
-	userID := c.Query("user_id")
-	projectID := c.Query("project_id")
 	budgetStr := c.Query("budget")
-	limitStr := c.DefaultQuery("limit", "5")
 
 	if eventType == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -694,15 +1037,15 @@ func (app *App) getBundleRecommendations(c *gin.Context) {
 		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 5
-	}
+	verr := &recommendation.ValidationError{}
 
 	// Build recommendation request
 	req := &recommendation.RecommendationRequest{
+		UserID:    queryUUID(c, verr, "user_id"),
+		ProjectID: queryUUID(c, verr, "project_id"),
 		EventType: eventType,
-		Limit:     limit,
+		Limit:     queryLimit(c, verr, 5),
+		Location:  queryLocation(c, verr),
 		RequestedTypes: []recommendation.RecommendationType{
 			recommendation.BundleSuggestion,
 			recommendation.EventBasedSuggest,
@@ -710,20 +1053,6 @@ func (app *App) getBundleRecommendations(c *gin.Context) {
 		DiversityFactor: 0.5, // Bundles should have good category diversity
 	}
 
-	// Parse user ID if provided
-	if userID != "" {
-		if uid, err := uuid.Parse(userID); err == nil {
-			req.UserID = uid
-		}
-	}
-
-	// Parse project ID if provided
-	if projectID != "" {
-		if pid, err := uuid.Parse(projectID); err == nil {
-			req.ProjectID = pid
-		}
-	}
-
 	// Parse budget if provided
 	if budgetStr != "" {
 		if budget, err := strconv.ParseFloat(budgetStr, 64); err == nil {
@@ -731,21 +1060,13 @@ func (app *App) getBundleRecommendations(c *gin.Context) {
 				Max:      budget,
 				Currency: "NGN", // Default to Nigerian Naira
 			}
+		} else {
+			verr.Add("budget", "must be a number")
 		}
 	}
 
-	// Parse location if provided
-	latStr := c.Query("latitude")
-	lonStr := c.Query("longitude")
-	if latStr != "" && lonStr != "" {
-		if lat, errLat := strconv.ParseFloat(latStr, 64); errLat == nil {
-			if lon, errLon := strconv.ParseFloat(lonStr, 64); errLon == nil {
-				req.Location = &recommendation.GeoPoint{
-					Latitude:  lat,
-					Longitude: lon,
-				}
-			}
-		}
+	if rejectInvalidRecommendationRequest(c, req, verr) {
+		return
 	}
 
 	// Get recommendations from engine
@@ -110,6 +110,8 @@ CREATE TABLE vendors (
     -- Location & Coverage
     headquarters_address_id UUID,
     service_location GEOGRAPHY(POINT, 4326),
+    service_latitude DECIMAL(10, 8), -- Mirrors service_location for nearby search without PostGIS
+    service_longitude DECIMAL(11, 8),
     service_radius_km DECIMAL(6, 2) DEFAULT 50,
     service_areas GEOGRAPHY(MULTIPOLYGON, 4326), -- Complex service boundaries
     covers_nationwide BOOLEAN DEFAULT FALSE,
@@ -127,6 +129,9 @@ CREATE TABLE vendors (
     
     -- Capacity & Availability
     max_concurrent_bookings INTEGER DEFAULT 5,
+    working_hours JSONB DEFAULT '{}', -- {"monday": {"open": "09:00", "close": "17:00"}}; empty = always open
+    booking_buffer_minutes INTEGER DEFAULT 0, -- Kept free between bookings
+    cancellation_policy JSONB, -- {"tiers": [{"min_notice_hours": 168, "refund_percent": 100}]}; NULL = platform default
     lead_time_hours INTEGER DEFAULT 24, -- Minimum notice required
     advance_booking_days INTEGER DEFAULT 90, -- Max days ahead
     instant_booking_enabled BOOLEAN DEFAULT FALSE,
@@ -172,6 +177,7 @@ CREATE TABLE vendors (
 
 CREATE INDEX idx_vendors_slug ON vendors(slug);
 CREATE INDEX idx_vendors_location ON vendors USING GIST(service_location);
+CREATE INDEX idx_vendors_lat_lng ON vendors(service_latitude, service_longitude);
 CREATE INDEX idx_vendors_service_areas ON vendors USING GIST(service_areas);
 CREATE INDEX idx_vendors_rating ON vendors(rating_average DESC, rating_count DESC);
 CREATE INDEX idx_vendors_active ON vendors(is_active, is_verified);
@@ -274,6 +280,7 @@ CREATE TABLE services (
     is_available BOOLEAN DEFAULT TRUE,
     availability_type VARCHAR(20) DEFAULT 'always', -- 'always', 'scheduled', 'on_request'
     lead_time_hours INTEGER,
+    cancellation_policy JSONB, -- Overrides the vendor's; see vendors.cancellation_policy
     
     -- Media
     images TEXT[],
@@ -645,7 +652,9 @@ CREATE TABLE bookings (
     confirmed_at TIMESTAMPTZ,
     completed_at TIMESTAMPTZ,
     cancelled_at TIMESTAMPTZ,
-    cancellation_reason TEXT
+    cancellation_reason TEXT,
+    cancelled_by VARCHAR(20), -- 'customer', 'vendor', 'admin'
+    refund_amount DECIMAL(12, 2)
 );
 
 CREATE INDEX idx_bookings_user ON bookings(user_id);
@@ -657,6 +666,20 @@ CREATE INDEX idx_bookings_status ON bookings(status);
 CREATE INDEX idx_bookings_source ON bookings(source_type);
 CREATE INDEX idx_bookings_number ON bookings(booking_number);
 
+-- Times a booking was moved from and to
+CREATE TABLE booking_reschedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    old_start TIMESTAMPTZ NOT NULL,
+    old_end TIMESTAMPTZ NOT NULL,
+    new_start TIMESTAMPTZ NOT NULL,
+    new_end TIMESTAMPTZ NOT NULL,
+    actor VARCHAR(50) NOT NULL, -- 'customer', 'vendor', 'admin'
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_booking_reschedules_booking ON booking_reschedules(booking_id, created_at);
+
 -- ============================================================================
 -- SECTION 4: USER BEHAVIOR & ANALYTICS TABLES
 -- ============================================================================
@@ -906,6 +929,7 @@ CREATE TABLE recommendation_events (
     -- Scores
     relevance_score DECIMAL(5, 4),
     diversity_score DECIMAL(5, 4),
+    score_factors JSONB, -- how the score was built, for explanations
     
     -- Outcome
     was_impressed BOOLEAN DEFAULT TRUE,
@@ -929,6 +953,22 @@ CREATE INDEX idx_rec_events_type ON recommendation_events(recommendation_type);
 CREATE INDEX idx_rec_events_entity ON recommendation_events(recommended_entity_type, recommended_entity_id);
 CREATE INDEX idx_rec_events_experiment ON recommendation_events(experiment_id, variant);
 
+-- ----------------------------------------------------------------------------
+-- 6.2 Recommendation Feedback (What users did with what they were shown)
+-- ----------------------------------------------------------------------------
+CREATE TABLE recommendation_feedback (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    recommendation_id UUID NOT NULL, -- recommendation_events.id
+    user_id UUID REFERENCES users(id),
+    entity_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('view', 'click', 'dismiss', 'convert')),
+    algorithm_version VARCHAR(20), -- of the request that served it, for CTR by version
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_rec_feedback_recommendation ON recommendation_feedback(recommendation_id);
+CREATE INDEX idx_rec_feedback_ctr ON recommendation_feedback(algorithm_version, action, created_at);
+
 -- ============================================================================
 -- SECTION 7: HELPER FUNCTIONS AND TRIGGERS
 -- ============================================================================
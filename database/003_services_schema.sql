@@ -26,6 +26,28 @@ CREATE INDEX idx_sessions_user_id ON sessions(user_id);
 CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
 CREATE INDEX idx_sessions_refresh_token ON sessions(refresh_token);
 
+-- Refresh tokens rotated out of a session. Presenting one again means it was
+-- stolen, and the session is revoked
+CREATE TABLE IF NOT EXISTS rotated_refresh_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY, -- SHA-256 of the token
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    rotated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_rotated_refresh_tokens_session_id ON rotated_refresh_tokens(session_id);
+
+-- Email verification tokens. Kept after use or expiry so either can be
+-- reported rather than treated as an unknown token
+CREATE TABLE IF NOT EXISTS email_verifications (
+    token_hash VARCHAR(64) PRIMARY KEY, -- SHA-256 of the token
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_verifications_user_id ON email_verifications(user_id);
+
 -- Add authentication columns to users if not exists
 DO $$ 
 BEGIN
@@ -170,6 +192,24 @@ CREATE INDEX idx_notifications_status ON notifications(status);
 CREATE INDEX idx_notifications_created_at ON notifications(created_at DESC);
 CREATE INDEX idx_notifications_unread ON notifications(user_id, channel) WHERE read_at IS NULL;
 
+-- Notifications whose delivery was given up on (permanent failure or retries exhausted)
+CREATE TABLE IF NOT EXISTS notification_dead_letters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    
+    type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+    permanent BOOLEAN NOT NULL DEFAULT FALSE,
+    
+    failed_at TIMESTAMPTZ DEFAULT NOW(),
+    replayed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notification_dead_letters_pending ON notification_dead_letters(failed_at) WHERE replayed_at IS NULL;
+
 -- Device tokens for push notifications
 CREATE TABLE IF NOT EXISTS device_tokens (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -255,6 +295,7 @@ CREATE TABLE IF NOT EXISTS emergency_technicians (
     rating DECIMAL(3,2) DEFAULT 0,
     completed_jobs INT DEFAULT 0,
     acceptance_rate DECIMAL(5,4) DEFAULT 0,
+    missed_offers INT DEFAULT 0,
     avg_response_time_minutes INT DEFAULT 0,
     avg_arrival_time_minutes INT DEFAULT 0,
     on_time_rate DECIMAL(5,4) DEFAULT 0,
@@ -273,10 +314,53 @@ CREATE TABLE IF NOT EXISTS emergency_technicians (
 );
 
 CREATE INDEX idx_emergency_techs_vendor ON emergency_technicians(vendor_id);
+
+-- Vendor rates layered over the category pricing defaults
+CREATE TABLE IF NOT EXISTS vendor_pricing_overrides (
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    category VARCHAR(30) NOT NULL,
+    overrides JSONB NOT NULL DEFAULT '{}', -- only the fields the vendor changed
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (vendor_id, category)
+);
 CREATE INDEX idx_emergency_techs_status ON emergency_technicians(current_status) WHERE is_online = TRUE;
 CREATE INDEX idx_emergency_techs_location ON emergency_technicians USING GIST(current_location);
 CREATE INDEX idx_emergency_techs_categories ON emergency_technicians USING GIN(categories);
 
+-- Public holidays, billed at the holiday labour rate. A NULL region applies
+-- nationwide; otherwise it names the state that observes the holiday.
+CREATE TABLE IF NOT EXISTS holidays (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    holiday_date DATE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    region VARCHAR(100),
+    
+    UNIQUE (holiday_date, name, region)
+);
+
+CREATE INDEX idx_holidays_date ON holidays(holiday_date);
+
+-- Fixed and Easter holidays; the Eid dates are added once the Federal
+-- Government announces them
+INSERT INTO holidays (holiday_date, name) VALUES
+    ('2026-01-01', 'New Year''s Day'),
+    ('2026-04-03', 'Good Friday'),
+    ('2026-04-06', 'Easter Monday'),
+    ('2026-05-01', 'Workers'' Day'),
+    ('2026-06-12', 'Democracy Day'),
+    ('2026-10-01', 'Independence Day'),
+    ('2026-12-25', 'Christmas Day'),
+    ('2026-12-26', 'Boxing Day'),
+    ('2027-01-01', 'New Year''s Day'),
+    ('2027-03-26', 'Good Friday'),
+    ('2027-03-29', 'Easter Monday'),
+    ('2027-05-01', 'Workers'' Day'),
+    ('2027-06-12', 'Democracy Day'),
+    ('2027-10-01', 'Independence Day'),
+    ('2027-12-25', 'Christmas Day'),
+    ('2027-12-26', 'Boxing Day')
+ON CONFLICT DO NOTHING;
+
 -- Emergency requests
 CREATE TABLE IF NOT EXISTS emergency_requests (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -294,6 +378,7 @@ CREATE TABLE IF NOT EXISTS emergency_requests (
     
     location JSONB NOT NULL,
     access_instructions TEXT,
+    contact_phone VARCHAR(20), -- E.164
     
     status VARCHAR(30) NOT NULL DEFAULT 'new',
     status_history JSONB DEFAULT '[]',
@@ -306,6 +391,7 @@ CREATE TABLE IF NOT EXISTS emergency_requests (
     arrival_deadline TIMESTAMPTZ,
     actual_response_time TIMESTAMPTZ,
     actual_arrival_time TIMESTAMPTZ,
+    sla_breached BOOLEAN DEFAULT FALSE,
     
     diagnosis_notes TEXT,
     work_performed TEXT,
@@ -335,6 +421,36 @@ CREATE INDEX idx_emergency_requests_urgency ON emergency_requests(urgency);
 CREATE INDEX idx_emergency_requests_category ON emergency_requests(category);
 CREATE INDEX idx_emergency_requests_created ON emergency_requests(created_at DESC);
 
+-- Dispatch progress, so a restarted server resumes searches where they were
+CREATE TABLE IF NOT EXISTS dispatch_state (
+    request_id UUID PRIMARY KEY REFERENCES emergency_requests(id) ON DELETE CASCADE,
+    current_search_radius_km DECIMAL(6,2) NOT NULL,
+    assignment_attempts INT NOT NULL DEFAULT 0,
+    last_attempt_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Escalated emergency requests waiting on a support agent
+CREATE TABLE IF NOT EXISTS emergency_support_queue (
+    request_id UUID PRIMARY KEY REFERENCES emergency_requests(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    category VARCHAR(50) NOT NULL,
+    urgency VARCHAR(20) NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    
+    created_at TIMESTAMPTZ NOT NULL, -- when the customer raised the request
+    escalated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    response_deadline TIMESTAMPTZ NOT NULL,
+    
+    claimed_by UUID REFERENCES users(id),
+    claimed_at TIMESTAMPTZ,
+    assigned_tech_id UUID REFERENCES emergency_technicians(id),
+    assigned_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_emergency_support_queue_open ON emergency_support_queue(urgency, created_at) WHERE assigned_tech_id IS NULL;
+
 -- -----------------------------------------------------------------------------
 -- CONVERSATION/CHAT TABLES (EventGPT)
 -- -----------------------------------------------------------------------------
@@ -351,10 +467,19 @@ CREATE TABLE IF NOT EXISTS conversations (
     
     slot_values JSONB DEFAULT '{}',
     short_term_memory JSONB DEFAULT '[]',
+    slots JSONB DEFAULT '{}',
+    context JSONB DEFAULT '{}',
     
+    -- Full message history, rewritten on every turn
+    messages JSONB NOT NULL DEFAULT '[]',
+    turn_count INTEGER NOT NULL DEFAULT 0,
+    
+    language VARCHAR(10) DEFAULT 'en',
     channel VARCHAR(20) DEFAULT 'web',
     
+    started_at TIMESTAMPTZ DEFAULT NOW(),
     last_message_at TIMESTAMPTZ,
+    ended_at TIMESTAMPTZ,
     created_at TIMESTAMPTZ DEFAULT NOW(),
     updated_at TIMESTAMPTZ DEFAULT NOW()
 );
@@ -386,6 +511,115 @@ CREATE TABLE IF NOT EXISTS messages (
 CREATE INDEX idx_messages_conversation ON messages(conversation_id);
 CREATE INDEX idx_messages_created ON messages(created_at);
 
+-- Usage events (model calls and action executions, for tier caps and billing)
+CREATE TABLE IF NOT EXISTS eventgpt_usage_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    
+    kind VARCHAR(20) NOT NULL, -- 'model_call', 'action'
+    name VARCHAR(50) NOT NULL, -- call purpose or action type
+    model VARCHAR(100),
+    prompt_tokens INTEGER DEFAULT 0,
+    completion_tokens INTEGER DEFAULT 0,
+    
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_eventgpt_usage_conversation ON eventgpt_usage_events(conversation_id);
+CREATE INDEX idx_eventgpt_usage_user_day ON eventgpt_usage_events(user_id, kind, created_at);
+
+-- Response processing times (for latency percentiles per intent and action)
+CREATE TABLE IF NOT EXISTS eventgpt_message_metrics (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    
+    intent VARCHAR(50) NOT NULL,
+    action_types TEXT[] DEFAULT '{}',
+    processing_time_ms INTEGER NOT NULL,
+    
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_eventgpt_message_metrics_created ON eventgpt_message_metrics(created_at);
+
+-- Vendors users saved from chat cards to revisit later
+CREATE TABLE IF NOT EXISTS eventgpt_saved_vendors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    vendor_id UUID NOT NULL,
+    service_id UUID,
+    
+    vendor_name VARCHAR(255) NOT NULL DEFAULT '',
+    service_name VARCHAR(255) NOT NULL DEFAULT '',
+    vendor_type VARCHAR(100) NOT NULL DEFAULT '',
+    conversation_id UUID REFERENCES conversations(id) ON DELETE SET NULL,
+    
+    saved_at TIMESTAMPTZ DEFAULT NOW(),
+    
+    UNIQUE (user_id, vendor_id)
+);
+
+CREATE INDEX idx_eventgpt_saved_vendors_user ON eventgpt_saved_vendors(user_id, saved_at DESC);
+
+-- Help articles EventGPT answers general questions from
+CREATE TABLE IF NOT EXISTS kb_articles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    tags TEXT[] DEFAULT '{}',
+    
+    published BOOLEAN DEFAULT TRUE,
+    
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_kb_articles_published ON kb_articles(published);
+
+-- Conversations EventGPT handed to a human agent; one per conversation
+CREATE TABLE IF NOT EXISTS support_handoffs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id UUID NOT NULL UNIQUE REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    
+    reason VARCHAR(50) NOT NULL,
+    last_intent VARCHAR(50) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_support_handoffs_status ON support_handoffs(status, created_at);
+
+-- City centres, for turning a location named in chat into coordinates
+CREATE TABLE IF NOT EXISTS cities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    state VARCHAR(100),
+    country_code CHAR(2) NOT NULL DEFAULT 'NG',
+    
+    latitude DECIMAL(9,6) NOT NULL,
+    longitude DECIMAL(9,6) NOT NULL,
+    
+    UNIQUE (name, state, country_code)
+);
+
+CREATE INDEX idx_cities_name ON cities(LOWER(name));
+
+INSERT INTO cities (name, state, latitude, longitude) VALUES
+    ('Lagos', 'Lagos', 6.524400, 3.379200),
+    ('Ikeja', 'Lagos', 6.601800, 3.351500),
+    ('Lekki', 'Lagos', 6.469800, 3.585200),
+    ('Abuja', 'FCT', 9.076500, 7.398600),
+    ('Ibadan', 'Oyo', 7.377500, 3.947000),
+    ('Abeokuta', 'Ogun', 7.147500, 3.361900),
+    ('Port Harcourt', 'Rivers', 4.815600, 7.049800),
+    ('Benin City', 'Edo', 6.335000, 5.627000),
+    ('Enugu', 'Enugu', 6.524900, 7.518100),
+    ('Kano', 'Kano', 12.002200, 8.592000)
+ON CONFLICT DO NOTHING;
+
 -- -----------------------------------------------------------------------------
 -- LIFE EVENTS TABLE (LifeOS)
 -- -----------------------------------------------------------------------------
@@ -484,7 +718,7 @@ CREATE TABLE IF NOT EXISTS referrals (
     event_date DATE,
     estimated_value BIGINT,
     
-    status VARCHAR(30) DEFAULT 'pending', -- 'pending', 'accepted', 'contacted', 'quoted', 'converted', 'lost'
+    status VARCHAR(30) DEFAULT 'pending', -- 'pending', 'accepted', 'contacted', 'quoted', 'converted', 'lost', 'expired'
     status_history JSONB DEFAULT '[]',
     
     fee_type VARCHAR(20), -- 'percentage', 'fixed', 'none'
@@ -505,6 +739,68 @@ CREATE INDEX idx_referrals_dest ON referrals(dest_vendor_id);
 CREATE INDEX idx_referrals_status ON referrals(status);
 CREATE INDEX idx_referrals_tracking ON referrals(tracking_code);
 
+-- Every referral sent or referral link followed, for multi-touch attribution
+CREATE TABLE IF NOT EXISTS referral_touches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    referral_id UUID NOT NULL REFERENCES referrals(id) ON DELETE CASCADE,
+    source_vendor_id UUID NOT NULL REFERENCES vendors(id),
+    dest_vendor_id UUID NOT NULL REFERENCES vendors(id),
+    
+    client_email VARCHAR(255) DEFAULT '',
+    client_phone VARCHAR(20) DEFAULT '',
+    channel VARCHAR(20) NOT NULL, -- 'referral', 'link', 'pool'
+    
+    touched_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_referral_touches_dest ON referral_touches(dest_vendor_id, touched_at);
+
+-- Fee credits recorded when a referral converts
+CREATE TABLE IF NOT EXISTS referral_attributions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    converted_referral_id UUID NOT NULL REFERENCES referrals(id) ON DELETE CASCADE,
+    credited_referral_id UUID NOT NULL REFERENCES referrals(id),
+    source_vendor_id UUID NOT NULL REFERENCES vendors(id),
+    
+    model VARCHAR(20) NOT NULL, -- 'first_touch', 'last_touch', 'split'
+    share DECIMAL(5,4) NOT NULL,
+    fee DECIMAL(12,2) NOT NULL,
+    
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_referral_attributions_converted ON referral_attributions(converted_referral_id);
+
+-- Referrals posted without a destination for any eligible vendor to claim
+CREATE TABLE IF NOT EXISTS referral_pool (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_vendor_id UUID NOT NULL REFERENCES vendors(id),
+    service_category_id UUID NOT NULL,
+    city VARCHAR(100) DEFAULT '',
+    state VARCHAR(100) DEFAULT '',
+    
+    client_name VARCHAR(100),
+    client_email VARCHAR(255),
+    client_phone VARCHAR(20),
+    
+    event_type VARCHAR(50),
+    event_date DATE,
+    estimated_value BIGINT,
+    notes TEXT,
+    
+    fee_type VARCHAR(20) NOT NULL, -- 'percentage', 'fixed', 'none'
+    fee_value DECIMAL(10,2) NOT NULL,
+    
+    claimed_by UUID REFERENCES vendors(id),
+    claimed_at TIMESTAMPTZ,
+    referral_id UUID REFERENCES referrals(id),
+    
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_referral_pool_open ON referral_pool(service_category_id, expires_at) WHERE claimed_by IS NULL;
+
 -- -----------------------------------------------------------------------------
 -- SUBSCRIPTIONS TABLE
 -- -----------------------------------------------------------------------------
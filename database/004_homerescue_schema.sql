@@ -29,6 +29,11 @@ CREATE TABLE IF NOT EXISTS emergencies (
     latitude DECIMAL(10, 8) NOT NULL,
     longitude DECIMAL(11, 8) NOT NULL,
     access_instructions TEXT,
+    contact_phone VARCHAR(20), -- E.164, e.g. +2348012345678
+
+    -- Structured intake
+    intake_answers JSONB DEFAULT '{}'::jsonb, -- question id -> answer
+    diagnosis_hints TEXT[] DEFAULT '{}',
 
     -- Status tracking
     status VARCHAR(50) NOT NULL DEFAULT 'new' CHECK (status IN (
@@ -31,6 +31,7 @@ CREATE TABLE IF NOT EXISTS life_events (
     status VARCHAR(20) NOT NULL DEFAULT 'confirmed',
     phase VARCHAR(30) NOT NULL DEFAULT 'discovery',
     completion_percentage DECIMAL(5,2) DEFAULT 0.0,
+    required_services JSONB DEFAULT '[]',
 
     -- Metadata
     custom_attributes JSONB DEFAULT '{}',
@@ -125,6 +126,73 @@ CREATE TABLE IF NOT EXISTS life_event_milestones (
     CONSTRAINT fk_milestones_category FOREIGN KEY (category_id) REFERENCES service_categories(id)
 );
 
+-- Plan Checklist Task Progress
+CREATE TABLE IF NOT EXISTS life_event_tasks (
+    event_id UUID NOT NULL,
+    task_id UUID NOT NULL,
+    phase VARCHAR(30) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (event_id, task_id),
+    CONSTRAINT fk_event_tasks_event FOREIGN KEY (event_id) REFERENCES life_events(id) ON DELETE CASCADE,
+    CHECK (status IN ('pending', 'completed'))
+);
+
+-- Plan Version Snapshots
+CREATE TABLE IF NOT EXISTS life_event_plan_versions (
+    event_id UUID NOT NULL,
+    version INTEGER NOT NULL,
+    plan JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (event_id, version),
+    CONSTRAINT fk_event_plan_versions_event FOREIGN KEY (event_id) REFERENCES life_events(id) ON DELETE CASCADE
+);
+
+-- Bundles users accepted for an event, with the discounts that applied
+CREATE TABLE IF NOT EXISTS life_event_bundle_acceptances (
+    event_id UUID NOT NULL,
+    bundle_id UUID NOT NULL,
+    vendors JSONB NOT NULL DEFAULT '[]',
+    regular_price DECIMAL(15, 2) NOT NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL,
+    pricing JSONB NOT NULL,
+    accepted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (event_id, bundle_id),
+    CONSTRAINT fk_event_bundle_acceptances_event FOREIGN KEY (event_id) REFERENCES life_events(id) ON DELETE CASCADE
+);
+
+-- Events from users' synced calendars, read by calendar event detection
+CREATE TABLE IF NOT EXISTS user_calendar_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    external_id VARCHAR(255),
+    title VARCHAR(500) NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    attendee_count INTEGER NOT NULL DEFAULT 0,
+    synced_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_user_calendar_events_external UNIQUE (user_id, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_calendar_events_user_start ON user_calendar_events(user_id, start_time);
+
+-- Per-event-type logistic weights for ML event prediction
+CREATE TABLE IF NOT EXISTS ml_event_weights (
+    event_type VARCHAR(50) PRIMARY KEY,
+    bias DOUBLE PRECISION NOT NULL DEFAULT 0,
+    recency_weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    frequency_weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cluster_weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    trained_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
 -- Indexes for performance
 CREATE INDEX IF NOT EXISTS idx_life_events_user_id ON life_events(user_id);
 CREATE INDEX IF NOT EXISTS idx_life_events_status ON life_events(status);
@@ -43,6 +43,7 @@ CREATE TABLE IF NOT EXISTS reviews (
     -- Vendor Response
     vendor_response TEXT,
     vendor_responded_at TIMESTAMPTZ,
+    vendor_response_edited_at TIMESTAMPTZ,
 
     -- Timestamps
     created_at TIMESTAMPTZ DEFAULT NOW(),
@@ -83,6 +83,9 @@ CREATE INDEX idx_escrow_vendor_id ON escrow_accounts(vendor_id);
 CREATE INDEX idx_escrow_status ON escrow_accounts(status);
 CREATE INDEX idx_escrow_expires_at ON escrow_accounts(expires_at);
 
+-- Who released an escrow; NULL for automatic release after expiry
+ALTER TABLE escrow_accounts ADD COLUMN IF NOT EXISTS released_by UUID;
+
 -- Payment methods - stored payment methods for users
 CREATE TABLE IF NOT EXISTS payment_methods (
     id UUID PRIMARY KEY,
@@ -162,6 +165,63 @@ CREATE INDEX idx_webhook_events_reference ON webhook_events(reference);
 CREATE INDEX idx_webhook_events_provider ON webhook_events(provider);
 CREATE INDEX idx_webhook_events_created_at ON webhook_events(created_at DESC);
 
+-- Processed webhook events - one row per provider event applied, so a
+-- redelivered event is acknowledged without being applied twice
+CREATE TABLE IF NOT EXISTS processed_webhook_events (
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    reference VARCHAR(255),
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, event_id)
+);
+
+CREATE INDEX idx_processed_webhook_events_reference ON processed_webhook_events(reference);
+
+-- Refunds - full or partial returns of a captured payment. Pending and
+-- completed refunds count against the payment's amount; failed ones do not
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY,
+    payment_id UUID NOT NULL REFERENCES transactions(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    booking_id UUID REFERENCES bookings(id),
+    provider VARCHAR(50) NOT NULL,
+
+    amount BIGINT NOT NULL CHECK (amount > 0),  -- In kobo/cents
+    currency VARCHAR(3) NOT NULL DEFAULT 'NGN',
+    reason TEXT,
+
+    status VARCHAR(50) NOT NULL CHECK (status IN ('pending', 'completed', 'failed')),
+    provider_ref VARCHAR(255),  -- Refund ID at the payment provider
+
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refunds_payment_id ON refunds(payment_id);
+CREATE INDEX idx_refunds_user_id ON refunds(user_id);
+
+-- Ledger entries - double-entry record of every money movement. The debits
+-- of each ledger transaction equal its credits, and a transaction is posted
+-- at most once: a repeated post conflicts on (transaction_id, line)
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY,
+    transaction_id VARCHAR(255) NOT NULL,  -- e.g. escrow_release:<escrow id>
+    line INTEGER NOT NULL,
+    account VARCHAR(255) NOT NULL,  -- e.g. wallet:<user id>, escrow:<booking id>, platform:fees
+
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount BIGINT NOT NULL CHECK (amount > 0),  -- In kobo/cents
+    currency VARCHAR(3) NOT NULL,
+    description TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (transaction_id, line)
+);
+
+CREATE INDEX idx_ledger_entries_account ON ledger_entries(account, currency);
+CREATE INDEX idx_ledger_entries_created_at ON ledger_entries(created_at DESC);
+
 -- Create updated_at trigger function if not exists
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
@@ -188,3 +248,4 @@ COMMENT ON TABLE escrow_accounts IS 'Escrow accounts for holding funds until ser
 COMMENT ON TABLE payment_methods IS 'Stored payment methods for quick checkout';
 COMMENT ON TABLE payouts IS 'Vendor payout/withdrawal requests';
 COMMENT ON TABLE webhook_events IS 'Payment provider webhook event log';
+COMMENT ON TABLE processed_webhook_events IS 'Provider webhook events already applied, for idempotent handling';
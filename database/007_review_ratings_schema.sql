@@ -0,0 +1,18 @@
+-- =============================================================================
+-- REVIEW RATINGS SCHEMA
+-- Vendor ratings weighted toward verified purchase reviews
+-- =============================================================================
+
+-- vendors.rating_average and rating_count are recomputed by the review
+-- service in the same transaction as each review write, weighting verified
+-- purchase reviews (reviews.is_verified) by a configurable multiplier. The
+-- unweighted trigger from 005_reviews_schema.sql would overwrite that, so it
+-- is dropped here.
+--
+-- With no trigger, nothing in the database maintains the aggregate. Any
+-- writer that changes reviews.rating or reviews.is_published without going
+-- through the review service (manual SQL, moderation scripts, bulk imports)
+-- leaves the vendor's rating stale until its next review write, or until
+-- review.Service.RecomputeVendorRating is run for that vendor.
+DROP TRIGGER IF EXISTS trigger_update_vendor_ratings ON reviews;
+DROP FUNCTION IF EXISTS update_vendor_ratings();
@@ -0,0 +1,11 @@
+-- =============================================================================
+-- BOOKING LIFE EVENTS SCHEMA
+-- Links bookings to the LifeOS event they were made for
+-- =============================================================================
+
+-- Confirming a booking with a life_event_id marks the event's required
+-- service for the booked service's category as booked and recomputes the
+-- event's completion
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS life_event_id UUID REFERENCES life_events(id);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_life_event ON bookings(life_event_id) WHERE life_event_id IS NOT NULL;
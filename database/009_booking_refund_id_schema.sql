@@ -0,0 +1,10 @@
+-- =============================================================================
+-- BOOKING REFUND ID SCHEMA
+-- Ties a cancelled booking's refund to its cancellation
+-- =============================================================================
+
+-- Cancelling a booking with money to refund reserves the refund's ID here,
+-- in the same transaction, so retrying the refund never refunds twice
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS refund_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_refund_id ON bookings(refund_id) WHERE refund_id IS NOT NULL;
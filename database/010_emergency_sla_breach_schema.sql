@@ -0,0 +1,11 @@
+-- =============================================================================
+-- EMERGENCY SLA BREACH SCHEMA
+-- Flags emergencies whose technician missed the arrival deadline
+-- =============================================================================
+
+-- Set once by the per-minute arrival SLA sweep, so each breach is refunded
+-- and reported to the customer once
+ALTER TABLE emergencies ADD COLUMN IF NOT EXISTS sla_breached BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_emergencies_arrival_sla ON emergencies(arrival_deadline)
+    WHERE status IN ('accepted', 'en_route') AND NOT sla_breached AND actual_arrival_time IS NULL;
@@ -0,0 +1,7 @@
+-- =============================================================================
+-- LIFE EVENT ACTIONS SCHEMA
+-- Keeps the actions generated when a life event changes phase
+-- =============================================================================
+
+-- Replaced on every phase change and shown with the event's plan until done
+ALTER TABLE life_events ADD COLUMN IF NOT EXISTS pending_actions JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,8 @@
+-- =============================================================================
+-- BOOKING TRANSACTION REFERENCE SCHEMA
+-- Records the payment provider's reference against a booking
+-- =============================================================================
+
+-- 004 declares this column, but its CREATE TABLE is skipped once the core
+-- schema has created bookings
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS transaction_ref VARCHAR(255);
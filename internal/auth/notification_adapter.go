@@ -64,6 +64,7 @@ func (a *NotificationAdapter) Send(ctx context.Context, req SendNotificationRequ
 		Data:     req.Data,
 		Priority: priority,
 		Channels: channels,
+		TargetID: req.TargetID,
 	}
 
 	_, err = a.service.Send(ctx, notifReq)
@@ -8,17 +8,21 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
@@ -52,6 +56,7 @@ const (
 	RoleCustomer    UserRole = "customer"
 	RoleVendor      UserRole = "vendor"
 	RoleTechnician  UserRole = "technician"
+	RoleSupport     UserRole = "support"
 	RoleAdmin       UserRole = "admin"
 	RoleSuperAdmin  UserRole = "superadmin"
 )
@@ -105,6 +110,10 @@ type Config struct {
 	BCryptCost          int
 	MaxSessionsPerUser  int
 	VerificationExpiry  time.Duration
+	MaxFailedLogins     int           // Failures within FailedLoginWindow before a lockout; 0 disables lockouts
+	FailedLoginWindow   time.Duration
+	LockoutDuration     time.Duration // First lockout; each further lockout doubles it
+	MaxLockoutDuration  time.Duration
 }
 
 // DefaultConfig returns default configuration
@@ -116,6 +125,10 @@ func DefaultConfig() *Config {
 		BCryptCost:         12,
 		MaxSessionsPerUser: 5,
 		VerificationExpiry: 24 * time.Hour,
+		MaxFailedLogins:    5,
+		FailedLoginWindow:  15 * time.Minute,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
 	}
 }
 
@@ -133,14 +146,18 @@ type SendNotificationRequest struct {
 	Data     map[string]interface{}
 	Priority string
 	Channels []string
+	TargetID string // Entity the notification is about; repeats are deduplicated
 }
 
 // Service handles authentication
 type Service struct {
-	db           *pgxpool.Pool
-	cache        *redis.Client
-	config       *Config
-	notification NotificationSender
+	db            *pgxpool.Pool
+	cache         *redis.Client
+	config        *Config
+	notification  NotificationSender
+	sessions      SessionStore
+	verifications VerificationStore
+	logins        *LoginLimiter
 }
 
 // NewService creates a new auth service
@@ -148,11 +165,22 @@ func NewService(db *pgxpool.Pool, cache *redis.Client, config *Config) *Service
 	if config == nil {
 		config = DefaultConfig()
 	}
-	return &Service{
+	s := &Service{
 		db:     db,
 		cache:  cache,
 		config: config,
+		logins: NewLoginLimiter(cache, config),
 	}
+	if db != nil {
+		s.sessions = NewPostgresSessionStore(db)
+		s.verifications = NewPostgresVerificationStore(db)
+	}
+	return s
+}
+
+// SetSessionStore sets where sessions and their refresh tokens are kept
+func (s *Service) SetSessionStore(store SessionStore) {
+	s.sessions = store
 }
 
 // SetNotificationService sets the notification service for sending emails
@@ -225,7 +253,7 @@ func (s *Service) Register(ctx context.Context, req RegisterRequest) (*User, err
 	}
 
 	// Generate verification token
-	verificationToken, err := s.generateVerificationToken(ctx, user.ID, "email")
+	verificationToken, err := s.IssueVerification(ctx, user.ID)
 	if err != nil {
 		// Log but don't fail - user is created
 		fmt.Printf("failed to generate verification token: %v\n", err)
@@ -251,6 +279,12 @@ type LoginRequest struct {
 
 // Login authenticates a user and returns tokens
 func (s *Service) Login(ctx context.Context, req LoginRequest, deviceInfo, ipAddress, userAgent string) (*TokenPair, *User, error) {
+	// Lockouts fail open if Redis is unavailable
+	identifier := strings.ToLower(req.Email)
+	if err := s.logins.Check(ctx, identifier); errors.Is(err, ErrAccountLocked) {
+		return nil, nil, err
+	}
+
 	// Find user by email
 	var user User
 	var passwordHash string
@@ -260,14 +294,14 @@ func (s *Service) Login(ctx context.Context, req LoginRequest, deviceInfo, ipAdd
 		       email_verified, phone_verified, avatar_url, created_at, updated_at, last_login_at
 		FROM users WHERE email = $1
 	`
-	err := s.db.QueryRow(ctx, query, strings.ToLower(req.Email)).Scan(
+	err := s.db.QueryRow(ctx, query, identifier).Scan(
 		&user.ID, &user.Email, &user.Phone, &passwordHash,
 		&user.FirstName, &user.LastName, &user.Role, &user.Status,
 		&user.EmailVerified, &user.PhoneVerified, &user.AvatarURL,
 		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
 	)
 	if err != nil {
-		return nil, nil, errors.New("invalid credentials")
+		return nil, nil, s.failLogin(ctx, identifier)
 	}
 
 	// Check status
@@ -277,19 +311,13 @@ func (s *Service) Login(ctx context.Context, req LoginRequest, deviceInfo, ipAdd
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)); err != nil {
-		return nil, nil, errors.New("invalid credentials")
+		return nil, nil, s.failLogin(ctx, identifier)
 	}
+	s.logins.Reset(ctx, identifier)
 
-	// Create session
-	session, err := s.createSession(ctx, user.ID, deviceInfo, ipAddress, userAgent)
+	tokens, err := s.IssueSession(ctx, user, deviceInfo, ipAddress, userAgent)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create session: %w", err)
-	}
-
-	// Generate tokens
-	tokens, err := s.generateTokenPair(user, session.ID)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate tokens: %w", err)
+		return nil, nil, err
 	}
 
 	// Update last login
@@ -298,24 +326,155 @@ func (s *Service) Login(ctx context.Context, req LoginRequest, deviceInfo, ipAdd
 	return tokens, &user, nil
 }
 
+// failLogin counts a failed login, returning the lockout it triggered or
+// invalid credentials
+func (s *Service) failLogin(ctx context.Context, identifier string) error {
+	if err := s.logins.Fail(ctx, identifier); errors.Is(err, ErrAccountLocked) {
+		return err
+	}
+	return errors.New("invalid credentials")
+}
+
+// =============================================================================
+// LOGIN LOCKOUT
+// =============================================================================
+
+// ErrAccountLocked is matched by every AccountLockedError
+var ErrAccountLocked = errors.New("account temporarily locked")
+
+// AccountLockedError rejects a login while its identifier is locked out
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("%s: try again in %s", ErrAccountLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *AccountLockedError) Is(target error) bool { return target == ErrAccountLocked }
+
+// lockoutHistoryTTL is how long past lockouts keep making the next one
+// longer without a successful login
+const lockoutHistoryTTL = 24 * time.Hour
+
+// LoginLimiter counts failed logins per identifier in Redis and locks the
+// identifier out after MaxFailedLogins failures within FailedLoginWindow.
+// Each lockout is twice as long as the last, up to MaxLockoutDuration.
+type LoginLimiter struct {
+	cache  *redis.Client
+	config *Config
+}
+
+// NewLoginLimiter creates a login limiter; without a cache it never locks
+func NewLoginLimiter(cache *redis.Client, config *Config) *LoginLimiter {
+	return &LoginLimiter{cache: cache, config: config}
+}
+
+func (l *LoginLimiter) enabled() bool {
+	return l.cache != nil && l.config.MaxFailedLogins > 0
+}
+
+func loginFailuresKey(identifier string) string { return "login:failures:" + identifier }
+func loginLockoutsKey(identifier string) string { return "login:lockouts:" + identifier }
+func loginLockedKey(identifier string) string   { return "login:locked:" + identifier }
+
+// LockoutDuration returns how long the nth lockout in a row lasts
+func (l *LoginLimiter) LockoutDuration(n int) time.Duration {
+	duration := l.config.LockoutDuration
+	for i := 1; i < n; i++ {
+		duration *= 2
+		if l.config.MaxLockoutDuration > 0 && duration >= l.config.MaxLockoutDuration {
+			break
+		}
+	}
+	if l.config.MaxLockoutDuration > 0 && duration > l.config.MaxLockoutDuration {
+		duration = l.config.MaxLockoutDuration
+	}
+	return duration
+}
+
+// Check returns an AccountLockedError while identifier is locked out
+func (l *LoginLimiter) Check(ctx context.Context, identifier string) error {
+	if !l.enabled() {
+		return nil
+	}
+	ttl, err := l.cache.PTTL(ctx, loginLockedKey(identifier)).Result()
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		return &AccountLockedError{RetryAfter: ttl}
+	}
+	return nil
+}
+
+// Fail counts a failed login, returning an AccountLockedError when it locks
+// identifier out
+func (l *LoginLimiter) Fail(ctx context.Context, identifier string) error {
+	if !l.enabled() {
+		return nil
+	}
+
+	failures, err := l.cache.Incr(ctx, loginFailuresKey(identifier)).Result()
+	if err != nil {
+		return err
+	}
+	if failures == 1 {
+		l.cache.Expire(ctx, loginFailuresKey(identifier), l.config.FailedLoginWindow)
+	}
+	if failures < int64(l.config.MaxFailedLogins) {
+		return nil
+	}
+
+	lockouts, err := l.cache.Incr(ctx, loginLockoutsKey(identifier)).Result()
+	if err != nil {
+		return err
+	}
+	l.cache.Expire(ctx, loginLockoutsKey(identifier), lockoutHistoryTTL)
+
+	duration := l.LockoutDuration(int(lockouts))
+	pipe := l.cache.TxPipeline()
+	pipe.Set(ctx, loginLockedKey(identifier), strconv.FormatInt(lockouts, 10), duration)
+	pipe.Del(ctx, loginFailuresKey(identifier))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	return &AccountLockedError{RetryAfter: duration}
+}
+
+// Reset clears identifier's failures and lockouts
+func (l *LoginLimiter) Reset(ctx context.Context, identifier string) error {
+	if l.cache == nil {
+		return nil
+	}
+	return l.cache.Del(ctx, loginFailuresKey(identifier), loginLockoutsKey(identifier), loginLockedKey(identifier)).Err()
+}
+
 // =============================================================================
 // SESSION MANAGEMENT
 // =============================================================================
 
-func (s *Service) createSession(ctx context.Context, userID uuid.UUID, deviceInfo, ipAddress, userAgent string) (*Session, error) {
-	// Check existing sessions and remove oldest if exceeds limit
-	var count int
-	s.db.QueryRow(ctx, "SELECT COUNT(*) FROM sessions WHERE user_id = $1", userID).Scan(&count)
-	
-	if count >= s.config.MaxSessionsPerUser {
-		// Delete oldest session
-		s.db.Exec(ctx, `
-			DELETE FROM sessions WHERE id = (
-				SELECT id FROM sessions WHERE user_id = $1 ORDER BY created_at ASC LIMIT 1
-			)
-		`, userID)
+// IssueSession starts a session for an authenticated user and returns its
+// first token pair. The user's oldest sessions are evicted to stay within
+// MaxSessionsPerUser.
+func (s *Service) IssueSession(ctx context.Context, user User, deviceInfo, ipAddress, userAgent string) (*TokenPair, error) {
+	if s.sessions == nil {
+		return nil, errors.New("session store not configured")
+	}
+
+	session, err := s.createSession(ctx, user.ID, deviceInfo, ipAddress, userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	tokens, err := s.generateTokenPair(user, session.ID, session.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
+	return tokens, nil
+}
 
+func (s *Service) createSession(ctx context.Context, userID uuid.UUID, deviceInfo, ipAddress, userAgent string) (*Session, error) {
 	// Generate refresh token
 	refreshToken, err := generateSecureToken(32)
 	if err != nil {
@@ -333,19 +492,9 @@ func (s *Service) createSession(ctx context.Context, userID uuid.UUID, deviceInf
 		CreatedAt:    time.Now(),
 	}
 
-	query := `
-		INSERT INTO sessions (id, user_id, refresh_token, device_info, ip_address, user_agent, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`
-	_, err = s.db.Exec(ctx, query,
-		session.ID, session.UserID, session.RefreshToken,
-		session.DeviceInfo, session.IPAddress, session.UserAgent,
-		session.ExpiresAt, session.CreatedAt,
-	)
-	if err != nil {
+	if err := s.sessions.CreateSession(ctx, session, s.config.MaxSessionsPerUser); err != nil {
 		return nil, err
 	}
-
 	return session, nil
 }
 
@@ -365,7 +514,7 @@ func (s *Service) LogoutAll(ctx context.Context, userID uuid.UUID) error {
 // TOKEN MANAGEMENT
 // =============================================================================
 
-func (s *Service) generateTokenPair(user User, sessionID uuid.UUID) (*TokenPair, error) {
+func (s *Service) generateTokenPair(user User, sessionID uuid.UUID, refreshToken string) (*TokenPair, error) {
 	now := time.Now()
 	expiresAt := now.Add(s.config.AccessTokenExpiry)
 
@@ -391,12 +540,6 @@ func (s *Service) generateTokenPair(user User, sessionID uuid.UUID) (*TokenPair,
 		return nil, err
 	}
 
-	// Generate refresh token
-	refreshToken, err := generateSecureToken(32)
-	if err != nil {
-		return nil, err
-	}
-
 	return &TokenPair{
 		AccessToken:  accessTokenString,
 		RefreshToken: refreshToken,
@@ -425,41 +568,184 @@ func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-// RefreshTokens refreshes the access token using a refresh token
+// RefreshTokens exchanges a refresh token for a new token pair. Each refresh
+// token can be used once: presenting one that was already rotated out means
+// it was stolen, so the session it belongs to is revoked.
 func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*TokenPair, error) {
-	// Find session by refresh token
+	if s.sessions == nil {
+		return nil, errors.New("session store not configured")
+	}
+
+	next, err := generateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session, user, err := s.sessions.RotateRefreshToken(ctx, refreshToken, next, time.Now())
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// Both the thief and the user hold tokens descended from this
+		// session; end it so neither can continue
+		if revokeErr := s.sessions.RevokeSession(ctx, session.ID); revokeErr != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", revokeErr)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status != StatusActive {
+		return nil, errors.New("account is not active")
+	}
+
+	return s.generateTokenPair(*user, session.ID, next)
+}
+
+// =============================================================================
+// SESSION STORES
+// =============================================================================
+
+// Refresh token errors
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token already used")
+)
+
+// SessionStore keeps sessions and the refresh tokens rotated through them
+type SessionStore interface {
+	// CreateSession stores a new session, first evicting the user's oldest
+	// sessions so they have at most maxSessions. A maxSessions of 0 or less
+	// means no limit.
+	CreateSession(ctx context.Context, session *Session, maxSessions int) error
+	// RotateRefreshToken replaces the session's current refresh token,
+	// presented, with next, and returns the session and its user. A token
+	// that was already rotated out returns ErrRefreshTokenReused with the
+	// session it belonged to.
+	RotateRefreshToken(ctx context.Context, presented, next string, now time.Time) (*Session, *User, error)
+	// RevokeSession deletes a session along with its rotated tokens
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
+}
+
+// hashToken is how single-use tokens are stored, so a leaked table or
+// cache cannot be replayed
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PostgresSessionStore keeps sessions in the sessions table and rotated-out
+// refresh tokens in rotated_refresh_tokens
+type PostgresSessionStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresSessionStore creates a database-backed session store
+func NewPostgresSessionStore(db *pgxpool.Pool) *PostgresSessionStore {
+	return &PostgresSessionStore{db: db}
+}
+
+// CreateSession locks the user's row, so concurrent logins cannot together
+// exceed maxSessions
+func (p *PostgresSessionStore) CreateSession(ctx context.Context, session *Session, maxSessions int) error {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if maxSessions > 0 {
+		if _, err := tx.Exec(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", session.UserID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			DELETE FROM sessions WHERE id IN (
+				SELECT id FROM sessions WHERE user_id = $1 ORDER BY created_at DESC OFFSET $2
+			)
+		`, session.UserID, maxSessions-1)
+		if err != nil {
+			return fmt.Errorf("failed to evict sessions: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO sessions (id, user_id, refresh_token, device_info, ip_address, user_agent, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, session.ID, session.UserID, session.RefreshToken,
+		session.DeviceInfo, session.IPAddress, session.UserAgent,
+		session.ExpiresAt, session.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RotateRefreshToken locks the session row, so a token raced by two
+// refreshes is rotated once and the loser is treated as a reuse
+func (p *PostgresSessionStore) RotateRefreshToken(ctx context.Context, presented, next string, now time.Time) (*Session, *User, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	var session Session
 	var user User
-	
-	query := `
-		SELECT s.id, s.user_id, s.expires_at, 
+	err = tx.QueryRow(ctx, `
+		SELECT s.id, s.user_id, s.expires_at, s.created_at,
 		       u.id, u.email, u.role, u.status
 		FROM sessions s
 		JOIN users u ON u.id = s.user_id
-		WHERE s.refresh_token = $1 AND s.expires_at > NOW()
-	`
-	err := s.db.QueryRow(ctx, query, refreshToken).Scan(
-		&session.ID, &session.UserID, &session.ExpiresAt,
+		WHERE s.refresh_token = $1
+		FOR UPDATE OF s
+	`, presented).Scan(
+		&session.ID, &session.UserID, &session.ExpiresAt, &session.CreatedAt,
 		&user.ID, &user.Email, &user.Role, &user.Status,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx,
+			"SELECT session_id FROM rotated_refresh_tokens WHERE token_hash = $1",
+			hashToken(presented),
+		).Scan(&session.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return &session, nil, ErrRefreshTokenReused
+	}
 	if err != nil {
-		return nil, errors.New("invalid refresh token")
+		return nil, nil, err
 	}
-
-	if user.Status != StatusActive {
-		return nil, errors.New("account is not active")
+	if !now.Before(session.ExpiresAt) {
+		return nil, nil, ErrInvalidRefreshToken
 	}
 
-	// Generate new tokens
-	tokens, err := s.generateTokenPair(user, session.ID)
+	_, err = tx.Exec(ctx,
+		"INSERT INTO rotated_refresh_tokens (token_hash, session_id, rotated_at) VALUES ($1, $2, $3)",
+		hashToken(presented), session.ID, now,
+	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	_, err = tx.Exec(ctx, "UPDATE sessions SET refresh_token = $1 WHERE id = $2", next, session.ID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Update session with new refresh token
-	s.db.Exec(ctx, "UPDATE sessions SET refresh_token = $1 WHERE id = $2", tokens.RefreshToken, session.ID)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	session.RefreshToken = next
+	return &session, &user, nil
+}
 
-	return tokens, nil
+// RevokeSession deletes a session; its rotated tokens cascade
+func (p *PostgresSessionStore) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := p.db.Exec(ctx, "DELETE FROM sessions WHERE id = $1", sessionID)
+	return err
 }
 
 // =============================================================================
@@ -482,29 +768,139 @@ func (s *Service) generateVerificationToken(ctx context.Context, userID uuid.UUI
 	return token, nil
 }
 
-// VerifyEmail verifies a user's email address
-func (s *Service) VerifyEmail(ctx context.Context, token string) error {
-	key := fmt.Sprintf("verify:email:%s", token)
-	userIDStr, err := s.cache.Get(ctx, key).Result()
-	if err != nil {
-		return errors.New("invalid or expired verification token")
+// Email verification errors
+var (
+	ErrInvalidVerificationToken = errors.New("invalid verification token")
+	ErrVerificationTokenExpired = errors.New("verification token has expired")
+	ErrVerificationTokenUsed    = errors.New("verification token has already been used")
+)
+
+// Verification is an email verification token, stored by its hash
+type Verification struct {
+	TokenHash string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// VerificationStore keeps email verification tokens
+type VerificationStore interface {
+	SaveVerification(ctx context.Context, verification *Verification) error
+	// ConsumeVerification marks the token used and its user's email
+	// verified in one step, returning ErrVerificationTokenExpired or
+	// ErrVerificationTokenUsed for a token that can no longer be used
+	ConsumeVerification(ctx context.Context, tokenHash string, now time.Time) (*Verification, error)
+}
+
+// SetVerificationStore sets where email verification tokens are kept
+func (s *Service) SetVerificationStore(store VerificationStore) {
+	s.verifications = store
+}
+
+// CheckVerificationUsable reports why a verification token cannot be used
+func CheckVerificationUsable(verification *Verification, now time.Time) error {
+	if verification.UsedAt != nil {
+		return ErrVerificationTokenUsed
+	}
+	if !now.Before(verification.ExpiresAt) {
+		return ErrVerificationTokenExpired
+	}
+	return nil
+}
+
+// IssueVerification creates a single-use email verification token for the
+// user, valid for VerificationExpiry. Only its hash is stored.
+func (s *Service) IssueVerification(ctx context.Context, userID uuid.UUID) (string, error) {
+	if s.verifications == nil {
+		return "", errors.New("verification store not configured")
 	}
 
-	userID, err := uuid.Parse(userIDStr)
+	token, err := generateSecureToken(32)
 	if err != nil {
-		return errors.New("invalid verification token")
+		return "", err
+	}
+
+	now := time.Now()
+	verification := &Verification{
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		ExpiresAt: now.Add(s.config.VerificationExpiry),
+		CreatedAt: now,
+	}
+	if err := s.verifications.SaveVerification(ctx, verification); err != nil {
+		return "", fmt.Errorf("failed to save verification token: %w", err)
+	}
+	return token, nil
+}
+
+// VerifyEmail verifies a user's email address and uses up the token
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	if s.verifications == nil {
+		return errors.New("verification store not configured")
 	}
+	_, err := s.verifications.ConsumeVerification(ctx, hashToken(token), time.Now())
+	return err
+}
+
+// PostgresVerificationStore keeps tokens in email_verifications
+type PostgresVerificationStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresVerificationStore creates a database-backed verification store
+func NewPostgresVerificationStore(db *pgxpool.Pool) *PostgresVerificationStore {
+	return &PostgresVerificationStore{db: db}
+}
 
-	// Update user
-	_, err = s.db.Exec(ctx, "UPDATE users SET email_verified = TRUE, status = 'active', updated_at = $1 WHERE id = $2", time.Now(), userID)
+// SaveVerification stores a new token
+func (p *PostgresVerificationStore) SaveVerification(ctx context.Context, verification *Verification) error {
+	_, err := p.db.Exec(ctx, `
+		INSERT INTO email_verifications (token_hash, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, verification.TokenHash, verification.UserID, verification.ExpiresAt, verification.CreatedAt)
+	return err
+}
+
+// ConsumeVerification locks the token row, so a token raced by two requests
+// verifies once
+func (p *PostgresVerificationStore) ConsumeVerification(ctx context.Context, tokenHash string, now time.Time) (*Verification, error) {
+	tx, err := p.db.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	verification := &Verification{TokenHash: tokenHash}
+	err = tx.QueryRow(ctx, `
+		SELECT user_id, expires_at, used_at, created_at
+		FROM email_verifications
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash).Scan(&verification.UserID, &verification.ExpiresAt, &verification.UsedAt, &verification.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckVerificationUsable(verification, now); err != nil {
+		return nil, err
 	}
 
-	// Delete token
-	s.cache.Del(ctx, key)
+	if _, err := tx.Exec(ctx, "UPDATE email_verifications SET used_at = $1 WHERE token_hash = $2", now, tokenHash); err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, "UPDATE users SET email_verified = TRUE, status = 'active', updated_at = $1 WHERE id = $2", now, verification.UserID)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	verification.UsedAt = &now
+	return verification, nil
 }
 
 // =============================================================================
@@ -562,13 +958,18 @@ func (s *Service) ResetPassword(ctx context.Context, token, newPassword string)
 	}
 
 	// Update password
-	_, err = s.db.Exec(ctx, "UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3", string(hash), time.Now(), userID)
+	var email string
+	err = s.db.QueryRow(ctx,
+		"UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3 RETURNING email",
+		string(hash), time.Now(), userID,
+	).Scan(&email)
 	if err != nil {
 		return err
 	}
 
-	// Invalidate all sessions
+	// Invalidate all sessions and lift any lockout
 	s.LogoutAll(ctx, userID)
+	s.logins.Reset(ctx, email)
 
 	// Delete token
 	s.cache.Del(ctx, key)
@@ -643,6 +1044,20 @@ func (s *Service) AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware authenticates requests that carry an Authorization
+// header as AuthMiddleware does, and lets requests without one through
+// anonymously
+func (s *Service) OptionalAuthMiddleware() gin.HandlerFunc {
+	required := s.AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
+
 // RequireRole middleware checks if user has required role
 func RequireRole(roles ...UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -721,6 +1136,7 @@ func (s *Service) sendVerificationEmail(ctx context.Context, user *User, token s
 		},
 		Priority: "high",
 		Channels: []string{"email"},
+		TargetID: hashToken(token),
 	}
 
 	return s.notification.Send(ctx, req)
@@ -749,6 +1165,7 @@ func (s *Service) sendPasswordResetEmail(ctx context.Context, user *User, token
 		},
 		Priority: "high",
 		Channels: []string{"email"},
+		TargetID: hashToken(token),
 	}
 
 	return s.notification.Send(ctx, req)
@@ -0,0 +1,129 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/availability"
+)
+
+// ErrVendorNotFound is returned when checking an unknown vendor's availability
+var ErrVendorNotFound = errors.New("vendor not found")
+
+// AvailabilityResult is whether a vendor can take a booking, with other
+// open slots when they cannot
+type AvailabilityResult = availability.Result
+
+// bookingTimezone is the timezone bookings and working hours are kept in
+const bookingTimezone = "Africa/Lagos"
+
+// queryer runs availability queries on the pool or inside a transaction
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// CheckAvailability reports whether a vendor can take a booking from start to
+// end, given their working hours, the buffer they keep between jobs and
+// max_concurrent_bookings. A whole-day request asks about the vendor's
+// working hours that day.
+func (s *Service) CheckAvailability(ctx context.Context, vendorID uuid.UUID, start, end time.Time) (AvailabilityResult, error) {
+	vendor, err := availabilityVendor(ctx, s.db, vendorID, false)
+	if err != nil {
+		return AvailabilityResult{}, err
+	}
+
+	request := availability.Interval{Start: start, End: end}
+	search := availability.SearchWindow(vendor, request)
+	booked, err := bookedIntervals(ctx, s.db, vendorID, uuid.Nil, search, vendor.Location)
+	if err != nil {
+		return AvailabilityResult{}, err
+	}
+	return availability.Check(vendor, booked, request), nil
+}
+
+// availabilityVendor loads how a vendor takes bookings, locking the vendor
+// row when lock is set so their bookings change one at a time
+func availabilityVendor(ctx context.Context, q queryer, vendorID uuid.UUID, lock bool) (availability.Vendor, error) {
+	query := `
+		SELECT COALESCE(max_concurrent_bookings, 1), COALESCE(working_hours, '{}'),
+		       COALESCE(booking_buffer_minutes, 0), COALESCE(lead_time_hours, 0)
+		FROM vendors
+		WHERE id = $1
+	`
+	if lock {
+		query += " FOR UPDATE"
+	}
+	var maxConcurrent, bufferMinutes, leadTimeHours int
+	var hoursJSON []byte
+	err := q.QueryRow(ctx, query, vendorID).Scan(&maxConcurrent, &hoursJSON, &bufferMinutes, &leadTimeHours)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return availability.Vendor{}, ErrVendorNotFound
+	}
+	if err != nil {
+		return availability.Vendor{}, fmt.Errorf("failed to fetch vendor: %w", err)
+	}
+
+	hours, err := availability.ParseWorkingHours(hoursJSON)
+	if err != nil {
+		return availability.Vendor{}, err
+	}
+	location, err := time.LoadLocation(bookingTimezone)
+	if err != nil {
+		location = time.UTC
+	}
+	return availability.Vendor{
+		MaxConcurrent: maxConcurrent,
+		Buffer:        time.Duration(bufferMinutes) * time.Minute,
+		Hours:         hours,
+		Location:      location,
+		MinNotice:     time.Duration(leadTimeHours) * time.Hour,
+	}, nil
+}
+
+// bookedIntervals lists the times a vendor's active bookings, other than
+// exclude, take up within search. A booking without times takes up its whole
+// day.
+func bookedIntervals(ctx context.Context, q queryer, vendorID, exclude uuid.UUID, search availability.Interval, location *time.Location) ([]availability.Interval, error) {
+	rows, err := q.Query(ctx, `
+		SELECT
+			scheduled_date + COALESCE(scheduled_start_time, TIME '00:00'),
+			COALESCE(
+				scheduled_date + scheduled_end_time,
+				scheduled_date + scheduled_start_time + duration_minutes * INTERVAL '1 minute',
+				scheduled_date + INTERVAL '1 day'
+			)
+		FROM bookings
+		WHERE vendor_id = $1
+		  AND id <> $4
+		  AND status NOT IN ('cancelled', 'refunded')
+		  AND scheduled_date BETWEEN $2::date - 1 AND $3::date
+	`, vendorID, search.Start.In(location).Format("2006-01-02"), search.End.In(location).Format("2006-01-02"), exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var booked []availability.Interval
+	for rows.Next() {
+		var start, end time.Time
+		if err := rows.Scan(&start, &end); err != nil {
+			return nil, err
+		}
+		// Scheduled times are wall-clock times in the booking timezone
+		booked = append(booked, availability.Interval{
+			Start: wallClock(start, location),
+			End:   wallClock(end, location),
+		})
+	}
+	return booked, rows.Err()
+}
+
+func wallClock(t time.Time, location *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, location)
+}
@@ -0,0 +1,257 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/cancellation"
+)
+
+// Cancellation is a cancelled booking and what it refunds
+type Cancellation struct {
+	BookingID     uuid.UUID  `json:"booking_id"`
+	Actor         string     `json:"cancelled_by"`
+	Reason        string     `json:"reason"`
+	AmountPaid    float64    `json:"amount_paid"`
+	RefundPercent float64    `json:"refund_percent"`
+	RefundAmount  float64    `json:"refund_amount"`
+	Currency      string     `json:"currency"`
+	RefundID      *uuid.UUID `json:"refund_id,omitempty"`
+	RefundPending bool       `json:"refund_pending"`
+	CancelledAt   time.Time  `json:"cancelled_at"`
+}
+
+// Canceller is who is cancelling a booking
+type Canceller struct {
+	UserID uuid.UUID
+	Admin  bool
+}
+
+// refundPending is the payment_status of a cancelled booking whose refund
+// has not been made yet
+const refundPending = "refund_pending"
+
+// refundRetryAfter is how long a pending refund is left to the request that
+// cancelled the booking before SettlePendingRefunds retries it
+const refundRetryAfter = 5 * time.Minute
+
+// CancellationActor decides whether canceller may cancel a booking made by
+// customerID with the vendor whose user is vendorUserID, and if so as which
+// cancellation actor. Admins cancel as cancellation.ActorAdmin.
+func CancellationActor(canceller Canceller, customerID, vendorUserID uuid.UUID) (string, error) {
+	switch {
+	case canceller.Admin:
+		return cancellation.ActorAdmin, nil
+	case canceller.UserID == customerID:
+		return cancellation.ActorCustomer, nil
+	case canceller.UserID == vendorUserID:
+		return cancellation.ActorVendor, nil
+	}
+	return "", ErrUnauthorized
+}
+
+// Refunder returns money paid for a booking to the customer. The server
+// backs it with the payment service.
+type Refunder interface {
+	// RefundBooking makes the refund with ID refundID, returning nil only
+	// once it has been made and recorded. Retrying with the same refundID
+	// never refunds twice.
+	RefundBooking(ctx context.Context, bookingID, refundID uuid.UUID, amount float64, reason string) error
+	// SettleEscrow pays what is left of a cancelled booking's escrow after
+	// its refund, the cancellation fee, to the vendor. A booking with
+	// nothing held is left as it is.
+	SettleEscrow(ctx context.Context, bookingID uuid.UUID) error
+}
+
+// SetRefunder sets how cancelled bookings are refunded
+func (s *Service) SetRefunder(refunder Refunder) {
+	s.refunder = refunder
+}
+
+// Cancel cancels a booking for its customer, its vendor or an admin, and
+// refunds what the service's cancellation policy, or else the vendor's,
+// allows for the notice given and who cancelled. The refund is recorded as
+// pending with the cancellation, under a refund ID written to the booking
+// so retries refund once; if it cannot be made straight away the
+// cancellation is returned with RefundPending set, and SettlePendingRefunds
+// retries it. Once refunded, what is left of the booking's escrow goes to
+// the vendor.
+func (s *Service) Cancel(ctx context.Context, bookingID uuid.UUID, canceller Canceller, reason string) (*Cancellation, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	cancelled := &Cancellation{
+		BookingID:   bookingID,
+		Reason:      reason,
+		CancelledAt: time.Now(),
+	}
+	var status string
+	var customerID, vendorUserID uuid.UUID
+	var start time.Time
+	var policyJSON []byte
+	booked := &Booking{ID: bookingID}
+	err = tx.QueryRow(ctx, `
+		SELECT b.status, b.user_id, v.user_id, COALESCE(b.amount_paid, 0), b.currency,
+		       b.scheduled_date + COALESCE(b.scheduled_start_time, TIME '00:00'),
+		       COALESCE(s.cancellation_policy, v.cancellation_policy),
+		       b.service_id, b.total_amount, b.life_event_id
+		FROM bookings b
+		JOIN vendors v ON v.id = b.vendor_id
+		LEFT JOIN services s ON s.id = b.service_id
+		WHERE b.id = $1
+		FOR UPDATE OF b
+	`, bookingID).Scan(&status, &customerID, &vendorUserID, &cancelled.AmountPaid, &cancelled.Currency, &start, &policyJSON,
+		&booked.ServiceID, &booked.TotalAmount, &booked.LifeEventID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch booking: %w", err)
+	}
+	actor, err := CancellationActor(canceller, customerID, vendorUserID)
+	if err != nil {
+		return nil, err
+	}
+	cancelled.Actor = actor
+	switch BookingStatus(status) {
+	case StatusCompleted, StatusCancelled, StatusRefunded:
+		return nil, ErrBookingNotCancellable
+	}
+
+	policy, err := cancellation.ParsePolicy(policyJSON)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(bookingTimezone)
+	if err != nil {
+		location = time.UTC
+	}
+	quote := policy.Refund(cancelled.AmountPaid, wallClock(start, location), cancelled.CancelledAt, actor)
+	cancelled.RefundPercent = quote.RefundPercent
+	cancelled.RefundAmount = quote.RefundAmount
+	cancelled.RefundPending = cancelled.RefundAmount > 0
+	if cancelled.RefundPending {
+		refundID := uuid.New()
+		cancelled.RefundID = &refundID
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE bookings
+		SET status = $1, cancelled_at = $2, cancellation_reason = $3, cancelled_by = $4,
+		    refund_amount = $5, refund_id = $6, updated_at = $2,
+		    payment_status = CASE WHEN $5 > 0 THEN $7 ELSE payment_status END
+		WHERE id = $8
+	`, StatusCancelled, cancelled.CancelledAt, reason, actor, cancelled.RefundAmount, cancelled.RefundID, refundPending, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel booking: %w", err)
+	}
+	if s.eventRecorder != nil && booked.LifeEventID != nil {
+		booked.UserID = customerID
+		if err := s.eventRecorder.BookingCancelled(ctx, tx, booked); err != nil {
+			return nil, fmt.Errorf("failed to take booking off its event: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if !cancelled.RefundPending {
+		// Nothing to refund, so the vendor keeps what was paid. Should this
+		// fail, the escrow sweep pays it out once the escrow expires.
+		if s.refunder != nil {
+			s.refunder.SettleEscrow(ctx, bookingID)
+		}
+		return cancelled, nil
+	}
+	// A refund that fails here stays pending for SettlePendingRefunds
+	if err := s.settleRefund(ctx, bookingID, *cancelled.RefundID, cancelled.RefundAmount, cancelled.AmountPaid, reason); err == nil {
+		cancelled.RefundPending = false
+	}
+	return cancelled, nil
+}
+
+// SettlePendingRefunds retries the refunds of cancelled bookings that could
+// not be made when they were cancelled. It returns how many it settled, and
+// the first error met; a booking whose refund fails again is left pending.
+func (s *Service) SettlePendingRefunds(ctx context.Context) (int, error) {
+	// Bookings cancelled before refunds had IDs are given one here, once
+	rows, err := s.db.Query(ctx, `
+		UPDATE bookings SET refund_id = COALESCE(refund_id, gen_random_uuid())
+		WHERE status = $1 AND payment_status = $2 AND updated_at < $3
+		RETURNING id, refund_id, refund_amount, COALESCE(amount_paid, 0), COALESCE(cancellation_reason, '')
+	`, StatusCancelled, refundPending, time.Now().Add(-refundRetryAfter))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find pending refunds: %w", err)
+	}
+
+	type pendingRefund struct {
+		bookingID  uuid.UUID
+		refundID   uuid.UUID
+		amount     float64
+		amountPaid float64
+		reason     string
+	}
+	var pending []pendingRefund
+	for rows.Next() {
+		var p pendingRefund
+		if err := rows.Scan(&p.bookingID, &p.refundID, &p.amount, &p.amountPaid, &p.reason); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	var firstErr error
+	for _, p := range pending {
+		if err := s.settleRefund(ctx, p.bookingID, p.refundID, p.amount, p.amountPaid, p.reason); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		settled++
+	}
+	return settled, firstErr
+}
+
+// settleRefund makes a cancelled booking's pending refund, pays the rest of
+// its escrow to the vendor and records the refund in the booking's payment
+// status. Any step failing leaves the refund pending, to be retried from the
+// start under the same refund ID.
+func (s *Service) settleRefund(ctx context.Context, bookingID, refundID uuid.UUID, amount, amountPaid float64, reason string) error {
+	if s.refunder == nil {
+		return errors.New("refunder not configured")
+	}
+	if err := s.refunder.RefundBooking(ctx, bookingID, refundID, amount, "Cancelled: "+reason); err != nil {
+		return fmt.Errorf("failed to refund cancelled booking: %w", err)
+	}
+	if err := s.refunder.SettleEscrow(ctx, bookingID); err != nil {
+		return fmt.Errorf("failed to settle cancelled booking's escrow: %w", err)
+	}
+
+	paymentStatus := "partially_refunded"
+	if amount >= amountPaid {
+		paymentStatus = "refunded"
+	}
+	_, err := s.db.Exec(ctx, `
+		UPDATE bookings SET payment_status = $1, updated_at = NOW()
+		WHERE id = $2 AND payment_status = $3
+	`, paymentStatus, bookingID, refundPending)
+	if err != nil {
+		return fmt.Errorf("failed to update payment status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/notification"
+)
+
+// NotificationAdapter adapts the notification service to tell customers and
+// vendors about changes to their bookings
+type NotificationAdapter struct {
+	service *notification.Service
+}
+
+// NewNotificationAdapter creates a new notification adapter
+func NewNotificationAdapter(service *notification.Service) *NotificationAdapter {
+	return &NotificationAdapter{
+		service: service,
+	}
+}
+
+// NotifyReschedule tells the customer and the vendor a booking has moved
+func (a *NotificationAdapter) NotifyReschedule(ctx context.Context, reschedule *Reschedule) error {
+	body := fmt.Sprintf("Your booking has moved from %s to %s.",
+		reschedule.OldStart.Format("Mon 2 Jan 15:04"), reschedule.NewStart.Format("Mon 2 Jan 15:04"))
+	data := map[string]interface{}{
+		"booking_id": reschedule.BookingID.String(),
+		"old_start":  reschedule.OldStart,
+		"new_start":  reschedule.NewStart,
+		"new_end":    reschedule.NewEnd,
+		"actor":      reschedule.Actor,
+	}
+
+	var firstErr error
+	for _, userID := range []uuid.UUID{reschedule.CustomerID, reschedule.VendorUserID} {
+		if userID == uuid.Nil {
+			continue
+		}
+		_, err := a.service.Send(ctx, notification.SendRequest{
+			UserID:   userID,
+			Type:     notification.TypeBookingRescheduled,
+			Title:    "Booking rescheduled",
+			Body:     body,
+			Priority: notification.PriorityHigh,
+			Data:     data,
+			TargetID: reschedule.ID.String(),
+		})
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
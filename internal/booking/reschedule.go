@@ -0,0 +1,150 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/availability"
+)
+
+// Rescheduling errors
+var (
+	ErrBookingNotReschedulable = errors.New("completed or cancelled bookings cannot be rescheduled")
+	ErrRescheduleTooLate       = availability.ErrInsufficientNotice
+	ErrSlotUnavailable         = availability.ErrUnavailable
+)
+
+// Reschedule is a booking moved from one time to another
+type Reschedule struct {
+	ID           uuid.UUID `json:"id"`
+	BookingID    uuid.UUID `json:"booking_id"`
+	CustomerID   uuid.UUID `json:"customer_id"`
+	VendorID     uuid.UUID `json:"vendor_id"`
+	VendorUserID uuid.UUID `json:"-"` // uuid.Nil when the vendor has no account
+	OldStart     time.Time `json:"old_start"`
+	OldEnd       time.Time `json:"old_end"`
+	NewStart     time.Time `json:"new_start"`
+	NewEnd       time.Time `json:"new_end"`
+	Actor        string    `json:"actor"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RescheduleNotifier tells the customer and vendor a booking was moved
+type RescheduleNotifier interface {
+	NotifyReschedule(ctx context.Context, reschedule *Reschedule) error
+}
+
+// SetRescheduleNotifier sets who is told about rescheduled bookings
+func (s *Service) SetRescheduleNotifier(notifier RescheduleNotifier) {
+	s.rescheduleNotifier = notifier
+}
+
+// Reschedule moves a booking to newStart-newEnd on behalf of actor. The new
+// time must be free by CheckAvailability, and both the old and new times must
+// be at least the vendor's lead time away. The move is kept in the booking's
+// reschedule history and both parties are notified.
+func (s *Service) Reschedule(ctx context.Context, bookingID uuid.UUID, newStart, newEnd time.Time, actor string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	reschedule := &Reschedule{
+		ID:        uuid.New(),
+		BookingID: bookingID,
+		NewStart:  newStart,
+		NewEnd:    newEnd,
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	}
+	var status string
+	var vendorUserID *uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT b.user_id, b.vendor_id, v.user_id, b.status,
+		       b.scheduled_date + COALESCE(b.scheduled_start_time, TIME '00:00'),
+		       COALESCE(
+		           b.scheduled_date + b.scheduled_end_time,
+		           b.scheduled_date + b.scheduled_start_time + b.duration_minutes * INTERVAL '1 minute',
+		           b.scheduled_date + INTERVAL '1 day'
+		       )
+		FROM bookings b
+		JOIN vendors v ON v.id = b.vendor_id
+		WHERE b.id = $1
+		FOR UPDATE OF b
+	`, bookingID).Scan(
+		&reschedule.CustomerID, &reschedule.VendorID, &vendorUserID, &status,
+		&reschedule.OldStart, &reschedule.OldEnd,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrBookingNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch booking: %w", err)
+	}
+	if vendorUserID != nil {
+		reschedule.VendorUserID = *vendorUserID
+	}
+	switch BookingStatus(status) {
+	case StatusCompleted, StatusCancelled, StatusRefunded:
+		return ErrBookingNotReschedulable
+	}
+
+	// Locking the vendor keeps concurrent moves from taking the same slot
+	vendor, err := availabilityVendor(ctx, tx, reschedule.VendorID, true)
+	if err != nil {
+		return err
+	}
+	reschedule.OldStart = wallClock(reschedule.OldStart, vendor.Location)
+	reschedule.OldEnd = wallClock(reschedule.OldEnd, vendor.Location)
+	from := availability.Interval{Start: reschedule.OldStart, End: reschedule.OldEnd}
+	to := availability.Interval{Start: newStart, End: newEnd}
+	booked, err := bookedIntervals(ctx, tx, reschedule.VendorID, bookingID, availability.SearchWindow(vendor, to), vendor.Location)
+	if err != nil {
+		return err
+	}
+	if _, err := availability.Reschedule(vendor, booked, from, to, reschedule.CreatedAt); err != nil {
+		return err
+	}
+
+	// The end time is only kept when the booking ends on the day it starts
+	start, end := newStart.In(vendor.Location), newEnd.In(vendor.Location)
+	var endTime *string
+	if end.Format("2006-01-02") == start.Format("2006-01-02") {
+		t := end.Format("15:04:05")
+		endTime = &t
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE bookings
+		SET scheduled_date = $1::date, scheduled_start_time = $2::time, scheduled_end_time = $3::time,
+		    duration_minutes = $4, updated_at = $5
+		WHERE id = $6
+	`, start.Format("2006-01-02"), start.Format("15:04:05"), endTime,
+		int(end.Sub(start).Minutes()), reschedule.CreatedAt, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule booking: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO booking_reschedules (id, booking_id, old_start, old_end, new_start, new_end, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, reschedule.ID, bookingID, reschedule.OldStart, reschedule.OldEnd,
+		reschedule.NewStart, reschedule.NewEnd, actor, reschedule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record reschedule: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if s.rescheduleNotifier != nil {
+		s.rescheduleNotifier.NotifyReschedule(ctx, reschedule)
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ package eventgpt
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -93,12 +92,38 @@ type Config struct {
 	MaxTokens         int
 	Temperature       float64
 	ConversationTTL   time.Duration
+	WelcomeFlows      map[string]WelcomeFlow // keyed by entry source, optionally ":"+vendor category
+}
+
+// Entry sources a conversation can be started from
+const (
+	EntryDirect     = "direct"
+	EntryVendorPage = "vendor_page"
+	EntryCampaign   = "campaign"
+)
+
+// EntryContext describes where the user was when they opened the chat
+type EntryContext struct {
+	Source         string     `json:"source"`
+	VendorID       *uuid.UUID `json:"vendor_id,omitempty"`
+	VendorName     string     `json:"vendor_name,omitempty"`
+	VendorCategory string     `json:"vendor_category,omitempty"`
+	EventType      string     `json:"event_type,omitempty"`
+	CampaignID     string     `json:"campaign_id,omitempty"`
+}
+
+// WelcomeFlow is the opening message shown for an entry point
+type WelcomeFlow struct {
+	Greeting     string               `json:"greeting"` // {vendor_name} and {event_type} are filled from the entry context
+	QuickReplies []string             `json:"quick_replies"`
+	Slots        map[Slot]interface{} `json:"slots,omitempty"`
 }
 
 // Service handles EventGPT business logic
 type Service struct {
 	db     *pgxpool.Pool
 	cache  *redis.Client
+	store  ConversationStore
 	config *Config
 	logger *zap.Logger
 }
@@ -117,66 +142,136 @@ func NewService(db *pgxpool.Pool, cache *redis.Client, config *Config, logger *z
 			ConversationTTL: 24 * time.Hour,
 		}
 	}
+	if config.WelcomeFlows == nil {
+		config.WelcomeFlows = DefaultWelcomeFlows()
+	}
 
-	return &Service{
+	s := &Service{
 		db:     db,
 		cache:  cache,
 		config: config,
 		logger: logger,
 	}
+	if db != nil {
+		s.store = NewPostgresConversationStore(db)
+	}
+	return s
+}
+
+// SetConversationStore replaces where conversations are kept
+func (s *Service) SetConversationStore(store ConversationStore) {
+	s.store = store
 }
 
 // =============================================================================
 // CONVERSATION MANAGEMENT
 // =============================================================================
 
-// StartConversation creates a new conversation
-func (s *Service) StartConversation(ctx context.Context, userID uuid.UUID) (*Conversation, error) {
+// StartConversation creates a new conversation opened from the given entry
+// point. A nil entry is treated as a direct visit.
+func (s *Service) StartConversation(ctx context.Context, userID uuid.UUID, entry *EntryContext) (*Conversation, error) {
+	conversation := s.NewConversation(userID, entry)
+
+	if err := s.store.CreateConversation(ctx, conversation); err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	return conversation, nil
+}
+
+// NewConversation builds a conversation with the welcome message for its
+// entry point, pre-filling whatever the entry context already tells us
+func (s *Service) NewConversation(userID uuid.UUID, entry *EntryContext) *Conversation {
+	if entry == nil {
+		entry = &EntryContext{Source: EntryDirect}
+	}
+	flow := s.WelcomeFlowFor(entry)
+
 	conversation := &Conversation{
 		ID:            uuid.New(),
 		UserID:        userID,
 		State:         StateInitial,
 		Messages:      []Message{},
 		Slots:         make(map[Slot]interface{}),
-		Context:       make(map[string]interface{}),
+		Context:       map[string]interface{}{"entry_source": entry.Source},
 		TurnCount:     0,
 		StartedAt:     time.Now(),
 		LastMessageAt: time.Now(),
 	}
 
-	// Save to database
-	messagesJSON, _ := json.Marshal(conversation.Messages)
-	slotsJSON, _ := json.Marshal(conversation.Slots)
-	contextJSON, _ := json.Marshal(conversation.Context)
-
-	query := `
-		INSERT INTO conversations (
-			id, user_id, conversation_state, messages, slots, context,
-			turn_count, started_at, last_message_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`
-
-	_, err := s.db.Exec(ctx, query,
-		conversation.ID,
-		conversation.UserID,
-		conversation.State,
-		messagesJSON,
-		slotsJSON,
-		contextJSON,
-		conversation.TurnCount,
-		conversation.StartedAt,
-		conversation.LastMessageAt,
-	)
+	for slot, value := range flow.Slots {
+		conversation.Slots[slot] = value
+	}
+	if entry.VendorCategory != "" {
+		conversation.Slots[SlotVendorType] = strings.ToLower(entry.VendorCategory)
+	}
+	if entry.EventType != "" {
+		conversation.Slots[SlotEventType] = strings.ToLower(entry.EventType)
+	}
+	if entry.VendorID != nil {
+		conversation.Context["vendor_id"] = entry.VendorID.String()
+	}
+	if entry.CampaignID != "" {
+		conversation.Context["campaign_id"] = entry.CampaignID
+	}
+	conversation.Messages = append(conversation.Messages, s.generateWelcomeMessage(flow, entry))
+	return conversation
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create conversation: %w", err)
+// WelcomeFlowFor picks the most specific welcome flow for an entry point:
+// source and vendor category first, then source alone, then the default
+func (s *Service) WelcomeFlowFor(entry *EntryContext) WelcomeFlow {
+	flows := DefaultWelcomeFlows()
+	if s.config != nil && s.config.WelcomeFlows != nil {
+		flows = s.config.WelcomeFlows
 	}
 
-	// Add welcome message
-	welcomeMsg := s.generateWelcomeMessage()
-	conversation.Messages = append(conversation.Messages, welcomeMsg)
+	if entry != nil {
+		category := strings.ToLower(entry.VendorCategory)
+		if flow, ok := flows[entry.Source+":"+category]; ok && category != "" {
+			return flow
+		}
+		if flow, ok := flows[entry.Source]; ok {
+			return flow
+		}
+	}
+	return flows[EntryDirect]
+}
 
-	return conversation, nil
+// DefaultWelcomeFlows returns the built-in welcome flows
+func DefaultWelcomeFlows() map[string]WelcomeFlow {
+	return map[string]WelcomeFlow{
+		EntryDirect: {
+			Greeting: "👋 Welcome to EventGPT! I'm your AI event planning assistant.\n\n" +
+				"I can help you plan your perfect event by:\n" +
+				"• Finding the right vendors\n" +
+				"• Getting competitive quotes\n" +
+				"• Managing your budget\n" +
+				"• Coordinating timelines\n\n" +
+				"Tell me about your event and let's get started!",
+			QuickReplies: []string{"Plan a wedding", "Find vendors", "Get quotes", "Just browsing"},
+		},
+		EntryVendorPage: {
+			Greeting:     "👋 Hi! I can help you with {vendor_name} - checking their availability, getting a quote, or finding similar vendors.",
+			QuickReplies: []string{"Check availability", "Get a quote", "See similar vendors", "Plan my event"},
+		},
+		EntryVendorPage + ":photographer": {
+			Greeting:     "📸 Looking to book {vendor_name}? Tell me your event date and I'll check their availability and packages.",
+			QuickReplies: []string{"Check availability", "See photo packages", "Get a quote", "Add a videographer"},
+		},
+		EntryVendorPage + ":caterer": {
+			Greeting:     "🍽️ Planning the menu with {vendor_name}? Tell me your date and guest count and I'll get you a quote.",
+			QuickReplies: []string{"Check availability", "See menu options", "Get a quote", "Add a cake baker"},
+		},
+		EntryVendorPage + ":venue": {
+			Greeting:     "🏛️ Interested in {vendor_name}? Let me check if it's free on your date and fits your guest list.",
+			QuickReplies: []string{"Check availability", "Book a viewing", "Get a quote", "See similar venues"},
+		},
+		EntryCampaign: {
+			Greeting:     "🎉 Welcome! Let's start planning your {event_type}. I'll match you with vendors from this offer.",
+			QuickReplies: []string{"Start planning", "See featured vendors", "Get quotes"},
+		},
+	}
 }
 
 // ProcessMessage handles a user message and generates a response
@@ -229,9 +324,9 @@ func (s *Service) ProcessMessage(ctx context.Context, conversationID uuid.UUID,
 	// Update conversation state
 	conversation.State = s.determineNextState(conversation)
 
-	// Save updated conversation
-	if err := s.updateConversation(ctx, conversation); err != nil {
-		s.logger.Error("Failed to update conversation", zap.Error(err))
+	// Save the turn; a reply the history doesn't hold would be lost on reload
+	if err := s.store.UpdateConversation(ctx, conversation); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
 	}
 
 	return assistantMsg, nil
@@ -239,55 +334,18 @@ func (s *Service) ProcessMessage(ctx context.Context, conversationID uuid.UUID,
 
 // GetConversation retrieves a conversation by ID
 func (s *Service) GetConversation(ctx context.Context, conversationID uuid.UUID) (*Conversation, error) {
-	query := `
-		SELECT id, user_id, conversation_state, messages, slots, context,
-		       turn_count, started_at, last_message_at, ended_at
-		FROM conversations
-		WHERE id = $1
-	`
-
-	var conversation Conversation
-	var messagesJSON, slotsJSON, contextJSON []byte
-
-	err := s.db.QueryRow(ctx, query, conversationID).Scan(
-		&conversation.ID,
-		&conversation.UserID,
-		&conversation.State,
-		&messagesJSON,
-		&slotsJSON,
-		&contextJSON,
-		&conversation.TurnCount,
-		&conversation.StartedAt,
-		&conversation.LastMessageAt,
-		&conversation.EndedAt,
-	)
-
+	conversation, err := s.store.GetConversation(ctx, conversationID)
 	if err != nil {
 		return nil, fmt.Errorf("conversation not found: %w", err)
 	}
-
-	// Unmarshal JSON fields
-	json.Unmarshal(messagesJSON, &conversation.Messages)
-	json.Unmarshal(slotsJSON, &conversation.Slots)
-	json.Unmarshal(contextJSON, &conversation.Context)
-
-	return &conversation, nil
+	return conversation, nil
 }
 
 // EndConversation marks a conversation as ended
 func (s *Service) EndConversation(ctx context.Context, conversationID uuid.UUID) error {
-	now := time.Now()
-	query := `
-		UPDATE conversations
-		SET conversation_state = $1, ended_at = $2
-		WHERE id = $3
-	`
-
-	_, err := s.db.Exec(ctx, query, StateEnded, now, conversationID)
-	if err != nil {
+	if err := s.store.EndConversation(ctx, conversationID, time.Now()); err != nil {
 		return fmt.Errorf("failed to end conversation: %w", err)
 	}
-
 	return nil
 }
 
@@ -537,20 +595,25 @@ func (s *Service) handleUnknown(conversation *Conversation, userMsg Message) str
 // =============================================================================
 
 // generateWelcomeMessage creates the initial welcome message
-func (s *Service) generateWelcomeMessage() Message {
+func (s *Service) generateWelcomeMessage(flow WelcomeFlow, entry *EntryContext) Message {
+	vendorName := entry.VendorName
+	if vendorName == "" {
+		vendorName = "this vendor"
+	}
+	eventType := entry.EventType
+	if eventType == "" {
+		eventType = "event"
+	}
+	content := strings.NewReplacer("{vendor_name}", vendorName, "{event_type}", eventType).Replace(flow.Greeting)
+
 	return Message{
 		ID:        uuid.New(),
 		Role:      "assistant",
-		Content:   "👋 Welcome to EventGPT! I'm your AI event planning assistant.\n\n" +
-			"I can help you plan your perfect event by:\n" +
-			"• Finding the right vendors\n" +
-			"• Getting competitive quotes\n" +
-			"• Managing your budget\n" +
-			"• Coordinating timelines\n\n" +
-			"Tell me about your event and let's get started!",
+		Content:   content,
 		Timestamp: time.Now(),
 		Metadata:  map[string]interface{}{
-			"quick_replies": []string{"Plan a wedding", "Find vendors", "Get quotes", "Just browsing"},
+			"quick_replies": flow.QuickReplies,
+			"entry_source":  entry.Source,
 		},
 	}
 }
@@ -629,29 +692,3 @@ func (s *Service) determineNextState(conversation *Conversation) ConversationSta
 
 	return conversation.State
 }
-
-// updateConversation saves conversation changes to database
-func (s *Service) updateConversation(ctx context.Context, conversation *Conversation) error {
-	messagesJSON, _ := json.Marshal(conversation.Messages)
-	slotsJSON, _ := json.Marshal(conversation.Slots)
-	contextJSON, _ := json.Marshal(conversation.Context)
-
-	query := `
-		UPDATE conversations
-		SET conversation_state = $1, messages = $2, slots = $3, context = $4,
-		    turn_count = $5, last_message_at = $6
-		WHERE id = $7
-	`
-
-	_, err := s.db.Exec(ctx, query,
-		conversation.State,
-		messagesJSON,
-		slotsJSON,
-		contextJSON,
-		conversation.TurnCount,
-		conversation.LastMessageAt,
-		conversation.ID,
-	)
-
-	return err
-}
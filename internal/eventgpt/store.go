@@ -0,0 +1,280 @@
+// EventGPT - Conversation Storage
+// Copyright (c) 2024 BillyRonks Global Limited. All rights reserved.
+
+package eventgpt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrConversationNotFound is returned for an unknown conversation ID
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// ConversationStore persists conversations with their full message history
+type ConversationStore interface {
+	CreateConversation(ctx context.Context, conversation *Conversation) error
+	GetConversation(ctx context.Context, conversationID uuid.UUID) (*Conversation, error)
+	// UpdateConversation writes state, slots, context and the whole message
+	// history back in one statement
+	UpdateConversation(ctx context.Context, conversation *Conversation) error
+	EndConversation(ctx context.Context, conversationID uuid.UUID, endedAt time.Time) error
+}
+
+// =============================================================================
+// POSTGRES
+// =============================================================================
+
+// PostgresConversationStore keeps conversations in the conversations table,
+// with messages, slots and context as JSONB
+type PostgresConversationStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresConversationStore creates a database-backed store
+func NewPostgresConversationStore(db *pgxpool.Pool) *PostgresConversationStore {
+	return &PostgresConversationStore{db: db}
+}
+
+// CreateConversation inserts a new conversation
+func (p *PostgresConversationStore) CreateConversation(ctx context.Context, conversation *Conversation) error {
+	messagesJSON, slotsJSON, contextJSON, err := encodeConversation(conversation)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO conversations (
+			id, user_id, conversation_state, messages, slots, context,
+			turn_count, started_at, last_message_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = p.db.Exec(ctx, query,
+		conversation.ID,
+		conversation.UserID,
+		conversation.State,
+		messagesJSON,
+		slotsJSON,
+		contextJSON,
+		conversation.TurnCount,
+		conversation.StartedAt,
+		conversation.LastMessageAt,
+	)
+	return err
+}
+
+// GetConversation loads a conversation and its message history
+func (p *PostgresConversationStore) GetConversation(ctx context.Context, conversationID uuid.UUID) (*Conversation, error) {
+	query := `
+		SELECT id, user_id, conversation_state, messages, slots, context,
+		       turn_count, started_at, last_message_at, ended_at
+		FROM conversations
+		WHERE id = $1
+	`
+
+	var conversation Conversation
+	var messagesJSON, slotsJSON, contextJSON []byte
+
+	err := p.db.QueryRow(ctx, query, conversationID).Scan(
+		&conversation.ID,
+		&conversation.UserID,
+		&conversation.State,
+		&messagesJSON,
+		&slotsJSON,
+		&contextJSON,
+		&conversation.TurnCount,
+		&conversation.StartedAt,
+		&conversation.LastMessageAt,
+		&conversation.EndedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeConversation(&conversation, messagesJSON, slotsJSON, contextJSON); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// UpdateConversation saves the conversation after a turn
+func (p *PostgresConversationStore) UpdateConversation(ctx context.Context, conversation *Conversation) error {
+	messagesJSON, slotsJSON, contextJSON, err := encodeConversation(conversation)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE conversations
+		SET conversation_state = $1, messages = $2, slots = $3, context = $4,
+		    turn_count = $5, last_message_at = $6, updated_at = NOW()
+		WHERE id = $7
+	`
+
+	tag, err := p.db.Exec(ctx, query,
+		conversation.State,
+		messagesJSON,
+		slotsJSON,
+		contextJSON,
+		conversation.TurnCount,
+		conversation.LastMessageAt,
+		conversation.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
+// EndConversation marks a conversation as ended
+func (p *PostgresConversationStore) EndConversation(ctx context.Context, conversationID uuid.UUID, endedAt time.Time) error {
+	query := `
+		UPDATE conversations
+		SET conversation_state = $1, ended_at = $2
+		WHERE id = $3
+	`
+
+	_, err := p.db.Exec(ctx, query, StateEnded, endedAt, conversationID)
+	return err
+}
+
+func encodeConversation(conversation *Conversation) (messagesJSON, slotsJSON, contextJSON []byte, err error) {
+	history := conversation.Messages
+	if history == nil {
+		history = []Message{}
+	}
+	if messagesJSON, err = json.Marshal(history); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode messages: %w", err)
+	}
+	if slotsJSON, err = json.Marshal(conversation.Slots); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode slots: %w", err)
+	}
+	if contextJSON, err = json.Marshal(conversation.Context); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode context: %w", err)
+	}
+	return messagesJSON, slotsJSON, contextJSON, nil
+}
+
+func decodeConversation(conversation *Conversation, messagesJSON, slotsJSON, contextJSON []byte) error {
+	conversation.Messages = []Message{}
+	conversation.Slots = make(map[Slot]interface{})
+	conversation.Context = make(map[string]interface{})
+
+	if len(messagesJSON) > 0 {
+		if err := json.Unmarshal(messagesJSON, &conversation.Messages); err != nil {
+			return fmt.Errorf("failed to decode messages: %w", err)
+		}
+	}
+	if len(slotsJSON) > 0 {
+		if err := json.Unmarshal(slotsJSON, &conversation.Slots); err != nil {
+			return fmt.Errorf("failed to decode slots: %w", err)
+		}
+	}
+	if len(contextJSON) > 0 {
+		if err := json.Unmarshal(contextJSON, &conversation.Context); err != nil {
+			return fmt.Errorf("failed to decode context: %w", err)
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+// IN MEMORY
+// =============================================================================
+
+// MemoryConversationStore keeps conversations in process. Conversations go
+// through the same JSON encoding as the database, so what comes back matches
+// what a JSONB round trip would give.
+type MemoryConversationStore struct {
+	mu            sync.Mutex
+	conversations map[uuid.UUID]memoryConversation
+}
+
+type memoryConversation struct {
+	conversation                         Conversation
+	messagesJSON, slotsJSON, contextJSON []byte
+}
+
+// NewMemoryConversationStore creates an empty in-process store
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{conversations: make(map[uuid.UUID]memoryConversation)}
+}
+
+// CreateConversation stores a new conversation
+func (m *MemoryConversationStore) CreateConversation(ctx context.Context, conversation *Conversation) error {
+	return m.put(conversation, false)
+}
+
+// GetConversation returns a copy of a stored conversation
+func (m *MemoryConversationStore) GetConversation(ctx context.Context, conversationID uuid.UUID) (*Conversation, error) {
+	m.mu.Lock()
+	stored, ok := m.conversations[conversationID]
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrConversationNotFound
+	}
+
+	conversation := stored.conversation
+	if err := decodeConversation(&conversation, stored.messagesJSON, stored.slotsJSON, stored.contextJSON); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// UpdateConversation replaces a stored conversation
+func (m *MemoryConversationStore) UpdateConversation(ctx context.Context, conversation *Conversation) error {
+	return m.put(conversation, true)
+}
+
+// EndConversation marks a stored conversation as ended
+func (m *MemoryConversationStore) EndConversation(ctx context.Context, conversationID uuid.UUID, endedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.conversations[conversationID]
+	if !ok {
+		return nil
+	}
+	stored.conversation.State = StateEnded
+	stored.conversation.EndedAt = &endedAt
+	m.conversations[conversationID] = stored
+	return nil
+}
+
+func (m *MemoryConversationStore) put(conversation *Conversation, mustExist bool) error {
+	messagesJSON, slotsJSON, contextJSON, err := encodeConversation(conversation)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.conversations[conversation.ID]; mustExist && !ok {
+		return ErrConversationNotFound
+	}
+	stored := memoryConversation{
+		conversation: *conversation,
+		messagesJSON: messagesJSON,
+		slotsJSON:    slotsJSON,
+		contextJSON:  contextJSON,
+	}
+	stored.conversation.Messages, stored.conversation.Slots, stored.conversation.Context = nil, nil, nil
+	m.conversations[conversation.ID] = stored
+	return nil
+}
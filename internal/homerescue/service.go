@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +13,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/geo"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/phone"
 )
 
 // Error definitions
@@ -24,6 +26,9 @@ var (
 	ErrUnauthorized           = errors.New("unauthorized")
 	ErrInvalidUrgency         = errors.New("invalid urgency level")
 	ErrSLABreach              = errors.New("SLA deadline breached")
+	ErrNotReorderable         = errors.New("only completed emergencies can be reordered")
+	ErrInvalidContactPhone    = errors.New("invalid contact phone number")
+	ErrInvalidIntake          = errors.New("invalid intake answers")
 )
 
 // Service handles HomeRescue business logic
@@ -44,54 +49,59 @@ func NewService(db *pgxpool.Pool, cache *redis.Client, logger *zap.Logger) *Serv
 
 // Emergency represents an emergency service request
 type Emergency struct {
-	ID                 uuid.UUID  `json:"id"`
-	UserID             uuid.UUID  `json:"user_id"`
-	Category           string     `json:"category"`
-	Subcategory        string     `json:"subcategory"`
-	Urgency            string     `json:"urgency"`
-	Title              string     `json:"title"`
-	Description        string     `json:"description"`
-	Address            string     `json:"address"`
-	Unit               string     `json:"unit,omitempty"`
-	City               string     `json:"city"`
-	State              string     `json:"state"`
-	PostalCode         string     `json:"postal_code"`
-	Latitude           float64    `json:"latitude"`
-	Longitude          float64    `json:"longitude"`
-	AccessInstructions string     `json:"access_instructions,omitempty"`
-	Status             string     `json:"status"`
-	AssignedVendorID   *uuid.UUID `json:"assigned_vendor_id,omitempty"`
-	AssignedTechID     *uuid.UUID `json:"assigned_tech_id,omitempty"`
-	TechLatitude       *float64   `json:"tech_latitude,omitempty"`
-	TechLongitude      *float64   `json:"tech_longitude,omitempty"`
-	EstimatedArrival   *time.Time `json:"estimated_arrival,omitempty"`
-	ActualArrival      *time.Time `json:"actual_arrival,omitempty"`
-	ResponseDeadline   time.Time  `json:"response_deadline"`
-	ArrivalDeadline    time.Time  `json:"arrival_deadline"`
-	EstimatedCost      *float64   `json:"estimated_cost,omitempty"`
-	FinalCost          *float64   `json:"final_cost,omitempty"`
-	WorkPerformed      string     `json:"work_performed,omitempty"`
-	CreatedAt          time.Time  `json:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at"`
-	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	ID                 uuid.UUID         `json:"id"`
+	UserID             uuid.UUID         `json:"user_id"`
+	Category           string            `json:"category"`
+	Subcategory        string            `json:"subcategory"`
+	Urgency            string            `json:"urgency"`
+	Title              string            `json:"title"`
+	Description        string            `json:"description"`
+	Address            string            `json:"address"`
+	Unit               string            `json:"unit,omitempty"`
+	City               string            `json:"city"`
+	State              string            `json:"state"`
+	PostalCode         string            `json:"postal_code"`
+	Latitude           float64           `json:"latitude"`
+	Longitude          float64           `json:"longitude"`
+	AccessInstructions string            `json:"access_instructions,omitempty"`
+	ContactPhone       string            `json:"contact_phone,omitempty"`
+	IntakeAnswers      map[string]string `json:"intake_answers,omitempty"`
+	DiagnosisHints     []string          `json:"diagnosis_hints,omitempty"`
+	Status             string            `json:"status"`
+	AssignedVendorID   *uuid.UUID        `json:"assigned_vendor_id,omitempty"`
+	AssignedTechID     *uuid.UUID        `json:"assigned_tech_id,omitempty"`
+	TechLatitude       *float64          `json:"tech_latitude,omitempty"`
+	TechLongitude      *float64          `json:"tech_longitude,omitempty"`
+	EstimatedArrival   *time.Time        `json:"estimated_arrival,omitempty"`
+	ActualArrival      *time.Time        `json:"actual_arrival,omitempty"`
+	ResponseDeadline   time.Time         `json:"response_deadline"`
+	ArrivalDeadline    time.Time         `json:"arrival_deadline"`
+	EstimatedCost      *float64          `json:"estimated_cost,omitempty"`
+	FinalCost          *float64          `json:"final_cost,omitempty"`
+	WorkPerformed      string            `json:"work_performed,omitempty"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	CompletedAt        *time.Time        `json:"completed_at,omitempty"`
 }
 
 // CreateEmergencyRequest represents a request to create an emergency
 type CreateEmergencyRequest struct {
-	UserID             uuid.UUID `json:"user_id"`
-	Category           string    `json:"category"`
-	Subcategory        string    `json:"subcategory"`
-	Urgency            string    `json:"urgency"`
-	Title              string    `json:"title"`
-	Description        string    `json:"description"`
-	Address            string    `json:"address"`
-	Unit               string    `json:"unit,omitempty"`
-	City               string    `json:"city"`
-	State              string    `json:"state"`
-	PostalCode         string    `json:"postal_code"`
-	Latitude           float64   `json:"latitude"`
-	Longitude          float64   `json:"longitude"`
-	AccessInstructions string    `json:"access_instructions,omitempty"`
+	UserID             uuid.UUID         `json:"user_id"`
+	Category           string            `json:"category"`
+	Subcategory        string            `json:"subcategory"`
+	Urgency            string            `json:"urgency"`
+	Title              string            `json:"title"`
+	Description        string            `json:"description"`
+	Address            string            `json:"address"`
+	Unit               string            `json:"unit,omitempty"`
+	City               string            `json:"city"`
+	State              string            `json:"state"`
+	PostalCode         string            `json:"postal_code"`
+	Latitude           float64           `json:"latitude"`
+	Longitude          float64           `json:"longitude"`
+	AccessInstructions string            `json:"access_instructions,omitempty"`
+	ContactPhone       string            `json:"contact_phone,omitempty"`
+	IntakeAnswers      map[string]string `json:"intake_answers,omitempty"`
 }
 
 // EmergencyStatus represents the status information of an emergency
@@ -105,6 +115,8 @@ type EmergencyStatus struct {
 	ArrivalDeadline   time.Time  `json:"arrival_deadline"`
 	EstimatedArrival  *time.Time `json:"estimated_arrival,omitempty"`
 	SLAStatus         string     `json:"sla_status"`
+	SecondsRemaining  int64      `json:"seconds_remaining"`
+	SLABreached       bool       `json:"sla_breached"`
 	CreatedAt         time.Time  `json:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at"`
 }
@@ -130,10 +142,7 @@ type EmergencyTracking struct {
 }
 
 // GeoPoint represents a geographic coordinate
-type GeoPoint struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-}
+type GeoPoint = geo.GeoPoint
 
 // TechnicianAvailability represents technician availability information
 type TechnicianAvailability struct {
@@ -176,6 +185,172 @@ var slaRefundPercentages = map[string]int{
 	"scheduled": 0,   // No refund
 }
 
+// =============================================================================
+// CATEGORY INTAKE
+// =============================================================================
+
+// IntakeQuestion is a structured triage question asked for a category
+type IntakeQuestion struct {
+	ID       string   `json:"id"`
+	Prompt   string   `json:"prompt"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+// IntakeRule adjusts triage when a question gets a particular answer.
+// MinUrgency raises the urgency to at least that level, MaxUrgency caps it.
+type IntakeRule struct {
+	QuestionID string
+	Answer     string
+	MinUrgency string
+	MaxUrgency string
+	Hint       string
+}
+
+// IntakeForm is the set of intake questions for one emergency category
+type IntakeForm struct {
+	Category  string           `json:"category"`
+	Questions []IntakeQuestion `json:"questions"`
+	Rules     []IntakeRule     `json:"-"`
+}
+
+// IntakeAssessment is the triage outcome of a set of intake answers
+type IntakeAssessment struct {
+	Urgency        string   `json:"urgency"`
+	DiagnosisHints []string `json:"diagnosis_hints,omitempty"`
+}
+
+// urgencyRank orders urgency levels from least to most pressing
+var urgencyRank = map[string]int{
+	"scheduled": 0,
+	"same_day":  1,
+	"urgent":    2,
+	"critical":  3,
+}
+
+var yesNo = []string{"yes", "no"}
+
+// IntakeForms holds the intake questions for each category that has them
+var IntakeForms = map[string]IntakeForm{
+	"plumbing": {
+		Category: "plumbing",
+		Questions: []IntakeQuestion{
+			{ID: "water_flowing", Prompt: "Is water actively flowing or leaking right now?", Options: yesNo, Required: true},
+			{ID: "main_shut_off", Prompt: "Have you been able to shut off the main water valve?", Options: yesNo, Required: true},
+			{ID: "near_electrics", Prompt: "Is the water near sockets, wiring or appliances?", Options: yesNo},
+			{ID: "source", Prompt: "Where is the problem?", Options: []string{"pipe", "toilet", "sink", "water_heater", "drain", "unknown"}},
+		},
+		Rules: []IntakeRule{
+			{QuestionID: "water_flowing", Answer: "yes", MinUrgency: "urgent", Hint: "Active leak"},
+			{QuestionID: "main_shut_off", Answer: "no", MinUrgency: "urgent", Hint: "Water supply still on"},
+			{QuestionID: "near_electrics", Answer: "yes", MinUrgency: "critical", Hint: "Water near electrics - isolate power before work"},
+			{QuestionID: "water_flowing", Answer: "no", MaxUrgency: "same_day"},
+			{QuestionID: "source", Answer: "water_heater", Hint: "Check water heater pressure relief valve"},
+			{QuestionID: "source", Answer: "drain", Hint: "Likely blockage - bring drain auger"},
+		},
+	},
+	"electrical": {
+		Category: "electrical",
+		Questions: []IntakeQuestion{
+			{ID: "burning_smell", Prompt: "Can you smell burning or see sparks or smoke?", Options: yesNo, Required: true},
+			{ID: "power_out", Prompt: "Is the power out in the whole home?", Options: yesNo, Required: true},
+			{ID: "breaker_tripped", Prompt: "Has a breaker tripped or a fuse blown?", Options: yesNo},
+		},
+		Rules: []IntakeRule{
+			{QuestionID: "burning_smell", Answer: "yes", MinUrgency: "critical", Hint: "Possible electrical fire - advise customer to cut power"},
+			{QuestionID: "power_out", Answer: "yes", MinUrgency: "urgent", Hint: "Whole-home outage - check main supply and meter"},
+			{QuestionID: "breaker_tripped", Answer: "yes", Hint: "Tripped breaker - test circuits for a fault before resetting"},
+		},
+	},
+	"locksmith": {
+		Category: "locksmith",
+		Questions: []IntakeQuestion{
+			{ID: "locked_out", Prompt: "Are you locked out right now?", Options: yesNo, Required: true},
+			{ID: "vulnerable_inside", Prompt: "Is a child, elderly person or pet locked inside?", Options: yesNo, Required: true},
+			{ID: "break_in", Prompt: "Is this after a break-in?", Options: yesNo},
+		},
+		Rules: []IntakeRule{
+			{QuestionID: "vulnerable_inside", Answer: "yes", MinUrgency: "critical", Hint: "Vulnerable person inside"},
+			{QuestionID: "locked_out", Answer: "yes", MinUrgency: "urgent"},
+			{QuestionID: "break_in", Answer: "yes", MinUrgency: "urgent", Hint: "Post break-in - bring replacement lock cylinders"},
+			{QuestionID: "locked_out", Answer: "no", MaxUrgency: "same_day"},
+		},
+	},
+	"hvac": {
+		Category: "hvac",
+		Questions: []IntakeQuestion{
+			{ID: "gas_smell", Prompt: "Can you smell gas?", Options: yesNo, Required: true},
+			{ID: "no_cooling", Prompt: "Has cooling or heating stopped completely?", Options: yesNo, Required: true},
+		},
+		Rules: []IntakeRule{
+			{QuestionID: "gas_smell", Answer: "yes", MinUrgency: "critical", Hint: "Gas smell - advise customer to ventilate and leave"},
+			{QuestionID: "no_cooling", Answer: "no", MaxUrgency: "same_day", Hint: "Partial loss - check filters and refrigerant"},
+		},
+	},
+}
+
+// AssessIntake validates intake answers for a category and refines the
+// reported urgency from them. Caps apply before floors so a safety answer
+// always wins. Categories without an intake form take no answers.
+func AssessIntake(category, urgency string, answers map[string]string) (*IntakeAssessment, error) {
+	if _, ok := urgencyRank[urgency]; !ok {
+		return nil, ErrInvalidUrgency
+	}
+
+	form, ok := IntakeForms[category]
+	if !ok {
+		if len(answers) > 0 {
+			return nil, fmt.Errorf("%w: no intake questions for %s", ErrInvalidIntake, category)
+		}
+		return &IntakeAssessment{Urgency: urgency}, nil
+	}
+
+	questions := make(map[string]IntakeQuestion, len(form.Questions))
+	for _, q := range form.Questions {
+		questions[q.ID] = q
+	}
+	for id, answer := range answers {
+		q, ok := questions[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown question %s", ErrInvalidIntake, id)
+		}
+		if len(q.Options) > 0 && !containsString(q.Options, answer) {
+			return nil, fmt.Errorf("%w: %q is not an answer to %s", ErrInvalidIntake, answer, id)
+		}
+	}
+	// Answers are optional, but a partial set must cover the required questions
+	if len(answers) > 0 {
+		for _, q := range form.Questions {
+			if _, answered := answers[q.ID]; q.Required && !answered {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidIntake, q.ID)
+			}
+		}
+	}
+
+	assessment := &IntakeAssessment{Urgency: urgency}
+	for _, rule := range form.Rules {
+		if answers[rule.QuestionID] != rule.Answer {
+			continue
+		}
+		if rule.MaxUrgency != "" && urgencyRank[assessment.Urgency] > urgencyRank[rule.MaxUrgency] {
+			assessment.Urgency = rule.MaxUrgency
+		}
+		if rule.Hint != "" {
+			assessment.DiagnosisHints = append(assessment.DiagnosisHints, rule.Hint)
+		}
+	}
+	for _, rule := range form.Rules {
+		if answers[rule.QuestionID] != rule.Answer {
+			continue
+		}
+		if rule.MinUrgency != "" && urgencyRank[assessment.Urgency] < urgencyRank[rule.MinUrgency] {
+			assessment.Urgency = rule.MinUrgency
+		}
+	}
+
+	return assessment, nil
+}
+
 // =============================================================================
 // EMERGENCY CREATION AND MANAGEMENT
 // =============================================================================
@@ -187,10 +362,22 @@ func (s *Service) CreateEmergency(ctx context.Context, req *CreateEmergencyReque
 		return nil, ErrInvalidRequest
 	}
 
-	// Validate urgency level
-	slaMinutes, ok := responseSLAMinutes[req.Urgency]
-	if !ok {
-		return nil, ErrInvalidUrgency
+	// Refine the reported urgency from the category intake answers
+	assessment, err := AssessIntake(req.Category, req.Urgency, req.IntakeAnswers)
+	if err != nil {
+		return nil, err
+	}
+	req.Urgency = assessment.Urgency
+
+	slaMinutes := responseSLAMinutes[req.Urgency]
+
+	// Normalize the contact number so SMS updates can reach it
+	if req.ContactPhone != "" {
+		contactPhone, err := phone.Normalize(req.ContactPhone)
+		if err != nil {
+			return nil, ErrInvalidContactPhone
+		}
+		req.ContactPhone = contactPhone
 	}
 
 	emergency := &Emergency{
@@ -209,6 +396,9 @@ func (s *Service) CreateEmergency(ctx context.Context, req *CreateEmergencyReque
 		Latitude:           req.Latitude,
 		Longitude:          req.Longitude,
 		AccessInstructions: req.AccessInstructions,
+		ContactPhone:       req.ContactPhone,
+		IntakeAnswers:      req.IntakeAnswers,
+		DiagnosisHints:     assessment.DiagnosisHints,
 		Status:             "new",
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
@@ -222,16 +412,18 @@ func (s *Service) CreateEmergency(ctx context.Context, req *CreateEmergencyReque
 		INSERT INTO emergencies (
 			id, user_id, category, subcategory, urgency, title, description,
 			address, unit, city, state, postal_code, latitude, longitude,
-			access_instructions, status, response_deadline, arrival_deadline,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+			access_instructions, contact_phone, intake_answers, diagnosis_hints,
+			status, response_deadline, arrival_deadline, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
 	`
 
-	_, err := s.db.Exec(ctx, query,
+	intakeJSON, _ := json.Marshal(emergency.IntakeAnswers)
+	_, err = s.db.Exec(ctx, query,
 		emergency.ID, emergency.UserID, emergency.Category, emergency.Subcategory,
 		emergency.Urgency, emergency.Title, emergency.Description, emergency.Address,
 		emergency.Unit, emergency.City, emergency.State, emergency.PostalCode,
 		emergency.Latitude, emergency.Longitude, emergency.AccessInstructions,
+		emergency.ContactPhone, intakeJSON, emergency.DiagnosisHints,
 		emergency.Status, emergency.ResponseDeadline, emergency.ArrivalDeadline,
 		emergency.CreatedAt, emergency.UpdatedAt,
 	)
@@ -261,12 +453,103 @@ func (s *Service) CreateEmergency(ctx context.Context, req *CreateEmergencyReque
 	return emergency, nil
 }
 
+// ReorderOverrides holds the details a customer changed before resubmitting a
+// previous emergency. Empty fields keep the previous value.
+type ReorderOverrides struct {
+	Urgency            string `json:"urgency,omitempty"`
+	Subcategory        string `json:"subcategory,omitempty"`
+	Title              string `json:"title,omitempty"`
+	Description        string `json:"description,omitempty"`
+	Unit               string `json:"unit,omitempty"`
+	AccessInstructions string `json:"access_instructions,omitempty"`
+}
+
+// BuildReorderRequest clones a completed emergency's category, location and
+// access details into a new request, applying any overrides
+func BuildReorderRequest(previous *Emergency, userID uuid.UUID, overrides ReorderOverrides) (*CreateEmergencyRequest, error) {
+	if previous.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+	if previous.Status != "completed" {
+		return nil, ErrNotReorderable
+	}
+
+	req := &CreateEmergencyRequest{
+		UserID:             userID,
+		Category:           previous.Category,
+		Subcategory:        previous.Subcategory,
+		Urgency:            previous.Urgency,
+		Title:              previous.Title,
+		Description:        previous.Description,
+		Address:            previous.Address,
+		Unit:               previous.Unit,
+		City:               previous.City,
+		State:              previous.State,
+		PostalCode:         previous.PostalCode,
+		Latitude:           previous.Latitude,
+		Longitude:          previous.Longitude,
+		AccessInstructions: previous.AccessInstructions,
+		ContactPhone:       previous.ContactPhone,
+	}
+
+	if overrides.Urgency != "" {
+		if _, ok := responseSLAMinutes[overrides.Urgency]; !ok {
+			return nil, ErrInvalidUrgency
+		}
+		req.Urgency = overrides.Urgency
+	}
+	if overrides.Subcategory != "" {
+		req.Subcategory = overrides.Subcategory
+	}
+	if overrides.Title != "" {
+		req.Title = overrides.Title
+	}
+	if overrides.Description != "" {
+		req.Description = overrides.Description
+	}
+	if overrides.Unit != "" {
+		req.Unit = overrides.Unit
+	}
+	if overrides.AccessInstructions != "" {
+		req.AccessInstructions = overrides.AccessInstructions
+	}
+
+	return req, nil
+}
+
+// ReorderEmergency creates and dispatches a new emergency from one of the
+// user's previously completed requests
+func (s *Service) ReorderEmergency(ctx context.Context, previousID, userID uuid.UUID, overrides ReorderOverrides) (*Emergency, error) {
+	previous, err := s.GetEmergency(ctx, previousID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := BuildReorderRequest(previous, userID, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	emergency, err := s.CreateEmergency(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Emergency reordered",
+		zap.String("emergency_id", emergency.ID.String()),
+		zap.String("previous_id", previousID.String()),
+	)
+
+	return emergency, nil
+}
+
 // GetEmergency retrieves an emergency by ID
 func (s *Service) GetEmergency(ctx context.Context, id uuid.UUID) (*Emergency, error) {
 	query := `
 		SELECT id, user_id, category, subcategory, urgency, title, description,
 		       address, unit, city, state, postal_code, latitude, longitude,
-		       access_instructions, status, assigned_vendor_id, assigned_tech_id,
+		       access_instructions, COALESCE(contact_phone, ''), COALESCE(intake_answers, '{}'::jsonb),
+		       COALESCE(diagnosis_hints, '{}'), status, assigned_vendor_id, assigned_tech_id,
 		       tech_latitude, tech_longitude, estimated_arrival, actual_arrival,
 		       response_deadline, arrival_deadline, estimated_cost, final_cost,
 		       work_performed, created_at, updated_at, completed_at
@@ -279,6 +562,7 @@ func (s *Service) GetEmergency(ctx context.Context, id uuid.UUID) (*Emergency, e
 		&emergency.Urgency, &emergency.Title, &emergency.Description, &emergency.Address,
 		&emergency.Unit, &emergency.City, &emergency.State, &emergency.PostalCode,
 		&emergency.Latitude, &emergency.Longitude, &emergency.AccessInstructions,
+		&emergency.ContactPhone, &emergency.IntakeAnswers, &emergency.DiagnosisHints,
 		&emergency.Status, &emergency.AssignedVendorID, &emergency.AssignedTechID,
 		&emergency.TechLatitude, &emergency.TechLongitude, &emergency.EstimatedArrival,
 		&emergency.ActualArrival, &emergency.ResponseDeadline, &emergency.ArrivalDeadline,
@@ -336,6 +620,7 @@ func (s *Service) GetEmergencyStatus(ctx context.Context, emergencyID uuid.UUID)
 
 	// Calculate SLA status
 	status.SLAStatus = s.calculateSLAStatus(status.ResponseDeadline, status.ArrivalDeadline, status.Status)
+	status.SecondsRemaining, status.SLABreached = SLACountdown(status.ResponseDeadline, status.ArrivalDeadline, status.Status, time.Now())
 
 	return &status, nil
 }
@@ -938,23 +1223,41 @@ func (s *Service) calculateSLAStatus(responseDeadline, arrivalDeadline time.Time
 	return "on_track"
 }
 
-// calculateDistance calculates distance between two GPS coordinates using Haversine formula
-func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
-	const earthRadiusKm = 6371.0
-
-	dLat := toRadians(lat2 - lat1)
-	dLon := toRadians(lon2 - lon1)
-
-	lat1Rad := toRadians(lat1)
-	lat2Rad := toRadians(lat2)
+// SLACountdown returns the whole seconds left before the deadline an
+// emergency is currently racing: the response deadline until a technician
+// accepts, then the arrival deadline until they arrive. Remaining time is
+// clamped at zero and breached is set once the active deadline has passed.
+// Emergencies with no pending deadline report zero and not breached.
+func SLACountdown(responseDeadline, arrivalDeadline time.Time, status string, now time.Time) (int64, bool) {
+	var deadline time.Time
+	switch status {
+	case "new", "searching", "assigned", "no_technicians_available":
+		deadline = responseDeadline
+	case "accepted", "en_route":
+		deadline = arrivalDeadline
+	default:
+		return 0, false
+	}
 
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Sin(dLon/2)*math.Sin(dLon/2)*math.Cos(lat1Rad)*math.Cos(lat2Rad)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	remaining := deadline.Sub(now)
+	if remaining <= 0 {
+		return 0, true
+	}
+	return int64(remaining / time.Second), false
+}
 
-	return earthRadiusKm * c
+// calculateDistance returns the great-circle distance between two GPS
+// coordinates in km
+func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	return geo.Distance(GeoPoint{Latitude: lat1, Longitude: lon1}, GeoPoint{Latitude: lat2, Longitude: lon2})
 }
 
-func toRadians(deg float64) float64 {
-	return deg * math.Pi / 180.0
+// containsString reports whether values includes s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
@@ -9,10 +9,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
 	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -43,21 +47,24 @@ type Notification struct {
 
 type NotificationType string
 const (
-	TypeBookingCreated    NotificationType = "booking_created"
-	TypeBookingConfirmed  NotificationType = "booking_confirmed"
-	TypeBookingCancelled  NotificationType = "booking_cancelled"
-	TypePaymentReceived   NotificationType = "payment_received"
-	TypePaymentFailed     NotificationType = "payment_failed"
-	TypeEmergencyAssigned NotificationType = "emergency_assigned"
-	TypeEmergencyUpdate   NotificationType = "emergency_update"
-	TypeTechEnRoute       NotificationType = "tech_en_route"
-	TypeTechArrived       NotificationType = "tech_arrived"
-	TypeReferralReceived  NotificationType = "referral_received"
-	TypeReferralConverted NotificationType = "referral_converted"
-	TypeNewMessage        NotificationType = "new_message"
-	TypeReviewReceived    NotificationType = "review_received"
-	TypePromotion         NotificationType = "promotion"
-	TypeSystemAlert       NotificationType = "system_alert"
+	TypeBookingCreated     NotificationType = "booking_created"
+	TypeBookingConfirmed   NotificationType = "booking_confirmed"
+	TypeBookingCancelled   NotificationType = "booking_cancelled"
+	TypeBookingRescheduled NotificationType = "booking_rescheduled"
+	TypePaymentReceived    NotificationType = "payment_received"
+	TypePaymentFailed      NotificationType = "payment_failed"
+	TypePaymentRefunded    NotificationType = "payment_refunded"
+	TypeEmergencyAssigned  NotificationType = "emergency_assigned"
+	TypeEmergencyUpdate    NotificationType = "emergency_update"
+	TypeTechEnRoute        NotificationType = "tech_en_route"
+	TypeTechArrived        NotificationType = "tech_arrived"
+	TypeEventUpdate        NotificationType = "event_update"
+	TypeReferralReceived   NotificationType = "referral_received"
+	TypeReferralConverted  NotificationType = "referral_converted"
+	TypeNewMessage         NotificationType = "new_message"
+	TypeReviewReceived     NotificationType = "review_received"
+	TypePromotion          NotificationType = "promotion"
+	TypeSystemAlert        NotificationType = "system_alert"
 )
 
 type NotificationChannel string
@@ -134,6 +141,12 @@ type Config struct {
 	
 	// Templates
 	TemplateDir string
+	
+	// Deduplication window for identical notifications (default 10m)
+	DedupeTTL time.Duration
+	
+	// Retries of failed provider sends (zero fields use DefaultRetryPolicy)
+	Retry RetryPolicy
 }
 
 // Service handles notifications
@@ -143,6 +156,8 @@ type Service struct {
 	config    *Config
 	templates map[string]*template.Template
 	http      *http.Client
+	dedupe    *Deduplicator
+	dispatch  *Dispatcher
 }
 
 // NewService creates a new notification service
@@ -153,7 +168,13 @@ func NewService(db *pgxpool.Pool, cache *redis.Client, config *Config) *Service
 		config:    config,
 		templates: make(map[string]*template.Template),
 		http:      &http.Client{Timeout: 30 * time.Second},
+		dedupe:    NewDeduplicator(cache, config.DedupeTTL),
 	}
+	s.dispatch = NewDispatcher(config.Retry, s)
+	s.dispatch.Register(ChannelPush, s.sendPush)
+	s.dispatch.Register(ChannelEmail, s.sendEmail)
+	s.dispatch.Register(ChannelSMS, s.sendSMS)
+	s.dispatch.Register(ChannelInApp, s.sendInApp)
 	s.loadTemplates()
 	return s
 }
@@ -191,10 +212,22 @@ type SendRequest struct {
 	Data     map[string]interface{} `json:"data,omitempty"`
 	Priority NotificationPriority   `json:"priority"`
 	Channels []NotificationChannel  `json:"channels"` // Empty = all enabled channels
+	TargetID string                 `json:"target_id,omitempty"` // Entity the notification is about; enables dedupe
 }
 
-// Send sends a notification to a user
+// Send sends a notification to a user. Requests carrying a TargetID are
+// deduplicated so the same (user, type, target) is only sent once per TTL.
+// If any channel fails, the claim is released so a retry is not suppressed;
+// the retry sends on every channel again.
 func (s *Service) Send(ctx context.Context, req SendRequest) ([]*Notification, error) {
+	dedupeKey := ""
+	if req.TargetID != "" {
+		dedupeKey = DedupeKey(req.UserID, req.Type, req.TargetID)
+		if !s.dedupe.Claim(ctx, dedupeKey) {
+			return nil, nil // Duplicate of a recently sent notification
+		}
+	}
+	
 	// Get user preferences
 	prefs, err := s.GetUserPreferences(ctx, req.UserID)
 	if err != nil {
@@ -237,6 +270,7 @@ func (s *Service) Send(ctx context.Context, req SendRequest) ([]*Notification, e
 	}
 	
 	var notifications []*Notification
+	failed := false
 	
 	for _, channel := range channels {
 		notification := &Notification{
@@ -252,25 +286,9 @@ func (s *Service) Send(ctx context.Context, req SendRequest) ([]*Notification, e
 			CreatedAt: time.Now(),
 		}
 		
-		// Send via channel
-		var sendErr error
-		switch channel {
-		case ChannelPush:
-			sendErr = s.sendPush(ctx, notification)
-		case ChannelEmail:
-			sendErr = s.sendEmail(ctx, notification)
-		case ChannelSMS:
-			sendErr = s.sendSMS(ctx, notification)
-		case ChannelInApp:
-			sendErr = s.sendInApp(ctx, notification)
-		}
-		
-		if sendErr != nil {
-			notification.Status = StatusFailed
-		} else {
-			notification.Status = StatusSent
-			now := time.Now()
-			notification.SentAt = &now
+		// Send via channel, retrying transient provider failures
+		if err := s.dispatch.Dispatch(ctx, notification); err != nil {
+			failed = true
 		}
 		
 		// Save notification
@@ -278,9 +296,305 @@ func (s *Service) Send(ctx context.Context, req SendRequest) ([]*Notification, e
 		notifications = append(notifications, notification)
 	}
 	
+	if failed && dedupeKey != "" {
+		s.dedupe.Release(ctx, dedupeKey)
+	}
+	
 	return notifications, nil
 }
 
+// =============================================================================
+// DEDUPLICATION
+// =============================================================================
+
+const defaultDedupeTTL = 10 * time.Minute
+
+// Deduplicator collapses identical notifications fired from multiple code
+// paths. Claims are recorded in Redis so they hold across instances, with an
+// in-process fallback when Redis is unavailable.
+type Deduplicator struct {
+	cache *redis.Client
+	ttl   time.Duration
+	
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewDeduplicator creates a deduplicator; a zero ttl uses the default window
+func NewDeduplicator(cache *redis.Client, ttl time.Duration) *Deduplicator {
+	if ttl <= 0 {
+		ttl = defaultDedupeTTL
+	}
+	return &Deduplicator{
+		cache: cache,
+		ttl:   ttl,
+		seen:  make(map[string]time.Time),
+	}
+}
+
+// DedupeKey identifies a notification by recipient, type and target entity
+func DedupeKey(userID uuid.UUID, notificationType NotificationType, targetID string) string {
+	return fmt.Sprintf("notification:dedupe:%s:%s:%s", userID, notificationType, targetID)
+}
+
+// Claim returns true the first time a key is seen within the TTL and false
+// for every repeat until it expires
+func (d *Deduplicator) Claim(ctx context.Context, key string) bool {
+	if d.cache != nil {
+		claimed, err := d.cache.SetNX(ctx, key, time.Now().Unix(), d.ttl).Result()
+		if err == nil {
+			return claimed
+		}
+	}
+	
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	
+	now := time.Now()
+	if expiresAt, ok := d.seen[key]; ok && now.Before(expiresAt) {
+		return false
+	}
+	d.seen[key] = now.Add(d.ttl)
+	
+	// Opportunistically drop expired claims
+	for k, expiresAt := range d.seen {
+		if now.After(expiresAt) {
+			delete(d.seen, k)
+		}
+	}
+	return true
+}
+
+// Release gives up a claim so the next Claim of the key succeeds, for a
+// notification that failed to send
+func (d *Deduplicator) Release(ctx context.Context, key string) {
+	if d.cache != nil {
+		d.cache.Del(ctx, key)
+	}
+	
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, key)
+}
+
+// =============================================================================
+// RETRIES & DEAD LETTERS
+// =============================================================================
+
+// RetryPolicy bounds how a failed provider send is retried
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts, including the first
+	BaseDelay   time.Duration // wait before the first retry, doubling after each
+	MaxDelay    time.Duration // cap on any single wait
+}
+
+// DefaultRetryPolicy retries briefly, since sends happen inline with the
+// request that triggered them
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	return p
+}
+
+// Backoff returns the wait before the given retry (1 for the first retry).
+// A provider asking to be left alone for longer (HTTP 429 Retry-After) is
+// honoured, up to MaxDelay.
+func (p RetryPolicy) Backoff(retry int, err error) time.Duration {
+	p = p.withDefaults()
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	var limited *RateLimitedError
+	if errors.As(err, &limited) && limited.RetryAfter > delay {
+		delay = limited.RetryAfter
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// PermanentError marks a send failure that retrying cannot fix, such as a
+// missing phone number or a provider rejecting the request outright
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent wraps err so the dispatcher does not retry it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err should not be retried
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// RateLimitedError is a provider throttling us, with how long it asked us
+// to wait when it said
+type RateLimitedError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string { return e.Err.Error() }
+func (e *RateLimitedError) Unwrap() error { return e.Err }
+
+// ProviderStatusError classifies a provider's non-success HTTP response:
+// 429 is rate limited, other 4xx except 408 are permanent, and everything
+// else is worth retrying
+func ProviderStatusError(provider string, resp *http.Response) error {
+	err := fmt.Errorf("%s failed with status %d", provider, resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		limited := &RateLimitedError{Err: err}
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			limited.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return limited
+	case resp.StatusCode == http.StatusRequestTimeout:
+		return err
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return Permanent(err)
+	default:
+		return err
+	}
+}
+
+// DeadLetter records a notification whose delivery was given up on
+type DeadLetter struct {
+	NotificationID uuid.UUID           `json:"notification_id"`
+	UserID         uuid.UUID           `json:"user_id"`
+	Type           NotificationType    `json:"type"`
+	Channel        NotificationChannel `json:"channel"`
+	Attempts       int                 `json:"attempts"`
+	LastError      string              `json:"last_error"`
+	Permanent      bool                `json:"permanent"`
+	FailedAt       time.Time           `json:"failed_at"`
+}
+
+// DeadLetterSink stores dead letters for inspection and replay
+type DeadLetterSink interface {
+	DeadLetter(ctx context.Context, letter DeadLetter) error
+}
+
+// SendFunc delivers a notification through one provider
+type SendFunc func(ctx context.Context, notification *Notification) error
+
+// Dispatcher routes notifications to their channel's provider, retrying
+// transient failures with exponential backoff and dead-lettering the ones
+// that fail permanently or run out of attempts
+type Dispatcher struct {
+	policy      RetryPolicy
+	senders     map[NotificationChannel]SendFunc
+	deadLetters DeadLetterSink
+}
+
+// NewDispatcher creates a dispatcher; a nil sink drops dead letters
+func NewDispatcher(policy RetryPolicy, deadLetters DeadLetterSink) *Dispatcher {
+	return &Dispatcher{
+		policy:      policy.withDefaults(),
+		senders:     make(map[NotificationChannel]SendFunc),
+		deadLetters: deadLetters,
+	}
+}
+
+// Register sets the provider for a channel
+func (d *Dispatcher) Register(channel NotificationChannel, send SendFunc) {
+	d.senders[channel] = send
+}
+
+// Dispatch sends the notification and sets its status. It returns the last
+// send error once delivery has been given up on.
+func (d *Dispatcher) Dispatch(ctx context.Context, notification *Notification) error {
+	send, ok := d.senders[notification.Channel]
+	if !ok {
+		err := Permanent(fmt.Errorf("no provider for channel %s", notification.Channel))
+		d.fail(ctx, notification, 0, err)
+		return err
+	}
+	
+	var err error
+	attempts := 0
+	for attempts < d.policy.MaxAttempts {
+		if attempts > 0 {
+			timer := time.NewTimer(d.policy.Backoff(attempts, err))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				d.fail(ctx, notification, attempts, err)
+				return err
+			case <-timer.C:
+			}
+		}
+		
+		attempts++
+		if err = send(ctx, notification); err == nil {
+			notification.Status = StatusSent
+			now := time.Now()
+			notification.SentAt = &now
+			return nil
+		}
+		if IsPermanent(err) {
+			break
+		}
+	}
+	
+	d.fail(ctx, notification, attempts, err)
+	return err
+}
+
+func (d *Dispatcher) fail(ctx context.Context, notification *Notification, attempts int, err error) {
+	notification.Status = StatusFailed
+	if d.deadLetters == nil {
+		return
+	}
+	// Record the dead letter even if the caller has gone away
+	d.deadLetters.DeadLetter(context.WithoutCancel(ctx), DeadLetter{
+		NotificationID: notification.ID,
+		UserID:         notification.UserID,
+		Type:           notification.Type,
+		Channel:        notification.Channel,
+		Attempts:       attempts,
+		LastError:      err.Error(),
+		Permanent:      IsPermanent(err),
+		FailedAt:       time.Now(),
+	})
+}
+
+// DeadLetter stores a notification whose delivery was given up on
+func (s *Service) DeadLetter(ctx context.Context, letter DeadLetter) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO notification_dead_letters (
+			notification_id, user_id, type, channel,
+			attempts, last_error, permanent, failed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, letter.NotificationID, letter.UserID, letter.Type, letter.Channel,
+		letter.Attempts, letter.LastError, letter.Permanent, letter.FailedAt)
+	return err
+}
+
 // =============================================================================
 // PUSH NOTIFICATIONS
 // =============================================================================
@@ -288,8 +602,11 @@ func (s *Service) Send(ctx context.Context, req SendRequest) ([]*Notification, e
 func (s *Service) sendPush(ctx context.Context, notification *Notification) error {
 	// Get user's device tokens
 	tokens, err := s.getDeviceTokens(ctx, notification.UserID)
-	if err != nil || len(tokens) == 0 {
-		return fmt.Errorf("no device tokens found")
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return Permanent(fmt.Errorf("no device tokens found"))
 	}
 	
 	// Send via OneSignal
@@ -320,7 +637,7 @@ func (s *Service) sendPush(ctx context.Context, notification *Notification) erro
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("push notification failed with status %d", resp.StatusCode)
+		return ProviderStatusError("push notification", resp)
 	}
 	
 	return nil
@@ -414,7 +731,14 @@ func (s *Service) sendEmail(ctx context.Context, notification *Notification) err
 	auth := smtp.PlainAuth("", s.config.SMTPUser, s.config.SMTPPassword, s.config.SMTPHost)
 	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
 	
-	return smtp.SendMail(addr, auth, s.config.FromEmail, []string{email}, []byte(msg))
+	err = smtp.SendMail(addr, auth, s.config.FromEmail, []string{email}, []byte(msg))
+	
+	// 5xx SMTP replies (bad mailbox, rejected sender) won't succeed on retry
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
 }
 
 // =============================================================================
@@ -425,8 +749,11 @@ func (s *Service) sendSMS(ctx context.Context, notification *Notification) error
 	// Get user phone
 	var phone string
 	err := s.db.QueryRow(ctx, "SELECT phone FROM users WHERE id = $1", notification.UserID).Scan(&phone)
-	if err != nil || phone == "" {
-		return fmt.Errorf("no phone number found")
+	if err != nil {
+		return err
+	}
+	if phone == "" {
+		return Permanent(fmt.Errorf("no phone number found"))
 	}
 	
 	// Send via Termii
@@ -452,7 +779,7 @@ func (s *Service) sendSMS(ctx context.Context, notification *Notification) error
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("SMS failed with status %d", resp.StatusCode)
+		return ProviderStatusError("SMS", resp)
 	}
 	
 	return nil
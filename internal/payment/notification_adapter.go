@@ -0,0 +1,46 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/notification"
+)
+
+// NotificationAdapter adapts the notification service to tell customers
+// about their payments
+type NotificationAdapter struct {
+	service *notification.Service
+}
+
+// NewNotificationAdapter creates a new notification adapter
+func NewNotificationAdapter(service *notification.Service) *NotificationAdapter {
+	return &NotificationAdapter{
+		service: service,
+	}
+}
+
+// NotifyRefund tells the customer a refund has been sent back to them
+func (a *NotificationAdapter) NotifyRefund(ctx context.Context, refund *Refund) error {
+	amount := fmt.Sprintf("%s %.2f", refund.Currency, float64(refund.Amount)/100)
+	destination := "your original payment method"
+	if refund.Provider == ProviderInternal {
+		destination = "your wallet"
+	}
+
+	_, err := a.service.Send(ctx, notification.SendRequest{
+		UserID:   refund.UserID,
+		Type:     notification.TypePaymentRefunded,
+		Title:    "Refund processed",
+		Body:     fmt.Sprintf("We've refunded %s to %s.", amount, destination),
+		Priority: notification.PriorityHigh,
+		Data: map[string]interface{}{
+			"refund_id":  refund.ID.String(),
+			"payment_id": refund.PaymentID.String(),
+			"amount":     refund.Amount,
+			"currency":   refund.Currency,
+		},
+		TargetID: refund.ID.String(),
+	})
+	return err
+}
@@ -9,16 +9,21 @@ package payment
 import (
 	"context"
 	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 )
@@ -134,28 +139,129 @@ type Config struct {
 	FlutterwavePublicKey string
 	StripeSecretKey      string
 	StripePublicKey      string
-	WebhookSecret        string
+	WebhookSecret        string // Flutterwave's webhook secret hash; Paystack signs with PaystackSecretKey
 	DefaultCurrency      string
 	PlatformFeePercent   float64 // Platform fee percentage
+	TierFeePercents      map[string]float64 // Fee percentage by vendor subscription tier; nil uses DefaultTierFeePercents
 	EscrowExpiryDays     int
 }
 
+// dbExecutor is what the service queries through: the pool, or a transaction
+// when a change has to commit together with the caller's
+type dbExecutor interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // Service handles payments
 type Service struct {
-	db     *pgxpool.Pool
+	db     dbExecutor
 	cache  *redis.Client
 	config *Config
 	http   *http.Client
+	
+	webhookEvents  WebhookEventStore
+	webhookApplier WebhookEventApplier
+	escrows        EscrowStore
+	refunds        RefundStore
+	refundGateway  RefundGateway
+	refundNotifier RefundNotifier
+	vendorTiers    VendorTierStore
 }
 
 // NewService creates a new payment service
 func NewService(db *pgxpool.Pool, cache *redis.Client, config *Config) *Service {
-	return &Service{
-		db:     db,
+	s := &Service{
 		cache:  cache,
 		config: config,
 		http:   &http.Client{Timeout: 30 * time.Second},
 	}
+	if db != nil {
+		s.db = db
+		s.webhookEvents = NewPostgresWebhookEventStore(db)
+		s.escrows = NewPostgresEscrowStore(db)
+		s.refunds = NewPostgresRefundStore(db)
+		s.vendorTiers = NewPostgresVendorTierStore(db)
+	}
+	return s
+}
+
+// inTx returns a copy of the service whose queries run in tx
+func (s *Service) inTx(tx pgx.Tx) *Service {
+	txs := *s
+	txs.db = tx
+	return &txs
+}
+
+// withTx runs fn against a copy of the service bound to a new transaction,
+// committing only if fn succeeds
+func (s *Service) withTx(ctx context.Context, fn func(txs *Service) error) error {
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		return fn(s.inTx(tx))
+	})
+}
+
+// =============================================================================
+// PLATFORM FEES
+// =============================================================================
+
+// DefaultTierFeePercents is the platform fee percentage charged to vendors on
+// each subscription tier. Vendors on other tiers pay PlatformFeePercent.
+var DefaultTierFeePercents = map[string]float64{
+	"free":  15,
+	"basic": 12,
+	"pro":   10,
+}
+
+// VendorTierStore looks up vendors' subscription tiers
+type VendorTierStore interface {
+	// VendorTier returns the vendor's subscription tier, or "" when they
+	// have none
+	VendorTier(ctx context.Context, vendorID uuid.UUID) (string, error)
+}
+
+// FeeRate returns the platform fee percentage charged to a vendor
+type FeeRate func(ctx context.Context, vendorID uuid.UUID) (float64, error)
+
+// SetVendorTierStore sets where vendors' subscription tiers are looked up
+func (s *Service) SetVendorTierStore(store VendorTierStore) {
+	s.vendorTiers = store
+}
+
+// tierFeePercent returns the fee percentage for a tier, or PlatformFeePercent
+// when the tier has no rate of its own
+func (s *Service) tierFeePercent(tier string) float64 {
+	rates := s.config.TierFeePercents
+	if rates == nil {
+		rates = DefaultTierFeePercents
+	}
+	if rate, ok := rates[strings.ToLower(tier)]; ok {
+		return rate
+	}
+	return s.config.PlatformFeePercent
+}
+
+// CalculatePlatformFee returns the platform's cut of amount for a vendor at
+// the rate of their subscription tier. The fee is in the same units as
+// amount.
+func (s *Service) CalculatePlatformFee(ctx context.Context, vendorID uuid.UUID, amount float64) (fee float64, ratePct float64, err error) {
+	tier := ""
+	if s.vendorTiers != nil && vendorID != uuid.Nil {
+		tier, err = s.vendorTiers.VendorTier(ctx, vendorID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to look up vendor tier: %w", err)
+		}
+	}
+	ratePct = s.tierFeePercent(tier)
+	return amount * ratePct / 100, ratePct, nil
+}
+
+// vendorFeeRate is the FeeRate of the vendor's subscription tier
+func (s *Service) vendorFeeRate(ctx context.Context, vendorID uuid.UUID) (float64, error) {
+	_, ratePct, err := s.CalculatePlatformFee(ctx, vendorID, 0)
+	return ratePct, err
 }
 
 // =============================================================================
@@ -191,8 +297,16 @@ func (s *Service) InitializePayment(ctx context.Context, req InitializePaymentRe
 	// Generate unique reference
 	reference := fmt.Sprintf("VND-%s-%d", uuid.New().String()[:8], time.Now().Unix())
 	
-	// Calculate fees
-	platformFee := int64(float64(req.Amount) * s.config.PlatformFeePercent / 100)
+	// Calculate fees at the vendor's subscription tier rate
+	vendorID := uuid.Nil
+	if req.VendorID != nil {
+		vendorID = *req.VendorID
+	}
+	fee, _, err := s.CalculatePlatformFee(ctx, vendorID, float64(req.Amount))
+	if err != nil {
+		return nil, err
+	}
+	platformFee := int64(fee)
 	netAmount := req.Amount - platformFee
 	
 	// Create transaction record
@@ -222,7 +336,6 @@ func (s *Service) InitializePayment(ctx context.Context, req InitializePaymentRe
 	
 	// Initialize with provider
 	var authURL, accessCode string
-	var err error
 	
 	switch req.Provider {
 	case ProviderPaystack:
@@ -240,7 +353,8 @@ func (s *Service) InitializePayment(ctx context.Context, req InitializePaymentRe
 		return nil, err
 	}
 	
-	// If escrow, create escrow account
+	// If escrow, create escrow account. It holds the full amount; the
+	// platform fee is taken when it is released.
 	if req.UseEscrow && req.VendorID != nil && req.BookingID != nil {
 		escrow := &EscrowAccount{
 			ID:              uuid.New(),
@@ -248,7 +362,7 @@ func (s *Service) InitializePayment(ctx context.Context, req InitializePaymentRe
 			BookingID:       *req.BookingID,
 			CustomerID:      req.UserID,
 			VendorID:        *req.VendorID,
-			Amount:          netAmount,
+			Amount:          req.Amount,
 			Currency:        req.Currency,
 			Status:          EscrowHeld,
 			ReleaseCondition: "service_completed",
@@ -362,16 +476,34 @@ func (s *Service) VerifyPaystack(ctx context.Context, reference string) (*Transa
 	}
 	
 	txn.UpdatedAt = time.Now()
-	s.saveTransaction(ctx, txn)
-	
-	// If successful and has escrow, update escrow status
-	if txn.Status == StatusSuccess && txn.VendorID != nil {
-		s.updateEscrowOnPayment(ctx, txn.ID)
+	if err := s.recordPayment(ctx, txn); err != nil {
+		return nil, err
 	}
 	
 	return txn, nil
 }
 
+// recordPayment saves a verified transaction. A successful payment is
+// posted to the ledger and, if it has a vendor, its escrow is held, all in
+// one transaction.
+func (s *Service) recordPayment(ctx context.Context, txn *Transaction) error {
+	return s.withTx(ctx, func(txs *Service) error {
+		if err := txs.saveTransaction(ctx, txn); err != nil {
+			return err
+		}
+		if txn.Status != StatusSuccess || txn.Type != TypePayment || txn.Amount <= 0 {
+			return nil
+		}
+		if err := NewLedger(txs.db).Post(ctx, txn.LedgerEntries()); err != nil {
+			return err
+		}
+		if txn.VendorID != nil {
+			return txs.updateEscrowOnPayment(ctx, txn)
+		}
+		return nil
+	})
+}
+
 // =============================================================================
 // FLUTTERWAVE INTEGRATION
 // =============================================================================
@@ -440,59 +572,216 @@ func (s *Service) createEscrow(ctx context.Context, escrow *EscrowAccount) error
 	return err
 }
 
-func (s *Service) updateEscrowOnPayment(ctx context.Context, transactionID uuid.UUID) error {
-	_, err := s.db.Exec(ctx, 
+// updateEscrowOnPayment holds a paid transaction's escrow. It records a money
+// movement, so it must run in the transaction that records the payment.
+func (s *Service) updateEscrowOnPayment(ctx context.Context, txn *Transaction) error {
+	tag, err := s.db.Exec(ctx, 
 		"UPDATE escrow_accounts SET status = $1 WHERE transaction_id = $2",
-		EscrowHeld, transactionID,
+		EscrowHeld, txn.ID,
 	)
-	return err
+	if err != nil || tag.RowsAffected() == 0 || txn.BookingID == nil {
+		return err
+	}
+	
+	// The captured payment is now held for the booking
+	return NewLedger(s.db).Post(ctx, ledgerTransfer("escrow_hold:"+txn.ID.String(), "Escrow hold",
+		txn.Amount, txn.Currency, ProviderAccount(txn.Provider), EscrowLedgerAccount(*txn.BookingID)))
 }
 
-// ReleaseEscrow releases held funds to vendor
-func (s *Service) ReleaseEscrow(ctx context.Context, bookingID uuid.UUID) error {
-	var escrow EscrowAccount
-	err := s.db.QueryRow(ctx, `
-		SELECT id, vendor_id, amount, currency, status 
-		FROM escrow_accounts WHERE booking_id = $1
-	`, bookingID).Scan(&escrow.ID, &escrow.VendorID, &escrow.Amount, &escrow.Currency, &escrow.Status)
-	
+// Escrow errors
+var (
+	ErrEscrowNotFound  = errors.New("escrow not found")
+	ErrEscrowNotHeld   = errors.New("escrow not in held status")
+	ErrEscrowDisputed  = errors.New("escrow has an open dispute")
+	ErrEscrowForbidden = errors.New("not allowed to release this escrow")
+)
+
+// AutoReleasedBy marks a release made by SweepExpiredEscrows rather than a
+// person
+var AutoReleasedBy = uuid.Nil
+
+// EscrowReleaser is who asked for an escrow to be released. Only the
+// booking's customer or an admin may release it.
+type EscrowReleaser struct {
+	UserID uuid.UUID
+	Admin  bool
+}
+
+// autoReleaser releases expired escrows on the platform's behalf
+var autoReleaser = EscrowReleaser{UserID: AutoReleasedBy, Admin: true}
+
+// CanRelease reports whether the releaser may release escrow
+func (r EscrowReleaser) CanRelease(escrow *EscrowAccount) bool {
+	return r.Admin || (r.UserID != uuid.Nil && r.UserID == escrow.CustomerID)
+}
+
+// EscrowRelease is the payout of a released escrow. The vendor is paid the
+// held amount less the platform fee.
+type EscrowRelease struct {
+	EscrowID   uuid.UUID `json:"escrow_id"`
+	BookingID  uuid.UUID `json:"booking_id"`
+	VendorID   uuid.UUID `json:"vendor_id"`
+	Amount     int64     `json:"amount"` // In kobo/cents
+	Fee        int64     `json:"fee"`
+	Payout     int64     `json:"payout"`
+	Currency   string    `json:"currency"`
+	Reference  string    `json:"reference"` // ledger entry
+	ReleasedBy uuid.UUID `json:"released_by"`
+	ReleasedAt time.Time `json:"released_at"`
+}
+
+// NewEscrowRelease prices the release of an escrow. The ledger reference is
+// derived from the escrow, so an escrow can only ever have one.
+func NewEscrowRelease(escrow *EscrowAccount, feePercent float64, releasedBy uuid.UUID, now time.Time) *EscrowRelease {
+	fee := int64(float64(escrow.Amount) * feePercent / 100)
+	return &EscrowRelease{
+		EscrowID:   escrow.ID,
+		BookingID:  escrow.BookingID,
+		VendorID:   escrow.VendorID,
+		Amount:     escrow.Amount,
+		Fee:        fee,
+		Payout:     escrow.Amount - fee,
+		Currency:   escrow.Currency,
+		Reference:  "ESC-" + escrow.ID.String(),
+		ReleasedBy: releasedBy,
+		ReleasedAt: now,
+	}
+}
+
+// ApplyRefund takes a completed refund of the booking's payment out of its
+// escrow, so the refunded amount is never also paid to the vendor. An
+// escrow with nothing left is marked refunded. It reports whether the escrow
+// changed: one already released or refunded is left as it is.
+func (e *EscrowAccount) ApplyRefund(amount int64) bool {
+	if e.Status != EscrowHeld && e.Status != EscrowDisputed {
+		return false
+	}
+	e.Amount -= amount
+	if e.Amount <= 0 {
+		e.Amount = 0
+		e.Status = EscrowRefunded
+	}
+	return true
+}
+
+// CheckEscrowReleasable reports why an escrow cannot be released
+func CheckEscrowReleasable(escrow *EscrowAccount) error {
+	switch {
+	case escrow.Status == EscrowDisputed || escrow.DisputeID != nil:
+		return ErrEscrowDisputed
+	case escrow.Status != EscrowHeld:
+		return ErrEscrowNotHeld
+	}
+	return nil
+}
+
+// EscrowStore holds escrow accounts and moves their funds
+type EscrowStore interface {
+	// ReleaseEscrow pays out a booking's held escrow in one transaction: the
+	// escrow is marked released, the vendor's wallet credited and the
+	// release posted to the ledger. An escrow that was already released
+	// returns its earlier release with applied false. The fee is charged at
+	// feeRate's percentage for the escrow's vendor. A releaser who may not
+	// release the escrow gets ErrEscrowForbidden.
+	ReleaseEscrow(ctx context.Context, bookingID uuid.UUID, by EscrowReleaser, feeRate FeeRate, now time.Time) (release *EscrowRelease, applied bool, err error)
+	// ExpiredEscrows lists the bookings whose escrow has been held since
+	// before cutoff without a dispute, oldest first
+	ExpiredEscrows(ctx context.Context, cutoff time.Time) ([]uuid.UUID, error)
+}
+
+// SetEscrowStore sets where escrows are held
+func (s *Service) SetEscrowStore(store EscrowStore) {
+	s.escrows = store
+}
+
+// ReleaseEscrow releases a booking's held funds to the vendor, less the
+// platform fee, on the say of its customer or an admin. Releasing again
+// returns the original release.
+func (s *Service) ReleaseEscrow(ctx context.Context, bookingID uuid.UUID, by EscrowReleaser) (*EscrowRelease, error) {
+	if s.escrows == nil {
+		return nil, errors.New("escrow store not configured")
+	}
+	release, _, err := s.escrows.ReleaseEscrow(ctx, bookingID, by, s.vendorFeeRate, time.Now())
 	if err != nil {
-		return errors.New("escrow not found")
+		return nil, err
 	}
-	
-	if escrow.Status != EscrowHeld {
-		return errors.New("escrow not in held status")
+	return release, nil
+}
+
+// SweepExpiredEscrows auto-releases escrows held for longer than
+// EscrowExpiryDays without a dispute, returning how many it released
+func (s *Service) SweepExpiredEscrows(ctx context.Context) (int, error) {
+	if s.escrows == nil {
+		return 0, errors.New("escrow store not configured")
 	}
-	
-	// Credit vendor wallet
-	if err := s.creditWallet(ctx, escrow.VendorID, escrow.Amount, escrow.Currency); err != nil {
-		return err
+	if s.config.EscrowExpiryDays <= 0 {
+		return 0, nil
 	}
 	
-	// Update escrow status
 	now := time.Now()
-	_, err = s.db.Exec(ctx, 
-		"UPDATE escrow_accounts SET status = $1, released_at = $2 WHERE id = $3",
-		EscrowReleased, now, escrow.ID,
-	)
+	bookingIDs, err := s.escrows.ExpiredEscrows(ctx, now.AddDate(0, 0, -s.config.EscrowExpiryDays))
+	if err != nil {
+		return 0, err
+	}
 	
-	return err
+	released := 0
+	for _, bookingID := range bookingIDs {
+		_, applied, err := s.escrows.ReleaseEscrow(ctx, bookingID, autoReleaser, s.vendorFeeRate, now)
+		switch {
+		case errors.Is(err, ErrEscrowDisputed), errors.Is(err, ErrEscrowNotHeld):
+			// Disputed or settled since it was listed
+			continue
+		case err != nil:
+			return released, err
+		}
+		if applied {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// SettleCancelledEscrow pays what is left of a cancelled booking's escrow,
+// once its refund has been taken out, to the vendor less the platform fee.
+// A booking without an escrow, or whose escrow was fully refunded or already
+// released, has nothing to settle and returns nil.
+func (s *Service) SettleCancelledEscrow(ctx context.Context, bookingID uuid.UUID) (*EscrowRelease, error) {
+	if s.escrows == nil {
+		return nil, errors.New("escrow store not configured")
+	}
+	release, _, err := s.escrows.ReleaseEscrow(ctx, bookingID, autoReleaser, s.vendorFeeRate, time.Now())
+	if errors.Is(err, ErrEscrowNotFound) || errors.Is(err, ErrEscrowNotHeld) {
+		return nil, nil
+	}
+	return release, err
 }
 
-// RefundEscrow refunds held funds to customer
+// RefundEscrow refunds held funds to customer. The escrow is locked and
+// every change, ledger posting included, commits together.
 func (s *Service) RefundEscrow(ctx context.Context, bookingID uuid.UUID, reason string) error {
+	return s.withTx(ctx, func(txs *Service) error {
+		return txs.refundEscrow(ctx, bookingID, reason)
+	})
+}
+
+func (s *Service) refundEscrow(ctx context.Context, bookingID uuid.UUID, reason string) error {
 	var escrow EscrowAccount
 	err := s.db.QueryRow(ctx, `
 		SELECT id, customer_id, amount, currency, status, transaction_id 
 		FROM escrow_accounts WHERE booking_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+		FOR UPDATE
 	`, bookingID).Scan(&escrow.ID, &escrow.CustomerID, &escrow.Amount, &escrow.Currency, &escrow.Status, &escrow.TransactionID)
-	
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrEscrowNotFound
+	}
 	if err != nil {
-		return errors.New("escrow not found")
+		return err
 	}
 	
 	if escrow.Status != EscrowHeld {
-		return errors.New("escrow not in held status")
+		return ErrEscrowNotHeld
 	}
 	
 	// Create refund transaction
@@ -510,7 +799,9 @@ func (s *Service) RefundEscrow(ctx context.Context, bookingID uuid.UUID, reason
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
-	s.saveTransaction(ctx, refund)
+	if err := s.saveTransaction(ctx, refund); err != nil {
+		return err
+	}
 	
 	// Credit customer wallet
 	if err := s.creditWallet(ctx, escrow.CustomerID, escrow.Amount, escrow.Currency); err != nil {
@@ -522,138 +813,862 @@ func (s *Service) RefundEscrow(ctx context.Context, bookingID uuid.UUID, reason
 		"UPDATE escrow_accounts SET status = $1 WHERE id = $2",
 		EscrowRefunded, escrow.ID,
 	)
+	if err != nil {
+		return err
+	}
 	
-	return err
+	return NewLedger(s.db).Post(ctx, ledgerTransfer("escrow_refund:"+escrow.ID.String(), "Refund: "+reason,
+		escrow.Amount, escrow.Currency, EscrowLedgerAccount(bookingID), WalletAccount(escrow.CustomerID)))
 }
 
 // =============================================================================
-// WALLET
+// REFUNDS
 // =============================================================================
 
-// GetOrCreateWallet gets or creates a user wallet
-func (s *Service) GetOrCreateWallet(ctx context.Context, userID uuid.UUID, currency string) (*Wallet, error) {
-	var wallet Wallet
-	err := s.db.QueryRow(ctx, `
-		SELECT id, user_id, balance, pending_balance, currency, is_active, created_at, updated_at
-		FROM wallets WHERE user_id = $1 AND currency = $2
-	`, userID, currency).Scan(
-		&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.PendingBalance,
-		&wallet.Currency, &wallet.IsActive, &wallet.CreatedAt, &wallet.UpdatedAt,
-	)
-	
-	if err == nil {
-		return &wallet, nil
+// Refund errors
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrPaymentNotRefundable = errors.New("payment cannot be refunded")
+	ErrInvalidRefundAmount  = errors.New("refund amount must be greater than 0")
+	ErrRefundExceedsPayment = errors.New("refund exceeds the amount left to refund")
+	// ErrRefundRejected marks a provider turning a refund down, as opposed
+	// to a request whose outcome is unknown
+	ErrRefundRejected = errors.New("provider rejected the refund")
+	// ErrRefundPending is returned when the provider's answer to a refund
+	// was lost; the refund stays pending for ReconcilePendingRefunds
+	ErrRefundPending = errors.New("refund outcome unknown, left pending")
+	// ErrRefundExists is returned by a RefundStore asked to reserve a
+	// refund already recorded under the same ID
+	ErrRefundExists = errors.New("refund already recorded")
+)
+
+type RefundStatus string
+const (
+	RefundPending   RefundStatus = "pending"
+	RefundCompleted RefundStatus = "completed"
+	RefundFailed    RefundStatus = "failed"
+)
+
+// Refund returns some or all of a captured payment to the customer
+type Refund struct {
+	ID          uuid.UUID       `json:"id"`
+	PaymentID   uuid.UUID       `json:"payment_id"`
+	UserID      uuid.UUID       `json:"user_id"`
+	BookingID   *uuid.UUID      `json:"booking_id,omitempty"`
+	Provider    PaymentProvider `json:"provider"`
+	Amount      int64           `json:"amount"` // In kobo/cents
+	Currency    string          `json:"currency"`
+	Reason      string          `json:"reason"`
+	Status      RefundStatus    `json:"status"`
+	ProviderRef string          `json:"provider_reference,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// PendingRefund is a refund whose outcome was never recorded, with the
+// payment it returns money from
+type PendingRefund struct {
+	Refund  Refund
+	Payment Transaction
+}
+
+// RefundStore records refunds against payments
+type RefundStore interface {
+	// ReserveRefund loads the payment and records the refund as pending,
+	// unless the payment's pending and completed refunds would then exceed
+	// what was captured. The refund's payment details are filled in. A
+	// refund already pending or completed under the same ID is not reserved
+	// again: it is filled in from its record and ErrRefundExists returned
+	// with the payment. A failed one is reserved again.
+	ReserveRefund(ctx context.Context, refund *Refund) (*Transaction, error)
+	// FinishRefund records whether the provider made a pending refund,
+	// crediting the customer's wallet for a completed internal refund and
+	// posting a completed refund to the ledger in the same transaction. A
+	// completed refund of a booking's payment is taken out of the booking's
+	// escrow with EscrowAccount.ApplyRefund, under the escrow's lock. A
+	// payment whose completed refunds reach its amount is marked refunded.
+	// A refund that is no longer pending is left as it is.
+	FinishRefund(ctx context.Context, refund *Refund) error
+	// PendingRefunds lists refunds still pending that were reserved before
+	// cutoff, oldest first
+	PendingRefunds(ctx context.Context, cutoff time.Time) ([]PendingRefund, error)
+	// BookingPayment returns the ID of a booking's captured payment, or
+	// ErrPaymentNotFound
+	BookingPayment(ctx context.Context, bookingID uuid.UUID) (uuid.UUID, error)
+	// EmergencyPayment returns the ID of the captured payment whose metadata
+	// carries a HomeRescue emergency_id, and how much of it, in minor units,
+	// is not yet refunded or being refunded, or ErrPaymentNotFound
+	EmergencyPayment(ctx context.Context, emergencyID uuid.UUID) (uuid.UUID, int64, error)
+}
+
+// RefundGateway returns money through the provider a payment was made with
+type RefundGateway interface {
+	// RefundWithProvider asks the provider to make the refund, tagged with
+	// the refund's ID. A refund the provider turned down is reported with
+	// an error wrapping ErrRefundRejected; any other error leaves it unknown
+	// whether the refund was made.
+	RefundWithProvider(ctx context.Context, payment *Transaction, refund *Refund) (providerRef string, err error)
+	// FindRefund looks up a refund the provider was asked to make, for a
+	// refund whose outcome was never recorded
+	FindRefund(ctx context.Context, payment *Transaction, refund *Refund) (providerRef string, found bool, err error)
+}
+
+// refundSettleAfter is how long a refund may stay pending before
+// ReconcilePendingRefunds asks its provider what became of it; well past the
+// HTTP client's timeout, so the original request has given up
+const refundSettleAfter = 15 * time.Minute
+
+// RefundNotifier tells a customer about their refund
+type RefundNotifier interface {
+	NotifyRefund(ctx context.Context, refund *Refund) error
+}
+
+// SetRefundStore sets where refunds are recorded
+func (s *Service) SetRefundStore(store RefundStore) {
+	s.refunds = store
+}
+
+// SetRefundGateway replaces the provider APIs refunds are sent through
+func (s *Service) SetRefundGateway(gateway RefundGateway) {
+	s.refundGateway = gateway
+}
+
+// SetRefundNotifier sets who is told about completed refunds
+func (s *Service) SetRefundNotifier(notifier RefundNotifier) {
+	s.refundNotifier = notifier
+}
+
+// CheckRefundable validates a refund of amount against a payment that
+// already has refunded pending or completed
+func CheckRefundable(payment *Transaction, refunded, amount int64) error {
+	if payment.Type != TypePayment || (payment.Status != StatusSuccess && payment.Status != StatusRefunded) {
+		return ErrPaymentNotRefundable
+	}
+	if amount <= 0 {
+		return ErrInvalidRefundAmount
+	}
+	if refunded+amount > payment.Amount {
+		return fmt.Errorf("%w: %d of %d left", ErrRefundExceedsPayment, payment.Amount-refunded, payment.Amount)
+	}
+	return nil
+}
+
+// RefundPayment refunds amount, in major currency units, of a captured
+// payment through the provider it was made with. Partial refunds may be
+// repeated until the payment is fully refunded. A refund the provider made
+// that could not be recorded is returned along with the error, and is left
+// pending for ReconcilePendingRefunds to settle. So is one whose request
+// failed without the provider turning it down, with ErrRefundPending: the
+// provider may still have made it.
+func (s *Service) RefundPayment(ctx context.Context, paymentID uuid.UUID, amount float64, reason string) (*Refund, error) {
+	if s.refunds == nil {
+		return nil, errors.New("refund store not configured")
+	}
+	return s.refundPayment(ctx, uuid.New(), paymentID, amount, reason)
+}
+
+// refundPayment makes the refund with ID refundID. Asked again for a refund
+// already made it returns that refund, and for one still pending
+// ErrRefundPending, so a caller retrying with the same ID refunds once.
+func (s *Service) refundPayment(ctx context.Context, refundID, paymentID uuid.UUID, amount float64, reason string) (*Refund, error) {
+	minorAmount := int64(math.Round(amount * 100))
+	if minorAmount <= 0 {
+		return nil, ErrInvalidRefundAmount
 	}
 	
-	// Create new wallet
-	wallet = Wallet{
-		ID:        uuid.New(),
-		UserID:    userID,
-		Balance:   0,
-		Currency:  currency,
-		IsActive:  true,
+	refund := &Refund{
+		ID:        refundID,
+		PaymentID: paymentID,
+		Amount:    minorAmount,
+		Reason:    reason,
+		Status:    RefundPending,
 		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
 	}
-	
-	_, err = s.db.Exec(ctx, `
-		INSERT INTO wallets (id, user_id, balance, pending_balance, currency, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, wallet.ID, wallet.UserID, wallet.Balance, wallet.PendingBalance,
-		wallet.Currency, wallet.IsActive, wallet.CreatedAt, wallet.UpdatedAt)
-	
+	payment, err := s.refunds.ReserveRefund(ctx, refund)
+	if errors.Is(err, ErrRefundExists) {
+		if refund.Status == RefundCompleted {
+			return refund, nil
+		}
+		return nil, ErrRefundPending
+	}
 	if err != nil {
 		return nil, err
 	}
 	
-	return &wallet, nil
-}
-
-func (s *Service) creditWallet(ctx context.Context, userID uuid.UUID, amount int64, currency string) error {
-	wallet, err := s.GetOrCreateWallet(ctx, userID, currency)
+	providerRef, err := s.gateway().RefundWithProvider(ctx, payment, refund)
+	if err != nil && !refundRejected(err) {
+		return nil, fmt.Errorf("%w: %v", ErrRefundPending, err)
+	}
 	if err != nil {
-		return err
+		// Failed refunds no longer count against the payment
+		refund.Status = RefundFailed
+		if finishErr := s.refunds.FinishRefund(ctx, refund); finishErr != nil {
+			return nil, fmt.Errorf("provider refund failed: %w (recording the failure: %v)", err, finishErr)
+		}
+		return nil, fmt.Errorf("provider refund failed: %w", err)
 	}
 	
-	_, err = s.db.Exec(ctx, 
-		"UPDATE wallets SET balance = balance + $1, updated_at = $2 WHERE id = $3",
-		amount, time.Now(), wallet.ID,
-	)
-	return err
+	now := time.Now()
+	refund.Status = RefundCompleted
+	refund.ProviderRef = providerRef
+	refund.CompletedAt = &now
+	if err := s.refunds.FinishRefund(ctx, refund); err != nil {
+		// The provider has made the refund, so it is returned with the error
+		return refund, fmt.Errorf("refund made but not recorded: %w", err)
+	}
+	
+	s.notifyRefund(ctx, refund)
+	return refund, nil
 }
 
-func (s *Service) debitWallet(ctx context.Context, userID uuid.UUID, amount int64, currency string) error {
-	wallet, err := s.GetOrCreateWallet(ctx, userID, currency)
+// refundRejected reports whether a refund request failed because the
+// provider, or the payment, ruled it out, so it was certainly not made
+func refundRejected(err error) bool {
+	return errors.Is(err, ErrRefundRejected) || errors.Is(err, ErrPaymentNotRefundable)
+}
+
+// ReconcilePendingRefunds settles refunds left pending by a request that
+// failed after asking the provider, looking each up with the provider:
+// one the provider made is completed and one it never made is failed, so it
+// no longer counts against the payment. It returns how many it settled.
+func (s *Service) ReconcilePendingRefunds(ctx context.Context) (int, error) {
+	if s.refunds == nil {
+		return 0, errors.New("refund store not configured")
+	}
+	pending, err := s.refunds.PendingRefunds(ctx, time.Now().Add(-refundSettleAfter))
 	if err != nil {
-		return err
+		return 0, err
 	}
 	
-	if wallet.Balance < amount {
-		return errors.New("insufficient balance")
+	settled := 0
+	for i := range pending {
+		refund := &pending[i].Refund
+		providerRef, found, err := s.gateway().FindRefund(ctx, &pending[i].Payment, refund)
+		if err != nil {
+			return settled, err
+		}
+		
+		refund.Status = RefundFailed
+		if found {
+			now := time.Now()
+			refund.Status = RefundCompleted
+			refund.ProviderRef = providerRef
+			refund.CompletedAt = &now
+		}
+		if err := s.refunds.FinishRefund(ctx, refund); err != nil {
+			return settled, err
+		}
+		settled++
+		if found {
+			s.notifyRefund(ctx, refund)
+		}
 	}
-	
-	_, err = s.db.Exec(ctx, 
-		"UPDATE wallets SET balance = balance - $1, updated_at = $2 WHERE id = $3",
-		amount, time.Now(), wallet.ID,
-	)
-	return err
+	return settled, nil
 }
 
-// =============================================================================
-// PAYOUTS
-// =============================================================================
+// notifyRefund tells the customer about a recorded refund
+func (s *Service) notifyRefund(ctx context.Context, refund *Refund) {
+	if s.refundNotifier != nil {
+		s.refundNotifier.NotifyRefund(ctx, refund)
+	}
+}
 
-// PayoutRequest for vendor withdrawals
-type PayoutRequest struct {
-	VendorID    uuid.UUID `json:"vendor_id"`
-	Amount      int64     `json:"amount"`
-	Currency    string    `json:"currency"`
-	BankCode    string    `json:"bank_code"`
-	AccountNumber string  `json:"account_number"`
-	AccountName string    `json:"account_name"`
+func (s *Service) gateway() RefundGateway {
+	if s.refundGateway != nil {
+		return s.refundGateway
+	}
+	return s
 }
 
-// RequestPayout initiates a vendor payout
-func (s *Service) RequestPayout(ctx context.Context, req PayoutRequest) (*Transaction, error) {
-	// Verify wallet balance
-	wallet, err := s.GetOrCreateWallet(ctx, req.VendorID, req.Currency)
+// RefundBooking refunds amount, in major currency units, of the payment
+// captured for a booking, as the refund with ID refundID. Retrying with the
+// same ID returns the refund once made and never refunds twice.
+func (s *Service) RefundBooking(ctx context.Context, bookingID, refundID uuid.UUID, amount float64, reason string) (*Refund, error) {
+	if s.refunds == nil {
+		return nil, errors.New("refund store not configured")
+	}
+	paymentID, err := s.refunds.BookingPayment(ctx, bookingID)
 	if err != nil {
 		return nil, err
 	}
-	
-	if wallet.Balance < req.Amount {
-		return nil, errors.New("insufficient balance")
-	}
-	
-	// Create payout transaction
-	txn := &Transaction{
-		ID:          uuid.New(),
-		Reference:   fmt.Sprintf("PAY-%s", uuid.New().String()[:8]),
-		UserID:      req.VendorID,
-		Type:        TypePayout,
-		Status:      StatusProcessing,
-		Provider:    ProviderPaystack, // Default to Paystack
-		Amount:      req.Amount,
-		Currency:    req.Currency,
-		Description: "Wallet withdrawal",
-		Metadata: map[string]interface{}{
-			"bank_code":      req.BankCode,
-			"account_number": req.AccountNumber,
-			"account_name":   req.AccountName,
-		},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	return s.refundPayment(ctx, refundID, paymentID, amount, reason)
+}
+
+// RefundEmergency refunds whatever is left of the payment for a HomeRescue
+// emergency request
+func (s *Service) RefundEmergency(ctx context.Context, emergencyID uuid.UUID, reason string) (*Refund, error) {
+	if s.refunds == nil {
+		return nil, errors.New("refund store not configured")
 	}
-	
-	// Debit wallet
-	if err := s.debitWallet(ctx, req.VendorID, req.Amount, req.Currency); err != nil {
+	paymentID, remaining, err := s.refunds.EmergencyPayment(ctx, emergencyID)
+	if err != nil {
 		return nil, err
 	}
-	
-	// Save transaction
-	if err := s.saveTransaction(ctx, txn); err != nil {
-		// Rollback wallet debit
-		s.creditWallet(ctx, req.VendorID, req.Amount, req.Currency)
+	if remaining <= 0 {
+		return nil, ErrPaymentNotRefundable
+	}
+	return s.RefundPayment(ctx, paymentID, float64(remaining)/100, reason)
+}
+
+// RefundWithProvider sends a refund to Paystack or Flutterwave. An internal
+// payment needs nothing from a provider: the store credits the customer's
+// wallet when the refund is recorded as completed.
+func (s *Service) RefundWithProvider(ctx context.Context, payment *Transaction, refund *Refund) (string, error) {
+	switch payment.Provider {
+	case ProviderPaystack:
+		return s.refundPaystack(ctx, payment, refund)
+	case ProviderFlutterwave:
+		return s.refundFlutterwave(ctx, payment, refund)
+	case ProviderInternal:
+		return "", nil
+	}
+	return "", fmt.Errorf("%w: refunds through %s are not supported", ErrPaymentNotRefundable, payment.Provider)
+}
+
+// FindRefund looks for the refund among those the provider has made for the
+// payment, by the refund ID it was tagged with. An internal refund is only
+// ever made by recording it, so one still pending was never made.
+func (s *Service) FindRefund(ctx context.Context, payment *Transaction, refund *Refund) (string, bool, error) {
+	switch payment.Provider {
+	case ProviderPaystack:
+		return s.findPaystackRefund(ctx, payment, refund)
+	case ProviderFlutterwave:
+		return s.findFlutterwaveRefund(ctx, payment, refund)
+	case ProviderInternal:
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("%w: refunds through %s are not supported", ErrPaymentNotRefundable, payment.Provider)
+}
+
+func (s *Service) refundPaystack(ctx context.Context, payment *Transaction, refund *Refund) (string, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"transaction":   payment.Reference,
+		"amount":        refund.Amount,
+		"merchant_note": refund.ID.String(),
+	})
+	httpReq, _ := http.NewRequestWithContext(ctx, "POST", "https://api.paystack.co/refund", strings.NewReader(string(body)))
+	httpReq.Header.Set("Authorization", "Bearer "+s.config.PaystackSecretKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	
+	resp, err := s.http.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	
+	var result struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if !result.Status {
+		return "", refundFailure(resp, result.Message)
+	}
+	return fmt.Sprintf("%d", result.Data.ID), nil
+}
+
+// refundFailure is the error for a refund request the provider answered
+// without making it. Server errors leave the outcome unknown; anything else
+// is the provider turning the refund down.
+func refundFailure(resp *http.Response, message string) error {
+	if resp.StatusCode >= 500 {
+		return errors.New(message)
+	}
+	return fmt.Errorf("%w: %s", ErrRefundRejected, message)
+}
+
+func (s *Service) findPaystackRefund(ctx context.Context, payment *Transaction, refund *Refund) (string, bool, error) {
+	httpReq, _ := http.NewRequestWithContext(ctx, "GET",
+		fmt.Sprintf("https://api.paystack.co/refund?transaction=%s", payment.Reference), nil)
+	httpReq.Header.Set("Authorization", "Bearer "+s.config.PaystackSecretKey)
+	
+	resp, err := s.http.Do(httpReq)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	
+	var result struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    []struct {
+			ID           int64  `json:"id"`
+			MerchantNote string `json:"merchant_note"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", false, err
+	}
+	if !result.Status {
+		return "", false, errors.New(result.Message)
+	}
+	for _, made := range result.Data {
+		if made.MerchantNote == refund.ID.String() {
+			return fmt.Sprintf("%d", made.ID), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (s *Service) refundFlutterwave(ctx context.Context, payment *Transaction, refund *Refund) (string, error) {
+	if payment.ProviderRef == "" {
+		return "", fmt.Errorf("%w: flutterwave transaction ID unknown", ErrRefundRejected)
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"amount":   float64(refund.Amount) / 100,
+		"comments": refund.ID.String(),
+	})
+	httpReq, _ := http.NewRequestWithContext(ctx, "POST",
+		fmt.Sprintf("https://api.flutterwave.com/v3/transactions/%s/refund", payment.ProviderRef),
+		strings.NewReader(string(body)))
+	httpReq.Header.Set("Authorization", "Bearer "+s.config.FlutterwaveSecretKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	
+	resp, err := s.http.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	
+	var result struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.Status != "success" {
+		return "", refundFailure(resp, result.Message)
+	}
+	return fmt.Sprintf("%d", result.Data.ID), nil
+}
+
+func (s *Service) findFlutterwaveRefund(ctx context.Context, payment *Transaction, refund *Refund) (string, bool, error) {
+	if payment.ProviderRef == "" {
+		// Never sent: refundFlutterwave refuses without the transaction ID
+		return "", false, nil
+	}
+	httpReq, _ := http.NewRequestWithContext(ctx, "GET",
+		fmt.Sprintf("https://api.flutterwave.com/v3/refunds?from=%s", refund.CreatedAt.Format("2006-01-02")), nil)
+	httpReq.Header.Set("Authorization", "Bearer "+s.config.FlutterwaveSecretKey)
+	
+	resp, err := s.http.Do(httpReq)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	
+	var result struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Data    []struct {
+			ID       int64       `json:"id"`
+			FlwRef   string      `json:"flw_ref"`
+			TxID     json.Number `json:"transaction_id"`
+			Comments string      `json:"comments"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", false, err
+	}
+	if result.Status != "success" {
+		return "", false, errors.New(result.Message)
+	}
+	for _, made := range result.Data {
+		if made.TxID.String() == payment.ProviderRef && made.Comments == refund.ID.String() {
+			return fmt.Sprintf("%d", made.ID), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// =============================================================================
+// LEDGER
+// =============================================================================
+
+// Ledger errors
+var (
+	ErrInvalidLedgerEntry = errors.New("invalid ledger entry")
+	ErrUnbalancedPosting  = errors.New("ledger debits do not equal credits")
+)
+
+type EntryDirection string
+const (
+	Debit  EntryDirection = "debit"
+	Credit EntryDirection = "credit"
+)
+
+// Ledger accounts shared by every booking and user
+const (
+	PlatformFeesAccount    = "platform:fees"    // Revenue from platform fees
+	PlatformRefundsAccount = "platform:refunds" // Money returned to customers
+)
+
+// WalletAccount is the ledger account of a user's wallet
+func WalletAccount(userID uuid.UUID) string {
+	return "wallet:" + userID.String()
+}
+
+// EscrowLedgerAccount is the ledger account of a booking's held funds
+func EscrowLedgerAccount(bookingID uuid.UUID) string {
+	return "escrow:" + bookingID.String()
+}
+
+// ProviderAccount is the ledger account of funds held at a payment provider
+func ProviderAccount(provider PaymentProvider) string {
+	return "provider:" + string(provider)
+}
+
+// CustomerAccount is the ledger account money paid in by a user comes from
+func CustomerAccount(userID uuid.UUID) string {
+	return "customer:" + userID.String()
+}
+
+// LedgerEntry is one side of a money movement. Entries sharing a
+// TransactionID make up one ledger transaction.
+type LedgerEntry struct {
+	TransactionID string         `json:"transaction_id"`
+	Account       string         `json:"account"`
+	Direction     EntryDirection `json:"direction"`
+	Amount        int64          `json:"amount"` // In kobo/cents
+	Currency      string         `json:"currency"`
+	Description   string         `json:"description"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// CheckLedgerPosting validates entries and groups them by transaction, in
+// the order each transaction first appears
+func CheckLedgerPosting(entries []LedgerEntry) ([][]LedgerEntry, error) {
+	var transactions [][]LedgerEntry
+	index := make(map[string]int)
+	for _, entry := range entries {
+		switch {
+		case entry.TransactionID == "", entry.Account == "", entry.Currency == "":
+			return nil, fmt.Errorf("%w: transaction, account and currency are required", ErrInvalidLedgerEntry)
+		case entry.Direction != Debit && entry.Direction != Credit:
+			return nil, fmt.Errorf("%w: unknown direction %q", ErrInvalidLedgerEntry, entry.Direction)
+		case entry.Amount <= 0:
+			return nil, fmt.Errorf("%w: amount must be greater than 0", ErrInvalidLedgerEntry)
+		}
+		i, ok := index[entry.TransactionID]
+		if !ok {
+			i = len(transactions)
+			index[entry.TransactionID] = i
+			transactions = append(transactions, nil)
+		}
+		transactions[i] = append(transactions[i], entry)
+	}
+	
+	for _, transaction := range transactions {
+		balance := make(map[string]int64) // by currency
+		for _, entry := range transaction {
+			if entry.Direction == Debit {
+				balance[entry.Currency] += entry.Amount
+			} else {
+				balance[entry.Currency] -= entry.Amount
+			}
+		}
+		for currency, net := range balance {
+			if net != 0 {
+				return nil, fmt.Errorf("%w: transaction %s is off by %d %s",
+					ErrUnbalancedPosting, transaction[0].TransactionID, net, currency)
+			}
+		}
+	}
+	return transactions, nil
+}
+
+// ledgerTransfer moves amount from the debited account to the credited one
+func ledgerTransfer(transactionID, description string, amount int64, currency, debit, credit string) []LedgerEntry {
+	now := time.Now()
+	return []LedgerEntry{
+		{TransactionID: transactionID, Account: debit, Direction: Debit, Amount: amount, Currency: currency, Description: description, CreatedAt: now},
+		{TransactionID: transactionID, Account: credit, Direction: Credit, Amount: amount, Currency: currency, Description: description, CreatedAt: now},
+	}
+}
+
+// LedgerEntries moves a captured payment from the customer to the provider
+// that collected it
+func (t *Transaction) LedgerEntries() []LedgerEntry {
+	entries := ledgerTransfer("capture:"+t.ID.String(), "Payment captured", t.Amount, t.Currency,
+		CustomerAccount(t.UserID), ProviderAccount(t.Provider))
+	if t.PaidAt != nil {
+		for i := range entries {
+			entries[i].CreatedAt = *t.PaidAt
+		}
+	}
+	return entries
+}
+
+// LedgerEntries moves the escrowed amount to the vendor's wallet and the
+// platform's fees
+func (r *EscrowRelease) LedgerEntries() []LedgerEntry {
+	transactionID := "escrow_release:" + r.EscrowID.String()
+	entries := []LedgerEntry{
+		{TransactionID: transactionID, Account: EscrowLedgerAccount(r.BookingID), Direction: Debit, Amount: r.Amount, Currency: r.Currency, Description: "Escrow release", CreatedAt: r.ReleasedAt},
+		{TransactionID: transactionID, Account: WalletAccount(r.VendorID), Direction: Credit, Amount: r.Payout, Currency: r.Currency, Description: "Escrow payout", CreatedAt: r.ReleasedAt},
+	}
+	if r.Fee > 0 {
+		entries = append(entries, LedgerEntry{
+			TransactionID: transactionID, Account: PlatformFeesAccount, Direction: Credit, Amount: r.Fee, Currency: r.Currency, Description: "Platform fee", CreatedAt: r.ReleasedAt,
+		})
+	}
+	return entries
+}
+
+// LedgerEntries moves a completed refund back to the customer, through the
+// provider the payment was made with or into their wallet. fromEscrow is
+// the part taken out of the booking's escrow; the platform covers the rest.
+func (r *Refund) LedgerEntries(fromEscrow int64) []LedgerEntry {
+	destination := ProviderAccount(r.Provider)
+	if r.Provider == ProviderInternal {
+		destination = WalletAccount(r.UserID)
+	}
+	transactionID := "refund:" + r.ID.String()
+	description := "Refund: " + r.Reason
+	if r.BookingID == nil || fromEscrow <= 0 {
+		return ledgerTransfer(transactionID, description, r.Amount, r.Currency,
+			PlatformRefundsAccount, destination)
+	}
+	
+	if fromEscrow > r.Amount {
+		fromEscrow = r.Amount
+	}
+	now := time.Now()
+	entries := []LedgerEntry{
+		{TransactionID: transactionID, Account: EscrowLedgerAccount(*r.BookingID), Direction: Debit, Amount: fromEscrow, Currency: r.Currency, Description: description, CreatedAt: now},
+	}
+	if rest := r.Amount - fromEscrow; rest > 0 {
+		entries = append(entries, LedgerEntry{
+			TransactionID: transactionID, Account: PlatformRefundsAccount, Direction: Debit, Amount: rest, Currency: r.Currency, Description: description, CreatedAt: now,
+		})
+	}
+	return append(entries, LedgerEntry{
+		TransactionID: transactionID, Account: destination, Direction: Credit, Amount: r.Amount, Currency: r.Currency, Description: description, CreatedAt: now,
+	})
+}
+
+// LedgerExecutor runs the ledger's statements: the pool, or the database
+// transaction of the change being recorded
+type LedgerExecutor interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Ledger records money movements in ledger_entries
+type Ledger struct {
+	db LedgerExecutor
+}
+
+// NewLedger creates a ledger that posts through db
+func NewLedger(db LedgerExecutor) *Ledger {
+	return &Ledger{db: db}
+}
+
+// Post records the entries of one or more ledger transactions. Run on the
+// caller's database transaction, the posting commits or rolls back with
+// the change it records. Each transaction's debits must equal its credits
+// in every currency. Entries are numbered within their ledger transaction,
+// so a transaction posted again conflicts on its first line and is skipped.
+func (l *Ledger) Post(ctx context.Context, entries []LedgerEntry) error {
+	_, err := l.post(ctx, entries)
+	return err
+}
+
+// post records entries like Post and reports whether any transaction was
+// newly posted
+func (l *Ledger) post(ctx context.Context, entries []LedgerEntry) (bool, error) {
+	transactions, err := CheckLedgerPosting(entries)
+	if err != nil {
+		return false, err
+	}
+	
+	posted := false
+	for _, transaction := range transactions {
+		for line, entry := range transaction {
+			createdAt := entry.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+			tag, err := l.db.Exec(ctx, `
+				INSERT INTO ledger_entries (
+					id, transaction_id, line, account, direction,
+					amount, currency, description, created_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				ON CONFLICT (transaction_id, line) DO NOTHING
+			`, uuid.New(), entry.TransactionID, line, entry.Account, entry.Direction,
+				entry.Amount, entry.Currency, entry.Description, createdAt)
+			if err != nil {
+				return false, fmt.Errorf("failed to post to ledger: %w", err)
+			}
+			if tag.RowsAffected() == 0 {
+				// Already posted
+				break
+			}
+			posted = true
+		}
+	}
+	return posted, nil
+}
+
+// =============================================================================
+// WALLET
+// =============================================================================
+
+// GetOrCreateWallet gets or creates a user wallet
+func (s *Service) GetOrCreateWallet(ctx context.Context, userID uuid.UUID, currency string) (*Wallet, error) {
+	var wallet Wallet
+	err := s.db.QueryRow(ctx, `
+		SELECT id, user_id, balance, pending_balance, currency, is_active, created_at, updated_at
+		FROM wallets WHERE user_id = $1 AND currency = $2
+	`, userID, currency).Scan(
+		&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.PendingBalance,
+		&wallet.Currency, &wallet.IsActive, &wallet.CreatedAt, &wallet.UpdatedAt,
+	)
+	
+	if err == nil {
+		return &wallet, nil
+	}
+	
+	// Create new wallet
+	wallet = Wallet{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Balance:   0,
+		Currency:  currency,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO wallets (id, user_id, balance, pending_balance, currency, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, wallet.ID, wallet.UserID, wallet.Balance, wallet.PendingBalance,
+		wallet.Currency, wallet.IsActive, wallet.CreatedAt, wallet.UpdatedAt)
+	
+	if err != nil {
+		return nil, err
+	}
+	
+	return &wallet, nil
+}
+
+func (s *Service) creditWallet(ctx context.Context, userID uuid.UUID, amount int64, currency string) error {
+	wallet, err := s.GetOrCreateWallet(ctx, userID, currency)
+	if err != nil {
+		return err
+	}
+	
+	_, err = s.db.Exec(ctx, 
+		"UPDATE wallets SET balance = balance + $1, updated_at = $2 WHERE id = $3",
+		amount, time.Now(), wallet.ID,
+	)
+	return err
+}
+
+func (s *Service) debitWallet(ctx context.Context, userID uuid.UUID, amount int64, currency string) error {
+	wallet, err := s.GetOrCreateWallet(ctx, userID, currency)
+	if err != nil {
+		return err
+	}
+	
+	if wallet.Balance < amount {
+		return errors.New("insufficient balance")
+	}
+	
+	_, err = s.db.Exec(ctx, 
+		"UPDATE wallets SET balance = balance - $1, updated_at = $2 WHERE id = $3",
+		amount, time.Now(), wallet.ID,
+	)
+	return err
+}
+
+// PayReferralFee moves a referral fee from the paying vendor's wallet to the
+// referring vendor's and posts it to the ledger. The ledger transaction is
+// keyed by referral and payee, so paying the same fee again moves nothing.
+// It returns the ledger transaction ID as the payment reference.
+func (s *Service) PayReferralFee(ctx context.Context, referralID, payerID, payeeID uuid.UUID, amount int64, currency string) (string, error) {
+	transactionID := fmt.Sprintf("referral_fee:%s:%s", referralID, payeeID)
+	err := s.withTx(ctx, func(txs *Service) error {
+		posted, err := NewLedger(txs.db).post(ctx, ledgerTransfer(transactionID, "Referral fee", amount, currency,
+			WalletAccount(payerID), WalletAccount(payeeID)))
+		if err != nil || !posted {
+			return err
+		}
+		if err := txs.debitWallet(ctx, payerID, amount, currency); err != nil {
+			return err
+		}
+		return txs.creditWallet(ctx, payeeID, amount, currency)
+	})
+	if err != nil {
+		return "", err
+	}
+	return transactionID, nil
+}
+
+// =============================================================================
+// PAYOUTS
+// =============================================================================
+
+// PayoutRequest for vendor withdrawals
+type PayoutRequest struct {
+	VendorID    uuid.UUID `json:"vendor_id"`
+	Amount      int64     `json:"amount"`
+	Currency    string    `json:"currency"`
+	BankCode    string    `json:"bank_code"`
+	AccountNumber string  `json:"account_number"`
+	AccountName string    `json:"account_name"`
+}
+
+// RequestPayout initiates a vendor payout
+func (s *Service) RequestPayout(ctx context.Context, req PayoutRequest) (*Transaction, error) {
+	// Verify wallet balance
+	wallet, err := s.GetOrCreateWallet(ctx, req.VendorID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	
+	if wallet.Balance < req.Amount {
+		return nil, errors.New("insufficient balance")
+	}
+	
+	// Create payout transaction
+	txn := &Transaction{
+		ID:          uuid.New(),
+		Reference:   fmt.Sprintf("PAY-%s", uuid.New().String()[:8]),
+		UserID:      req.VendorID,
+		Type:        TypePayout,
+		Status:      StatusProcessing,
+		Provider:    ProviderPaystack, // Default to Paystack
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: "Wallet withdrawal",
+		Metadata: map[string]interface{}{
+			"bank_code":      req.BankCode,
+			"account_number": req.AccountNumber,
+			"account_name":   req.AccountName,
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	
+	// Debit wallet
+	if err := s.debitWallet(ctx, req.VendorID, req.Amount, req.Currency); err != nil {
+		return nil, err
+	}
+	
+	// Save transaction
+	if err := s.saveTransaction(ctx, txn); err != nil {
+		// Rollback wallet debit
+		s.creditWallet(ctx, req.VendorID, req.Amount, req.Currency)
 		return nil, err
 	}
 	
@@ -754,37 +1769,234 @@ func (s *Service) processPaystackTransfer(ctx context.Context, txn *Transaction,
 
 // HandlePaystackWebhook processes Paystack webhooks
 func (s *Service) HandlePaystackWebhook(ctx context.Context, payload []byte, signature string) error {
-	// Verify signature
-	mac := hmac.New(sha512.New, []byte(s.config.PaystackSecretKey))
-	mac.Write(payload)
-	expectedSig := hex.EncodeToString(mac.Sum(nil))
+	_, err := s.HandleWebhook(ctx, ProviderPaystack, payload, signature)
+	return err
+}
+
+// Webhook errors
+var (
+	ErrInvalidSignature    = errors.New("invalid webhook signature")
+	ErrInvalidWebhookEvent = errors.New("invalid webhook event")
+	ErrUnsupportedProvider = errors.New("unsupported webhook provider")
+)
+
+// webhookSignatureHeaders names the header each provider signs webhooks in
+var webhookSignatureHeaders = map[PaymentProvider]string{
+	ProviderPaystack:    "X-Paystack-Signature",
+	ProviderFlutterwave: "Flutterwave-Signature",
+}
+
+// WebhookSignatureHeader returns the header a provider's webhook signature
+// arrives in
+func WebhookSignatureHeader(provider PaymentProvider) (string, bool) {
+	header, ok := webhookSignatureHeaders[provider]
+	return header, ok
+}
+
+// WebhookEventType is a provider event mapped onto the state change it
+// calls for
+type WebhookEventType string
+const (
+	WebhookChargeSuccess   WebhookEventType = "charge.success"
+	WebhookChargeFailed    WebhookEventType = "charge.failed"
+	WebhookTransferSuccess WebhookEventType = "transfer.success"
+	WebhookTransferFailed  WebhookEventType = "transfer.failed"
+	WebhookIgnored         WebhookEventType = "ignored" // recorded but changes nothing
+)
+
+// WebhookEvent is a verified provider event
+type WebhookEvent struct {
+	Provider  PaymentProvider  `json:"provider"`
+	ID        string           `json:"id"`   // unique per provider
+	Name      string           `json:"name"` // the provider's own event name
+	Type      WebhookEventType `json:"type"`
+	Reference string           `json:"reference"`
+	Amount    int64            `json:"amount"` // In kobo/cents
+}
+
+// WebhookResult is the outcome of handling a webhook
+type WebhookResult struct {
+	Event     WebhookEvent `json:"event"`
+	Duplicate bool         `json:"duplicate"` // already processed, nothing applied
+}
+
+// WebhookEventStore records which provider events have been processed
+type WebhookEventStore interface {
+	// ProcessEvent records an event and runs apply in the same transaction,
+	// returning false without applying if the event was already recorded.
+	// If apply fails nothing is recorded, so the provider's retry applies it.
+	ProcessEvent(ctx context.Context, event WebhookEvent, apply func(tx pgx.Tx) error) (bool, error)
+}
+
+// WebhookEventApplier makes the state changes a webhook event calls for,
+// inside the transaction that records the event
+type WebhookEventApplier interface {
+	ApplyWebhookEvent(ctx context.Context, tx pgx.Tx, event WebhookEvent) error
+}
+
+// SetWebhookEventStore sets where processed webhook events are recorded
+func (s *Service) SetWebhookEventStore(store WebhookEventStore) {
+	s.webhookEvents = store
+}
+
+// SetWebhookEventApplier replaces the service's own handling of webhook
+// events
+func (s *Service) SetWebhookEventApplier(applier WebhookEventApplier) {
+	s.webhookApplier = applier
+}
+
+// HandleWebhook verifies a provider webhook against that provider's secret
+// and applies its event once. Events are keyed by provider event ID, so a
+// redelivered event is reported as a duplicate without being applied again.
+func (s *Service) HandleWebhook(ctx context.Context, provider PaymentProvider, payload []byte, signature string) (*WebhookResult, error) {
+	if err := VerifyWebhookSignature(provider, s.webhookSecret(provider), payload, signature); err != nil {
+		return nil, err
+	}
+	
+	event, err := ParseWebhookEvent(provider, payload)
+	if err != nil {
+		return nil, err
+	}
+	
+	if s.webhookEvents == nil {
+		return nil, errors.New("webhook event store not configured")
+	}
+	applier := s.webhookApplier
+	if applier == nil {
+		applier = s
+	}
+	applied, err := s.webhookEvents.ProcessEvent(ctx, *event, func(tx pgx.Tx) error {
+		return applier.ApplyWebhookEvent(ctx, tx, *event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return &WebhookResult{Event: *event, Duplicate: !applied}, nil
+}
+
+// webhookSecret returns the key a provider signs its webhooks with:
+// Paystack uses the account's secret key, Flutterwave the secret hash set
+// on its dashboard
+func (s *Service) webhookSecret(provider PaymentProvider) string {
+	switch provider {
+	case ProviderPaystack:
+		return s.config.PaystackSecretKey
+	case ProviderFlutterwave:
+		return s.config.WebhookSecret
+	}
+	return ""
+}
+
+// VerifyWebhookSignature checks a webhook signature against the shared
+// secret: a hex HMAC-SHA512 of the body for Paystack and a base64
+// HMAC-SHA256 for Flutterwave. Without a secret nothing verifies.
+func VerifyWebhookSignature(provider PaymentProvider, secret string, payload []byte, signature string) error {
+	if secret == "" || signature == "" {
+		return ErrInvalidSignature
+	}
 	
-	if signature != expectedSig {
-		return errors.New("invalid signature")
+	var expected string
+	switch provider {
+	case ProviderPaystack:
+		mac := hmac.New(sha512.New, []byte(secret))
+		mac.Write(payload)
+		expected = hex.EncodeToString(mac.Sum(nil))
+	case ProviderFlutterwave:
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		expected = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	default:
+		return ErrUnsupportedProvider
 	}
 	
-	var event struct {
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseWebhookEvent reads a provider's webhook payload
+func ParseWebhookEvent(provider PaymentProvider, payload []byte) (*WebhookEvent, error) {
+	var body struct {
 		Event string `json:"event"`
 		Data  struct {
-			Reference string `json:"reference"`
-			Status    string `json:"status"`
-			Amount    int64  `json:"amount"`
+			ID        json.Number `json:"id"`
+			Reference string      `json:"reference"`
+			TxRef     string      `json:"tx_ref"`
+			Status    string      `json:"status"`
+			Amount    json.Number `json:"amount"`
 		} `json:"data"`
 	}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookEvent, err)
+	}
+	if body.Event == "" || body.Data.ID == "" {
+		return nil, fmt.Errorf("%w: missing event name or ID", ErrInvalidWebhookEvent)
+	}
 	
-	if err := json.Unmarshal(payload, &event); err != nil {
-		return err
+	event := &WebhookEvent{
+		Provider:  provider,
+		ID:        body.Event + ":" + body.Data.ID.String(),
+		Name:      body.Event,
+		Type:      WebhookIgnored,
+		Reference: body.Data.Reference,
 	}
+	amount, _ := body.Data.Amount.Float64()
+	status := strings.ToLower(body.Data.Status)
 	
-	switch event.Event {
-	case "charge.success":
-		return s.handleChargeSuccess(ctx, event.Data.Reference)
-	case "transfer.success":
-		return s.handleTransferSuccess(ctx, event.Data.Reference)
-	case "transfer.failed":
-		return s.handleTransferFailed(ctx, event.Data.Reference)
+	switch provider {
+	case ProviderPaystack:
+		event.Amount = int64(amount)
+		switch body.Event {
+		case "charge.success":
+			event.Type = WebhookChargeSuccess
+		case "transfer.success":
+			event.Type = WebhookTransferSuccess
+		case "transfer.failed", "transfer.reversed":
+			event.Type = WebhookTransferFailed
+		}
+	case ProviderFlutterwave:
+		// Flutterwave amounts are in major units
+		event.Amount = int64(amount * 100)
+		switch {
+		case body.Event == "charge.completed" && status == "successful":
+			event.Type = WebhookChargeSuccess
+			event.Reference = body.Data.TxRef
+		case body.Event == "charge.completed" && status == "failed":
+			event.Type = WebhookChargeFailed
+			event.Reference = body.Data.TxRef
+		case body.Event == "transfer.completed" && status == "successful":
+			event.Type = WebhookTransferSuccess
+		case body.Event == "transfer.completed" && status == "failed":
+			event.Type = WebhookTransferFailed
+		}
+	default:
+		return nil, ErrUnsupportedProvider
 	}
 	
+	return event, nil
+}
+
+// ApplyWebhookEvent updates transactions, escrow and wallets for an event
+// within tx
+func (s *Service) ApplyWebhookEvent(ctx context.Context, tx pgx.Tx, event WebhookEvent) error {
+	if tx != nil {
+		s = s.inTx(tx)
+	}
+	switch event.Type {
+	case WebhookChargeSuccess:
+		if event.Provider == ProviderPaystack {
+			return s.handleChargeSuccess(ctx, event.Reference)
+		}
+		return s.completeCharge(ctx, event.Reference)
+	case WebhookChargeFailed:
+		return s.handleChargeFailed(ctx, event.Reference)
+	case WebhookTransferSuccess:
+		return s.handleTransferSuccess(ctx, event.Reference)
+	case WebhookTransferFailed:
+		return s.handleTransferFailed(ctx, event.Reference)
+	}
 	return nil
 }
 
@@ -817,6 +2029,219 @@ func (s *Service) handleTransferFailed(ctx context.Context, reference string) er
 	return s.creditWallet(ctx, txn.UserID, txn.Amount, txn.Currency)
 }
 
+// completeCharge marks a charge paid on the provider's word, holding its
+// escrow
+func (s *Service) completeCharge(ctx context.Context, reference string) error {
+	txn, err := s.GetTransactionByReference(ctx, reference)
+	if err != nil {
+		return err
+	}
+	
+	now := time.Now()
+	txn.Status = StatusSuccess
+	txn.PaidAt = &now
+	txn.UpdatedAt = now
+	return s.recordPayment(ctx, txn)
+}
+
+func (s *Service) handleChargeFailed(ctx context.Context, reference string) error {
+	_, err := s.db.Exec(ctx,
+		"UPDATE transactions SET status = $1, updated_at = $2 WHERE reference = $3 AND status = $4",
+		StatusFailed, time.Now(), reference, StatusPending,
+	)
+	return err
+}
+
+// =============================================================================
+// WEBHOOK EVENT STORES
+// =============================================================================
+
+// PostgresWebhookEventStore records processed events in
+// processed_webhook_events
+type PostgresWebhookEventStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresWebhookEventStore creates a database-backed event store
+func NewPostgresWebhookEventStore(db *pgxpool.Pool) *PostgresWebhookEventStore {
+	return &PostgresWebhookEventStore{db: db}
+}
+
+// ProcessEvent inserts the event and applies it in one transaction. A
+// concurrent delivery of the same event waits on the insert and then finds
+// it recorded, or records it itself if the first delivery rolled back.
+func (p *PostgresWebhookEventStore) ProcessEvent(ctx context.Context, event WebhookEvent, apply func(tx pgx.Tx) error) (bool, error) {
+	applied := false
+	err := pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO processed_webhook_events (provider, event_id, event_type, reference)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (provider, event_id) DO NOTHING
+		`, event.Provider, event.ID, event.Name, event.Reference)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		applied = true
+		return apply(tx)
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
+
+// =============================================================================
+// ESCROW STORES
+// =============================================================================
+
+// PostgresEscrowStore keeps escrows in escrow_accounts and records releases
+// as escrow_release transactions
+type PostgresEscrowStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresEscrowStore creates a database-backed escrow store
+func NewPostgresEscrowStore(db *pgxpool.Pool) *PostgresEscrowStore {
+	return &PostgresEscrowStore{db: db}
+}
+
+// ReleaseEscrow locks the booking's escrow row, so concurrent releases of
+// the same escrow pay out once
+func (p *PostgresEscrowStore) ReleaseEscrow(ctx context.Context, bookingID uuid.UUID, by EscrowReleaser, feeRate FeeRate, now time.Time) (*EscrowRelease, bool, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+	
+	// Escrows hold the vendor's user ID; tiers are looked up by the
+	// vendor's record
+	var escrow EscrowAccount
+	var releasedByCol, vendorRecordID *uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT e.id, e.transaction_id, e.booking_id, e.customer_id, e.vendor_id,
+		       e.amount, e.currency, e.status, e.dispute_id, e.released_at, e.released_by,
+		       (SELECT v.id FROM vendors v WHERE v.user_id = e.vendor_id)
+		FROM escrow_accounts e
+		WHERE e.booking_id = $1
+		ORDER BY e.created_at DESC
+		LIMIT 1
+		FOR UPDATE OF e
+	`, bookingID).Scan(
+		&escrow.ID, &escrow.TransactionID, &escrow.BookingID, &escrow.CustomerID, &escrow.VendorID,
+		&escrow.Amount, &escrow.Currency, &escrow.Status, &escrow.DisputeID, &escrow.ReleasedAt, &releasedByCol,
+		&vendorRecordID,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, ErrEscrowNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !by.CanRelease(&escrow) {
+		return nil, false, ErrEscrowForbidden
+	}
+	tierVendorID := uuid.Nil
+	if vendorRecordID != nil {
+		tierVendorID = *vendorRecordID
+	}
+	feePercent, err := feeRate(ctx, tierVendorID)
+	if err != nil {
+		return nil, false, err
+	}
+	
+	if escrow.Status == EscrowReleased && escrow.ReleasedAt != nil {
+		release := NewEscrowRelease(&escrow, feePercent, AutoReleasedBy, *escrow.ReleasedAt)
+		if releasedByCol != nil {
+			release.ReleasedBy = *releasedByCol
+		}
+		// The ledger has what was actually paid out
+		err := tx.QueryRow(ctx,
+			"SELECT fee, net_amount FROM transactions WHERE reference = $1",
+			release.Reference,
+		).Scan(&release.Fee, &release.Payout)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, err
+		}
+		return release, false, nil
+	}
+	if err := CheckEscrowReleasable(&escrow); err != nil {
+		return nil, false, err
+	}
+	
+	release := NewEscrowRelease(&escrow, feePercent, by.UserID, now)
+	metadataJSON, _ := json.Marshal(map[string]interface{}{
+		"escrow_id":               escrow.ID.String(),
+		"original_transaction_id": escrow.TransactionID.String(),
+		"released_by":             by.UserID.String(),
+	})
+	
+	_, err = tx.Exec(ctx, `
+		INSERT INTO transactions (
+			id, reference, user_id, vendor_id, booking_id,
+			type, status, provider, amount, currency, fee, net_amount,
+			description, metadata, paid_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15, $15)
+	`, uuid.New(), release.Reference, escrow.CustomerID, escrow.VendorID, escrow.BookingID,
+		TypeEscrowRelease, StatusSuccess, ProviderInternal, release.Amount, release.Currency, release.Fee, release.Payout,
+		"Escrow release", metadataJSON, now)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record escrow release: %w", err)
+	}
+	
+	_, err = tx.Exec(ctx, `
+		INSERT INTO wallets (id, user_id, balance, pending_balance, currency, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, 0, $4, TRUE, $5, $5)
+		ON CONFLICT (user_id, currency) DO UPDATE SET
+			balance = wallets.balance + EXCLUDED.balance,
+			updated_at = EXCLUDED.updated_at
+	`, uuid.New(), escrow.VendorID, release.Payout, release.Currency, now)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to credit vendor wallet: %w", err)
+	}
+	
+	_, err = tx.Exec(ctx,
+		"UPDATE escrow_accounts SET status = $1, released_at = $2, released_by = $3 WHERE id = $4",
+		EscrowReleased, now, by.UserID, escrow.ID,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	
+	if err := NewLedger(tx).Post(ctx, release.LedgerEntries()); err != nil {
+		return nil, false, err
+	}
+	
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+	return release, true, nil
+}
+
+// ExpiredEscrows lists held, undisputed escrows created before cutoff
+func (p *PostgresEscrowStore) ExpiredEscrows(ctx context.Context, cutoff time.Time) ([]uuid.UUID, error) {
+	rows, err := p.db.Query(ctx, `
+		SELECT booking_id
+		FROM escrow_accounts
+		WHERE status = $1 AND dispute_id IS NULL AND created_at < $2
+		ORDER BY created_at
+	`, EscrowHeld, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var bookingIDs []uuid.UUID
+	for rows.Next() {
+		var bookingID uuid.UUID
+		if err := rows.Scan(&bookingID); err != nil {
+			return nil, err
+		}
+		bookingIDs = append(bookingIDs, bookingID)
+	}
+	return bookingIDs, rows.Err()
+}
+
 // =============================================================================
 // HELPERS
 // =============================================================================
@@ -879,3 +2304,312 @@ func (s *Service) GetTransactionByReference(ctx context.Context, reference strin
 	return &txn, nil
 }
 
+// =============================================================================
+// REFUND STORES
+// =============================================================================
+
+// PostgresRefundStore records refunds in the refunds table
+type PostgresRefundStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRefundStore creates a database-backed refund store
+func NewPostgresRefundStore(db *pgxpool.Pool) *PostgresRefundStore {
+	return &PostgresRefundStore{db: db}
+}
+
+// ReserveRefund locks the payment row, so concurrent refunds of the same
+// payment cannot together exceed it, and concurrent reservations of the same
+// refund ID record it once
+func (p *PostgresRefundStore) ReserveRefund(ctx context.Context, refund *Refund) (*Transaction, error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	
+	var payment Transaction
+	var providerRef *string
+	err = tx.QueryRow(ctx, `
+		SELECT id, reference, user_id, booking_id, type, status, provider,
+		       amount, currency, provider_ref
+		FROM transactions
+		WHERE id = $1
+		FOR UPDATE
+	`, refund.PaymentID).Scan(
+		&payment.ID, &payment.Reference, &payment.UserID, &payment.BookingID, &payment.Type, &payment.Status, &payment.Provider,
+		&payment.Amount, &payment.Currency, &providerRef,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if providerRef != nil {
+		payment.ProviderRef = *providerRef
+	}
+	
+	var recorded Refund
+	var recordedRef *string
+	err = tx.QueryRow(ctx,
+		"SELECT amount, status, provider_ref, completed_at, created_at FROM refunds WHERE id = $1",
+		refund.ID,
+	).Scan(&recorded.Amount, &recorded.Status, &recordedRef, &recorded.CompletedAt, &recorded.CreatedAt)
+	exists := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if exists && recorded.Status != RefundFailed {
+		refund.UserID = payment.UserID
+		refund.BookingID = payment.BookingID
+		refund.Provider = payment.Provider
+		refund.Currency = payment.Currency
+		refund.Amount = recorded.Amount
+		refund.Status = recorded.Status
+		refund.CompletedAt = recorded.CompletedAt
+		refund.CreatedAt = recorded.CreatedAt
+		if recordedRef != nil {
+			refund.ProviderRef = *recordedRef
+		}
+		return &payment, ErrRefundExists
+	}
+	
+	var refunded int64
+	err = tx.QueryRow(ctx,
+		"SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE payment_id = $1 AND status <> $2",
+		payment.ID, RefundFailed,
+	).Scan(&refunded)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckRefundable(&payment, refunded, refund.Amount); err != nil {
+		return nil, err
+	}
+	
+	refund.UserID = payment.UserID
+	refund.BookingID = payment.BookingID
+	refund.Provider = payment.Provider
+	refund.Currency = payment.Currency
+	if exists {
+		// A failed attempt under the same ID is tried again
+		_, err = tx.Exec(ctx, `
+			UPDATE refunds SET amount = $1, reason = $2, status = $3, provider_ref = NULL,
+			       completed_at = NULL, created_at = $4
+			WHERE id = $5
+		`, refund.Amount, refund.Reason, refund.Status, refund.CreatedAt, refund.ID)
+	} else {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO refunds (
+				id, payment_id, user_id, booking_id, provider,
+				amount, currency, reason, status, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, refund.ID, refund.PaymentID, refund.UserID, refund.BookingID, refund.Provider,
+			refund.Amount, refund.Currency, refund.Reason, refund.Status, refund.CreatedAt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record refund: %w", err)
+	}
+	
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// FinishRefund updates the pending refund row, credits the wallet for an
+// internal refund, takes a completed refund out of the booking's escrow,
+// posts it and marks a fully refunded payment
+func (p *PostgresRefundStore) FinishRefund(ctx context.Context, refund *Refund) error {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	
+	tag, err := tx.Exec(ctx,
+		"UPDATE refunds SET status = $1, provider_ref = $2, completed_at = $3 WHERE id = $4 AND status = $5",
+		refund.Status, refund.ProviderRef, refund.CompletedAt, refund.ID, RefundPending,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		// Already settled
+		return nil
+	}
+	
+	if refund.Status == RefundCompleted && refund.Provider == ProviderInternal {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO wallets (id, user_id, balance, pending_balance, currency, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, 0, $4, TRUE, NOW(), NOW())
+			ON CONFLICT (user_id, currency) DO UPDATE SET
+				balance = wallets.balance + EXCLUDED.balance,
+				updated_at = EXCLUDED.updated_at
+		`, uuid.New(), refund.UserID, refund.Amount, refund.Currency)
+		if err != nil {
+			return fmt.Errorf("failed to credit customer wallet: %w", err)
+		}
+	}
+	
+	var fromEscrow int64
+	if refund.Status == RefundCompleted && refund.BookingID != nil {
+		var escrow EscrowAccount
+		err := tx.QueryRow(ctx, `
+			SELECT id, amount, status FROM escrow_accounts
+			WHERE booking_id = $1
+			ORDER BY created_at DESC
+			LIMIT 1
+			FOR UPDATE
+		`, *refund.BookingID).Scan(&escrow.ID, &escrow.Amount, &escrow.Status)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// Not paid through escrow
+		case err != nil:
+			return err
+		default:
+			held := escrow.Amount
+			if !escrow.ApplyRefund(refund.Amount) {
+				break
+			}
+			fromEscrow = held - escrow.Amount
+			_, err = tx.Exec(ctx,
+				"UPDATE escrow_accounts SET amount = $1, status = $2 WHERE id = $3",
+				escrow.Amount, escrow.Status, escrow.ID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	
+	if refund.Status == RefundCompleted {
+		if err := NewLedger(tx).Post(ctx, refund.LedgerEntries(fromEscrow)); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE transactions SET status = $1, updated_at = NOW()
+			WHERE id = $2 AND amount <= (
+				SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE payment_id = $2 AND status = $3
+			)
+		`, StatusRefunded, refund.PaymentID, RefundCompleted)
+		if err != nil {
+			return err
+		}
+	}
+	
+	return tx.Commit(ctx)
+}
+
+// PendingRefunds lists pending refunds reserved before cutoff with their
+// payments
+func (p *PostgresRefundStore) PendingRefunds(ctx context.Context, cutoff time.Time) ([]PendingRefund, error) {
+	rows, err := p.db.Query(ctx, `
+		SELECT r.id, r.payment_id, r.user_id, r.booking_id, r.provider,
+		       r.amount, r.currency, r.reason, r.status, r.created_at,
+		       t.reference, t.type, t.status, t.amount, t.provider_ref
+		FROM refunds r
+		JOIN transactions t ON t.id = r.payment_id
+		WHERE r.status = $1 AND r.created_at < $2
+		ORDER BY r.created_at
+	`, RefundPending, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var pending []PendingRefund
+	for rows.Next() {
+		var refund Refund
+		var payment Transaction
+		var providerRef *string
+		err := rows.Scan(
+			&refund.ID, &refund.PaymentID, &refund.UserID, &refund.BookingID, &refund.Provider,
+			&refund.Amount, &refund.Currency, &refund.Reason, &refund.Status, &refund.CreatedAt,
+			&payment.Reference, &payment.Type, &payment.Status, &payment.Amount, &providerRef,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payment.ID = refund.PaymentID
+		payment.UserID = refund.UserID
+		payment.BookingID = refund.BookingID
+		payment.Provider = refund.Provider
+		payment.Currency = refund.Currency
+		if providerRef != nil {
+			payment.ProviderRef = *providerRef
+		}
+		pending = append(pending, PendingRefund{Refund: refund, Payment: payment})
+	}
+	return pending, rows.Err()
+}
+
+// BookingPayment returns the booking's latest captured payment
+func (p *PostgresRefundStore) BookingPayment(ctx context.Context, bookingID uuid.UUID) (uuid.UUID, error) {
+	var paymentID uuid.UUID
+	err := p.db.QueryRow(ctx, `
+		SELECT id FROM transactions
+		WHERE booking_id = $1 AND type = $2 AND status IN ($3, $4)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, bookingID, TypePayment, StatusSuccess, StatusRefunded).Scan(&paymentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, ErrPaymentNotFound
+	}
+	return paymentID, err
+}
+
+// EmergencyPayment returns the emergency request's latest captured payment
+// and the amount not yet refunded
+func (p *PostgresRefundStore) EmergencyPayment(ctx context.Context, emergencyID uuid.UUID) (uuid.UUID, int64, error) {
+	var paymentID uuid.UUID
+	var remaining int64
+	err := p.db.QueryRow(ctx, `
+		SELECT t.id, t.amount - COALESCE((
+			SELECT SUM(r.amount) FROM refunds r
+			WHERE r.payment_id = t.id AND r.status <> $5
+		), 0)
+		FROM transactions t
+		WHERE t.metadata->>'emergency_id' = $1 AND t.type = $2 AND t.status IN ($3, $4)
+		ORDER BY t.created_at DESC
+		LIMIT 1
+	`, emergencyID.String(), TypePayment, StatusSuccess, StatusRefunded, RefundFailed).Scan(&paymentID, &remaining)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, 0, ErrPaymentNotFound
+	}
+	return paymentID, remaining, err
+}
+
+// =============================================================================
+// VENDOR TIER STORES
+// =============================================================================
+
+// PostgresVendorTierStore reads subscription tiers from the vendors table
+type PostgresVendorTierStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresVendorTierStore creates a database-backed vendor tier store
+func NewPostgresVendorTierStore(db *pgxpool.Pool) *PostgresVendorTierStore {
+	return &PostgresVendorTierStore{db: db}
+}
+
+// VendorTier returns the subscription tier of the vendor with record ID
+// vendorID, or "" for an unknown vendor or one without a tier
+func (p *PostgresVendorTierStore) VendorTier(ctx context.Context, vendorID uuid.UUID) (string, error) {
+	var tier *string
+	err := p.db.QueryRow(ctx,
+		"SELECT subscription_tier FROM vendors WHERE id = $1",
+		vendorID,
+	).Scan(&tier)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	if tier == nil {
+		return "", nil
+	}
+	return *tier, nil
+}
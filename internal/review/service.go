@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,20 +21,73 @@ var (
 	ErrDuplicateReview     = errors.New("review already exists for this booking")
 	ErrUnauthorized        = errors.New("unauthorized")
 	ErrBookingNotCompleted = errors.New("booking must be completed before reviewing")
+	ErrBookingNotFound     = errors.New("booking not found or does not belong to user")
+	ErrBookingWrongVendor  = errors.New("booking is with a different vendor")
+	ErrReplyExists         = errors.New("review already has a vendor reply")
+	ErrReplyNotFound       = errors.New("review has no vendor reply")
+	ErrReplyEditClosed     = errors.New("vendor reply can no longer be edited")
 )
 
+// DefaultVerifiedPurchaseWeight is how many times more a verified purchase
+// review counts toward a vendor's rating than an unverified one
+const DefaultVerifiedPurchaseWeight = 2.0
+
+// DefaultReplyEditWindow is how long after replying a vendor may edit their
+// reply
+const DefaultReplyEditWindow = 24 * time.Hour
+
 // Service handles review-related operations
 type Service struct {
-	db    *pgxpool.Pool
-	cache *redis.Client
+	db              *pgxpool.Pool
+	cache           *redis.Client
+	ratings         RatingStore
+	bookings        BookingLookup
+	verifiedWeight  float64
+	replies         ReplyStore
+	replyEditWindow time.Duration
 }
 
 // NewService creates a new review service
 func NewService(db *pgxpool.Pool, cache *redis.Client) *Service {
-	return &Service{
-		db:    db,
-		cache: cache,
-	}
+	s := &Service{
+		db:              db,
+		cache:           cache,
+		verifiedWeight:  DefaultVerifiedPurchaseWeight,
+		replyEditWindow: DefaultReplyEditWindow,
+	}
+	if db != nil {
+		s.ratings = NewPostgresRatingStore(db)
+		s.bookings = NewPostgresBookingLookup(db)
+		s.replies = NewPostgresReplyStore(db)
+	}
+	return s
+}
+
+// SetBookingLookup sets where reviewers' bookings are looked up
+func (s *Service) SetBookingLookup(lookup BookingLookup) {
+	s.bookings = lookup
+}
+
+// SetVerifiedPurchaseWeight sets how many times more a verified purchase
+// review counts toward a vendor's rating
+func (s *Service) SetVerifiedPurchaseWeight(weight float64) {
+	s.verifiedWeight = weight
+}
+
+// SetReplyStore sets where vendors' replies to reviews are kept
+func (s *Service) SetReplyStore(store ReplyStore) {
+	s.replies = store
+}
+
+// SetReplyEditWindow sets how long after replying a vendor may edit their
+// reply
+func (s *Service) SetReplyEditWindow(window time.Duration) {
+	s.replyEditWindow = window
+}
+
+// SetRatingStore sets where vendors' aggregate ratings are recomputed
+func (s *Service) SetRatingStore(store RatingStore) {
+	s.ratings = store
 }
 
 // Review represents a review in the system
@@ -55,18 +110,18 @@ type Review struct {
 	ImageURLs []string `json:"image_urls,omitempty"`
 
 	// Status
-	IsVerified  bool   `json:"is_verified"`
-	IsPublished bool   `json:"is_published"`
-	IsFlagged   bool   `json:"is_flagged"`
-	FlagReason  string `json:"flag_reason,omitempty"`
+	IsVerified       bool   `json:"is_verified"`       // Same as VerifiedPurchase, kept for existing clients
+	VerifiedPurchase bool   `json:"verified_purchase"` // Reviewer completed a booking with the vendor
+	IsPublished      bool   `json:"is_published"`
+	IsFlagged        bool   `json:"is_flagged"`
+	FlagReason       string `json:"flag_reason,omitempty"`
 
 	// Engagement
 	HelpfulCount    int `json:"helpful_count"`
 	NotHelpfulCount int `json:"not_helpful_count"`
 
 	// Vendor Response
-	VendorResponse    string     `json:"vendor_response,omitempty"`
-	VendorRespondedAt *time.Time `json:"vendor_responded_at,omitempty"`
+	Reply *ReviewReply `json:"reply,omitempty"`
 
 	// User Info (populated from join)
 	UserName   string `json:"user_name,omitempty"`
@@ -77,6 +132,30 @@ type Review struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ReviewReply is the reviewed vendor's public reply to a review
+type ReviewReply struct {
+	ReviewID  uuid.UUID  `json:"review_id"`
+	VendorID  uuid.UUID  `json:"vendor_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+// newReviewReply builds a review's reply from its vendor_response columns,
+// returning nil when the vendor has not replied
+func newReviewReply(review *Review, body *string, repliedAt, editedAt *time.Time) *ReviewReply {
+	if body == nil || repliedAt == nil {
+		return nil
+	}
+	return &ReviewReply{
+		ReviewID:  review.ID,
+		VendorID:  review.VendorID,
+		Body:      *body,
+		CreatedAt: *repliedAt,
+		EditedAt:  editedAt,
+	}
+}
+
 // CreateReviewRequest represents a request to create a review
 type CreateReviewRequest struct {
 	VendorID  uuid.UUID  `json:"vendor_id"`
@@ -112,7 +191,7 @@ type ReviewListOptions struct {
 	VendorID     *uuid.UUID
 	UserID       *uuid.UUID
 	MinRating    *int
-	IsVerified   *bool
+	IsVerified   *bool // Verified purchases only, or unverified only
 	WithResponse *bool
 	Limit        int
 	Offset       int
@@ -127,24 +206,9 @@ func (s *Service) Create(ctx context.Context, req *CreateReviewRequest) (*Review
 		return nil, fmt.Errorf("%w: %v", ErrInvalidReviewData, err)
 	}
 
-	// If booking_id is provided, verify booking exists and is completed
-	if req.BookingID != nil {
-		var bookingStatus string
-		err := s.db.QueryRow(ctx,
-			"SELECT status FROM bookings WHERE id = $1 AND user_id = $2",
-			req.BookingID, req.UserID,
-		).Scan(&bookingStatus)
-
-		if err == pgx.ErrNoRows {
-			return nil, errors.New("booking not found or does not belong to user")
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to verify booking: %w", err)
-		}
-
-		if bookingStatus != "completed" && bookingStatus != "confirmed" {
-			return nil, ErrBookingNotCompleted
-		}
+	verifiedPurchase, err := s.VerifyPurchase(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check for duplicate review on same booking
@@ -177,7 +241,8 @@ func (s *Service) Create(ctx context.Context, req *CreateReviewRequest) (*Review
 		Title:               req.Title,
 		Comment:             req.Comment,
 		ImageURLs:           req.ImageURLs,
-		IsVerified:          req.BookingID != nil, // Verified if linked to booking
+		IsVerified:          verifiedPurchase,
+		VerifiedPurchase:    verifiedPurchase,
 		IsPublished:         true,
 		IsFlagged:           false,
 		CreatedAt:           time.Now(),
@@ -196,21 +261,24 @@ func (s *Service) Create(ctx context.Context, req *CreateReviewRequest) (*Review
 		)
 	`
 
-	_, err := s.db.Exec(ctx, query,
-		review.ID, review.VendorID, review.UserID, review.BookingID,
-		review.Rating, review.QualityRating, review.CommunicationRating,
-		review.TimelinessRating, review.ValueRating,
-		review.Title, review.Comment, review.ImageURLs,
-		review.IsVerified, review.IsPublished, review.IsFlagged,
-		review.CreatedAt, review.UpdatedAt,
-	)
-
+	err = s.writeReview(ctx, review.VendorID, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, query,
+			review.ID, review.VendorID, review.UserID, review.BookingID,
+			review.Rating, review.QualityRating, review.CommunicationRating,
+			review.TimelinessRating, review.ValueRating,
+			review.Title, review.Comment, review.ImageURLs,
+			review.VerifiedPurchase, review.IsPublished, review.IsFlagged,
+			review.CreatedAt, review.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create review: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create review: %w", err)
+		return nil, err
 	}
 
-	// Trigger updates vendor ratings automatically via database trigger
-
 	return review, nil
 }
 
@@ -225,7 +293,7 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*Review, error) {
 			r.title, r.comment, r.image_urls,
 			r.is_verified, r.is_published, r.is_flagged, r.flag_reason,
 			r.helpful_count, r.not_helpful_count,
-			r.vendor_response, r.vendor_responded_at,
+			r.vendor_response, r.vendor_responded_at, r.vendor_response_edited_at,
 			r.created_at, r.updated_at,
 			COALESCE(u.first_name || ' ' || u.last_name, u.display_name, 'Anonymous') as user_name,
 			u.avatar_url
@@ -234,6 +302,8 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*Review, error) {
 		WHERE r.id = $1
 	`
 
+	var replyBody *string
+	var repliedAt, replyEditedAt *time.Time
 	err := s.db.QueryRow(ctx, query, id).Scan(
 		&review.ID, &review.VendorID, &review.UserID, &review.BookingID,
 		&review.Rating, &review.QualityRating, &review.CommunicationRating,
@@ -241,7 +311,7 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*Review, error) {
 		&review.Title, &review.Comment, &review.ImageURLs,
 		&review.IsVerified, &review.IsPublished, &review.IsFlagged, &review.FlagReason,
 		&review.HelpfulCount, &review.NotHelpfulCount,
-		&review.VendorResponse, &review.VendorRespondedAt,
+		&replyBody, &repliedAt, &replyEditedAt,
 		&review.CreatedAt, &review.UpdatedAt,
 		&review.UserName, &review.UserAvatar,
 	)
@@ -252,6 +322,8 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*Review, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get review: %w", err)
 	}
+	review.VerifiedPurchase = review.IsVerified
+	review.Reply = newReviewReply(review, replyBody, repliedAt, replyEditedAt)
 
 	return review, nil
 }
@@ -279,7 +351,7 @@ func (s *Service) List(ctx context.Context, opts *ReviewListOptions) ([]*Review,
 			r.title, r.comment, r.image_urls,
 			r.is_verified, r.is_published, r.is_flagged, r.flag_reason,
 			r.helpful_count, r.not_helpful_count,
-			r.vendor_response, r.vendor_responded_at,
+			r.vendor_response, r.vendor_responded_at, r.vendor_response_edited_at,
 			r.created_at, r.updated_at,
 			COALESCE(u.first_name || ' ' || u.last_name, u.display_name, 'Anonymous') as user_name,
 			u.avatar_url
@@ -355,6 +427,8 @@ func (s *Service) List(ctx context.Context, opts *ReviewListOptions) ([]*Review,
 	reviews := []*Review{}
 	for rows.Next() {
 		review := &Review{}
+		var replyBody *string
+		var repliedAt, replyEditedAt *time.Time
 		err := rows.Scan(
 			&review.ID, &review.VendorID, &review.UserID, &review.BookingID,
 			&review.Rating, &review.QualityRating, &review.CommunicationRating,
@@ -362,13 +436,15 @@ func (s *Service) List(ctx context.Context, opts *ReviewListOptions) ([]*Review,
 			&review.Title, &review.Comment, &review.ImageURLs,
 			&review.IsVerified, &review.IsPublished, &review.IsFlagged, &review.FlagReason,
 			&review.HelpfulCount, &review.NotHelpfulCount,
-			&review.VendorResponse, &review.VendorRespondedAt,
+			&replyBody, &repliedAt, &replyEditedAt,
 			&review.CreatedAt, &review.UpdatedAt,
 			&review.UserName, &review.UserAvatar,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan review: %w", err)
 		}
+		review.VerifiedPurchase = review.IsVerified
+		review.Reply = newReviewReply(review, replyBody, repliedAt, replyEditedAt)
 		reviews = append(reviews, review)
 	}
 
@@ -378,8 +454,8 @@ func (s *Service) List(ctx context.Context, opts *ReviewListOptions) ([]*Review,
 // Update updates a review
 func (s *Service) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, req *UpdateReviewRequest) (*Review, error) {
 	// Verify user owns this review
-	var reviewUserID uuid.UUID
-	err := s.db.QueryRow(ctx, "SELECT user_id FROM reviews WHERE id = $1", id).Scan(&reviewUserID)
+	var reviewUserID, vendorID uuid.UUID
+	err := s.db.QueryRow(ctx, "SELECT user_id, vendor_id FROM reviews WHERE id = $1", id).Scan(&reviewUserID, &vendorID)
 	if err == pgx.ErrNoRows {
 		return nil, ErrReviewNotFound
 	}
@@ -429,9 +505,14 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, re
 
 	query := fmt.Sprintf("UPDATE reviews SET %s WHERE id = $1", joinUpdates(updates))
 
-	_, err = s.db.Exec(ctx, query, args...)
+	err = s.writeReview(ctx, vendorID, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to update review: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update review: %w", err)
+		return nil, err
 	}
 
 	return s.GetByID(ctx, id)
@@ -440,8 +521,8 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, re
 // Delete deletes a review (soft delete by unpublishing)
 func (s *Service) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
 	// Verify user owns this review
-	var reviewUserID uuid.UUID
-	err := s.db.QueryRow(ctx, "SELECT user_id FROM reviews WHERE id = $1", id).Scan(&reviewUserID)
+	var reviewUserID, vendorID uuid.UUID
+	err := s.db.QueryRow(ctx, "SELECT user_id, vendor_id FROM reviews WHERE id = $1", id).Scan(&reviewUserID, &vendorID)
 	if err == pgx.ErrNoRows {
 		return ErrReviewNotFound
 	}
@@ -454,55 +535,123 @@ func (s *Service) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) er
 	}
 
 	query := `UPDATE reviews SET is_published = FALSE, updated_at = $1 WHERE id = $2`
-	_, err = s.db.Exec(ctx, query, time.Now(), id)
+	return s.writeReview(ctx, vendorID, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, query, time.Now(), id); err != nil {
+			return fmt.Errorf("failed to delete review: %w", err)
+		}
+		return nil
+	})
+}
+
+// AddVendorResponse replies to a review on behalf of the user who owns the
+// reviewed vendor
+func (s *Service) AddVendorResponse(ctx context.Context, reviewID uuid.UUID, vendorUserID uuid.UUID, response string) error {
+	vendorID, err := s.respondingVendor(ctx, reviewID, vendorUserID)
 	if err != nil {
-		return fmt.Errorf("failed to delete review: %w", err)
+		return err
 	}
+	return s.AddReply(ctx, reviewID, vendorID, response)
+}
 
-	return nil
+// EditVendorResponse edits the reply to a review on behalf of the user who
+// owns the reviewed vendor
+func (s *Service) EditVendorResponse(ctx context.Context, reviewID uuid.UUID, vendorUserID uuid.UUID, response string) error {
+	vendorID, err := s.respondingVendor(ctx, reviewID, vendorUserID)
+	if err != nil {
+		return err
+	}
+	return s.EditReply(ctx, reviewID, vendorID, response)
 }
 
-// AddVendorResponse adds a vendor response to a review
-func (s *Service) AddVendorResponse(ctx context.Context, reviewID uuid.UUID, vendorUserID uuid.UUID, response string) error {
-	// Verify vendor owns the vendor account being reviewed
-	var vendorID uuid.UUID
+// respondingVendor returns the reviewed vendor if vendorUserID owns it
+func (s *Service) respondingVendor(ctx context.Context, reviewID uuid.UUID, vendorUserID uuid.UUID) (uuid.UUID, error) {
+	var vendorID, ownerUserID uuid.UUID
 	err := s.db.QueryRow(ctx,
-		"SELECT r.vendor_id FROM reviews r WHERE r.id = $1",
+		"SELECT v.id, v.user_id FROM reviews r JOIN vendors v ON v.id = r.vendor_id WHERE r.id = $1",
 		reviewID,
-	).Scan(&vendorID)
+	).Scan(&vendorID, &ownerUserID)
 
 	if err == pgx.ErrNoRows {
-		return ErrReviewNotFound
+		return uuid.Nil, ErrReviewNotFound
 	}
 	if err != nil {
-		return fmt.Errorf("failed to get review: %w", err)
+		return uuid.Nil, fmt.Errorf("failed to verify vendor ownership: %w", err)
+	}
+	if ownerUserID != vendorUserID {
+		return uuid.Nil, ErrUnauthorized
 	}
+	return vendorID, nil
+}
+
+// ReplyStore keeps vendors' replies to reviews
+type ReplyStore interface {
+	// ReviewReply returns the vendor a review is for and its reply, nil if
+	// the vendor has not replied, or ErrReviewNotFound
+	ReviewReply(ctx context.Context, reviewID uuid.UUID) (vendorID uuid.UUID, reply *ReviewReply, err error)
+	// CreateReply saves a review's reply, or returns ErrReplyExists if the
+	// review already has one
+	CreateReply(ctx context.Context, reply *ReviewReply) error
+	// EditReply replaces the body of a review's reply
+	EditReply(ctx context.Context, reply *ReviewReply) error
+}
 
-	// Check if vendor user owns this vendor
-	var ownerUserID uuid.UUID
-	err = s.db.QueryRow(ctx, "SELECT user_id FROM vendors WHERE id = $1", vendorID).Scan(&ownerUserID)
+// reviewReply loads a review's reply for vendorID, which must be the
+// reviewed vendor
+func (s *Service) reviewReply(ctx context.Context, reviewID, vendorID uuid.UUID, body string) (*ReviewReply, error) {
+	if s.replies == nil {
+		return nil, errors.New("reply store not configured")
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("%w: reply is required", ErrInvalidReviewData)
+	}
+
+	reviewedVendor, reply, err := s.replies.ReviewReply(ctx, reviewID)
 	if err != nil {
-		return fmt.Errorf("failed to verify vendor ownership: %w", err)
+		return nil, err
+	}
+	if reviewedVendor != vendorID {
+		return nil, ErrUnauthorized
 	}
+	return reply, nil
+}
 
-	if ownerUserID != vendorUserID {
-		return ErrUnauthorized
+// AddReply publishes the reviewed vendor's reply to a review. Only the
+// reviewed vendor may reply, and only once; see EditReply.
+func (s *Service) AddReply(ctx context.Context, reviewID, vendorID uuid.UUID, body string) error {
+	reply, err := s.reviewReply(ctx, reviewID, vendorID, body)
+	if err != nil {
+		return err
+	}
+	if reply != nil {
+		return ErrReplyExists
 	}
 
-	// Add response
-	now := time.Now()
-	query := `
-		UPDATE reviews
-		SET vendor_response = $1, vendor_responded_at = $2, updated_at = $3
-		WHERE id = $4
-	`
+	return s.replies.CreateReply(ctx, &ReviewReply{
+		ReviewID:  reviewID,
+		VendorID:  vendorID,
+		Body:      body,
+		CreatedAt: time.Now(),
+	})
+}
 
-	_, err = s.db.Exec(ctx, query, response, now, now, reviewID)
+// EditReply replaces the body of the vendor's reply to a review, within the
+// reply edit window of it first being posted
+func (s *Service) EditReply(ctx context.Context, reviewID, vendorID uuid.UUID, body string) error {
+	reply, err := s.reviewReply(ctx, reviewID, vendorID, body)
 	if err != nil {
-		return fmt.Errorf("failed to add vendor response: %w", err)
+		return err
+	}
+	if reply == nil {
+		return ErrReplyNotFound
 	}
 
-	return nil
+	now := time.Now()
+	if now.Sub(reply.CreatedAt) > s.replyEditWindow {
+		return ErrReplyEditClosed
+	}
+	reply.Body = body
+	reply.EditedAt = &now
+	return s.replies.EditReply(ctx, reply)
 }
 
 // VoteHelpful records a helpful vote on a review
@@ -522,6 +671,283 @@ func (s *Service) VoteHelpful(ctx context.Context, reviewID uuid.UUID, userID uu
 	return nil
 }
 
+// VendorRating is a vendor's aggregate over their published reviews
+type VendorRating struct {
+	Average float64 `json:"rating_average"` // Rounded to one decimal, 0 with no reviews
+	Count   int     `json:"rating_count"`
+}
+
+// NewVendorRating aggregates published reviews the way vendors.rating_average
+// stores them. Verified purchase reviews count verifiedWeight times toward
+// the average; a weight of 0 or less counts them once.
+func NewVendorRating(reviews []Review, verifiedWeight float64) VendorRating {
+	if verifiedWeight <= 0 {
+		verifiedWeight = 1
+	}
+
+	var rating VendorRating
+	var total, weights float64
+	for _, review := range reviews {
+		if !review.IsPublished {
+			continue
+		}
+		weight := 1.0
+		if review.VerifiedPurchase {
+			weight = verifiedWeight
+		}
+		total += weight * float64(review.Rating)
+		weights += weight
+		rating.Count++
+	}
+	if rating.Count > 0 {
+		rating.Average = math.Round(total/weights*10) / 10
+	}
+	return rating
+}
+
+// RatingStore recomputes vendors' aggregate ratings
+type RatingStore interface {
+	// RecomputeVendorRating recalculates the vendor's rating from their
+	// published reviews, as NewVendorRating does, and saves it on the
+	// vendor. It runs within tx so the rating commits with the review write
+	// that changed it; a nil tx runs it in a transaction of its own.
+	RecomputeVendorRating(ctx context.Context, tx pgx.Tx, vendorID uuid.UUID, verifiedWeight float64) (VendorRating, error)
+}
+
+// RecomputeVendorRating recalculates a vendor's rating_average and
+// rating_count from their published reviews, weighting verified purchases
+func (s *Service) RecomputeVendorRating(ctx context.Context, vendorID uuid.UUID) error {
+	return s.recomputeVendorRating(ctx, nil, vendorID)
+}
+
+func (s *Service) recomputeVendorRating(ctx context.Context, tx pgx.Tx, vendorID uuid.UUID) error {
+	if s.ratings == nil {
+		return errors.New("rating store not configured")
+	}
+	if _, err := s.ratings.RecomputeVendorRating(ctx, tx, vendorID, s.verifiedWeight); err != nil {
+		return fmt.Errorf("failed to update vendor rating: %w", err)
+	}
+	return nil
+}
+
+// writeReview runs write and the recompute of the vendor's rating in one
+// transaction, so a review change never commits without its rating and a
+// failed recompute leaves nothing for the client to retry into a duplicate
+func (s *Service) writeReview(ctx context.Context, vendorID uuid.UUID, write func(tx pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		if err := write(tx); err != nil {
+			return err
+		}
+		return s.recomputeVendorRating(ctx, tx, vendorID)
+	})
+}
+
+// PostgresRatingStore recomputes ratings from the reviews table onto vendors
+type PostgresRatingStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRatingStore creates a database-backed rating store
+func NewPostgresRatingStore(db *pgxpool.Pool) *PostgresRatingStore {
+	return &PostgresRatingStore{db: db}
+}
+
+// RecomputeVendorRating locks the vendor row while it reads the reviews, so
+// concurrent recomputes cannot save a stale aggregate over a newer one
+func (p *PostgresRatingStore) RecomputeVendorRating(ctx context.Context, tx pgx.Tx, vendorID uuid.UUID, verifiedWeight float64) (VendorRating, error) {
+	if tx == nil {
+		var rating VendorRating
+		err := pgx.BeginFunc(ctx, p.db, func(tx pgx.Tx) error {
+			var err error
+			rating, err = p.RecomputeVendorRating(ctx, tx, vendorID, verifiedWeight)
+			return err
+		})
+		return rating, err
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM vendors WHERE id = $1 FOR UPDATE", vendorID); err != nil {
+		return VendorRating{}, err
+	}
+
+	rows, err := tx.Query(ctx, "SELECT rating, is_verified FROM reviews WHERE vendor_id = $1 AND is_published = TRUE", vendorID)
+	if err != nil {
+		return VendorRating{}, err
+	}
+	var reviews []Review
+	for rows.Next() {
+		review := Review{IsPublished: true}
+		if err := rows.Scan(&review.Rating, &review.VerifiedPurchase); err != nil {
+			rows.Close()
+			return VendorRating{}, err
+		}
+		reviews = append(reviews, review)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return VendorRating{}, err
+	}
+
+	rating := NewVendorRating(reviews, verifiedWeight)
+	_, err = tx.Exec(ctx,
+		"UPDATE vendors SET rating_average = $1, rating_count = $2, updated_at = NOW() WHERE id = $3",
+		rating.Average, rating.Count, vendorID,
+	)
+	if err != nil {
+		return VendorRating{}, err
+	}
+	return rating, nil
+}
+
+// ReviewBooking is the part of a booking that verifies a purchase
+type ReviewBooking struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	VendorID uuid.UUID
+	Status   string
+}
+
+// BookingLookup finds the bookings behind verified purchase reviews
+type BookingLookup interface {
+	// FindBooking returns ErrBookingNotFound for an unknown booking
+	FindBooking(ctx context.Context, bookingID uuid.UUID) (*ReviewBooking, error)
+	HasCompletedBooking(ctx context.Context, userID, vendorID uuid.UUID) (bool, error)
+}
+
+// VerifyPurchase reports whether a review is a verified purchase: the
+// reviewer has completed a booking with the vendor. A review naming a
+// booking must be for that booking's vendor, by its customer, once the
+// booking is confirmed or completed; only a completed one verifies it.
+func (s *Service) VerifyPurchase(ctx context.Context, req *CreateReviewRequest) (bool, error) {
+	if s.bookings == nil {
+		return false, errors.New("booking lookup not configured")
+	}
+
+	if req.BookingID == nil {
+		completed, err := s.bookings.HasCompletedBooking(ctx, req.UserID, req.VendorID)
+		if err != nil {
+			return false, fmt.Errorf("failed to verify booking: %w", err)
+		}
+		return completed, nil
+	}
+
+	booking, err := s.bookings.FindBooking(ctx, *req.BookingID)
+	if err != nil && !errors.Is(err, ErrBookingNotFound) {
+		return false, fmt.Errorf("failed to verify booking: %w", err)
+	}
+	if err != nil || booking.UserID != req.UserID {
+		return false, ErrBookingNotFound
+	}
+	if booking.VendorID != req.VendorID {
+		return false, ErrBookingWrongVendor
+	}
+	switch booking.Status {
+	case "completed":
+		return true, nil
+	case "confirmed":
+		return false, nil
+	}
+	return false, ErrBookingNotCompleted
+}
+
+// PostgresBookingLookup reads the bookings table
+type PostgresBookingLookup struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresBookingLookup creates a database-backed booking lookup
+func NewPostgresBookingLookup(db *pgxpool.Pool) *PostgresBookingLookup {
+	return &PostgresBookingLookup{db: db}
+}
+
+// FindBooking returns a booking's customer, vendor and status
+func (p *PostgresBookingLookup) FindBooking(ctx context.Context, bookingID uuid.UUID) (*ReviewBooking, error) {
+	booking := &ReviewBooking{ID: bookingID}
+	err := p.db.QueryRow(ctx,
+		"SELECT user_id, vendor_id, status FROM bookings WHERE id = $1",
+		bookingID,
+	).Scan(&booking.UserID, &booking.VendorID, &booking.Status)
+	if err == pgx.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// HasCompletedBooking reports whether the user completed any booking with
+// the vendor
+func (p *PostgresBookingLookup) HasCompletedBooking(ctx context.Context, userID, vendorID uuid.UUID) (bool, error) {
+	var completed bool
+	err := p.db.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM bookings WHERE user_id = $1 AND vendor_id = $2 AND status = 'completed')",
+		userID, vendorID,
+	).Scan(&completed)
+	return completed, err
+}
+
+// PostgresReplyStore keeps replies in the reviews table's vendor_response
+// columns
+type PostgresReplyStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresReplyStore creates a database-backed reply store
+func NewPostgresReplyStore(db *pgxpool.Pool) *PostgresReplyStore {
+	return &PostgresReplyStore{db: db}
+}
+
+// ReviewReply returns a review's vendor and reply
+func (p *PostgresReplyStore) ReviewReply(ctx context.Context, reviewID uuid.UUID) (uuid.UUID, *ReviewReply, error) {
+	review := &Review{ID: reviewID}
+	var body *string
+	var repliedAt, editedAt *time.Time
+	err := p.db.QueryRow(ctx,
+		"SELECT vendor_id, vendor_response, vendor_responded_at, vendor_response_edited_at FROM reviews WHERE id = $1",
+		reviewID,
+	).Scan(&review.VendorID, &body, &repliedAt, &editedAt)
+	if err == pgx.ErrNoRows {
+		return uuid.Nil, nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to get review: %w", err)
+	}
+	return review.VendorID, newReviewReply(review, body, repliedAt, editedAt), nil
+}
+
+// CreateReply sets the reply only while the review has none, so concurrent
+// replies save one
+func (p *PostgresReplyStore) CreateReply(ctx context.Context, reply *ReviewReply) error {
+	tag, err := p.db.Exec(ctx, `
+		UPDATE reviews
+		SET vendor_response = $1, vendor_responded_at = $2, vendor_response_edited_at = NULL, updated_at = $2
+		WHERE id = $3 AND vendor_response IS NULL
+	`, reply.Body, reply.CreatedAt, reply.ReviewID)
+	if err != nil {
+		return fmt.Errorf("failed to add vendor response: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReplyExists
+	}
+	return nil
+}
+
+// EditReply replaces a reply's body
+func (p *PostgresReplyStore) EditReply(ctx context.Context, reply *ReviewReply) error {
+	tag, err := p.db.Exec(ctx, `
+		UPDATE reviews
+		SET vendor_response = $1, vendor_response_edited_at = $2, updated_at = $2
+		WHERE id = $3 AND vendor_response IS NOT NULL
+	`, reply.Body, reply.EditedAt, reply.ReviewID)
+	if err != nil {
+		return fmt.Errorf("failed to edit vendor response: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReplyNotFound
+	}
+	return nil
+}
+
 // Helper methods
 
 func (s *Service) validateCreateRequest(req *CreateReviewRequest) error {
@@ -8,10 +8,13 @@ package search
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -35,6 +38,15 @@ type SearchRequest struct {
 	PageSize   int                 `json:"page_size,omitempty"`
 	SortBy     string              `json:"sort_by,omitempty"`   // 'relevance', 'rating', 'distance', 'price'
 	SortOrder  string              `json:"sort_order,omitempty"` // 'asc', 'desc'
+	
+	// Personalization; requests carrying either are never served from cache
+	ExcludeIDs []uuid.UUID `json:"exclude_ids,omitempty"` // vendors the user has blocked
+	BoostIDs   []uuid.UUID `json:"boost_ids,omitempty"`   // vendors from the user's history
+}
+
+// Personalized reports whether results depend on who is searching
+func (r SearchRequest) Personalized() bool {
+	return len(r.ExcludeIDs) > 0 || len(r.BoostIDs) > 0
 }
 
 type SearchType string
@@ -136,25 +148,62 @@ type ServiceDocument struct {
 type Config struct {
 	ElasticsearchURL string
 	IndexPrefix      string
-	CacheTTL         time.Duration
+	CacheTTL         time.Duration // for non-personalized results, DefaultCacheTTL when zero
 }
 
+// DefaultCacheTTL keeps popular searches warm without serving stale
+// availability or ratings for long
+const DefaultCacheTTL = time.Minute
+
 // Service handles search operations
 type Service struct {
-	db     *pgxpool.Pool
-	cache  *redis.Client
-	config *Config
-	http   *http.Client
+	db      *pgxpool.Pool
+	cache   *redis.Client
+	results ResultCache
+	config  *Config
+	http    *http.Client
 }
 
 // NewService creates a new search service
 func NewService(db *pgxpool.Pool, cache *redis.Client, config *Config) *Service {
-	return &Service{
+	s := &Service{
 		db:     db,
 		cache:  cache,
 		config: config,
 		http:   &http.Client{Timeout: 10 * time.Second},
 	}
+	if cache != nil {
+		s.results = &RedisResultCache{client: cache}
+	}
+	return s
+}
+
+// SetResultCache replaces where search results are cached; nil disables
+// result caching
+func (s *Service) SetResultCache(cache ResultCache) {
+	s.results = cache
+}
+
+// ResultCache stores encoded search responses by key
+type ResultCache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+}
+
+// RedisResultCache keeps search results in Redis
+type RedisResultCache struct {
+	client *redis.Client
+}
+
+// Get returns the cached value, if any
+func (c *RedisResultCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	return value, err == nil
+}
+
+// Set caches value for ttl
+func (c *RedisResultCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	c.client.Set(ctx, key, value, ttl)
 }
 
 // =============================================================================
@@ -179,13 +228,17 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (*SearchRespons
 		req.Type = TypeAll
 	}
 	
-	// Check cache for common queries
-	cacheKey := s.buildCacheKey(req)
-	if cached, err := s.cache.Get(ctx, cacheKey).Result(); err == nil {
-		var resp SearchResponse
-		if json.Unmarshal([]byte(cached), &resp) == nil {
-			resp.TookMs = time.Since(start).Milliseconds()
-			return &resp, nil
+	// Popular searches repeat constantly, so serve them from cache unless
+	// the results depend on who is asking
+	cacheable := s.results != nil && !req.Personalized()
+	cacheKey := CacheKey(req)
+	if cacheable {
+		if cached, ok := s.results.Get(ctx, cacheKey); ok {
+			var resp SearchResponse
+			if json.Unmarshal(cached, &resp) == nil {
+				resp.TookMs = time.Since(start).Milliseconds()
+				return &resp, nil
+			}
 		}
 	}
 	
@@ -208,14 +261,42 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (*SearchRespons
 	resp.TookMs = time.Since(start).Milliseconds()
 	
 	// Cache result
-	respJSON, _ := json.Marshal(resp)
-	s.cache.Set(ctx, cacheKey, respJSON, s.config.CacheTTL)
+	if cacheable {
+		ttl := s.config.CacheTTL
+		if ttl <= 0 {
+			ttl = DefaultCacheTTL
+		}
+		respJSON, _ := json.Marshal(resp)
+		s.results.Set(ctx, cacheKey, respJSON, ttl)
+	}
 	
 	return resp, nil
 }
 
-func (s *Service) buildCacheKey(req SearchRequest) string {
-	return fmt.Sprintf("search:%s:%s:%d:%d", req.Type, req.Query, req.Page, req.PageSize)
+// CacheKey identifies a search by its normalized query and filters, so
+// "Photographer  in Lagos" and "photographer in lagos" share an entry.
+// Locations are rounded to about 100m.
+func CacheKey(req SearchRequest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%d|%d|%s|%s",
+		req.Type, strings.Join(strings.Fields(strings.ToLower(req.Query)), " "),
+		req.Page, req.PageSize, req.SortBy, req.SortOrder)
+	
+	keys := make([]string, 0, len(req.Filters))
+	for k := range req.Filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "|%s=%v", k, req.Filters[k])
+	}
+	
+	if req.Location != nil {
+		fmt.Fprintf(&b, "|geo=%.3f,%.3f,%g", req.Location.Lat, req.Location.Lon, req.RadiusKM)
+	}
+	
+	sum := sha256.Sum256([]byte(b.String()))
+	return "search:" + hex.EncodeToString(sum[:16])
 }
 
 func (s *Service) getIndices(searchType SearchType) string {
@@ -268,6 +349,19 @@ func (s *Service) buildElasticsearchQuery(req SearchRequest) map[string]interfac
 		})
 	}
 	
+	// Personalization: hide blocked vendors, lift ones from the user's history
+	mustNot := []map[string]interface{}{}
+	if len(req.ExcludeIDs) > 0 {
+		mustNot = append(mustNot, map[string]interface{}{
+			"ids": map[string]interface{}{"values": uuidStrings(req.ExcludeIDs)},
+		})
+	}
+	if len(req.BoostIDs) > 0 {
+		should = append(should, map[string]interface{}{
+			"ids": map[string]interface{}{"values": uuidStrings(req.BoostIDs), "boost": 2.0},
+		})
+	}
+	
 	// Apply filters
 	for key, value := range req.Filters {
 		switch key {
@@ -306,6 +400,10 @@ func (s *Service) buildElasticsearchQuery(req SearchRequest) map[string]interfac
 	}
 	if len(should) > 0 {
 		boolQuery["should"] = should
+		boolQuery["minimum_should_match"] = 0 // should clauses only rank
+	}
+	if len(mustNot) > 0 {
+		boolQuery["must_not"] = mustNot
 	}
 	
 	if len(boolQuery) > 0 {
@@ -388,6 +486,14 @@ func (s *Service) buildElasticsearchQuery(req SearchRequest) map[string]interfac
 	return query
 }
 
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
 func (s *Service) executeSearch(ctx context.Context, indices string, query map[string]interface{}) (*SearchResponse, error) {
 	body, _ := json.Marshal(query)
 	
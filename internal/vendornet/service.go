@@ -4,13 +4,20 @@ package vendornet
 import (
 	"context"
 	"database/sql"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/phone"
 )
 
 var (
@@ -21,6 +28,10 @@ var (
 	ErrReferralNotFound      = errors.New("referral not found")
 	ErrInvalidReferralData   = errors.New("invalid referral data")
 	ErrUnauthorized          = errors.New("unauthorized")
+	ErrVendorNotFound        = errors.New("no vendor account for user")
+	ErrInvalidExportType     = errors.New("invalid export type")
+	ErrInvalidExportFormat   = errors.New("invalid export format")
+	ErrExportNotInPlan       = errors.New("network export requires a paid subscription")
 )
 
 // Service handles VendorNet partnership and referral operations
@@ -137,6 +148,18 @@ type NetworkAnalytics struct {
 	TotalRevenueEarned    float64   `json:"total_revenue_earned"`
 }
 
+// ReferralFeeStats holds a vendor's received-referral outcomes alongside the
+// norms of vendors sharing its primary category
+type ReferralFeeStats struct {
+	Received          int     `json:"received"`
+	Accepted          int     `json:"accepted"`
+	Converted         int     `json:"converted"`
+	CategoryMedianFee float64 `json:"category_median_fee"` // percentage fees only
+	CategoryReceived  int     `json:"category_received"`
+	CategoryAccepted  int     `json:"category_accepted"`
+	CategoryConverted int     `json:"category_converted"`
+}
+
 // =============================================================================
 // PARTNERSHIP OPERATIONS
 // =============================================================================
@@ -328,6 +351,13 @@ func (s *Service) CreateReferral(ctx context.Context, req *CreateReferralRequest
 	if req.SourceVendorID == req.DestVendorID {
 		return nil, fmt.Errorf("%w: cannot refer to self", ErrInvalidReferralData)
 	}
+	if req.ClientPhone != nil && *req.ClientPhone != "" {
+		clientPhone, err := phone.Normalize(*req.ClientPhone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid client phone", ErrInvalidReferralData)
+		}
+		req.ClientPhone = &clientPhone
+	}
 
 	// Generate tracking code
 	trackingCode := fmt.Sprintf("REF-%s", uuid.New().String()[:8])
@@ -557,3 +587,482 @@ func (s *Service) GetNetworkAnalytics(ctx context.Context, vendorID uuid.UUID) (
 
 	return analytics, nil
 }
+
+// GetReferralFeeStats gathers the referral outcomes used to recommend a
+// vendor's default referral fee
+func (s *Service) GetReferralFeeStats(ctx context.Context, vendorID uuid.UUID) (*ReferralFeeStats, error) {
+	stats := &ReferralFeeStats{}
+
+	err := s.db.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status IN ('accepted', 'contacted', 'quoted', 'converted', 'lost')),
+			COUNT(*) FILTER (WHERE status = 'converted')
+		FROM referrals
+		WHERE dest_vendor_id = $1
+	`, vendorID).Scan(&stats.Received, &stats.Accepted, &stats.Converted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referral stats: %w", err)
+	}
+
+	err = s.db.QueryRow(ctx, `
+		WITH peers AS (
+			SELECT v.id FROM vendors v
+			JOIN vendors me ON me.id = $1
+			WHERE v.primary_category_id = me.primary_category_id
+		)
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE r.status IN ('accepted', 'contacted', 'quoted', 'converted', 'lost')),
+			COUNT(*) FILTER (WHERE r.status = 'converted'),
+			COALESCE((
+				SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY vp.referral_fee_value)
+				FROM vendor_partnerships vp
+				WHERE vp.status = 'active'
+				  AND vp.referral_fee_type = 'percentage'
+				  AND (vp.vendor_a_id IN (SELECT id FROM peers) OR vp.vendor_b_id IN (SELECT id FROM peers))
+			), 0)
+		FROM referrals r
+		WHERE r.dest_vendor_id IN (SELECT id FROM peers)
+	`, vendorID).Scan(&stats.CategoryReceived, &stats.CategoryAccepted, &stats.CategoryConverted, &stats.CategoryMedianFee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category referral stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// =============================================================================
+// EXPORT OPERATIONS
+// =============================================================================
+
+// Export types and formats supported by ExportNetwork
+const (
+	ExportReferrals    = "referrals"
+	ExportPartnerships = "partnerships"
+	ExportConnections  = "connections"
+
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+
+	exportBatchSize = 500
+)
+
+// Connection is a partner vendor as seen from the exporting vendor's side
+type Connection struct {
+	PartnershipID       uuid.UUID  `json:"partnership_id"`
+	PartnerVendorID     uuid.UUID  `json:"partner_vendor_id"`
+	PartnerBusinessName string     `json:"partner_business_name"`
+	PartnershipType     string     `json:"partnership_type"`
+	Status              string     `json:"status"`
+	ReferralsSent       int        `json:"referrals_sent"`
+	ReferralsReceived   int        `json:"referrals_received"`
+	ConnectedAt         time.Time  `json:"connected_at"`
+	ActivatedAt         *time.Time `json:"activated_at,omitempty"`
+}
+
+var exportColumns = map[string][]string{
+	ExportReferrals: {
+		"id", "direction", "source_vendor_id", "dest_vendor_id", "client_name",
+		"client_email", "client_phone", "event_type", "event_date", "estimated_value",
+		"status", "status_history", "fee_type", "fee_value", "fee_paid",
+		"tracking_code", "created_at", "updated_at", "converted_at",
+	},
+	ExportPartnerships: {
+		"id", "vendor_a_id", "vendor_b_id", "partnership_type", "referral_fee_type",
+		"referral_fee_value", "is_bidirectional", "total_referrals", "successful_referrals",
+		"total_revenue_generated", "status", "created_at", "updated_at",
+		"activated_at", "expires_at",
+	},
+	ExportConnections: {
+		"partnership_id", "partner_vendor_id", "partner_business_name", "partnership_type",
+		"status", "referrals_sent", "referrals_received", "connected_at", "activated_at",
+	},
+}
+
+// ExportColumns returns the CSV header for an export type
+func ExportColumns(exportType string) ([]string, error) {
+	cols, ok := exportColumns[exportType]
+	if !ok {
+		return nil, ErrInvalidExportType
+	}
+	return cols, nil
+}
+
+// ExportWriter receives export records one at a time so large accounts are
+// streamed rather than buffered
+type ExportWriter interface {
+	Begin(columns []string) error
+	Write(record any, row []string) error
+	End() error
+}
+
+// NewExportWriter returns a writer for the requested format
+func NewExportWriter(format string, w io.Writer) (ExportWriter, error) {
+	switch format {
+	case ExportFormatCSV, "":
+		return &csvExportWriter{w: csv.NewWriter(w)}, nil
+	case ExportFormatJSON:
+		return &jsonExportWriter{w: w}, nil
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvExportWriter) Begin(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvExportWriter) Write(record any, row []string) error {
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) End() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonExportWriter) Begin(columns []string) error {
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonExportWriter) Write(record any, row []string) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonExportWriter) End() error {
+	_, err := io.WriteString(j.w, "]")
+	return err
+}
+
+// ExportRow flattens a referral into CSV columns from the exporting vendor's
+// point of view
+func (r *Referral) ExportRow(vendorID uuid.UUID) []string {
+	direction := "received"
+	if r.SourceVendorID == vendorID {
+		direction = "sent"
+	}
+	return []string{
+		r.ID.String(), direction, r.SourceVendorID.String(), r.DestVendorID.String(),
+		derefString(r.ClientName), derefString(r.ClientEmail), derefString(r.ClientPhone),
+		derefString(r.EventType), formatTimePtr(r.EventDate), formatInt64Ptr(r.EstimatedValue),
+		r.Status, string(r.StatusHistory), derefString(r.FeeType), formatFloatPtr(r.FeeValue),
+		strconv.FormatBool(r.FeePaid), r.TrackingCode,
+		r.CreatedAt.Format(time.RFC3339), r.UpdatedAt.Format(time.RFC3339), formatTimePtr(r.ConvertedAt),
+	}
+}
+
+// ExportRow flattens a partnership into CSV columns
+func (p *Partnership) ExportRow() []string {
+	return []string{
+		p.ID.String(), p.VendorAID.String(), p.VendorBID.String(), p.PartnershipType,
+		derefString(p.ReferralFeeType), formatFloatPtr(p.ReferralFeeValue),
+		strconv.FormatBool(p.IsBidirectional), strconv.Itoa(p.TotalReferrals),
+		strconv.Itoa(p.SuccessfulReferrals), strconv.FormatFloat(p.TotalRevenueGenerated, 'f', 2, 64),
+		p.Status, p.CreatedAt.Format(time.RFC3339), p.UpdatedAt.Format(time.RFC3339),
+		formatTimePtr(p.ActivatedAt), formatTimePtr(p.ExpiresAt),
+	}
+}
+
+// ExportRow flattens a connection into CSV columns
+func (c *Connection) ExportRow() []string {
+	return []string{
+		c.PartnershipID.String(), c.PartnerVendorID.String(), c.PartnerBusinessName,
+		c.PartnershipType, c.Status, strconv.Itoa(c.ReferralsSent), strconv.Itoa(c.ReferralsReceived),
+		c.ConnectedAt.Format(time.RFC3339), formatTimePtr(c.ActivatedAt),
+	}
+}
+
+// referralExport is the JSON shape of an exported referral; status history is
+// emitted as JSON rather than base64 bytes
+type referralExport struct {
+	*Referral
+	Direction     string          `json:"direction"`
+	StatusHistory json.RawMessage `json:"status_history,omitempty"`
+}
+
+// WriteReferralExport writes referrals to w, skipping any that do not involve
+// vendorID so an export can never leak another vendor's records
+func WriteReferralExport(w ExportWriter, vendorID uuid.UUID, referrals []*Referral) error {
+	for _, r := range referrals {
+		if r.SourceVendorID != vendorID && r.DestVendorID != vendorID {
+			continue
+		}
+		record := referralExport{Referral: r, Direction: "received"}
+		if r.SourceVendorID == vendorID {
+			record.Direction = "sent"
+		}
+		if len(r.StatusHistory) > 0 {
+			record.StatusHistory = json.RawMessage(r.StatusHistory)
+		}
+		if err := w.Write(record, r.ExportRow(vendorID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportTiers are the paid subscription tiers that include network export.
+// The entry tiers, "free" and "basic", do not.
+var exportTiers = map[string]bool{
+	"pro":          true,
+	"professional": true,
+	"business":     true,
+	"enterprise":   true,
+}
+
+// ExportAccount is the vendor account a user exports from, with the
+// subscription that decides whether they may
+type ExportAccount struct {
+	VendorID         uuid.UUID
+	SubscriptionTier string
+	SubscriptionEnds *time.Time
+}
+
+// CanExport reports whether the account's subscription includes network
+// export at now; a paid tier without an end date is treated as ongoing
+func (a ExportAccount) CanExport(now time.Time) bool {
+	if !exportTiers[a.SubscriptionTier] {
+		return false
+	}
+	return a.SubscriptionEnds == nil || a.SubscriptionEnds.After(now)
+}
+
+// GetExportAccount resolves the vendor account owned by a user and its
+// subscription
+func (s *Service) GetExportAccount(ctx context.Context, userID uuid.UUID) (*ExportAccount, error) {
+	var account ExportAccount
+	var tier *string
+	err := s.db.QueryRow(ctx, `
+		SELECT id, subscription_tier, subscription_ends
+		FROM vendors WHERE user_id = $1 LIMIT 1
+	`, userID).Scan(&account.VendorID, &tier, &account.SubscriptionEnds)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVendorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tier != nil {
+		account.SubscriptionTier = *tier
+	}
+	return &account, nil
+}
+
+// ExportNetwork streams a vendor's referrals, partnerships or connections to
+// w, paging through the database with a keyset cursor
+func (s *Service) ExportNetwork(ctx context.Context, vendorID uuid.UUID, exportType string, w ExportWriter) error {
+	columns, err := ExportColumns(exportType)
+	if err != nil {
+		return err
+	}
+	if err := w.Begin(columns); err != nil {
+		return err
+	}
+
+	cursorTime := time.Time{}
+	cursorID := uuid.Nil
+	for {
+		var n int
+		switch exportType {
+		case ExportReferrals:
+			var batch []*Referral
+			batch, err = s.listReferralsAfter(ctx, vendorID, cursorTime, cursorID)
+			if err == nil {
+				err = WriteReferralExport(w, vendorID, batch)
+			}
+			if n = len(batch); n > 0 {
+				cursorTime, cursorID = batch[n-1].CreatedAt, batch[n-1].ID
+			}
+		case ExportPartnerships:
+			var batch []*Partnership
+			batch, err = s.listPartnershipsAfter(ctx, vendorID, cursorTime, cursorID)
+			for _, p := range batch {
+				if err == nil {
+					err = w.Write(p, p.ExportRow())
+				}
+			}
+			if n = len(batch); n > 0 {
+				cursorTime, cursorID = batch[n-1].CreatedAt, batch[n-1].ID
+			}
+		case ExportConnections:
+			var batch []*Connection
+			batch, err = s.listConnectionsAfter(ctx, vendorID, cursorTime, cursorID)
+			for _, c := range batch {
+				if err == nil {
+					err = w.Write(c, c.ExportRow())
+				}
+			}
+			if n = len(batch); n > 0 {
+				cursorTime, cursorID = batch[n-1].ConnectedAt, batch[n-1].PartnershipID
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", exportType, err)
+		}
+		if n < exportBatchSize {
+			break
+		}
+	}
+
+	return w.End()
+}
+
+func (s *Service) listReferralsAfter(ctx context.Context, vendorID uuid.UUID, afterTime time.Time, afterID uuid.UUID) ([]*Referral, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, source_vendor_id, dest_vendor_id, client_name,
+		       client_email, client_phone, event_type, event_date,
+		       estimated_value, status, status_history, fee_type,
+		       fee_value, fee_paid, tracking_code, notes, feedback,
+		       created_at, updated_at, converted_at
+		FROM referrals
+		WHERE (source_vendor_id = $1 OR dest_vendor_id = $1)
+		  AND (created_at, id) > ($2, $3)
+		ORDER BY created_at, id
+		LIMIT $4
+	`, vendorID, afterTime, afterID, exportBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var referrals []*Referral
+	for rows.Next() {
+		var r Referral
+		if err := rows.Scan(
+			&r.ID, &r.SourceVendorID, &r.DestVendorID, &r.ClientName,
+			&r.ClientEmail, &r.ClientPhone, &r.EventType, &r.EventDate,
+			&r.EstimatedValue, &r.Status, &r.StatusHistory, &r.FeeType,
+			&r.FeeValue, &r.FeePaid, &r.TrackingCode, &r.Notes, &r.Feedback,
+			&r.CreatedAt, &r.UpdatedAt, &r.ConvertedAt,
+		); err != nil {
+			return nil, err
+		}
+		referrals = append(referrals, &r)
+	}
+	return referrals, rows.Err()
+}
+
+func (s *Service) listPartnershipsAfter(ctx context.Context, vendorID uuid.UUID, afterTime time.Time, afterID uuid.UUID) ([]*Partnership, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, vendor_a_id, vendor_b_id, partnership_type,
+		       referral_fee_type, referral_fee_value, is_bidirectional,
+		       total_referrals, successful_referrals, total_revenue_generated,
+		       status, initiated_by, terms_and_conditions,
+		       created_at, updated_at, activated_at, expires_at
+		FROM vendor_partnerships
+		WHERE (vendor_a_id = $1 OR vendor_b_id = $1)
+		  AND (created_at, id) > ($2, $3)
+		ORDER BY created_at, id
+		LIMIT $4
+	`, vendorID, afterTime, afterID, exportBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partnerships []*Partnership
+	for rows.Next() {
+		var p Partnership
+		if err := rows.Scan(
+			&p.ID, &p.VendorAID, &p.VendorBID, &p.PartnershipType,
+			&p.ReferralFeeType, &p.ReferralFeeValue, &p.IsBidirectional,
+			&p.TotalReferrals, &p.SuccessfulReferrals, &p.TotalRevenueGenerated,
+			&p.Status, &p.InitiatedBy, &p.TermsAndConditions,
+			&p.CreatedAt, &p.UpdatedAt, &p.ActivatedAt, &p.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		partnerships = append(partnerships, &p)
+	}
+	return partnerships, rows.Err()
+}
+
+func (s *Service) listConnectionsAfter(ctx context.Context, vendorID uuid.UUID, afterTime time.Time, afterID uuid.UUID) ([]*Connection, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT vp.id,
+		       CASE WHEN vp.vendor_a_id = $1 THEN vp.vendor_b_id ELSE vp.vendor_a_id END AS partner_id,
+		       COALESCE(v.business_name, ''),
+		       vp.partnership_type, vp.status,
+		       (SELECT COUNT(*) FROM referrals r WHERE r.source_vendor_id = $1 AND r.dest_vendor_id = v.id),
+		       (SELECT COUNT(*) FROM referrals r WHERE r.dest_vendor_id = $1 AND r.source_vendor_id = v.id),
+		       vp.created_at, vp.activated_at
+		FROM vendor_partnerships vp
+		LEFT JOIN vendors v ON v.id = CASE WHEN vp.vendor_a_id = $1 THEN vp.vendor_b_id ELSE vp.vendor_a_id END
+		WHERE (vp.vendor_a_id = $1 OR vp.vendor_b_id = $1)
+		  AND (vp.created_at, vp.id) > ($2, $3)
+		ORDER BY vp.created_at, vp.id
+		LIMIT $4
+	`, vendorID, afterTime, afterID, exportBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var connections []*Connection
+	for rows.Next() {
+		var c Connection
+		if err := rows.Scan(
+			&c.PartnershipID, &c.PartnerVendorID, &c.PartnerBusinessName,
+			&c.PartnershipType, &c.Status, &c.ReferralsSent, &c.ReferralsReceived,
+			&c.ConnectedAt, &c.ActivatedAt,
+		); err != nil {
+			return nil, err
+		}
+		connections = append(connections, &c)
+	}
+	return connections, rows.Err()
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatInt64Ptr(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+func formatFloatPtr(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
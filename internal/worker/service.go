@@ -69,6 +69,7 @@ const (
 	JobMatchPartners      JobType = "match_partners"
 	JobProcessReferrals   JobType = "process_referrals"
 	JobUpdateVendorRanks  JobType = "update_vendor_ranks"
+	JobCheckSLABreaches   JobType = "check_sla_breaches"
 )
 
 type JobStatus string
@@ -0,0 +1,298 @@
+// =============================================================================
+// AVAILABILITY PACKAGE
+// Decides whether a vendor can take a booking, against their working hours,
+// the buffer they keep between jobs and how many bookings they run at once
+// =============================================================================
+
+package availability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rescheduling errors
+var (
+	ErrInsufficientNotice = errors.New("too close to the booking to reschedule")
+	ErrUnavailable        = errors.New("vendor is not available then")
+)
+
+// Slot search limits for unavailable requests
+const (
+	MaxSlots   = 3
+	SearchDays = 7
+	SlotStep   = 30 * time.Minute
+)
+
+// Window is the part of a day a vendor works, as offsets from midnight
+type Window struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// WorkingHours are a vendor's hours per weekday. A weekday without hours is
+// a day off; a vendor with no hours at all is always open.
+type WorkingHours map[time.Weekday]Window
+
+// ParseWorkingHours reads hours stored as
+// {"monday": {"open": "09:00", "close": "17:00"}, ...}
+func ParseWorkingHours(data []byte) (WorkingHours, error) {
+	hours := WorkingHours{}
+	if len(data) == 0 || string(data) == "null" {
+		return hours, nil
+	}
+
+	var days map[string]struct {
+		Open  string `json:"open"`
+		Close string `json:"close"`
+	}
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, fmt.Errorf("invalid working hours: %w", err)
+	}
+	for name, day := range days {
+		weekday, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid working hours: unknown day %q", name)
+		}
+		open, err := clockTime(day.Open)
+		if err != nil {
+			return nil, err
+		}
+		closing, err := clockTime(day.Close)
+		if err != nil {
+			return nil, err
+		}
+		if closing <= open {
+			return nil, fmt.Errorf("invalid working hours: %s closes before it opens", name)
+		}
+		hours[weekday] = Window{Open: open, Close: closing}
+	}
+	return hours, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// clockTime parses "15:04" into an offset from midnight; "24:00" is the end
+// of the day
+func clockTime(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid working hours: time %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Interval is a span of time, end exclusive
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+func (i Interval) overlaps(other Interval) bool {
+	return i.Start.Before(other.End) && other.Start.Before(i.End)
+}
+
+// Vendor is how a vendor takes bookings
+type Vendor struct {
+	MaxConcurrent int           // bookings at once; less than 1 means 1
+	Buffer        time.Duration // kept free before and after each booking
+	Hours         WorkingHours
+	Location      *time.Location // the hours are local to; UTC when nil
+	MinNotice     time.Duration  // needed before a booking is moved
+}
+
+func (v Vendor) location() *time.Location {
+	if v.Location == nil {
+		return time.UTC
+	}
+	return v.Location
+}
+
+func (v Vendor) capacity() int {
+	if v.MaxConcurrent < 1 {
+		return 1
+	}
+	return v.MaxConcurrent
+}
+
+// day returns the vendor's working window on the day t falls on
+func (v Vendor) day(t time.Time) (Interval, bool) {
+	local := t.In(v.location())
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, v.location())
+	if len(v.Hours) == 0 {
+		return Interval{Start: midnight, End: midnight.AddDate(0, 0, 1)}, true
+	}
+	window, ok := v.Hours[local.Weekday()]
+	if !ok {
+		return Interval{}, false
+	}
+	return Interval{Start: midnight.Add(window.Open), End: midnight.Add(window.Close)}, true
+}
+
+// Reason is why a request is unavailable
+type Reason string
+
+const (
+	ReasonOutsideHours Reason = "outside_working_hours"
+	ReasonFullyBooked  Reason = "fully_booked"
+)
+
+// Result is whether a vendor can take a requested booking
+type Result struct {
+	Available bool     `json:"available"`
+	Reason    Reason   `json:"reason,omitempty"`
+	Requested Interval `json:"requested"`
+	// Remaining is how many more bookings the vendor could take at the
+	// busiest point of the request, counting the requested one
+	Remaining int `json:"remaining"`
+	// Slots are open times of the same length, offered when unavailable
+	Slots []Interval `json:"slots,omitempty"`
+}
+
+// wholeDay narrows a request for a whole day, midnight to midnight in any
+// timezone, to the vendor's working hours on that date
+func (v Vendor) wholeDay(request Interval) Interval {
+	start := request.Start
+	if start.Hour() != 0 || start.Minute() != 0 || start.Second() != 0 || !request.End.Equal(start.AddDate(0, 0, 1)) {
+		return request
+	}
+	midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, v.location())
+	if day, ok := v.day(midnight); ok {
+		return day
+	}
+	return Interval{Start: midnight, End: midnight.AddDate(0, 0, 1)}
+}
+
+// SearchWindow is the span Check looks at for request, including the days
+// it searches for other slots and the vendor's buffer. Bookings outside it
+// do not affect the result.
+func SearchWindow(vendor Vendor, request Interval) Interval {
+	request = vendor.wholeDay(request)
+	local := request.Start.In(vendor.location())
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, vendor.location())
+	end := midnight.AddDate(0, 0, SearchDays+1)
+	if request.End.After(end) {
+		end = request.End
+	}
+	return Interval{Start: midnight.Add(-vendor.Buffer), End: end.Add(vendor.Buffer)}
+}
+
+// Check decides whether vendor, with booked already taken, can take a
+// booking for request. A request for a whole day is narrowed to that day's
+// working hours. When unavailable, up to MaxSlots open slots of the same
+// length are offered from the request's day onward.
+func Check(vendor Vendor, booked []Interval, request Interval) Result {
+	request = vendor.wholeDay(request)
+	result := check(vendor, booked, request)
+	if result.Available {
+		return result
+	}
+
+	length := request.End.Sub(request.Start)
+	if length <= 0 {
+		return result
+	}
+	first := request.Start.In(vendor.location())
+	for d := 0; d <= SearchDays && len(result.Slots) < MaxSlots; d++ {
+		day, ok := vendor.day(first.AddDate(0, 0, d))
+		if !ok {
+			continue
+		}
+		for start := day.Start; !start.Add(length).After(day.End) && len(result.Slots) < MaxSlots; start = start.Add(SlotStep) {
+			slot := Interval{Start: start, End: start.Add(length)}
+			if slot.Start.Equal(request.Start) {
+				continue
+			}
+			if check(vendor, booked, slot).Available {
+				result.Slots = append(result.Slots, slot)
+			}
+		}
+	}
+	return result
+}
+
+func check(vendor Vendor, booked []Interval, request Interval) Result {
+	result := Result{Requested: request}
+	day, ok := vendor.day(request.Start)
+	if !ok || !request.End.After(request.Start) || request.Start.Before(day.Start) || request.End.After(day.End) {
+		result.Reason = ReasonOutsideHours
+		return result
+	}
+
+	result.Remaining = vendor.capacity() - peak(booked, request, vendor.Buffer)
+	if result.Remaining < 1 {
+		result.Remaining = 0
+		result.Reason = ReasonFullyBooked
+		return result
+	}
+	result.Available = true
+	return result
+}
+
+// peak is the most bookings, each widened by buffer, running at once during
+// request
+func peak(booked []Interval, request Interval, buffer time.Duration) int {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+	var edges []edge
+	for _, b := range booked {
+		widened := Interval{Start: b.Start.Add(-buffer), End: b.End.Add(buffer)}
+		if !widened.overlaps(request) {
+			continue
+		}
+		// Only overlaps within the request count
+		if widened.Start.Before(request.Start) {
+			widened.Start = request.Start
+		}
+		edges = append(edges, edge{widened.Start, 1}, edge{widened.End, -1})
+	}
+	// Ends sort before starts at the same instant: back-to-back bookings do
+	// not overlap
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	running, most := 0, 0
+	for _, e := range edges {
+		running += e.delta
+		if running > most {
+			most = running
+		}
+	}
+	return most
+}
+
+// Reschedule checks moving a booking from one interval to another at now.
+// The booking cannot be moved once it is within the vendor's MinNotice, nor
+// moved to a time within it, and the vendor must be available at the new
+// time. booked must not include the booking being moved.
+func Reschedule(vendor Vendor, booked []Interval, from, to Interval, now time.Time) (Result, error) {
+	if from.Start.Sub(now) < vendor.MinNotice || to.Start.Sub(now) < vendor.MinNotice {
+		return Result{Requested: to}, fmt.Errorf("%w: %s notice required", ErrInsufficientNotice, vendor.MinNotice)
+	}
+	result := Check(vendor, booked, to)
+	if !result.Available {
+		return result, fmt.Errorf("%w: %s", ErrUnavailable, result.Reason)
+	}
+	return result, nil
+}
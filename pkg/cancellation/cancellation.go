@@ -0,0 +1,100 @@
+// =============================================================================
+// CANCELLATION PACKAGE
+// Works out how much of a cancelled booking is refunded, from the vendor's
+// or service's policy and how long before the booking it was cancelled
+// =============================================================================
+
+package cancellation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Who cancelled a booking
+const (
+	ActorCustomer = "customer"
+	ActorVendor   = "vendor"
+	ActorAdmin    = "admin"
+)
+
+// Tier refunds RefundPercent of what was paid when a booking is cancelled at
+// least MinNotice before it starts
+type Tier struct {
+	MinNotice     time.Duration `json:"-"`
+	RefundPercent float64       `json:"refund_percent"`
+}
+
+// Policy is a vendor's or service's cancellation terms. Cancelling with less
+// notice than every tier refunds nothing.
+type Policy struct {
+	Tiers []Tier `json:"tiers"`
+}
+
+// DefaultPolicy refunds in full a week or more out, half until the day
+// before, and nothing on the day
+var DefaultPolicy = Policy{Tiers: []Tier{
+	{MinNotice: 7 * 24 * time.Hour, RefundPercent: 100},
+	{MinNotice: 24 * time.Hour, RefundPercent: 50},
+}}
+
+// ParsePolicy reads a policy stored as
+// {"tiers": [{"min_notice_hours": 168, "refund_percent": 100}, ...]}.
+// Empty data is the DefaultPolicy.
+func ParsePolicy(data []byte) (Policy, error) {
+	if len(data) == 0 || string(data) == "null" || string(data) == "{}" {
+		return DefaultPolicy, nil
+	}
+
+	var stored struct {
+		Tiers []struct {
+			MinNoticeHours float64 `json:"min_notice_hours"`
+			RefundPercent  float64 `json:"refund_percent"`
+		} `json:"tiers"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return Policy{}, fmt.Errorf("invalid cancellation policy: %w", err)
+	}
+	policy := Policy{}
+	for _, t := range stored.Tiers {
+		if t.MinNoticeHours < 0 || t.RefundPercent < 0 || t.RefundPercent > 100 {
+			return Policy{}, fmt.Errorf("invalid cancellation policy: tier %gh at %g%%", t.MinNoticeHours, t.RefundPercent)
+		}
+		policy.Tiers = append(policy.Tiers, Tier{
+			MinNotice:     time.Duration(t.MinNoticeHours * float64(time.Hour)),
+			RefundPercent: t.RefundPercent,
+		})
+	}
+	return policy, nil
+}
+
+// Quote is what a cancellation refunds
+type Quote struct {
+	Notice        time.Duration `json:"-"`
+	RefundPercent float64       `json:"refund_percent"`
+	RefundAmount  float64       `json:"refund_amount"`
+}
+
+// Refund quotes the refund of paid for a booking starting at start that actor
+// cancels at cancelledAt. The tier with the most notice that the
+// cancellation meets applies. Vendors and admins cancelling refund in full.
+func (p Policy) Refund(paid float64, start, cancelledAt time.Time, actor string) Quote {
+	quote := Quote{Notice: start.Sub(cancelledAt)}
+	if actor == ActorVendor || actor == ActorAdmin {
+		quote.RefundPercent = 100
+	} else {
+		tiers := append([]Tier(nil), p.Tiers...)
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinNotice > tiers[j].MinNotice })
+		for _, tier := range tiers {
+			if quote.Notice >= tier.MinNotice {
+				quote.RefundPercent = tier.RefundPercent
+				break
+			}
+		}
+	}
+	quote.RefundAmount = math.Round(paid*quote.RefundPercent) / 100
+	return quote
+}
@@ -0,0 +1,156 @@
+// =============================================================================
+// DISCOUNT PACKAGE
+// Resolves competing discounts (bundle, subscription, promo) into the ones
+// that actually apply, under an explicit stacking rule
+// =============================================================================
+
+package discount
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Source identifies where a discount comes from
+type Source string
+
+const (
+	SourceBundle       Source = "bundle"
+	SourceSubscription Source = "subscription"
+	SourcePromo        Source = "promo"
+)
+
+// Discount is a reduction a customer qualifies for. Percent is taken off the
+// subtotal and Amount is a fixed reduction; a discount may carry both.
+type Discount struct {
+	Source  Source  `json:"source"`
+	Code    string  `json:"code,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+	Amount  float64 `json:"amount,omitempty"`
+}
+
+// value is what the discount is worth on its own against subtotal
+func (d Discount) value(subtotal float64) float64 {
+	v := subtotal*d.Percent/100 + d.Amount
+	if v > subtotal {
+		v = subtotal
+	}
+	return v
+}
+
+func (d Discount) label() string {
+	name := string(d.Source)
+	if d.Code != "" {
+		name += " " + d.Code
+	}
+	switch {
+	case d.Percent > 0 && d.Amount > 0:
+		return fmt.Sprintf("%s %g%% + %g", name, d.Percent, d.Amount)
+	case d.Percent > 0:
+		return fmt.Sprintf("%s %g%%", name, d.Percent)
+	default:
+		return fmt.Sprintf("%s %g", name, d.Amount)
+	}
+}
+
+// Rule is how qualifying discounts combine
+type Rule string
+
+const (
+	// RuleBestOf applies only the single most valuable discount
+	RuleBestOf Rule = "best_of"
+	// RuleAdditive adds discounts together, most valuable first, until the
+	// policy's cap is reached
+	RuleAdditive Rule = "additive"
+)
+
+// Policy is the stacking rule for a pricing context
+type Policy struct {
+	Rule Rule
+	// MaxPercent caps the combined discount as a percent of the subtotal;
+	// zero only stops the discount exceeding the subtotal
+	MaxPercent float64
+}
+
+// DefaultPolicy lets a bundle, a subscription and a promo stack, but never
+// past 30% off
+var DefaultPolicy = Policy{Rule: RuleAdditive, MaxPercent: 30}
+
+// Applied is a discount that counted towards the total
+type Applied struct {
+	Discount
+	AmountOff float64 `json:"amount_off"`
+	Capped    bool    `json:"capped,omitempty"` // reduced to stay within the cap
+}
+
+// Result is the outcome of resolving discounts against a subtotal
+type Result struct {
+	Subtotal       float64    `json:"subtotal"`
+	Applied        []Applied  `json:"applied"`
+	Skipped        []Discount `json:"skipped,omitempty"` // lost out under the stacking rule
+	TotalDiscount  float64    `json:"total_discount"`
+	EffectiveTotal float64    `json:"effective_total"`
+}
+
+// Reason describes the applied discounts, e.g. "bundle 10% + promo EASTER 5%"
+func (r Result) Reason() string {
+	labels := make([]string, 0, len(r.Applied))
+	for _, a := range r.Applied {
+		labels = append(labels, a.label())
+	}
+	return strings.Join(labels, " + ")
+}
+
+// Resolve applies the policy to the discounts a customer qualifies for.
+// Discounts worth nothing are ignored. Ties in value keep input order, so
+// callers list discounts in order of preference.
+func Resolve(subtotal float64, discounts []Discount, policy Policy) Result {
+	result := Result{Subtotal: subtotal, EffectiveTotal: subtotal}
+	if subtotal <= 0 {
+		return result
+	}
+
+	type candidate struct {
+		discount Discount
+		value    float64
+	}
+	var candidates []candidate
+	for _, d := range discounts {
+		if v := d.value(subtotal); v > 0 {
+			candidates = append(candidates, candidate{discount: d, value: v})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].value > candidates[j].value
+	})
+
+	limit := subtotal
+	if policy.MaxPercent > 0 && subtotal*policy.MaxPercent/100 < limit {
+		limit = subtotal * policy.MaxPercent / 100
+	}
+
+	for i, c := range candidates {
+		remaining := limit - result.TotalDiscount
+		if remaining <= 0 || (policy.Rule == RuleBestOf && i > 0) {
+			result.Skipped = append(result.Skipped, c.discount)
+			continue
+		}
+		applied := Applied{Discount: c.discount, AmountOff: c.value}
+		if applied.AmountOff > remaining {
+			applied.AmountOff = remaining
+			applied.Capped = true
+		}
+		applied.AmountOff = roundMoney(applied.AmountOff)
+		result.Applied = append(result.Applied, applied)
+		result.TotalDiscount = roundMoney(result.TotalDiscount + applied.AmountOff)
+	}
+
+	result.EffectiveTotal = roundMoney(subtotal - result.TotalDiscount)
+	return result
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
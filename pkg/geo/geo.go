@@ -0,0 +1,376 @@
+// =============================================================================
+// GEO PACKAGE
+// Distances between coordinates and finding vendors near a point, through
+// PostGIS where the database has it and a bounding box + Haversine otherwise
+// =============================================================================
+
+package geo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EarthRadiusKM is the mean radius of the Earth
+const EarthRadiusKM = 6371.0
+
+// MaxNearbyVendors caps how many vendors NearbyVendors returns
+const MaxNearbyVendors = 50
+
+// GeoPoint is a WGS84 coordinate in degrees
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Distance returns the great-circle distance between a and b in km, by the
+// Haversine formula
+func Distance(a, b GeoPoint) float64 {
+	dLat := radians(b.Latitude - a.Latitude)
+	dLng := radians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(a.Latitude))*math.Cos(radians(b.Latitude))*
+			math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return EarthRadiusKM * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// BoundingBox is a latitude/longitude rectangle. A box crossing the
+// antimeridian has MinLng greater than MaxLng.
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MaxLat float64 `json:"max_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// BoundingBoxAround returns the smallest box holding every point within
+// radiusKM of center, so it can prefilter candidates before the exact
+// distance is checked
+func BoundingBoxAround(center GeoPoint, radiusKM float64) BoundingBox {
+	angular := math.Max(radiusKM, 0) / EarthRadiusKM
+	latDelta := degrees(angular)
+	box := BoundingBox{
+		MinLat: center.Latitude - latDelta,
+		MaxLat: center.Latitude + latDelta,
+		MinLng: -180,
+		MaxLng: 180,
+	}
+
+	// A circle reaching a pole covers every longitude
+	if box.MinLat <= -90 || box.MaxLat >= 90 {
+		box.MinLat = math.Max(box.MinLat, -90)
+		box.MaxLat = math.Min(box.MaxLat, 90)
+		return box
+	}
+	ratio := math.Sin(angular) / math.Cos(radians(center.Latitude))
+	if ratio >= 1 {
+		return box
+	}
+
+	lngDelta := degrees(math.Asin(ratio))
+	box.MinLng = center.Longitude - lngDelta
+	box.MaxLng = center.Longitude + lngDelta
+	if box.MaxLng-box.MinLng >= 360 {
+		box.MinLng, box.MaxLng = -180, 180
+		return box
+	}
+	if box.MinLng < -180 {
+		box.MinLng += 360
+	}
+	if box.MaxLng > 180 {
+		box.MaxLng -= 360
+	}
+	return box
+}
+
+// CrossesAntimeridian reports whether the box wraps from 180 to -180
+func (b BoundingBox) CrossesAntimeridian() bool {
+	return b.MinLng > b.MaxLng
+}
+
+// Contains reports whether p lies inside the box
+func (b BoundingBox) Contains(p GeoPoint) bool {
+	if p.Latitude < b.MinLat || p.Latitude > b.MaxLat {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return p.Longitude >= b.MinLng || p.Longitude <= b.MaxLng
+	}
+	return p.Longitude >= b.MinLng && p.Longitude <= b.MaxLng
+}
+
+// NearbyVendor is a vendor found near a point
+type NearbyVendor struct {
+	VendorID   uuid.UUID `json:"vendor_id"`
+	Name       string    `json:"name"`
+	Location   GeoPoint  `json:"location"`
+	DistanceKM float64   `json:"distance_km"`
+}
+
+// Within keeps the vendors inside the bounding box and radius of center,
+// nearest first, with their distances filled in
+func Within(vendors []NearbyVendor, center GeoPoint, radiusKM float64) []NearbyVendor {
+	box := BoundingBoxAround(center, radiusKM)
+	var nearby []NearbyVendor
+	for _, v := range vendors {
+		if !box.Contains(v.Location) {
+			continue
+		}
+		v.DistanceKM = Distance(center, v.Location)
+		if v.DistanceKM <= radiusKM {
+			nearby = append(nearby, v)
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].DistanceKM < nearby[j].DistanceKM })
+	return nearby
+}
+
+// =============================================================================
+// NEARBY VENDORS
+// =============================================================================
+
+// Locator finds active vendors within radiusKM of a point. An empty category
+// matches every vendor.
+type Locator interface {
+	NearbyVendors(ctx context.Context, point GeoPoint, radiusKM float64, category string) ([]NearbyVendor, error)
+}
+
+// categoryFilter matches vendors offering an available service in the
+// category given by $n, by slug or name
+func categoryFilter(n int) string {
+	return fmt.Sprintf(`($%[1]d = '' OR EXISTS (
+			SELECT 1 FROM services s
+			JOIN service_categories sc ON sc.id = s.category_id
+			WHERE s.vendor_id = v.id
+			  AND s.is_available = TRUE
+			  AND (LOWER(sc.slug) = LOWER($%[1]d) OR LOWER(sc.name) = LOWER($%[1]d))
+		))`, n)
+}
+
+// PostGISLocator searches vendors' service_location with ST_DWithin
+type PostGISLocator struct {
+	db *pgxpool.Pool
+}
+
+// NewPostGISLocator creates a locator for databases with PostGIS
+func NewPostGISLocator(db *pgxpool.Pool) *PostGISLocator {
+	return &PostGISLocator{db: db}
+}
+
+// NearbyVendors finds vendors within radiusKM, nearest first
+func (l *PostGISLocator) NearbyVendors(ctx context.Context, point GeoPoint, radiusKM float64, category string) ([]NearbyVendor, error) {
+	rows, err := l.db.Query(ctx, `
+		SELECT v.id, v.business_name,
+		       ST_Y(v.service_location::geometry), ST_X(v.service_location::geometry),
+		       ST_Distance(v.service_location, ST_MakePoint($1, $2)::geography) / 1000 AS distance_km
+		FROM vendors v
+		WHERE v.is_active = TRUE
+		  AND v.service_location IS NOT NULL
+		  AND ST_DWithin(v.service_location, ST_MakePoint($1, $2)::geography, $3)
+		  AND `+categoryFilter(4)+`
+		ORDER BY distance_km ASC
+		LIMIT $5
+	`, point.Longitude, point.Latitude, radiusKM*1000, category, MaxNearbyVendors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearby vendors: %w", err)
+	}
+	defer rows.Close()
+
+	var vendors []NearbyVendor
+	for rows.Next() {
+		var v NearbyVendor
+		if err := rows.Scan(&v.VendorID, &v.Name, &v.Location.Latitude, &v.Location.Longitude, &v.DistanceKM); err != nil {
+			return nil, err
+		}
+		vendors = append(vendors, v)
+	}
+	return vendors, rows.Err()
+}
+
+// CandidateStore lists vendors whose location falls inside a bounding box
+type CandidateStore interface {
+	VendorsInBox(ctx context.Context, box BoundingBox, category string) ([]NearbyVendor, error)
+}
+
+// FallbackLocator prefilters candidates to the radius's bounding box and
+// checks the exact distance with Haversine, for databases without PostGIS
+type FallbackLocator struct {
+	candidates CandidateStore
+}
+
+// NewFallbackLocator creates a locator over candidates
+func NewFallbackLocator(candidates CandidateStore) *FallbackLocator {
+	return &FallbackLocator{candidates: candidates}
+}
+
+// NearbyVendors finds vendors within radiusKM, nearest first
+func (l *FallbackLocator) NearbyVendors(ctx context.Context, point GeoPoint, radiusKM float64, category string) ([]NearbyVendor, error) {
+	candidates, err := l.candidates.VendorsInBox(ctx, BoundingBoxAround(point, radiusKM), category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearby vendors: %w", err)
+	}
+	nearby := Within(candidates, point, radiusKM)
+	if len(nearby) > MaxNearbyVendors {
+		nearby = nearby[:MaxNearbyVendors]
+	}
+	return nearby, nil
+}
+
+// PostgresCandidates reads vendors' plain service_latitude and
+// service_longitude columns
+type PostgresCandidates struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresCandidates creates a candidate store backed by Postgres
+func NewPostgresCandidates(db *pgxpool.Pool) *PostgresCandidates {
+	return &PostgresCandidates{db: db}
+}
+
+// VendorsInBox lists active vendors located inside box
+func (c *PostgresCandidates) VendorsInBox(ctx context.Context, box BoundingBox, category string) ([]NearbyVendor, error) {
+	longitude := `v.service_longitude BETWEEN $3 AND $4`
+	if box.CrossesAntimeridian() {
+		longitude = `(v.service_longitude >= $3 OR v.service_longitude <= $4)`
+	}
+	rows, err := c.db.Query(ctx, `
+		SELECT v.id, v.business_name, v.service_latitude::float8, v.service_longitude::float8
+		FROM vendors v
+		WHERE v.is_active = TRUE
+		  AND v.service_latitude BETWEEN $1 AND $2
+		  AND `+longitude+`
+		  AND `+categoryFilter(5)+`
+	`, box.MinLat, box.MaxLat, box.MinLng, box.MaxLng, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vendors []NearbyVendor
+	for rows.Next() {
+		var v NearbyVendor
+		if err := rows.Scan(&v.VendorID, &v.Name, &v.Location.Latitude, &v.Location.Longitude); err != nil {
+			return nil, err
+		}
+		vendors = append(vendors, v)
+	}
+	return vendors, rows.Err()
+}
+
+// MemoryCandidates keeps vendor locations in memory, for tests
+type MemoryCandidates struct {
+	mu         sync.RWMutex
+	vendors    []NearbyVendor
+	categories map[uuid.UUID][]string
+}
+
+// NewMemoryCandidates creates an empty in-memory candidate store
+func NewMemoryCandidates() *MemoryCandidates {
+	return &MemoryCandidates{categories: make(map[uuid.UUID][]string)}
+}
+
+// AddVendor adds a vendor offering services in categories
+func (c *MemoryCandidates) AddVendor(vendor NearbyVendor, categories ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vendors = append(c.vendors, vendor)
+	c.categories[vendor.VendorID] = categories
+}
+
+// VendorsInBox lists vendors located inside box
+func (c *MemoryCandidates) VendorsInBox(ctx context.Context, box BoundingBox, category string) ([]NearbyVendor, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var vendors []NearbyVendor
+	for _, v := range c.vendors {
+		if box.Contains(v.Location) && c.offers(v.VendorID, category) {
+			vendors = append(vendors, v)
+		}
+	}
+	return vendors, nil
+}
+
+func (c *MemoryCandidates) offers(vendorID uuid.UUID, category string) bool {
+	if category == "" {
+		return true
+	}
+	for _, offered := range c.categories[vendorID] {
+		if strings.EqualFold(offered, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// SERVICE
+// =============================================================================
+
+// HasPostGIS reports whether the database has the PostGIS extension
+func HasPostGIS(ctx context.Context, db *pgxpool.Pool) bool {
+	var installed bool
+	err := db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')`).Scan(&installed)
+	return err == nil && installed
+}
+
+// Service is the platform's one place for distances and nearby vendors
+type Service struct {
+	db *pgxpool.Pool
+
+	once    sync.Once
+	locator Locator
+}
+
+// NewService creates a geo service. The locator is chosen on first use:
+// PostGIS when the database has it, the bounding-box fallback otherwise.
+func NewService(db *pgxpool.Pool) *Service {
+	return &Service{db: db}
+}
+
+// SetLocator replaces how nearby vendors are found
+func (s *Service) SetLocator(locator Locator) {
+	s.once.Do(func() {})
+	s.locator = locator
+}
+
+// Distance returns the great-circle distance between a and b in km
+func (s *Service) Distance(a, b GeoPoint) float64 {
+	return Distance(a, b)
+}
+
+// NearbyVendors finds active vendors within radiusKM of point offering
+// category, nearest first
+func (s *Service) NearbyVendors(ctx context.Context, point GeoPoint, radiusKM float64, category string) ([]NearbyVendor, error) {
+	s.once.Do(func() {
+		if s.db == nil {
+			return
+		}
+		if HasPostGIS(ctx, s.db) {
+			s.locator = NewPostGISLocator(s.db)
+		} else {
+			s.locator = NewFallbackLocator(NewPostgresCandidates(s.db))
+		}
+	})
+	if s.locator == nil {
+		return nil, fmt.Errorf("geo: no vendor locator configured")
+	}
+	return s.locator.NearbyVendors(ctx, point, radiusKM, category)
+}
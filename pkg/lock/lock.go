@@ -0,0 +1,55 @@
+// =============================================================================
+// LOCK PACKAGE
+// Redis-backed distributed locks for work that must run on one instance only
+// =============================================================================
+
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Locker takes short-lived named locks shared by every server instance
+type Locker interface {
+	// TryLock takes key for ttl without waiting and reports whether it was acquired
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Unlock releases key if this locker still holds it
+	Unlock(ctx context.Context, key string) error
+}
+
+// unlockScript deletes the key only while it still carries our token, so a
+// lock that expired and was taken by another instance is left alone
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker implements Locker with SET NX PX
+type RedisLocker struct {
+	client *redis.Client
+	token  string
+}
+
+// NewRedisLocker creates a locker identified by a token unique to this instance
+func NewRedisLocker(client *redis.Client) *RedisLocker {
+	return &RedisLocker{
+		client: client,
+		token:  uuid.New().String(),
+	}
+}
+
+// TryLock takes key for ttl if no other holder has it
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return l.client.SetNX(ctx, key, l.token, ttl).Result()
+}
+
+// Unlock releases key before its TTL if this instance still holds it
+func (l *RedisLocker) Unlock(ctx context.Context, key string) error {
+	return unlockScript.Run(ctx, l.client, []string{key}, l.token).Err()
+}
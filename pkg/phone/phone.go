@@ -0,0 +1,87 @@
+// =============================================================================
+// PHONE PACKAGE
+// E.164 normalization and validation for phone numbers captured at ingestion
+// =============================================================================
+
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultCountryCode is applied to numbers written in local format
+const DefaultCountryCode = "234"
+
+// ErrInvalidPhone is returned for input that cannot be a phone number
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// nationalNumberLength is the subscriber number length (without the trunk
+// prefix) for countries where it is fixed
+var nationalNumberLength = map[string]int{
+	"234": 10, // Nigeria
+	"233": 9,  // Ghana
+	"254": 9,  // Kenya
+	"27":  9,  // South Africa
+	"44":  10, // United Kingdom
+	"1":   10, // North America
+}
+
+// Normalize converts a phone number to E.164 (e.g. "+2348012345678"),
+// treating local-format numbers as Nigerian
+func Normalize(raw string) (string, error) {
+	return NormalizeWithCountry(raw, DefaultCountryCode)
+}
+
+// NormalizeWithCountry converts a phone number to E.164, treating numbers
+// without an international prefix as belonging to countryCode
+func NormalizeWithCountry(raw, countryCode string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", ErrInvalidPhone
+	}
+
+	international := false
+	if strings.HasPrefix(s, "+") {
+		international = true
+		s = s[1:]
+	}
+
+	var digits strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+			// common separators
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	number := digits.String()
+
+	switch {
+	case international:
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = countryCode + number[1:]
+	case strings.HasPrefix(number, countryCode) && len(number) > len(countryCode)+nationalNumberLength[countryCode]-1:
+		// already carries the country code, just missing the "+"
+	default:
+		number = countryCode + number
+	}
+
+	// E.164 allows at most 15 digits and country codes never start with 0
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+
+	for code, length := range nationalNumberLength {
+		if strings.HasPrefix(number, code) && len(number)-len(code) != length {
+			return "", ErrInvalidPhone
+		}
+	}
+
+	return "+" + number, nil
+}
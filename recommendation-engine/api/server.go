@@ -121,6 +121,9 @@ type RecommendationAPIRequest struct {
 	Limit            int      `json:"limit,omitempty"`
 	ExcludeIDs       []string `json:"exclude_ids,omitempty"`
 	DiversityFactor  float64  `json:"diversity_factor,omitempty"`
+	Blend            *recommendation.BlendWeights `json:"blend,omitempty"`
+	Freshness        *recommendation.FreshnessDecay `json:"freshness,omitempty"`
+	Explain          bool     `json:"explain,omitempty"`
 }
 
 // RecommendationAPIResponse is the API response
@@ -146,6 +149,8 @@ type RecommendationItem struct {
 	Score           float64                `json:"score"`
 	Position        int                    `json:"position"`
 	Explanation     string                 `json:"explanation"`
+	Reasons         []string               `json:"reasons,omitempty"`
+	ScoreBreakdown  map[string]float64     `json:"score_breakdown,omitempty"`
 	Entity          *EntityDetails         `json:"entity,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -202,6 +207,7 @@ type MetaResponse struct {
 	AlgorithmVersion string `json:"algorithm_version"`
 	ExperimentID     string `json:"experiment_id,omitempty"`
 	Variant          string `json:"variant,omitempty"`
+	Degraded         bool   `json:"degraded,omitempty"`
 }
 
 // FeedbackRequest is the request for feedback endpoints
@@ -246,7 +252,7 @@ func (s *Server) getRecommendations(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get recommendations
-	resp, err := s.engine.GetRecommendations(ctx, internalReq)
+	resp, err := s.engine.GetRecommendationsOrFallback(ctx, internalReq)
 	if err != nil {
 		s.logger.Error("failed to get recommendations", "error", err, "request_id", requestID)
 		s.errorResponse(w, http.StatusInternalServerError, "ENGINE_ERROR", "Failed to generate recommendations", "")
@@ -283,7 +289,7 @@ func (s *Server) getAdjacentRecommendations(w http.ResponseWriter, r *http.Reque
 	// Limit to adjacent only
 	internalReq.RequestedTypes = []recommendation.RecommendationType{recommendation.AdjacentService}
 
-	resp, err := s.engine.GetRecommendations(ctx, internalReq)
+	resp, err := s.engine.GetRecommendationsOrFallback(ctx, internalReq)
 	if err != nil {
 		s.logger.Error("failed to get adjacent recommendations", "error", err, "request_id", requestID)
 		s.errorResponse(w, http.StatusInternalServerError, "ENGINE_ERROR", "Failed to generate recommendations", "")
@@ -318,7 +324,7 @@ func (s *Server) getEventBasedRecommendations(w http.ResponseWriter, r *http.Req
 
 	internalReq.RequestedTypes = []recommendation.RecommendationType{recommendation.EventBasedSuggest}
 
-	resp, err := s.engine.GetRecommendations(ctx, internalReq)
+	resp, err := s.engine.GetRecommendationsOrFallback(ctx, internalReq)
 	if err != nil {
 		s.logger.Error("failed to get event-based recommendations", "error", err, "request_id", requestID)
 		s.errorResponse(w, http.StatusInternalServerError, "ENGINE_ERROR", "Failed to generate recommendations", "")
@@ -347,7 +353,7 @@ func (s *Server) getTrendingRecommendations(w http.ResponseWriter, r *http.Reque
 
 	internalReq.RequestedTypes = []recommendation.RecommendationType{recommendation.TrendingService}
 
-	resp, err := s.engine.GetRecommendations(ctx, internalReq)
+	resp, err := s.engine.GetRecommendationsOrFallback(ctx, internalReq)
 	if err != nil {
 		s.logger.Error("failed to get trending recommendations", "error", err, "request_id", requestID)
 		s.errorResponse(w, http.StatusInternalServerError, "ENGINE_ERROR", "Failed to generate recommendations", "")
@@ -385,7 +391,7 @@ func (s *Server) getPersonalizedRecommendations(w http.ResponseWriter, r *http.R
 		recommendation.PersonalizedPick,
 	}
 
-	resp, err := s.engine.GetRecommendations(ctx, internalReq)
+	resp, err := s.engine.GetRecommendationsOrFallback(ctx, internalReq)
 	if err != nil {
 		s.logger.Error("failed to get personalized recommendations", "error", err, "request_id", requestID)
 		s.errorResponse(w, http.StatusInternalServerError, "ENGINE_ERROR", "Failed to generate recommendations", "")
@@ -414,7 +420,7 @@ func (s *Server) getBundleRecommendations(w http.ResponseWriter, r *http.Request
 
 	internalReq.RequestedTypes = []recommendation.RecommendationType{recommendation.BundleSuggestion}
 
-	resp, err := s.engine.GetRecommendations(ctx, internalReq)
+	resp, err := s.engine.GetRecommendationsOrFallback(ctx, internalReq)
 	if err != nil {
 		s.logger.Error("failed to get bundle recommendations", "error", err, "request_id", requestID)
 		s.errorResponse(w, http.StatusInternalServerError, "ENGINE_ERROR", "Failed to generate recommendations", "")
@@ -513,6 +519,9 @@ func (s *Server) convertToInternalRequest(req *RecommendationAPIRequest) (*recom
 		Limit:           req.Limit,
 		DiversityFactor: req.DiversityFactor,
 		EventType:       req.EventType,
+		Blend:           req.Blend,
+		Freshness:       req.Freshness,
+		Explain:         req.Explain,
 	}
 
 	if req.UserID != "" {
@@ -601,14 +610,16 @@ func (s *Server) convertToAPIResponse(ctx context.Context, resp *recommendation.
 
 	for _, rec := range resp.Recommendations {
 		item := RecommendationItem{
-			ID:          rec.ID.String(),
-			Type:        string(rec.Type),
-			EntityType:  string(rec.EntityType),
-			EntityID:    rec.EntityID.String(),
-			Score:       rec.Score,
-			Position:    rec.Position,
-			Explanation: rec.ExplanationCopy,
-			Metadata:    rec.Metadata,
+			ID:             rec.ID.String(),
+			Type:           string(rec.Type),
+			EntityType:     string(rec.EntityType),
+			EntityID:       rec.EntityID.String(),
+			Score:          rec.Score,
+			Position:       rec.Position,
+			Explanation:    rec.ExplanationCopy,
+			Reasons:        rec.Reasons,
+			ScoreBreakdown: rec.ScoreBreakdown,
+			Metadata:       rec.Metadata,
 		}
 
 		// Enrich with entity details (would query database)
@@ -629,6 +640,7 @@ func (s *Server) convertToAPIResponse(ctx context.Context, resp *recommendation.
 			AlgorithmVersion: resp.AlgorithmVersion,
 			ExperimentID:     resp.ExperimentID.String(),
 			Variant:          resp.Variant,
+			Degraded:         resp.Degraded,
 		},
 	}
 }
@@ -5,16 +5,23 @@ package recommendation
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/tracing"
 )
 
 // =============================================================================
@@ -54,10 +61,16 @@ type Recommendation struct {
 	Score            float64            `json:"score"`
 	RelevanceScore   float64            `json:"relevance_score"`
 	DiversityScore   float64            `json:"diversity_score"`
+	CollaborativeScore float64          `json:"collaborative_score,omitempty"`
+	ContentScore     float64            `json:"content_score,omitempty"`
+	FreshnessFactor  float64            `json:"freshness_factor,omitempty"`
 	ExplanationCopy  string             `json:"explanation_copy"`
+	Reasons          []string           `json:"reasons,omitempty"`
+	ScoreBreakdown   map[string]float64 `json:"score_breakdown,omitempty"` // only when the request asks to Explain
 	Position         int                `json:"position"`
 	Metadata         map[string]any     `json:"metadata"`
 	SourceContext    *SourceContext     `json:"source_context,omitempty"`
+	Factors          *ScoreFactors      `json:"-"` // logged with the impression for explanations
 }
 
 // SourceContext provides context for why a recommendation was made
@@ -82,7 +95,11 @@ type RecommendationRequest struct {
 	RequestedTypes  []RecommendationType `json:"requested_types,omitempty"`
 	Limit           int                `json:"limit"`
 	ExcludeIDs      []uuid.UUID        `json:"exclude_ids,omitempty"`
-	DiversityFactor float64            `json:"diversity_factor"` // 0-1, higher = more diverse
+	DiversityFactor float64            `json:"diversity_factor"` // 0 = pure relevance, 1 = most diverse
+	Blend           *BlendWeights      `json:"blend,omitempty"`  // overrides Config.Blend
+	Freshness       *FreshnessDecay    `json:"freshness,omitempty"` // overrides Config.Freshness
+	Cursor          string             `json:"cursor,omitempty"`    // NextCursor from the previous page
+	Explain         bool               `json:"explain,omitempty"`   // include each item's ScoreBreakdown
 }
 
 // GeoPoint represents a geographic location
@@ -106,6 +123,8 @@ type RecommendationResponse struct {
 	ProcessingTimeMs int64           `json:"processing_time_ms"`
 	ExperimentID    uuid.UUID        `json:"experiment_id,omitempty"`
 	Variant         string           `json:"variant,omitempty"`
+	Degraded        bool             `json:"degraded,omitempty"` // served from the fallback, not the engine
+	NextCursor      string           `json:"next_cursor,omitempty"` // fetches the page after this one
 }
 
 // =============================================================================
@@ -124,6 +143,9 @@ type Engine struct {
 	scorer          *Scorer
 	ranker          *Ranker
 	diversifier     *Diversifier
+	candidatePool   *CandidatePool
+	popular         []TrendingItem // snapshot served when the engine is degraded
+	feedback        FeedbackStore
 	mu              sync.RWMutex
 }
 
@@ -131,7 +153,10 @@ type Engine struct {
 type Config struct {
 	// Caching
 	CacheTTL              time.Duration
+	CandidateCacheTTL     time.Duration // candidate pools per entity and event type; 0 disables
 	AdjacencyRefreshRate  time.Duration
+	FallbackCacheTTL      time.Duration // how long a good response is kept for degraded mode
+	FallbackPopularSize   int           // popular services kept in memory for degraded mode
 	
 	// Scoring weights
 	AdjacencyWeight       float64
@@ -144,6 +169,15 @@ type Config struct {
 	// Diversity
 	MinDiversityScore     float64
 	CategoryDiversityBonus float64
+	VendorCapWindow       int // leading results in which one vendor is capped
+	MaxPerVendor          int // most results one vendor may take in that window
+	
+	// Signal blending
+	Blend                 BlendWeights
+	BlendLookbackDays     int
+	
+	// Recency of positive vendor activity
+	Freshness             FreshnessDecay
 	
 	// Performance
 	MaxCandidates         int
@@ -159,7 +193,10 @@ type Config struct {
 func DefaultConfig() *Config {
 	return &Config{
 		CacheTTL:              5 * time.Minute,
+		CandidateCacheTTL:     15 * time.Minute,
 		AdjacencyRefreshRate:  1 * time.Hour,
+		FallbackCacheTTL:      1 * time.Hour,
+		FallbackPopularSize:   50,
 		AdjacencyWeight:       0.35,
 		CollaborativeWeight:   0.25,
 		TrendingWeight:        0.15,
@@ -168,6 +205,11 @@ func DefaultConfig() *Config {
 		RecencyWeight:         0.10,
 		MinDiversityScore:     0.3,
 		CategoryDiversityBonus: 0.1,
+		VendorCapWindow:       5,
+		MaxPerVendor:          2,
+		Blend:                 BlendWeights{Base: 0.6, Collaborative: 0.2, Content: 0.2},
+		BlendLookbackDays:     90,
+		Freshness:             FreshnessDecay{Enabled: true, BoostWindowDays: 30, MaxBoost: 0.1, HalfLifeDays: 180, Floor: 0.6},
 		MaxCandidates:         500,
 		ParallelScoring:       true,
 		ScoringWorkers:        4,
@@ -196,11 +238,14 @@ func NewEngine(db *pgxpool.Pool, cache *redis.Client, config *Config) (*Engine,
 	engine.scorer = NewScorer(config)
 	engine.ranker = NewRanker(config)
 	engine.diversifier = NewDiversifier(config)
+	engine.feedback = NewPostgresFeedbackStore(db)
+	engine.candidatePool = NewCandidatePool(cache, config.CandidateCacheTTL)
 	
 	// Load adjacency graph into memory
 	if err := engine.adjacencyGraph.Load(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to load adjacency graph: %w", err)
 	}
+	engine.refreshPopular(context.Background())
 	
 	// Start background refresh
 	go engine.backgroundRefresh()
@@ -209,17 +254,27 @@ func NewEngine(db *pgxpool.Pool, cache *redis.Client, config *Config) (*Engine,
 }
 
 // GetRecommendations is the main entry point for getting recommendations
-func (e *Engine) GetRecommendations(ctx context.Context, req *RecommendationRequest) (*RecommendationResponse, error) {
+func (e *Engine) GetRecommendations(ctx context.Context, req *RecommendationRequest) (resp *RecommendationResponse, err error) {
 	startTime := time.Now()
 	
+	ctx, span := tracing.Start(ctx, "recommendation.GetRecommendations")
+	defer func() { tracing.End(span, err) }()
+	
 	// Validate request
 	if err := e.validateRequest(req); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
+	span.SetAttributes(
+		attribute.String("user.id", req.UserID.String()),
+		attribute.String("session.id", req.SessionID.String()),
+		attribute.String("entity.id", req.CurrentEntityID.String()),
+		attribute.String("entity.type", string(req.CurrentEntityType)),
+		attribute.String("event.type", req.EventType),
+	)
 	
 	// Set defaults
 	if req.Limit == 0 {
-		req.Limit = 10
+		req.Limit = DefaultLimit
 	}
 	if req.DiversityFactor == 0 {
 		req.DiversityFactor = 0.3
@@ -240,9 +295,32 @@ func (e *Engine) GetRecommendations(ctx context.Context, req *RecommendationRequ
 	// Score candidates
 	scoredCandidates := e.scorer.ScoreAll(ctx, candidates, req, userCtx)
 	
+	// Blend in collaborative and content signals
+	scoredCandidates = e.blend(ctx, scoredCandidates, req, userCtx)
+	
+	// Favour vendors with recent positive activity
+	scoredCandidates = e.freshen(ctx, scoredCandidates, req)
+	
 	// Rank and diversify
+	_, rankSpan := tracing.Start(ctx, "recommendation.rank",
+		attribute.Int("candidates.count", len(scoredCandidates)),
+		attribute.Int("limit", req.Limit),
+	)
 	ranked := e.ranker.Rank(scoredCandidates)
-	diversified := e.diversifier.Diversify(ranked, req.Limit, req.DiversityFactor)
+	page, nextCursor, err := Paginate(ranked, req.Cursor, req.Limit)
+	if err != nil {
+		rankSpan.End()
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	diversified := e.diversifier.Arrange(page, req.DiversityFactor)
+	diversified = AttachBreakdowns(diversified, req.Explain)
+	rankSpan.SetAttributes(attribute.Int("results.count", len(diversified)))
+	rankSpan.End()
+	
+	span.SetAttributes(
+		attribute.Int("candidates.count", len(candidates)),
+		attribute.Int("results.count", len(diversified)),
+	)
 	
 	// Build response
 	response := &RecommendationResponse{
@@ -250,6 +328,7 @@ func (e *Engine) GetRecommendations(ctx context.Context, req *RecommendationRequ
 		TotalCandidates:   len(candidates),
 		AlgorithmVersion:  "v2.1.0",
 		ProcessingTimeMs:  time.Since(startTime).Milliseconds(),
+		NextCursor:        nextCursor,
 	}
 	
 	// Add experiment info if enabled
@@ -258,12 +337,243 @@ func (e *Engine) GetRecommendations(ctx context.Context, req *RecommendationRequ
 		response.Variant = e.config.DefaultVariant
 	}
 	
-	// Log recommendations for analytics (async)
+	// Log recommendations for analytics and keep them for degraded mode (async)
 	go e.logRecommendations(ctx, req, response)
+	go e.storeFallback(context.WithoutCancel(ctx), req, response)
 	
 	return response, nil
 }
 
+// =============================================================================
+// BATCH RECOMMENDATIONS
+// =============================================================================
+
+// MaxBatchSize caps the number of sub-requests accepted in a single batch
+const MaxBatchSize = 20
+
+// BatchResult holds the outcome of one sub-request in a batch. Exactly one of
+// Response or Error is set, so a failing sub-request never fails the batch.
+type BatchResult struct {
+	Index    int                     `json:"index"`
+	Response *RecommendationResponse `json:"response,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+}
+
+// RecommendFunc produces recommendations for a single request
+type RecommendFunc func(ctx context.Context, req *RecommendationRequest) (*RecommendationResponse, error)
+
+// GetBatchRecommendations runs several recommendation requests concurrently
+// under the deadline carried by ctx and returns one result per request.
+func (e *Engine) GetBatchRecommendations(ctx context.Context, reqs []*RecommendationRequest) ([]BatchResult, error) {
+	return RunBatch(ctx, reqs, e.GetRecommendations)
+}
+
+// RunBatch fans the requests out to fetch concurrently. Invalid sub-requests
+// are rejected up front, and any sub-request still running when ctx expires
+// is reported with the context error.
+func RunBatch(ctx context.Context, reqs []*RecommendationRequest, fetch RecommendFunc) (results []BatchResult, err error) {
+	ctx, span := tracing.Start(ctx, "recommendation.batch", attribute.Int("batch.size", len(reqs)))
+	defer func() {
+		failed := 0
+		for _, r := range results {
+			if r.Error != "" {
+				failed++
+			}
+		}
+		span.SetAttributes(attribute.Int("batch.failed", failed))
+		tracing.End(span, err)
+	}()
+	
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("at least one request is required")
+	}
+	if len(reqs) > MaxBatchSize {
+		return nil, fmt.Errorf("batch size must not exceed %d", MaxBatchSize)
+	}
+	
+	results = make([]BatchResult, len(reqs))
+	done := make(chan BatchResult, len(reqs))
+	pending := 0
+	
+	for i, req := range reqs {
+		results[i].Index = i
+		if err := ValidateRequest(req); err != nil {
+			results[i].Error = fmt.Sprintf("invalid request: %v", err)
+			continue
+		}
+		
+		pending++
+		go func(idx int, r *RecommendationRequest) {
+			resp, err := fetch(ctx, r)
+			result := BatchResult{Index: idx, Response: resp}
+			if err != nil {
+				result = BatchResult{Index: idx, Error: err.Error()}
+			}
+			done <- result
+		}(i, req)
+	}
+	
+	finished := make([]bool, len(reqs))
+	for pending > 0 {
+		select {
+		case result := <-done:
+			results[result.Index] = result
+			finished[result.Index] = true
+			pending--
+		case <-ctx.Done():
+			for i := range results {
+				if results[i].Error == "" && !finished[i] {
+					results[i].Error = ctx.Err().Error()
+				}
+			}
+			return results, nil
+		}
+	}
+	
+	return results, nil
+}
+
+// =============================================================================
+// DEGRADED MODE
+// =============================================================================
+
+// FallbackAlgorithmVersion marks responses built from the popularity fallback
+const FallbackAlgorithmVersion = "fallback-popular"
+
+// ErrNoFallback is returned when the engine fails and nothing is available
+// to serve in its place
+var ErrNoFallback = errors.New("no fallback recommendations available")
+
+// GetRecommendationsOrFallback serves recommendations from the engine and,
+// when the engine or its database fails, a degraded list instead of an error
+func (e *Engine) GetRecommendationsOrFallback(ctx context.Context, req *RecommendationRequest) (*RecommendationResponse, error) {
+	return WithFallback(e.GetRecommendations, e.fallbackRecommendations)(ctx, req)
+}
+
+// WithFallback wraps primary so that a failure is answered by fallback with
+// the response flagged as degraded. Invalid requests are still rejected, and
+// if the fallback fails too the primary error is returned.
+func WithFallback(primary, fallback RecommendFunc) RecommendFunc {
+	return func(ctx context.Context, req *RecommendationRequest) (resp *RecommendationResponse, err error) {
+		if err := ValidateRequest(req); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+		
+		resp, err = primary(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		
+		ctx, span := tracing.Start(ctx, "recommendation.fallback")
+		startTime := time.Now()
+		degraded, fallbackErr := fallback(ctx, req)
+		tracing.End(span, fallbackErr)
+		if fallbackErr != nil || degraded == nil {
+			return nil, err
+		}
+		
+		degraded.Degraded = true
+		degraded.ProcessingTimeMs = time.Since(startTime).Milliseconds()
+		return degraded, nil
+	}
+}
+
+// PopularityFallback ranks popular services by trend score for a request,
+// skipping excluded services and honouring the request limit
+func PopularityFallback(popular []TrendingItem, req *RecommendationRequest) []Recommendation {
+	limit := req.Limit
+	if limit == 0 {
+		limit = 10
+	}
+	excluded := make(map[uuid.UUID]bool, len(req.ExcludeIDs))
+	for _, id := range req.ExcludeIDs {
+		excluded[id] = true
+	}
+	
+	items := append([]TrendingItem(nil), popular...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].TrendScore > items[j].TrendScore })
+	
+	recs := make([]Recommendation, 0, limit)
+	for _, item := range items {
+		if len(recs) == limit {
+			break
+		}
+		if excluded[item.ServiceID] {
+			continue
+		}
+		recs = append(recs, Recommendation{
+			ID:              uuid.New(),
+			Type:            TrendingService,
+			EntityType:      EntityService,
+			EntityID:        item.ServiceID,
+			Score:           item.TrendScore,
+			RelevanceScore:  item.TrendScore,
+			ExplanationCopy: "Popular right now",
+			Position:        len(recs) + 1,
+			Metadata:        map[string]any{"category_id": item.CategoryID, "vendor_id": item.VendorID},
+		})
+	}
+	return recs
+}
+
+// fallbackRecommendations serves the last good response cached for the
+// request, or the in-memory popular services when there is none
+func (e *Engine) fallbackRecommendations(ctx context.Context, req *RecommendationRequest) (*RecommendationResponse, error) {
+	if e.cache != nil {
+		if data, err := e.cache.Get(ctx, fallbackCacheKey(req)).Bytes(); err == nil {
+			var cached RecommendationResponse
+			if err := json.Unmarshal(data, &cached); err == nil {
+				// A stale first page can't be continued from
+				cached.NextCursor = ""
+				return &cached, nil
+			}
+		}
+	}
+	
+	e.mu.RLock()
+	popular := e.popular
+	e.mu.RUnlock()
+	
+	recs := PopularityFallback(popular, req)
+	if len(recs) == 0 {
+		return nil, ErrNoFallback
+	}
+	return &RecommendationResponse{
+		Recommendations:  recs,
+		TotalCandidates:  len(popular),
+		AlgorithmVersion: FallbackAlgorithmVersion,
+	}, nil
+}
+
+// storeFallback caches a good first page for serving while degraded
+func (e *Engine) storeFallback(ctx context.Context, req *RecommendationRequest, resp *RecommendationResponse) {
+	if e.cache == nil || req.Cursor != "" || len(resp.Recommendations) == 0 {
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	_ = e.cache.Set(ctx, fallbackCacheKey(req), data, e.config.FallbackCacheTTL).Err()
+}
+
+// refreshPopular snapshots trending services so degraded mode has something
+// to serve even when the database is unreachable. A failed refresh keeps the
+// previous snapshot.
+func (e *Engine) refreshPopular(ctx context.Context) {
+	items := e.trendingService.GetTrending(ctx, nil, e.config.FallbackPopularSize)
+	if len(items) == 0 {
+		return
+	}
+	e.mu.Lock()
+	e.popular = items
+	e.mu.Unlock()
+}
+
+func fallbackCacheKey(req *RecommendationRequest) string {
+	return fmt.Sprintf("rec:fallback:%s:%s:%s:%s", req.UserID, req.CurrentEntityType, req.CurrentEntityID, req.EventType)
+}
+
 // =============================================================================
 // CANDIDATE GENERATION
 // =============================================================================
@@ -273,19 +583,200 @@ type Candidate struct {
 	EntityType    EntityType
 	EntityID      uuid.UUID
 	CategoryID    uuid.UUID
+	VendorID      uuid.UUID
 	Source        RecommendationType
 	BaseScore     float64
 	Metadata      map[string]any
 }
 
 func (e *Engine) generateCandidates(ctx context.Context, req *RecommendationRequest, userCtx *UserContext) ([]Candidate, error) {
-	var allCandidates []Candidate
+	// Determine which generators run for every request
+	generators := e.selectGenerators(req)
+	
+	ctx, span := tracing.Start(ctx, "recommendation.generate_candidates",
+		attribute.Int("generators.count", len(generators)),
+	)
+	defer span.End()
+	
+	// Entity and event candidates may come from the pool cache while the
+	// user's own and trending ones are generated
+	var pooled []Candidate
+	var hit bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pooled, hit = e.pooledCandidates(ctx, req, userCtx)
+	}()
+	allCandidates := runGenerators(ctx, generators, req, userCtx)
+	<-done
+	allCandidates = append(pooled, allCandidates...)
+	
+	// Deduplicate
+	deduped := e.deduplicateCandidates(allCandidates)
+	span.SetAttributes(
+		attribute.Bool("candidates.pool_hit", hit),
+		attribute.Int("candidates.raw", len(allCandidates)),
+		attribute.Int("candidates.count", len(deduped)),
+	)
+	return deduped, nil
+}
+
+// CandidateGenerator interface for different recommendation sources
+type CandidateGenerator interface {
+	Generate(ctx context.Context, req *RecommendationRequest, userCtx *UserContext) ([]Candidate, error)
+}
+
+// =============================================================================
+// CANDIDATE POOL CACHE
+// =============================================================================
+
+// CandidatePool caches the candidates that depend only on the current entity
+// and event type, since category adjacency changes slowly. Requests filtered
+// by location are never read from or written to the cache, so the shared
+// pools stay location-agnostic.
+type CandidatePool struct {
+	cache *redis.Client
+	ttl   time.Duration
+}
+
+// NewCandidatePool creates a pool cache holding entries for ttl. A nil cache
+// or a ttl of zero disables caching.
+func NewCandidatePool(cache *redis.Client, ttl time.Duration) *CandidatePool {
+	return &CandidatePool{cache: cache, ttl: ttl}
+}
+
+// CandidatePoolKey is the cache key of the pool for an entity and event type
+func CandidatePoolKey(entityType EntityType, entityID uuid.UUID, eventType string) string {
+	return fmt.Sprintf("rec:candidates:%s:%s:%s", entityType, entityID, eventType)
+}
+
+// PoolCacheable reports whether a request's pool may be shared through the
+// cache: it must have an entity or event type and no location
+func PoolCacheable(req *RecommendationRequest) bool {
+	return req.Location == nil && (req.CurrentEntityID != uuid.Nil || req.EventType != "")
+}
+
+func (p *CandidatePool) enabled() bool {
+	return p != nil && p.cache != nil && p.ttl > 0
+}
+
+// Get returns the cached pool for req, or generates and caches it. hit
+// reports whether the pool came from the cache. Uncacheable requests always
+// generate.
+func (p *CandidatePool) Get(ctx context.Context, req *RecommendationRequest, generate func(ctx context.Context) ([]Candidate, error)) (candidates []Candidate, hit bool, err error) {
+	if !p.enabled() || !PoolCacheable(req) {
+		candidates, err = generate(ctx)
+		return candidates, false, err
+	}
+	
+	key := CandidatePoolKey(req.CurrentEntityType, req.CurrentEntityID, req.EventType)
+	if data, err := p.cache.Get(ctx, key).Bytes(); err == nil {
+		if err := json.Unmarshal(data, &candidates); err == nil {
+			return candidates, true, nil
+		}
+	}
+	
+	candidates, err = generate(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if data, err := json.Marshal(candidates); err == nil {
+		_ = p.cache.Set(ctx, key, data, p.ttl).Err()
+	}
+	return candidates, false, nil
+}
+
+// Invalidate drops the cached pools for an entity, under every event type.
+// An empty entity type and nil id drop every pool, for adjacency changes
+// that can affect any of them.
+func (p *CandidatePool) Invalidate(ctx context.Context, entityType EntityType, entityID uuid.UUID) error {
+	if p == nil || p.cache == nil {
+		return nil
+	}
+	pattern := "rec:candidates:*"
+	if entityType != "" || entityID != uuid.Nil {
+		pattern = CandidatePoolKey(entityType, entityID, "*")
+	}
+	
+	iter := p.cache.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return p.cache.Del(ctx, keys...).Err()
+}
+
+// InvalidateCandidates drops cached candidate pools after an entity's
+// services or adjacencies change. An empty entity type and nil id drop them
+// all.
+func (e *Engine) InvalidateCandidates(ctx context.Context, entityType EntityType, entityID uuid.UUID) error {
+	return e.candidatePool.Invalidate(ctx, entityType, entityID)
+}
+
+// pooledCandidates runs the entity and event generators, through the pool
+// cache when the request allows. Cached pools are generated for no user in
+// particular, so they are fitted to this user afterwards.
+func (e *Engine) pooledCandidates(ctx context.Context, req *RecommendationRequest, userCtx *UserContext) ([]Candidate, bool) {
+	generators := []CandidateGenerator{&AdjacencyGenerator{graph: e.adjacencyGraph, db: e.db}}
+	if req.EventType != "" {
+		generators = append(generators, &EventBasedGenerator{db: e.db, eventDetector: e.eventDetector})
+	}
+	if !e.candidatePool.enabled() || !PoolCacheable(req) {
+		return runGenerators(ctx, generators, req, userCtx), false
+	}
+	
+	shared := &RecommendationRequest{
+		CurrentEntityID:   req.CurrentEntityID,
+		CurrentEntityType: req.CurrentEntityType,
+		EventType:         req.EventType,
+	}
+	candidates, hit, _ := e.candidatePool.Get(ctx, shared, func(ctx context.Context) ([]Candidate, error) {
+		return runGenerators(ctx, generators, shared, &UserContext{AlreadyBookedCategories: []uuid.UUID{}}), nil
+	})
+	return FitPoolToUser(candidates, userCtx), hit
+}
+
+// FitPoolToUser adapts a shared candidate pool to a user: event suggestions
+// for categories they already booked are dropped, and adjacency candidates
+// note whether they booked the source category
+func FitPoolToUser(candidates []Candidate, userCtx *UserContext) []Candidate {
+	fitted := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Source == EventBasedSuggest && containsID(userCtx.AlreadyBookedCategories, c.CategoryID) {
+			continue
+		}
+		if c.Source == AdjacentService && c.Metadata != nil {
+			// Cached metadata comes back from JSON with ids as strings
+			var source uuid.UUID
+			switch v := c.Metadata["source_category"].(type) {
+			case uuid.UUID:
+				source = v
+			case string:
+				source, _ = uuid.Parse(v)
+			}
+			if source != uuid.Nil {
+				c.Metadata["source_category"] = source
+				c.Metadata["source_booked"] = containsID(userCtx.AlreadyBookedCategories, source)
+			}
+		}
+		fitted = append(fitted, c)
+	}
+	return fitted
+}
+
+// runGenerators runs generators concurrently and collects their candidates.
+// A failing generator contributes nothing rather than failing the request.
+func runGenerators(ctx context.Context, generators []CandidateGenerator, req *RecommendationRequest, userCtx *UserContext) []Candidate {
+	var all []Candidate
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	
-	// Determine which generators to use
-	generators := e.selectGenerators(req)
-	
 	for _, gen := range generators {
 		wg.Add(1)
 		go func(g CandidateGenerator) {
@@ -296,20 +787,13 @@ func (e *Engine) generateCandidates(ctx context.Context, req *RecommendationRequ
 				return
 			}
 			mu.Lock()
-			allCandidates = append(allCandidates, candidates...)
+			all = append(all, candidates...)
 			mu.Unlock()
 		}(gen)
 	}
 	
 	wg.Wait()
-	
-	// Deduplicate
-	return e.deduplicateCandidates(allCandidates), nil
-}
-
-// CandidateGenerator interface for different recommendation sources
-type CandidateGenerator interface {
-	Generate(ctx context.Context, req *RecommendationRequest, userCtx *UserContext) ([]Candidate, error)
+	return all
 }
 
 // =============================================================================
@@ -346,6 +830,8 @@ func (g *AdjacencyGenerator) Generate(ctx context.Context, req *RecommendationRe
 	
 	// Get adjacent categories from the graph
 	adjacentCategories := g.graph.GetAdjacent(categoryID, req.EventType, 20)
+	sourceName := g.getCategoryName(ctx, categoryID)
+	sourceBooked := containsID(userCtx.AlreadyBookedCategories, categoryID)
 	
 	var candidates []Candidate
 	for _, adj := range adjacentCategories {
@@ -357,13 +843,16 @@ func (g *AdjacencyGenerator) Generate(ctx context.Context, req *RecommendationRe
 				EntityType: EntityService,
 				EntityID:   svc.ID,
 				CategoryID: adj.TargetCategoryID,
+				VendorID:   svc.VendorID,
 				Source:     AdjacentService,
 				BaseScore:  adj.Score,
 				Metadata: map[string]any{
 					"adjacency_type":      adj.AdjacencyType,
 					"recommendation_copy": adj.RecommendationCopy,
-					"source_category":     categoryID,
-					"target_category":     adj.TargetCategoryID,
+					"source_category":      categoryID,
+					"source_category_name": sourceName,
+					"source_booked":        sourceBooked,
+					"target_category":      adj.TargetCategoryID,
 				},
 			})
 		}
@@ -378,6 +867,12 @@ func (g *AdjacencyGenerator) getCategoryForService(ctx context.Context, serviceI
 	return categoryID
 }
 
+func (g *AdjacencyGenerator) getCategoryName(ctx context.Context, categoryID uuid.UUID) string {
+	var name string
+	g.db.QueryRow(ctx, "SELECT name FROM service_categories WHERE id = $1", categoryID).Scan(&name)
+	return name
+}
+
 func (g *AdjacencyGenerator) getPrimaryCategoryForVendor(ctx context.Context, vendorID uuid.UUID) uuid.UUID {
 	var categoryID uuid.UUID
 	g.db.QueryRow(ctx, `
@@ -473,6 +968,7 @@ func (g *EventBasedGenerator) Generate(ctx context.Context, req *RecommendationR
 				EntityType: EntityService,
 				EntityID:   svc.ID,
 				CategoryID: cat.CategoryID,
+				VendorID:   svc.VendorID,
 				Source:     EventBasedSuggest,
 				BaseScore:  cat.NecessityScore * cat.PopularityScore,
 				Metadata: map[string]any{
@@ -595,6 +1091,7 @@ func (g *CollaborativeGenerator) Generate(ctx context.Context, req *Recommendati
 			EntityType: EntityService,
 			EntityID:   item.ServiceID,
 			CategoryID: item.CategoryID,
+			VendorID:   item.VendorID,
 			Source:     CollaborativeFilter,
 			BaseScore:  item.Score,
 			Metadata: map[string]any{
@@ -654,6 +1151,7 @@ func (g *CollaborativeGenerator) findSimilarUsers(ctx context.Context, userID uu
 type PopularItem struct {
 	ServiceID        uuid.UUID
 	CategoryID       uuid.UUID
+	VendorID         uuid.UUID
 	Score            float64
 	SimilarUserCount int
 	BookingFrequency float64
@@ -661,7 +1159,7 @@ type PopularItem struct {
 
 func (g *CollaborativeGenerator) getPopularAmongSimilar(ctx context.Context, similarUserIDs []uuid.UUID, excludeServices []uuid.UUID, limit int) ([]PopularItem, error) {
 	query := `
-		SELECT s.id, s.category_id, 
+		SELECT s.id, s.category_id, s.vendor_id,
 		       COUNT(DISTINCT b.user_id) as similar_user_count,
 		       COUNT(b.id) as booking_count
 		FROM bookings b
@@ -670,7 +1168,7 @@ func (g *CollaborativeGenerator) getPopularAmongSimilar(ctx context.Context, sim
 		  AND b.status IN ('completed', 'confirmed')
 		  AND s.id != ALL($2)
 		  AND s.is_available = TRUE
-		GROUP BY s.id, s.category_id
+		GROUP BY s.id, s.category_id, s.vendor_id
 		ORDER BY similar_user_count DESC, booking_count DESC
 		LIMIT $3
 	`
@@ -686,7 +1184,7 @@ func (g *CollaborativeGenerator) getPopularAmongSimilar(ctx context.Context, sim
 	for rows.Next() {
 		var item PopularItem
 		var bookingCount int
-		if err := rows.Scan(&item.ServiceID, &item.CategoryID, &item.SimilarUserCount, &bookingCount); err != nil {
+		if err := rows.Scan(&item.ServiceID, &item.CategoryID, &item.VendorID, &item.SimilarUserCount, &bookingCount); err != nil {
 			continue
 		}
 		if item.SimilarUserCount > maxCount {
@@ -722,6 +1220,7 @@ func (g *TrendingGenerator) Generate(ctx context.Context, req *RecommendationReq
 			EntityType: EntityService,
 			EntityID:   item.ServiceID,
 			CategoryID: item.CategoryID,
+			VendorID:   item.VendorID,
 			Source:     TrendingService,
 			BaseScore:  item.TrendScore,
 			Metadata: map[string]any{
@@ -1056,6 +1555,9 @@ func NewScorer(config *Config) *Scorer {
 }
 
 func (s *Scorer) ScoreAll(ctx context.Context, candidates []Candidate, req *RecommendationRequest, userCtx *UserContext) []Recommendation {
+	_, span := tracing.Start(ctx, "recommendation.score", attribute.Int("candidates.count", len(candidates)))
+	defer span.End()
+	
 	recs := make([]Recommendation, 0, len(candidates))
 	
 	for _, c := range candidates {
@@ -1091,13 +1593,28 @@ func (s *Scorer) scoreCandidate(c Candidate, req *RecommendationRequest, userCtx
 	// Final score
 	finalScore := weightedBase + 
 		(personalizationBoost * s.config.PersonalizationWeight) +
-		(relevanceScore * 0.2) +
+		(relevanceScore * relevanceWeight) +
 		(recencyBoost * s.config.RecencyWeight)
 	
 	// Normalize to 0-1
 	finalScore = math.Min(1.0, math.Max(0.0, finalScore))
 	
+	factors := &ScoreFactors{
+		BaseScore:             baseScore,
+		SourceWeight:          sourceWeight,
+		PersonalizationBoost:  personalizationBoost,
+		PersonalizationWeight: s.config.PersonalizationWeight,
+		Relevance:             relevanceScore,
+		RelevanceWeight:       relevanceWeight,
+		RecencyBoost:          recencyBoost,
+		RecencyWeight:         s.config.RecencyWeight,
+		PipelineScore:         finalScore,
+		FinalScore:            finalScore,
+		MatchedSignals:        s.MatchedSignals(c, req, userCtx),
+	}
+	
 	// Build explanation
+	reasons := s.BuildReasons(c, req, userCtx)
 	explanation := s.buildExplanation(c, userCtx)
 	
 	return Recommendation{
@@ -1108,10 +1625,36 @@ func (s *Scorer) scoreCandidate(c Candidate, req *RecommendationRequest, userCtx
 		Score:           finalScore,
 		RelevanceScore:  relevanceScore,
 		ExplanationCopy: explanation,
-		Metadata:        c.Metadata,
+		Reasons:         reasons,
+		Metadata:        diversityMetadata(c),
+		Factors:         factors,
+	}
+}
+
+// diversityMetadata copies a candidate's metadata with the category and
+// vendor the diversifier spreads results across
+func diversityMetadata(c Candidate) map[string]any {
+	metadata := make(map[string]any, len(c.Metadata)+2)
+	for k, v := range c.Metadata {
+		metadata[k] = v
+	}
+	if c.CategoryID != uuid.Nil {
+		metadata["category_id"] = c.CategoryID
 	}
+	vendorID := c.VendorID
+	if c.EntityType == EntityVendor {
+		vendorID = c.EntityID
+	}
+	if vendorID != uuid.Nil {
+		metadata["vendor_id"] = vendorID
+	}
+	return metadata
 }
 
+// relevanceWeight is how much the relevance score counts towards the
+// pipeline score
+const relevanceWeight = 0.2
+
 func (s *Scorer) getSourceWeight(source RecommendationType) float64 {
 	switch source {
 	case AdjacentService:
@@ -1192,83 +1735,748 @@ func (s *Scorer) buildExplanation(c Candidate, userCtx *UserContext) string {
 	}
 }
 
-// =============================================================================
-// RANKING & DIVERSIFICATION
-// =============================================================================
+// BuildReasons derives human-readable reasons from the signals that produced
+// a candidate, e.g. "Pairs well with your booked Catering" for an adjacency
+// hit or "Popular for weddings near you" for an event-based suggestion.
+func (s *Scorer) BuildReasons(c Candidate, req *RecommendationRequest, userCtx *UserContext) []string {
+	var reasons []string
+	nearby := req != nil && req.Location != nil
 
-// Ranker sorts recommendations by score
-type Ranker struct {
-	config *Config
+	switch c.Source {
+	case AdjacentService:
+		name, _ := c.Metadata["source_category_name"].(string)
+		booked, _ := c.Metadata["source_booked"].(bool)
+		switch {
+		case name != "" && booked:
+			reasons = append(reasons, fmt.Sprintf("Pairs well with your booked %s", name))
+		case name != "":
+			reasons = append(reasons, fmt.Sprintf("Complementary to %s", name))
+		default:
+			reasons = append(reasons, "Frequently booked together with your selection")
+		}
+	case EventBasedSuggest:
+		eventType, _ := c.Metadata["event_type"].(string)
+		if eventType != "" {
+			reason := "Popular for " + pluralizeEvent(eventType)
+			if nearby {
+				reason += " near you"
+			}
+			reasons = append(reasons, reason)
+		}
+		if necessity, ok := c.Metadata["necessity_score"].(float64); ok && necessity >= 0.8 {
+			reasons = append(reasons, "Essential for your event")
+		}
+	case CollaborativeFilter:
+		if count, ok := c.Metadata["similar_user_count"].(int); ok && count > 0 {
+			reasons = append(reasons, fmt.Sprintf("Booked by %d people with similar preferences", count))
+		} else {
+			reasons = append(reasons, "Popular among users with similar preferences")
+		}
+	case TrendingService:
+		if nearby {
+			reasons = append(reasons, "Trending in your area")
+		} else {
+			reasons = append(reasons, "Trending this week")
+		}
+	}
+
+	if userCtx != nil && containsID(userCtx.PreferredCategories, c.CategoryID) {
+		reasons = append(reasons, "Matches categories you've booked before")
+	}
+
+	if len(reasons) == 0 {
+		reasons = append(reasons, "Recommended for you")
+	}
+	return reasons
 }
 
-func NewRanker(config *Config) *Ranker {
-	return &Ranker{config: config}
+// MatchedSignals lists the signals that contributed to a candidate's score,
+// e.g. "adjacent_to:Catering" or "event_type:wedding", for explanations
+func (s *Scorer) MatchedSignals(c Candidate, req *RecommendationRequest, userCtx *UserContext) []string {
+	signals := []string{"source:" + string(c.Source)}
+	
+	if name, ok := c.Metadata["source_category_name"].(string); ok && name != "" {
+		signals = append(signals, "adjacent_to:"+name)
+	}
+	if req != nil && req.EventType != "" {
+		if eventType, ok := c.Metadata["event_type"].(string); ok && eventType == req.EventType {
+			signals = append(signals, "event_type:"+eventType)
+		}
+	}
+	if count, ok := c.Metadata["similar_user_count"].(int); ok && count > 0 {
+		signals = append(signals, fmt.Sprintf("similar_users:%d", count))
+	}
+	if growth, ok := c.Metadata["growth_rate"].(float64); ok && growth > 0 {
+		signals = append(signals, "trending_growth")
+	}
+	
+	if userCtx != nil {
+		if len(userCtx.Interests) > 0 {
+			signals = append(signals, "interests")
+		}
+		if containsID(userCtx.PreferredCategories, c.CategoryID) {
+			signals = append(signals, "preferred_category")
+		}
+		if containsID(userCtx.ViewedServiceIDs, c.EntityID) {
+			signals = append(signals, "previously_viewed")
+		}
+	}
+	return signals
 }
 
-func (r *Ranker) Rank(recs []Recommendation) []Recommendation {
-	sort.Slice(recs, func(i, j int) bool {
-		return recs[i].Score > recs[j].Score
-	})
-	return recs
+// pluralizeEvent turns an event slug such as "birthday_party" into
+// "birthday parties" for use in explanation copy.
+func pluralizeEvent(eventType string) string {
+	label := strings.ReplaceAll(eventType, "_", " ")
+	switch {
+	case strings.HasSuffix(label, "y") && !strings.HasSuffix(label, "ay") && !strings.HasSuffix(label, "ey"):
+		return strings.TrimSuffix(label, "y") + "ies"
+	case strings.HasSuffix(label, "s"):
+		return label
+	default:
+		return label + "s"
+	}
 }
 
-// Diversifier ensures variety in recommendations
-type Diversifier struct {
-	config *Config
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
 }
 
-func NewDiversifier(config *Config) *Diversifier {
-	return &Diversifier{config: config}
+// =============================================================================
+// SIGNAL BLENDING
+// =============================================================================
+
+// BlendWeights sets how much the final score leans on the pipeline score
+// versus the collaborative and content signals. Weights are relative to
+// each other and need not sum to 1.
+type BlendWeights struct {
+	Base          float64 `json:"base"`
+	Collaborative float64 `json:"collaborative"`
+	Content       float64 `json:"content"`
 }
 
-func (d *Diversifier) Diversify(recs []Recommendation, limit int, diversityFactor float64) []Recommendation {
-	if len(recs) <= limit {
-		return d.assignPositions(recs)
+// Validate checks the weights are usable
+func (w BlendWeights) Validate() error {
+	if w.Base < 0 || w.Collaborative < 0 || w.Content < 0 {
+		return fmt.Errorf("blend weights must not be negative")
+	}
+	if w.Base+w.Collaborative+w.Content == 0 {
+		return fmt.Errorf("at least one blend weight must be positive")
+	}
+	return nil
+}
+
+// Interaction is a single user touching a single item
+type Interaction struct {
+	UserID   uuid.UUID
+	EntityID uuid.UUID
+}
+
+// CooccurrenceIndex counts how often two items are interacted with by the
+// same user
+type CooccurrenceIndex struct {
+	itemUsers map[uuid.UUID]int
+	pairs     map[uuid.UUID]map[uuid.UUID]int
+}
+
+// NewCooccurrenceIndex builds an index from raw interactions. Repeat
+// interactions by the same user with the same item count once.
+func NewCooccurrenceIndex(interactions []Interaction) *CooccurrenceIndex {
+	byUser := make(map[uuid.UUID]map[uuid.UUID]bool)
+	for _, in := range interactions {
+		if byUser[in.UserID] == nil {
+			byUser[in.UserID] = make(map[uuid.UUID]bool)
+		}
+		byUser[in.UserID][in.EntityID] = true
 	}
 	
-	// Use Maximal Marginal Relevance (MMR) for diversification
-	selected := make([]Recommendation, 0, limit)
-	remaining := make([]Recommendation, len(recs))
-	copy(remaining, recs)
-	
-	// Always add the top item
-	selected = append(selected, remaining[0])
-	remaining = remaining[1:]
-	
-	for len(selected) < limit && len(remaining) > 0 {
-		bestIdx := 0
-		bestMMR := -1.0
-		
-		for i, candidate := range remaining {
-			// Calculate similarity to already selected
-			maxSim := 0.0
-			for _, sel := range selected {
-				sim := d.calculateSimilarity(candidate, sel)
-				if sim > maxSim {
-					maxSim = sim
+	idx := &CooccurrenceIndex{
+		itemUsers: make(map[uuid.UUID]int),
+		pairs:     make(map[uuid.UUID]map[uuid.UUID]int),
+	}
+	for _, items := range byUser {
+		for a := range items {
+			idx.itemUsers[a]++
+			for b := range items {
+				if a == b {
+					continue
 				}
-			}
-			
-			// MMR = λ * Relevance - (1-λ) * MaxSimilarity
-			mmr := diversityFactor*candidate.Score - (1-diversityFactor)*maxSim
-			
-			if mmr > bestMMR {
-				bestMMR = mmr
-				bestIdx = i
+				if idx.pairs[a] == nil {
+					idx.pairs[a] = make(map[uuid.UUID]int)
+				}
+				idx.pairs[a][b]++
 			}
 		}
-		
-		selected = append(selected, remaining[bestIdx])
-		remaining = append(remaining[:bestIdx], remaining[bestIdx+1:]...)
 	}
-	
-	return d.assignPositions(selected)
+	return idx
 }
 
-func (d *Diversifier) calculateSimilarity(a, b Recommendation) float64 {
+// Similarity is the cosine similarity of two items' user sets, 0-1
+func (idx *CooccurrenceIndex) Similarity(a, b uuid.UUID) float64 {
+	if idx == nil {
+		return 0
+	}
+	both := idx.pairs[a][b]
+	if both == 0 {
+		return 0
+	}
+	return float64(both) / math.Sqrt(float64(idx.itemUsers[a]*idx.itemUsers[b]))
+}
+
+// ItemFeatures describes an item for content similarity
+type ItemFeatures struct {
+	CategoryID uuid.UUID
+	Attributes []string
+}
+
+// ContentSimilarity scores two items 0-1: half for sharing a category, half
+// for the Jaccard overlap of their attributes
+func ContentSimilarity(a, b ItemFeatures) float64 {
 	sim := 0.0
+	if a.CategoryID != uuid.Nil && a.CategoryID == b.CategoryID {
+		sim += 0.5
+	}
 	
-	// Same category = high similarity
+	attrs := make(map[string]bool, len(a.Attributes))
+	for _, attr := range a.Attributes {
+		attrs[strings.ToLower(attr)] = true
+	}
+	shared, union := 0, len(attrs)
+	seen := make(map[string]bool, len(b.Attributes))
+	for _, attr := range b.Attributes {
+		attr = strings.ToLower(attr)
+		if seen[attr] {
+			continue
+		}
+		seen[attr] = true
+		if attrs[attr] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	if union > 0 {
+		sim += 0.5 * float64(shared) / float64(union)
+	}
+	return sim
+}
+
+// BlendSignals holds what the user has interacted with and the data needed
+// to compare candidates against it
+type BlendSignals struct {
+	History      []uuid.UUID
+	Cooccurrence *CooccurrenceIndex
+	Features     map[uuid.UUID]ItemFeatures
+}
+
+// CollaborativeScore is the strongest co-occurrence between the item and
+// anything in the user's history
+func (s *BlendSignals) CollaborativeScore(id uuid.UUID) float64 {
+	best := 0.0
+	for _, h := range s.History {
+		if h != id {
+			best = math.Max(best, s.Cooccurrence.Similarity(h, id))
+		}
+	}
+	return best
+}
+
+// ContentScore is the closest content match between the item and anything
+// in the user's history
+func (s *BlendSignals) ContentScore(id uuid.UUID) float64 {
+	features, ok := s.Features[id]
+	if !ok {
+		return 0
+	}
+	best := 0.0
+	for _, h := range s.History {
+		if other, ok := s.Features[h]; ok && h != id {
+			best = math.Max(best, ContentSimilarity(features, other))
+		}
+	}
+	return best
+}
+
+// BlendScores replaces each recommendation's score with the weighted blend
+// of its pipeline score and its collaborative and content scores
+func BlendScores(recs []Recommendation, signals *BlendSignals, weights BlendWeights) []Recommendation {
+	total := weights.Base + weights.Collaborative + weights.Content
+	if signals == nil || len(signals.History) == 0 || total <= 0 {
+		return recs
+	}
+	
+	for i := range recs {
+		collab := signals.CollaborativeScore(recs[i].EntityID)
+		content := signals.ContentScore(recs[i].EntityID)
+		recs[i].CollaborativeScore = collab
+		recs[i].ContentScore = content
+		recs[i].Score = (weights.Base*recs[i].Score +
+			weights.Collaborative*collab +
+			weights.Content*content) / total
+		if f := recs[i].Factors; f != nil {
+			w := weights
+			f.CollaborativeScore = collab
+			f.ContentScore = content
+			f.Blend = &w
+			f.FinalScore = recs[i].Score
+		}
+	}
+	return recs
+}
+
+func (e *Engine) blendWeights(req *RecommendationRequest) BlendWeights {
+	if req.Blend != nil {
+		return *req.Blend
+	}
+	return e.config.Blend
+}
+
+func (e *Engine) blend(ctx context.Context, recs []Recommendation, req *RecommendationRequest, userCtx *UserContext) []Recommendation {
+	weights := e.blendWeights(req)
+	if weights.Collaborative == 0 && weights.Content == 0 {
+		return recs
+	}
+	
+	ctx, span := tracing.Start(ctx, "recommendation.blend",
+		attribute.Float64("blend.collaborative", weights.Collaborative),
+		attribute.Float64("blend.content", weights.Content),
+	)
+	signals, err := e.loadBlendSignals(ctx, recs, req, userCtx)
+	tracing.End(span, err)
+	if err != nil {
+		// Fall back to the unblended scores
+		return recs
+	}
+	return BlendScores(recs, signals, weights)
+}
+
+func (e *Engine) loadBlendSignals(ctx context.Context, recs []Recommendation, req *RecommendationRequest, userCtx *UserContext) (*BlendSignals, error) {
+	signals := &BlendSignals{Features: make(map[uuid.UUID]ItemFeatures)}
+	if req.CurrentEntityType == EntityService && req.CurrentEntityID != uuid.Nil {
+		signals.History = append(signals.History, req.CurrentEntityID)
+	}
+	if userCtx != nil {
+		signals.History = append(signals.History, userCtx.BookedServiceIDs...)
+		signals.History = append(signals.History, userCtx.ViewedServiceIDs...)
+	}
+	if len(signals.History) == 0 {
+		return signals, nil
+	}
+	
+	itemIDs := append([]uuid.UUID{}, signals.History...)
+	for _, rec := range recs {
+		if rec.EntityType == EntityService {
+			itemIDs = append(itemIDs, rec.EntityID)
+		}
+	}
+	
+	rows, err := e.db.Query(ctx, `
+		SELECT DISTINCT user_id, entity_id
+		FROM user_interactions
+		WHERE entity_type = 'service'
+		  AND entity_id = ANY($1)
+		  AND interaction_type IN ('view', 'save', 'add_to_cart', 'inquire', 'book')
+		  AND created_at > NOW() - make_interval(days => $2)
+		LIMIT 50000
+	`, itemIDs, e.config.BlendLookbackDays)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	for rows.Next() {
+		var in Interaction
+		if err := rows.Scan(&in.UserID, &in.EntityID); err != nil {
+			continue
+		}
+		interactions = append(interactions, in)
+	}
+	rows.Close()
+	signals.Cooccurrence = NewCooccurrenceIndex(interactions)
+	
+	rows, err = e.db.Query(ctx, `
+		SELECT id, category_id, COALESCE(highlights, '{}') || COALESCE(includes, '{}')
+		FROM services
+		WHERE id = ANY($1)
+	`, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		var features ItemFeatures
+		if err := rows.Scan(&id, &features.CategoryID, &features.Attributes); err != nil {
+			continue
+		}
+		signals.Features[id] = features
+	}
+	
+	return signals, nil
+}
+
+// =============================================================================
+// FRESHNESS DECAY
+// =============================================================================
+
+// FreshnessDecay scales scores by how recently the vendor behind a
+// recommendation had positive activity (a confirmed booking or a good review).
+// Activity inside the boost window earns up to MaxBoost; after it the score
+// halves every HalfLifeDays, never dropping below Floor.
+type FreshnessDecay struct {
+	Enabled         bool    `json:"enabled"`
+	BoostWindowDays float64 `json:"boost_window_days"`
+	MaxBoost        float64 `json:"max_boost"`
+	HalfLifeDays    float64 `json:"half_life_days"`
+	Floor           float64 `json:"floor"`
+}
+
+// Validate checks the decay settings are usable
+func (f FreshnessDecay) Validate() error {
+	if !f.Enabled {
+		return nil
+	}
+	if f.HalfLifeDays <= 0 {
+		return fmt.Errorf("freshness half life must be positive")
+	}
+	if f.BoostWindowDays < 0 {
+		return fmt.Errorf("freshness boost window must not be negative")
+	}
+	if f.MaxBoost < 0 || f.MaxBoost > 1 {
+		return fmt.Errorf("freshness max boost must be between 0 and 1")
+	}
+	if f.Floor < 0 || f.Floor > 1 {
+		return fmt.Errorf("freshness floor must be between 0 and 1")
+	}
+	return nil
+}
+
+// Factor is the multiplier for a vendor last active at lastActive. Vendors
+// with no recorded activity are left unchanged.
+func (f FreshnessDecay) Factor(lastActive, now time.Time) float64 {
+	if !f.Enabled || lastActive.IsZero() {
+		return 1.0
+	}
+	
+	ageDays := math.Max(0, now.Sub(lastActive).Hours()/24)
+	if ageDays <= f.BoostWindowDays {
+		if f.BoostWindowDays == 0 {
+			return 1.0 + f.MaxBoost
+		}
+		return 1.0 + f.MaxBoost*(1-ageDays/f.BoostWindowDays)
+	}
+	
+	decayed := math.Pow(0.5, (ageDays-f.BoostWindowDays)/f.HalfLifeDays)
+	return math.Max(f.Floor, decayed)
+}
+
+// ApplyFreshness scales each recommendation's score by its freshness factor,
+// keeping scores within 0-1
+func ApplyFreshness(recs []Recommendation, lastActivity map[uuid.UUID]time.Time, decay FreshnessDecay, now time.Time) []Recommendation {
+	if !decay.Enabled {
+		return recs
+	}
+	
+	for i := range recs {
+		factor := decay.Factor(lastActivity[recs[i].EntityID], now)
+		recs[i].FreshnessFactor = factor
+		recs[i].Score = math.Min(1.0, recs[i].Score*factor)
+		if f := recs[i].Factors; f != nil {
+			f.FreshnessFactor = factor
+			f.FinalScore = recs[i].Score
+		}
+	}
+	return recs
+}
+
+func (e *Engine) freshnessDecay(req *RecommendationRequest) FreshnessDecay {
+	if req.Freshness != nil {
+		return *req.Freshness
+	}
+	return e.config.Freshness
+}
+
+func (e *Engine) freshen(ctx context.Context, recs []Recommendation, req *RecommendationRequest) []Recommendation {
+	decay := e.freshnessDecay(req)
+	if !decay.Enabled || len(recs) == 0 {
+		return recs
+	}
+	
+	ctx, span := tracing.Start(ctx, "recommendation.freshness",
+		attribute.Float64("freshness.half_life_days", decay.HalfLifeDays),
+	)
+	lastActivity, err := e.loadLastActivity(ctx, recs)
+	tracing.End(span, err)
+	if err != nil {
+		// Fall back to the undecayed scores
+		return recs
+	}
+	return ApplyFreshness(recs, lastActivity, decay, time.Now())
+}
+
+// loadLastActivity finds the latest confirmed booking or 4+ star review for
+// the vendor behind each recommended service
+func (e *Engine) loadLastActivity(ctx context.Context, recs []Recommendation) (map[uuid.UUID]time.Time, error) {
+	var serviceIDs []uuid.UUID
+	for _, rec := range recs {
+		if rec.EntityType == EntityService {
+			serviceIDs = append(serviceIDs, rec.EntityID)
+		}
+	}
+	
+	lastActivity := make(map[uuid.UUID]time.Time)
+	if len(serviceIDs) == 0 {
+		return lastActivity, nil
+	}
+	
+	rows, err := e.db.Query(ctx, `
+		SELECT s.id, GREATEST(
+			(SELECT MAX(b.created_at) FROM bookings b
+			 WHERE b.vendor_id = s.vendor_id AND b.status IN ('confirmed', 'in_progress', 'completed')),
+			(SELECT MAX(r.created_at) FROM reviews r
+			 WHERE r.vendor_id = s.vendor_id AND r.rating >= 4)
+		)
+		FROM services s
+		WHERE s.id = ANY($1)
+	`, serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var id uuid.UUID
+		var last *time.Time
+		if err := rows.Scan(&id, &last); err != nil || last == nil {
+			continue
+		}
+		lastActivity[id] = *last
+	}
+	
+	return lastActivity, nil
+}
+
+// =============================================================================
+// RANKING & DIVERSIFICATION
+// =============================================================================
+
+// Ranker sorts recommendations by score
+type Ranker struct {
+	config *Config
+}
+
+func NewRanker(config *Config) *Ranker {
+	return &Ranker{config: config}
+}
+
+// Rank orders by score, breaking ties by entity ID so the order is the same
+// on every request and pages can pick up where the last one stopped
+func (r *Ranker) Rank(recs []Recommendation) []Recommendation {
+	sort.SliceStable(recs, func(i, j int) bool {
+		return rankedBefore(recs[i].Score, recs[i].EntityID, recs[j].Score, recs[j].EntityID)
+	})
+	return recs
+}
+
+func rankedBefore(scoreA float64, idA uuid.UUID, scoreB float64, idB uuid.UUID) bool {
+	if scoreA != scoreB {
+		return scoreA > scoreB
+	}
+	return idA.String() < idB.String()
+}
+
+// ErrInvalidCursor is returned for a cursor this engine did not issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the last candidate a page served, in ranked order
+type pageCursor struct {
+	Score    float64   `json:"s"`
+	EntityID uuid.UUID `json:"id"`
+}
+
+// EncodeCursor builds the opaque cursor for continuing after rec
+func EncodeCursor(rec Recommendation) string {
+	raw, _ := json.Marshal(pageCursor{Score: rec.Score, EntityID: rec.EntityID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(cursor string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.EntityID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Paginate takes the next limit recommendations after cursor from a ranked
+// list, returning the cursor for the page after, or "" on the last page. An
+// empty cursor starts from the top. Pages never overlap or skip a candidate,
+// so diversification only reorders within a page.
+func Paginate(ranked []Recommendation, cursor string, limit int) ([]Recommendation, string, error) {
+	start := 0
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(ranked), func(i int) bool {
+			return rankedBefore(after.Score, after.EntityID, ranked[i].Score, ranked[i].EntityID)
+		})
+	}
+	
+	end := start + limit
+	if end >= len(ranked) {
+		return ranked[start:], "", nil
+	}
+	return ranked[start:end], EncodeCursor(ranked[end-1]), nil
+}
+
+// Diversifier ensures variety in recommendations
+type Diversifier struct {
+	config *Config
+}
+
+func NewDiversifier(config *Config) *Diversifier {
+	return &Diversifier{config: config}
+}
+
+// Diversify picks limit items balancing relevance against variety, as
+// weighted by diversityFactor
+func (d *Diversifier) Diversify(recs []Recommendation, limit int, diversityFactor float64) []Recommendation {
+	d.recordRankedPositions(recs)
+	if limit > len(recs) {
+		limit = len(recs)
+	}
+	return d.assignPositions(d.selectMMR(recs, limit, diversityFactor))
+}
+
+// Arrange orders a page for variety without dropping any of it, leaving the
+// top item first
+func (d *Diversifier) Arrange(recs []Recommendation, diversityFactor float64) []Recommendation {
+	d.recordRankedPositions(recs)
+	if len(recs) <= 2 {
+		return d.assignPositions(recs)
+	}
+	return d.assignPositions(d.selectMMR(recs, len(recs), diversityFactor))
+}
+
+// recordRankedPositions remembers where each item stood on score alone, so
+// explanations can show how far diversification moved it
+func (d *Diversifier) recordRankedPositions(recs []Recommendation) {
+	for i := range recs {
+		if recs[i].Factors != nil {
+			recs[i].Factors.RankedPosition = i + 1
+			recs[i].Factors.MaxSimilarity = 0
+		}
+	}
+}
+
+// selectMMR picks limit items by Maximal Marginal Relevance. Each pick
+// maximises (1-diversityFactor) * relevance - diversityFactor * similarity
+// to the items already picked, so 0 keeps the ranked order and 1 spreads
+// categories and vendors as far as possible. Relevance is the score relative
+// to the top item. Whatever the factor, one vendor takes at most
+// MaxPerVendor of the first VendorCapWindow places while others are left.
+func (d *Diversifier) selectMMR(recs []Recommendation, limit int, diversityFactor float64) []Recommendation {
+	if len(recs) == 0 || limit <= 0 {
+		return recs[:0]
+	}
+	diversityFactor = math.Min(1, math.Max(0, diversityFactor))
+	
+	selected := make([]Recommendation, 0, limit)
+	remaining := make([]Recommendation, len(recs))
+	copy(remaining, recs)
+	
+	topScore := 0.0
+	for _, r := range remaining {
+		topScore = math.Max(topScore, r.Score)
+	}
+	vendorCount := make(map[uuid.UUID]int)
+	
+	for len(selected) < limit && len(remaining) > 0 {
+		capped := d.vendorCapped(vendorCount, len(selected))
+		bestIdx := -1
+		bestMMR := math.Inf(-1)
+		bestSim := 0.0
+		
+		for _, skipCapped := range []bool{true, false} {
+			for i, candidate := range remaining {
+				if skipCapped && capped(candidate) {
+					continue
+				}
+				
+				// Calculate similarity to already selected
+				maxSim := 0.0
+				for _, sel := range selected {
+					if sim := d.calculateSimilarity(candidate, sel); sim > maxSim {
+						maxSim = sim
+					}
+				}
+				
+				relevance := 0.0
+				if topScore > 0 {
+					relevance = candidate.Score / topScore
+				}
+				mmr := (1-diversityFactor)*relevance - diversityFactor*maxSim
+				if mmr > bestMMR {
+					bestMMR = mmr
+					bestIdx = i
+					bestSim = maxSim
+				}
+			}
+			// Only fall back to capped vendors when nobody else is left
+			if bestIdx >= 0 {
+				break
+			}
+		}
+		
+		if f := remaining[bestIdx].Factors; f != nil {
+			f.MaxSimilarity = bestSim
+		}
+		if vendorID, ok := recommendationVendor(remaining[bestIdx]); ok {
+			vendorCount[vendorID]++
+		}
+		selected = append(selected, remaining[bestIdx])
+		remaining = append(remaining[:bestIdx], remaining[bestIdx+1:]...)
+	}
+	
+	return selected
+}
+
+// vendorCapped returns whether a recommendation's vendor already has its
+// share of the leading places, when filling the given position
+func (d *Diversifier) vendorCapped(vendorCount map[uuid.UUID]int, position int) func(Recommendation) bool {
+	return func(r Recommendation) bool {
+		if d.config.MaxPerVendor <= 0 || position >= d.config.VendorCapWindow {
+			return false
+		}
+		vendorID, ok := recommendationVendor(r)
+		return ok && vendorCount[vendorID] >= d.config.MaxPerVendor
+	}
+}
+
+// recommendationVendor returns the vendor offering a recommendation
+func recommendationVendor(r Recommendation) (uuid.UUID, bool) {
+	if r.EntityType == EntityVendor {
+		return r.EntityID, true
+	}
+	vendorID, ok := r.Metadata["vendor_id"].(uuid.UUID)
+	return vendorID, ok && vendorID != uuid.Nil
+}
+
+// calculateSimilarity scores how alike two recommendations are, from 0 to 1
+func (d *Diversifier) calculateSimilarity(a, b Recommendation) float64 {
+	sim := 0.0
+	
+	// Same category = high similarity
 	if aCat, ok := a.Metadata["category_id"].(uuid.UUID); ok {
 		if bCat, ok := b.Metadata["category_id"].(uuid.UUID); ok {
 			if aCat == bCat {
@@ -1277,9 +2485,16 @@ func (d *Diversifier) calculateSimilarity(a, b Recommendation) float64 {
 		}
 	}
 	
+	// Same vendor = high similarity
+	if aVendor, ok := recommendationVendor(a); ok {
+		if bVendor, ok := recommendationVendor(b); ok && aVendor == bVendor {
+			sim += 0.3
+		}
+	}
+	
 	// Same source type = some similarity
 	if a.Type == b.Type {
-		sim += 0.3
+		sim += 0.2
 	}
 	
 	return sim
@@ -1289,10 +2504,252 @@ func (d *Diversifier) assignPositions(recs []Recommendation) []Recommendation {
 	for i := range recs {
 		recs[i].Position = i + 1
 		recs[i].DiversityScore = 1.0 - float64(i)/float64(len(recs))
+		if f := recs[i].Factors; f != nil {
+			f.ServedPosition = i + 1
+		}
+	}
+	return recs
+}
+
+// =============================================================================
+// EXPLANATIONS
+// =============================================================================
+
+// ErrExplanationNotFound is returned when a recommendation was never served,
+// its impression was not recorded, or it was served before score factors
+// were logged
+var ErrExplanationNotFound = errors.New("no recorded explanation for recommendation")
+
+// ScoreFactors records how a recommendation's score was put together. It is
+// logged with the impression so the recommendation can be explained later.
+type ScoreFactors struct {
+	BaseScore             float64 `json:"base_score"`
+	SourceWeight          float64 `json:"source_weight"`
+	PersonalizationBoost  float64 `json:"personalization_boost"`
+	PersonalizationWeight float64 `json:"personalization_weight"`
+	Relevance             float64 `json:"relevance"`
+	RelevanceWeight       float64 `json:"relevance_weight"`
+	RecencyBoost          float64 `json:"recency_boost"`
+	RecencyWeight         float64 `json:"recency_weight"`
+	PipelineScore         float64 `json:"pipeline_score"` // clamped to 0-1, before blending
+	
+	CollaborativeScore float64       `json:"collaborative_score,omitempty"`
+	ContentScore       float64       `json:"content_score,omitempty"`
+	Blend              *BlendWeights `json:"blend,omitempty"` // nil when no blending happened
+	FreshnessFactor    float64       `json:"freshness_factor,omitempty"`
+	FinalScore         float64       `json:"final_score"`
+	
+	MatchedSignals []string `json:"matched_signals"`
+	
+	RankedPosition int     `json:"ranked_position"` // by score alone
+	ServedPosition int     `json:"served_position"` // after diversification
+	MaxSimilarity  float64 `json:"max_similarity"`  // to the items served above it
+}
+
+// ScoreComponent is one weighted term of the pipeline score
+type ScoreComponent struct {
+	Name         string  `json:"name"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// BlendBreakdown shows how the pipeline score was blended with the
+// collaborative and content signals
+type BlendBreakdown struct {
+	Weights            BlendWeights `json:"weights"`
+	PipelineScore      float64      `json:"pipeline_score"`
+	CollaborativeScore float64      `json:"collaborative_score"`
+	ContentScore       float64      `json:"content_score"`
+}
+
+// DiversityAdjustment shows how diversification moved a recommendation.
+// A positive PositionsMoved means it was served higher than its score alone
+// would have placed it.
+type DiversityAdjustment struct {
+	RankedPosition int     `json:"ranked_position"`
+	ServedPosition int     `json:"served_position"`
+	PositionsMoved int     `json:"positions_moved"`
+	MaxSimilarity  float64 `json:"max_similarity"`
+}
+
+// Explanation is a detailed breakdown of why a recommendation appeared
+type Explanation struct {
+	RecommendationID uuid.UUID           `json:"recommendation_id"`
+	Type             RecommendationType  `json:"type"`
+	EntityType       EntityType          `json:"entity_type"`
+	EntityID         uuid.UUID           `json:"entity_id"`
+	Score            float64             `json:"score"`
+	PipelineScore    float64             `json:"pipeline_score"`
+	Components       []ScoreComponent    `json:"components"`
+	Blend            *BlendBreakdown     `json:"blend,omitempty"`
+	FreshnessFactor  float64             `json:"freshness_factor,omitempty"`
+	MatchedSignals   []string            `json:"matched_signals"`
+	Diversity        DiversityAdjustment `json:"diversity"`
+	ServedAt         time.Time           `json:"served_at,omitempty"`
+}
+
+// Explain breaks a served recommendation down into the factors recorded when
+// it was scored
+func Explain(rec Recommendation) (*Explanation, error) {
+	f := rec.Factors
+	if f == nil {
+		return nil, ErrExplanationNotFound
+	}
+	
+	component := func(name string, value, weight float64) ScoreComponent {
+		return ScoreComponent{Name: name, Value: value, Weight: weight, Contribution: value * weight}
+	}
+	
+	exp := &Explanation{
+		RecommendationID: rec.ID,
+		Type:             rec.Type,
+		EntityType:       rec.EntityType,
+		EntityID:         rec.EntityID,
+		Score:            f.FinalScore,
+		PipelineScore:    f.PipelineScore,
+		Components: []ScoreComponent{
+			component("source", f.BaseScore, f.SourceWeight),
+			component("personalization", f.PersonalizationBoost, f.PersonalizationWeight),
+			component("relevance", f.Relevance, f.RelevanceWeight),
+			component("recency", f.RecencyBoost, f.RecencyWeight),
+		},
+		FreshnessFactor: f.FreshnessFactor,
+		MatchedSignals:  f.MatchedSignals,
+		Diversity: DiversityAdjustment{
+			RankedPosition: f.RankedPosition,
+			ServedPosition: f.ServedPosition,
+			PositionsMoved: f.RankedPosition - f.ServedPosition,
+			MaxSimilarity:  f.MaxSimilarity,
+		},
+	}
+	if f.Blend != nil {
+		exp.Blend = &BlendBreakdown{
+			Weights:            *f.Blend,
+			PipelineScore:      f.PipelineScore,
+			CollaborativeScore: f.CollaborativeScore,
+			ContentScore:       f.ContentScore,
+		}
+	}
+	return exp, nil
+}
+
+// Breakdown splits a recommendation's score into what each factor
+// contributed, keyed by factor. The values sum to the score: the pipeline
+// terms are scaled by the blend weight they were blended with, and clamping
+// and freshness appear as adjustments. Unscored recommendations, such as
+// degraded-mode ones, have no breakdown.
+func Breakdown(rec Recommendation) map[string]float64 {
+	f := rec.Factors
+	if f == nil {
+		return nil
+	}
+	
+	breakdown := make(map[string]float64)
+	add := func(name string, value float64) {
+		if value != 0 {
+			breakdown[name] += value
+		}
+	}
+	
+	// Share of the final score the pipeline terms kept through blending
+	scale := 1.0
+	score := f.PipelineScore
+	if f.Blend != nil {
+		total := f.Blend.Base + f.Blend.Collaborative + f.Blend.Content
+		if total > 0 {
+			scale = f.Blend.Base / total
+			add("collaborative_signal", f.Blend.Collaborative*f.CollaborativeScore/total)
+			add("content_signal", f.Blend.Content*f.ContentScore/total)
+			score = scale*f.PipelineScore +
+				(f.Blend.Collaborative*f.CollaborativeScore+f.Blend.Content*f.ContentScore)/total
+		}
+	}
+	
+	raw := 0.0
+	for _, term := range []struct {
+		name          string
+		value, weight float64
+	}{
+		{sourceFactor(rec.Type), f.BaseScore, f.SourceWeight},
+		{"personalization", f.PersonalizationBoost, f.PersonalizationWeight},
+		{"relevance", f.Relevance, f.RelevanceWeight},
+		{"recency", f.RecencyBoost, f.RecencyWeight},
+	} {
+		raw += term.value * term.weight
+		add(term.name, term.value*term.weight*scale)
+	}
+	// The pipeline score is clamped to 0-1
+	add("clamp", (f.PipelineScore-raw)*scale)
+	
+	if f.FreshnessFactor != 0 {
+		add("freshness", f.FinalScore-score)
+	}
+	return breakdown
+}
+
+// sourceFactor names the breakdown entry for a recommendation source
+func sourceFactor(source RecommendationType) string {
+	switch source {
+	case AdjacentService, ContextualUpsell:
+		return "adjacency"
+	case EventBasedSuggest:
+		return "event_fit"
+	case CollaborativeFilter:
+		return "similar_users"
+	case TrendingService:
+		return "trending"
+	}
+	return "source"
+}
+
+// AttachBreakdowns fills in each recommendation's ScoreBreakdown when
+// explain is set, and leaves it out otherwise to keep payloads small
+func AttachBreakdowns(recs []Recommendation, explain bool) []Recommendation {
+	for i := range recs {
+		recs[i].ScoreBreakdown = nil
+		if explain {
+			recs[i].ScoreBreakdown = Breakdown(recs[i])
+		}
 	}
 	return recs
 }
 
+// ExplainRecommendation explains a previously served recommendation from the
+// factors logged with its impression
+func (e *Engine) ExplainRecommendation(ctx context.Context, id uuid.UUID) (*Explanation, error) {
+	var (
+		rec      Recommendation
+		factors  []byte
+		servedAt time.Time
+	)
+	err := e.db.QueryRow(ctx, `
+		SELECT recommendation_type, recommended_entity_type, recommended_entity_id,
+		       score_factors, created_at
+		FROM recommendation_events
+		WHERE id = $1 AND was_impressed AND score_factors IS NOT NULL
+	`, id).Scan(&rec.Type, &rec.EntityType, &rec.EntityID, &factors, &servedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrExplanationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recommendation event: %w", err)
+	}
+	
+	rec.ID = id
+	rec.Factors = &ScoreFactors{}
+	if err := json.Unmarshal(factors, rec.Factors); err != nil {
+		return nil, fmt.Errorf("failed to decode score factors: %w", err)
+	}
+	
+	exp, err := Explain(rec)
+	if err != nil {
+		return nil, err
+	}
+	exp.ServedAt = servedAt
+	return exp, nil
+}
+
 // =============================================================================
 // SUPPORTING COMPONENTS
 // =============================================================================
@@ -1315,6 +2772,7 @@ type TrendingService struct {
 type TrendingItem struct {
 	ServiceID      uuid.UUID
 	CategoryID     uuid.UUID
+	VendorID       uuid.UUID
 	TrendScore     float64
 	ViewCount7D    int
 	BookingCount7D int
@@ -1349,6 +2807,7 @@ func (t *TrendingService) GetTrending(ctx context.Context, loc *GeoPoint, limit
 		SELECT 
 			s.id,
 			s.category_id,
+			s.vendor_id,
 			ra.views,
 			ra.bookings,
 			CASE WHEN COALESCE(pa.prev_interactions, 0) = 0 THEN 1.0
@@ -1375,7 +2834,7 @@ func (t *TrendingService) GetTrending(ctx context.Context, loc *GeoPoint, limit
 	maxScore := 0.0
 	for rows.Next() {
 		var item TrendingItem
-		if err := rows.Scan(&item.ServiceID, &item.CategoryID, 
+		if err := rows.Scan(&item.ServiceID, &item.CategoryID, &item.VendorID,
 			&item.ViewCount7D, &item.BookingCount7D, &item.GrowthRate); err != nil {
 			continue
 		}
@@ -1395,31 +2854,363 @@ func (t *TrendingService) GetTrending(ctx context.Context, loc *GeoPoint, limit
 	return items
 }
 
+// =============================================================================
+// FEEDBACK
+// =============================================================================
+
+// FeedbackAction is what a user did with a served recommendation
+type FeedbackAction string
+
+const (
+	FeedbackView    FeedbackAction = "view"
+	FeedbackClick   FeedbackAction = "click"
+	FeedbackDismiss FeedbackAction = "dismiss"
+	FeedbackConvert FeedbackAction = "convert"
+)
+
+// Feedback errors
+var (
+	ErrInvalidFeedbackAction  = errors.New("action must be one of view, click, dismiss or convert")
+	ErrRecommendationNotFound = errors.New("recommendation was never served")
+	ErrFeedbackEntityMismatch = errors.New("entity was not the one recommended")
+)
+
+// ParseFeedbackAction validates a feedback action
+func ParseFeedbackAction(action string) (FeedbackAction, error) {
+	switch a := FeedbackAction(strings.ToLower(strings.TrimSpace(action))); a {
+	case FeedbackView, FeedbackClick, FeedbackDismiss, FeedbackConvert:
+		return a, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidFeedbackAction, action)
+}
+
+// FeedbackRequest reports an action on a served recommendation
+type FeedbackRequest struct {
+	RecommendationID uuid.UUID `json:"recommendation_id" binding:"required"`
+	EntityID         uuid.UUID `json:"entity_id" binding:"required"`
+	Action           string    `json:"action" binding:"required"`
+}
+
+// Feedback is a recorded action on a recommendation, kept with the
+// algorithm version that served it for click-through reporting
+type Feedback struct {
+	ID               uuid.UUID      `json:"id"`
+	RecommendationID uuid.UUID      `json:"recommendation_id"`
+	UserID           uuid.UUID      `json:"user_id,omitempty"`
+	EntityID         uuid.UUID      `json:"entity_id"`
+	Action           FeedbackAction `json:"action"`
+	AlgorithmVersion string         `json:"algorithm_version"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+// ServedRecommendation is what was logged when a recommendation was served
+type ServedRecommendation struct {
+	UserID           uuid.UUID
+	EntityID         uuid.UUID
+	AlgorithmVersion string
+}
+
+// FeedbackStore looks up served recommendations and records feedback on them
+type FeedbackStore interface {
+	// ServedRecommendation returns ErrRecommendationNotFound for an id that
+	// was never served
+	ServedRecommendation(ctx context.Context, recommendationID uuid.UUID) (*ServedRecommendation, error)
+	SaveFeedback(ctx context.Context, feedback *Feedback) error
+}
+
+// SetFeedbackStore replaces where feedback is recorded
+func (e *Engine) SetFeedbackStore(store FeedbackStore) {
+	e.feedback = store
+}
+
+// RecordFeedback records that the user a recommendation was served to
+// viewed, clicked, dismissed or converted on it
+func (e *Engine) RecordFeedback(ctx context.Context, req FeedbackRequest) (*Feedback, error) {
+	action, err := ParseFeedbackAction(req.Action)
+	if err != nil {
+		return nil, err
+	}
+	if e.feedback == nil {
+		return nil, errors.New("feedback store not configured")
+	}
+	
+	served, err := e.feedback.ServedRecommendation(ctx, req.RecommendationID)
+	if err != nil {
+		return nil, err
+	}
+	if served.EntityID != req.EntityID {
+		return nil, ErrFeedbackEntityMismatch
+	}
+	
+	feedback := &Feedback{
+		ID:               uuid.New(),
+		RecommendationID: req.RecommendationID,
+		UserID:           served.UserID,
+		EntityID:         req.EntityID,
+		Action:           action,
+		AlgorithmVersion: served.AlgorithmVersion,
+		CreatedAt:        time.Now(),
+	}
+	if err := e.feedback.SaveFeedback(ctx, feedback); err != nil {
+		return nil, fmt.Errorf("failed to record feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+// PostgresFeedbackStore reads recommendation_events and writes
+// recommendation_feedback
+type PostgresFeedbackStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresFeedbackStore creates a feedback store backed by Postgres
+func NewPostgresFeedbackStore(db *pgxpool.Pool) *PostgresFeedbackStore {
+	return &PostgresFeedbackStore{db: db}
+}
+
+// ServedRecommendation loads the impression logged for a recommendation
+func (s *PostgresFeedbackStore) ServedRecommendation(ctx context.Context, recommendationID uuid.UUID) (*ServedRecommendation, error) {
+	var (
+		served  ServedRecommendation
+		userID  *uuid.UUID
+		version *string
+	)
+	err := s.db.QueryRow(ctx, `
+		SELECT user_id, recommended_entity_id, algorithm_version
+		FROM recommendation_events
+		WHERE id = $1
+	`, recommendationID).Scan(&userID, &served.EntityID, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRecommendationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recommendation event: %w", err)
+	}
+	if userID != nil {
+		served.UserID = *userID
+	}
+	if version != nil {
+		served.AlgorithmVersion = *version
+	}
+	return &served, nil
+}
+
+// SaveFeedback records feedback and marks clicks and conversions on the
+// recommendation's event
+func (s *PostgresFeedbackStore) SaveFeedback(ctx context.Context, feedback *Feedback) error {
+	var userID *uuid.UUID
+	if feedback.UserID != uuid.Nil {
+		userID = &feedback.UserID
+	}
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO recommendation_feedback
+		(id, recommendation_id, user_id, entity_id, action, algorithm_version, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, feedback.ID, feedback.RecommendationID, userID, feedback.EntityID,
+		feedback.Action, feedback.AlgorithmVersion, feedback.CreatedAt)
+	if err != nil {
+		return err
+	}
+	
+	switch feedback.Action {
+	case FeedbackClick:
+		_, err = s.db.Exec(ctx, `
+			UPDATE recommendation_events
+			SET was_clicked = TRUE, clicked_at = COALESCE(clicked_at, $2)
+			WHERE id = $1
+		`, feedback.RecommendationID, feedback.CreatedAt)
+	case FeedbackConvert:
+		_, err = s.db.Exec(ctx, `
+			UPDATE recommendation_events
+			SET was_converted = TRUE, converted_at = COALESCE(converted_at, $2)
+			WHERE id = $1
+		`, feedback.RecommendationID, feedback.CreatedAt)
+	}
+	return err
+}
+
+// MemoryFeedbackStore keeps served recommendations and feedback in memory,
+// for tests
+type MemoryFeedbackStore struct {
+	mu       sync.RWMutex
+	served   map[uuid.UUID]ServedRecommendation
+	feedback []Feedback
+}
+
+// NewMemoryFeedbackStore creates an empty in-memory feedback store
+func NewMemoryFeedbackStore() *MemoryFeedbackStore {
+	return &MemoryFeedbackStore{served: make(map[uuid.UUID]ServedRecommendation)}
+}
+
+// AddServed records that a recommendation was served
+func (s *MemoryFeedbackStore) AddServed(recommendationID uuid.UUID, served ServedRecommendation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.served[recommendationID] = served
+}
+
+// ServedRecommendation looks up a served recommendation
+func (s *MemoryFeedbackStore) ServedRecommendation(ctx context.Context, recommendationID uuid.UUID) (*ServedRecommendation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	served, ok := s.served[recommendationID]
+	if !ok {
+		return nil, ErrRecommendationNotFound
+	}
+	return &served, nil
+}
+
+// SaveFeedback records feedback
+func (s *MemoryFeedbackStore) SaveFeedback(ctx context.Context, feedback *Feedback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feedback = append(s.feedback, *feedback)
+	return nil
+}
+
+// Feedback returns the feedback recorded so far
+func (s *MemoryFeedbackStore) Feedback() []Feedback {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Feedback(nil), s.feedback...)
+}
+
+// =============================================================================
+// REQUEST VALIDATION
+// =============================================================================
+
+// Request limits
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// FieldError is a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field of a request that failed validation
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// Add records a problem with a field
+func (e *ValidationError) Add(field, message string) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: message})
+}
+
+// HasErrors reports whether any field failed
+func (e *ValidationError) HasErrors() bool {
+	return len(e.Errors) > 0
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validate checks every field and returns a *ValidationError listing all
+// that are out of bounds, or nil
+func (r *RecommendationRequest) Validate() error {
+	verr := &ValidationError{}
+	
+	if r.Limit < 1 || r.Limit > MaxLimit {
+		verr.Add("limit", fmt.Sprintf("must be between 1 and %d", MaxLimit))
+	}
+	if (r.CurrentEntityID == uuid.Nil) != (r.CurrentEntityType == "") {
+		verr.Add("current_entity", "current_entity_id and current_entity_type must be given together")
+	}
+	if r.Budget != nil {
+		if r.Budget.Min < 0 {
+			verr.Add("budget.min", "must not be negative")
+		}
+		if r.Budget.Max < 0 {
+			verr.Add("budget.max", "must not be negative")
+		}
+		if r.Budget.Max > 0 && r.Budget.Min > r.Budget.Max {
+			verr.Add("budget", "min must not exceed max")
+		}
+	}
+	if r.Location != nil {
+		if r.Location.Latitude < -90 || r.Location.Latitude > 90 {
+			verr.Add("location.latitude", "must be between -90 and 90")
+		}
+		if r.Location.Longitude < -180 || r.Location.Longitude > 180 {
+			verr.Add("location.longitude", "must be between -180 and 180")
+		}
+	}
+	if r.DiversityFactor < 0 || r.DiversityFactor > 1 {
+		verr.Add("diversity_factor", "must be between 0 and 1")
+	}
+	if r.Blend != nil {
+		if err := r.Blend.Validate(); err != nil {
+			verr.Add("blend", err.Error())
+		}
+	}
+	if r.Freshness != nil {
+		if err := r.Freshness.Validate(); err != nil {
+			verr.Add("freshness", err.Error())
+		}
+	}
+	if r.Cursor != "" {
+		if _, err := decodeCursor(r.Cursor); err != nil {
+			verr.Add("cursor", "is not a cursor from a previous page")
+		}
+	}
+	
+	if verr.HasErrors() {
+		return verr
+	}
+	return nil
+}
+
 // =============================================================================
 // ENGINE HELPER METHODS
 // =============================================================================
 
 func (e *Engine) validateRequest(req *RecommendationRequest) error {
-	if req.Limit < 0 || req.Limit > 100 {
-		return fmt.Errorf("limit must be between 0 and 100")
+	return ValidateRequest(req)
+}
+
+// ValidateRequest checks that a recommendation request is within bounds. A
+// zero limit is allowed here and means DefaultLimit.
+func ValidateRequest(req *RecommendationRequest) error {
+	if req == nil {
+		return fmt.Errorf("request is required")
 	}
-	if req.DiversityFactor < 0 || req.DiversityFactor > 1 {
-		return fmt.Errorf("diversity factor must be between 0 and 1")
+	if req.Limit == 0 {
+		withDefault := *req
+		withDefault.Limit = DefaultLimit
+		return withDefault.Validate()
 	}
-	return nil
+	return req.Validate()
 }
 
 func (e *Engine) buildUserContext(ctx context.Context, req *RecommendationRequest) (*UserContext, error) {
-	return e.userProfiler.BuildContext(ctx, req.UserID, req.SessionID)
+	ctx, span := tracing.Start(ctx, "recommendation.build_user_context",
+		attribute.String("user.id", req.UserID.String()),
+	)
+	userCtx, err := e.userProfiler.BuildContext(ctx, req.UserID, req.SessionID)
+	tracing.End(span, err)
+	return userCtx, err
 }
 
+// selectGenerators returns the generators run outside the candidate pool:
+// the user's own, trending, and events detected for the user when the
+// request names none
 func (e *Engine) selectGenerators(req *RecommendationRequest) []CandidateGenerator {
 	generators := []CandidateGenerator{
-		&AdjacencyGenerator{graph: e.adjacencyGraph, db: e.db},
-		&EventBasedGenerator{db: e.db, eventDetector: e.eventDetector},
 		&CollaborativeGenerator{db: e.db, cache: e.cache},
 		&TrendingGenerator{service: e.trendingService},
 	}
+	if req.EventType == "" {
+		generators = append(generators, &EventBasedGenerator{db: e.db, eventDetector: e.eventDetector})
+	}
 	
 	// Could filter based on req.RequestedTypes
 	return generators
@@ -1440,20 +3231,28 @@ func (e *Engine) deduplicateCandidates(candidates []Candidate) []Candidate {
 }
 
 func (e *Engine) logRecommendations(ctx context.Context, req *RecommendationRequest, resp *RecommendationResponse) {
-	// Insert recommendation events for analytics
+	// Runs after the response is sent, so don't inherit its cancellation
+	ctx = context.WithoutCancel(ctx)
+	
+	// Insert recommendation events for analytics, keyed by the id clients
+	// see so impressions, clicks and explanations can find them
 	for _, rec := range resp.Recommendations {
+		var factors []byte
+		if rec.Factors != nil {
+			factors, _ = json.Marshal(rec.Factors)
+		}
 		_, _ = e.db.Exec(ctx, `
 			INSERT INTO recommendation_events 
-			(user_id, session_id, recommendation_type, algorithm_version,
+			(id, user_id, session_id, recommendation_type, algorithm_version,
 			 recommended_entity_type, recommended_entity_id,
 			 source_entity_type, source_entity_id, position, total_recommendations,
-			 relevance_score, diversity_score, experiment_id, variant)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			 relevance_score, diversity_score, experiment_id, variant, score_factors)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		`,
-			req.UserID, req.SessionID, rec.Type, resp.AlgorithmVersion,
+			rec.ID, req.UserID, req.SessionID, rec.Type, resp.AlgorithmVersion,
 			rec.EntityType, rec.EntityID,
 			req.CurrentEntityType, req.CurrentEntityID, rec.Position, len(resp.Recommendations),
-			rec.RelevanceScore, rec.DiversityScore, resp.ExperimentID, resp.Variant,
+			rec.RelevanceScore, rec.DiversityScore, resp.ExperimentID, resp.Variant, factors,
 		)
 	}
 }
@@ -1464,6 +3263,7 @@ func (e *Engine) backgroundRefresh() {
 	
 	for range ticker.C {
 		ctx := context.Background()
+		e.refreshPopular(ctx)
 		if err := e.adjacencyGraph.Load(ctx); err != nil {
 			// Log error
 			continue
@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Login Lockout
+
+func newLoginLimiter(t *testing.T) (*auth.LoginLimiter, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	limiter := auth.NewLoginLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}), &auth.Config{
+		MaxFailedLogins:    3,
+		FailedLoginWindow:  15 * time.Minute,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: 5 * time.Minute,
+	})
+	return limiter, mr
+}
+
+// failUntilLocked fails logins until one triggers a lockout
+func failUntilLocked(t *testing.T, limiter *auth.LoginLimiter, identifier string) *auth.AccountLockedError {
+	t.Helper()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		require.NoError(t, limiter.Fail(ctx, identifier))
+	}
+	var locked *auth.AccountLockedError
+	require.True(t, errors.As(limiter.Fail(ctx, identifier), &locked))
+	return locked
+}
+
+func TestLoginLimiter_LocksAfterMaxFailures(t *testing.T) {
+	limiter, _ := newLoginLimiter(t)
+	ctx := context.Background()
+
+	require.NoError(t, limiter.Check(ctx, "ada@example.com"))
+	locked := failUntilLocked(t, limiter, "ada@example.com")
+	assert.Equal(t, time.Minute, locked.RetryAfter)
+
+	err := limiter.Check(ctx, "ada@example.com")
+	assert.ErrorIs(t, err, auth.ErrAccountLocked)
+	var checked *auth.AccountLockedError
+	require.True(t, errors.As(err, &checked))
+	assert.InDelta(t, time.Minute, checked.RetryAfter, float64(time.Second))
+
+	// Other identifiers are unaffected
+	assert.NoError(t, limiter.Check(ctx, "grace@example.com"))
+}
+
+func TestLoginLimiter_RetryAfterGrows(t *testing.T) {
+	limiter, mr := newLoginLimiter(t)
+	ctx := context.Background()
+
+	var retryAfters []time.Duration
+	for i := 0; i < 4; i++ {
+		locked := failUntilLocked(t, limiter, "ada@example.com")
+		retryAfters = append(retryAfters, locked.RetryAfter)
+		mr.FastForward(locked.RetryAfter)
+		require.NoError(t, limiter.Check(ctx, "ada@example.com"))
+	}
+
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute}, retryAfters)
+}
+
+func TestLoginLimiter_FailuresOutsideWindowDoNotLock(t *testing.T) {
+	limiter, mr := newLoginLimiter(t)
+	ctx := context.Background()
+
+	require.NoError(t, limiter.Fail(ctx, "ada@example.com"))
+	require.NoError(t, limiter.Fail(ctx, "ada@example.com"))
+	mr.FastForward(16 * time.Minute)
+
+	assert.NoError(t, limiter.Fail(ctx, "ada@example.com"))
+	assert.NoError(t, limiter.Check(ctx, "ada@example.com"))
+}
+
+func TestLoginLimiter_ResetOnSuccess(t *testing.T) {
+	limiter, _ := newLoginLimiter(t)
+	ctx := context.Background()
+
+	failUntilLocked(t, limiter, "ada@example.com")
+	require.NoError(t, limiter.Reset(ctx, "ada@example.com"))
+	assert.NoError(t, limiter.Check(ctx, "ada@example.com"))
+
+	// The count starts over, and so does the lockout length
+	locked := failUntilLocked(t, limiter, "ada@example.com")
+	assert.Equal(t, time.Minute, locked.RetryAfter)
+}
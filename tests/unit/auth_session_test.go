@@ -0,0 +1,199 @@
+package unit
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Refresh Token Rotation
+
+// memorySessionStore holds users, sessions and rotated-out refresh tokens in
+// process
+type memorySessionStore struct {
+	mu       sync.Mutex
+	users    map[uuid.UUID]auth.User
+	sessions map[uuid.UUID]*auth.Session
+	rotated  map[string]uuid.UUID // token to session
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{
+		users:    make(map[uuid.UUID]auth.User),
+		sessions: make(map[uuid.UUID]*auth.Session),
+		rotated:  make(map[string]uuid.UUID),
+	}
+}
+
+func (m *memorySessionStore) AddUser(user auth.User) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users[user.ID] = user
+}
+
+// Sessions lists a user's sessions, oldest first
+func (m *memorySessionStore) Sessions(userID uuid.UUID) []auth.Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.userSessions(userID)
+}
+
+func (m *memorySessionStore) userSessions(userID uuid.UUID) []auth.Session {
+	var sessions []auth.Session
+	for _, session := range m.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, *session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions
+}
+
+func (m *memorySessionStore) CreateSession(ctx context.Context, session *auth.Session, maxSessions int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if maxSessions > 0 {
+		existing := m.userSessions(session.UserID)
+		for len(existing) >= maxSessions {
+			m.revoke(existing[0].ID)
+			existing = existing[1:]
+		}
+	}
+	stored := *session
+	m.sessions[session.ID] = &stored
+	return nil
+}
+
+func (m *memorySessionStore) RotateRefreshToken(ctx context.Context, presented, next string, now time.Time) (*auth.Session, *auth.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if sessionID, ok := m.rotated[presented]; ok {
+		return &auth.Session{ID: sessionID}, nil, auth.ErrRefreshTokenReused
+	}
+	for _, session := range m.sessions {
+		if session.RefreshToken != presented {
+			continue
+		}
+		user, ok := m.users[session.UserID]
+		if !ok || !now.Before(session.ExpiresAt) {
+			return nil, nil, auth.ErrInvalidRefreshToken
+		}
+		m.rotated[presented] = session.ID
+		session.RefreshToken = next
+		rotated := *session
+		return &rotated, &user, nil
+	}
+	return nil, nil, auth.ErrInvalidRefreshToken
+}
+
+func (m *memorySessionStore) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revoke(sessionID)
+	return nil
+}
+
+func (m *memorySessionStore) revoke(sessionID uuid.UUID) {
+	delete(m.sessions, sessionID)
+	for token, id := range m.rotated {
+		if id == sessionID {
+			delete(m.rotated, token)
+		}
+	}
+}
+
+func newSessionService(maxSessions int) (*auth.Service, *memorySessionStore, auth.User) {
+	service := auth.NewService(nil, nil, &auth.Config{
+		JWTSecret:          "test-secret",
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+		MaxSessionsPerUser: maxSessions,
+	})
+	store := newMemorySessionStore()
+	service.SetSessionStore(store)
+	user := auth.User{
+		ID:     uuid.New(),
+		Email:  "ada@example.com",
+		Role:   auth.RoleCustomer,
+		Status: auth.StatusActive,
+	}
+	store.AddUser(user)
+	return service, store, user
+}
+
+func TestRefreshTokens_Rotates(t *testing.T) {
+	service, _, user := newSessionService(5)
+	ctx := context.Background()
+
+	first, err := service.IssueSession(ctx, user, "iPhone", "10.0.0.1", "app/1.0")
+	require.NoError(t, err)
+	second, err := service.RefreshTokens(ctx, first.RefreshToken)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	firstClaims, err := service.ValidateToken(first.AccessToken)
+	require.NoError(t, err)
+	secondClaims, err := service.ValidateToken(second.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, firstClaims.SessionID, secondClaims.SessionID)
+	assert.Equal(t, user.ID, secondClaims.UserID)
+
+	// The new refresh token rotates in turn
+	_, err = service.RefreshTokens(ctx, second.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestRefreshTokens_ReuseRevokesSession(t *testing.T) {
+	service, store, user := newSessionService(5)
+	ctx := context.Background()
+
+	first, err := service.IssueSession(ctx, user, "iPhone", "10.0.0.1", "app/1.0")
+	require.NoError(t, err)
+	other, err := service.IssueSession(ctx, user, "Laptop", "10.0.0.2", "web")
+	require.NoError(t, err)
+	second, err := service.RefreshTokens(ctx, first.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = service.RefreshTokens(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+
+	// The token rotated in after the stolen one no longer works either
+	_, err = service.RefreshTokens(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+
+	// Other sessions are untouched
+	require.Len(t, store.Sessions(user.ID), 1)
+	_, err = service.RefreshTokens(ctx, other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestIssueSession_EvictsOldestOverLimit(t *testing.T) {
+	service, store, user := newSessionService(2)
+	ctx := context.Background()
+
+	oldest, err := service.IssueSession(ctx, user, "Tablet", "10.0.0.1", "app/1.0")
+	require.NoError(t, err)
+	_, err = service.IssueSession(ctx, user, "iPhone", "10.0.0.2", "app/1.0")
+	require.NoError(t, err)
+	_, err = service.IssueSession(ctx, user, "Laptop", "10.0.0.3", "web")
+	require.NoError(t, err)
+
+	sessions := store.Sessions(user.ID)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "iPhone", sessions[0].DeviceInfo)
+	assert.Equal(t, "Laptop", sessions[1].DeviceInfo)
+
+	_, err = service.RefreshTokens(ctx, oldest.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+}
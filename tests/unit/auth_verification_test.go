@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Email Verification
+
+// memoryVerificationStore holds tokens and verified users in process
+type memoryVerificationStore struct {
+	mu            sync.Mutex
+	verifications map[string]*auth.Verification
+	verified      map[uuid.UUID]bool
+}
+
+func newMemoryVerificationStore() *memoryVerificationStore {
+	return &memoryVerificationStore{
+		verifications: make(map[string]*auth.Verification),
+		verified:      make(map[uuid.UUID]bool),
+	}
+}
+
+func (m *memoryVerificationStore) Verified(userID uuid.UUID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.verified[userID]
+}
+
+func (m *memoryVerificationStore) SaveVerification(ctx context.Context, verification *auth.Verification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *verification
+	m.verifications[verification.TokenHash] = &stored
+	return nil
+}
+
+func (m *memoryVerificationStore) ConsumeVerification(ctx context.Context, tokenHash string, now time.Time) (*auth.Verification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	verification, ok := m.verifications[tokenHash]
+	if !ok {
+		return nil, auth.ErrInvalidVerificationToken
+	}
+	if err := auth.CheckVerificationUsable(verification, now); err != nil {
+		return nil, err
+	}
+	verification.UsedAt = &now
+	m.verified[verification.UserID] = true
+	consumed := *verification
+	return &consumed, nil
+}
+
+func newVerificationService(expiry time.Duration) (*auth.Service, *memoryVerificationStore) {
+	service := auth.NewService(nil, nil, &auth.Config{VerificationExpiry: expiry})
+	store := newMemoryVerificationStore()
+	service.SetVerificationStore(store)
+	return service, store
+}
+
+func TestVerifyEmail_Succeeds(t *testing.T) {
+	service, store := newVerificationService(24 * time.Hour)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	token, err := service.IssueVerification(ctx, userID)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	require.NoError(t, service.VerifyEmail(ctx, token))
+	assert.True(t, store.Verified(userID))
+}
+
+func TestVerifyEmail_ExpiredToken(t *testing.T) {
+	service, store := newVerificationService(time.Nanosecond)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	token, err := service.IssueVerification(ctx, userID)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	assert.ErrorIs(t, service.VerifyEmail(ctx, token), auth.ErrVerificationTokenExpired)
+	assert.False(t, store.Verified(userID))
+}
+
+func TestVerifyEmail_ReusedToken(t *testing.T) {
+	service, _ := newVerificationService(24 * time.Hour)
+	ctx := context.Background()
+
+	token, err := service.IssueVerification(ctx, uuid.New())
+	require.NoError(t, err)
+	require.NoError(t, service.VerifyEmail(ctx, token))
+
+	assert.ErrorIs(t, service.VerifyEmail(ctx, token), auth.ErrVerificationTokenUsed)
+	assert.ErrorIs(t, service.VerifyEmail(ctx, "not-a-token"), auth.ErrInvalidVerificationToken)
+}
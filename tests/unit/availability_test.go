@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	eventgptapi "github.com/BillyRonksGlobal/vendorplatform/api/eventgpt"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/availability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Vendor Availability
+
+// at is a time on the week of Monday 1 January 2024
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+}
+
+func span(day, startHour, endHour int) availability.Interval {
+	return availability.Interval{Start: at(day, startHour, 0), End: at(day, endHour, 0)}
+}
+
+func weekdayVendor(maxConcurrent int) availability.Vendor {
+	hours, err := availability.ParseWorkingHours([]byte(`{
+		"monday": {"open": "09:00", "close": "17:00"},
+		"tuesday": {"open": "09:00", "close": "17:00"},
+		"wednesday": {"open": "09:00", "close": "17:00"},
+		"thursday": {"open": "09:00", "close": "17:00"},
+		"friday": {"open": "09:00", "close": "17:00"}
+	}`))
+	if err != nil {
+		panic(err)
+	}
+	return availability.Vendor{MaxConcurrent: maxConcurrent, Buffer: 30 * time.Minute, Hours: hours}
+}
+
+func TestCheckAvailability_FreeSlot(t *testing.T) {
+	vendor := weekdayVendor(2)
+	booked := []availability.Interval{span(1, 9, 11)}
+
+	result := availability.Check(vendor, booked, span(1, 12, 14))
+	assert.True(t, result.Available)
+	assert.Equal(t, 2, result.Remaining)
+	assert.Empty(t, result.Slots)
+
+	// The buffer after the morning booking runs into an 11:00 start
+	result = availability.Check(vendor, booked, span(1, 11, 13))
+	assert.True(t, result.Available)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestCheckAvailability_OverCapacity(t *testing.T) {
+	vendor := weekdayVendor(2)
+	booked := []availability.Interval{span(1, 10, 12), span(1, 11, 15)}
+
+	result := availability.Check(vendor, booked, span(1, 11, 13))
+
+	assert.False(t, result.Available)
+	assert.Equal(t, availability.ReasonFullyBooked, result.Reason)
+	assert.Zero(t, result.Remaining)
+	require.Len(t, result.Slots, availability.MaxSlots)
+	assert.Equal(t, availability.Interval{Start: at(1, 12, 30), End: at(1, 14, 30)}, result.Slots[0])
+}
+
+func TestCheckAvailability_WholeDayOverCapacity(t *testing.T) {
+	vendor := weekdayVendor(1)
+	booked := []availability.Interval{{Start: at(1, 0, 0), End: at(2, 0, 0)}}
+
+	result := availability.Check(vendor, booked, availability.Interval{Start: at(1, 0, 0), End: at(2, 0, 0)})
+
+	assert.False(t, result.Available)
+	assert.Equal(t, availability.ReasonFullyBooked, result.Reason)
+	assert.Equal(t, span(1, 9, 17), result.Requested)
+	require.NotEmpty(t, result.Slots)
+	assert.Equal(t, span(2, 9, 17), result.Slots[0])
+}
+
+func TestCheckAvailability_OutsideWorkingHours(t *testing.T) {
+	vendor := weekdayVendor(2)
+
+	result := availability.Check(vendor, nil, span(1, 16, 18))
+	assert.False(t, result.Available)
+	assert.Equal(t, availability.ReasonOutsideHours, result.Reason)
+	require.NotEmpty(t, result.Slots)
+	assert.Equal(t, span(1, 9, 11), result.Slots[0])
+
+	// Sunday is a day off; the next opening is Monday morning
+	result = availability.Check(vendor, nil, span(7, 10, 12))
+	assert.False(t, result.Available)
+	assert.Equal(t, availability.ReasonOutsideHours, result.Reason)
+	require.NotEmpty(t, result.Slots)
+	assert.Equal(t, span(8, 9, 11), result.Slots[0])
+}
+
+func TestParseWorkingHours(t *testing.T) {
+	hours, err := availability.ParseWorkingHours([]byte(`{"Saturday": {"open": "10:00", "close": "24:00"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, availability.WorkingHours{
+		time.Saturday: {Open: 10 * time.Hour, Close: 24 * time.Hour},
+	}, hours)
+
+	_, err = availability.ParseWorkingHours([]byte(`{"monday": {"open": "17:00", "close": "09:00"}}`))
+	assert.Error(t, err)
+	_, err = availability.ParseWorkingHours([]byte(`{"someday": {"open": "09:00", "close": "17:00"}}`))
+	assert.Error(t, err)
+}
+
+func TestDescribeAvailability(t *testing.T) {
+	status, _ := eventgptapi.DescribeAvailability(availability.Result{Available: true, Remaining: 3})
+	assert.Equal(t, "available", status)
+
+	status, _ = eventgptapi.DescribeAvailability(availability.Result{Available: true, Remaining: 1})
+	assert.Equal(t, "limited", status)
+
+	status, message := eventgptapi.DescribeAvailability(availability.Result{
+		Reason: availability.ReasonFullyBooked,
+		Slots:  []availability.Interval{span(2, 9, 17)},
+	})
+	assert.Equal(t, "unavailable", status)
+	assert.Contains(t, message, "Tuesday 2 Jan at 09:00")
+}
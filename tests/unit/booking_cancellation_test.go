@@ -0,0 +1,184 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/booking"
+	"github.com/BillyRonksGlobal/vendorplatform/internal/payment"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/cancellation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Cancellation Policies
+
+func TestCancellationPolicy_TierBoundaries(t *testing.T) {
+	start := time.Date(2024, time.March, 15, 14, 0, 0, 0, time.UTC)
+	policy := cancellation.DefaultPolicy
+
+	tests := []struct {
+		name    string
+		notice  time.Duration
+		percent float64
+		amount  float64
+	}{
+		{"a week out", 7 * 24 * time.Hour, 100, 80000},
+		{"just under a week", 7*24*time.Hour - time.Minute, 50, 40000},
+		{"a day out", 24 * time.Hour, 50, 40000},
+		{"just under a day", 24*time.Hour - time.Minute, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := policy.Refund(80000, start, start.Add(-tt.notice), cancellation.ActorCustomer)
+			assert.Equal(t, tt.percent, quote.RefundPercent)
+			assert.Equal(t, tt.amount, quote.RefundAmount)
+		})
+	}
+}
+
+func TestCancellationPolicy_SameDayRefundsNothing(t *testing.T) {
+	start := time.Date(2024, time.March, 15, 14, 0, 0, 0, time.UTC)
+
+	quote := cancellation.DefaultPolicy.Refund(80000, start, start.Add(-3*time.Hour), cancellation.ActorCustomer)
+	assert.Zero(t, quote.RefundPercent)
+	assert.Zero(t, quote.RefundAmount)
+
+	// Cancelling after the start refunds nothing either
+	quote = cancellation.DefaultPolicy.Refund(80000, start, start.Add(time.Hour), cancellation.ActorCustomer)
+	assert.Zero(t, quote.RefundAmount)
+
+	// A vendor cancelling on the day refunds in full
+	quote = cancellation.DefaultPolicy.Refund(80000, start, start.Add(-3*time.Hour), cancellation.ActorVendor)
+	assert.Equal(t, 80000.0, quote.RefundAmount)
+}
+
+func TestCancellationPolicy_Parse(t *testing.T) {
+	policy, err := cancellation.ParsePolicy([]byte(`{"tiers": [
+		{"min_notice_hours": 48, "refund_percent": 50},
+		{"min_notice_hours": 336, "refund_percent": 100},
+		{"min_notice_hours": 0, "refund_percent": 10}
+	]}`))
+	require.NoError(t, err)
+	start := time.Date(2024, time.March, 15, 14, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 100.0, policy.Refund(333.33, start, start.Add(-14*24*time.Hour), cancellation.ActorCustomer).RefundPercent)
+	assert.Equal(t, 166.67, policy.Refund(333.33, start, start.Add(-48*time.Hour), cancellation.ActorCustomer).RefundAmount)
+	assert.Equal(t, 10.0, policy.Refund(333.33, start, start.Add(-time.Hour), cancellation.ActorCustomer).RefundPercent)
+
+	empty, err := cancellation.ParsePolicy(nil)
+	require.NoError(t, err)
+	assert.Equal(t, cancellation.DefaultPolicy, empty)
+
+	_, err = cancellation.ParsePolicy([]byte(`{"tiers": [{"min_notice_hours": 24, "refund_percent": 150}]}`))
+	assert.Error(t, err)
+}
+
+func TestRefundBooking_RefundsTheBookingsPayment(t *testing.T) {
+	service, store, gateway, _ := newRefundService()
+	bookingID := uuid.New()
+	paid := capturedPayment()
+	paid.BookingID = &bookingID
+	store.AddPayment(paid)
+	store.AddPayment(capturedPayment())
+
+	refund, err := service.RefundBooking(context.Background(), bookingID, uuid.New(), 25000, "Cancelled: change of plans")
+
+	require.NoError(t, err)
+	assert.Equal(t, paid.ID, refund.PaymentID)
+	assert.Equal(t, []int64{2500000}, gateway.calls)
+
+	_, err = service.RefundBooking(context.Background(), uuid.New(), uuid.New(), 25000, "Cancelled")
+	assert.ErrorIs(t, err, payment.ErrPaymentNotFound)
+}
+
+func TestRefundBooking_RetriesUnderOneRefundIDRefundOnce(t *testing.T) {
+	service, store, gateway, _ := newRefundService()
+	bookingID := uuid.New()
+	paid := capturedPayment()
+	paid.BookingID = &bookingID
+	store.AddPayment(paid)
+	refundID := uuid.New()
+	ctx := context.Background()
+
+	// The provider's answer is lost, so the refund is pending until
+	// reconciled, and retrying meanwhile sends nothing
+	gateway.err = errors.New("connection reset")
+	_, err := service.RefundBooking(ctx, bookingID, refundID, 25000, "Cancelled")
+	assert.ErrorIs(t, err, payment.ErrRefundPending)
+	gateway.err = nil
+	_, err = service.RefundBooking(ctx, bookingID, refundID, 25000, "Cancelled")
+	assert.ErrorIs(t, err, payment.ErrRefundPending)
+	assert.Len(t, gateway.calls, 1)
+
+	// Reconciled as never made, it is tried again under the same ID
+	store.Backdate(time.Hour)
+	_, err = service.ReconcilePendingRefunds(ctx)
+	require.NoError(t, err)
+	refund, err := service.RefundBooking(ctx, bookingID, refundID, 25000, "Cancelled")
+	require.NoError(t, err)
+	assert.Equal(t, refundID, refund.ID)
+
+	again, err := service.RefundBooking(ctx, bookingID, refundID, 25000, "Cancelled")
+	require.NoError(t, err)
+	assert.Equal(t, payment.RefundCompleted, again.Status)
+	assert.Len(t, gateway.calls, 2)
+	assert.Len(t, store.Refunds(paid.ID), 1)
+}
+
+func TestSettleCancelledEscrow_PaysTheVendorWhatWasNotRefunded(t *testing.T) {
+	refunds, store, _, _ := newRefundService()
+	service, escrows := newEscrowService()
+	store.escrows = escrows
+	escrow := heldEscrow(time.Hour)
+	escrows.AddEscrow(escrow)
+	paid := capturedPayment()
+	paid.BookingID = &escrow.BookingID
+	store.AddPayment(paid)
+	ctx := context.Background()
+
+	_, err := refunds.RefundBooking(ctx, escrow.BookingID, uuid.New(), 40000, "Cancelled")
+	require.NoError(t, err)
+	release, err := service.SettleCancelledEscrow(ctx, escrow.BookingID)
+
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	assert.Equal(t, int64(1000000), release.Amount)
+	assert.Equal(t, int64(900000), escrows.Balance(escrow.VendorID, "NGN"))
+
+	// Fully refunded, there is nothing left to pay out
+	refunded := heldEscrow(time.Hour)
+	escrows.AddEscrow(refunded)
+	full := capturedPayment()
+	full.BookingID = &refunded.BookingID
+	store.AddPayment(full)
+	_, err = refunds.RefundBooking(ctx, refunded.BookingID, uuid.New(), 50000, "Cancelled")
+	require.NoError(t, err)
+	release, err = service.SettleCancelledEscrow(ctx, refunded.BookingID)
+	require.NoError(t, err)
+	assert.Nil(t, release)
+	assert.Zero(t, escrows.Balance(refunded.VendorID, "NGN"))
+}
+
+func TestCancellationActor_FollowsWhoCancels(t *testing.T) {
+	customerID := uuid.New()
+	vendorUserID := uuid.New()
+
+	actor, err := booking.CancellationActor(booking.Canceller{UserID: customerID}, customerID, vendorUserID)
+	require.NoError(t, err)
+	assert.Equal(t, cancellation.ActorCustomer, actor)
+
+	actor, err = booking.CancellationActor(booking.Canceller{UserID: vendorUserID}, customerID, vendorUserID)
+	require.NoError(t, err)
+	assert.Equal(t, cancellation.ActorVendor, actor)
+
+	actor, err = booking.CancellationActor(booking.Canceller{UserID: uuid.New(), Admin: true}, customerID, vendorUserID)
+	require.NoError(t, err)
+	assert.Equal(t, cancellation.ActorAdmin, actor)
+
+	_, err = booking.CancellationActor(booking.Canceller{UserID: uuid.New()}, customerID, vendorUserID)
+	assert.ErrorIs(t, err, booking.ErrUnauthorized)
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/availability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Booking Rescheduling
+
+func rescheduleVendor() availability.Vendor {
+	vendor := weekdayVendor(1)
+	vendor.MinNotice = 24 * time.Hour
+	return vendor
+}
+
+func TestReschedule_ToFreeSlot(t *testing.T) {
+	now := at(1, 9, 0)
+	booked := []availability.Interval{span(3, 9, 11)}
+
+	result, err := availability.Reschedule(rescheduleVendor(), booked, span(3, 14, 16), span(4, 10, 12), now)
+
+	require.NoError(t, err)
+	assert.True(t, result.Available)
+	assert.Equal(t, span(4, 10, 12), result.Requested)
+}
+
+func TestReschedule_ConflictingSlot(t *testing.T) {
+	now := at(1, 9, 0)
+	booked := []availability.Interval{span(4, 9, 11)}
+
+	result, err := availability.Reschedule(rescheduleVendor(), booked, span(3, 14, 16), span(4, 10, 12), now)
+
+	assert.ErrorIs(t, err, availability.ErrUnavailable)
+	assert.Equal(t, availability.ReasonFullyBooked, result.Reason)
+	require.NotEmpty(t, result.Slots)
+	assert.Equal(t, at(4, 11, 30), result.Slots[0].Start)
+
+	// Outside working hours is unavailable too
+	_, err = availability.Reschedule(rescheduleVendor(), nil, span(3, 14, 16), span(6, 10, 12), now)
+	assert.ErrorIs(t, err, availability.ErrUnavailable)
+}
+
+func TestReschedule_TooLate(t *testing.T) {
+	now := at(3, 9, 0)
+
+	// The booking starts within the vendor's notice period
+	_, err := availability.Reschedule(rescheduleVendor(), nil, span(3, 14, 16), span(5, 10, 12), now)
+	assert.ErrorIs(t, err, availability.ErrInsufficientNotice)
+
+	// Nor can it be moved into the notice period
+	_, err = availability.Reschedule(rescheduleVendor(), nil, span(5, 14, 16), span(3, 14, 16), now)
+	assert.ErrorIs(t, err, availability.ErrInsufficientNotice)
+}
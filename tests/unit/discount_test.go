@@ -0,0 +1,69 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/discount"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Discount Stacking
+
+var conflictingDiscounts = []discount.Discount{
+	{Source: discount.SourceBundle, Percent: 10},
+	{Source: discount.SourceSubscription, Percent: 15},
+	{Source: discount.SourcePromo, Code: "EASTER", Amount: 2000},
+}
+
+func TestDiscountResolve_BestOfAppliesOnlyTheLargest(t *testing.T) {
+	result := discount.Resolve(100000, conflictingDiscounts, discount.Policy{Rule: discount.RuleBestOf})
+
+	require.Len(t, result.Applied, 1)
+	assert.Equal(t, discount.SourceSubscription, result.Applied[0].Source)
+	assert.Equal(t, 15000.0, result.TotalDiscount)
+	assert.Equal(t, 85000.0, result.EffectiveTotal)
+	assert.Len(t, result.Skipped, 2)
+}
+
+func TestDiscountResolve_AdditiveStacksUnderCap(t *testing.T) {
+	result := discount.Resolve(100000, conflictingDiscounts, discount.Policy{Rule: discount.RuleAdditive, MaxPercent: 50})
+
+	assert.Len(t, result.Applied, 3)
+	assert.Equal(t, 27000.0, result.TotalDiscount)
+	assert.Equal(t, 73000.0, result.EffectiveTotal)
+	assert.Empty(t, result.Skipped)
+	assert.Equal(t, "subscription 15% + bundle 10% + promo EASTER 2000", result.Reason())
+}
+
+func TestDiscountResolve_AdditiveCapTrimsAndSkips(t *testing.T) {
+	result := discount.Resolve(100000, conflictingDiscounts, discount.Policy{Rule: discount.RuleAdditive, MaxPercent: 20})
+
+	require.Len(t, result.Applied, 2)
+	assert.Equal(t, 15000.0, result.Applied[0].AmountOff)
+	assert.Equal(t, 5000.0, result.Applied[1].AmountOff)
+	assert.True(t, result.Applied[1].Capped)
+	assert.Equal(t, []discount.Discount{conflictingDiscounts[2]}, result.Skipped)
+	assert.Equal(t, 20000.0, result.TotalDiscount)
+	assert.Equal(t, 80000.0, result.EffectiveTotal)
+}
+
+func TestDiscountResolve_NeverBelowZero(t *testing.T) {
+	result := discount.Resolve(1500, []discount.Discount{
+		{Source: discount.SourcePromo, Amount: 1000},
+		{Source: discount.SourceSubscription, Amount: 1000},
+	}, discount.Policy{Rule: discount.RuleAdditive})
+
+	assert.Equal(t, 1500.0, result.TotalDiscount)
+	assert.Equal(t, 0.0, result.EffectiveTotal)
+}
+
+func TestDiscountResolve_TiesKeepPreferenceOrder(t *testing.T) {
+	result := discount.Resolve(1000, []discount.Discount{
+		{Source: discount.SourcePromo, Percent: 10},
+		{Source: discount.SourceBundle, Percent: 10},
+	}, discount.Policy{Rule: discount.RuleBestOf})
+
+	require.Len(t, result.Applied, 1)
+	assert.Equal(t, discount.SourcePromo, result.Applied[0].Source)
+}
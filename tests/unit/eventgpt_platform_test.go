@@ -0,0 +1,1382 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	eventgptapi "github.com/BillyRonksGlobal/vendorplatform/api/eventgpt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPlatformConversation() *eventgptapi.Conversation {
+	return &eventgptapi.Conversation{
+		ID:              uuid.New(),
+		UserID:          uuid.New(),
+		SlotValues:      make(map[string]eventgptapi.SlotValue),
+		ShortTermMemory: make(map[string]interface{}),
+		StartedAt:       time.Now(),
+	}
+}
+
+func addTurn(conv *eventgptapi.Conversation, msg eventgptapi.Message) {
+	msg.ID = uuid.New()
+	msg.Role = eventgptapi.RoleUser
+	msg.Timestamp = time.Now()
+	conv.Messages = append(conv.Messages, msg, eventgptapi.Message{
+		ID:        uuid.New(),
+		Role:      eventgptapi.RoleAssistant,
+		Content:   "Noted!",
+		Timestamp: time.Now(),
+	})
+	conv.TurnCount++
+}
+
+// Test Conversation Summarization
+
+func TestConversationSummary_NotTriggeredBelowThreshold(t *testing.T) {
+	conv := newPlatformConversation()
+	for i := 0; i < 5; i++ {
+		addTurn(conv, eventgptapi.Message{Content: fmt.Sprintf("message %d", i)})
+	}
+
+	mm := &eventgptapi.MemoryManager{}
+	assert.False(t, mm.SummarizeConversation(conv))
+	assert.Len(t, (&eventgptapi.DialogManager{}).BuildContext(conv).LastMessages, 10)
+}
+
+func TestConversationSummary_EarlyDecisionKeptInContext(t *testing.T) {
+	conv := newPlatformConversation()
+	addTurn(conv, eventgptapi.Message{
+		Content: "We settled on a rustic style in Lekki",
+		Intent:  &eventgptapi.Intent{Name: "update_preference", Confidence: 0.9},
+		Entities: []eventgptapi.Entity{
+			{Type: "style", Text: "rustic"},
+		},
+	})
+	for i := 0; i < 24; i++ {
+		addTurn(conv, eventgptapi.Message{
+			Content: fmt.Sprintf("small talk %d", i),
+			Intent:  &eventgptapi.Intent{Name: "thanks", Confidence: 0.9},
+		})
+	}
+
+	mm := &eventgptapi.MemoryManager{}
+	assert.True(t, mm.SummarizeConversation(conv))
+	assert.False(t, mm.SummarizeConversation(conv), "nothing new to summarize")
+
+	convContext := (&eventgptapi.DialogManager{}).BuildContext(conv)
+
+	assert.Contains(t, convContext.Summary, "style=rustic")
+	assert.Equal(t, eventgptapi.RoleSystem, convContext.LastMessages[0].Role)
+	assert.Contains(t, convContext.LastMessages[0].Content, "style=rustic")
+	assert.Len(t, convContext.LastMessages, 11)
+	for _, msg := range convContext.LastMessages[1:] {
+		assert.NotContains(t, msg.Content, "rustic")
+	}
+}
+
+func summaryOf(conv *eventgptapi.Conversation) []string {
+	summary, _ := conv.ShortTermMemory["conversation_summary"].(string)
+	return strings.Split(summary, "\n")
+}
+
+func TestConversationSummary_LongConversationKeepsEarliestDecisions(t *testing.T) {
+	conv := newPlatformConversation()
+	mm := &eventgptapi.MemoryManager{}
+	addTurn(conv, eventgptapi.Message{
+		Content:  "Rustic, in Lekki, Lagos",
+		Intent:   &eventgptapi.Intent{Name: "update_preference", Confidence: 0.9},
+		Entities: []eventgptapi.Entity{{Type: "style", Text: "rustic"}, {Type: "location", Text: "Lekki, Lagos"}},
+	})
+	// Far more questions and services than the summary has lines for
+	for i := 0; i < 60; i++ {
+		addTurn(conv, eventgptapi.Message{
+			Content: fmt.Sprintf("what about option %d?", i),
+			Intent:  &eventgptapi.Intent{Name: "ask_question", Confidence: 0.9},
+		})
+		addTurn(conv, eventgptapi.Message{
+			Content:  fmt.Sprintf("add service %d", i),
+			Intent:   &eventgptapi.Intent{Name: "add_service", Confidence: 0.9},
+			Entities: []eventgptapi.Entity{{Type: "service", Text: fmt.Sprintf("service-%d", i)}},
+		})
+		mm.SummarizeConversation(conv)
+	}
+
+	lines := summaryOf(conv)
+	assert.LessOrEqual(t, len(lines), 30)
+	assert.Equal(t, "- update_preference: style=rustic, location=Lekki, Lagos", lines[0])
+	// Every service decision survives, merged into one line
+	assert.Contains(t, lines[1], "- add_service: service=service-0, service=service-1,")
+	assert.Contains(t, lines[1], "service=service-54")
+	// The questions make way, oldest first
+	assert.NotContains(t, strings.Join(lines, "\n"), "option 0?")
+	assert.Contains(t, lines[len(lines)-1], "option 57?")
+}
+
+func TestConversationSummary_TooManyDecisionsKeepsTheOpeningAndLatest(t *testing.T) {
+	conv := newPlatformConversation()
+	for i := 0; i < 60; i++ {
+		addTurn(conv, eventgptapi.Message{
+			Content:  fmt.Sprintf("decision %d", i),
+			Intent:   &eventgptapi.Intent{Name: fmt.Sprintf("decide_%d", i), Confidence: 0.9},
+			Entities: []eventgptapi.Entity{{Type: "choice", Text: fmt.Sprintf("%d", i)}},
+		})
+	}
+
+	mm := &eventgptapi.MemoryManager{}
+	assert.True(t, mm.SummarizeConversation(conv))
+
+	lines := summaryOf(conv)
+	require.Len(t, lines, 30)
+	assert.Equal(t, "- decide_0: choice=0", lines[0])
+	assert.Equal(t, "- decide_14: choice=14", lines[14])
+	assert.Equal(t, "- decide_54: choice=54", lines[29])
+}
+
+// Test Confidence Gating
+
+func newBookingReadyConversation() *eventgptapi.Conversation {
+	conv := newPlatformConversation()
+	conv.ShortTermMemory["selected_vendor_id"] = uuid.New()
+	conv.ShortTermMemory["selected_service_id"] = uuid.New()
+	conv.SlotValues["event_date"] = eventgptapi.SlotValue{Value: "2025-12-20"}
+	return conv
+}
+
+func bookingStrategy() *eventgptapi.ResponseStrategy {
+	return &eventgptapi.ResponseStrategy{
+		Type:     eventgptapi.ResponseConfirm,
+		Template: "confirm_booking",
+		Actions:  []eventgptapi.ActionDefinition{{Type: "prepare_booking"}},
+	}
+}
+
+func TestConfidenceGate_LowConfidenceBookAsksToConfirm(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newBookingReadyConversation()
+	intent := &eventgptapi.Intent{Name: "book_service", Confidence: 0.5}
+
+	strategy := dm.ApplyConfidenceGate(conv, intent, bookingStrategy())
+
+	assert.Equal(t, "confirm_intent", strategy.Template)
+	assert.Empty(t, strategy.Actions)
+	assert.Equal(t, "book_service", conv.ShortTermMemory["pending_intent"])
+}
+
+func TestConfidenceGate_HighConfidenceBookExecutes(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newBookingReadyConversation()
+	intent := &eventgptapi.Intent{Name: "book_service", Confidence: 0.9}
+
+	strategy := dm.ApplyConfidenceGate(conv, intent, bookingStrategy())
+
+	assert.Equal(t, "confirm_booking", strategy.Template)
+	assert.Len(t, strategy.Actions, 1)
+	assert.NotContains(t, conv.ShortTermMemory, "pending_intent")
+}
+
+func TestConfidenceGate_ConfigurableThresholds(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	dm.SetConfidenceThresholds(eventgptapi.ConfidenceThresholds{"prepare_booking": 0.4})
+	conv := newBookingReadyConversation()
+
+	strategy := dm.ApplyConfidenceGate(conv, &eventgptapi.Intent{Name: "book_service", Confidence: 0.5}, bookingStrategy())
+
+	assert.Equal(t, "confirm_booking", strategy.Template)
+}
+
+func TestConfidenceGate_YesRestoresPendingIntent(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newBookingReadyConversation()
+	dm.ApplyConfidenceGate(conv, &eventgptapi.Intent{Name: "book_service", Confidence: 0.5}, bookingStrategy())
+
+	resolved := dm.ResolvePendingIntent(conv, "Yes", &eventgptapi.Intent{Name: "ask_question", Confidence: 0.5})
+
+	assert.Equal(t, "book_service", resolved.Name)
+	assert.Equal(t, 1.0, resolved.Confidence)
+	assert.NotContains(t, conv.ShortTermMemory, "pending_intent")
+}
+
+// Test Multi-Currency Budgets
+
+func budgetEntity(t *testing.T, text string) eventgptapi.BudgetAmount {
+	t.Helper()
+	for _, entity := range eventgptapi.NewEntityExtractor().ExtractEntities(text) {
+		if entity.Type != "budget" {
+			continue
+		}
+		if budget, ok := entity.Value.(eventgptapi.BudgetAmount); ok && budget.Amount >= 1000 {
+			return budget
+		}
+	}
+	t.Fatalf("no budget entity extracted from %q", text)
+	return eventgptapi.BudgetAmount{}
+}
+
+func TestExtractEntities_DollarBudget(t *testing.T) {
+	budget := budgetEntity(t, "Our budget is $5,000")
+	assert.Equal(t, 5000.0, budget.Amount)
+	assert.Equal(t, "USD", budget.Currency)
+	assert.Equal(t, "$5,000", budget.String())
+
+	budget = budgetEntity(t, "we can spend about 5k dollars")
+	assert.Equal(t, 5000.0, budget.Amount)
+	assert.Equal(t, "USD", budget.Currency)
+}
+
+func TestExtractEntities_PoundBudget(t *testing.T) {
+	budget := budgetEntity(t, "I have £2,500 to spend")
+	assert.Equal(t, 2500.0, budget.Amount)
+	assert.Equal(t, "GBP", budget.Currency)
+
+	budget = budgetEntity(t, "around 3.5k pounds")
+	assert.Equal(t, 3500.0, budget.Amount)
+	assert.Equal(t, "GBP", budget.Currency)
+}
+
+func TestExtractEntities_NairaBudgetStillDefault(t *testing.T) {
+	budget := budgetEntity(t, "budget is 2 million naira")
+	assert.Equal(t, 2000000.0, budget.Amount)
+	assert.Equal(t, "NGN", budget.Currency)
+
+	budget = budgetEntity(t, "budget of 750,000")
+	assert.Equal(t, 750000.0, budget.Amount)
+	assert.Equal(t, eventgptapi.DefaultBudgetCurrency, budget.Currency)
+}
+
+func TestParseBudget_TrailingCodeWins(t *testing.T) {
+	budget, ok := eventgptapi.ParseBudget("$8000 CAD")
+	assert.True(t, ok)
+	assert.Equal(t, eventgptapi.BudgetAmount{Amount: 8000, Currency: "CAD"}, budget)
+}
+
+// Test Usage Limits
+
+func comparisonStrategy() *eventgptapi.ResponseStrategy {
+	return &eventgptapi.ResponseStrategy{
+		Type:      eventgptapi.ResponseComparison,
+		Template:  "vendor_comparison",
+		NextState: eventgptapi.StateComparing,
+		Actions:   []eventgptapi.ActionDefinition{{Type: "generate_comparison"}},
+	}
+}
+
+func TestUsageLimits_FreeTierOverCapGetsUpgradePrompt(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newPlatformConversation()
+
+	strategy := dm.ApplyUsageLimits(conv, eventgptapi.TierFree, map[string]int{"generate_comparison": 3}, comparisonStrategy())
+
+	assert.Equal(t, "upgrade_required", strategy.Template)
+	assert.Empty(t, strategy.Actions)
+	assert.Equal(t, "upgrade:premium", strategy.QuickReplies[0].Payload)
+}
+
+func TestUsageLimits_FreeTierUnderCapRuns(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newPlatformConversation()
+
+	strategy := dm.ApplyUsageLimits(conv, eventgptapi.TierFree, map[string]int{"generate_comparison": 2}, comparisonStrategy())
+
+	assert.Equal(t, "vendor_comparison", strategy.Template)
+	assert.Len(t, strategy.Actions, 1)
+}
+
+func TestUsageLimits_PremiumBypassesCap(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newPlatformConversation()
+
+	for _, tier := range []eventgptapi.PlanTier{eventgptapi.TierPremium, eventgptapi.TierPro} {
+		strategy := dm.ApplyUsageLimits(conv, tier, map[string]int{"generate_comparison": 50}, comparisonStrategy())
+		assert.Equal(t, "vendor_comparison", strategy.Template, tier)
+	}
+}
+
+func TestUsageLimits_ConfigurableCaps(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	dm.SetUsageMeter(eventgptapi.NewUsageMeter(nil, eventgptapi.UsageLimits{
+		eventgptapi.TierFree: {"generate_comparison": 10},
+	}))
+	conv := newPlatformConversation()
+
+	strategy := dm.ApplyUsageLimits(conv, eventgptapi.TierFree, map[string]int{"generate_comparison": 5}, comparisonStrategy())
+	assert.Equal(t, "vendor_comparison", strategy.Template)
+
+	action, exceeded := eventgptapi.DefaultUsageLimits().Exceeded(eventgptapi.TierFree,
+		map[string]int{"generate_comparison": 3}, comparisonStrategy().Actions)
+	assert.True(t, exceeded)
+	assert.Equal(t, "generate_comparison", action)
+}
+
+// Test Intent Clarification
+
+func TestClassifyIntent_RanksAlternatives(t *testing.T) {
+	classifier := eventgptapi.NewIntentClassifier(nil)
+
+	ranked, err := classifier.ClassifyIntent(context.Background(), "photographer price", nil)
+	assert.NoError(t, err)
+
+	names := []string{}
+	for i, intent := range ranked {
+		names = append(names, intent.Name)
+		if i > 0 {
+			assert.GreaterOrEqual(t, ranked[i-1].Confidence, intent.Confidence)
+		}
+	}
+	assert.Contains(t, names, "get_quote")
+	assert.Contains(t, names, "find_vendor")
+}
+
+func TestClassifyIntent_ClearMessageNeedsNoClarification(t *testing.T) {
+	classifier := eventgptapi.NewIntentClassifier(nil)
+	dm := &eventgptapi.DialogManager{}
+
+	ranked, _ := classifier.ClassifyIntent(context.Background(), "Help me plan a wedding", nil)
+	intent := classifier.SelectIntent(ranked)
+
+	assert.Equal(t, "create_event", intent.Name)
+	assert.GreaterOrEqual(t, intent.Confidence, eventgptapi.DefaultMinIntentConfidence)
+	if assert.Len(t, intent.Alternatives, 1) {
+		assert.Equal(t, ranked[1].Name, intent.Alternatives[0].Name)
+		assert.Equal(t, ranked[1].Confidence, intent.Alternatives[0].Confidence)
+	}
+	assert.Nil(t, dm.ClarifyIntent(newPlatformConversation(), intent))
+}
+
+func TestClarifyIntent_AmbiguousMessageOffersChoices(t *testing.T) {
+	classifier := eventgptapi.NewIntentClassifier(nil)
+	dm := &eventgptapi.DialogManager{}
+
+	ranked, _ := classifier.ClassifyIntent(context.Background(), "photographer price", nil)
+	intent := classifier.SelectIntent(ranked)
+	assert.Equal(t, eventgptapi.IntentClarify, intent.Name)
+	assert.Less(t, intent.Confidence, eventgptapi.DefaultMinIntentConfidence)
+
+	strategy := dm.ClarifyIntent(newPlatformConversation(), intent)
+	if assert.NotNil(t, strategy) {
+		assert.Equal(t, "clarify_intent", strategy.Template)
+		assert.Empty(t, strategy.Actions)
+
+		payloads := []string{}
+		for _, reply := range strategy.QuickReplies {
+			payloads = append(payloads, reply.Payload)
+		}
+		assert.Equal(t, []string{"intent:get_quote", "intent:find_vendor"}, payloads)
+	}
+}
+
+func TestClarifyIntent_OffersTheTopScoringCandidates(t *testing.T) {
+	classifier := eventgptapi.NewIntentClassifier(nil)
+
+	ranked, _ := classifier.ClassifyIntent(context.Background(), "photographer price", nil)
+	intent := classifier.SelectIntent(ranked)
+
+	// The offered options outscore every other actionable candidate
+	if assert.Len(t, intent.Alternatives, 2) {
+		first, second := intent.Alternatives[0], intent.Alternatives[1]
+		assert.GreaterOrEqual(t, first.Confidence, second.Confidence)
+		for _, candidate := range ranked {
+			if candidate.Name == first.Name || candidate.Name == second.Name || candidate.Name == "ask_question" {
+				continue
+			}
+			assert.Less(t, candidate.Confidence, second.Confidence, candidate.Name)
+		}
+		for _, offered := range intent.Alternatives {
+			for _, candidate := range ranked {
+				if candidate.Name == offered.Name {
+					assert.Equal(t, candidate.Confidence, offered.Confidence)
+				}
+			}
+		}
+	}
+}
+
+func TestSelectIntent_MinConfidenceIsConfigurable(t *testing.T) {
+	classifier := eventgptapi.NewIntentClassifier(nil)
+	ranked, _ := classifier.ClassifyIntent(context.Background(), "Help me plan a wedding", nil)
+
+	classifier.SetMinIntentConfidence(0.95)
+	intent := classifier.SelectIntent(ranked)
+	assert.Equal(t, eventgptapi.IntentClarify, intent.Name)
+	if assert.Len(t, intent.Alternatives, 2) {
+		assert.Equal(t, "create_event", intent.Alternatives[0].Name)
+	}
+
+	classifier.SetMinIntentConfidence(0.1)
+	ranked, _ = classifier.ClassifyIntent(context.Background(), "photographer price", nil)
+	assert.NotEqual(t, eventgptapi.IntentClarify, classifier.SelectIntent(ranked).Name)
+}
+
+func TestClarifyIntent_ChoicePayloadResolvesIntent(t *testing.T) {
+	classifier := eventgptapi.NewIntentClassifier(nil)
+
+	ranked, _ := classifier.ClassifyIntent(context.Background(), "intent:find_vendor", nil)
+
+	assert.Len(t, ranked, 1)
+	assert.Equal(t, "find_vendor", ranked[0].Name)
+	assert.Equal(t, 1.0, ranked[0].Confidence)
+}
+
+// Test Event Type Slot Sets
+
+func missingSlotNames(slots []eventgptapi.SlotDefinition) []string {
+	names := []string{}
+	for _, slot := range slots {
+		names = append(names, slot.Name)
+	}
+	return names
+}
+
+func TestSlotSets_EventTypeChangesRequiredSlots(t *testing.T) {
+	sf := eventgptapi.NewSlotFiller()
+
+	missing := sf.GetMissingRequiredSlots(map[string]eventgptapi.SlotValue{}, "create_event")
+	assert.Equal(t, []string{"event_type", "event_date", "guest_count", "location"}, missingSlotNames(missing))
+
+	wedding := map[string]eventgptapi.SlotValue{"event_type": {Value: "wedding"}}
+	missing = sf.GetMissingRequiredSlots(wedding, "create_event")
+	assert.Equal(t, []string{"event_date", "guest_count", "location"}, missingSlotNames(missing))
+	assert.Equal(t, "ask_event_date", missing[0].Template)
+
+	funeral := map[string]eventgptapi.SlotValue{"event_type": {Value: "Funeral"}}
+	missing = sf.GetMissingRequiredSlots(funeral, "create_event")
+	assert.Equal(t, []string{"event_date", "location"}, missingSlotNames(missing))
+	assert.Equal(t, "ask_event_date_funeral", missing[0].Template)
+	assert.Equal(t, "ask_location_funeral", missing[1].Template)
+
+	corporate := map[string]eventgptapi.SlotValue{"event_type": {Value: "corporate event"}}
+	missing = sf.GetMissingRequiredSlots(corporate, "create_event")
+	assert.Contains(t, missingSlotNames(missing), "budget")
+}
+
+func TestSlotSets_FuneralPromptsSkipCelebration(t *testing.T) {
+	for _, name := range []string{"ask_event_date_funeral", "ask_location_funeral", "ask_guest_count_funeral"} {
+		template, ok := eventgptapi.ResponseTemplates[name]
+		require.True(t, ok, name)
+		for _, variation := range template.Variations {
+			assert.NotContains(t, strings.ToLower(variation), "celebrat", name)
+			assert.NotContains(t, variation, "🎉", name)
+		}
+	}
+}
+
+func TestSlotSets_RegisterCustomEventType(t *testing.T) {
+	sf := eventgptapi.NewSlotFiller()
+	sf.RegisterEventTypeSlots("Naming Ceremony", map[string]eventgptapi.SlotDefinition{
+		"event_type": {Name: "event_type", Required: true, Priority: 1},
+		"event_date": {Name: "event_date", Required: true, Priority: 2, Template: "ask_naming_date"},
+	})
+
+	slots := map[string]eventgptapi.SlotValue{"event_type": {Value: "naming ceremony"}}
+	missing := sf.GetMissingRequiredSlots(slots, "create_event")
+
+	require.Len(t, missing, 1)
+	assert.Equal(t, "ask_naming_date", missing[0].Template)
+}
+
+// Test Entity Overlap Resolution
+
+func TestExtractEntities_ResolvesOverlappingSpans(t *testing.T) {
+	message := "Wedding on 12/05/2026 for 200 guests, budget 5 million naira"
+
+	entities := eventgptapi.NewEntityExtractor().ExtractEntities(message)
+
+	resolved := []string{}
+	for _, entity := range entities {
+		resolved = append(resolved, entity.Type+"="+entity.Text)
+	}
+	assert.Equal(t, []string{
+		"event_type=Wedding",
+		"date=12/05/2026",
+		"number=200 guests",
+		"budget=5 million naira",
+	}, resolved)
+
+	budget, ok := entities[3].Value.(eventgptapi.BudgetAmount)
+	require.True(t, ok)
+	assert.Equal(t, 5000000.0, budget.Amount)
+}
+
+func TestExtractEntities_LooseLocationKeepsNestedEntities(t *testing.T) {
+	entities := eventgptapi.NewEntityExtractor().ExtractEntities("Party at a rustic venue, next saturday")
+
+	types := []string{}
+	for _, entity := range entities {
+		types = append(types, entity.Type)
+	}
+	assert.Equal(t, []string{"event_type", "location", "style", "vendor_type", "date"}, types)
+}
+
+func TestResolveEntityOverlaps_MergesIdenticalSpans(t *testing.T) {
+	entities := eventgptapi.ResolveEntityOverlaps([]eventgptapi.Entity{
+		{Type: "time", Text: "evening", StartPos: 20, EndPos: 27, Confidence: 0.85},
+		{Type: "budget", Text: "2026", StartPos: 6, EndPos: 10, Confidence: 0.85},
+		{Type: "date", Text: "12/05/2026", StartPos: 0, EndPos: 10, Confidence: 0.85},
+		{Type: "time", Text: "evening", StartPos: 20, EndPos: 27, Confidence: 0.9},
+	})
+
+	require.Len(t, entities, 2)
+	assert.Equal(t, "date", entities[0].Type)
+	assert.Equal(t, "time", entities[1].Type)
+	assert.Equal(t, 0.9, entities[1].Confidence)
+}
+
+// Test Relative Date Resolution
+
+func TestResolveRelativeDate(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 15, 30, 0, 0, time.UTC) // a Wednesday
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		text        string
+		want        time.Time
+		flexibility eventgptapi.DateFlexibility
+	}{
+		{"today", day(2026, time.October, 14), eventgptapi.DateFixed},
+		{"Tomorrow", day(2026, time.October, 15), eventgptapi.DateFixed},
+		{"weekend", day(2026, time.October, 17), eventgptapi.DateFlexible},
+		{"this weekend", day(2026, time.October, 17), eventgptapi.DateFlexible},
+		{"next weekend", day(2026, time.October, 24), eventgptapi.DateFlexible},
+		{"next week", day(2026, time.October, 19), eventgptapi.DateFlexible},
+		{"next month", day(2026, time.November, 1), eventgptapi.DateFlexible},
+		{"next year", day(2027, time.January, 1), eventgptapi.DateFlexible},
+		{"next Saturday", day(2026, time.October, 17), eventgptapi.DateFixed},
+		{"next wednesday", day(2026, time.October, 21), eventgptapi.DateFixed},
+		{"next December", day(2026, time.December, 1), eventgptapi.DateFlexible},
+		{"next March", day(2027, time.March, 1), eventgptapi.DateFlexible},
+		{"next October", day(2027, time.October, 1), eventgptapi.DateFlexible},
+		{"in 3 days", day(2026, time.October, 17), eventgptapi.DateFixed},
+		{"in 2 weeks", day(2026, time.October, 28), eventgptapi.DateFlexible},
+		{"in 6 months", day(2027, time.April, 14), eventgptapi.DateFlexible},
+		{"in 1 year", day(2027, time.October, 14), eventgptapi.DateFlexible},
+		{"12/05/2026", day(2026, time.May, 12), eventgptapi.DateFixed},
+		{"25-12-26", day(2026, time.December, 25), eventgptapi.DateFixed},
+		{"December 12th, 2026", day(2026, time.December, 12), eventgptapi.DateFixed},
+		{"june 5", day(2027, time.June, 5), eventgptapi.DateFixed},
+	}
+
+	for _, tc := range cases {
+		got, flexibility, err := eventgptapi.ResolveRelativeDate(tc.text, now)
+		require.NoError(t, err, tc.text)
+		assert.Equal(t, tc.want, got, tc.text)
+		assert.Equal(t, tc.flexibility, flexibility, tc.text)
+	}
+}
+
+func TestResolveRelativeDate_Unparseable(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 15, 30, 0, 0, time.UTC)
+
+	for _, text := range []string{"someday", "next fortnight", "31/02/2026", "smarch 3"} {
+		_, _, err := eventgptapi.ResolveRelativeDate(text, now)
+		assert.ErrorIs(t, err, eventgptapi.ErrUnparseableDate, text)
+	}
+}
+
+func TestExtractEntities_StoresResolvedDate(t *testing.T) {
+	for _, message := range []string{"a wedding next December", "a wedding in 6 months"} {
+		var date *eventgptapi.Entity
+		for _, entity := range eventgptapi.NewEntityExtractor().ExtractEntities(message) {
+			if entity.Type == "date" {
+				date = &entity
+			}
+		}
+		require.NotNil(t, date, message)
+		assert.IsType(t, time.Time{}, date.Value, message)
+		assert.Equal(t, eventgptapi.DateFlexible, date.DateFlexibility, message)
+	}
+}
+
+// Test Corrections
+
+func TestCorrection_UpdatesEventType(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newPlatformConversation()
+	conv.SlotValues["event_type"] = eventgptapi.SlotValue{Value: "wedding"}
+	conv.SlotValues["guest_count"] = eventgptapi.SlotValue{Value: 150}
+	conv.ConversationState = eventgptapi.StateConfirming
+	conv.TurnCount = 4
+
+	message := "actually, make it a birthday not a wedding"
+	entities := eventgptapi.NewEntityExtractor().ExtractEntities(message)
+
+	slot := dm.ApplyCorrection(conv, message, entities)
+
+	assert.Equal(t, "event_type", slot)
+	assert.Equal(t, "birthday", conv.SlotValues["event_type"].Value)
+	assert.Equal(t, 150, conv.SlotValues["guest_count"].Value)
+	assert.Equal(t, eventgptapi.StateGatheringInfo, conv.ConversationState)
+	require.Len(t, conv.SlotHistory, 1)
+	assert.Equal(t, "wedding", conv.SlotHistory[0].OldValue)
+	assert.Equal(t, 4, conv.SlotHistory[0].Turn)
+}
+
+func TestCorrection_ResetsSlotsTheNewEventTypeSkips(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newPlatformConversation()
+	conv.SlotValues["event_type"] = eventgptapi.SlotValue{Value: "corporate event"}
+	conv.SlotValues["budget"] = eventgptapi.SlotValue{Value: "5 million"}
+	conv.SlotValues["location"] = eventgptapi.SlotValue{Value: "Lagos"}
+
+	message := "sorry, I meant a funeral instead"
+	entities := eventgptapi.NewEntityExtractor().ExtractEntities(message)
+
+	require.Equal(t, "event_type", dm.ApplyCorrection(conv, message, entities))
+	assert.Equal(t, "funeral", conv.SlotValues["event_type"].Value)
+	assert.NotContains(t, conv.SlotValues, "budget")
+	assert.Equal(t, "Lagos", conv.SlotValues["location"].Value)
+
+	require.Len(t, conv.SlotHistory, 2)
+	assert.Equal(t, "reset", conv.SlotHistory[1].Reason)
+	assert.Equal(t, "budget", conv.SlotHistory[1].Slot)
+
+	missing := eventgptapi.NewSlotFiller().GetMissingRequiredSlots(conv.SlotValues, "create_event")
+	assert.Equal(t, []string{"event_date"}, missingSlotNames(missing))
+}
+
+func TestCorrection_IgnoresNonCorrections(t *testing.T) {
+	current := map[string]eventgptapi.SlotValue{"event_type": {Value: "wedding"}}
+	extractor := eventgptapi.NewEntityExtractor()
+
+	for _, message := range []string{
+		"I also need a photographer for the wedding",
+		"actually, it's still a wedding",
+	} {
+		_, _, ok := eventgptapi.DetectCorrection(message, extractor.ExtractEntities(message), current)
+		assert.False(t, ok, message)
+	}
+}
+
+func TestCorrection_ResumesPreviousTask(t *testing.T) {
+	conv := newPlatformConversation()
+	conv.IntentHistory = []string{"greeting", "create_event", "update_preference"}
+	assert.Equal(t, "create_event", eventgptapi.PreviousTaskIntent(conv))
+
+	conv.IntentHistory = []string{"greeting", "update_preference"}
+	assert.Equal(t, "", eventgptapi.PreviousTaskIntent(conv))
+}
+
+// Test Processing Time Metrics
+
+func TestPercentile_NearestRank(t *testing.T) {
+	values := []int64{120, 40, 80, 200, 60, 100, 20, 180, 140, 160}
+
+	assert.Equal(t, int64(100), eventgptapi.Percentile(values, 50))
+	assert.Equal(t, int64(200), eventgptapi.Percentile(values, 95))
+	assert.Equal(t, int64(20), eventgptapi.Percentile(values, 0))
+	assert.Equal(t, int64(0), eventgptapi.Percentile(nil, 50))
+	assert.Equal(t, []int64{120, 40, 80, 200, 60, 100, 20, 180, 140, 160}, values, "input must not be reordered")
+}
+
+func TestSummarizeProcessingTimes_ByIntentAndAction(t *testing.T) {
+	var samples []eventgptapi.ProcessingSample
+	for ms := int64(1); ms <= 20; ms++ {
+		samples = append(samples, eventgptapi.ProcessingSample{Intent: "greeting", ProcessingTimeMs: ms})
+	}
+	for ms := int64(100); ms <= 1000; ms += 100 {
+		samples = append(samples, eventgptapi.ProcessingSample{
+			Intent:           "find_vendor",
+			Actions:          []string{"search_vendors", "rank_vendors"},
+			ProcessingTimeMs: ms,
+		})
+	}
+	samples = append(samples, eventgptapi.ProcessingSample{
+		Intent:           "compare_options",
+		Actions:          []string{"rank_vendors"},
+		ProcessingTimeMs: 5000,
+	})
+
+	intents, actions := eventgptapi.SummarizeProcessingTimes(samples)
+
+	assert.Equal(t, eventgptapi.LatencyStats{Count: 20, P50Ms: 10, P95Ms: 19}, intents["greeting"])
+	assert.Equal(t, eventgptapi.LatencyStats{Count: 10, P50Ms: 500, P95Ms: 1000}, intents["find_vendor"])
+	assert.Equal(t, eventgptapi.LatencyStats{Count: 10, P50Ms: 500, P95Ms: 1000}, actions["search_vendors"])
+	assert.Equal(t, eventgptapi.LatencyStats{Count: 11, P50Ms: 600, P95Ms: 5000}, actions["rank_vendors"])
+	assert.NotContains(t, actions, "greeting")
+}
+
+// Test Vendor Search Fallback
+
+func TestVendorSearch_EmptyStrictSearchBroadens(t *testing.T) {
+	strict := eventgptapi.VendorCriteriaFromParams(map[string]interface{}{
+		"vendor_type": "photographer",
+		"location":    "Ibadan",
+	})
+
+	var tried []eventgptapi.VendorSearchCriteria
+	search := func(ctx context.Context, c eventgptapi.VendorSearchCriteria) ([]eventgptapi.VendorResult, error) {
+		tried = append(tried, c)
+		if c.AnyLocation && c.IncludeUnverified {
+			return []eventgptapi.VendorResult{{VendorID: uuid.New(), VendorName: "Lagos Lens"}}, nil
+		}
+		return nil, nil
+	}
+
+	vendors, matched, err := eventgptapi.SearchVendorsWithFallback(context.Background(), strict, search)
+	require.NoError(t, err)
+	require.Len(t, vendors, 1)
+	assert.True(t, vendors[0].Broadened)
+	assert.Len(t, tried, 3)
+	assert.False(t, matched.FuzzyCategory)
+	assert.Equal(t, []string{"beyond Ibadan", "including unverified vendors"}, matched.Relaxations())
+}
+
+func TestVendorSearch_StrictResultsAreNotBroadened(t *testing.T) {
+	calls := 0
+	search := func(ctx context.Context, c eventgptapi.VendorSearchCriteria) ([]eventgptapi.VendorResult, error) {
+		calls++
+		return []eventgptapi.VendorResult{{VendorID: uuid.New()}}, nil
+	}
+
+	vendors, matched, err := eventgptapi.SearchVendorsWithFallback(context.Background(),
+		eventgptapi.VendorSearchCriteria{VendorType: "caterer", Location: "Lagos"}, search)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.False(t, vendors[0].Broadened)
+	assert.Empty(t, matched.Relaxations())
+}
+
+func TestVendorSearch_BroadenedResponseIsLabelled(t *testing.T) {
+	strategy := &eventgptapi.ResponseStrategy{Type: eventgptapi.ResponseCards, Template: "vendor_results"}
+
+	broadened := eventgptapi.ApplyVendorSearchOutcome(strategy, map[string]interface{}{
+		"vendor_count": 2,
+		"broadened":    true,
+		"broadened_by": "beyond Ibadan",
+	})
+	assert.Equal(t, "vendor_results_broadened", broadened.Template)
+	assert.Equal(t, "vendor_results", strategy.Template)
+
+	empty := eventgptapi.ApplyVendorSearchOutcome(strategy, map[string]interface{}{"vendor_count": 0})
+	assert.Equal(t, "no_vendors_found", empty.Template)
+
+	_, ok := eventgptapi.ResponseTemplates["vendor_results_broadened"]
+	assert.True(t, ok)
+}
+
+func TestCategorySearchPatterns_FuzzyStems(t *testing.T) {
+	assert.Equal(t, []string{"%photographer%"}, eventgptapi.CategorySearchPatterns("Photographer", false))
+	assert.Equal(t, []string{"%photographer%", "%photograph%"}, eventgptapi.CategorySearchPatterns("Photographer", true))
+	assert.Equal(t, []string{"%caterer%", "%cater%"}, eventgptapi.CategorySearchPatterns("caterer", true))
+	assert.Equal(t, []string{"%dj%"}, eventgptapi.CategorySearchPatterns("dj", true))
+}
+
+func TestVendorSearch_NearerVendorRanksFirst(t *testing.T) {
+	near, far := 3.0, 35.0
+	vendors := []eventgptapi.VendorResult{
+		{VendorID: uuid.New(), VendorName: "Mainland Lens", Rating: 4.5, DistanceKm: &far, GeoRestricted: true},
+		{VendorID: uuid.New(), VendorName: "Lekki Lens", Rating: 4.5, DistanceKm: &near, GeoRestricted: true},
+	}
+
+	eventgptapi.RankVendorsByProximity(vendors, eventgptapi.DefaultVendorSearchRadiusKm)
+	assert.Equal(t, "Lekki Lens", vendors[0].VendorName)
+
+	// Distance only counts for part of the rank: a much better rated vendor
+	// a little further out can still lead
+	nearby, fartherTopRated := 5.0, 10.0
+	vendors = []eventgptapi.VendorResult{
+		{VendorName: "Close", Rating: 2.0, DistanceKm: &nearby},
+		{VendorName: "Top rated", Rating: 5.0, DistanceKm: &fartherTopRated},
+	}
+	eventgptapi.RankVendorsByProximity(vendors, eventgptapi.DefaultVendorSearchRadiusKm)
+	assert.Equal(t, "Top rated", vendors[0].VendorName)
+}
+
+func TestVendorSearch_CoordinatesFromLocationParam(t *testing.T) {
+	criteria := eventgptapi.VendorCriteriaFromParams(map[string]interface{}{
+		"vendor_type": "photographer",
+		"location":    "6.4698, 3.5852",
+	})
+	require.NotNil(t, criteria.Coordinates)
+	assert.InDelta(t, 6.4698, criteria.Coordinates.Latitude, 1e-9)
+	assert.InDelta(t, 3.5852, criteria.Coordinates.Longitude, 1e-9)
+	assert.True(t, criteria.FiltersLocation())
+
+	named := eventgptapi.VendorCriteriaFromParams(map[string]interface{}{"location": "Lekki"})
+	assert.Nil(t, named.Coordinates, "place names are geocoded at search time")
+
+	_, ok := eventgptapi.ParseGeoPoint("95,3.5")
+	assert.False(t, ok)
+}
+
+func TestVendorSearch_UnresolvedLocationIsNoted(t *testing.T) {
+	strategy := &eventgptapi.ResponseStrategy{Type: eventgptapi.ResponseCards, Template: "vendor_results"}
+
+	adjusted := eventgptapi.ApplyVendorSearchOutcome(strategy, map[string]interface{}{
+		"vendor_count":        3,
+		"location_unresolved": true,
+	})
+	assert.Equal(t, "vendor_results_unlocated", adjusted.Template)
+	_, ok := eventgptapi.ResponseTemplates["vendor_results_unlocated"]
+	assert.True(t, ok)
+}
+
+// Test Feature Flags
+
+type failingFlagSource struct{}
+
+func (failingFlagSource) FlagRules(ctx context.Context) (map[eventgptapi.DialogFeature]eventgptapi.FlagRule, error) {
+	return nil, fmt.Errorf("store unavailable")
+}
+
+func weddingMissingSlots(conv *eventgptapi.Conversation) []eventgptapi.SlotDefinition {
+	conv.SlotValues["event_type"] = eventgptapi.SlotValue{Value: "wedding"}
+	return eventgptapi.NewSlotFiller().GetMissingRequiredSlots(conv.SlotValues, "create_event")
+}
+
+func TestFeatureFlags_FlaggedOnConversationBatchesQuestions(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newPlatformConversation()
+	flags := eventgptapi.NewFeatureFlags(eventgptapi.StaticFlags{
+		eventgptapi.FeatureBatchedQuestions: {UserIDs: []uuid.UUID{conv.UserID}},
+	})
+
+	flags.Resolve(context.Background(), conv)
+	require.True(t, conv.FeatureEnabled(eventgptapi.FeatureBatchedQuestions))
+
+	strategy := dm.AskForMissingSlots(conv, weddingMissingSlots(conv))
+	assert.Equal(t, "ask_event_date_and_guest_count", strategy.Template)
+	assert.Equal(t, []string{"event_date", "guest_count"}, strategy.DataNeeded)
+}
+
+func TestFeatureFlags_FlaggedOffConversationUsesLegacyPath(t *testing.T) {
+	dm := &eventgptapi.DialogManager{}
+	conv := newPlatformConversation()
+	flags := eventgptapi.NewFeatureFlags(eventgptapi.StaticFlags{
+		eventgptapi.FeatureBatchedQuestions: {UserIDs: []uuid.UUID{uuid.New()}},
+	})
+
+	flags.Resolve(context.Background(), conv)
+	assert.False(t, conv.FeatureEnabled(eventgptapi.FeatureBatchedQuestions))
+
+	strategy := dm.AskForMissingSlots(conv, weddingMissingSlots(conv))
+	assert.Equal(t, "ask_event_date", strategy.Template)
+	assert.Equal(t, []string{"event_date"}, strategy.DataNeeded)
+}
+
+func TestFeatureFlags_DefaultOff(t *testing.T) {
+	conv := newPlatformConversation()
+
+	var unset *eventgptapi.FeatureFlags
+	unset.Resolve(context.Background(), conv)
+	assert.False(t, conv.FeatureEnabled(eventgptapi.FeatureBatchedQuestions))
+
+	eventgptapi.NewFeatureFlags(failingFlagSource{}).Resolve(context.Background(), conv)
+	assert.False(t, conv.FeatureEnabled(eventgptapi.FeatureBatchedQuestions))
+	assert.Nil(t, conv.Features, "a failed lookup is retried on the next turn")
+}
+
+func TestFeatureFlags_PinnedForTheConversation(t *testing.T) {
+	conv := newPlatformConversation()
+	eventgptapi.NewFeatureFlags(eventgptapi.StaticFlags{
+		eventgptapi.FeatureBatchedQuestions: {Enabled: true},
+	}).Resolve(context.Background(), conv)
+
+	eventgptapi.NewFeatureFlags(eventgptapi.StaticFlags{}).Resolve(context.Background(), conv)
+	assert.True(t, conv.FeatureEnabled(eventgptapi.FeatureBatchedQuestions))
+}
+
+func TestFlagRule_PercentCohortIsStable(t *testing.T) {
+	rule := eventgptapi.FlagRule{Percent: 30}
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		userID := uuid.New()
+		allowed := rule.Allows(eventgptapi.FeatureBatchedQuestions, userID)
+		assert.Equal(t, allowed, rule.Allows(eventgptapi.FeatureBatchedQuestions, userID))
+		if allowed {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 300, enabled, 60)
+	assert.False(t, eventgptapi.FlagRule{}.Allows(eventgptapi.FeatureBatchedQuestions, uuid.New()))
+}
+
+// Test Saved Vendors
+
+func TestSavedVendors_SaveFromCardPersists(t *testing.T) {
+	conv := newPlatformConversation()
+	conv.SlotValues["vendor_type"] = eventgptapi.SlotValue{Value: "Photographer"}
+	vendor := eventgptapi.VendorResult{VendorID: uuid.New(), ServiceID: uuid.New(), VendorName: "Lens & Light", ServiceName: "Wedding coverage"}
+	conv.ShortTermMemory["vendor_results"] = []eventgptapi.VendorResult{vendor}
+
+	intents, err := eventgptapi.NewIntentClassifier(nil).ClassifyIntent(context.Background(), "save_vendor:"+vendor.VendorID.String(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "save_vendor", intents[0].Name)
+
+	vendorID, ok := eventgptapi.ParseSaveVendorPayload("save_vendor:" + vendor.VendorID.String())
+	require.True(t, ok)
+
+	store := eventgptapi.NewMemorySavedVendors()
+	saved, err := eventgptapi.SaveVendor(context.Background(), store, conv, vendorID)
+	require.NoError(t, err)
+	assert.Equal(t, "Lens & Light", saved.Label())
+	assert.Equal(t, vendor.VendorID, conv.ShortTermMemory["selected_vendor_id"])
+	assert.Equal(t, vendor.ServiceID, conv.ShortTermMemory["selected_service_id"])
+
+	list, err := store.SavedVendors(context.Background(), conv.UserID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, vendor.VendorID, list[0].VendorID)
+	assert.Equal(t, "photographer", list[0].VendorType)
+	assert.Equal(t, conv.ID, list[0].ConversationID)
+
+	others, err := store.SavedVendors(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, others)
+}
+
+func TestSavedVendors_ResaveMovesToFront(t *testing.T) {
+	conv := newPlatformConversation()
+	store := eventgptapi.NewMemorySavedVendors()
+	first, second := uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{first, second, first} {
+		_, err := eventgptapi.SaveVendor(context.Background(), store, conv, id)
+		require.NoError(t, err)
+	}
+
+	list, err := store.SavedVendors(context.Background(), conv.UserID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, first, list[0].VendorID)
+	assert.Equal(t, second, list[1].VendorID)
+}
+
+func TestSavedVendors_NoStoreFailsTheSave(t *testing.T) {
+	_, err := eventgptapi.SaveVendor(context.Background(), nil, newPlatformConversation(), uuid.New())
+	assert.ErrorIs(t, err, eventgptapi.ErrSavedVendorsUnavailable)
+
+	strategy := eventgptapi.ApplySaveVendorOutcome(&eventgptapi.ResponseStrategy{Template: "vendor_saved"}, map[string]interface{}{})
+	assert.Equal(t, "vendor_save_failed", strategy.Template)
+}
+
+func TestSavedVendors_LaterTurnRecallsByCategory(t *testing.T) {
+	ctx := context.Background()
+	store := eventgptapi.NewMemorySavedVendors()
+	userID := uuid.New()
+	photographer := eventgptapi.SavedVendor{UserID: userID, VendorID: uuid.New(), VendorName: "Lens & Light", VendorType: "photographer", SavedAt: time.Now().Add(-time.Hour)}
+	caterer := eventgptapi.SavedVendor{UserID: userID, VendorID: uuid.New(), ServiceID: uuid.New(), VendorName: "Mama Put Kitchen", VendorType: "caterer", SavedAt: time.Now()}
+	require.NoError(t, store.SaveVendor(ctx, photographer))
+	require.NoError(t, store.SaveVendor(ctx, caterer))
+
+	dm := &eventgptapi.DialogManager{}
+	dm.SetSavedVendors(store)
+
+	conv := newPlatformConversation()
+	conv.UserID = userID
+	assert.True(t, dm.RecallSavedVendor(ctx, conv, "How much would my saved photographer charge?"))
+	assert.Equal(t, photographer.VendorID, conv.ShortTermMemory["selected_vendor_id"])
+
+	assert.True(t, dm.RecallSavedVendor(ctx, conv, "Is the saved vendor free in December?"))
+	assert.Equal(t, caterer.VendorID, conv.ShortTermMemory["selected_vendor_id"], "a bare reference means the latest save")
+	assert.Equal(t, caterer.ServiceID, conv.ShortTermMemory["selected_service_id"])
+
+	assert.False(t, dm.RecallSavedVendor(ctx, conv, "Find me a decorator"))
+}
+
+// Test Concurrent Turns
+
+func TestProcessMessage_DoubleSendRunsTurnsSerially(t *testing.T) {
+	dm := eventgptapi.NewDialogManager(nil, nil)
+	dm.SetSavedVendors(eventgptapi.NewMemorySavedVendors())
+
+	conv := newPlatformConversation()
+	vendor := eventgptapi.VendorResult{VendorID: uuid.New(), ServiceID: uuid.New(), VendorName: "Lens & Light"}
+	conv.ShortTermMemory["vendor_results"] = []eventgptapi.VendorResult{vendor}
+
+	// Both messages touch short-term memory: the save writes the selected
+	// vendor and the recall reads saves back into it
+	messages := []string{
+		"save_vendor:" + vendor.VendorID.String(),
+		"Thanks, that's the saved one I wanted",
+	}
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	errs := make([]error, len(messages))
+	for i, msg := range messages {
+		wg.Add(1)
+		go func(i int, msg string) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = dm.ProcessMessage(context.Background(), conv, msg)
+		}(i, msg)
+	}
+	close(start)
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, conv.TurnCount)
+	require.Len(t, conv.Messages, 4)
+	for i, msg := range conv.Messages {
+		want := eventgptapi.RoleUser
+		if i%2 == 1 {
+			want = eventgptapi.RoleAssistant
+		}
+		assert.Equal(t, want, msg.Role, "turns must not interleave")
+	}
+	assert.Equal(t, vendor.VendorID, conv.ShortTermMemory["selected_vendor_id"])
+}
+
+// Test Response Variation Selection
+
+func greetingContents(t *testing.T, rg *eventgptapi.ResponseGenerator, conv *eventgptapi.Conversation, n int) []string {
+	t.Helper()
+	strategy := &eventgptapi.ResponseStrategy{Type: eventgptapi.ResponseText, Template: "greeting_new"}
+	contents := []string{}
+	for i := 0; i < n; i++ {
+		response, err := rg.GenerateResponse(context.Background(), conv, strategy, nil)
+		require.NoError(t, err)
+		contents = append(contents, response.Content)
+	}
+	return contents
+}
+
+func TestGenerateResponse_NoRepeatUntilVariationsExhausted(t *testing.T) {
+	variations := len(eventgptapi.ResponseTemplates["greeting_new"].Variations)
+	require.Greater(t, variations, 2)
+
+	for seed := int64(1); seed <= 20; seed++ {
+		rg := eventgptapi.NewResponseGenerator(nil)
+		rg.SetRandSeed(seed)
+		conv := newPlatformConversation()
+
+		contents := greetingContents(t, rg, conv, 3*variations)
+		for start := 0; start+variations <= len(contents); start++ {
+			window := map[string]bool{}
+			for _, content := range contents[start : start+variations] {
+				window[content] = true
+			}
+			assert.Len(t, window, variations, "seed %d, calls %d-%d", seed, start, start+variations)
+		}
+	}
+}
+
+func TestGenerateResponse_VariationTieBreakIsSeeded(t *testing.T) {
+	first := eventgptapi.NewResponseGenerator(nil)
+	first.SetRandSeed(42)
+	second := eventgptapi.NewResponseGenerator(nil)
+	second.SetRandSeed(42)
+
+	assert.Equal(t,
+		greetingContents(t, first, newPlatformConversation(), 6),
+		greetingContents(t, second, newPlatformConversation(), 6))
+}
+
+func TestGenerateResponse_VariationHistoryResetsAfterIdleTurns(t *testing.T) {
+	rg := eventgptapi.NewResponseGenerator(nil)
+	rg.SetRandSeed(7)
+	conv := newPlatformConversation()
+
+	greetingContents(t, rg, conv, 2)
+	assert.Len(t, conv.ShortTermMemory["recent_variations:greeting_new"], 2)
+
+	conv.TurnCount += 10
+	greetingContents(t, rg, conv, 1)
+	assert.Len(t, conv.ShortTermMemory["recent_variations:greeting_new"], 1)
+}
+
+// Test Knowledge Base Answers
+
+func seededKnowledgeBase() *eventgptapi.MemoryKnowledgeBase {
+	return eventgptapi.NewMemoryKnowledgeBase(
+		eventgptapi.KBArticle{
+			ID:    uuid.New(),
+			Title: "Paying a vendor deposit",
+			Body:  "Bookings are secured with a deposit. You pay a 30% deposit when the vendor accepts, held in escrow until the event. The balance is due a week before.",
+			Tags:  []string{"payments", "escrow"},
+		},
+		eventgptapi.KBArticle{
+			ID:    uuid.New(),
+			Title: "Cancelling a booking",
+			Body:  "You can cancel from the booking page. Refunds depend on how close to the event you cancel.",
+			Tags:  []string{"refunds"},
+		},
+	)
+}
+
+func TestSearchKnowledgeBase_MatchingQuery(t *testing.T) {
+	match, err := eventgptapi.SearchKnowledgeBase(context.Background(), seededKnowledgeBase(),
+		"How much deposit do I pay?", eventgptapi.DefaultKBMinRank)
+
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	assert.Equal(t, "Paying a vendor deposit", match.Title)
+	assert.Equal(t, "Bookings are secured with a deposit.", match.Snippet)
+	assert.GreaterOrEqual(t, match.Rank, eventgptapi.DefaultKBMinRank)
+}
+
+func TestSearchKnowledgeBase_NoMatch(t *testing.T) {
+	match, err := eventgptapi.SearchKnowledgeBase(context.Background(), seededKnowledgeBase(),
+		"What is the capital of Mongolia?", eventgptapi.DefaultKBMinRank)
+
+	require.NoError(t, err)
+	assert.Nil(t, match)
+
+	_, err = eventgptapi.SearchKnowledgeBase(context.Background(), nil, "deposit", eventgptapi.DefaultKBMinRank)
+	assert.ErrorIs(t, err, eventgptapi.ErrKnowledgeBaseUnavailable)
+}
+
+func TestProcessMessage_AnswersGeneralQuestionFromKnowledgeBase(t *testing.T) {
+	dm := eventgptapi.NewDialogManager(nil, nil)
+	dm.SetKnowledgeBase(seededKnowledgeBase())
+
+	response, err := dm.ProcessMessage(context.Background(), newPlatformConversation(), "Tell me about refunds")
+
+	require.NoError(t, err)
+	assert.Contains(t, response.Content, "Refunds depend on how close to the event you cancel.")
+	assert.NotContains(t, response.Content, "{kb_answer}")
+}
+
+func TestProcessMessage_OffersHandoffWhenKnowledgeBaseHasNoAnswer(t *testing.T) {
+	dm := eventgptapi.NewDialogManager(nil, nil)
+	dm.SetKnowledgeBase(seededKnowledgeBase())
+
+	response, err := dm.ProcessMessage(context.Background(), newPlatformConversation(), "What is the capital of Mongolia?")
+
+	require.NoError(t, err)
+	payloads := []string{}
+	for _, reply := range response.QuickReplies {
+		payloads = append(payloads, reply.Payload)
+	}
+	assert.Contains(t, payloads, "handoff:request")
+	assert.NotContains(t, response.Content, "{kb_answer}")
+}
+
+// Test Support Handoff
+
+func newHandoffDialog() (*eventgptapi.DialogManager, *eventgptapi.MemoryHandoffStore) {
+	dm := eventgptapi.NewDialogManager(nil, nil)
+	dm.SetKnowledgeBase(seededKnowledgeBase())
+	store := eventgptapi.NewMemoryHandoffStore()
+	dm.SetHandoffStore(store)
+	return dm, store
+}
+
+func TestHandoff_RepeatedFailedTurnsQueueOnce(t *testing.T) {
+	dm, store := newHandoffDialog()
+	conv := newPlatformConversation()
+	ctx := context.Background()
+
+	for i := 0; i < eventgptapi.MaxFailedTurns-1; i++ {
+		_, err := dm.ProcessMessage(ctx, conv, "What is the capital of Mongolia?")
+		require.NoError(t, err)
+	}
+	open, _ := store.Handoffs(ctx, eventgptapi.HandoffOpen)
+	assert.Empty(t, open)
+
+	for i := 0; i < 3; i++ {
+		_, err := dm.ProcessMessage(ctx, conv, "What is the capital of Mongolia?")
+		require.NoError(t, err)
+	}
+
+	open, _ = store.Handoffs(ctx, eventgptapi.HandoffOpen)
+	require.Len(t, open, 1)
+	assert.Equal(t, conv.ID, open[0].ConversationID)
+	assert.Equal(t, conv.UserID, open[0].UserID)
+	assert.Equal(t, eventgptapi.HandoffReasonFailedTurns, open[0].Reason)
+	assert.Equal(t, "ask_question", open[0].LastIntent)
+	assert.Equal(t, eventgptapi.StateHandoff, conv.ConversationState)
+
+	// Asking for a person after the handoff does not queue it twice
+	_, err := dm.ProcessMessage(ctx, conv, eventgptapi.HandoffRequestPayload)
+	require.NoError(t, err)
+	open, _ = store.Handoffs(ctx, eventgptapi.HandoffOpen)
+	assert.Len(t, open, 1)
+}
+
+func TestHandoff_AnsweredTurnResetsFailureCount(t *testing.T) {
+	dm, store := newHandoffDialog()
+	conv := newPlatformConversation()
+	ctx := context.Background()
+
+	for _, message := range []string{
+		"What is the capital of Mongolia?",
+		"What is the capital of Mongolia?",
+		"Tell me about refunds",
+		"What is the capital of Mongolia?",
+		"What is the capital of Mongolia?",
+	} {
+		_, err := dm.ProcessMessage(ctx, conv, message)
+		require.NoError(t, err)
+	}
+
+	open, _ := store.Handoffs(ctx, eventgptapi.HandoffOpen)
+	assert.Empty(t, open)
+	assert.NotEqual(t, eventgptapi.StateHandoff, conv.ConversationState)
+}
+
+func TestHandoff_RequestedByQuickReply(t *testing.T) {
+	dm, store := newHandoffDialog()
+	conv := newPlatformConversation()
+	ctx := context.Background()
+
+	_, err := dm.ProcessMessage(ctx, conv, "Tell me about refunds")
+	require.NoError(t, err)
+	response, err := dm.ProcessMessage(ctx, conv, eventgptapi.HandoffRequestPayload)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, response.Content)
+	open, _ := store.Handoffs(ctx, eventgptapi.HandoffOpen)
+	require.Len(t, open, 1)
+	assert.Equal(t, eventgptapi.HandoffReasonRequested, open[0].Reason)
+	assert.Equal(t, "ask_question", open[0].LastIntent)
+}
+
+// Test Booking Confirmation
+
+type stubBookingService struct {
+	requests []eventgptapi.BookingRequest
+	booking  *eventgptapi.ConfirmedBooking
+	err      error
+}
+
+func (s *stubBookingService) CreateBooking(ctx context.Context, req eventgptapi.BookingRequest) (*eventgptapi.ConfirmedBooking, error) {
+	s.requests = append(s.requests, req)
+	return s.booking, s.err
+}
+
+func TestConfirmBooking_BooksTheDraft(t *testing.T) {
+	bookingID := uuid.New()
+	bookings := &stubBookingService{booking: &eventgptapi.ConfirmedBooking{
+		ID:          bookingID,
+		Reference:   "BK-20270512-0042",
+		TotalAmount: 161250,
+	}}
+	dm := eventgptapi.NewDialogManager(nil, nil)
+	dm.SetBookingService(bookings)
+	conv := newPlatformConversation()
+	draft := eventgptapi.BookingDraft{
+		VendorID:    uuid.New(),
+		ServiceID:   uuid.New(),
+		Date:        time.Date(2027, 5, 12, 0, 0, 0, 0, time.UTC),
+		TotalAmount: 150000,
+	}
+	eventgptapi.RememberBookingDraft(conv, draft)
+	conv.ShortTermMemory["selected_vendor_name"] = "Lens & Light Studio"
+
+	response, err := dm.ProcessMessage(context.Background(), conv, eventgptapi.BookingConfirmPayload)
+	require.NoError(t, err)
+
+	require.Len(t, bookings.requests, 1)
+	assert.Equal(t, eventgptapi.BookingRequest{
+		UserID:         conv.UserID,
+		VendorID:       draft.VendorID,
+		ServiceID:      draft.ServiceID,
+		Date:           draft.Date,
+		ConversationID: conv.ID,
+	}, bookings.requests[0])
+	assert.Contains(t, response.Content, "Lens & Light Studio")
+	assert.Contains(t, response.Content, "BK-20270512-0042")
+	assert.Contains(t, response.Content, "12 May 2027")
+	assert.Contains(t, response.Content, "₦161,250")
+	assert.Equal(t, bookingID, conv.ShortTermMemory["booking_id"])
+	_, stillDrafted := eventgptapi.BookingDraftFromMemory(conv.ShortTermMemory)
+	assert.False(t, stillDrafted, "a booked draft cannot be confirmed again")
+	assert.Equal(t, eventgptapi.StateCompleted, conv.ConversationState)
+}
+
+func TestConfirmBooking_MissingDraft(t *testing.T) {
+	bookings := &stubBookingService{}
+	dm := eventgptapi.NewDialogManager(nil, nil)
+	dm.SetBookingService(bookings)
+	conv := newPlatformConversation()
+
+	response, err := dm.ProcessMessage(context.Background(), conv, eventgptapi.BookingConfirmPayload)
+	require.NoError(t, err)
+
+	assert.Empty(t, bookings.requests)
+	assert.Contains(t, response.Content, "don't have a booking ready")
+	require.NotEmpty(t, response.QuickReplies)
+	assert.Equal(t, "intent:find_vendor", response.QuickReplies[0].Payload)
+	assert.NotContains(t, conv.ShortTermMemory, "booking_id")
+	assert.Equal(t, eventgptapi.StateRecommending, conv.ConversationState)
+}
+
+func TestConfirmBooking_ServiceErrorOffersRetry(t *testing.T) {
+	bookings := &stubBookingService{err: errors.New("service not found or inactive")}
+	dm := eventgptapi.NewDialogManager(nil, nil)
+	dm.SetBookingService(bookings)
+	conv := newPlatformConversation()
+	eventgptapi.RememberBookingDraft(conv, eventgptapi.BookingDraft{
+		VendorID:  uuid.New(),
+		ServiceID: uuid.New(),
+		Date:      time.Now().AddDate(0, 2, 0),
+	})
+
+	response, err := dm.ProcessMessage(context.Background(), conv, eventgptapi.BookingConfirmPayload)
+	require.NoError(t, err)
+
+	assert.Len(t, bookings.requests, 1)
+	require.NotEmpty(t, response.QuickReplies)
+	assert.Equal(t, eventgptapi.BookingConfirmPayload, response.QuickReplies[0].Payload)
+	_, drafted := eventgptapi.BookingDraftFromMemory(conv.ShortTermMemory)
+	assert.True(t, drafted, "the draft is kept so the booking can be retried")
+}
+
+func TestConfirmBooking_AnonymousUserMustSignIn(t *testing.T) {
+	bookings := &stubBookingService{}
+	dm := eventgptapi.NewDialogManager(nil, nil)
+	dm.SetBookingService(bookings)
+	conv := newPlatformConversation()
+	conv.UserID = eventgptapi.AnonymousUserID
+	eventgptapi.RememberBookingDraft(conv, eventgptapi.BookingDraft{
+		VendorID:  uuid.New(),
+		ServiceID: uuid.New(),
+		Date:      time.Now().AddDate(0, 2, 0),
+	})
+
+	response, err := dm.ProcessMessage(context.Background(), conv, eventgptapi.BookingConfirmPayload)
+	require.NoError(t, err)
+
+	assert.Empty(t, bookings.requests)
+	assert.Contains(t, response.Content, "sign in")
+	_, drafted := eventgptapi.BookingDraftFromMemory(conv.ShortTermMemory)
+	assert.True(t, drafted, "the draft is kept for after sign in")
+}
+
+func TestChat_RefusesAnotherUsersConversation(t *testing.T) {
+	api := eventgptapi.NewEventGPTAPI(nil, nil)
+	ctx := context.Background()
+	owner := uuid.New()
+
+	started, err := api.Chat(ctx, owner, eventgptapi.ChatRequest{Message: "Help me plan a wedding", Channel: eventgptapi.ChannelWeb})
+	require.NoError(t, err)
+
+	_, err = api.Chat(ctx, uuid.New(), eventgptapi.ChatRequest{ConversationID: &started.ConversationID, Message: eventgptapi.BookingConfirmPayload})
+	assert.ErrorIs(t, err, eventgptapi.ErrConversationForbidden)
+	_, err = api.Chat(ctx, eventgptapi.AnonymousUserID, eventgptapi.ChatRequest{ConversationID: &started.ConversationID, Message: "hello"})
+	assert.ErrorIs(t, err, eventgptapi.ErrConversationForbidden)
+	_, err = api.Chat(ctx, owner, eventgptapi.ChatRequest{ConversationID: &started.ConversationID, Message: "It's on 12/05/2027"})
+	assert.NoError(t, err)
+}
+
+func TestBookingDraftFromMemory_SurvivesJSONRoundTrip(t *testing.T) {
+	conv := newPlatformConversation()
+	draft := eventgptapi.BookingDraft{
+		VendorID:    uuid.New(),
+		ServiceID:   uuid.New(),
+		Date:        time.Date(2027, 5, 12, 0, 0, 0, 0, time.UTC),
+		TotalAmount: 150000,
+	}
+	eventgptapi.RememberBookingDraft(conv, draft)
+
+	raw, err := json.Marshal(conv.ShortTermMemory)
+	require.NoError(t, err)
+	var memory map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &memory))
+
+	got, ok := eventgptapi.BookingDraftFromMemory(memory)
+	require.True(t, ok)
+	assert.Equal(t, draft, *got)
+}
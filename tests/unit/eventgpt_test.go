@@ -4,10 +4,21 @@
 package unit
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	eventgptapi "github.com/BillyRonksGlobal/vendorplatform/api/eventgpt"
 	"github.com/BillyRonksGlobal/vendorplatform/internal/eventgpt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 // TestIntentClassification tests the intent classification logic
@@ -66,6 +77,7 @@ func TestIntentClassification(t *testing.T) {
 
 	// Create a minimal service for testing
 	service := &eventgpt.Service{}
+	assert.NotNil(t, service)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -130,6 +142,7 @@ func TestSlotExtraction(t *testing.T) {
 	}
 
 	service := &eventgpt.Service{}
+	assert.NotNil(t, service)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -310,3 +323,318 @@ func TestWelcomeMessage(t *testing.T) {
 	// This would test the generateWelcomeMessage method
 	assert.NotNil(t, service)
 }
+
+// TestWelcomeFlowsByEntryPoint tests that the opening message depends on where
+// the conversation was started from
+func TestWelcomeFlowsByEntryPoint(t *testing.T) {
+	service := eventgpt.NewService(nil, nil, nil, zap.NewNop())
+	userID := uuid.New()
+	vendorID := uuid.New()
+
+	quickReplies := func(conv *eventgpt.Conversation) []string {
+		return conv.Messages[0].Metadata["quick_replies"].([]string)
+	}
+
+	direct := service.NewConversation(userID, nil)
+	photographer := service.NewConversation(userID, &eventgpt.EntryContext{
+		Source:         eventgpt.EntryVendorPage,
+		VendorID:       &vendorID,
+		VendorName:     "Lens & Light Studios",
+		VendorCategory: "Photographer",
+	})
+	caterer := service.NewConversation(userID, &eventgpt.EntryContext{
+		Source:         eventgpt.EntryVendorPage,
+		VendorCategory: "caterer",
+	})
+	campaign := service.NewConversation(userID, &eventgpt.EntryContext{
+		Source:     eventgpt.EntryCampaign,
+		CampaignID: "owambe-2026",
+		EventType:  "Birthday",
+	})
+
+	t.Run("Direct visit keeps the generic welcome", func(t *testing.T) {
+		assert.Equal(t, []string{"Plan a wedding", "Find vendors", "Get quotes", "Just browsing"}, quickReplies(direct))
+		assert.Empty(t, direct.Slots)
+	})
+
+	t.Run("Photographer page opens with photographer options", func(t *testing.T) {
+		assert.Contains(t, quickReplies(photographer), "See photo packages")
+		assert.NotEqual(t, quickReplies(direct), quickReplies(photographer))
+		assert.Contains(t, photographer.Messages[0].Content, "Lens & Light Studios")
+		assert.Equal(t, "photographer", photographer.Slots[eventgpt.SlotVendorType])
+		assert.Equal(t, vendorID.String(), photographer.Context["vendor_id"])
+	})
+
+	t.Run("Each vendor category gets its own options", func(t *testing.T) {
+		assert.Contains(t, quickReplies(caterer), "See menu options")
+		assert.NotEqual(t, quickReplies(photographer), quickReplies(caterer))
+		assert.Equal(t, "caterer", caterer.Slots[eventgpt.SlotVendorType])
+	})
+
+	t.Run("Unknown vendor category falls back to the vendor page flow", func(t *testing.T) {
+		conv := service.NewConversation(userID, &eventgpt.EntryContext{
+			Source:         eventgpt.EntryVendorPage,
+			VendorCategory: "florist",
+		})
+		assert.Equal(t, []string{"Check availability", "Get a quote", "See similar vendors", "Plan my event"}, quickReplies(conv))
+		assert.Contains(t, conv.Messages[0].Content, "this vendor")
+	})
+
+	t.Run("Campaign pre-fills the event type", func(t *testing.T) {
+		assert.Contains(t, quickReplies(campaign), "See featured vendors")
+		assert.Equal(t, "birthday", campaign.Slots[eventgpt.SlotEventType])
+		assert.Equal(t, "owambe-2026", campaign.Context["campaign_id"])
+		assert.Contains(t, campaign.Messages[0].Content, "Birthday")
+	})
+
+	t.Run("Configured flows override the defaults", func(t *testing.T) {
+		custom := eventgpt.NewService(nil, nil, &eventgpt.Config{
+			WelcomeFlows: map[string]eventgpt.WelcomeFlow{
+				eventgpt.EntryDirect: {Greeting: "Hello", QuickReplies: []string{"Go"}},
+				eventgpt.EntryCampaign: {
+					Greeting:     "Valentine's special!",
+					QuickReplies: []string{"Book a dinner"},
+					Slots:        map[eventgpt.Slot]interface{}{eventgpt.SlotEventType: "anniversary"},
+				},
+			},
+		}, zap.NewNop())
+
+		conv := custom.NewConversation(userID, &eventgpt.EntryContext{Source: eventgpt.EntryCampaign})
+		assert.Equal(t, []string{"Book a dinner"}, quickReplies(conv))
+		assert.Equal(t, "anniversary", conv.Slots[eventgpt.SlotEventType])
+	})
+}
+
+// TestConversationHistoryPersisted tests that each turn's user message and
+// reply are saved and come back from GetConversation in order
+func TestConversationHistoryPersisted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := eventgpt.NewService(nil, nil, nil, zap.NewNop())
+	service.SetConversationStore(eventgpt.NewMemoryConversationStore())
+	router := gin.New()
+	eventgptapi.NewHandler(service, nil, nil, nil, zap.NewNop()).RegisterRoutes(router.Group("/api/v1"))
+
+	do := func(method, path string, body interface{}) map[string]interface{} {
+		var payload bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&payload).Encode(body))
+		}
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, &payload)
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		require.Less(t, w.Code, 300, w.Body.String())
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+		return decoded
+	}
+
+	started := do(http.MethodPost, "/api/v1/eventgpt/conversations", gin.H{})
+	conversationPath := "/api/v1/eventgpt/conversations/" + started["conversation_id"].(string)
+
+	sent := []string{
+		"I'm planning a wedding",
+		"It's in Lagos for 200 guests",
+		"I need to find a photographer",
+	}
+	var replies []string
+	for _, text := range sent {
+		reply := do(http.MethodPost, conversationPath+"/messages", gin.H{"message": text})
+		replies = append(replies, reply["message"].(map[string]interface{})["content"].(string))
+	}
+
+	conversation := do(http.MethodGet, conversationPath, nil)
+	assert.EqualValues(t, 3, conversation["turn_count"])
+
+	// The welcome message opens the history, then each turn adds two
+	messages := conversation["messages"].([]interface{})
+	require.Len(t, messages, 7)
+	assert.Equal(t, "assistant", messages[0].(map[string]interface{})["role"])
+	for i, text := range sent {
+		user := messages[1+2*i].(map[string]interface{})
+		reply := messages[2+2*i].(map[string]interface{})
+		assert.Equal(t, "user", user["role"])
+		assert.Equal(t, text, user["content"])
+		assert.NotEmpty(t, user["timestamp"])
+		assert.Equal(t, "assistant", reply["role"])
+		assert.Equal(t, replies[i], reply["content"])
+		assert.NotEmpty(t, reply["timestamp"])
+	}
+}
+
+// TestConversationStoreRoundTrip tests that messages survive the JSON encoding
+// used for the JSONB column
+func TestConversationStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := eventgpt.NewMemoryConversationStore()
+	service := eventgpt.NewService(nil, nil, nil, zap.NewNop())
+	conversation := service.NewConversation(uuid.New(), nil)
+	require.NoError(t, store.CreateConversation(ctx, conversation))
+
+	conversation.Messages = append(conversation.Messages, eventgpt.Message{
+		ID:      uuid.New(),
+		Role:    "user",
+		Content: "Wedding in Abuja",
+		Intent:  eventgpt.IntentCreateEvent,
+		Slots:   map[eventgpt.Slot]interface{}{eventgpt.SlotLocation: "Abuja"},
+	})
+	conversation.TurnCount++
+	require.NoError(t, store.UpdateConversation(ctx, conversation))
+
+	loaded, err := store.GetConversation(ctx, conversation.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Messages, 2)
+	assert.Equal(t, conversation.Messages[1].ID, loaded.Messages[1].ID)
+	assert.Equal(t, eventgpt.IntentCreateEvent, loaded.Messages[1].Intent)
+	assert.Equal(t, "Abuja", loaded.Messages[1].Slots[eventgpt.SlotLocation])
+	assert.Equal(t, 1, loaded.TurnCount)
+
+	// Changes to the loaded copy don't reach the store until saved
+	loaded.Messages = loaded.Messages[:1]
+	again, err := store.GetConversation(ctx, conversation.ID)
+	require.NoError(t, err)
+	assert.Len(t, again.Messages, 2)
+
+	_, err = store.GetConversation(ctx, uuid.New())
+	assert.ErrorIs(t, err, eventgpt.ErrConversationNotFound)
+}
+
+// TestHandoffQueueEndpoint tests that agents can list open handoffs
+func TestHandoffQueueEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := eventgptapi.NewMemoryHandoffStore()
+	conversationID := uuid.New()
+	_, err := store.CreateHandoff(context.Background(), eventgptapi.Handoff{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		UserID:         uuid.New(),
+		Reason:         eventgptapi.HandoffReasonRequested,
+		Status:         eventgptapi.HandoffOpen,
+		CreatedAt:      time.Now(),
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	eventgptapi.NewHandler(nil, nil, nil, store, zap.NewNop()).RegisterRoutes(router.Group("/api/v1"))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/v1/eventgpt/handoffs?status=open")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Handoffs []eventgptapi.Handoff `json:"handoffs"`
+		Count    int                   `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, 1, body.Count)
+	assert.Equal(t, conversationID, body.Handoffs[0].ConversationID)
+
+	w = get("/api/v1/eventgpt/handoffs?status=resolved")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 0, body.Count)
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/eventgpt/handoffs?status=closed").Code)
+}
+
+// TestSendMessageUsesDialogManager tests that with the dialog manager wired
+// in, messages come back as structured chat responses that gather the
+// event's details
+func TestSendMessageUsesDialogManager(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := eventgpt.NewService(nil, nil, nil, zap.NewNop())
+	service.SetConversationStore(eventgpt.NewMemoryConversationStore())
+	router := gin.New()
+	eventgptapi.NewHandler(service, eventgptapi.NewEventGPTAPI(nil, nil), nil, nil, zap.NewNop()).RegisterRoutes(router.Group("/api/v1"))
+
+	post := func(path string, body interface{}) *httptest.ResponseRecorder {
+		var payload bytes.Buffer
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, &payload)
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/api/v1/eventgpt/conversations", gin.H{})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var started struct {
+		ConversationID string `json:"conversation_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	messagesPath := "/api/v1/eventgpt/conversations/" + started.ConversationID + "/messages"
+
+	chat := func(message string) eventgptapi.ChatResponse {
+		w := post(messagesPath, gin.H{"message": message})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response eventgptapi.ChatResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	first := chat("Help me plan a wedding")
+	assert.Equal(t, started.ConversationID, first.ConversationID.String())
+	assert.Equal(t, eventgptapi.StateGatheringInfo, first.State)
+	assert.Equal(t, eventgptapi.RoleAssistant, first.Message.Role)
+	assert.Contains(t, []string{
+		"When is your wedding? You can give me an exact date or just a general timeframe like 'next December' or 'summer 2025'.",
+		"What date do you have in mind for your wedding?",
+	}, first.Message.Content)
+
+	// The dialog state carries over, so the next question moves on
+	second := chat("We're planning the wedding for 12/05/2027")
+	assert.Equal(t, eventgptapi.StateGatheringInfo, second.State)
+	assert.Contains(t, []string{
+		"How many guests are you expecting at your wedding?",
+		"Approximately how many people will be attending?",
+	}, second.Message.Content)
+}
+
+// TestStartConversationAttributesTheCaller tests that a conversation belongs
+// to the authenticated user, not to a user_id in the body, and to the
+// anonymous user without a login
+func TestStartConversationAttributesTheCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := eventgpt.NewService(nil, nil, nil, zap.NewNop())
+	service.SetConversationStore(eventgpt.NewMemoryConversationStore())
+	userID := uuid.New()
+	handler := eventgptapi.NewHandler(service, nil, nil, nil, zap.NewNop())
+	handler.SetOptionalAuthMiddleware(func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Set("user_id", userID)
+		}
+		c.Next()
+	})
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	start := func(authorization string) string {
+		var payload bytes.Buffer
+		require.NoError(t, json.NewEncoder(&payload).Encode(gin.H{"user_id": uuid.New().String()}))
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/eventgpt/conversations", &payload)
+		req.Header.Set("Content-Type", "application/json")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var started struct {
+			ConversationID string `json:"conversation_id"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+		conversation, err := service.GetConversation(context.Background(), uuid.MustParse(started.ConversationID))
+		require.NoError(t, err)
+		return conversation.UserID.String()
+	}
+
+	assert.Equal(t, userID.String(), start("Bearer token"))
+	assert.Equal(t, eventgptapi.AnonymousUserID.String(), start(""))
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/geo"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Geo Distances and Nearby Vendors
+
+var (
+	geoLagos    = geo.GeoPoint{Latitude: 6.5244, Longitude: 3.3792}
+	geoAbuja    = geo.GeoPoint{Latitude: 9.0765, Longitude: 7.3986}
+	geoLondon   = geo.GeoPoint{Latitude: 51.5074, Longitude: -0.1278}
+	geoParis    = geo.GeoPoint{Latitude: 48.8566, Longitude: 2.3522}
+	geoNewYork  = geo.GeoPoint{Latitude: 40.7128, Longitude: -74.0060}
+	geoLA       = geo.GeoPoint{Latitude: 34.0522, Longitude: -118.2437}
+	geoSydney   = geo.GeoPoint{Latitude: -33.8688, Longitude: 151.2093}
+	geoAuckland = geo.GeoPoint{Latitude: -36.8485, Longitude: 174.7633}
+)
+
+func TestDistanceMatchesKnownCityPairs(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b geo.GeoPoint
+		km   float64
+	}{
+		{"Lagos to Abuja", geoLagos, geoAbuja, 526},
+		{"London to Paris", geoLondon, geoParis, 344},
+		{"New York to Los Angeles", geoNewYork, geoLA, 3936},
+		{"Sydney to Auckland", geoSydney, geoAuckland, 2156},
+		{"London to New York", geoLondon, geoNewYork, 5570},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.km, geo.Distance(tt.a, tt.b), tt.km*0.005)
+			assert.InDelta(t, geo.Distance(tt.a, tt.b), geo.Distance(tt.b, tt.a), 1e-9)
+		})
+	}
+
+	assert.Zero(t, geo.Distance(geoLagos, geoLagos))
+}
+
+func TestBoundingBoxHoldsEveryPointInRadius(t *testing.T) {
+	centers := []struct {
+		name   string
+		center geo.GeoPoint
+		radius float64
+	}{
+		{"Lagos", geoLagos, 50},
+		{"London wide", geoLondon, 800},
+		{"near the north pole", geo.GeoPoint{Latitude: 89.5, Longitude: 20}, 100},
+		{"across the antimeridian", geo.GeoPoint{Latitude: -17.7, Longitude: 179.8}, 150},
+		{"south", geo.GeoPoint{Latitude: -60, Longitude: -70}, 400},
+	}
+	for _, c := range centers {
+		t.Run(c.name, func(t *testing.T) {
+			box := geo.BoundingBoxAround(c.center, c.radius)
+			for lat := -90.0; lat <= 90; lat += 0.25 {
+				for lng := -180.0; lng <= 180; lng += 0.25 {
+					p := geo.GeoPoint{Latitude: lat, Longitude: lng}
+					if geo.Distance(c.center, p) <= c.radius && !box.Contains(p) {
+						t.Fatalf("%v is %.1fkm away but outside %+v", p, geo.Distance(c.center, p), box)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBoundingBoxIsTight(t *testing.T) {
+	box := geo.BoundingBoxAround(geoLagos, 50)
+	assert.False(t, box.CrossesAntimeridian())
+	assert.InDelta(t, 0.45, box.MaxLat-geoLagos.Latitude, 0.01)
+	assert.True(t, box.Contains(geoLagos))
+	assert.False(t, box.Contains(geoAbuja))
+
+	// Just beyond the radius due north and due east
+	assert.False(t, box.Contains(geo.GeoPoint{Latitude: geoLagos.Latitude + 0.46, Longitude: geoLagos.Longitude}))
+	assert.False(t, box.Contains(geo.GeoPoint{Latitude: geoLagos.Latitude, Longitude: geoLagos.Longitude + 0.46}))
+}
+
+func TestBoundingBoxAcrossAntimeridian(t *testing.T) {
+	box := geo.BoundingBoxAround(geo.GeoPoint{Latitude: -17.7, Longitude: 179.9}, 50)
+
+	assert.True(t, box.CrossesAntimeridian())
+	assert.True(t, box.Contains(geo.GeoPoint{Latitude: -17.7, Longitude: -179.9}))
+	assert.True(t, box.Contains(geo.GeoPoint{Latitude: -17.7, Longitude: 179.7}))
+	assert.False(t, box.Contains(geo.GeoPoint{Latitude: -17.7, Longitude: 0}))
+}
+
+func TestBoundingBoxReachingPoleCoversAllLongitudes(t *testing.T) {
+	box := geo.BoundingBoxAround(geo.GeoPoint{Latitude: 89.9, Longitude: 0}, 50)
+
+	assert.Equal(t, 90.0, box.MaxLat)
+	assert.True(t, box.Contains(geo.GeoPoint{Latitude: 89.8, Longitude: 180}))
+	assert.True(t, box.Contains(geo.GeoPoint{Latitude: 89.8, Longitude: -90}))
+}
+
+func TestFallbackLocatorFindsNearestVendorsInCategory(t *testing.T) {
+	candidates := geo.NewMemoryCandidates()
+	yaba := geo.NearbyVendor{VendorID: uuid.New(), Name: "Yaba Plumbing", Location: geo.GeoPoint{Latitude: 6.5095, Longitude: 3.3711}}
+	ikeja := geo.NearbyVendor{VendorID: uuid.New(), Name: "Ikeja Plumbing", Location: geo.GeoPoint{Latitude: 6.6018, Longitude: 3.3515}}
+	lekki := geo.NearbyVendor{VendorID: uuid.New(), Name: "Lekki Catering", Location: geo.GeoPoint{Latitude: 6.4698, Longitude: 3.5852}}
+	abuja := geo.NearbyVendor{VendorID: uuid.New(), Name: "Abuja Plumbing", Location: geoAbuja}
+	candidates.AddVendor(ikeja, "plumbing")
+	candidates.AddVendor(yaba, "plumbing")
+	candidates.AddVendor(lekki, "catering")
+	candidates.AddVendor(abuja, "plumbing")
+
+	svc := geo.NewService(nil)
+	svc.SetLocator(geo.NewFallbackLocator(candidates))
+
+	nearby, err := svc.NearbyVendors(context.Background(), geoLagos, 25, "Plumbing")
+	require.NoError(t, err)
+	require.Len(t, nearby, 2)
+	assert.Equal(t, yaba.VendorID, nearby[0].VendorID)
+	assert.Equal(t, ikeja.VendorID, nearby[1].VendorID)
+	assert.InDelta(t, geo.Distance(geoLagos, yaba.Location), nearby[0].DistanceKM, 1e-9)
+
+	all, err := svc.NearbyVendors(context.Background(), geoLagos, 25, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestGeoServiceNeedsLocatorWithoutDatabase(t *testing.T) {
+	_, err := geo.NewService(nil).NearbyVendors(context.Background(), geoLagos, 10, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,1392 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	homerescueapi "github.com/BillyRonksGlobal/vendorplatform/api/homerescue"
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
+	"github.com/BillyRonksGlobal/vendorplatform/internal/homerescue"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Test Proximity Notifications
+
+func TestProximityNotifier_EachThresholdFiresOnce(t *testing.T) {
+	notifier := homerescueapi.NewProximityNotifier(nil, homerescueapi.DefaultProximityThresholds())
+	ctx := context.Background()
+	requestID := uuid.New()
+
+	fired := map[string]int{}
+	for _, eta := range []int{18, 14, 11, 10, 9, 7, 6, 5, 4, 4, 3, 3, 3} {
+		if threshold := notifier.Check(ctx, requestID, eta); threshold != nil {
+			fired[threshold.Key]++
+		}
+	}
+
+	assert.Equal(t, map[string]int{"10_min": 1, "5_min": 1, "arriving": 1}, fired)
+}
+
+func TestProximityNotifier_JumpSendsClosestOnly(t *testing.T) {
+	notifier := homerescueapi.NewProximityNotifier(nil, homerescueapi.DefaultProximityThresholds())
+	ctx := context.Background()
+	requestID := uuid.New()
+
+	assert.Nil(t, notifier.Check(ctx, requestID, 15))
+
+	threshold := notifier.Check(ctx, requestID, 4)
+	if assert.NotNil(t, threshold) {
+		assert.Equal(t, "5_min", threshold.Key)
+	}
+
+	// The skipped 10 minute threshold must not fire late
+	assert.Nil(t, notifier.Check(ctx, requestID, 8))
+	assert.Nil(t, notifier.Check(ctx, requestID, 5))
+}
+
+func TestProximityNotifier_ScopedPerRequest(t *testing.T) {
+	notifier := homerescueapi.NewProximityNotifier(nil, homerescueapi.DefaultProximityThresholds())
+	ctx := context.Background()
+
+	first := notifier.Check(ctx, uuid.New(), 9)
+	second := notifier.Check(ctx, uuid.New(), 9)
+
+	assert.NotNil(t, first)
+	assert.NotNil(t, second)
+}
+
+func TestProximityThreshold_Notification(t *testing.T) {
+	thresholds := homerescueapi.DefaultProximityThresholds()
+
+	n := thresholds[0].Notification("Tunde", 9)
+	assert.Equal(t, "tech_proximity_10_min", n.Type)
+	assert.Equal(t, "Tunde is about 9 minutes away", n.Message)
+
+	arriving := thresholds[2].Notification("", 3)
+	assert.Equal(t, "Your technician is arriving now", arriving.Message)
+}
+
+// Test Service Area Coverage
+
+func TestFilterCoveredCandidates_SmallRadiusExcluded(t *testing.T) {
+	// Request in Ikeja; both techs are within a 10km search radius
+	request := homerescueapi.EmergencyLocation{Latitude: 6.6018, Longitude: 3.3515}
+
+	nearbyNarrow := homerescueapi.TechCandidate{
+		TechID:        uuid.New(),
+		TechName:      "Narrow coverage",
+		Distance:      3,
+		HomeBase:      &homerescueapi.GeoPoint{Latitude: 6.5244, Longitude: 3.3792}, // Yaba, ~9km away
+		ServiceRadius: 5,
+	}
+	wideCoverage := homerescueapi.TechCandidate{
+		TechID:        uuid.New(),
+		TechName:      "Wide coverage",
+		Distance:      6,
+		HomeBase:      &homerescueapi.GeoPoint{Latitude: 6.5244, Longitude: 3.3792},
+		ServiceRadius: 20,
+	}
+	undeclared := homerescueapi.TechCandidate{TechID: uuid.New(), TechName: "No declared area", Distance: 8}
+
+	covered := homerescueapi.FilterCoveredCandidates(
+		[]homerescueapi.TechCandidate{nearbyNarrow, wideCoverage, undeclared}, request)
+
+	var names []string
+	for _, c := range covered {
+		names = append(names, c.TechName)
+	}
+	assert.Equal(t, []string{"Wide coverage", "No declared area"}, names)
+}
+
+// Test Skills Matching
+
+func TestSkillMatching_SubcategoryRequirementExcludesUnskilledTechs(t *testing.T) {
+	request := &homerescueapi.EmergencyRequest{
+		Category:    homerescueapi.CategoryPlumbing,
+		Subcategory: "water_heater",
+	}
+	skills := homerescueapi.RequiredSkills(request)
+	assert.Equal(t, []string{"water_heater"}, skills.Required)
+
+	generalist := homerescueapi.TechCandidate{TechID: uuid.New(), TechName: "Generalist", Skills: []string{"drain_cleaning"}}
+	specialist := homerescueapi.TechCandidate{TechID: uuid.New(), TechName: "Heater specialist", Skills: []string{"water_heater"}}
+	untagged := homerescueapi.TechCandidate{TechID: uuid.New(), TechName: "No skills listed"}
+
+	skilled := homerescueapi.FilterSkilledCandidates(
+		[]homerescueapi.TechCandidate{generalist, specialist, untagged}, skills)
+
+	require.Len(t, skilled, 1)
+	assert.Equal(t, "Heater specialist", skilled[0].TechName)
+	assert.Equal(t, 1.0, skilled[0].SkillMatch)
+}
+
+func TestSkillMatching_IntakeAnswersAddRequirements(t *testing.T) {
+	request := &homerescueapi.EmergencyRequest{
+		Category:      homerescueapi.CategoryPlumbing,
+		IntakeAnswers: map[string]string{"source": "water_heater", "near_electrics": "yes"},
+	}
+	skills := homerescueapi.RequiredSkills(request)
+
+	assert.Equal(t, []string{"water_heater"}, skills.Required)
+	assert.Equal(t, []string{"electrical_safety"}, skills.Preferred)
+
+	// Preferred skills raise the match score without excluding anyone
+	partial, ok := homerescueapi.SkillMatchScore([]string{"water_heater"}, skills)
+	assert.True(t, ok)
+	full, ok := homerescueapi.SkillMatchScore([]string{"water_heater", "electrical_safety"}, skills)
+	assert.True(t, ok)
+	assert.Greater(t, full, partial)
+}
+
+func TestSkillMatching_NoRequirementKeepsEveryone(t *testing.T) {
+	request := &homerescueapi.EmergencyRequest{Category: homerescueapi.CategoryPlumbing, Subcategory: "burst_pipe"}
+	candidates := []homerescueapi.TechCandidate{{TechID: uuid.New()}, {TechID: uuid.New(), Skills: []string{"water_heater"}}}
+
+	skilled := homerescueapi.FilterSkilledCandidates(candidates, homerescueapi.RequiredSkills(request))
+
+	require.Len(t, skilled, 2)
+	assert.Equal(t, 1.0, skilled[0].SkillMatch)
+}
+
+// Test Technician Shifts
+
+// Wednesday 14:00 and 23:00 in Lagos
+var (
+	shiftAfternoon = time.Date(2026, 3, 4, 14, 0, 0, 0, homerescueapi.ShiftTimezone)
+	shiftNight     = time.Date(2026, 3, 4, 23, 0, 0, 0, homerescueapi.ShiftTimezone)
+)
+
+func weekdayHours(emergency bool) []homerescueapi.WorkingHours {
+	var hours []homerescueapi.WorkingHours
+	for day := 1; day <= 5; day++ {
+		hours = append(hours, homerescueapi.WorkingHours{DayOfWeek: day, StartTime: "08:00", EndTime: "18:00", IsEmergency: emergency})
+	}
+	return hours
+}
+
+func TestFilterOnShiftCandidates_InsideWorkingHours(t *testing.T) {
+	tech := homerescueapi.TechCandidate{TechID: uuid.New(), Price: 20000, WorkingHours: weekdayHours(true)}
+
+	kept := homerescueapi.FilterOnShiftCandidates([]homerescueapi.TechCandidate{tech}, shiftAfternoon, homerescueapi.UrgencyCritical)
+
+	require.Len(t, kept, 1)
+	assert.False(t, kept[0].OnCall)
+	assert.Equal(t, 20000.0, kept[0].Price)
+}
+
+func TestFilterOnShiftCandidates_OffShiftExcluded(t *testing.T) {
+	offShift := homerescueapi.TechCandidate{TechID: uuid.New(), WorkingHours: weekdayHours(true)}
+	noEmergencies := homerescueapi.TechCandidate{TechID: uuid.New(), WorkingHours: weekdayHours(false)}
+	unscheduled := homerescueapi.TechCandidate{TechID: uuid.New()}
+
+	kept := homerescueapi.FilterOnShiftCandidates([]homerescueapi.TechCandidate{offShift, unscheduled}, shiftNight, homerescueapi.UrgencyCritical)
+	require.Len(t, kept, 1)
+	assert.Equal(t, unscheduled.TechID, kept[0].TechID, "techs without a schedule are always available")
+
+	// Hours that don't take emergency calls still take scheduled work
+	candidates := []homerescueapi.TechCandidate{noEmergencies}
+	assert.Empty(t, homerescueapi.FilterOnShiftCandidates(candidates, shiftAfternoon, homerescueapi.UrgencyUrgent))
+	candidates = []homerescueapi.TechCandidate{noEmergencies}
+	assert.Len(t, homerescueapi.FilterOnShiftCandidates(candidates, shiftAfternoon, homerescueapi.UrgencyScheduled), 1)
+}
+
+func TestFilterOnShiftCandidates_OnCallIncludedAtPremium(t *testing.T) {
+	tech := homerescueapi.TechCandidate{
+		TechID:       uuid.New(),
+		Price:        20000,
+		WorkingHours: weekdayHours(true),
+		OnCallSchedule: []homerescueapi.OnCallPeriod{
+			{StartTime: shiftNight.Add(-4 * time.Hour), EndTime: shiftNight.Add(8 * time.Hour), Premium: 25},
+			{StartTime: shiftNight.Add(-time.Hour), EndTime: shiftNight.Add(time.Hour), Premium: 40},
+			{StartTime: shiftNight.Add(24 * time.Hour), EndTime: shiftNight.Add(36 * time.Hour), Premium: 90},
+		},
+	}
+
+	kept := homerescueapi.FilterOnShiftCandidates([]homerescueapi.TechCandidate{tech}, shiftNight, homerescueapi.UrgencyCritical)
+
+	require.Len(t, kept, 1)
+	assert.True(t, kept[0].OnCall)
+	assert.Equal(t, 40.0, kept[0].OnCallPremium, "highest overlapping premium applies")
+	assert.InDelta(t, 28000, kept[0].Price, 1e-6)
+}
+
+func TestWorkingHours_OvernightShift(t *testing.T) {
+	night := homerescueapi.WorkingHours{DayOfWeek: 3, StartTime: "22:00", EndTime: "06:00", IsEmergency: true}
+
+	assert.True(t, night.Covers(shiftNight))
+	assert.True(t, night.Covers(shiftNight.Add(6*time.Hour)), "early Thursday belongs to Wednesday's shift")
+	assert.False(t, night.Covers(shiftNight.Add(8*time.Hour)))
+	assert.False(t, night.Covers(shiftAfternoon))
+}
+
+// Test Escalation Locking
+
+// fakeLocker is an in-memory stand-in for the Redis lock shared by instances
+type fakeLocker struct {
+	mu       sync.Mutex
+	held     map[string]bool
+	unlocked []string
+	err      error
+}
+
+func newFakeLocker() *fakeLocker {
+	return &fakeLocker{held: make(map[string]bool)}
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return false, l.err
+	}
+	if l.held[key] {
+		return false, nil
+	}
+	l.held[key] = true
+	return true, nil
+}
+
+func (l *fakeLocker) Unlock(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, key)
+	l.unlocked = append(l.unlocked, key)
+	return nil
+}
+
+// expire drops a lock as its TTL running out would
+func (l *fakeLocker) expire(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, key)
+}
+
+func TestEscalate_SecondConcurrentAttemptIsNoOp(t *testing.T) {
+	locker := newFakeLocker()
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetEscalationLocker(locker)
+	request := &homerescueapi.EmergencyRequest{ID: uuid.New(), UserID: uuid.New()}
+
+	// Another instance is mid-escalation for this request
+	key := "homerescue:escalation:" + request.ID.String()
+	acquired, _ := locker.TryLock(context.Background(), key, time.Minute)
+	assert.True(t, acquired)
+
+	assert.False(t, engine.Escalate(context.Background(), request))
+	assert.Empty(t, locker.unlocked, "must not release a lock it does not hold")
+
+	// Once the other instance's lock expires, escalation goes ahead and
+	// keeps the lock
+	locker.expire(key)
+	assert.True(t, engine.Escalate(context.Background(), request))
+	assert.True(t, locker.held[key])
+}
+
+func TestEscalate_ConcurrentCallsEscalateOnce(t *testing.T) {
+	locker := newFakeLocker()
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetEscalationLocker(locker)
+	request := &homerescueapi.EmergencyRequest{ID: uuid.New(), UserID: uuid.New()}
+
+	results := make(chan bool, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			results <- engine.Escalate(context.Background(), request)
+		}()
+	}
+
+	escalated := 0
+	for i := 0; i < 2; i++ {
+		if <-results {
+			escalated++
+		}
+	}
+	assert.Equal(t, 1, escalated)
+	assert.Empty(t, locker.unlocked)
+}
+
+func TestEscalate_LaterAttemptAfterEscalatingIsNoOp(t *testing.T) {
+	locker := newFakeLocker()
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetEscalationLocker(locker)
+	request := &homerescueapi.EmergencyRequest{ID: uuid.New(), UserID: uuid.New()}
+
+	assert.True(t, engine.Escalate(context.Background(), request))
+	// Another instance's dispatch loop gives up on the same request
+	assert.False(t, engine.Escalate(context.Background(), request))
+}
+
+func TestAssignFromSupport_LetsTheRequestEscalateAgain(t *testing.T) {
+	locker := newFakeLocker()
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetEscalationLocker(locker)
+	ctx := context.Background()
+
+	// Escalated, then handed to a technician by support
+	request := newOfferedRequest(t, engine, uuid.New())
+
+	// That technician falls through too
+	assert.True(t, engine.Escalate(ctx, request))
+}
+
+func TestEscalate_LockStoreDownStillEscalates(t *testing.T) {
+	locker := newFakeLocker()
+	locker.err = errors.New("redis unavailable")
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetEscalationLocker(locker)
+
+	assert.True(t, engine.Escalate(context.Background(), &homerescueapi.EmergencyRequest{ID: uuid.New()}))
+}
+
+// Test Support Escalation Queue
+
+func TestSupportQueue_CriticalBreachedAheadOfSameDay(t *testing.T) {
+	now := time.Now()
+	sameDay := homerescueapi.NewSupportTicket(&homerescueapi.EmergencyRequest{
+		ID:        uuid.New(),
+		Urgency:   homerescueapi.UrgencySameDay,
+		CreatedAt: now.Add(-2 * time.Hour),
+	}, "no technician", now)
+	critical := homerescueapi.NewSupportTicket(&homerescueapi.EmergencyRequest{
+		ID:        uuid.New(),
+		Urgency:   homerescueapi.UrgencyCritical,
+		CreatedAt: now.Add(-45 * time.Minute),
+	}, "no technician", now)
+
+	queue := homerescueapi.OrderSupportQueue([]homerescueapi.SupportTicket{sameDay, critical}, now)
+
+	require.Len(t, queue, 2)
+	assert.Equal(t, critical.RequestID, queue[0].RequestID)
+	assert.True(t, queue[0].SLABreached, "critical request is past its 30 minute SLA")
+	assert.Equal(t, 45, queue[0].WaitingMinutes)
+	assert.Equal(t, sameDay.RequestID, queue[1].RequestID)
+	assert.False(t, queue[1].SLABreached, "same-day request is within its 6 hour SLA")
+}
+
+func TestSupportQueue_SameUrgencyLongestWaitingFirst(t *testing.T) {
+	now := time.Now()
+	newer := homerescueapi.NewSupportTicket(&homerescueapi.EmergencyRequest{
+		ID: uuid.New(), Urgency: homerescueapi.UrgencyUrgent, CreatedAt: now.Add(-10 * time.Minute),
+	}, "", now)
+	older := homerescueapi.NewSupportTicket(&homerescueapi.EmergencyRequest{
+		ID: uuid.New(), Urgency: homerescueapi.UrgencyUrgent, CreatedAt: now.Add(-40 * time.Minute),
+	}, "", now)
+
+	queue := homerescueapi.OrderSupportQueue([]homerescueapi.SupportTicket{newer, older}, now)
+
+	assert.Equal(t, older.RequestID, queue[0].RequestID)
+	assert.Equal(t, newer.RequestID, queue[1].RequestID)
+}
+
+func TestAssignFromSupport_DispatchesToChosenTech(t *testing.T) {
+	ctx := context.Background()
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetSupportQueue(homerescueapi.NewMemorySupportQueue())
+	request := &homerescueapi.EmergencyRequest{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Category:  homerescueapi.CategoryPlumbing,
+		Urgency:   homerescueapi.UrgencyCritical,
+		Status:    homerescueapi.StatusSearching,
+		CreatedAt: time.Now().Add(-time.Hour),
+	}
+	require.True(t, engine.Escalate(ctx, request))
+
+	queue, err := engine.SupportQueue(ctx)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.True(t, queue[0].SLABreached)
+
+	agent, otherAgent, tech := uuid.New(), uuid.New(), uuid.New()
+
+	// Assigning needs the ticket claimed first
+	_, err = engine.AssignFromSupport(ctx, request.ID, agent, tech)
+	assert.ErrorIs(t, err, homerescueapi.ErrTicketNotClaimed)
+
+	ticket, err := engine.ClaimEscalation(ctx, request.ID, agent)
+	require.NoError(t, err)
+	assert.Equal(t, agent, *ticket.ClaimedBy)
+
+	_, err = engine.ClaimEscalation(ctx, request.ID, otherAgent)
+	assert.ErrorIs(t, err, homerescueapi.ErrTicketClaimed)
+	_, err = engine.AssignFromSupport(ctx, request.ID, otherAgent, tech)
+	assert.ErrorIs(t, err, homerescueapi.ErrTicketClaimed)
+
+	result, err := engine.AssignFromSupport(ctx, request.ID, agent, tech)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, request.ID, result.RequestID)
+	assert.Equal(t, tech, *result.AssignedTechID)
+
+	assert.Equal(t, homerescueapi.StatusAssigned, request.Status)
+	assert.Equal(t, tech, *request.AssignedTechID)
+	require.NotEmpty(t, request.AssignmentHistory)
+	assert.Equal(t, tech, request.AssignmentHistory[len(request.AssignmentHistory)-1].TechID)
+
+	queue, err = engine.SupportQueue(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, queue, "assigned ticket leaves the queue")
+
+	_, err = engine.AssignFromSupport(ctx, request.ID, agent, uuid.New())
+	assert.ErrorIs(t, err, homerescueapi.ErrTicketAssigned)
+}
+
+func TestAssignFromSupport_UnknownRequest(t *testing.T) {
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetSupportQueue(homerescueapi.NewMemorySupportQueue())
+
+	_, err := engine.ClaimEscalation(context.Background(), uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, homerescueapi.ErrTicketNotFound)
+	_, err = engine.AssignFromSupport(context.Background(), uuid.New(), uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, homerescueapi.ErrTicketNotFound)
+}
+
+// Test Vendor Pricing Overrides
+
+func TestPricingOverride_ChangesVendorEstimate(t *testing.T) {
+	engine := homerescueapi.NewEmergencyPricingEngine(nil, nil)
+	vendor := uuid.New()
+	callOut, perKM := 25000.0, 800.0
+
+	err := engine.SetVendorOverride(vendor, homerescueapi.CategoryPlumbing, homerescueapi.PricingOverride{
+		CallOutFee:  &callOut,
+		PerKMCharge: &perKM,
+	})
+	assert.NoError(t, err)
+
+	defaultPrice := engine.EstimatePrice(context.Background(), homerescueapi.CategoryPlumbing, homerescueapi.UrgencySameDay, 10, uuid.Nil, homerescueapi.EmergencyLocation{})
+	vendorPrice := engine.EstimatePrice(context.Background(), homerescueapi.CategoryPlumbing, homerescueapi.UrgencySameDay, 10, vendor, homerescueapi.EmergencyLocation{})
+
+	// 10,000 more call-out and 300 more per km over the 5 free km
+	assert.InDelta(t, defaultPrice+10000+5*300, vendorPrice, 0.01)
+
+	rules := engine.RulesFor(homerescueapi.CategoryPlumbing, vendor)
+	assert.Equal(t, homerescueapi.DefaultPricingRules[homerescueapi.CategoryPlumbing].StandardRate, rules.StandardRate,
+		"fields the vendor didn't set keep the category default")
+}
+
+func TestPricingOverride_DefaultAppliesWhenAbsent(t *testing.T) {
+	engine := homerescueapi.NewEmergencyPricingEngine(nil, nil)
+	vendor := uuid.New()
+	callOut := 12000.0
+	assert.NoError(t, engine.SetVendorOverride(vendor, homerescueapi.CategoryPlumbing, homerescueapi.PricingOverride{CallOutFee: &callOut}))
+
+	// Another vendor, and the same vendor in another category, get defaults
+	other := engine.RulesFor(homerescueapi.CategoryPlumbing, uuid.New())
+	electrical := engine.RulesFor(homerescueapi.CategoryElectrical, vendor)
+
+	assert.Equal(t, homerescueapi.DefaultPricingRules[homerescueapi.CategoryPlumbing], other)
+	assert.Equal(t, homerescueapi.DefaultPricingRules[homerescueapi.CategoryElectrical], electrical)
+
+	final := engine.CalculateFinalPrice(context.Background(), homerescueapi.CategoryPlumbing, homerescueapi.UrgencySameDay, vendor, 1, nil, 0, "", homerescueapi.EmergencyLocation{})
+	assert.Equal(t, 12000.0, final.CallOutFee)
+}
+
+func TestPricingOverride_OutOfBoundsRejected(t *testing.T) {
+	engine := homerescueapi.NewEmergencyPricingEngine(nil, nil)
+	vendor := uuid.New()
+	tooCheap, tooSteep, premium := 1000.0, 100000.0, 150.0
+
+	for _, override := range []homerescueapi.PricingOverride{
+		{CallOutFee: &tooCheap},
+		{StandardRate: &tooSteep},
+		{CriticalPremium: &premium},
+	} {
+		err := engine.SetVendorOverride(vendor, homerescueapi.CategoryPlumbing, override)
+		assert.True(t, errors.Is(err, homerescueapi.ErrPricingOutOfBounds))
+	}
+
+	assert.Equal(t, homerescueapi.DefaultPricingRules[homerescueapi.CategoryPlumbing], engine.RulesFor(homerescueapi.CategoryPlumbing, vendor))
+}
+
+// Test Holiday Pricing
+
+func newHolidayPricingEngine() *homerescueapi.EmergencyPricingEngine {
+	engine := homerescueapi.NewEmergencyPricingEngine(nil, nil)
+	engine.SetHolidays([]homerescueapi.Holiday{
+		{Date: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), Name: "Independence Day"},
+		{Date: time.Date(2026, time.May, 27, 0, 0, 0, 0, time.UTC), Name: "Children's Day", Region: "Lagos"},
+	})
+	return engine
+}
+
+func TestLaborRate_PublicHolidayUsesHolidayRate(t *testing.T) {
+	engine := newHolidayPricingEngine()
+	rules := homerescueapi.DefaultPricingRules[homerescueapi.CategoryPlumbing]
+
+	// Independence Day 2026 is a Thursday; holiday beats both business and
+	// after-hours rates
+	assert.Equal(t, rules.HolidayRate, engine.LaborRate(rules, time.Date(2026, time.October, 1, 10, 0, 0, 0, time.UTC), "Oyo"))
+	assert.Equal(t, rules.HolidayRate, engine.LaborRate(rules, time.Date(2026, time.October, 1, 22, 0, 0, 0, time.UTC), "Oyo"))
+}
+
+func TestLaborRate_RegionalHolidayOnlyInItsState(t *testing.T) {
+	engine := newHolidayPricingEngine()
+	rules := homerescueapi.DefaultPricingRules[homerescueapi.CategoryPlumbing]
+	at := time.Date(2026, time.May, 27, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, rules.HolidayRate, engine.LaborRate(rules, at, "lagos"))
+	assert.Equal(t, rules.StandardRate, engine.LaborRate(rules, at, "Kano"))
+}
+
+func TestLaborRate_WeekendWithoutHolidayIsAfterHours(t *testing.T) {
+	engine := newHolidayPricingEngine()
+	rules := homerescueapi.DefaultPricingRules[homerescueapi.CategoryPlumbing]
+
+	saturday := time.Date(2026, time.October, 3, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, rules.AfterHoursRate, engine.LaborRate(rules, saturday, "Lagos"))
+}
+
+func TestLaborRate_RegularWeekdayIsStandard(t *testing.T) {
+	engine := newHolidayPricingEngine()
+	rules := homerescueapi.DefaultPricingRules[homerescueapi.CategoryPlumbing]
+
+	tuesday := time.Date(2026, time.October, 6, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, rules.StandardRate, engine.LaborRate(rules, tuesday, "Lagos"))
+}
+
+// Test Demand Surge Pricing
+
+type stubDemand struct {
+	demand homerescueapi.Demand
+	calls  int
+}
+
+func (s *stubDemand) Demand(ctx context.Context, category homerescueapi.EmergencyCategory, location homerescueapi.EmergencyLocation, radiusKm float64) (homerescueapi.Demand, error) {
+	s.calls++
+	return s.demand, nil
+}
+
+func surgeEngine(available, open int) (*homerescueapi.EmergencyPricingEngine, *stubDemand) {
+	engine := homerescueapi.NewEmergencyPricingEngine(nil, nil)
+	source := &stubDemand{demand: homerescueapi.Demand{AvailableTechs: available, OpenRequests: open}}
+	engine.SetDemandSource(source)
+	return engine, source
+}
+
+var lekki = homerescueapi.EmergencyLocation{City: "Lekki", State: "Lagos", Latitude: 6.4698, Longitude: 3.5852}
+
+func TestDemandMultiplier_BalancedSupplyIsFlat(t *testing.T) {
+	engine, _ := surgeEngine(6, 6)
+	multiplier, reason := engine.DemandMultiplier(context.Background(), homerescueapi.CategoryPlumbing, lekki)
+	assert.Equal(t, 1.0, multiplier)
+	assert.Empty(t, reason)
+
+	flat := homerescueapi.NewEmergencyPricingEngine(nil, nil)
+	assert.Equal(t,
+		flat.EstimatePrice(context.Background(), homerescueapi.CategoryPlumbing, homerescueapi.UrgencySameDay, 3, uuid.Nil, lekki),
+		engine.EstimatePrice(context.Background(), homerescueapi.CategoryPlumbing, homerescueapi.UrgencySameDay, 3, uuid.Nil, lekki))
+}
+
+func TestDemandMultiplier_ScarceSupplyRaisesPrice(t *testing.T) {
+	engine, source := surgeEngine(4, 6)
+	ctx := context.Background()
+
+	multiplier, reason := engine.DemandMultiplier(ctx, homerescueapi.CategoryPlumbing, lekki)
+	assert.Equal(t, 1.5, multiplier)
+	assert.Contains(t, reason, "6 open plumbing requests")
+	assert.Contains(t, reason, "4 available technicians")
+
+	flat := homerescueapi.NewEmergencyPricingEngine(nil, nil)
+	base := flat.EstimatePrice(ctx, homerescueapi.CategoryPlumbing, homerescueapi.UrgencySameDay, 3, uuid.Nil, lekki)
+	surged := engine.EstimatePrice(ctx, homerescueapi.CategoryPlumbing, homerescueapi.UrgencySameDay, 3, uuid.Nil, lekki)
+	assert.Greater(t, surged, base)
+
+	request := &homerescueapi.EmergencyRequest{Category: homerescueapi.CategoryPlumbing, Urgency: homerescueapi.UrgencySameDay, Location: lekki}
+	estimate := engine.Estimate(ctx, request, uuid.Nil, 3)
+	assert.Equal(t, reason, estimate.Notes)
+	assert.Greater(t, estimate.TotalMax, estimate.TotalMin)
+
+	final := engine.CalculateFinalPrice(ctx, homerescueapi.CategoryPlumbing, homerescueapi.UrgencySameDay, uuid.Nil, 1, nil, 3, "", lekki)
+	assert.Equal(t, 1.5, final.DemandMultiplier)
+	assert.InDelta(t, (final.CallOutFee+final.LaborCost)*0.5, final.DemandSurcharge, 0.01)
+
+	assert.Equal(t, 1, source.calls, "lookups for the same area are reused")
+}
+
+func TestDemandMultiplier_Capped(t *testing.T) {
+	engine, _ := surgeEngine(1, 9)
+	multiplier, _ := engine.DemandMultiplier(context.Background(), homerescueapi.CategoryElectrical, lekki)
+	assert.Equal(t, homerescueapi.MaxDemandMultiplier, multiplier)
+
+	multiplier, reason := homerescueapi.SurgeMultiplier(homerescueapi.CategoryElectrical, homerescueapi.Demand{AvailableTechs: 0, OpenRequests: 3})
+	assert.Equal(t, homerescueapi.MaxDemandMultiplier, multiplier, "no technicians at all is the cap")
+	assert.NotEmpty(t, reason)
+}
+
+// Test Dispatch State Rehydration
+
+func TestRehydrateActiveState_ResumesSearchAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	store := homerescueapi.NewMemoryDispatchStateStore()
+	queue := homerescueapi.NewMemorySupportQueue()
+
+	// The previous instance had widened the search to 45km when it stopped
+	request := homerescueapi.EmergencyRequest{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Category:  homerescueapi.CategoryLocksmith,
+		Urgency:   homerescueapi.UrgencyCritical,
+		Status:    homerescueapi.StatusSearching,
+		CreatedAt: time.Now().Add(-20 * time.Minute),
+	}
+	store.PutRequest(request)
+	require.NoError(t, store.SaveRequestState(ctx, homerescueapi.RequestState{
+		Request:             &request,
+		AssignmentAttempts:  3,
+		CurrentSearchRadius: 45,
+	}))
+
+	restarted := homerescueapi.NewDispatchEngine(nil, nil)
+	restarted.SetDispatchStateStore(store)
+	restarted.SetSupportQueue(queue)
+	require.NoError(t, restarted.RehydrateActiveState(ctx))
+
+	// With nobody nearby the resumed search widens from 45km, not from the
+	// initial radius, and escalates once it passes the maximum
+	require.Eventually(t, func() bool {
+		tickets, _ := queue.Open(ctx)
+		return len(tickets) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	state, ok := store.State(request.ID)
+	require.True(t, ok)
+	assert.Equal(t, 55.0, state.CurrentSearchRadius)
+	assert.Equal(t, 3, state.AssignmentAttempts)
+}
+
+func TestRehydrateActiveState_AcceptedRequestTrackedNotRedispatched(t *testing.T) {
+	ctx := context.Background()
+	store := homerescueapi.NewMemoryDispatchStateStore()
+	queue := homerescueapi.NewMemorySupportQueue()
+	tech := uuid.New()
+
+	onTheWay := homerescueapi.EmergencyRequest{
+		ID:             uuid.New(),
+		Status:         homerescueapi.StatusEnRoute,
+		AssignedTechID: &tech,
+		CreatedAt:      time.Now(),
+	}
+	finished := homerescueapi.EmergencyRequest{
+		ID:        uuid.New(),
+		Status:    homerescueapi.StatusCompleted,
+		CreatedAt: time.Now(),
+	}
+	store.PutRequest(onTheWay)
+	store.PutRequest(finished)
+
+	restarted := homerescueapi.NewDispatchEngine(nil, nil)
+	restarted.SetDispatchStateStore(store)
+	restarted.SetSupportQueue(queue)
+	require.NoError(t, restarted.RehydrateActiveState(ctx))
+
+	// The en-route request is tracked again (found, though its tech is past
+	// declining) while the completed one isn't loaded at all
+	assert.ErrorIs(t, restarted.DeclineAssignment(ctx, onTheWay.ID, tech, ""), homerescueapi.ErrNotAssignedTech)
+	assert.ErrorIs(t, restarted.DeclineAssignment(ctx, finished.ID, tech, ""), homerescueapi.ErrRequestNotFound)
+
+	// Nor is the en-route request searched for again
+	time.Sleep(50 * time.Millisecond)
+	tickets, err := queue.Open(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tickets)
+	_, saved := store.State(onTheWay.ID)
+	assert.False(t, saved, "no dispatch attempt was made")
+}
+
+func TestRehydrateActiveState_SkipsRequestsAnotherInstanceResumed(t *testing.T) {
+	ctx := context.Background()
+	store := homerescueapi.NewMemoryDispatchStateStore()
+	queue := homerescueapi.NewMemorySupportQueue()
+	locker := newFakeLocker()
+
+	request := homerescueapi.EmergencyRequest{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Category:  homerescueapi.CategoryLocksmith,
+		Urgency:   homerescueapi.UrgencyCritical,
+		Status:    homerescueapi.StatusSearching,
+		CreatedAt: time.Now().Add(-20 * time.Minute),
+	}
+	store.PutRequest(request)
+	require.NoError(t, store.SaveRequestState(ctx, homerescueapi.RequestState{
+		Request:             &request,
+		AssignmentAttempts:  3,
+		CurrentSearchRadius: 45,
+	}))
+
+	// Another replica started first and resumed it
+	acquired, _ := locker.TryLock(ctx, "homerescue:resume:"+request.ID.String(), time.Minute)
+	require.True(t, acquired)
+
+	restarted := homerescueapi.NewDispatchEngine(nil, nil)
+	restarted.SetEscalationLocker(locker)
+	restarted.SetDispatchStateStore(store)
+	restarted.SetSupportQueue(queue)
+	require.NoError(t, restarted.RehydrateActiveState(ctx))
+
+	time.Sleep(50 * time.Millisecond)
+	tickets, err := queue.Open(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tickets)
+	state, ok := store.State(request.ID)
+	require.True(t, ok)
+	assert.Equal(t, 45.0, state.CurrentSearchRadius, "the search was not widened again")
+	assert.ErrorIs(t, restarted.DeclineAssignment(ctx, request.ID, uuid.New(), ""), homerescueapi.ErrRequestNotFound)
+}
+
+// Test Arrival SLA Breaches
+
+type stubRefunds struct {
+	refunded []uuid.UUID
+	err      error
+}
+
+func (s *stubRefunds) RefundHeld(ctx context.Context, request *homerescueapi.EmergencyRequest, reason string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.refunded = append(s.refunded, request.ID)
+	return nil
+}
+
+func newSLAEngine(requests ...homerescueapi.EmergencyRequest) (*homerescueapi.DispatchEngine, *homerescueapi.MemoryArrivalSLAStore, *stubRefunds) {
+	store := homerescueapi.NewMemoryArrivalSLAStore()
+	for _, r := range requests {
+		store.Put(r)
+	}
+	refunds := &stubRefunds{}
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetArrivalSLAStore(store)
+	engine.SetPaymentService(refunds)
+	return engine, store, refunds
+}
+
+func TestCheckSLABreaches_LateRequestFlaggedAndRefunded(t *testing.T) {
+	request := homerescueapi.EmergencyRequest{
+		ID:              uuid.New(),
+		UserID:          uuid.New(),
+		Status:          homerescueapi.StatusEnRoute,
+		ArrivalDeadline: time.Now().Add(-10 * time.Minute),
+		PaymentStatus:   homerescueapi.PaymentHeld,
+	}
+	engine, store, refunds := newSLAEngine(request)
+
+	breached, err := engine.CheckSLABreaches(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{request.ID}, breached)
+	assert.Equal(t, []uuid.UUID{request.ID}, refunds.refunded)
+
+	stored, _ := store.Get(request.ID)
+	assert.True(t, stored.SLABreached)
+	assert.Equal(t, homerescueapi.PaymentRefunded, stored.PaymentStatus)
+	assert.Equal(t, homerescueapi.StatusEnRoute, stored.Status, "the technician is still on the way")
+	require.Len(t, stored.StatusHistory, 1)
+	assert.Contains(t, stored.StatusHistory[0].Notes, "SLA breached")
+
+	// A second sweep doesn't flag or refund it again
+	breached, err = engine.CheckSLABreaches(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, breached)
+	assert.Len(t, refunds.refunded, 1)
+}
+
+func TestCheckSLABreaches_JustInTimeArrivalNotBreached(t *testing.T) {
+	deadline := time.Now().Add(-10 * time.Minute)
+	request := homerescueapi.EmergencyRequest{
+		ID:                uuid.New(),
+		Status:            homerescueapi.StatusArrived,
+		ArrivalDeadline:   deadline,
+		ActualArrivalTime: &deadline,
+		PaymentStatus:     homerescueapi.PaymentHeld,
+	}
+	engine, store, refunds := newSLAEngine(request)
+
+	breached, err := engine.CheckSLABreaches(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, breached)
+	assert.Empty(t, refunds.refunded)
+
+	stored, _ := store.Get(request.ID)
+	assert.False(t, stored.SLABreached)
+	assert.Equal(t, homerescueapi.PaymentHeld, stored.PaymentStatus)
+	assert.False(t, homerescueapi.ArrivalSLABreached(&homerescueapi.EmergencyRequest{
+		Status:          homerescueapi.StatusEnRoute,
+		ArrivalDeadline: deadline,
+	}, deadline), "arriving exactly at the deadline is on time")
+}
+
+func TestCheckSLABreaches_WithinSLAUntouched(t *testing.T) {
+	request := homerescueapi.EmergencyRequest{
+		ID:              uuid.New(),
+		Status:          homerescueapi.StatusAccepted,
+		ArrivalDeadline: time.Now().Add(20 * time.Minute),
+		PaymentStatus:   homerescueapi.PaymentHeld,
+	}
+	engine, store, refunds := newSLAEngine(request)
+
+	breached, err := engine.CheckSLABreaches(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, breached)
+	assert.Empty(t, refunds.refunded)
+
+	stored, _ := store.Get(request.ID)
+	assert.False(t, stored.SLABreached)
+	assert.Empty(t, stored.StatusHistory)
+}
+
+func TestCheckSLABreaches_FailedRefundStillFlags(t *testing.T) {
+	request := homerescueapi.EmergencyRequest{
+		ID:              uuid.New(),
+		Status:          homerescueapi.StatusAccepted,
+		ArrivalDeadline: time.Now().Add(-time.Minute),
+		PaymentStatus:   homerescueapi.PaymentHeld,
+	}
+	engine, store, refunds := newSLAEngine(request)
+	refunds.err = errors.New("gateway down")
+
+	breached, err := engine.CheckSLABreaches(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, []uuid.UUID{request.ID}, breached)
+
+	stored, _ := store.Get(request.ID)
+	assert.True(t, stored.SLABreached)
+	assert.Equal(t, homerescueapi.PaymentHeld, stored.PaymentStatus)
+}
+
+// Test Tech Response Pub/Sub
+
+func publishWhenSubscribed(t *testing.T, mr *miniredis.Miniredis, engine *homerescueapi.DispatchEngine, requestID, techID uuid.UUID, response string) {
+	channel := homerescueapi.TechResponseChannel(requestID, techID)
+	go func() {
+		for mr.PubSubNumSub(channel)[channel] == 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		assert.NoError(t, engine.PublishTechResponse(context.Background(), requestID, techID, response))
+	}()
+}
+
+func TestWaitForTechResponse_AcceptedReturnsPromptly(t *testing.T) {
+	mr := miniredis.RunT(t)
+	engine := homerescueapi.NewDispatchEngine(nil, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	requestID, techID := uuid.New(), uuid.New()
+
+	publishWhenSubscribed(t, mr, engine, requestID, techID, homerescueapi.TechResponseAccepted)
+
+	start := time.Now()
+	accepted := engine.WaitForTechResponse(context.Background(), requestID, techID, 5*time.Second)
+	assert.True(t, accepted)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestWaitForTechResponse_DeclinedReturnsFalse(t *testing.T) {
+	mr := miniredis.RunT(t)
+	engine := homerescueapi.NewDispatchEngine(nil, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	requestID, techID := uuid.New(), uuid.New()
+
+	publishWhenSubscribed(t, mr, engine, requestID, techID, homerescueapi.TechResponseDeclined)
+
+	start := time.Now()
+	assert.False(t, engine.WaitForTechResponse(context.Background(), requestID, techID, 5*time.Second))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestWaitForTechResponse_TimesOut(t *testing.T) {
+	mr := miniredis.RunT(t)
+	engine := homerescueapi.NewDispatchEngine(nil, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	requestID, techID := uuid.New(), uuid.New()
+
+	// Another technician's answer doesn't count
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		engine.PublishTechResponse(context.Background(), requestID, uuid.New(), homerescueapi.TechResponseAccepted)
+	}()
+
+	start := time.Now()
+	assert.False(t, engine.WaitForTechResponse(context.Background(), requestID, techID, 100*time.Millisecond))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+// Test Technician Declines
+
+func newOfferedRequest(t *testing.T, engine *homerescueapi.DispatchEngine, techID uuid.UUID) *homerescueapi.EmergencyRequest {
+	ctx := context.Background()
+	engine.SetSupportQueue(homerescueapi.NewMemorySupportQueue())
+	request := &homerescueapi.EmergencyRequest{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Category:  homerescueapi.CategoryElectrical,
+		Urgency:   homerescueapi.UrgencyUrgent,
+		Status:    homerescueapi.StatusSearching,
+		CreatedAt: time.Now(),
+	}
+	require.True(t, engine.Escalate(ctx, request))
+	agent := uuid.New()
+	_, err := engine.ClaimEscalation(ctx, request.ID, agent)
+	require.NoError(t, err)
+	_, err = engine.AssignFromSupport(ctx, request.ID, agent, techID)
+	require.NoError(t, err)
+	return request
+}
+
+func TestDeclineAssignment_WakesDispatcherImmediately(t *testing.T) {
+	mr := miniredis.RunT(t)
+	engine := homerescueapi.NewDispatchEngine(nil, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	tech := uuid.New()
+	request := newOfferedRequest(t, engine, tech)
+
+	channel := homerescueapi.TechResponseChannel(request.ID, tech)
+	go func() {
+		for mr.PubSubNumSub(channel)[channel] == 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		assert.NoError(t, engine.DeclineAssignment(context.Background(), request.ID, tech, "out of fuses"))
+	}()
+
+	start := time.Now()
+	accepted := engine.WaitForTechResponse(context.Background(), request.ID, tech, 5*time.Second)
+	assert.False(t, accepted)
+	assert.Less(t, time.Since(start), time.Second, "dispatch moves on without waiting out the timeout")
+
+	assert.Equal(t, homerescueapi.StatusSearching, request.Status)
+	assert.Nil(t, request.AssignedTechID)
+	last := request.AssignmentHistory[len(request.AssignmentHistory)-1]
+	assert.Equal(t, tech, last.TechID)
+	assert.Equal(t, homerescueapi.TechResponseDeclined, last.Response)
+	assert.Equal(t, "out of fuses", last.Reason)
+	require.NotNil(t, last.ResponseAt)
+}
+
+func TestDeclineAssignment_RejectsTechWithoutTheOffer(t *testing.T) {
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	tech := uuid.New()
+	request := newOfferedRequest(t, engine, tech)
+
+	err := engine.DeclineAssignment(context.Background(), request.ID, uuid.New(), "")
+	assert.ErrorIs(t, err, homerescueapi.ErrNotAssignedTech)
+	assert.Equal(t, homerescueapi.StatusAssigned, request.Status)
+	assert.Equal(t, tech, *request.AssignedTechID)
+	assert.Equal(t, "pending", request.AssignmentHistory[len(request.AssignmentHistory)-1].Response)
+
+	// Once declined, the same tech can't decline again
+	require.NoError(t, engine.DeclineAssignment(context.Background(), request.ID, tech, ""))
+	assert.ErrorIs(t, engine.DeclineAssignment(context.Background(), request.ID, tech, ""), homerescueapi.ErrNotAssignedTech)
+
+	err = engine.DeclineAssignment(context.Background(), uuid.New(), tech, "")
+	assert.ErrorIs(t, err, homerescueapi.ErrRequestNotFound)
+}
+
+func TestDeclineEmergency_OnlyTheOfferedTechCanDecline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	tech := uuid.New()
+	request := newOfferedRequest(t, engine, tech)
+
+	handler := homerescueapi.NewHandler(nil, engine, zap.NewNop())
+	handler.SetAuthMiddleware(bearerAuth())
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	decline := func(header string) int {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/homerescue/emergencies/"+request.ID.String()+"/decline", strings.NewReader(`{"reason":"too far"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, decline(""))
+	assert.Equal(t, http.StatusForbidden, decline(bearerToken(uuid.New(), auth.RoleTechnician)))
+	assert.Equal(t, homerescueapi.StatusAssigned, request.Status)
+
+	assert.Equal(t, http.StatusOK, decline(bearerToken(tech, auth.RoleTechnician)))
+	assert.Equal(t, homerescueapi.StatusSearching, request.Status)
+	assert.Equal(t, "too far", request.AssignmentHistory[len(request.AssignmentHistory)-1].Reason)
+}
+
+func TestSupportQueueRoutes_RequireSupportOrAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := homerescueapi.NewDispatchEngine(nil, nil)
+	engine.SetSupportQueue(homerescueapi.NewMemorySupportQueue())
+	request := &homerescueapi.EmergencyRequest{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Category:  homerescueapi.CategoryPlumbing,
+		Urgency:   homerescueapi.UrgencyCritical,
+		Status:    homerescueapi.StatusSearching,
+		CreatedAt: time.Now(),
+	}
+	require.True(t, engine.Escalate(context.Background(), request))
+
+	handler := homerescueapi.NewHandler(nil, engine, zap.NewNop())
+	handler.SetAuthMiddleware(bearerAuth())
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	call := func(method, path, body, header string) int {
+		req := httptest.NewRequest(method, "/api/v1/homerescue/support/queue"+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	claim := "/" + request.ID.String() + "/claim"
+	assign := "/" + request.ID.String() + "/assign"
+	tech := uuid.New()
+	assignBody := `{"technician_id":"` + tech.String() + `"}`
+
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, assign, assignBody, ""))
+	customer := bearerToken(uuid.New(), auth.RoleCustomer)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "", "", customer))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, claim, "", customer))
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, assign, assignBody, bearerToken(uuid.New(), auth.RoleTechnician)))
+	assert.Nil(t, request.AssignedTechID)
+
+	agent := bearerToken(uuid.New(), auth.RoleSupport)
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "", "", bearerToken(uuid.New(), auth.RoleAdmin)))
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, claim, "", agent))
+	// Only the agent holding the claim can assign
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, assign, assignBody, bearerToken(uuid.New(), auth.RoleSupport)))
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, assign, assignBody, agent))
+	assert.Equal(t, tech, *request.AssignedTechID)
+}
+
+func TestReorderEmergency_RequiresAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := homerescueapi.NewHandler(nil, nil, zap.NewNop())
+	handler.SetAuthMiddleware(bearerAuth())
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	// Naming the owner in the body no longer stands in for being them
+	body := `{"user_id":"` + uuid.New().String() + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/homerescue/emergencies/reorder/"+uuid.New().String(), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// Test Live Tracking Stream
+
+type memoryEmergencies map[uuid.UUID]*homerescue.Emergency
+
+func (m memoryEmergencies) GetEmergency(ctx context.Context, id uuid.UUID) (*homerescue.Emergency, error) {
+	if e, ok := m[id]; ok {
+		return e, nil
+	}
+	return nil, homerescue.ErrEmergencyNotFound
+}
+
+func trackingStreamServer(t *testing.T, emergency *homerescue.Emergency) (*miniredis.Miniredis, *redis.Client, *httptest.Server) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	handler := homerescueapi.NewHandler(nil, nil, zap.NewNop())
+	handler.SetAuthMiddleware(bearerAuth())
+	handler.SetEmergencyLookup(memoryEmergencies{emergency.ID: emergency})
+	handler.SetTrackingSubscriber(homerescueapi.NewTrackingService(nil, client))
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return mr, client, server
+}
+
+func trackingStreamURL(server *httptest.Server, emergencyID uuid.UUID) string {
+	return server.URL + "/api/v1/homerescue/emergencies/" + emergencyID.String() + "/tracking/stream"
+}
+
+func dialTracking(server *httptest.Server, emergencyID uuid.UUID, header http.Header) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(trackingStreamURL(server, emergencyID), "http")
+	return websocket.DefaultDialer.Dial(url, header)
+}
+
+func bearerHeader(userID uuid.UUID) http.Header {
+	return http.Header{"Authorization": {bearerToken(userID, auth.RoleCustomer)}}
+}
+
+func TestStreamTracking_OwnerReceivesUpdatesUntilCompleted(t *testing.T) {
+	owner := uuid.New()
+	emergency := &homerescue.Emergency{ID: uuid.New(), UserID: owner, Status: "assigned"}
+	mr, client, server := trackingStreamServer(t, emergency)
+	channel := homerescueapi.TrackingChannel(emergency.ID)
+
+	ws, _, err := dialTracking(server, emergency.ID, bearerHeader(owner))
+	require.NoError(t, err)
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	publish := func(update homerescueapi.TrackingUpdate) {
+		payload, err := json.Marshal(update)
+		require.NoError(t, err)
+		require.NoError(t, client.Publish(context.Background(), channel, payload).Err())
+	}
+
+	publish(homerescueapi.TrackingUpdate{RequestID: emergency.ID, Status: "en_route", ETAMinutes: 12})
+	var update homerescueapi.TrackingUpdate
+	require.NoError(t, ws.ReadJSON(&update))
+	assert.Equal(t, emergency.ID, update.RequestID)
+	assert.Equal(t, "en_route", update.Status)
+	assert.Equal(t, 12, update.ETAMinutes)
+
+	publish(homerescueapi.TrackingUpdate{RequestID: emergency.ID, Status: "completed"})
+	require.NoError(t, ws.ReadJSON(&update))
+	assert.Equal(t, "completed", update.Status)
+
+	// The server ends the stream and drops its subscription
+	err = ws.ReadJSON(&update)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "got %v", err)
+	assert.Eventually(t, func() bool { return mr.PubSubNumSub(channel)[channel] == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamTracking_ClientDisconnectUnsubscribes(t *testing.T) {
+	owner := uuid.New()
+	emergency := &homerescue.Emergency{ID: uuid.New(), UserID: owner, Status: "en_route"}
+	mr, _, server := trackingStreamServer(t, emergency)
+	channel := homerescueapi.TrackingChannel(emergency.ID)
+
+	ws, _, err := dialTracking(server, emergency.ID, bearerHeader(owner))
+	require.NoError(t, err)
+	assert.Equal(t, 1, mr.PubSubNumSub(channel)[channel])
+
+	ws.Close()
+	assert.Eventually(t, func() bool { return mr.PubSubNumSub(channel)[channel] == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamTracking_RejectsOtherUsers(t *testing.T) {
+	emergency := &homerescue.Emergency{ID: uuid.New(), UserID: uuid.New(), Status: "en_route"}
+	mr, _, server := trackingStreamServer(t, emergency)
+
+	_, resp, err := dialTracking(server, emergency.ID, bearerHeader(uuid.New()))
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	channel := homerescueapi.TrackingChannel(emergency.ID)
+	assert.Zero(t, mr.PubSubNumSub(channel)[channel], "no subscription for a rejected user")
+}
+
+func TestStreamTracking_RejectsHeaderOnlyIdentity(t *testing.T) {
+	owner := uuid.New()
+	emergency := &homerescue.Emergency{ID: uuid.New(), UserID: owner, Status: "en_route"}
+	mr, _, server := trackingStreamServer(t, emergency)
+
+	// Knowing the owner's ID is not enough without a valid token
+	_, resp, err := dialTracking(server, emergency.ID, http.Header{"X-User-Id": {owner.String()}})
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	channel := homerescueapi.TrackingChannel(emergency.ID)
+	assert.Zero(t, mr.PubSubNumSub(channel)[channel])
+}
+
+func TestStreamTracking_RejectsCrossOriginBrowsers(t *testing.T) {
+	owner := uuid.New()
+	emergency := &homerescue.Emergency{ID: uuid.New(), UserID: owner, Status: "en_route"}
+	_, _, server := trackingStreamServer(t, emergency)
+
+	header := bearerHeader(owner)
+	header.Set("Origin", "https://attacker.example")
+	_, resp, err := dialTracking(server, emergency.ID, header)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestPublishEmergencyLocation_ReachesTheTrackingStream(t *testing.T) {
+	mr := miniredis.RunT(t)
+	tracking := homerescueapi.NewTrackingService(nil, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	tech := uuid.New()
+	emergency := &homerescue.Emergency{
+		ID: uuid.New(), UserID: uuid.New(), Status: "accepted", AssignedTechID: &tech,
+		Latitude: 6.4281, Longitude: 3.4219,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := tracking.SubscribeToTracking(ctx, emergency.ID)
+	require.NoError(t, err)
+
+	// About 3km out, at the default 30km/h plus the parking buffer
+	tracking.PublishEmergencyLocation(ctx, emergency, 6.4551, 3.4219)
+
+	select {
+	case update := <-updates:
+		assert.Equal(t, emergency.ID, update.RequestID)
+		assert.Equal(t, tech, update.TechID)
+		assert.Equal(t, "accepted", update.Status)
+		assert.InDelta(t, 3.0, update.DistanceRemaining, 0.1)
+		assert.Equal(t, 9, update.ETAMinutes)
+	case <-time.After(time.Second):
+		t.Fatal("no tracking update published")
+	}
+}
+
+// recordingCustomerNotifier records the notifications sent to customers
+type recordingCustomerNotifier struct {
+	mu   sync.Mutex
+	sent map[uuid.UUID][]*homerescueapi.CustomerNotification
+}
+
+func (r *recordingCustomerNotifier) NotifyCustomer(ctx context.Context, userID uuid.UUID, notification *homerescueapi.CustomerNotification) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sent == nil {
+		r.sent = make(map[uuid.UUID][]*homerescueapi.CustomerNotification)
+	}
+	r.sent[userID] = append(r.sent[userID], notification)
+}
+
+func TestPublishEmergencyLocation_NotifiesTheCustomerAsTheTechNears(t *testing.T) {
+	mr := miniredis.RunT(t)
+	tracking := homerescueapi.NewTrackingService(nil, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	notifier := &recordingCustomerNotifier{}
+	tracking.SetCustomerNotifier(notifier)
+	tech := uuid.New()
+	emergency := &homerescue.Emergency{
+		ID: uuid.New(), UserID: uuid.New(), Status: "en_route", AssignedTechID: &tech,
+		Latitude: 6.4281, Longitude: 3.4219,
+	}
+	ctx := context.Background()
+
+	// About 3km out: 9 minutes away
+	tracking.PublishEmergencyLocation(ctx, emergency, 6.4551, 3.4219)
+	// Still about 9 minutes away; the 10 minute notice is not sent again
+	tracking.PublishEmergencyLocation(ctx, emergency, 6.4550, 3.4219)
+	// At the door
+	tracking.PublishEmergencyLocation(ctx, emergency, 6.4281, 3.4219)
+
+	sent := notifier.sent[emergency.UserID]
+	require.Len(t, sent, 2)
+	assert.Equal(t, "tech_proximity_10_min", sent[0].Type)
+	assert.Equal(t, emergency.ID, sent[0].RequestID)
+	assert.Equal(t, "Your technician is about 9 minutes away", sent[0].Message)
+	assert.Equal(t, "tech_proximity_arriving", sent[1].Type)
+}
+
+// Test Idempotent Emergency Creation
+
+// memoryEmergencyCreator creates emergencies in process, counting each
+// create as a dispatch
+type memoryEmergencyCreator struct {
+	mu          sync.Mutex
+	emergencies map[uuid.UUID]*homerescue.Emergency
+}
+
+func (m *memoryEmergencyCreator) CreateEmergency(ctx context.Context, req *homerescue.CreateEmergencyRequest) (*homerescue.Emergency, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	emergency := &homerescue.Emergency{ID: uuid.New(), UserID: req.UserID, Category: req.Category, Status: "new"}
+	m.emergencies[emergency.ID] = emergency
+	return emergency, nil
+}
+
+func (m *memoryEmergencyCreator) GetEmergency(ctx context.Context, id uuid.UUID) (*homerescue.Emergency, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.emergencies[id]; ok {
+		return e, nil
+	}
+	return nil, homerescue.ErrEmergencyNotFound
+}
+
+func (m *memoryEmergencyCreator) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.emergencies)
+}
+
+func idempotentEmergencyRouter(t *testing.T) (*gin.Engine, *memoryEmergencyCreator) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+
+	creator := &memoryEmergencyCreator{emergencies: make(map[uuid.UUID]*homerescue.Emergency)}
+	handler := homerescueapi.NewHandler(nil, nil, zap.NewNop())
+	handler.SetEmergencyCreator(creator)
+	handler.SetEmergencyLookup(creator)
+	handler.SetIdempotencyCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+	return router, creator
+}
+
+func postEmergency(router *gin.Engine, userID uuid.UUID, idempotencyKey string) (int, uuid.UUID) {
+	body := `{
+		"user_id": "` + userID.String() + `",
+		"category": "plumbing", "urgency": "urgent",
+		"title": "Burst pipe", "description": "Burst pipe under the kitchen sink",
+		"address": "12 Admiralty Way", "city": "Lekki", "state": "Lagos", "postal_code": "106104",
+		"latitude": 6.4474, "longitude": 3.4723
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/homerescue/emergencies", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set(homerescueapi.IdempotencyKeyHeader, idempotencyKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Emergency homerescue.Emergency `json:"emergency"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp.Emergency.ID
+}
+
+func TestCreateEmergencyHandler_RepeatKeyReturnsSameEmergency(t *testing.T) {
+	router, creator := idempotentEmergencyRouter(t)
+	user := uuid.New()
+
+	firstStatus, firstID := postEmergency(router, user, "retry-me")
+	secondStatus, secondID := postEmergency(router, user, "retry-me")
+
+	assert.Equal(t, http.StatusCreated, firstStatus)
+	assert.Equal(t, http.StatusCreated, secondStatus)
+	assert.NotEqual(t, uuid.Nil, firstID)
+	assert.Equal(t, firstID, secondID)
+	assert.Equal(t, 1, creator.count(), "the retry isn't created or dispatched again")
+}
+
+func TestCreateEmergencyHandler_KeysAreScopedPerUser(t *testing.T) {
+	router, creator := idempotentEmergencyRouter(t)
+	user, otherUser := uuid.New(), uuid.New()
+
+	_, a := postEmergency(router, user, "key-a")
+	_, b := postEmergency(router, user, "key-b")
+	// Another user's "key-a" is a new request
+	_, c := postEmergency(router, otherUser, "key-a")
+	// So is a request without a key
+	_, d := postEmergency(router, user, "")
+
+	assert.NotEqual(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.NotEqual(t, a, d)
+	assert.Equal(t, 4, creator.count())
+}
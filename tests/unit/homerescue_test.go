@@ -1,6 +1,7 @@
 package unit
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -534,3 +535,214 @@ func TestUrgencyLevels(t *testing.T) {
 		}
 	})
 }
+
+// TestReorderEmergency tests cloning a completed emergency into a new request
+func TestReorderEmergency(t *testing.T) {
+	userID := uuid.New()
+	previous := &homerescue.Emergency{
+		ID:                 uuid.New(),
+		UserID:             userID,
+		Category:           "plumbing",
+		Subcategory:        "leak",
+		Urgency:            "urgent",
+		Title:              "Kitchen sink leak",
+		Description:        "Leaking under the sink",
+		Address:            "12 Admiralty Way",
+		City:               "Lagos",
+		State:              "Lagos",
+		PostalCode:         "106104",
+		Latitude:           6.4474,
+		Longitude:          3.4700,
+		AccessInstructions: "Gate code 4521",
+		Status:             "completed",
+	}
+
+	t.Run("Clones category, location and access details", func(t *testing.T) {
+		req, err := homerescue.BuildReorderRequest(previous, userID, homerescue.ReorderOverrides{
+			Urgency:     "critical",
+			Description: "Leaking again, worse this time",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if req.Category != "plumbing" || req.Address != previous.Address ||
+			req.Latitude != previous.Latitude || req.Longitude != previous.Longitude ||
+			req.AccessInstructions != "Gate code 4521" {
+			t.Errorf("Reorder did not carry over the previous details: %+v", req)
+		}
+		if req.Urgency != "critical" || req.Description != "Leaking again, worse this time" {
+			t.Errorf("Overrides not applied: urgency=%s description=%s", req.Urgency, req.Description)
+		}
+		if req.Title != previous.Title {
+			t.Errorf("Expected title %q to be kept, got %q", previous.Title, req.Title)
+		}
+	})
+
+	t.Run("Rejects another user's emergency", func(t *testing.T) {
+		_, err := homerescue.BuildReorderRequest(previous, uuid.New(), homerescue.ReorderOverrides{})
+		if err != homerescue.ErrUnauthorized {
+			t.Errorf("Expected ErrUnauthorized, got %v", err)
+		}
+	})
+
+	t.Run("Rejects emergencies that are not completed", func(t *testing.T) {
+		open := *previous
+		open.Status = "in_progress"
+		_, err := homerescue.BuildReorderRequest(&open, userID, homerescue.ReorderOverrides{})
+		if err != homerescue.ErrNotReorderable {
+			t.Errorf("Expected ErrNotReorderable, got %v", err)
+		}
+	})
+
+	t.Run("Rejects invalid urgency override", func(t *testing.T) {
+		_, err := homerescue.BuildReorderRequest(previous, userID, homerescue.ReorderOverrides{Urgency: "whenever"})
+		if err != homerescue.ErrInvalidUrgency {
+			t.Errorf("Expected ErrInvalidUrgency, got %v", err)
+		}
+	})
+}
+
+// TestPlumbingIntakeAdjustsUrgency tests that structured intake answers refine
+// the urgency the customer reported
+func TestPlumbingIntakeAdjustsUrgency(t *testing.T) {
+	tests := []struct {
+		name     string
+		urgency  string
+		answers  map[string]string
+		expected string
+	}{
+		{
+			name:     "Active leak with main still on is raised to urgent",
+			urgency:  "scheduled",
+			answers:  map[string]string{"water_flowing": "yes", "main_shut_off": "no"},
+			expected: "urgent",
+		},
+		{
+			name:     "Water near electrics is critical",
+			urgency:  "same_day",
+			answers:  map[string]string{"water_flowing": "yes", "main_shut_off": "yes", "near_electrics": "yes"},
+			expected: "critical",
+		},
+		{
+			name:     "No flowing water with main off is capped at same day",
+			urgency:  "critical",
+			answers:  map[string]string{"water_flowing": "no", "main_shut_off": "yes"},
+			expected: "same_day",
+		},
+		{
+			name:     "Stopped leak but main can't be shut off stays urgent",
+			urgency:  "critical",
+			answers:  map[string]string{"water_flowing": "no", "main_shut_off": "no"},
+			expected: "urgent",
+		},
+		{
+			name:     "No answers keeps the reported urgency",
+			urgency:  "urgent",
+			answers:  nil,
+			expected: "urgent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assessment, err := homerescue.AssessIntake("plumbing", tt.urgency, tt.answers)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if assessment.Urgency != tt.expected {
+				t.Errorf("Expected urgency %s, got %s", tt.expected, assessment.Urgency)
+			}
+		})
+	}
+
+	t.Run("Answers pre-populate diagnosis hints", func(t *testing.T) {
+		assessment, err := homerescue.AssessIntake("plumbing", "urgent", map[string]string{
+			"water_flowing": "yes", "main_shut_off": "yes", "source": "water_heater",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(assessment.DiagnosisHints) != 2 {
+			t.Errorf("Expected 2 diagnosis hints, got %v", assessment.DiagnosisHints)
+		}
+	})
+
+	t.Run("Rejects unknown questions and answers", func(t *testing.T) {
+		for _, answers := range []map[string]string{
+			{"water_flowing": "maybe", "main_shut_off": "no"},
+			{"water_flowing": "yes", "main_shut_off": "no", "colour": "blue"},
+			{"water_flowing": "yes"},
+		} {
+			_, err := homerescue.AssessIntake("plumbing", "urgent", answers)
+			if !errors.Is(err, homerescue.ErrInvalidIntake) {
+				t.Errorf("Expected ErrInvalidIntake for %v, got %v", answers, err)
+			}
+		}
+	})
+}
+
+// TestSLACountdown tests the remaining SLA time reported with emergency status
+func TestSLACountdown(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	responseDeadline := now.Add(10 * time.Minute)
+	arrivalDeadline := now.Add(45 * time.Minute)
+
+	tests := []struct {
+		name             string
+		status           string
+		now              time.Time
+		expectedSeconds  int64
+		expectedBreached bool
+	}{
+		{
+			name:            "Searching counts down to the response deadline",
+			status:          "searching",
+			now:             now,
+			expectedSeconds: 600,
+		},
+		{
+			name:            "Accepted counts down to the arrival deadline",
+			status:          "accepted",
+			now:             now.Add(90 * time.Second),
+			expectedSeconds: 2610,
+		},
+		{
+			name:             "Missed response deadline is clamped and breached",
+			status:           "new",
+			now:              now.Add(15 * time.Minute),
+			expectedSeconds:  0,
+			expectedBreached: true,
+		},
+		{
+			name:             "Missed arrival deadline is clamped and breached",
+			status:           "en_route",
+			now:              now.Add(time.Hour),
+			expectedSeconds:  0,
+			expectedBreached: true,
+		},
+		{
+			name:            "Response deadline no longer applies once accepted",
+			status:          "en_route",
+			now:             now.Add(15 * time.Minute),
+			expectedSeconds: 1800,
+		},
+		{
+			name:            "Completed emergency has no countdown",
+			status:          "completed",
+			now:             now.Add(time.Hour),
+			expectedSeconds: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seconds, breached := homerescue.SLACountdown(responseDeadline, arrivalDeadline, tt.status, tt.now)
+			if seconds != tt.expectedSeconds {
+				t.Errorf("Expected %d seconds remaining, got %d", tt.expectedSeconds, seconds)
+			}
+			if breached != tt.expectedBreached {
+				t.Errorf("Expected breached %v, got %v", tt.expectedBreached, breached)
+			}
+		})
+	}
+}
@@ -0,0 +1,1130 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	lifeosapi "github.com/BillyRonksGlobal/vendorplatform/api/lifeos"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/discount"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Budget Tracking
+
+func TestBudgetTracking_BookingUpdatesSpent(t *testing.T) {
+	cateringID := uuid.New()
+	budget := &lifeosapi.Budget{
+		TotalAmount: 1000000,
+		Currency:    "NGN",
+		Allocated:   400000,
+		Breakdown: []lifeosapi.BudgetItem{
+			{CategoryID: cateringID, CategoryName: "Catering", Allocated: 400000},
+		},
+	}
+
+	item := budget.RecordSpend(cateringID, "Catering", 150000)
+
+	assert.Equal(t, 150000.0, item.Spent)
+	assert.Equal(t, 150000.0, budget.Breakdown[0].Spent)
+	assert.Equal(t, 150000.0, budget.Spent)
+	assert.False(t, item.OverBudget)
+
+	status := budget.Status(uuid.New())
+	assert.Equal(t, 850000.0, status.Remaining)
+	assert.Empty(t, status.OverBudgetItems)
+}
+
+func TestBudgetTracking_CategoryFlipsToOverBudget(t *testing.T) {
+	venueID := uuid.New()
+	budget := &lifeosapi.Budget{
+		TotalAmount: 1000000,
+		Breakdown: []lifeosapi.BudgetItem{
+			{CategoryID: venueID, CategoryName: "Venue", Allocated: 300000},
+		},
+	}
+
+	budget.RecordSpend(venueID, "Venue", 200000)
+	assert.False(t, budget.Breakdown[0].OverBudget)
+
+	budget.RecordSpend(venueID, "Venue", 150000)
+	assert.True(t, budget.Breakdown[0].OverBudget)
+	assert.Equal(t, 350000.0, budget.Breakdown[0].Spent)
+
+	status := budget.Status(uuid.New())
+	assert.Len(t, status.OverBudgetItems, 1)
+	assert.False(t, status.OverBudget, "event total is still within budget")
+}
+
+func TestBudgetTracking_UnallocatedCategory(t *testing.T) {
+	budget := &lifeosapi.Budget{TotalAmount: 500000}
+
+	item := budget.RecordSpend(uuid.New(), "Fireworks", 50000)
+
+	assert.Len(t, budget.Breakdown, 1)
+	assert.Equal(t, "Fireworks", item.CategoryName)
+	assert.True(t, item.OverBudget)
+}
+
+func TestBudgetTracking_CancelledBookingReversesSpend(t *testing.T) {
+	venueID := uuid.New()
+	budget := &lifeosapi.Budget{
+		TotalAmount: 1000000,
+		Breakdown: []lifeosapi.BudgetItem{
+			{CategoryID: venueID, CategoryName: "Venue", Allocated: 300000},
+		},
+	}
+	budget.RecordSpend(venueID, "Venue", 200000)
+	budget.RecordSpend(venueID, "Venue", 150000)
+	require.True(t, budget.Breakdown[0].OverBudget)
+
+	budget.ReverseSpend(venueID, 150000)
+	assert.Equal(t, 200000.0, budget.Breakdown[0].Spent)
+	assert.Equal(t, 200000.0, budget.Spent)
+	assert.False(t, budget.Breakdown[0].OverBudget)
+
+	// Reversing more than was spent leaves nothing spent, not a credit
+	budget.ReverseSpend(venueID, 500000)
+	assert.Zero(t, budget.Breakdown[0].Spent)
+	assert.Zero(t, budget.Spent)
+}
+
+func TestBudgetTracking_EventOverBudgetRespectsFlex(t *testing.T) {
+	budget := &lifeosapi.Budget{TotalAmount: 100000, FlexPercentage: 10}
+
+	budget.RecordSpend(uuid.New(), "Decoration", 105000)
+	assert.False(t, budget.IsOverBudget())
+
+	budget.RecordSpend(uuid.New(), "Lighting", 10000)
+	assert.True(t, budget.IsOverBudget())
+}
+
+// Test Event Lifecycle
+
+func TestEventLifecycle_AdvanceToBookingFiresHook(t *testing.T) {
+	lifecycle := lifeosapi.NewEventLifecycle()
+	var fired []*lifeosapi.LifecycleChange
+	lifecycle.OnTransition(func(ctx context.Context, event *lifeosapi.LifeEvent, change *lifeosapi.LifecycleChange) {
+		fired = append(fired, change)
+	})
+
+	event := &lifeosapi.LifeEvent{
+		ID:     uuid.New(),
+		Status: lifeosapi.StatusPlanning,
+		Phase:  lifeosapi.PhaseVendorSelect,
+		RequiredServices: []lifeosapi.RequiredService{
+			{CategoryID: uuid.New(), CategoryName: "Catering", Priority: lifeosapi.PriorityCritical, Status: lifeosapi.RequirementShortlisted},
+			{CategoryID: uuid.New(), CategoryName: "Venue", Status: lifeosapi.RequirementBooked},
+		},
+	}
+
+	change, err := lifecycle.AdvancePhase(context.Background(), event, lifeosapi.PhaseBooking)
+	require.NoError(t, err)
+
+	assert.Equal(t, lifeosapi.PhaseBooking, event.Phase)
+	assert.Equal(t, lifeosapi.PhaseVendorSelect, change.FromPhase)
+	assert.Equal(t, 50.0, event.CompletionPct)
+	require.Len(t, fired, 1)
+	assert.Same(t, change, fired[0])
+	require.Len(t, change.Actions, 1)
+	assert.Equal(t, "Book Catering", change.Actions[0].Title)
+	assert.Equal(t, "high", change.Actions[0].Priority)
+}
+
+func TestEventLifecycle_PhaseChangeKeepsActionsOnTheEvent(t *testing.T) {
+	lifecycle := lifeosapi.NewEventLifecycle()
+	engine := lifeosapi.NewOrchestrationEngine(nil, nil)
+	catering := uuid.New()
+	event := &lifeosapi.LifeEvent{
+		ID:     uuid.New(),
+		Status: lifeosapi.StatusPlanning,
+		Phase:  lifeosapi.PhaseVendorSelect,
+		Budget: &lifeosapi.Budget{TotalAmount: 100000},
+		RequiredServices: []lifeosapi.RequiredService{
+			{CategoryID: catering, CategoryName: "Catering", Status: lifeosapi.RequirementShortlisted},
+		},
+	}
+
+	change, err := lifecycle.AdvancePhase(context.Background(), event, lifeosapi.PhaseBooking)
+	require.NoError(t, err)
+	assert.Equal(t, change.Actions, event.PendingActions)
+	plan := engine.AssemblePlan(event, nil, nil, nil, nil)
+	require.Len(t, plan.NextActions, 1)
+	assert.Equal(t, "Book Catering", plan.NextActions[0].Title)
+
+	// Done once the booking is made
+	event.RequiredServices[0].Status = lifeosapi.RequirementBooked
+	assert.Empty(t, engine.AssemblePlan(event, nil, nil, nil, nil).NextActions)
+
+	// The next phase replaces them
+	_, err = lifecycle.AdvancePhase(context.Background(), event, lifeosapi.PhasePreEvent)
+	require.NoError(t, err)
+	require.Len(t, event.PendingActions, 1)
+	assert.Equal(t, "Confirm all vendors", event.PendingActions[0].Title)
+}
+
+// recordingLifecycleNotifier records the changes owners are told about
+type recordingLifecycleNotifier struct {
+	changes []*lifeosapi.LifecycleChange
+}
+
+func (r *recordingLifecycleNotifier) NotifyEventChange(ctx context.Context, event *lifeosapi.LifeEvent, change *lifeosapi.LifecycleChange) {
+	r.changes = append(r.changes, change)
+}
+
+func TestLifeOSAPI_SetNotifierNotifiesEveryTransition(t *testing.T) {
+	api := lifeosapi.NewLifeOSAPI(nil, nil)
+	notifier := &recordingLifecycleNotifier{}
+	api.SetNotifier(notifier)
+	event := &lifeosapi.LifeEvent{
+		ID:        uuid.New(),
+		EventType: lifeosapi.EventTypeWedding,
+		Status:    lifeosapi.StatusDetected,
+		Phase:     lifeosapi.PhaseDiscovery,
+	}
+
+	_, err := api.Lifecycle().Transition(context.Background(), event, lifeosapi.PhasePlanning, lifeosapi.StatusConfirmed)
+	require.NoError(t, err)
+	_, err = api.Lifecycle().AdvancePhase(context.Background(), event, lifeosapi.PhaseVendorSelect)
+	require.NoError(t, err)
+
+	require.Len(t, notifier.changes, 2)
+	title, message := notifier.changes[1].Notification(event)
+	assert.Equal(t, "Your wedding has moved to vendor select", title)
+	assert.Equal(t, "Open your event to see what's next.", message)
+}
+
+func TestLifecycleChange_NotificationListsTheNextActions(t *testing.T) {
+	event := &lifeosapi.LifeEvent{ID: uuid.New(), EventType: lifeosapi.EventTypeWedding, Status: lifeosapi.StatusBooked, Phase: lifeosapi.PhaseBooking}
+
+	change, err := lifeosapi.NewEventLifecycle().AdvancePhase(context.Background(), event, lifeosapi.PhasePreEvent)
+	require.NoError(t, err)
+
+	title, message := change.Notification(event)
+	assert.Equal(t, "Your wedding has moved to pre event", title)
+	assert.Equal(t, "Next: Confirm all vendors", message)
+}
+
+func TestEventLifecycle_IllegalJumpRejected(t *testing.T) {
+	lifecycle := lifeosapi.NewEventLifecycle()
+	fired := 0
+	lifecycle.OnTransition(func(ctx context.Context, event *lifeosapi.LifeEvent, change *lifeosapi.LifecycleChange) {
+		fired++
+	})
+
+	event := &lifeosapi.LifeEvent{
+		ID:     uuid.New(),
+		Status: lifeosapi.StatusConfirmed,
+		Phase:  lifeosapi.PhaseDiscovery,
+	}
+
+	_, err := lifecycle.AdvancePhase(context.Background(), event, lifeosapi.PhaseBooking)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, lifeosapi.ErrInvalidTransition))
+	assert.Equal(t, lifeosapi.PhaseDiscovery, event.Phase)
+	assert.Zero(t, fired)
+
+	_, err = lifecycle.SetStatus(context.Background(), event, lifeosapi.StatusCompleted)
+	assert.True(t, errors.Is(err, lifeosapi.ErrInvalidTransition))
+	assert.Equal(t, lifeosapi.StatusConfirmed, event.Status)
+}
+
+func TestEventLifecycle_TerminalStatusIsFinal(t *testing.T) {
+	lifecycle := lifeosapi.NewEventLifecycle()
+	event := &lifeosapi.LifeEvent{Status: lifeosapi.StatusPlanning, Phase: lifeosapi.PhasePlanning}
+
+	_, err := lifecycle.SetStatus(context.Background(), event, lifeosapi.StatusCancelled)
+	require.NoError(t, err)
+
+	_, err = lifecycle.AdvancePhase(context.Background(), event, lifeosapi.PhaseVendorSelect)
+	assert.True(t, errors.Is(err, lifeosapi.ErrInvalidTransition))
+}
+
+// Test Service Completion
+
+func completionEvent(phase lifeosapi.EventPhase, statuses ...lifeosapi.ServiceRequirementStatus) *lifeosapi.LifeEvent {
+	priorities := []lifeosapi.ServicePriority{lifeosapi.PriorityCritical, lifeosapi.PriorityCritical, lifeosapi.PriorityHigh}
+	event := &lifeosapi.LifeEvent{ID: uuid.New(), Status: lifeosapi.StatusPlanning, Phase: phase}
+	for i, priority := range priorities {
+		event.RequiredServices = append(event.RequiredServices, lifeosapi.RequiredService{
+			CategoryID: uuid.New(),
+			Priority:   priority,
+			Status:     statuses[i],
+		})
+	}
+	// Medium priority services never count towards completion
+	event.RequiredServices = append(event.RequiredServices, lifeosapi.RequiredService{
+		CategoryID: uuid.New(),
+		Priority:   lifeosapi.PriorityMedium,
+		Status:     lifeosapi.RequirementPending,
+	})
+	return event
+}
+
+func TestRecomputeCompletion_NothingBooked(t *testing.T) {
+	engine := lifeosapi.NewOrchestrationEngine(nil, nil)
+	event := completionEvent(lifeosapi.PhaseDiscovery,
+		lifeosapi.RequirementPending, lifeosapi.RequirementShortlisted, lifeosapi.RequirementPending)
+
+	pct, err := engine.RecomputeCompletion(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, pct)
+	assert.Equal(t, 0.0, event.CompletionPct)
+	assert.Equal(t, lifeosapi.PhaseDiscovery, event.Phase)
+}
+
+func TestRecomputeCompletion_CriticalBookedAdvancesToBooking(t *testing.T) {
+	engine := lifeosapi.NewOrchestrationEngine(nil, nil)
+	lifecycle := lifeosapi.NewEventLifecycle()
+	var phases []lifeosapi.EventPhase
+	lifecycle.OnTransition(func(ctx context.Context, event *lifeosapi.LifeEvent, change *lifeosapi.LifecycleChange) {
+		phases = append(phases, change.ToPhase)
+	})
+	engine.SetLifecycle(lifecycle)
+
+	event := completionEvent(lifeosapi.PhasePlanning,
+		lifeosapi.RequirementBooked, lifeosapi.RequirementPending, lifeosapi.RequirementPending)
+	pct, err := engine.RecomputeCompletion(context.Background(), event)
+	require.NoError(t, err)
+	assert.InDelta(t, 40, pct, 1e-9, "one of two critical services is 2 of 5 weight")
+	assert.Equal(t, lifeosapi.PhasePlanning, event.Phase)
+	assert.Empty(t, phases)
+
+	event.RequiredServices[1].Status = lifeosapi.RequirementBooked
+	pct, err = engine.RecomputeCompletion(context.Background(), event)
+	require.NoError(t, err)
+	assert.InDelta(t, 80, pct, 1e-9)
+	assert.Equal(t, 80.0, event.CompletionPct)
+	assert.Equal(t, lifeosapi.PhaseBooking, event.Phase)
+	assert.Equal(t, []lifeosapi.EventPhase{lifeosapi.PhaseVendorSelect, lifeosapi.PhaseBooking}, phases)
+}
+
+func TestRecomputeCompletion_AllBookedAdvancesToPreEvent(t *testing.T) {
+	engine := lifeosapi.NewOrchestrationEngine(nil, nil)
+	event := completionEvent(lifeosapi.PhaseBooking,
+		lifeosapi.RequirementBooked, lifeosapi.RequirementSkipped, lifeosapi.RequirementBooked)
+
+	pct, err := engine.RecomputeCompletion(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, pct)
+	assert.Equal(t, lifeosapi.PhasePreEvent, event.Phase)
+}
+
+func TestRecomputeCompletion_NeverMovesPhaseBack(t *testing.T) {
+	engine := lifeosapi.NewOrchestrationEngine(nil, nil)
+	event := completionEvent(lifeosapi.PhaseEventDay,
+		lifeosapi.RequirementBooked, lifeosapi.RequirementBooked, lifeosapi.RequirementPending)
+
+	_, err := engine.RecomputeCompletion(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Equal(t, lifeosapi.PhaseEventDay, event.Phase)
+}
+
+func TestEventCompletion_FurtherOfPhaseAndBookings(t *testing.T) {
+	event := completionEvent(lifeosapi.PhaseBooking,
+		lifeosapi.RequirementBooked, lifeosapi.RequirementPending, lifeosapi.RequirementPending)
+
+	// Half way through the phases is further than 40% booked
+	assert.InDelta(t, 50, lifeosapi.EventCompletion(event, nil), 1e-9)
+
+	// Half of the booking phase's tasks done counts towards it
+	plan := &lifeosapi.EventOrchestrationPlan{Phases: []lifeosapi.PhasePlan{
+		{Phase: lifeosapi.PhaseBooking, Tasks: []lifeosapi.PhaseTask{
+			{ID: uuid.New(), Status: lifeosapi.TaskStatusCompleted},
+			{ID: uuid.New(), Status: lifeosapi.TaskStatusPending},
+		}},
+	}}
+	assert.InDelta(t, 3.5/6*100, lifeosapi.EventCompletion(event, plan), 1e-9)
+
+	// 80% booked is further than either
+	event.RequiredServices[1].Status = lifeosapi.RequirementBooked
+	assert.InDelta(t, 80, lifeosapi.EventCompletion(event, plan), 1e-9)
+
+	event.Status = lifeosapi.StatusCompleted
+	assert.Equal(t, 100.0, lifeosapi.EventCompletion(event, nil))
+}
+
+func TestEventCompletion_PhaseChangeKeepsBookedShare(t *testing.T) {
+	event := completionEvent(lifeosapi.PhaseVendorSelect,
+		lifeosapi.RequirementBooked, lifeosapi.RequirementBooked, lifeosapi.RequirementPending)
+
+	_, err := lifeosapi.NewEventLifecycle().AdvancePhase(context.Background(), event, lifeosapi.PhaseBooking)
+
+	require.NoError(t, err)
+	assert.InDelta(t, 80, event.CompletionPct, 1e-9, "the phase hook must not overwrite booking progress")
+}
+
+// Test Vendor Constraints
+
+func constraintVendors() (cheap, pricey, lowRated lifeosapi.VendorRecommendation) {
+	cheap = lifeosapi.VendorRecommendation{VendorID: uuid.New(), VendorName: "Mama Put Catering", Price: 400000, Rating: 4.6, MatchScore: 0.8}
+	pricey = lifeosapi.VendorRecommendation{VendorID: uuid.New(), VendorName: "Eko Grand Catering", Price: 900000, Rating: 4.9, MatchScore: 0.9}
+	lowRated = lifeosapi.VendorRecommendation{VendorID: uuid.New(), VendorName: "Quick Chops", Price: 300000, Rating: 3.2, MatchScore: 0.85}
+	return
+}
+
+func vendorIDs(recs []lifeosapi.VendorRecommendation) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(recs))
+	for _, r := range recs {
+		ids = append(ids, r.VendorID)
+	}
+	return ids
+}
+
+func TestApplyConstraints_HardBudgetExcludesVendor(t *testing.T) {
+	cheap, pricey, lowRated := constraintVendors()
+	event := &lifeosapi.LifeEvent{Constraints: []lifeosapi.Constraint{
+		{Type: "budget", Operator: "lte", Value: 500000.0, IsHard: true},
+	}}
+
+	recs := lifeosapi.ApplyConstraints([]lifeosapi.VendorRecommendation{cheap, pricey, lowRated}, event)
+
+	assert.Equal(t, []uuid.UUID{lowRated.VendorID, cheap.VendorID}, vendorIDs(recs))
+}
+
+func TestApplyConstraints_SoftRatingDownRanks(t *testing.T) {
+	cheap, _, lowRated := constraintVendors()
+	event := &lifeosapi.LifeEvent{Constraints: []lifeosapi.Constraint{
+		{Type: "rating", Operator: "gte", Value: 4, IsHard: false},
+	}}
+
+	recs := lifeosapi.ApplyConstraints([]lifeosapi.VendorRecommendation{lowRated, cheap}, event)
+
+	require.Len(t, recs, 2, "soft constraints never exclude")
+	assert.Equal(t, []uuid.UUID{cheap.VendorID, lowRated.VendorID}, vendorIDs(recs))
+	assert.InDelta(t, 0.85-lifeosapi.SoftConstraintPenalty, recs[1].MatchScore, 1e-9)
+	assert.InDelta(t, 0.8, recs[0].MatchScore, 1e-9)
+}
+
+func TestApplyConstraints_BlockedExcludedPreferredBoosted(t *testing.T) {
+	cheap, pricey, lowRated := constraintVendors()
+	event := &lifeosapi.LifeEvent{Preferences: lifeosapi.EventPreferences{VendorPrefs: lifeosapi.VendorPreferences{
+		BlockedVendors:   []uuid.UUID{pricey.VendorID},
+		PreferredVendors: []uuid.UUID{cheap.VendorID},
+	}}}
+
+	recs := lifeosapi.ApplyConstraints([]lifeosapi.VendorRecommendation{pricey, lowRated, cheap}, event)
+
+	assert.Equal(t, []uuid.UUID{cheap.VendorID, lowRated.VendorID}, vendorIDs(recs))
+	assert.InDelta(t, 0.8+lifeosapi.PreferredVendorBoost, recs[0].MatchScore, 1e-9)
+	assert.Contains(t, recs[0].MatchReasons, "Preferred vendor")
+}
+
+func TestConstraint_DietaryDateAndLocation(t *testing.T) {
+	near, far := 5.0, 40.0
+	halal := lifeosapi.VendorRecommendation{Tags: []string{"Halal", "outdoor"}, Availability: "available", DistanceKM: &near}
+	other := lifeosapi.VendorRecommendation{Tags: []string{"continental"}, Availability: "unavailable", DistanceKM: &far}
+	unknown := lifeosapi.VendorRecommendation{}
+
+	dietary := lifeosapi.Constraint{Type: "dietary", Operator: "in", Value: []interface{}{"halal"}, IsHard: true}
+	assert.True(t, dietary.SatisfiedBy(halal))
+	assert.False(t, dietary.SatisfiedBy(other))
+
+	date := lifeosapi.Constraint{Type: "date", Operator: "eq", Value: "available", IsHard: true}
+	assert.True(t, date.SatisfiedBy(halal))
+	assert.False(t, date.SatisfiedBy(other))
+
+	location := lifeosapi.Constraint{Type: "location", Operator: "lte", Value: 25, IsHard: true}
+	assert.True(t, location.SatisfiedBy(halal))
+	assert.False(t, location.SatisfiedBy(other))
+	assert.True(t, location.SatisfiedBy(unknown), "no distance to check against")
+}
+
+// Test Plan Checklist
+
+func newChecklistPlan(eventID uuid.UUID) *lifeosapi.EventOrchestrationPlan {
+	task := func(phase lifeosapi.EventPhase, title string) lifeosapi.PhaseTask {
+		return lifeosapi.PhaseTask{
+			ID:     lifeosapi.PlanTaskID(eventID, phase, title),
+			Title:  title,
+			Status: lifeosapi.TaskStatusPending,
+		}
+	}
+	return &lifeosapi.EventOrchestrationPlan{
+		EventID: eventID,
+		Phases: []lifeosapi.PhasePlan{
+			{Phase: lifeosapi.PhaseDiscovery, Tasks: []lifeosapi.PhaseTask{
+				task(lifeosapi.PhaseDiscovery, "Confirm event date"),
+				task(lifeosapi.PhaseDiscovery, "Set budget"),
+			}},
+			{Phase: lifeosapi.PhasePlanning, Tasks: []lifeosapi.PhaseTask{
+				task(lifeosapi.PhasePlanning, "Research Catering options"),
+			}},
+		},
+	}
+}
+
+func TestPlanTasks_CompletingTasksAdvancesCompletion(t *testing.T) {
+	event := &lifeosapi.LifeEvent{
+		ID:     uuid.New(),
+		Status: lifeosapi.StatusConfirmed,
+		Phase:  lifeosapi.PhaseDiscovery,
+	}
+	plan := newChecklistPlan(event.ID)
+	lifecycle := lifeosapi.NewEventLifecycle()
+
+	first := plan.Phases[0].Tasks[0].ID
+	progress, err := lifecycle.RecordTaskStatus(context.Background(), event, plan, first, lifeosapi.TaskStatusCompleted)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, progress.PhaseCompletion)
+	assert.Nil(t, progress.PhaseChange)
+	assert.Equal(t, lifeosapi.PhaseDiscovery, event.Phase)
+	halfway := event.CompletionPct
+	assert.Greater(t, halfway, 0.0)
+
+	// Finishing the phase moves the event on to planning
+	second := plan.Phases[0].Tasks[1].ID
+	progress, err = lifecycle.RecordTaskStatus(context.Background(), event, plan, second, lifeosapi.TaskStatusCompleted)
+	require.NoError(t, err)
+	require.NotNil(t, progress.PhaseChange)
+	assert.Equal(t, lifeosapi.PhasePlanning, event.Phase)
+	assert.Equal(t, lifeosapi.PhasePlanning, progress.CurrentPhase)
+	assert.Equal(t, "completed", plan.Phases[0].Status)
+	assert.Equal(t, "active", plan.Phases[1].Status)
+	assert.Greater(t, event.CompletionPct, halfway)
+}
+
+func TestPlanTasks_ReopenLowersCompletionWithoutRegressingPhase(t *testing.T) {
+	event := &lifeosapi.LifeEvent{
+		ID:     uuid.New(),
+		Status: lifeosapi.StatusPlanning,
+		Phase:  lifeosapi.PhasePlanning,
+	}
+	plan := newChecklistPlan(event.ID)
+	lifecycle := lifeosapi.NewEventLifecycle()
+	research := plan.Phases[1].Tasks[0].ID
+
+	_, err := lifecycle.RecordTaskStatus(context.Background(), event, plan, research, lifeosapi.TaskStatusCompleted)
+	require.NoError(t, err)
+	require.Equal(t, lifeosapi.PhaseVendorSelect, event.Phase)
+	advanced := event.CompletionPct
+
+	progress, err := lifecycle.RecordTaskStatus(context.Background(), event, plan, research, lifeosapi.TaskStatusPending)
+	require.NoError(t, err)
+	assert.Equal(t, lifeosapi.TaskStatusPending, progress.Task.Status)
+	assert.Equal(t, 0.0, progress.PhaseCompletion)
+	assert.Equal(t, lifeosapi.PhaseVendorSelect, event.Phase)
+	assert.Equal(t, advanced, event.CompletionPct)
+}
+
+func TestPlanTasks_UnknownTaskRejected(t *testing.T) {
+	event := &lifeosapi.LifeEvent{ID: uuid.New(), Status: lifeosapi.StatusConfirmed, Phase: lifeosapi.PhaseDiscovery}
+	plan := newChecklistPlan(event.ID)
+
+	_, err := lifeosapi.NewEventLifecycle().RecordTaskStatus(context.Background(), event, plan, uuid.New(), lifeosapi.TaskStatusCompleted)
+	assert.True(t, errors.Is(err, lifeosapi.ErrTaskNotFound))
+}
+
+func TestPlanTasks_StableIDsAcrossRegeneration(t *testing.T) {
+	eventID := uuid.New()
+	first := newChecklistPlan(eventID)
+	regenerated := newChecklistPlan(eventID)
+
+	statuses := map[uuid.UUID]string{first.Phases[0].Tasks[0].ID: lifeosapi.TaskStatusCompleted}
+	lifeosapi.ApplyTaskStatuses(regenerated, lifeosapi.PhaseDiscovery, statuses)
+
+	assert.Equal(t, lifeosapi.TaskStatusCompleted, regenerated.Phases[0].Tasks[0].Status)
+	assert.Equal(t, 50.0, regenerated.Phases[0].CompletionPct)
+	assert.NotEqual(t, first.Phases[0].Tasks[0].ID, lifeosapi.PlanTaskID(uuid.New(), lifeosapi.PhaseDiscovery, "Confirm event date"))
+}
+
+// Test Bundle Availability
+
+func TestBundleVendors_SubstitutesUnavailableDefault(t *testing.T) {
+	catering, photography := uuid.New(), uuid.New()
+	defaultCaterer, backupCaterer, photographer := uuid.New(), uuid.New(), uuid.New()
+	assignments := []lifeosapi.BundleVendor{
+		{VendorID: backupCaterer, CategoryID: catering, BundlePrice: 300000, IsRequired: true, DisplayOrder: 2},
+		{VendorID: defaultCaterer, CategoryID: catering, BundlePrice: 250000, IsRequired: true, IsDefault: true, DisplayOrder: 1},
+		{VendorID: photographer, CategoryID: photography, BundlePrice: 150000, IsDefault: true},
+	}
+
+	vendors, missing, ok := lifeosapi.ResolveBundleVendors(assignments, map[uuid.UUID]bool{defaultCaterer: true})
+
+	require.True(t, ok)
+	assert.Empty(t, missing)
+	require.Len(t, vendors, 2)
+	assert.Equal(t, backupCaterer, vendors[0].VendorID)
+	require.NotNil(t, vendors[0].SubstitutedFor)
+	assert.Equal(t, defaultCaterer, *vendors[0].SubstitutedFor)
+	assert.Equal(t, photographer, vendors[1].VendorID)
+	assert.Nil(t, vendors[1].SubstitutedFor)
+}
+
+func TestBundleVendors_FiltersBundleWhenRequiredCategoryUnavailable(t *testing.T) {
+	venue := uuid.New()
+	onlyVenue := uuid.New()
+	assignments := []lifeosapi.BundleVendor{
+		{VendorID: onlyVenue, CategoryID: venue, IsRequired: true, IsDefault: true},
+	}
+
+	_, _, ok := lifeosapi.ResolveBundleVendors(assignments, map[uuid.UUID]bool{onlyVenue: true})
+	assert.False(t, ok)
+}
+
+func TestBundleVendors_FlagsUnavailableOptionalCategory(t *testing.T) {
+	catering, decor := uuid.New(), uuid.New()
+	caterer, decorator := uuid.New(), uuid.New()
+	assignments := []lifeosapi.BundleVendor{
+		{VendorID: caterer, CategoryID: catering, IsRequired: true, IsDefault: true},
+		{VendorID: decorator, CategoryID: decor, IsDefault: true},
+	}
+
+	vendors, missing, ok := lifeosapi.ResolveBundleVendors(assignments, map[uuid.UUID]bool{decorator: true})
+
+	require.True(t, ok)
+	require.Len(t, vendors, 1)
+	assert.Equal(t, caterer, vendors[0].VendorID)
+	assert.Equal(t, []uuid.UUID{decor}, missing)
+}
+
+// Test Bundle Pricing
+
+func TestPriceBundleOption_AssignedBundleSumsVendorPrices(t *testing.T) {
+	catering, photography := uuid.New(), uuid.New()
+	assignments := []lifeosapi.BundleVendor{
+		{VendorID: uuid.New(), CategoryID: catering, BundlePrice: 250000, IsDefault: true},
+		{VendorID: uuid.New(), CategoryID: photography, BundlePrice: 150000, IsDefault: true},
+	}
+	vendors, _, ok := lifeosapi.ResolveBundleVendors(assignments, nil)
+	require.True(t, ok)
+	bundle := lifeosapi.BundleOption{IncludedServices: []uuid.UUID{catering, photography}, SavingsPercent: 20, Vendors: vendors}
+
+	estimate := func(uuid.UUID) lifeosapi.PriceRange {
+		t.Fatal("assigned bundles are not estimated")
+		return lifeosapi.PriceRange{}
+	}
+	priced, ok := lifeosapi.PriceBundleOption(bundle, assignments, estimate)
+
+	require.True(t, ok)
+	assert.Equal(t, 400000.0, priced.TotalPrice)
+	assert.InDelta(t, 100000, priced.Savings, 0.01)
+}
+
+func TestPriceBundleOption_UnassignedBundleUsesCategoryEstimates(t *testing.T) {
+	catering, photography := uuid.New(), uuid.New()
+	estimates := map[uuid.UUID]lifeosapi.PriceRange{
+		catering:    {Min: 200000, Max: 400000},
+		photography: {Min: 100000, Max: 200000},
+	}
+	bundle := lifeosapi.BundleOption{IncludedServices: []uuid.UUID{catering, photography}, SavingsPercent: 10}
+
+	priced, ok := lifeosapi.PriceBundleOption(bundle, nil, func(id uuid.UUID) lifeosapi.PriceRange { return estimates[id] })
+
+	require.True(t, ok)
+	assert.InDelta(t, 405000, priced.TotalPrice, 0.01)
+	assert.InDelta(t, 450000, priced.RegularPrice(), 0.01)
+	assert.InDelta(t, 45000, priced.Savings, 0.01)
+}
+
+func TestPriceBundleOption_SkipsZeroTotalBundle(t *testing.T) {
+	bundle := lifeosapi.BundleOption{IncludedServices: []uuid.UUID{uuid.New()}, SavingsPercent: 15}
+
+	priced, ok := lifeosapi.PriceBundleOption(bundle, nil, func(uuid.UUID) lifeosapi.PriceRange { return lifeosapi.PriceRange{} })
+
+	assert.False(t, ok)
+	assert.Zero(t, priced.Savings)
+}
+
+// Test Plan Versions
+
+func newVersionedEvent(total float64) (*lifeosapi.LifeEvent, []lifeosapi.PlannedService) {
+	event := &lifeosapi.LifeEvent{
+		ID:        uuid.New(),
+		EventType: lifeosapi.EventTypeWedding,
+		Budget:    &lifeosapi.Budget{TotalAmount: total, Currency: "NGN"},
+	}
+	services := []lifeosapi.PlannedService{
+		{CategoryID: uuid.New(), CategoryName: "Catering", Priority: lifeosapi.PriorityCritical, BudgetAllocation: 60, Status: "pending"},
+		{CategoryID: uuid.New(), CategoryName: "Venue", Priority: lifeosapi.PriorityCritical, BudgetAllocation: 40, Status: "pending"},
+	}
+	return event, services
+}
+
+func TestPlanVersions_BudgetChangeShowsInDiff(t *testing.T) {
+	engine := &lifeosapi.OrchestrationEngine{}
+	event, services := newVersionedEvent(1000000)
+	before := engine.AssemblePlan(event, services, nil, nil, nil)
+
+	event.Budget.TotalAmount = 800000
+	after := engine.AssemblePlan(event, services, nil, nil, nil)
+
+	diff := lifeosapi.DiffPlans(before, after)
+	require.False(t, diff.IsEmpty())
+	assert.Equal(t, -200000.0, diff.TotalBudgetChange)
+	require.Len(t, diff.BudgetShifts, 2)
+	assert.Equal(t, "Catering", diff.BudgetShifts[0].CategoryName)
+	assert.Equal(t, 600000.0, diff.BudgetShifts[0].From)
+	assert.Equal(t, 480000.0, diff.BudgetShifts[0].To)
+	assert.Equal(t, -120000.0, diff.BudgetShifts[0].Change)
+	assert.Equal(t, -80000.0, diff.BudgetShifts[1].Change)
+	assert.Empty(t, diff.AddedServices)
+	assert.Empty(t, diff.RemovedServices)
+	assert.Empty(t, diff.NewRisks)
+}
+
+func TestPlanVersions_AddedServiceAndNewRisk(t *testing.T) {
+	engine := &lifeosapi.OrchestrationEngine{}
+	event, services := newVersionedEvent(1000000)
+	before := engine.AssemblePlan(event, services, nil, nil, nil)
+
+	decor := lifeosapi.PlannedService{CategoryID: uuid.New(), CategoryName: "Decor", Priority: lifeosapi.PriorityHigh, BudgetAllocation: 25}
+	withDecor := append(append([]lifeosapi.PlannedService{}, services...), decor)
+	after := engine.AssemblePlan(event, withDecor, nil, nil, nil)
+
+	diff := lifeosapi.DiffPlans(before, after)
+	require.Len(t, diff.AddedServices, 1)
+	assert.Equal(t, "Decor", diff.AddedServices[0].CategoryName)
+	assert.Empty(t, diff.RemovedServices)
+	require.Len(t, diff.NewRisks, 1)
+	assert.Equal(t, "budget", diff.NewRisks[0].Type)
+
+	// Dropping the service again resolves the risk
+	back := lifeosapi.DiffPlans(after, before)
+	require.Len(t, back.RemovedServices, 1)
+	assert.Equal(t, decor.CategoryID, back.RemovedServices[0].CategoryID)
+	assert.Len(t, back.ResolvedRisks, 1)
+}
+
+func TestPlanVersions_UnchangedRegenerationIsEmpty(t *testing.T) {
+	engine := &lifeosapi.OrchestrationEngine{}
+	event, services := newVersionedEvent(1000000)
+	services = append(services, lifeosapi.PlannedService{CategoryID: uuid.New(), CategoryName: "Decor", BudgetAllocation: 25})
+
+	first := engine.AssemblePlan(event, services, nil, nil, nil)
+	second := engine.AssemblePlan(event, services, nil, nil, nil)
+
+	require.Len(t, second.Risks, 1)
+	assert.NotEqual(t, first.Risks[0].ID, second.Risks[0].ID)
+	assert.True(t, lifeosapi.DiffPlans(first, second).IsEmpty())
+}
+
+// Test Bundle Discount Stacking
+
+func TestPriceBundle_PromoStacksWithBundleDiscountUpToCap(t *testing.T) {
+	bundle := lifeosapi.BundleOption{Name: "Wedding Essentials", TotalPrice: 900000, SavingsPercent: 10}
+	assert.InDelta(t, 1000000, bundle.RegularPrice(), 0.01)
+
+	promo := discount.Discount{Source: discount.SourcePromo, Code: "JUNE", Percent: 25}
+	result := lifeosapi.PriceBundle(bundle, []discount.Discount{promo}, discount.Policy{Rule: discount.RuleAdditive, MaxPercent: 30})
+
+	require.Len(t, result.Applied, 2)
+	assert.Equal(t, discount.SourcePromo, result.Applied[0].Source)
+	assert.Equal(t, discount.SourceBundle, result.Applied[1].Source)
+	assert.True(t, result.Applied[1].Capped)
+	assert.Equal(t, 300000.0, result.TotalDiscount)
+	assert.Equal(t, 700000.0, result.EffectiveTotal)
+}
+
+func TestPriceBundle_BestOfKeepsBundleDiscountWhenLarger(t *testing.T) {
+	bundle := lifeosapi.BundleOption{Name: "Wedding Essentials", TotalPrice: 850000, SavingsPercent: 15}
+	subscription := discount.Discount{Source: discount.SourceSubscription, Percent: 5}
+
+	result := lifeosapi.PriceBundle(bundle, []discount.Discount{subscription}, discount.Policy{Rule: discount.RuleBestOf})
+	require.Len(t, result.Applied, 1)
+	assert.Equal(t, discount.SourceBundle, result.Applied[0].Source)
+	assert.Equal(t, 850000.0, result.EffectiveTotal)
+}
+
+// Test ML Event Prediction
+
+type fixedSignalProcessor struct {
+	signals       []lifeosapi.DetectionSignal
+	probabilities map[lifeosapi.EventType]float64
+}
+
+func (p *fixedSignalProcessor) ProcessSignals(ctx context.Context, userID uuid.UUID, window time.Duration) ([]lifeosapi.DetectionSignal, error) {
+	return p.signals, nil
+}
+
+func (p *fixedSignalProcessor) GetEventProbabilities(signals []lifeosapi.DetectionSignal) map[lifeosapi.EventType]float64 {
+	return p.probabilities
+}
+
+func detectedConfidence(t *testing.T, engine *lifeosapi.EventDetectionEngine) map[lifeosapi.EventType]float64 {
+	t.Helper()
+	events, _, err := engine.DetectEvents(context.Background(), uuid.New())
+	require.NoError(t, err)
+	confidence := map[lifeosapi.EventType]float64{}
+	for _, e := range events {
+		confidence[e.EventType] = e.DetectionConfidence
+	}
+	return confidence
+}
+
+func TestSignalFeatures_DirectAndClusterSignals(t *testing.T) {
+	now := time.Now()
+	features := lifeosapi.SignalFeatures([]lifeosapi.DetectionSignal{
+		{SignalType: "search_pattern", Value: "wedding", Timestamp: now},
+		{SignalType: "search_pattern", Value: "wedding", Timestamp: now.Add(-48 * time.Hour)},
+		{SignalType: "browse_pattern", Value: "celebrations", Timestamp: now.Add(-14 * 24 * time.Hour)},
+	}, now)
+
+	wedding := features[lifeosapi.EventTypeWedding]
+	assert.Equal(t, 2.0, wedding.Frequency)
+	assert.Equal(t, 1.0, wedding.ClusterCount)
+	assert.InDelta(t, 1.0, wedding.Recency, 1e-9, "most recent signal wins")
+
+	birthday := features[lifeosapi.EventTypeBirthday]
+	assert.Equal(t, 0.0, birthday.Frequency)
+	assert.Equal(t, 1.0, birthday.ClusterCount)
+	assert.InDelta(t, 0.5, birthday.Recency, 1e-9, "two weeks old is one half life")
+}
+
+func TestEventWeights_LogisticScore(t *testing.T) {
+	w := lifeosapi.EventWeights{Bias: -2, Recency: 1, Frequency: 0.5, ClusterCount: 0.25}
+
+	assert.InDelta(t, 0.5, w.Score(lifeosapi.EventFeatures{Recency: 1, Frequency: 2}), 1e-9)
+	want := 1 / (1 + math.Exp(-(-2 + 0.5 + 0.5*1 + 0.25*4)))
+	assert.InDelta(t, want, w.Score(lifeosapi.EventFeatures{Recency: 0.5, Frequency: 1, ClusterCount: 4}), 1e-9)
+}
+
+func TestBlendPredictions(t *testing.T) {
+	heuristic := map[lifeosapi.EventType]float64{
+		lifeosapi.EventTypeWedding:    0.4,
+		lifeosapi.EventTypeRelocation: 0.7,
+	}
+	predicted := map[lifeosapi.EventType]float64{
+		lifeosapi.EventTypeWedding:  0.8,
+		lifeosapi.EventTypeBirthday: 0.6,
+	}
+
+	blended := lifeosapi.BlendPredictions(heuristic, predicted, 0.25)
+	assert.InDelta(t, 0.75*0.4+0.25*0.8, blended[lifeosapi.EventTypeWedding], 1e-9)
+	assert.InDelta(t, 0.7, blended[lifeosapi.EventTypeRelocation], 1e-9, "no model prediction keeps the heuristic")
+	assert.InDelta(t, 0.25*0.6, blended[lifeosapi.EventTypeBirthday], 1e-9)
+	assert.InDelta(t, 0.4, heuristic[lifeosapi.EventTypeWedding], 1e-9, "inputs are not modified")
+}
+
+func TestDetectEvents_BlendsModelWhereWeightsExist(t *testing.T) {
+	now := time.Now()
+	processor := &fixedSignalProcessor{
+		signals: []lifeosapi.DetectionSignal{
+			{SignalType: "search_pattern", Value: "wedding", Confidence: 0.5, Timestamp: now},
+			{SignalType: "search_pattern", Value: "relocation", Confidence: 0.5, Timestamp: now},
+		},
+		probabilities: map[lifeosapi.EventType]float64{
+			lifeosapi.EventTypeWedding:    0.4,
+			lifeosapi.EventTypeRelocation: 0.7,
+		},
+	}
+	weights := map[lifeosapi.EventType]lifeosapi.EventWeights{
+		lifeosapi.EventTypeWedding: {Bias: -1, Recency: 1, Frequency: 2}, // z = 2
+	}
+	newEngine := func(enableML bool) *lifeosapi.EventDetectionEngine {
+		engine := lifeosapi.NewEventDetectionEngine(nil, nil, &lifeosapi.DetectionConfig{
+			MinConfidenceThreshold: 0.1,
+			SignalWindowDays:       90,
+			EnableMLPrediction:     enableML,
+			MLBlendWeight:          0.5,
+		})
+		engine.SetSignalProcessor(lifeosapi.DetectionBehavioral, processor)
+		predictor := lifeosapi.NewMLEventPredictor(nil)
+		predictor.SetWeights(weights)
+		engine.SetMLPredictor(predictor)
+		return engine
+	}
+
+	withML := detectedConfidence(t, newEngine(true))
+	model := 1 / (1 + math.Exp(-2))
+	assert.InDelta(t, 0.5*0.4+0.5*model, withML[lifeosapi.EventTypeWedding], 1e-6)
+	assert.InDelta(t, 0.7, withML[lifeosapi.EventTypeRelocation], 1e-9, "no weights falls back to the heuristic")
+
+	withoutML := detectedConfidence(t, newEngine(false))
+	assert.InDelta(t, 0.4, withoutML[lifeosapi.EventTypeWedding], 1e-9)
+	assert.InDelta(t, 0.7, withoutML[lifeosapi.EventTypeRelocation], 1e-9)
+}
+
+func TestDetectEvents_NoModelWeightsUsesHeuristic(t *testing.T) {
+	engine := lifeosapi.NewEventDetectionEngine(nil, nil, &lifeosapi.DetectionConfig{
+		MinConfidenceThreshold: 0.1,
+		SignalWindowDays:       90,
+		EnableMLPrediction:     true,
+		MLBlendWeight:          0.5,
+	})
+	engine.SetSignalProcessor(lifeosapi.DetectionBehavioral, &fixedSignalProcessor{
+		signals:       []lifeosapi.DetectionSignal{{Value: "wedding", Timestamp: time.Now()}},
+		probabilities: map[lifeosapi.EventType]float64{lifeosapi.EventTypeWedding: 0.4},
+	})
+
+	assert.InDelta(t, 0.4, detectedConfidence(t, engine)[lifeosapi.EventTypeWedding], 1e-9)
+}
+
+// Test Detection Diagnostics
+
+type blockingSignalProcessor struct {
+	release chan struct{}
+}
+
+func (p *blockingSignalProcessor) ProcessSignals(ctx context.Context, userID uuid.UUID, window time.Duration) ([]lifeosapi.DetectionSignal, error) {
+	// Ignores ctx, like a processor stuck on a slow query
+	<-p.release
+	return []lifeosapi.DetectionSignal{{Value: "relocation", Confidence: 0.9}}, nil
+}
+
+func (p *blockingSignalProcessor) GetEventProbabilities(signals []lifeosapi.DetectionSignal) map[lifeosapi.EventType]float64 {
+	return nil
+}
+
+type failingSignalProcessor struct{}
+
+func (failingSignalProcessor) ProcessSignals(ctx context.Context, userID uuid.UUID, window time.Duration) ([]lifeosapi.DetectionSignal, error) {
+	return nil, errors.New("calendar provider unavailable")
+}
+
+func (failingSignalProcessor) GetEventProbabilities(signals []lifeosapi.DetectionSignal) map[lifeosapi.EventType]float64 {
+	return nil
+}
+
+func diagnosticsEngine() *lifeosapi.EventDetectionEngine {
+	engine := lifeosapi.NewEventDetectionEngine(nil, nil, &lifeosapi.DetectionConfig{
+		MinConfidenceThreshold: 0.1,
+		SignalWindowDays:       90,
+	})
+	engine.SetSignalProcessor(lifeosapi.DetectionBehavioral, &fixedSignalProcessor{
+		signals:       []lifeosapi.DetectionSignal{{Value: "wedding", Confidence: 0.5, Timestamp: time.Now()}},
+		probabilities: map[lifeosapi.EventType]float64{lifeosapi.EventTypeWedding: 0.4},
+	})
+	return engine
+}
+
+func TestDetectEvents_SlowProcessorCutOffAtDeadline(t *testing.T) {
+	engine := diagnosticsEngine()
+	slow := &blockingSignalProcessor{release: make(chan struct{})}
+	defer close(slow.release)
+	engine.SetSignalProcessor(lifeosapi.DetectionCalendar, slow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	events, diagnostics, err := engine.DetectEvents(ctx, uuid.New())
+
+	require.NoError(t, err)
+	assert.Less(t, time.Since(started), time.Second)
+	require.Len(t, events, 1)
+	assert.Equal(t, lifeosapi.EventTypeWedding, events[0].EventType)
+
+	assert.True(t, diagnostics.Partial())
+	assert.Equal(t, []lifeosapi.DetectionMethod{lifeosapi.DetectionBehavioral}, diagnostics.Succeeded)
+	require.Len(t, diagnostics.Failed, 1)
+	assert.Equal(t, lifeosapi.DetectionCalendar, diagnostics.Failed[0].Method)
+	assert.True(t, diagnostics.Failed[0].TimedOut)
+}
+
+func TestDetectEvents_FailingProcessorDoesNotAbortDetection(t *testing.T) {
+	engine := diagnosticsEngine()
+	engine.SetSignalProcessor(lifeosapi.DetectionCalendar, failingSignalProcessor{})
+
+	events, diagnostics, err := engine.DetectEvents(context.Background(), uuid.New())
+
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Len(t, diagnostics.Failed, 1)
+	assert.Equal(t, lifeosapi.DetectionCalendar, diagnostics.Failed[0].Method)
+	assert.Equal(t, "calendar provider unavailable", diagnostics.Failed[0].Error)
+	assert.False(t, diagnostics.Failed[0].TimedOut)
+}
+
+func TestDetectEvents_ErrorsWhenEveryProcessorFails(t *testing.T) {
+	engine := lifeosapi.NewEventDetectionEngine(nil, nil, nil)
+	engine.SetSignalProcessor(lifeosapi.DetectionBehavioral, failingSignalProcessor{})
+
+	events, diagnostics, err := engine.DetectEvents(context.Background(), uuid.New())
+
+	assert.Error(t, err)
+	assert.Empty(t, events)
+	assert.Len(t, diagnostics.Failed, 1)
+}
+
+// Test Calendar Signals
+
+func TestCalendarSignals_FutureWeddingDetectedPastIgnored(t *testing.T) {
+	now := time.Now()
+	signals := lifeosapi.CalendarSignals([]lifeosapi.CalendarEntry{
+		{Title: "Tolu & Dayo's Wedding", StartTime: now.Add(60 * 24 * time.Hour), AttendeeCount: 150},
+		{Title: "Cousin's wedding", StartTime: now.Add(-7 * 24 * time.Hour), AttendeeCount: 150},
+		{Title: "Dentist", StartTime: now.Add(24 * time.Hour)},
+	}, now)
+
+	require.Len(t, signals, 1)
+	assert.Equal(t, "calendar_event", signals[0].SignalType)
+	assert.Equal(t, string(lifeosapi.EventTypeWedding), signals[0].Value)
+
+	probabilities := (&lifeosapi.CalendarSignalProcessor{}).GetEventProbabilities(signals)
+	assert.Contains(t, probabilities, lifeosapi.EventTypeWedding)
+	assert.Len(t, probabilities, 1)
+}
+
+func TestCalendarSignals_ConfidenceFallsWithDistance(t *testing.T) {
+	now := time.Now()
+	signals := lifeosapi.CalendarSignals([]lifeosapi.CalendarEntry{
+		{Title: "Baby shower", StartTime: now.Add(7 * 24 * time.Hour)},
+		{Title: "Move out of flat", StartTime: now.Add(300 * 24 * time.Hour)},
+		{Title: "Wedding in Abeokuta", StartTime: now.Add(400 * 24 * time.Hour)},
+	}, now)
+
+	require.Len(t, signals, 2, "entries beyond a year are ignored")
+	assert.Equal(t, string(lifeosapi.EventTypeChildbirth), signals[0].Value)
+	assert.Equal(t, string(lifeosapi.EventTypeRelocation), signals[1].Value)
+	assert.Greater(t, signals[0].Confidence, signals[1].Confidence)
+	assert.GreaterOrEqual(t, signals[1].Confidence, 0.4)
+}
+
+func TestCalendarProbabilities_IgnoreOtherSignalTypes(t *testing.T) {
+	processor := &lifeosapi.CalendarSignalProcessor{}
+	probabilities := processor.GetEventProbabilities([]lifeosapi.DetectionSignal{
+		{SignalType: "search_pattern", Value: "wedding", Confidence: 0.9},
+		{SignalType: "calendar_event", Value: "graduation", Confidence: 0.6},
+		{SignalType: "calendar_event", Value: "graduation", Confidence: 0.5},
+	})
+
+	assert.Equal(t, map[lifeosapi.EventType]float64{lifeosapi.EventTypeGraduation: 0.6 * 1.1}, probabilities)
+}
+
+// Test Timeline Generation
+
+func timelineServices(bookBy time.Time) []lifeosapi.PlannedService {
+	return []lifeosapi.PlannedService{
+		{CategoryID: uuid.New(), CategoryName: "Venue", Priority: lifeosapi.PriorityCritical, BookByDate: bookBy},
+		{CategoryID: uuid.New(), CategoryName: "Photography", Priority: lifeosapi.PriorityHigh, BookByDate: bookBy},
+	}
+}
+
+func timelinePhase(t *testing.T, phases []lifeosapi.PhasePlan, phase lifeosapi.EventPhase) lifeosapi.PhasePlan {
+	t.Helper()
+	for _, p := range phases {
+		if p.Phase == phase {
+			return p
+		}
+	}
+	t.Fatalf("phase %s missing from timeline", phase)
+	return lifeosapi.PhasePlan{}
+}
+
+func TestGenerateTimeline_FixedDate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	eventDate := now.AddDate(0, 6, 0)
+	event := &lifeosapi.LifeEvent{ID: uuid.New(), EventType: lifeosapi.EventTypeWedding, EventDate: &eventDate, EventDateFlex: lifeosapi.DateFixed}
+
+	phases, milestones := (&lifeosapi.OrchestrationEngine{}).GenerateTimeline(event, timelineServices(eventDate.AddDate(0, 0, -90)), now)
+
+	booking := timelinePhase(t, phases, lifeosapi.PhaseBooking)
+	assert.Equal(t, eventDate.AddDate(0, -2, 0), booking.StartDate)
+	assert.Equal(t, eventDate.AddDate(0, -1, 0), booking.EndDate)
+	assert.False(t, booking.IsEstimated)
+	require.Len(t, milestones, 1)
+	assert.False(t, milestones[0].IsEstimated)
+}
+
+func TestGenerateTimeline_FlexibleDateWidensWindows(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	eventDate := now.AddDate(0, 6, 0)
+	event := &lifeosapi.LifeEvent{ID: uuid.New(), EventType: lifeosapi.EventTypeWedding, EventDate: &eventDate, EventDateFlex: lifeosapi.DateFlexible}
+	services := timelineServices(eventDate.AddDate(0, 0, -90))
+
+	engine := &lifeosapi.OrchestrationEngine{}
+	phases, _ := engine.GenerateTimeline(event, services, now)
+	booking := timelinePhase(t, phases, lifeosapi.PhaseBooking)
+	window := eventDate.AddDate(0, -1, 0).Sub(eventDate.AddDate(0, -2, 0))
+	margin := time.Duration(float64(window) * lifeosapi.DefaultFlexibleWindowPct / 200)
+	assert.Equal(t, eventDate.AddDate(0, -2, 0).Add(-margin), booking.StartDate)
+	assert.Equal(t, eventDate.AddDate(0, -1, 0).Add(margin), booking.EndDate)
+	assert.Equal(t, now, timelinePhase(t, phases, lifeosapi.PhaseDiscovery).StartDate, "windows never start in the past")
+	assert.Equal(t, eventDate, timelinePhase(t, phases, lifeosapi.PhaseEventDay).StartDate)
+
+	engine.SetFlexibleWindowPercent(50)
+	phases, _ = engine.GenerateTimeline(event, services, now)
+	booking = timelinePhase(t, phases, lifeosapi.PhaseBooking)
+	assert.Equal(t, window*3/2, booking.EndDate.Sub(booking.StartDate))
+}
+
+func TestGenerateTimeline_OpenDateAnchorsOnPlanningHorizon(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	event := &lifeosapi.LifeEvent{ID: uuid.New(), EventType: lifeosapi.EventTypeWedding, EventDateFlex: lifeosapi.DateOpen, PlanningHorizon: 200}
+
+	anchor, estimated := lifeosapi.TimelineAnchor(event, now)
+	require.True(t, estimated)
+	assert.Equal(t, now.AddDate(0, 0, 200), anchor)
+
+	phases, milestones := (&lifeosapi.OrchestrationEngine{}).GenerateTimeline(event, timelineServices(anchor.AddDate(0, 0, -90)), now)
+
+	require.Len(t, phases, 7)
+	for _, phase := range phases {
+		assert.True(t, phase.IsEstimated, phase.Phase)
+	}
+	assert.Equal(t, anchor, timelinePhase(t, phases, lifeosapi.PhaseEventDay).StartDate)
+	require.Len(t, milestones, 1)
+	assert.True(t, milestones[0].IsEstimated)
+	assert.Equal(t, anchor.AddDate(0, 0, -90), milestones[0].Date)
+}
+
+func TestTimelineAnchor_OpenDateFallsBackToEventTypeDefault(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	funeral, estimated := lifeosapi.TimelineAnchor(&lifeosapi.LifeEvent{EventType: lifeosapi.EventTypeFuneral, EventDateFlex: lifeosapi.DateOpen}, now)
+	assert.True(t, estimated)
+	wedding, _ := lifeosapi.TimelineAnchor(&lifeosapi.LifeEvent{EventType: lifeosapi.EventTypeWedding, EventDateFlex: lifeosapi.DateOpen}, now)
+	assert.True(t, funeral.Before(wedding))
+	assert.True(t, funeral.After(now))
+}
+
+// Test Plan Caching
+
+func newCachedOrchestrationEngine(t *testing.T) (*lifeosapi.OrchestrationEngine, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return lifeosapi.NewOrchestrationEngine(nil, client), mr
+}
+
+func TestGeneratePlan_SecondCallServedFromCache(t *testing.T) {
+	engine, mr := newCachedOrchestrationEngine(t)
+	ctx := context.Background()
+	eventDate := time.Now().AddDate(0, 6, 0)
+	event := &lifeosapi.LifeEvent{ID: uuid.New(), EventType: lifeosapi.EventTypeBirthday, EventDate: &eventDate, UpdatedAt: time.Now()}
+
+	first, err := engine.GeneratePlan(ctx, event, lifeosapi.PlanOptions{})
+	require.NoError(t, err)
+	assert.True(t, mr.Exists(fmt.Sprintf("lifeos:plan:%s:%d", event.ID, event.UpdatedAt.Unix())))
+
+	second, err := engine.GeneratePlan(ctx, event, lifeosapi.PlanOptions{})
+	require.NoError(t, err)
+	assert.True(t, first.GeneratedAt.Equal(second.GeneratedAt), "second call should return the cached plan")
+
+	refreshed, err := engine.GeneratePlan(ctx, event, lifeosapi.PlanOptions{ForceRefresh: true})
+	require.NoError(t, err)
+	assert.True(t, refreshed.GeneratedAt.After(first.GeneratedAt))
+}
+
+func TestGeneratePlan_UpdateBustsCache(t *testing.T) {
+	engine, mr := newCachedOrchestrationEngine(t)
+	ctx := context.Background()
+	eventDate := time.Now().AddDate(0, 6, 0)
+	event := &lifeosapi.LifeEvent{ID: uuid.New(), EventType: lifeosapi.EventTypeBirthday, EventDate: &eventDate, UpdatedAt: time.Now().Add(-time.Hour)}
+	other := &lifeosapi.LifeEvent{ID: uuid.New(), EventType: lifeosapi.EventTypeBirthday, EventDate: &eventDate, UpdatedAt: time.Now()}
+
+	first, err := engine.GeneratePlan(ctx, event, lifeosapi.PlanOptions{})
+	require.NoError(t, err)
+	_, err = engine.GeneratePlan(ctx, other, lifeosapi.PlanOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, engine.InvalidatePlans(ctx, event.ID))
+	assert.Len(t, mr.Keys(), 1, "only the other event's plan remains")
+
+	event.UpdatedAt = time.Now()
+	updated, err := engine.GeneratePlan(ctx, event, lifeosapi.PlanOptions{})
+	require.NoError(t, err)
+	assert.True(t, updated.GeneratedAt.After(first.GeneratedAt))
+	assert.Len(t, mr.Keys(), 2)
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/auth"
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/middleware"
+)
+
+// bearerAuth stands in for the JWT auth middleware in handler tests; the
+// token built by bearerToken carries the caller's role and user ID
+func bearerAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, id, found := strings.Cut(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "), ":")
+		userID, err := uuid.Parse(id)
+		if !found || err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+		c.Set("user_id", userID)
+		c.Set("user_role", auth.UserRole(role))
+		c.Next()
+	}
+}
+
+func bearerToken(userID uuid.UUID, role auth.UserRole) string {
+	return "Bearer " + string(role) + ":" + userID.String()
+}
+
+func newUUIDParamRouter(handled *bool, got *uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/conversations/:id", middleware.UUIDParams("id"), func(c *gin.Context) {
+		*handled = true
+		*got = middleware.ParamUUID(c, "id")
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+// Test UUID Path Params
+
+func TestUUIDParams_ValidIDReachesHandler(t *testing.T) {
+	var handled bool
+	var got uuid.UUID
+	router := newUUIDParamRouter(&handled, &got)
+
+	id := uuid.New()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/conversations/"+id.String(), nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, handled)
+	assert.Equal(t, id, got)
+}
+
+func TestUUIDParams_MalformedIDRejected(t *testing.T) {
+	for _, raw := range []string{"not-a-uuid", "123", "00000000-0000-0000-0000-00000000000g"} {
+		var handled bool
+		var got uuid.UUID
+		router := newUUIDParamRouter(&handled, &got)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/conversations/"+raw, nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, raw)
+		assert.False(t, handled, raw)
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "Invalid ID", body["error"])
+		assert.Contains(t, body["message"], "'id'")
+	}
+}
+
+func TestParamUUID_UnvalidatedParamIsNil(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	assert.Equal(t, uuid.Nil, middleware.ParamUUID(c, "id"))
+}
+
+func TestUserKeyFunc_NonUUIDUserFallsBackToIP(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.RemoteAddr = "10.0.0.7:4312"
+	c.Set("user_id", "not-a-uuid")
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, "10.0.0.7", middleware.UserKeyFunc(c))
+	})
+}
+
+// Test Require Auth
+
+func TestRequireAuth_WithoutMiddlewareRejectsHeaderIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var handled bool
+	router := gin.New()
+	router.GET("/private", middleware.RequireAuth(nil), func(c *gin.Context) {
+		handled = true
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set("X-User-ID", uuid.New().String())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, handled)
+}
+
+func TestRequireAuth_RunsWiredMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	var got uuid.UUID
+	router := gin.New()
+	router.GET("/private", middleware.RequireAuth(bearerAuth()), func(c *gin.Context) {
+		got, _ = auth.GetUserFromContext(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set("Authorization", bearerToken(userID, auth.RoleCustomer))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, got)
+}
@@ -0,0 +1,172 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/notification"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Notification Deduplication
+
+func TestNotificationDedupe_IdenticalCallsSendOnce(t *testing.T) {
+	dedupe := notification.NewDeduplicator(nil, time.Minute)
+	key := notification.DedupeKey(uuid.New(), notification.TypePaymentReceived, "txn_123")
+
+	sent := 0
+	for i := 0; i < 2; i++ {
+		if dedupe.Claim(context.Background(), key) {
+			sent++
+		}
+	}
+
+	assert.Equal(t, 1, sent)
+}
+
+func TestNotificationDedupe_DistinctKeysBothSend(t *testing.T) {
+	dedupe := notification.NewDeduplicator(nil, time.Minute)
+	userID := uuid.New()
+
+	assert.True(t, dedupe.Claim(context.Background(), notification.DedupeKey(userID, notification.TypePaymentReceived, "txn_1")))
+	assert.True(t, dedupe.Claim(context.Background(), notification.DedupeKey(userID, notification.TypePaymentReceived, "txn_2")))
+	assert.True(t, dedupe.Claim(context.Background(), notification.DedupeKey(userID, notification.TypeBookingCreated, "txn_1")))
+	assert.True(t, dedupe.Claim(context.Background(), notification.DedupeKey(uuid.New(), notification.TypePaymentReceived, "txn_1")))
+}
+
+func TestNotificationDedupe_ExpiresAfterTTL(t *testing.T) {
+	dedupe := notification.NewDeduplicator(nil, 20*time.Millisecond)
+	key := notification.DedupeKey(uuid.New(), notification.TypeTechArrived, "emergency_1")
+
+	assert.True(t, dedupe.Claim(context.Background(), key))
+	assert.False(t, dedupe.Claim(context.Background(), key))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, dedupe.Claim(context.Background(), key))
+}
+
+func TestNotificationDedupe_ReleasedClaimCanBeRetried(t *testing.T) {
+	dedupe := notification.NewDeduplicator(nil, time.Minute)
+	key := notification.DedupeKey(uuid.New(), notification.TypePaymentReceived, "txn_123")
+
+	assert.True(t, dedupe.Claim(context.Background(), key))
+	dedupe.Release(context.Background(), key)
+
+	assert.True(t, dedupe.Claim(context.Background(), key))
+	assert.False(t, dedupe.Claim(context.Background(), key))
+}
+
+// Test Notification Retries
+
+type recordingDeadLetters struct {
+	letters []notification.DeadLetter
+}
+
+func (r *recordingDeadLetters) DeadLetter(ctx context.Context, letter notification.DeadLetter) error {
+	r.letters = append(r.letters, letter)
+	return nil
+}
+
+var fastRetries = notification.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func newSMSNotification() *notification.Notification {
+	return &notification.Notification{
+		ID:      uuid.New(),
+		UserID:  uuid.New(),
+		Type:    notification.TypeTechEnRoute,
+		Channel: notification.ChannelSMS,
+		Status:  notification.StatusQueued,
+	}
+}
+
+func TestNotificationDispatch_TransientFailureRetriesAndSucceeds(t *testing.T) {
+	deadLetters := &recordingDeadLetters{}
+	dispatcher := notification.NewDispatcher(fastRetries, deadLetters)
+	calls := 0
+	dispatcher.Register(notification.ChannelSMS, func(ctx context.Context, n *notification.Notification) error {
+		calls++
+		if calls < 3 {
+			return errors.New("provider timeout")
+		}
+		return nil
+	})
+
+	n := newSMSNotification()
+	require.NoError(t, dispatcher.Dispatch(context.Background(), n))
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, notification.StatusSent, n.Status)
+	assert.NotNil(t, n.SentAt)
+	assert.Empty(t, deadLetters.letters)
+}
+
+func TestNotificationDispatch_PermanentFailureDeadLetters(t *testing.T) {
+	deadLetters := &recordingDeadLetters{}
+	dispatcher := notification.NewDispatcher(fastRetries, deadLetters)
+	calls := 0
+	dispatcher.Register(notification.ChannelSMS, func(ctx context.Context, n *notification.Notification) error {
+		calls++
+		return notification.Permanent(errors.New("no phone number found"))
+	})
+
+	n := newSMSNotification()
+	err := dispatcher.Dispatch(context.Background(), n)
+	assert.True(t, notification.IsPermanent(err))
+	assert.Equal(t, 1, calls, "permanent failures are not retried")
+	assert.Equal(t, notification.StatusFailed, n.Status)
+
+	require.Len(t, deadLetters.letters, 1)
+	letter := deadLetters.letters[0]
+	assert.Equal(t, n.ID, letter.NotificationID)
+	assert.Equal(t, notification.ChannelSMS, letter.Channel)
+	assert.Equal(t, 1, letter.Attempts)
+	assert.True(t, letter.Permanent)
+	assert.Equal(t, "no phone number found", letter.LastError)
+}
+
+func TestNotificationDispatch_ExhaustedRetriesDeadLetter(t *testing.T) {
+	deadLetters := &recordingDeadLetters{}
+	dispatcher := notification.NewDispatcher(fastRetries, deadLetters)
+	calls := 0
+	dispatcher.Register(notification.ChannelSMS, func(ctx context.Context, n *notification.Notification) error {
+		calls++
+		return errors.New("provider unavailable")
+	})
+
+	err := dispatcher.Dispatch(context.Background(), newSMSNotification())
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+	require.Len(t, deadLetters.letters, 1)
+	assert.Equal(t, 3, deadLetters.letters[0].Attempts)
+	assert.False(t, deadLetters.letters[0].Permanent)
+}
+
+func TestNotificationRetryPolicy_BackoffDoublesUpToCap(t *testing.T) {
+	policy := notification.RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1, nil))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2, nil))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(3, nil))
+
+	throttled := &notification.RateLimitedError{Err: errors.New("slow down"), RetryAfter: 250 * time.Millisecond}
+	assert.Equal(t, 250*time.Millisecond, policy.Backoff(1, throttled))
+}
+
+func TestNotificationProviderStatusError_Classification(t *testing.T) {
+	status := func(code int, header http.Header) *http.Response {
+		return &http.Response{StatusCode: code, Header: header}
+	}
+
+	assert.True(t, notification.IsPermanent(notification.ProviderStatusError("SMS", status(http.StatusBadRequest, nil))))
+	assert.False(t, notification.IsPermanent(notification.ProviderStatusError("SMS", status(http.StatusBadGateway, nil))))
+	assert.False(t, notification.IsPermanent(notification.ProviderStatusError("SMS", status(http.StatusRequestTimeout, nil))))
+
+	var limited *notification.RateLimitedError
+	err := notification.ProviderStatusError("SMS", status(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"2"}}))
+	require.ErrorAs(t, err, &limited)
+	assert.Equal(t, 2*time.Second, limited.RetryAfter)
+}
@@ -0,0 +1,269 @@
+package unit
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/payment"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Escrow Release
+
+// memoryEscrowStore holds escrows, vendor balances, the releases and their
+// ledger postings in process
+type memoryEscrowStore struct {
+	mu       sync.Mutex
+	escrows  map[uuid.UUID]*payment.EscrowAccount // by booking
+	balances map[string]int64                     // by user and currency
+	ledger   map[uuid.UUID]payment.EscrowRelease  // by escrow
+	postings []payment.LedgerEntry
+}
+
+func newMemoryEscrowStore() *memoryEscrowStore {
+	return &memoryEscrowStore{
+		escrows:  make(map[uuid.UUID]*payment.EscrowAccount),
+		balances: make(map[string]int64),
+		ledger:   make(map[uuid.UUID]payment.EscrowRelease),
+	}
+}
+
+func (m *memoryEscrowStore) AddEscrow(escrow payment.EscrowAccount) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.escrows[escrow.BookingID] = &escrow
+}
+
+func (m *memoryEscrowStore) Escrow(bookingID uuid.UUID) (payment.EscrowAccount, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	escrow, ok := m.escrows[bookingID]
+	if !ok {
+		return payment.EscrowAccount{}, false
+	}
+	return *escrow, true
+}
+
+func (m *memoryEscrowStore) Balance(userID uuid.UUID, currency string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.balances[userID.String()+"/"+currency]
+}
+
+func (m *memoryEscrowStore) Ledger() []payment.EscrowRelease {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	releases := make([]payment.EscrowRelease, 0, len(m.ledger))
+	for _, release := range m.ledger {
+		releases = append(releases, release)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].ReleasedAt.Before(releases[j].ReleasedAt)
+	})
+	return releases
+}
+
+func (m *memoryEscrowStore) Postings() []payment.LedgerEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]payment.LedgerEntry(nil), m.postings...)
+}
+
+func (m *memoryEscrowStore) ReleaseEscrow(ctx context.Context, bookingID uuid.UUID, by payment.EscrowReleaser, feeRate payment.FeeRate, now time.Time) (*payment.EscrowRelease, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	escrow, ok := m.escrows[bookingID]
+	if !ok {
+		return nil, false, payment.ErrEscrowNotFound
+	}
+	if !by.CanRelease(escrow) {
+		return nil, false, payment.ErrEscrowForbidden
+	}
+	if release, ok := m.ledger[escrow.ID]; ok {
+		return &release, false, nil
+	}
+	if err := payment.CheckEscrowReleasable(escrow); err != nil {
+		return nil, false, err
+	}
+	feePercent, err := feeRate(ctx, escrow.VendorID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	release := payment.NewEscrowRelease(escrow, feePercent, by.UserID, now)
+	if _, err := payment.CheckLedgerPosting(release.LedgerEntries()); err != nil {
+		return nil, false, err
+	}
+	m.postings = append(m.postings, release.LedgerEntries()...)
+	m.ledger[escrow.ID] = *release
+	m.balances[escrow.VendorID.String()+"/"+escrow.Currency] += release.Payout
+	escrow.Status = payment.EscrowReleased
+	escrow.ReleasedAt = &now
+	return release, true, nil
+}
+
+func (m *memoryEscrowStore) ExpiredEscrows(ctx context.Context, cutoff time.Time) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expired []*payment.EscrowAccount
+	for _, escrow := range m.escrows {
+		if escrow.Status == payment.EscrowHeld && escrow.DisputeID == nil && escrow.CreatedAt.Before(cutoff) {
+			expired = append(expired, escrow)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].CreatedAt.Before(expired[j].CreatedAt)
+	})
+
+	bookingIDs := make([]uuid.UUID, 0, len(expired))
+	for _, escrow := range expired {
+		bookingIDs = append(bookingIDs, escrow.BookingID)
+	}
+	return bookingIDs, nil
+}
+
+func newEscrowService() (*payment.Service, *memoryEscrowStore) {
+	service := payment.NewService(nil, nil, &payment.Config{
+		PlatformFeePercent: 10,
+		EscrowExpiryDays:   30,
+	})
+	store := newMemoryEscrowStore()
+	service.SetEscrowStore(store)
+	return service, store
+}
+
+func customerReleaser(escrow payment.EscrowAccount) payment.EscrowReleaser {
+	return payment.EscrowReleaser{UserID: escrow.CustomerID}
+}
+
+func heldEscrow(age time.Duration) payment.EscrowAccount {
+	return payment.EscrowAccount{
+		ID:            uuid.New(),
+		TransactionID: uuid.New(),
+		BookingID:     uuid.New(),
+		CustomerID:    uuid.New(),
+		VendorID:      uuid.New(),
+		Amount:        5000000,
+		Currency:      "NGN",
+		Status:        payment.EscrowHeld,
+		CreatedAt:     time.Now().Add(-age),
+	}
+}
+
+func TestReleaseEscrow_PaysVendorLessPlatformFee(t *testing.T) {
+	service, store := newEscrowService()
+	escrow := heldEscrow(time.Hour)
+	store.AddEscrow(escrow)
+	admin := uuid.New()
+
+	release, err := service.ReleaseEscrow(context.Background(), escrow.BookingID,
+		payment.EscrowReleaser{UserID: admin, Admin: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(500000), release.Fee)
+	assert.Equal(t, int64(4500000), release.Payout)
+	assert.Equal(t, admin, release.ReleasedBy)
+	assert.Equal(t, int64(4500000), store.Balance(escrow.VendorID, "NGN"))
+	released, _ := store.Escrow(escrow.BookingID)
+	assert.Equal(t, payment.EscrowReleased, released.Status)
+	require.Len(t, store.Ledger(), 1)
+	assert.Equal(t, release.Reference, store.Ledger()[0].Reference)
+}
+
+func TestReleaseEscrow_IsIdempotent(t *testing.T) {
+	service, store := newEscrowService()
+	escrow := heldEscrow(time.Hour)
+	store.AddEscrow(escrow)
+	ctx := context.Background()
+
+	first, err := service.ReleaseEscrow(ctx, escrow.BookingID, customerReleaser(escrow))
+	require.NoError(t, err)
+	again, err := service.ReleaseEscrow(ctx, escrow.BookingID, customerReleaser(escrow))
+	require.NoError(t, err)
+
+	assert.Equal(t, first, again)
+	assert.Equal(t, int64(4500000), store.Balance(escrow.VendorID, "NGN"))
+	assert.Len(t, store.Ledger(), 1)
+}
+
+func TestReleaseEscrow_Disputed(t *testing.T) {
+	service, store := newEscrowService()
+	escrow := heldEscrow(time.Hour)
+	disputeID := uuid.New()
+	escrow.DisputeID = &disputeID
+	store.AddEscrow(escrow)
+
+	_, err := service.ReleaseEscrow(context.Background(), escrow.BookingID, customerReleaser(escrow))
+
+	assert.ErrorIs(t, err, payment.ErrEscrowDisputed)
+	assert.Zero(t, store.Balance(escrow.VendorID, "NGN"))
+}
+
+func TestReleaseEscrow_OnlyCustomerOrAdmin(t *testing.T) {
+	service, store := newEscrowService()
+	escrow := heldEscrow(time.Hour)
+	store.AddEscrow(escrow)
+	ctx := context.Background()
+
+	for name, by := range map[string]payment.EscrowReleaser{
+		"vendor":    {UserID: escrow.VendorID},
+		"stranger":  {UserID: uuid.New()},
+		"anonymous": {},
+	} {
+		_, err := service.ReleaseEscrow(ctx, escrow.BookingID, by)
+		assert.ErrorIs(t, err, payment.ErrEscrowForbidden, name)
+	}
+	assert.Zero(t, store.Balance(escrow.VendorID, "NGN"))
+	held, _ := store.Escrow(escrow.BookingID)
+	assert.Equal(t, payment.EscrowHeld, held.Status)
+
+	_, err := service.ReleaseEscrow(ctx, escrow.BookingID, customerReleaser(escrow))
+	require.NoError(t, err)
+
+	// The earlier release is not disclosed to someone who could not make it
+	_, err = service.ReleaseEscrow(ctx, escrow.BookingID, payment.EscrowReleaser{UserID: uuid.New()})
+	assert.ErrorIs(t, err, payment.ErrEscrowForbidden)
+}
+
+func TestSweepExpiredEscrows(t *testing.T) {
+	service, store := newEscrowService()
+	ctx := context.Background()
+
+	expired := heldEscrow(31 * 24 * time.Hour)
+	recent := heldEscrow(5 * 24 * time.Hour)
+	disputed := heldEscrow(45 * 24 * time.Hour)
+	disputeID := uuid.New()
+	disputed.DisputeID = &disputeID
+	disputedStatus := heldEscrow(40 * 24 * time.Hour)
+	disputedStatus.Status = payment.EscrowDisputed
+	for _, escrow := range []payment.EscrowAccount{expired, recent, disputed, disputedStatus} {
+		store.AddEscrow(escrow)
+	}
+
+	released, err := service.SweepExpiredEscrows(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+
+	assert.Equal(t, int64(4500000), store.Balance(expired.VendorID, "NGN"))
+	ledger := store.Ledger()
+	require.Len(t, ledger, 1)
+	assert.Equal(t, expired.BookingID, ledger[0].BookingID)
+	assert.Equal(t, payment.AutoReleasedBy, ledger[0].ReleasedBy)
+	for _, escrow := range []payment.EscrowAccount{recent, disputed, disputedStatus} {
+		still, _ := store.Escrow(escrow.BookingID)
+		assert.Equal(t, escrow.Status, still.Status)
+		assert.Zero(t, store.Balance(escrow.VendorID, "NGN"))
+	}
+
+	// A second sweep finds nothing left to release
+	released, err = service.SweepExpiredEscrows(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, released)
+}
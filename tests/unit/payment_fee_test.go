@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/payment"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Platform Fees
+
+// memoryVendorTierStore holds vendors' subscription tiers in process
+type memoryVendorTierStore struct {
+	mu    sync.Mutex
+	tiers map[uuid.UUID]string
+}
+
+func newMemoryVendorTierStore() *memoryVendorTierStore {
+	return &memoryVendorTierStore{tiers: make(map[uuid.UUID]string)}
+}
+
+func (m *memoryVendorTierStore) SetTier(vendorID uuid.UUID, tier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tiers[vendorID] = tier
+}
+
+func (m *memoryVendorTierStore) VendorTier(ctx context.Context, vendorID uuid.UUID) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tiers[vendorID], nil
+}
+
+func newFeeService() (*payment.Service, *memoryVendorTierStore) {
+	service := payment.NewService(nil, nil, &payment.Config{PlatformFeePercent: 10})
+	tiers := newMemoryVendorTierStore()
+	service.SetVendorTierStore(tiers)
+	return service, tiers
+}
+
+func TestCalculatePlatformFee_ByTier(t *testing.T) {
+	service, tiers := newFeeService()
+	ctx := context.Background()
+
+	tests := []struct {
+		tier    string
+		wantFee float64
+		wantPct float64
+	}{
+		{"free", 15000, 15},
+		{"basic", 12000, 12},
+		{"pro", 10000, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tier, func(t *testing.T) {
+			vendorID := uuid.New()
+			tiers.SetTier(vendorID, tt.tier)
+
+			fee, rate, err := service.CalculatePlatformFee(ctx, vendorID, 100000)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFee, fee)
+			assert.Equal(t, tt.wantPct, rate)
+		})
+	}
+}
+
+func TestCalculatePlatformFee_DefaultsToConfig(t *testing.T) {
+	service, tiers := newFeeService()
+	ctx := context.Background()
+	enterprise := uuid.New()
+	tiers.SetTier(enterprise, "enterprise")
+
+	for name, vendorID := range map[string]uuid.UUID{
+		"no tier":       uuid.New(),
+		"unpriced tier": enterprise,
+		"no vendor":     uuid.Nil,
+	} {
+		fee, rate, err := service.CalculatePlatformFee(ctx, vendorID, 100000)
+
+		require.NoError(t, err, name)
+		assert.Equal(t, 10000.0, fee, name)
+		assert.Equal(t, 10.0, rate, name)
+	}
+}
+
+func TestCalculatePlatformFee_ConfiguredTierRates(t *testing.T) {
+	service := payment.NewService(nil, nil, &payment.Config{
+		PlatformFeePercent: 10,
+		TierFeePercents:    map[string]float64{"pro": 8},
+	})
+	tiers := newMemoryVendorTierStore()
+	service.SetVendorTierStore(tiers)
+	vendorID := uuid.New()
+	tiers.SetTier(vendorID, "pro")
+
+	fee, rate, err := service.CalculatePlatformFee(context.Background(), vendorID, 100000)
+
+	require.NoError(t, err)
+	assert.Equal(t, 8000.0, fee)
+	assert.Equal(t, 8.0, rate)
+}
+
+func TestReleaseEscrow_ChargesVendorTierFee(t *testing.T) {
+	service, tiers := newFeeService()
+	escrows := newMemoryEscrowStore()
+	service.SetEscrowStore(escrows)
+	escrow := heldEscrow(time.Hour)
+	escrows.AddEscrow(escrow)
+	tiers.SetTier(escrow.VendorID, "free")
+
+	release, err := service.ReleaseEscrow(context.Background(), escrow.BookingID, customerReleaser(escrow))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(750000), release.Fee)
+	assert.Equal(t, int64(4250000), release.Payout)
+}
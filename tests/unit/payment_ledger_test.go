@@ -0,0 +1,226 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/payment"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Ledger
+
+func ledgerEntry(transactionID, account string, direction payment.EntryDirection, amount int64) payment.LedgerEntry {
+	return payment.LedgerEntry{
+		TransactionID: transactionID,
+		Account:       account,
+		Direction:     direction,
+		Amount:        amount,
+		Currency:      "NGN",
+	}
+}
+
+// ledgerBalance returns an account's debits less its credits in entries
+func ledgerBalance(entries []payment.LedgerEntry, account string) int64 {
+	var balance int64
+	for _, entry := range entries {
+		if entry.Account != account {
+			continue
+		}
+		if entry.Direction == payment.Debit {
+			balance += entry.Amount
+		} else {
+			balance -= entry.Amount
+		}
+	}
+	return balance
+}
+
+// memoryLedgerDB stands in for ledger_entries, keeping its unique
+// (transaction_id, line) constraint
+type memoryLedgerDB struct {
+	mu    sync.Mutex
+	lines map[string]bool
+	rows  int
+}
+
+func newMemoryLedgerDB() *memoryLedgerDB {
+	return &memoryLedgerDB{lines: make(map[string]bool)}
+}
+
+func (m *memoryLedgerDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := fmt.Sprintf("%v/%v", args[1], args[2])
+	if m.lines[key] {
+		return pgconn.NewCommandTag("INSERT 0 0"), nil
+	}
+	m.lines[key] = true
+	m.rows++
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func TestCheckLedgerPosting_RejectsUnbalancedPosting(t *testing.T) {
+	_, err := payment.CheckLedgerPosting([]payment.LedgerEntry{
+		ledgerEntry("txn-1", "provider:paystack", payment.Debit, 5000000),
+		ledgerEntry("txn-1", "escrow:booking", payment.Credit, 4999999),
+	})
+	assert.ErrorIs(t, err, payment.ErrUnbalancedPosting)
+
+	// Balanced overall, but not within each transaction
+	_, err = payment.CheckLedgerPosting([]payment.LedgerEntry{
+		ledgerEntry("txn-2", "provider:paystack", payment.Debit, 100),
+		ledgerEntry("txn-3", "escrow:booking", payment.Credit, 100),
+	})
+	assert.ErrorIs(t, err, payment.ErrUnbalancedPosting)
+}
+
+func TestCheckLedgerPosting_RejectsInvalidEntries(t *testing.T) {
+	_, err := payment.CheckLedgerPosting([]payment.LedgerEntry{
+		ledgerEntry("txn-1", "provider:paystack", payment.Debit, 0),
+		ledgerEntry("txn-1", "escrow:booking", payment.Credit, 0),
+	})
+
+	assert.ErrorIs(t, err, payment.ErrInvalidLedgerEntry)
+}
+
+func TestCheckLedgerPosting_GroupsByTransaction(t *testing.T) {
+	transactions, err := payment.CheckLedgerPosting([]payment.LedgerEntry{
+		ledgerEntry("txn-1", "provider:paystack", payment.Debit, 5000000),
+		ledgerEntry("txn-2", "escrow:booking", payment.Debit, 100),
+		ledgerEntry("txn-1", "escrow:booking", payment.Credit, 5000000),
+		ledgerEntry("txn-2", "platform:fees", payment.Credit, 100),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "txn-1", transactions[0][0].TransactionID)
+	assert.Len(t, transactions[0], 2)
+	assert.Len(t, transactions[1], 2)
+}
+
+func TestReleaseEscrow_PostsBalancedEntriesWithTheRelease(t *testing.T) {
+	service, store := newEscrowService()
+	escrow := heldEscrow(time.Hour)
+	store.AddEscrow(escrow)
+	ctx := context.Background()
+
+	_, err := service.ReleaseEscrow(ctx, escrow.BookingID, customerReleaser(escrow))
+	require.NoError(t, err)
+	_, err = service.ReleaseEscrow(ctx, escrow.BookingID, customerReleaser(escrow))
+	require.NoError(t, err)
+
+	postings := store.Postings()
+	assert.Len(t, postings, 3)
+	assert.Equal(t, int64(5000000), ledgerBalance(postings, payment.EscrowLedgerAccount(escrow.BookingID)))
+	assert.Equal(t, int64(-4500000), ledgerBalance(postings, payment.WalletAccount(escrow.VendorID)))
+	assert.Equal(t, int64(-500000), ledgerBalance(postings, payment.PlatformFeesAccount))
+}
+
+func TestRefundPayment_PostsBalancedEntriesWithTheRefund(t *testing.T) {
+	service, store, _, _ := newRefundService()
+	txn := capturedPayment()
+	store.AddPayment(txn)
+
+	_, err := service.RefundPayment(context.Background(), txn.ID, 20000, "Partial service")
+
+	require.NoError(t, err)
+	postings := store.Postings()
+	_, err = payment.CheckLedgerPosting(postings)
+	require.NoError(t, err)
+	assert.Len(t, postings, 2)
+	assert.Equal(t, int64(2000000), ledgerBalance(postings, payment.PlatformRefundsAccount))
+	assert.Equal(t, int64(-2000000), ledgerBalance(postings, payment.ProviderAccount(payment.ProviderPaystack)))
+}
+
+func TestRefund_InternalRefundsPostToTheWallet(t *testing.T) {
+	userID := uuid.New()
+	refund := payment.Refund{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Provider: payment.ProviderInternal,
+		Amount:   150000,
+		Currency: "NGN",
+		Reason:   "Cancelled",
+	}
+
+	entries := refund.LedgerEntries(0)
+
+	_, err := payment.CheckLedgerPosting(entries)
+	require.NoError(t, err)
+	assert.Equal(t, int64(150000), ledgerBalance(entries, payment.PlatformRefundsAccount))
+	assert.Equal(t, int64(-150000), ledgerBalance(entries, payment.WalletAccount(userID)))
+}
+
+func TestLedgerPost_SkipsATransactionPostedAgain(t *testing.T) {
+	db := newMemoryLedgerDB()
+	ledger := payment.NewLedger(db)
+	entries := []payment.LedgerEntry{
+		ledgerEntry("escrow_hold:txn-1", "provider:paystack", payment.Debit, 5000000),
+		ledgerEntry("escrow_hold:txn-1", "escrow:booking", payment.Credit, 5000000),
+	}
+
+	require.NoError(t, ledger.Post(context.Background(), entries))
+	require.NoError(t, ledger.Post(context.Background(), entries))
+	assert.Equal(t, 2, db.rows)
+
+	// A new transaction alongside the repeated one is still posted
+	require.NoError(t, ledger.Post(context.Background(), append(entries,
+		ledgerEntry("escrow_hold:txn-2", "provider:paystack", payment.Debit, 100),
+		ledgerEntry("escrow_hold:txn-2", "escrow:booking", payment.Credit, 100),
+	)))
+	assert.Equal(t, 4, db.rows)
+}
+
+func TestLedgerPost_RejectsUnbalancedPostingWithoutWriting(t *testing.T) {
+	db := newMemoryLedgerDB()
+
+	err := payment.NewLedger(db).Post(context.Background(), []payment.LedgerEntry{
+		ledgerEntry("txn-1", "provider:paystack", payment.Debit, 100),
+		ledgerEntry("txn-1", "escrow:booking", payment.Credit, 99),
+	})
+
+	assert.ErrorIs(t, err, payment.ErrUnbalancedPosting)
+	assert.Zero(t, db.rows)
+}
+
+func TestTransaction_CaptureMovesThePaymentToTheProvider(t *testing.T) {
+	txn := capturedPayment()
+
+	entries := txn.LedgerEntries()
+
+	_, err := payment.CheckLedgerPosting(entries)
+	require.NoError(t, err)
+	assert.Equal(t, "capture:"+txn.ID.String(), entries[0].TransactionID)
+	assert.Equal(t, int64(5000000), ledgerBalance(entries, payment.CustomerAccount(txn.UserID)))
+	assert.Equal(t, int64(-5000000), ledgerBalance(entries, payment.ProviderAccount(payment.ProviderPaystack)))
+}
+
+func TestRefundBooking_PostsTheRefundOutOfTheBookingsEscrow(t *testing.T) {
+	refunds, store, _, _ := newRefundService()
+	_, escrows := newEscrowService()
+	store.escrows = escrows
+	escrow := heldEscrow(time.Hour)
+	escrow.Amount = 3000000
+	escrows.AddEscrow(escrow)
+	paid := capturedPayment()
+	paid.BookingID = &escrow.BookingID
+	store.AddPayment(paid)
+
+	_, err := refunds.RefundBooking(context.Background(), escrow.BookingID, uuid.New(), 40000, "Cancelled")
+
+	require.NoError(t, err)
+	postings := store.Postings()
+	_, err = payment.CheckLedgerPosting(postings)
+	require.NoError(t, err)
+	// The escrow holds less than the refund; the platform covers the rest
+	assert.Equal(t, int64(3000000), ledgerBalance(postings, payment.EscrowLedgerAccount(escrow.BookingID)))
+	assert.Equal(t, int64(1000000), ledgerBalance(postings, payment.PlatformRefundsAccount))
+	assert.Equal(t, int64(-4000000), ledgerBalance(postings, payment.ProviderAccount(payment.ProviderPaystack)))
+}
@@ -0,0 +1,485 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/payment"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Refunds
+
+// memoryRefundStore holds payments and their refunds in process
+type memoryRefundStore struct {
+	mu         sync.Mutex
+	payments   map[uuid.UUID]*payment.Transaction
+	refunds    map[uuid.UUID][]payment.Refund // by payment
+	finishErrs []error                        // returned by the next FinishRefund calls
+	postings   []payment.LedgerEntry
+	escrows    *memoryEscrowStore // completed refunds come out of these, if set
+}
+
+func newMemoryRefundStore() *memoryRefundStore {
+	return &memoryRefundStore{
+		payments: make(map[uuid.UUID]*payment.Transaction),
+		refunds:  make(map[uuid.UUID][]payment.Refund),
+	}
+}
+
+func (m *memoryRefundStore) AddPayment(txn payment.Transaction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payments[txn.ID] = &txn
+}
+
+func (m *memoryRefundStore) Payment(paymentID uuid.UUID) (payment.Transaction, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	txn, ok := m.payments[paymentID]
+	if !ok {
+		return payment.Transaction{}, false
+	}
+	return *txn, true
+}
+
+func (m *memoryRefundStore) Refunds(paymentID uuid.UUID) []payment.Refund {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]payment.Refund(nil), m.refunds[paymentID]...)
+}
+
+// Backdate moves every refund's creation back by d
+func (m *memoryRefundStore) Backdate(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, refunds := range m.refunds {
+		for i := range refunds {
+			refunds[i].CreatedAt = refunds[i].CreatedAt.Add(-d)
+		}
+	}
+}
+
+func (m *memoryRefundStore) Postings() []payment.LedgerEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]payment.LedgerEntry(nil), m.postings...)
+}
+
+func (m *memoryRefundStore) ReserveRefund(ctx context.Context, refund *payment.Refund) (*payment.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	txn, ok := m.payments[refund.PaymentID]
+	if !ok {
+		return nil, payment.ErrPaymentNotFound
+	}
+	copied := *txn
+	retried := -1
+	var refunded int64
+	for i, existing := range m.refunds[txn.ID] {
+		if existing.ID == refund.ID {
+			if existing.Status != payment.RefundFailed {
+				*refund = existing
+				return &copied, payment.ErrRefundExists
+			}
+			retried = i
+		}
+		if existing.Status != payment.RefundFailed {
+			refunded += existing.Amount
+		}
+	}
+	if err := payment.CheckRefundable(txn, refunded, refund.Amount); err != nil {
+		return nil, err
+	}
+
+	refund.UserID = txn.UserID
+	refund.BookingID = txn.BookingID
+	refund.Provider = txn.Provider
+	refund.Currency = txn.Currency
+	if retried >= 0 {
+		m.refunds[txn.ID][retried] = *refund
+	} else {
+		m.refunds[txn.ID] = append(m.refunds[txn.ID], *refund)
+	}
+	return &copied, nil
+}
+
+func (m *memoryRefundStore) FinishRefund(ctx context.Context, refund *payment.Refund) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.finishErrs) > 0 {
+		err := m.finishErrs[0]
+		m.finishErrs = m.finishErrs[1:]
+		return err
+	}
+	var completed int64
+	refunds := m.refunds[refund.PaymentID]
+	for i := range refunds {
+		if refunds[i].ID == refund.ID && refunds[i].Status == payment.RefundPending {
+			refunds[i] = *refund
+			if refund.Status == payment.RefundCompleted {
+				fromEscrow := m.applyToEscrow(refund)
+				m.postings = append(m.postings, refund.LedgerEntries(fromEscrow)...)
+			}
+		}
+		if refunds[i].Status == payment.RefundCompleted {
+			completed += refunds[i].Amount
+		}
+	}
+	if txn, ok := m.payments[refund.PaymentID]; ok && completed >= txn.Amount {
+		txn.Status = payment.StatusRefunded
+	}
+	return nil
+}
+
+// applyToEscrow returns how much of the refund came out of escrow
+func (m *memoryRefundStore) applyToEscrow(refund *payment.Refund) int64 {
+	if m.escrows == nil || refund.BookingID == nil {
+		return 0
+	}
+	m.escrows.mu.Lock()
+	defer m.escrows.mu.Unlock()
+	escrow, ok := m.escrows.escrows[*refund.BookingID]
+	if !ok {
+		return 0
+	}
+	held := escrow.Amount
+	if !escrow.ApplyRefund(refund.Amount) {
+		return 0
+	}
+	return held - escrow.Amount
+}
+
+func (m *memoryRefundStore) PendingRefunds(ctx context.Context, cutoff time.Time) ([]payment.PendingRefund, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pending []payment.PendingRefund
+	for paymentID, refunds := range m.refunds {
+		for _, refund := range refunds {
+			if refund.Status == payment.RefundPending && refund.CreatedAt.Before(cutoff) {
+				pending = append(pending, payment.PendingRefund{Refund: refund, Payment: *m.payments[paymentID]})
+			}
+		}
+	}
+	return pending, nil
+}
+
+func (m *memoryRefundStore) BookingPayment(ctx context.Context, bookingID uuid.UUID) (uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latest *payment.Transaction
+	for _, txn := range m.payments {
+		if txn.BookingID == nil || *txn.BookingID != bookingID || txn.Type != payment.TypePayment {
+			continue
+		}
+		if txn.Status != payment.StatusSuccess && txn.Status != payment.StatusRefunded {
+			continue
+		}
+		if latest == nil || txn.CreatedAt.After(latest.CreatedAt) {
+			latest = txn
+		}
+	}
+	if latest == nil {
+		return uuid.Nil, payment.ErrPaymentNotFound
+	}
+	return latest.ID, nil
+}
+
+func (m *memoryRefundStore) EmergencyPayment(ctx context.Context, emergencyID uuid.UUID) (uuid.UUID, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latest *payment.Transaction
+	for _, txn := range m.payments {
+		if txn.Metadata["emergency_id"] != emergencyID.String() || txn.Type != payment.TypePayment {
+			continue
+		}
+		if txn.Status != payment.StatusSuccess && txn.Status != payment.StatusRefunded {
+			continue
+		}
+		if latest == nil || txn.CreatedAt.After(latest.CreatedAt) {
+			latest = txn
+		}
+	}
+	if latest == nil {
+		return uuid.Nil, 0, payment.ErrPaymentNotFound
+	}
+	remaining := latest.Amount
+	for _, refund := range m.refunds[latest.ID] {
+		if refund.Status != payment.RefundFailed {
+			remaining -= refund.Amount
+		}
+	}
+	return latest.ID, remaining, nil
+}
+
+// recordingRefundGateway makes every refund unless err is set, and finds
+// the refunds it made by refund ID
+type recordingRefundGateway struct {
+	calls []int64
+	made  map[uuid.UUID]string
+	err   error
+}
+
+func (r *recordingRefundGateway) RefundWithProvider(ctx context.Context, txn *payment.Transaction, refund *payment.Refund) (string, error) {
+	r.calls = append(r.calls, refund.Amount)
+	if r.err != nil {
+		return "", r.err
+	}
+	if r.made == nil {
+		r.made = make(map[uuid.UUID]string)
+	}
+	r.made[refund.ID] = "RF-" + txn.Reference
+	return r.made[refund.ID], nil
+}
+
+func (r *recordingRefundGateway) FindRefund(ctx context.Context, txn *payment.Transaction, refund *payment.Refund) (string, bool, error) {
+	providerRef, ok := r.made[refund.ID]
+	return providerRef, ok, nil
+}
+
+type recordingRefundNotifier struct {
+	refunds []payment.Refund
+}
+
+func (r *recordingRefundNotifier) NotifyRefund(ctx context.Context, refund *payment.Refund) error {
+	r.refunds = append(r.refunds, *refund)
+	return nil
+}
+
+func newRefundService() (*payment.Service, *memoryRefundStore, *recordingRefundGateway, *recordingRefundNotifier) {
+	service := payment.NewService(nil, nil, &payment.Config{})
+	store := newMemoryRefundStore()
+	gateway := &recordingRefundGateway{}
+	notifier := &recordingRefundNotifier{}
+	service.SetRefundStore(store)
+	service.SetRefundGateway(gateway)
+	service.SetRefundNotifier(notifier)
+	return service, store, gateway, notifier
+}
+
+func capturedPayment() payment.Transaction {
+	return payment.Transaction{
+		ID:        uuid.New(),
+		Reference: "VP-1A2B3C",
+		UserID:    uuid.New(),
+		Type:      payment.TypePayment,
+		Status:    payment.StatusSuccess,
+		Provider:  payment.ProviderPaystack,
+		Amount:    5000000,
+		Currency:  "NGN",
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestRefundPayment_Full(t *testing.T) {
+	service, store, gateway, notifier := newRefundService()
+	txn := capturedPayment()
+	store.AddPayment(txn)
+
+	refund, err := service.RefundPayment(context.Background(), txn.ID, 50000, "Vendor cancelled")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000000), refund.Amount)
+	assert.Equal(t, payment.RefundCompleted, refund.Status)
+	assert.Equal(t, txn.UserID, refund.UserID)
+	assert.Equal(t, payment.ProviderPaystack, refund.Provider)
+	assert.Equal(t, "RF-VP-1A2B3C", refund.ProviderRef)
+	assert.Equal(t, []int64{5000000}, gateway.calls)
+	require.Len(t, notifier.refunds, 1)
+	assert.Equal(t, refund.ID, notifier.refunds[0].ID)
+
+	updated, _ := store.Payment(txn.ID)
+	assert.Equal(t, payment.StatusRefunded, updated.Status)
+}
+
+func TestRefundPayment_PartialRefundsUpToTotal(t *testing.T) {
+	service, store, gateway, _ := newRefundService()
+	txn := capturedPayment()
+	store.AddPayment(txn)
+	ctx := context.Background()
+
+	_, err := service.RefundPayment(ctx, txn.ID, 20000, "Partial service")
+	require.NoError(t, err)
+	partial, _ := store.Payment(txn.ID)
+	assert.Equal(t, payment.StatusSuccess, partial.Status)
+
+	_, err = service.RefundPayment(ctx, txn.ID, 30000, "Remainder")
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{2000000, 3000000}, gateway.calls)
+	assert.Len(t, store.Refunds(txn.ID), 2)
+	refunded, _ := store.Payment(txn.ID)
+	assert.Equal(t, payment.StatusRefunded, refunded.Status)
+}
+
+func TestRefundPayment_RejectsOverRefund(t *testing.T) {
+	service, store, gateway, notifier := newRefundService()
+	txn := capturedPayment()
+	store.AddPayment(txn)
+	ctx := context.Background()
+
+	_, err := service.RefundPayment(ctx, txn.ID, 40000, "Partial service")
+	require.NoError(t, err)
+
+	_, err = service.RefundPayment(ctx, txn.ID, 10000.01, "Too much")
+
+	assert.ErrorIs(t, err, payment.ErrRefundExceedsPayment)
+	assert.Equal(t, []int64{4000000}, gateway.calls)
+	assert.Len(t, notifier.refunds, 1)
+	assert.Len(t, store.Refunds(txn.ID), 1)
+}
+
+func TestRefundPayment_ProviderFailureReleasesTheReservation(t *testing.T) {
+	service, store, gateway, notifier := newRefundService()
+	txn := capturedPayment()
+	store.AddPayment(txn)
+	ctx := context.Background()
+
+	gateway.err = fmt.Errorf("%w: transaction has been fully reversed", payment.ErrRefundRejected)
+	_, err := service.RefundPayment(ctx, txn.ID, 50000, "Vendor cancelled")
+	require.Error(t, err)
+	require.Len(t, store.Refunds(txn.ID), 1)
+	assert.Equal(t, payment.RefundFailed, store.Refunds(txn.ID)[0].Status)
+	assert.Empty(t, notifier.refunds)
+
+	// The failed attempt no longer counts against the payment
+	gateway.err = nil
+	_, err = service.RefundPayment(ctx, txn.ID, 50000, "Vendor cancelled")
+	require.NoError(t, err)
+}
+
+func TestRefundPayment_UnknownOutcomeStaysPending(t *testing.T) {
+	service, store, gateway, _ := newRefundService()
+	txn := capturedPayment()
+	store.AddPayment(txn)
+	ctx := context.Background()
+
+	// The provider made the refund, but the response never arrived
+	gateway.err = errors.New("context deadline exceeded")
+	_, err := service.RefundPayment(ctx, txn.ID, 50000, "Vendor cancelled")
+	assert.ErrorIs(t, err, payment.ErrRefundPending)
+	require.Len(t, store.Refunds(txn.ID), 1)
+	assert.Equal(t, payment.RefundPending, store.Refunds(txn.ID)[0].Status)
+
+	// Still pending, so it counts against the payment
+	gateway.err = nil
+	_, err = service.RefundPayment(ctx, txn.ID, 50000, "Vendor cancelled")
+	assert.ErrorIs(t, err, payment.ErrRefundExceedsPayment)
+	assert.Len(t, gateway.calls, 1)
+}
+
+func TestRefundPayment_TakesTheRefundOutOfTheBookingsEscrow(t *testing.T) {
+	service, store, _, _ := newRefundService()
+	escrowService, escrows := newEscrowService()
+	store.escrows = escrows
+	escrow := heldEscrow(31 * 24 * time.Hour)
+	escrows.AddEscrow(escrow)
+	txn := capturedPayment()
+	txn.BookingID = &escrow.BookingID
+	store.AddPayment(txn)
+	ctx := context.Background()
+
+	_, err := service.RefundPayment(ctx, txn.ID, 20000, "Partial service")
+	require.NoError(t, err)
+	partial, _ := escrows.Escrow(escrow.BookingID)
+	assert.Equal(t, int64(3000000), partial.Amount)
+	assert.Equal(t, payment.EscrowHeld, partial.Status)
+
+	_, err = service.RefundPayment(ctx, txn.ID, 30000, "Remainder")
+	require.NoError(t, err)
+	refunded, _ := escrows.Escrow(escrow.BookingID)
+	assert.Equal(t, payment.EscrowRefunded, refunded.Status)
+
+	// Nothing is left to pay the vendor
+	released, err := escrowService.SweepExpiredEscrows(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, released)
+	assert.Zero(t, escrows.Balance(escrow.VendorID, "NGN"))
+}
+
+func TestRefundPayment_UnrecordedRefundIsReturnedAndReconciled(t *testing.T) {
+	service, store, _, notifier := newRefundService()
+	txn := capturedPayment()
+	store.AddPayment(txn)
+	ctx := context.Background()
+
+	store.finishErrs = []error{errors.New("database unavailable")}
+	refund, err := service.RefundPayment(ctx, txn.ID, 50000, "Vendor cancelled")
+
+	require.Error(t, err)
+	require.NotNil(t, refund)
+	assert.Equal(t, "RF-VP-1A2B3C", refund.ProviderRef)
+	assert.Equal(t, payment.RefundPending, store.Refunds(txn.ID)[0].Status)
+	assert.Empty(t, notifier.refunds)
+
+	// Too recent for the request that made it to have given up
+	settled, err := service.ReconcilePendingRefunds(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, settled)
+
+	store.Backdate(time.Hour)
+	settled, err = service.ReconcilePendingRefunds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, settled)
+
+	reconciled := store.Refunds(txn.ID)[0]
+	assert.Equal(t, payment.RefundCompleted, reconciled.Status)
+	assert.Equal(t, "RF-VP-1A2B3C", reconciled.ProviderRef)
+	require.Len(t, notifier.refunds, 1)
+	refunded, _ := store.Payment(txn.ID)
+	assert.Equal(t, payment.StatusRefunded, refunded.Status)
+}
+
+func TestReconcilePendingRefunds_FailsRefundsNeverMade(t *testing.T) {
+	service, store, gateway, notifier := newRefundService()
+	txn := capturedPayment()
+	store.AddPayment(txn)
+	ctx := context.Background()
+
+	// The request failed without an answer, so the refund stays pending
+	gateway.err = errors.New("connection reset")
+	_, err := service.RefundPayment(ctx, txn.ID, 50000, "Vendor cancelled")
+	require.ErrorIs(t, err, payment.ErrRefundPending)
+	assert.Equal(t, payment.RefundPending, store.Refunds(txn.ID)[0].Status)
+
+	store.Backdate(time.Hour)
+	settled, err := service.ReconcilePendingRefunds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, settled)
+	assert.Equal(t, payment.RefundFailed, store.Refunds(txn.ID)[0].Status)
+	assert.Empty(t, notifier.refunds)
+}
+
+func TestRefundEmergency_RefundsWhatIsLeftOfTheEmergencysPayment(t *testing.T) {
+	service, store, gateway, _ := newRefundService()
+	emergencyID := uuid.New()
+	paid := capturedPayment()
+	paid.Metadata = map[string]interface{}{"emergency_id": emergencyID.String()}
+	store.AddPayment(paid)
+	store.AddPayment(capturedPayment())
+	ctx := context.Background()
+
+	_, err := service.RefundPayment(ctx, paid.ID, 20000, "Partial service")
+	require.NoError(t, err)
+	refund, err := service.RefundEmergency(ctx, emergencyID, "Arrival SLA missed")
+
+	require.NoError(t, err)
+	assert.Equal(t, paid.ID, refund.PaymentID)
+	assert.Equal(t, []int64{2000000, 3000000}, gateway.calls)
+
+	_, err = service.RefundEmergency(ctx, emergencyID, "Arrival SLA missed")
+	assert.ErrorIs(t, err, payment.ErrPaymentNotRefundable)
+	_, err = service.RefundEmergency(ctx, uuid.New(), "Arrival SLA missed")
+	assert.ErrorIs(t, err, payment.ErrPaymentNotFound)
+}
@@ -0,0 +1,166 @@
+package unit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/payment"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Payment Webhooks
+
+const (
+	testPaystackSecret  = "sk_test_paystack"
+	testFlutterwaveHash = "whsec_test"
+)
+
+// memoryWebhookEventStore records processed events in process, forgetting an
+// event whose apply fails as the database transaction would
+type memoryWebhookEventStore struct {
+	mu     sync.Mutex
+	events map[string]payment.WebhookEvent
+}
+
+func (m *memoryWebhookEventStore) ProcessEvent(ctx context.Context, event payment.WebhookEvent, apply func(tx pgx.Tx) error) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := string(event.Provider) + "/" + event.ID
+	if _, ok := m.events[key]; ok {
+		return false, nil
+	}
+	if err := apply(nil); err != nil {
+		return false, err
+	}
+	m.events[key] = event
+	return true, nil
+}
+
+type recordingWebhookApplier struct {
+	events []payment.WebhookEvent
+	err    error
+}
+
+func (r *recordingWebhookApplier) ApplyWebhookEvent(ctx context.Context, tx pgx.Tx, event payment.WebhookEvent) error {
+	r.events = append(r.events, event)
+	return r.err
+}
+
+func newWebhookService() (*payment.Service, *recordingWebhookApplier) {
+	service := payment.NewService(nil, nil, &payment.Config{
+		PaystackSecretKey: testPaystackSecret,
+		WebhookSecret:     testFlutterwaveHash,
+	})
+	service.SetWebhookEventStore(&memoryWebhookEventStore{events: make(map[string]payment.WebhookEvent)})
+	applier := &recordingWebhookApplier{}
+	service.SetWebhookEventApplier(applier)
+	return service, applier
+}
+
+func paystackSignature(payload []byte) string {
+	return paystackSignatureWith(testPaystackSecret, payload)
+}
+
+func paystackSignatureWith(secret string, payload []byte) string {
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var paystackChargeSuccess = []byte(`{"event":"charge.success","data":{"id":302961,"reference":"VP-1A2B3C","status":"success","amount":5000000}}`)
+
+func TestHandleWebhook_AppliesNewEvent(t *testing.T) {
+	service, applier := newWebhookService()
+
+	result, err := service.HandleWebhook(context.Background(), payment.ProviderPaystack,
+		paystackChargeSuccess, paystackSignature(paystackChargeSuccess))
+
+	require.NoError(t, err)
+	assert.False(t, result.Duplicate)
+	require.Len(t, applier.events, 1)
+	assert.Equal(t, payment.WebhookEvent{
+		Provider:  payment.ProviderPaystack,
+		ID:        "charge.success:302961",
+		Name:      "charge.success",
+		Type:      payment.WebhookChargeSuccess,
+		Reference: "VP-1A2B3C",
+		Amount:    5000000,
+	}, applier.events[0])
+}
+
+func TestHandleWebhook_ReplayedEventIsNotAppliedTwice(t *testing.T) {
+	service, applier := newWebhookService()
+	ctx := context.Background()
+	signature := paystackSignature(paystackChargeSuccess)
+
+	_, err := service.HandleWebhook(ctx, payment.ProviderPaystack, paystackChargeSuccess, signature)
+	require.NoError(t, err)
+	result, err := service.HandleWebhook(ctx, payment.ProviderPaystack, paystackChargeSuccess, signature)
+
+	require.NoError(t, err)
+	assert.True(t, result.Duplicate)
+	assert.Equal(t, "charge.success:302961", result.Event.ID)
+	assert.Len(t, applier.events, 1)
+}
+
+func TestHandleWebhook_InvalidSignature(t *testing.T) {
+	service, applier := newWebhookService()
+	ctx := context.Background()
+
+	for name, signature := range map[string]string{
+		"missing":      "",
+		"wrong secret": paystackSignatureWith("other", paystackChargeSuccess),
+		"webhook hash": paystackSignatureWith(testFlutterwaveHash, paystackChargeSuccess),
+		"tampered":     paystackSignature([]byte(`{"event":"charge.success","data":{"id":302961,"amount":1}}`)),
+	} {
+		_, err := service.HandleWebhook(ctx, payment.ProviderPaystack, paystackChargeSuccess, signature)
+		assert.ErrorIs(t, err, payment.ErrInvalidSignature, name)
+	}
+	assert.Empty(t, applier.events)
+}
+
+func TestHandleWebhook_FailedApplyIsRetried(t *testing.T) {
+	service, applier := newWebhookService()
+	ctx := context.Background()
+	signature := paystackSignature(paystackChargeSuccess)
+
+	applier.err = errors.New("database unavailable")
+	_, err := service.HandleWebhook(ctx, payment.ProviderPaystack, paystackChargeSuccess, signature)
+	require.Error(t, err)
+
+	applier.err = nil
+	result, err := service.HandleWebhook(ctx, payment.ProviderPaystack, paystackChargeSuccess, signature)
+	require.NoError(t, err)
+	assert.False(t, result.Duplicate)
+	assert.Len(t, applier.events, 2)
+}
+
+func TestHandleWebhook_Flutterwave(t *testing.T) {
+	service, applier := newWebhookService()
+	payload := []byte(`{"event":"charge.completed","data":{"id":285959875,"tx_ref":"VP-9Z8Y7X","status":"successful","amount":50000}}`)
+	mac := hmac.New(sha256.New, []byte(testFlutterwaveHash))
+	mac.Write(payload)
+
+	result, err := service.HandleWebhook(context.Background(), payment.ProviderFlutterwave,
+		payload, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	require.NoError(t, err)
+	assert.Equal(t, payment.WebhookChargeSuccess, result.Event.Type)
+	assert.Equal(t, "VP-9Z8Y7X", result.Event.Reference)
+	assert.Equal(t, int64(5000000), result.Event.Amount)
+	assert.Len(t, applier.events, 1)
+
+	header, ok := payment.WebhookSignatureHeader(payment.ProviderFlutterwave)
+	assert.True(t, ok)
+	assert.Equal(t, "Flutterwave-Signature", header)
+}
@@ -0,0 +1,55 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/BillyRonksGlobal/vendorplatform/pkg/phone"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Phone Normalization
+
+func TestPhoneNormalize_LocalFormats(t *testing.T) {
+	cases := map[string]string{
+		"08012345678":       "+2348012345678",
+		"0801 234 5678":     "+2348012345678",
+		"0801-234-5678":     "+2348012345678",
+		"8012345678":        "+2348012345678",
+		"2348012345678":     "+2348012345678",
+		"+234 801 234 5678": "+2348012345678",
+		"002348012345678":   "+2348012345678",
+		"(0803) 555.1234":   "+2348035551234",
+		"+44 20 7946 0958":  "+442079460958",
+	}
+
+	for input, want := range cases {
+		got, err := phone.Normalize(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+}
+
+func TestPhoneNormalize_RejectsJunk(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"   ",
+		"not a number",
+		"0801234567a",
+		"12345",
+		"0801234567",        // one digit short
+		"080123456789",      // one digit long
+		"+0123456789",       // country codes never start with 0
+		"+1234567890123456", // longer than E.164 allows
+		"call me",
+	} {
+		_, err := phone.Normalize(input)
+		assert.ErrorIs(t, err, phone.ErrInvalidPhone, input)
+	}
+}
+
+func TestPhoneNormalizeWithCountry(t *testing.T) {
+	got, err := phone.NormalizeWithCountry("0244 123 456", "233")
+	require.NoError(t, err)
+	assert.Equal(t, "+233244123456", got)
+}
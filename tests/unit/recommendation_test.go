@@ -0,0 +1,1184 @@
+// =============================================================================
+// RECOMMENDATION ENGINE TESTS
+// Unit tests for recommendation scoring and explanations
+// =============================================================================
+
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	recommendation "github.com/BillyRonksGlobal/vendorplatform/recommendation-engine"
+)
+
+// =============================================================================
+// EXPLANATION TESTS
+// =============================================================================
+
+func TestRecommendationReasons_AdjacentServiceComplementary(t *testing.T) {
+	scorer := recommendation.NewScorer(recommendation.DefaultConfig())
+	cateringID := uuid.New()
+
+	candidate := recommendation.Candidate{
+		EntityType: recommendation.EntityService,
+		EntityID:   uuid.New(),
+		CategoryID: uuid.New(),
+		Source:     recommendation.AdjacentService,
+		BaseScore:  0.8,
+		Metadata: map[string]any{
+			"source_category":      cateringID,
+			"source_category_name": "Catering",
+			"source_booked":        false,
+		},
+	}
+
+	recs := scorer.ScoreAll(context.Background(), []recommendation.Candidate{candidate},
+		&recommendation.RecommendationRequest{}, &recommendation.UserContext{})
+	require.Len(t, recs, 1)
+	assert.Contains(t, recs[0].Reasons, "Complementary to Catering")
+}
+
+func TestRecommendationReasons_AdjacentServiceBooked(t *testing.T) {
+	scorer := recommendation.NewScorer(recommendation.DefaultConfig())
+
+	candidate := recommendation.Candidate{
+		EntityType: recommendation.EntityService,
+		EntityID:   uuid.New(),
+		Source:     recommendation.AdjacentService,
+		Metadata: map[string]any{
+			"source_category_name": "caterer",
+			"source_booked":        true,
+		},
+	}
+
+	reasons := scorer.BuildReasons(candidate, &recommendation.RecommendationRequest{}, &recommendation.UserContext{})
+	assert.Equal(t, []string{"Pairs well with your booked caterer"}, reasons)
+}
+
+func TestRecommendationReasons_EventBasedNearby(t *testing.T) {
+	scorer := recommendation.NewScorer(recommendation.DefaultConfig())
+
+	candidate := recommendation.Candidate{
+		EntityType: recommendation.EntityService,
+		EntityID:   uuid.New(),
+		Source:     recommendation.EventBasedSuggest,
+		Metadata: map[string]any{
+			"event_type":      "wedding",
+			"necessity_score": 0.5,
+		},
+	}
+	req := &recommendation.RecommendationRequest{
+		Location: &recommendation.GeoPoint{Latitude: 6.5244, Longitude: 3.3792},
+	}
+
+	reasons := scorer.BuildReasons(candidate, req, &recommendation.UserContext{})
+	require.NotEmpty(t, reasons)
+	assert.Equal(t, "Popular for weddings near you", reasons[0])
+}
+
+func TestRecommendationReasons_FallbackNeverEmpty(t *testing.T) {
+	scorer := recommendation.NewScorer(recommendation.DefaultConfig())
+
+	candidate := recommendation.Candidate{
+		EntityID: uuid.New(),
+		Source:   recommendation.PersonalizedPick,
+	}
+
+	reasons := scorer.BuildReasons(candidate, nil, nil)
+	require.Len(t, reasons, 1)
+	assert.False(t, strings.TrimSpace(reasons[0]) == "")
+}
+
+// =============================================================================
+// BATCH TESTS
+// =============================================================================
+
+func TestRunBatch_MixedValidAndInvalid(t *testing.T) {
+	fetch := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		return &recommendation.RecommendationResponse{
+			Recommendations: []recommendation.Recommendation{{ID: uuid.New(), Type: recommendation.AdjacentService}},
+			TotalCandidates: 1,
+		}, nil
+	}
+
+	reqs := []*recommendation.RecommendationRequest{
+		{Limit: 5, EventType: "wedding"},
+		{Limit: 500},
+	}
+
+	results, err := recommendation.RunBatch(context.Background(), reqs, fetch)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, 0, results[0].Index)
+	assert.Empty(t, results[0].Error)
+	require.NotNil(t, results[0].Response)
+	assert.Len(t, results[0].Response.Recommendations, 1)
+
+	assert.Equal(t, 1, results[1].Index)
+	assert.Nil(t, results[1].Response)
+	assert.Contains(t, results[1].Error, "limit: must be between 1 and 100")
+}
+
+func TestRunBatch_SharedDeadline(t *testing.T) {
+	fetch := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		if req.EventType == "slow" {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			return nil, ctx.Err()
+		}
+		return &recommendation.RecommendationResponse{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	results, err := recommendation.RunBatch(ctx, []*recommendation.RecommendationRequest{
+		{Limit: 5},
+		{Limit: 5, EventType: "slow"},
+	}, fetch)
+	require.NoError(t, err)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, context.DeadlineExceeded.Error(), results[1].Error)
+}
+
+func TestRunBatch_RejectsEmptyAndOversized(t *testing.T) {
+	fetch := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		return &recommendation.RecommendationResponse{}, nil
+	}
+
+	_, err := recommendation.RunBatch(context.Background(), nil, fetch)
+	assert.Error(t, err)
+
+	oversized := make([]*recommendation.RecommendationRequest, recommendation.MaxBatchSize+1)
+	for i := range oversized {
+		oversized[i] = &recommendation.RecommendationRequest{Limit: 1}
+	}
+	_, err = recommendation.RunBatch(context.Background(), oversized, fetch)
+	assert.Error(t, err)
+}
+
+// =============================================================================
+// TRACING TESTS
+// =============================================================================
+
+func spanAttr(span tracetest.SpanStub, key string) attribute.Value {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestRecommendationTracing_RequestEmitsSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+	otel.SetTracerProvider(provider)
+
+	scorer := recommendation.NewScorer(recommendation.DefaultConfig())
+	candidates := []recommendation.Candidate{
+		{EntityType: recommendation.EntityService, EntityID: uuid.New(), Source: recommendation.PersonalizedPick, BaseScore: 0.6},
+		{EntityType: recommendation.EntityService, EntityID: uuid.New(), Source: recommendation.PersonalizedPick, BaseScore: 0.4},
+	}
+	fetch := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		recs := scorer.ScoreAll(ctx, candidates, req, &recommendation.UserContext{})
+		return &recommendation.RecommendationResponse{Recommendations: recs}, nil
+	}
+
+	req := &recommendation.RecommendationRequest{UserID: uuid.New(), Limit: 5}
+	_, err := recommendation.RunBatch(context.Background(), []*recommendation.RecommendationRequest{req}, fetch)
+	require.NoError(t, err)
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+
+	batch, ok := spans["recommendation.batch"]
+	require.True(t, ok, "batch span not emitted")
+	assert.Equal(t, int64(1), spanAttr(batch, "batch.size").AsInt64())
+	assert.Equal(t, int64(0), spanAttr(batch, "batch.failed").AsInt64())
+
+	score, ok := spans["recommendation.score"]
+	require.True(t, ok, "score span not emitted")
+	assert.Equal(t, int64(2), spanAttr(score, "candidates.count").AsInt64())
+	assert.Equal(t, batch.SpanContext.TraceID(), score.SpanContext.TraceID())
+	assert.Equal(t, batch.SpanContext.SpanID(), score.Parent.SpanID())
+}
+
+func TestRecommendationTracing_FailedRequestRecordsError(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+	otel.SetTracerProvider(provider)
+
+	engine := &recommendation.Engine{}
+	_, err := engine.GetRecommendations(context.Background(), &recommendation.RecommendationRequest{Limit: 500})
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "recommendation.GetRecommendations", spans[0].Name)
+	assert.Equal(t, "Error", spans[0].Status.Code.String())
+	assert.NotEmpty(t, spans[0].Events, "error should be recorded on the span")
+}
+
+// =============================================================================
+// SIGNAL BLENDING TESTS
+// =============================================================================
+
+// blendDataset seeds a user who booked a wedding photographer, plus two
+// candidates: a DJ that the same crowd books (collaborative) and a second
+// photographer with matching attributes that nobody has co-booked (content).
+func blendDataset() (*recommendation.BlendSignals, []recommendation.Recommendation, uuid.UUID, uuid.UUID) {
+	photographyCat, musicCat := uuid.New(), uuid.New()
+	booked, dj, photographer := uuid.New(), uuid.New(), uuid.New()
+
+	var interactions []recommendation.Interaction
+	for i := 0; i < 8; i++ {
+		user := uuid.New()
+		interactions = append(interactions,
+			recommendation.Interaction{UserID: user, EntityID: booked},
+			recommendation.Interaction{UserID: user, EntityID: dj},
+		)
+	}
+	interactions = append(interactions, recommendation.Interaction{UserID: uuid.New(), EntityID: photographer})
+
+	signals := &recommendation.BlendSignals{
+		History:      []uuid.UUID{booked},
+		Cooccurrence: recommendation.NewCooccurrenceIndex(interactions),
+		Features: map[uuid.UUID]recommendation.ItemFeatures{
+			booked:       {CategoryID: photographyCat, Attributes: []string{"wedding", "drone", "same-day edit"}},
+			photographer: {CategoryID: photographyCat, Attributes: []string{"wedding", "drone", "album"}},
+			dj:           {CategoryID: musicCat, Attributes: []string{"afrobeats", "mc"}},
+		},
+	}
+	recs := []recommendation.Recommendation{
+		{EntityType: recommendation.EntityService, EntityID: dj, Score: 0.5},
+		{EntityType: recommendation.EntityService, EntityID: photographer, Score: 0.5},
+	}
+	return signals, recs, dj, photographer
+}
+
+func rankBlended(signals *recommendation.BlendSignals, recs []recommendation.Recommendation, weights recommendation.BlendWeights) []recommendation.Recommendation {
+	blended := recommendation.BlendScores(append([]recommendation.Recommendation{}, recs...), signals, weights)
+	return recommendation.NewRanker(recommendation.DefaultConfig()).Rank(blended)
+}
+
+func TestBlendScores_SignalsComputed(t *testing.T) {
+	signals, recs, dj, photographer := blendDataset()
+	blended := recommendation.BlendScores(recs, signals, recommendation.BlendWeights{Base: 1, Collaborative: 1, Content: 1})
+
+	byID := map[uuid.UUID]recommendation.Recommendation{}
+	for _, rec := range blended {
+		byID[rec.EntityID] = rec
+	}
+	assert.InDelta(t, 1.0, byID[dj].CollaborativeScore, 0.001)
+	assert.Zero(t, byID[dj].ContentScore)
+	assert.Zero(t, byID[photographer].CollaborativeScore)
+	assert.InDelta(t, 0.5+0.5*2.0/4.0, byID[photographer].ContentScore, 0.001)
+}
+
+func TestBlendScores_WeightShiftsOrdering(t *testing.T) {
+	signals, recs, dj, photographer := blendDataset()
+
+	collaborative := rankBlended(signals, recs, recommendation.BlendWeights{Base: 0.2, Collaborative: 0.8})
+	assert.Equal(t, dj, collaborative[0].EntityID, "collaborative-heavy blend should favour the co-booked DJ")
+
+	content := rankBlended(signals, recs, recommendation.BlendWeights{Base: 0.2, Content: 0.8})
+	assert.Equal(t, photographer, content[0].EntityID, "content-heavy blend should favour the similar photographer")
+
+	// Sweeping from content to collaborative flips the order exactly once
+	flips, prev := 0, photographer
+	for w := 0.0; w <= 1.0001; w += 0.1 {
+		ranked := rankBlended(signals, recs, recommendation.BlendWeights{Collaborative: w, Content: 1 - w})
+		if ranked[0].EntityID != prev {
+			flips++
+			prev = ranked[0].EntityID
+		}
+	}
+	assert.Equal(t, 1, flips)
+	assert.Equal(t, dj, prev)
+}
+
+func TestBlendScores_NoHistoryKeepsScores(t *testing.T) {
+	signals, recs, _, _ := blendDataset()
+	signals.History = nil
+
+	blended := recommendation.BlendScores(recs, signals, recommendation.BlendWeights{Collaborative: 1})
+	assert.Equal(t, 0.5, blended[0].Score)
+	assert.Equal(t, 0.5, blended[1].Score)
+}
+
+func TestBlendWeights_Validation(t *testing.T) {
+	assert.NoError(t, recommendation.ValidateRequest(&recommendation.RecommendationRequest{
+		Blend: &recommendation.BlendWeights{Content: 1},
+	}))
+	assert.Error(t, recommendation.ValidateRequest(&recommendation.RecommendationRequest{
+		Blend: &recommendation.BlendWeights{},
+	}))
+	assert.Error(t, recommendation.ValidateRequest(&recommendation.RecommendationRequest{
+		Blend: &recommendation.BlendWeights{Base: 1, Content: -0.5},
+	}))
+}
+
+// Test Freshness Decay
+
+func TestApplyFreshness_EqualRatingsOrderByRecency(t *testing.T) {
+	now := time.Now()
+	stale, recent := uuid.New(), uuid.New()
+	recs := []recommendation.Recommendation{
+		{EntityType: recommendation.EntityService, EntityID: stale, Score: 0.8},
+		{EntityType: recommendation.EntityService, EntityID: recent, Score: 0.8},
+	}
+	lastActivity := map[uuid.UUID]time.Time{
+		stale:  now.AddDate(-2, 0, 0),
+		recent: now.AddDate(0, 0, -3),
+	}
+
+	decay := recommendation.DefaultConfig().Freshness
+	freshened := recommendation.ApplyFreshness(recs, lastActivity, decay, now)
+	ranked := recommendation.NewRanker(recommendation.DefaultConfig()).Rank(freshened)
+
+	assert.Equal(t, recent, ranked[0].EntityID)
+	assert.Greater(t, ranked[0].FreshnessFactor, 1.0)
+	assert.Less(t, ranked[1].FreshnessFactor, 1.0)
+	assert.GreaterOrEqual(t, ranked[1].FreshnessFactor, decay.Floor)
+}
+
+func TestApplyFreshness_DisabledKeepsScores(t *testing.T) {
+	now := time.Now()
+	id := uuid.New()
+	recs := []recommendation.Recommendation{{EntityID: id, Score: 0.8}}
+
+	freshened := recommendation.ApplyFreshness(recs, map[uuid.UUID]time.Time{id: now.AddDate(-3, 0, 0)},
+		recommendation.FreshnessDecay{}, now)
+
+	assert.Equal(t, 0.8, freshened[0].Score)
+}
+
+func TestFreshnessDecay_Factor(t *testing.T) {
+	now := time.Now()
+	decay := recommendation.FreshnessDecay{Enabled: true, BoostWindowDays: 30, MaxBoost: 0.1, HalfLifeDays: 100, Floor: 0.2}
+
+	assert.InDelta(t, 1.1, decay.Factor(now, now), 0.001)
+	assert.InDelta(t, 1.0, decay.Factor(now.AddDate(0, 0, -30), now), 0.001)
+	assert.InDelta(t, 0.5, decay.Factor(now.AddDate(0, 0, -130), now), 0.01)
+	assert.InDelta(t, 0.2, decay.Factor(now.AddDate(-5, 0, 0), now), 0.001)
+	assert.Equal(t, 1.0, decay.Factor(time.Time{}, now), "no activity on record is left neutral")
+}
+
+func TestFreshnessDecay_Validation(t *testing.T) {
+	assert.NoError(t, recommendation.ValidateRequest(&recommendation.RecommendationRequest{
+		Freshness: &recommendation.FreshnessDecay{},
+	}))
+	assert.Error(t, recommendation.ValidateRequest(&recommendation.RecommendationRequest{
+		Freshness: &recommendation.FreshnessDecay{Enabled: true},
+	}))
+	assert.Error(t, recommendation.ValidateRequest(&recommendation.RecommendationRequest{
+		Freshness: &recommendation.FreshnessDecay{Enabled: true, HalfLifeDays: 90, MaxBoost: 2},
+	}))
+}
+
+// =============================================================================
+// DEGRADED MODE TESTS
+// =============================================================================
+
+func TestWithFallback_EngineErrorServesDegradedList(t *testing.T) {
+	primary := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		return nil, errors.New("failed to build user context: connection refused")
+	}
+	popular := []recommendation.TrendingItem{
+		{ServiceID: uuid.New(), TrendScore: 0.4},
+		{ServiceID: uuid.New(), TrendScore: 0.9},
+	}
+	fallback := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		return &recommendation.RecommendationResponse{
+			Recommendations:  recommendation.PopularityFallback(popular, req),
+			AlgorithmVersion: recommendation.FallbackAlgorithmVersion,
+		}, nil
+	}
+
+	resp, err := recommendation.WithFallback(primary, fallback)(context.Background(), &recommendation.RecommendationRequest{Limit: 5})
+	require.NoError(t, err)
+	assert.True(t, resp.Degraded)
+	assert.Equal(t, recommendation.FallbackAlgorithmVersion, resp.AlgorithmVersion)
+	require.Len(t, resp.Recommendations, 2)
+	assert.Equal(t, popular[1].ServiceID, resp.Recommendations[0].EntityID)
+}
+
+func TestWithFallback_HealthyEngineIsNotDegraded(t *testing.T) {
+	primary := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		return &recommendation.RecommendationResponse{AlgorithmVersion: "v2.1.0"}, nil
+	}
+	fallback := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		t.Fatal("fallback must not run when the engine succeeds")
+		return nil, nil
+	}
+
+	resp, err := recommendation.WithFallback(primary, fallback)(context.Background(), &recommendation.RecommendationRequest{Limit: 5})
+	require.NoError(t, err)
+	assert.False(t, resp.Degraded)
+}
+
+func TestWithFallback_InvalidRequestAndFailedFallbackStillError(t *testing.T) {
+	engineErr := errors.New("engine down")
+	primary := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		return nil, engineErr
+	}
+	fallback := func(ctx context.Context, req *recommendation.RecommendationRequest) (*recommendation.RecommendationResponse, error) {
+		return nil, recommendation.ErrNoFallback
+	}
+	fetch := recommendation.WithFallback(primary, fallback)
+
+	_, err := fetch(context.Background(), &recommendation.RecommendationRequest{Limit: 500})
+	assert.ErrorContains(t, err, "invalid request")
+
+	_, err = fetch(context.Background(), &recommendation.RecommendationRequest{Limit: 5})
+	assert.ErrorIs(t, err, engineErr)
+}
+
+func TestPopularityFallback_HonoursExclusionsAndLimit(t *testing.T) {
+	excluded := uuid.New()
+	popular := []recommendation.TrendingItem{
+		{ServiceID: excluded, TrendScore: 1.0},
+		{ServiceID: uuid.New(), TrendScore: 0.8},
+		{ServiceID: uuid.New(), TrendScore: 0.6},
+		{ServiceID: uuid.New(), TrendScore: 0.2},
+	}
+
+	recs := recommendation.PopularityFallback(popular, &recommendation.RecommendationRequest{
+		Limit:      2,
+		ExcludeIDs: []uuid.UUID{excluded},
+	})
+
+	require.Len(t, recs, 2)
+	assert.Equal(t, popular[1].ServiceID, recs[0].EntityID)
+	assert.Equal(t, popular[2].ServiceID, recs[1].EntityID)
+	assert.Equal(t, 1, recs[0].Position)
+	assert.Equal(t, 2, recs[1].Position)
+	assert.Equal(t, recommendation.TrendingService, recs[0].Type)
+}
+
+// =============================================================================
+// RECORDED EXPLANATION TESTS
+// =============================================================================
+
+// servedRecommendations scores, ranks and diversifies three candidates: two
+// near-identical adjacent services and one event-based suggestion from a
+// different category that diversification lifts into the top two
+func servedRecommendations(t *testing.T) ([]recommendation.Recommendation, recommendation.Candidate) {
+	scorer := recommendation.NewScorer(recommendation.DefaultConfig())
+	photography, decor := uuid.New(), uuid.New()
+
+	candidates := []recommendation.Candidate{
+		{
+			EntityType: recommendation.EntityService,
+			EntityID:   uuid.New(),
+			CategoryID: photography,
+			Source:     recommendation.AdjacentService,
+			BaseScore:  0.9,
+			Metadata:   map[string]any{"category_id": photography, "source_category_name": "Catering"},
+		},
+		{
+			EntityType: recommendation.EntityService,
+			EntityID:   uuid.New(),
+			CategoryID: photography,
+			Source:     recommendation.AdjacentService,
+			BaseScore:  0.85,
+			Metadata:   map[string]any{"category_id": photography, "source_category_name": "Catering"},
+		},
+		{
+			EntityType: recommendation.EntityService,
+			EntityID:   uuid.New(),
+			CategoryID: decor,
+			Source:     recommendation.EventBasedSuggest,
+			BaseScore:  0.5,
+			Metadata:   map[string]any{"category_id": decor, "event_type": "wedding"},
+		},
+	}
+	req := &recommendation.RecommendationRequest{EventType: "wedding"}
+	userCtx := &recommendation.UserContext{PreferredCategories: []uuid.UUID{photography}}
+
+	recs := scorer.ScoreAll(context.Background(), candidates, req, userCtx)
+	ranked := recommendation.NewRanker(recommendation.DefaultConfig()).Rank(recs)
+	served := recommendation.NewDiversifier(recommendation.DefaultConfig()).Diversify(ranked, 2, 0.3)
+	require.Len(t, served, 2)
+	return served, candidates[2]
+}
+
+// stored round-trips a served recommendation through the columns logged with
+// its impression, as ExplainRecommendation reads them back
+func stored(t *testing.T, rec recommendation.Recommendation) recommendation.Recommendation {
+	raw, err := json.Marshal(rec.Factors)
+	require.NoError(t, err)
+
+	loaded := recommendation.Recommendation{
+		ID:         rec.ID,
+		Type:       rec.Type,
+		EntityType: rec.EntityType,
+		EntityID:   rec.EntityID,
+		Factors:    &recommendation.ScoreFactors{},
+	}
+	require.NoError(t, json.Unmarshal(raw, loaded.Factors))
+	return loaded
+}
+
+func TestExplain_ReflectsRecordedScoreComponents(t *testing.T) {
+	served, _ := servedRecommendations(t)
+	top := served[0]
+
+	exp, err := recommendation.Explain(stored(t, top))
+	require.NoError(t, err)
+
+	assert.Equal(t, top.ID, exp.RecommendationID)
+	assert.InDelta(t, top.Score, exp.Score, 1e-9)
+
+	contributions := map[string]float64{}
+	total := 0.0
+	for _, c := range exp.Components {
+		contributions[c.Name] = c.Contribution
+		total += c.Contribution
+	}
+	assert.InDelta(t, 0.9*0.35, contributions["source"], 1e-9)
+	assert.InDelta(t, 0.15*0.20, contributions["personalization"], 1e-9)
+	assert.InDelta(t, 0.5*0.2, contributions["relevance"], 1e-9)
+	assert.InDelta(t, 0.0, contributions["recency"], 1e-9)
+	assert.InDelta(t, exp.PipelineScore, total, 1e-9, "components add up to the pipeline score")
+
+	assert.Contains(t, exp.MatchedSignals, "adjacent_to:Catering")
+	assert.Contains(t, exp.MatchedSignals, "preferred_category")
+	assert.Nil(t, exp.Blend, "no blending happened")
+}
+
+func TestExplain_RecordsDiversityAdjustment(t *testing.T) {
+	served, promoted := servedRecommendations(t)
+	require.Equal(t, promoted.EntityID, served[1].EntityID, "diversification lifts the other category")
+
+	exp, err := recommendation.Explain(stored(t, served[1]))
+	require.NoError(t, err)
+	assert.Equal(t, recommendation.DiversityAdjustment{
+		RankedPosition: 3,
+		ServedPosition: 2,
+		PositionsMoved: 1,
+	}, exp.Diversity)
+	assert.Contains(t, exp.MatchedSignals, "event_type:wedding")
+	assert.NotContains(t, exp.MatchedSignals, "preferred_category")
+
+	top, err := recommendation.Explain(stored(t, served[0]))
+	require.NoError(t, err)
+	assert.Equal(t, 0, top.Diversity.PositionsMoved)
+}
+
+func TestExplain_RecordsBlendAndFreshness(t *testing.T) {
+	served, _ := servedRecommendations(t)
+	rec := served[0]
+	pipeline := rec.Score
+
+	weights := recommendation.BlendWeights{Base: 0.6, Collaborative: 0.2, Content: 0.2}
+	signals := &recommendation.BlendSignals{History: []uuid.UUID{uuid.New()}}
+	blended := recommendation.BlendScores([]recommendation.Recommendation{rec}, signals, weights)
+	now := time.Now()
+	decay := recommendation.DefaultConfig().Freshness
+	fresh := recommendation.ApplyFreshness(blended, map[uuid.UUID]time.Time{rec.EntityID: now}, decay, now)
+
+	exp, err := recommendation.Explain(stored(t, fresh[0]))
+	require.NoError(t, err)
+	require.NotNil(t, exp.Blend)
+	assert.Equal(t, weights, exp.Blend.Weights)
+	assert.InDelta(t, pipeline, exp.PipelineScore, 1e-9)
+	assert.Equal(t, fresh[0].FreshnessFactor, exp.FreshnessFactor)
+	assert.InDelta(t, fresh[0].Score, exp.Score, 1e-9)
+}
+
+func TestExplain_WithoutRecordedFactors(t *testing.T) {
+	_, err := recommendation.Explain(recommendation.Recommendation{ID: uuid.New()})
+	assert.ErrorIs(t, err, recommendation.ErrExplanationNotFound)
+}
+
+// =============================================================================
+// REQUEST VALIDATION TESTS
+// =============================================================================
+
+func validationFields(t *testing.T, err error) []string {
+	t.Helper()
+	var verr *recommendation.ValidationError
+	require.True(t, errors.As(err, &verr), "expected *ValidationError, got %v", err)
+	fields := make([]string, len(verr.Errors))
+	for i, fe := range verr.Errors {
+		fields[i] = fe.Field
+		assert.NotEmpty(t, fe.Message, fe.Field)
+	}
+	return fields
+}
+
+func TestRecommendationRequestValidate_Valid(t *testing.T) {
+	req := &recommendation.RecommendationRequest{
+		Limit:             10,
+		CurrentEntityID:   uuid.New(),
+		CurrentEntityType: recommendation.EntityService,
+		Budget:            &recommendation.BudgetRange{Min: 100000, Max: 500000},
+		Location:          &recommendation.GeoPoint{Latitude: 6.5244, Longitude: 3.3792},
+	}
+	assert.NoError(t, req.Validate())
+}
+
+func TestRecommendationRequestValidate_EachField(t *testing.T) {
+	valid := func() recommendation.RecommendationRequest {
+		return recommendation.RecommendationRequest{Limit: 10}
+	}
+	cases := []struct {
+		name  string
+		edit  func(r *recommendation.RecommendationRequest)
+		field string
+	}{
+		{"limit zero", func(r *recommendation.RecommendationRequest) { r.Limit = 0 }, "limit"},
+		{"limit too high", func(r *recommendation.RecommendationRequest) { r.Limit = 101 }, "limit"},
+		{"entity ID without type", func(r *recommendation.RecommendationRequest) { r.CurrentEntityID = uuid.New() }, "current_entity"},
+		{"entity type without ID", func(r *recommendation.RecommendationRequest) {
+			r.CurrentEntityType = recommendation.EntityVendor
+		}, "current_entity"},
+		{"negative budget min", func(r *recommendation.RecommendationRequest) {
+			r.Budget = &recommendation.BudgetRange{Min: -1, Max: 1000}
+		}, "budget.min"},
+		{"negative budget max", func(r *recommendation.RecommendationRequest) {
+			r.Budget = &recommendation.BudgetRange{Max: -500}
+		}, "budget.max"},
+		{"budget min above max", func(r *recommendation.RecommendationRequest) {
+			r.Budget = &recommendation.BudgetRange{Min: 2000, Max: 1000}
+		}, "budget"},
+		{"latitude out of range", func(r *recommendation.RecommendationRequest) {
+			r.Location = &recommendation.GeoPoint{Latitude: 91, Longitude: 3.4}
+		}, "location.latitude"},
+		{"longitude out of range", func(r *recommendation.RecommendationRequest) {
+			r.Location = &recommendation.GeoPoint{Latitude: 6.5, Longitude: -181}
+		}, "location.longitude"},
+		{"diversity factor out of range", func(r *recommendation.RecommendationRequest) { r.DiversityFactor = 1.5 }, "diversity_factor"},
+		{"invalid blend", func(r *recommendation.RecommendationRequest) { r.Blend = &recommendation.BlendWeights{} }, "blend"},
+		{"invalid freshness", func(r *recommendation.RecommendationRequest) {
+			r.Freshness = &recommendation.FreshnessDecay{Enabled: true}
+		}, "freshness"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := valid()
+			tc.edit(&req)
+			assert.Equal(t, []string{tc.field}, validationFields(t, req.Validate()))
+		})
+	}
+}
+
+func TestRecommendationRequestValidate_ReportsEveryFailingField(t *testing.T) {
+	req := &recommendation.RecommendationRequest{
+		Limit:           500,
+		CurrentEntityID: uuid.New(),
+		Budget:          &recommendation.BudgetRange{Max: -1},
+		Location:        &recommendation.GeoPoint{Latitude: -100, Longitude: 200},
+	}
+
+	err := req.Validate()
+	assert.Equal(t, []string{
+		"limit", "current_entity", "budget.max", "location.latitude", "location.longitude",
+	}, validationFields(t, err))
+
+	body, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+	assert.Contains(t, string(body), `{"errors":[{"field":"limit","message":`)
+}
+
+func TestValidateRequest_ZeroLimitMeansDefault(t *testing.T) {
+	assert.NoError(t, recommendation.ValidateRequest(&recommendation.RecommendationRequest{}))
+	assert.Equal(t, []string{"limit"}, validationFields(t,
+		recommendation.ValidateRequest(&recommendation.RecommendationRequest{Limit: -1})))
+}
+
+// =============================================================================
+// PAGINATION TESTS
+// =============================================================================
+
+func paginationFixture() []recommendation.Recommendation {
+	recs := make([]recommendation.Recommendation, 25)
+	for i := range recs {
+		recs[i] = recommendation.Recommendation{
+			ID:         uuid.New(),
+			EntityType: recommendation.EntityService,
+			EntityID:   uuid.New(),
+			// Groups of three share a score so ties straddle page boundaries
+			Score: 1 - float64(i/3)*0.1,
+		}
+	}
+	return recs
+}
+
+func TestPaginate_PagesWithoutDuplicatesOrGaps(t *testing.T) {
+	ranker := recommendation.NewRanker(recommendation.DefaultConfig())
+	fixture := paginationFixture()
+
+	var pages [][]recommendation.Recommendation
+	cursor := ""
+	for {
+		// Each page re-ranks the candidates from scratch, as a new request would
+		candidates := append([]recommendation.Recommendation(nil), fixture...)
+		rand.New(rand.NewSource(int64(len(pages)))).Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+
+		page, next, err := recommendation.Paginate(ranker.Rank(candidates), cursor, 10)
+		require.NoError(t, err)
+		pages = append(pages, page)
+		if next == "" {
+			break
+		}
+		cursor = next
+		require.Less(t, len(pages), 5, "pagination did not terminate")
+	}
+
+	require.Len(t, pages, 3)
+	assert.Len(t, pages[0], 10)
+	assert.Len(t, pages[1], 10)
+	assert.Len(t, pages[2], 5)
+
+	seen := map[uuid.UUID]bool{}
+	var served []recommendation.Recommendation
+	for _, page := range pages {
+		for _, rec := range page {
+			assert.False(t, seen[rec.EntityID], "duplicate %s", rec.EntityID)
+			seen[rec.EntityID] = true
+			served = append(served, rec)
+		}
+	}
+	assert.Len(t, seen, 25)
+
+	// Concatenated pages are exactly the full ranking
+	full := ranker.Rank(append([]recommendation.Recommendation(nil), fixture...))
+	for i := range full {
+		assert.Equal(t, full[i].EntityID, served[i].EntityID, "position %d", i)
+	}
+}
+
+func TestPaginate_CursorPastTheEnd(t *testing.T) {
+	ranked := recommendation.NewRanker(recommendation.DefaultConfig()).Rank(paginationFixture())
+
+	page, next, err := recommendation.Paginate(ranked, recommendation.EncodeCursor(ranked[len(ranked)-1]), 10)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+	assert.Empty(t, next)
+
+	page, next, err = recommendation.Paginate(ranked, "", 25)
+	require.NoError(t, err)
+	assert.Len(t, page, 25)
+	assert.Empty(t, next, "no cursor when everything fits on one page")
+}
+
+func TestPaginate_RejectsForeignCursor(t *testing.T) {
+	ranked := paginationFixture()
+	for _, cursor := range []string{"not-a-cursor", "e30"} {
+		_, _, err := recommendation.Paginate(ranked, cursor, 10)
+		assert.ErrorIs(t, err, recommendation.ErrInvalidCursor, cursor)
+	}
+
+	req := &recommendation.RecommendationRequest{Limit: 10, Cursor: "not-a-cursor"}
+	assert.Equal(t, []string{"cursor"}, validationFields(t, req.Validate()))
+}
+
+// =============================================================================
+// DIVERSITY TESTS
+// =============================================================================
+
+// rankedRecs builds recommendations in score order, one per category in cats
+// and each from its own vendor unless vendors gives one
+func rankedRecs(cats []uuid.UUID, vendors []uuid.UUID) []recommendation.Recommendation {
+	recs := make([]recommendation.Recommendation, len(cats))
+	for i, cat := range cats {
+		vendor := uuid.New()
+		if vendors != nil {
+			vendor = vendors[i]
+		}
+		recs[i] = recommendation.Recommendation{
+			ID:         uuid.New(),
+			Type:       recommendation.AdjacentService,
+			EntityType: recommendation.EntityService,
+			EntityID:   uuid.New(),
+			Score:      1 - float64(i)*0.02,
+			Metadata:   map[string]any{"category_id": cat, "vendor_id": vendor},
+		}
+	}
+	return recs
+}
+
+// topCategoryShare counts how many of recs are in category
+func topCategoryShare(recs []recommendation.Recommendation, category uuid.UUID) int {
+	n := 0
+	for _, r := range recs {
+		if r.Metadata["category_id"] == category {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDiversify_HigherFactorSpreadsCategories(t *testing.T) {
+	photography, catering, decor := uuid.New(), uuid.New(), uuid.New()
+	cats := []uuid.UUID{
+		photography, photography, photography, photography, photography,
+		catering, catering, decor, decor, catering,
+	}
+	diversifier := recommendation.NewDiversifier(recommendation.DefaultConfig())
+
+	previous := 6
+	for _, factor := range []float64{0, 0.3, 0.6, 1} {
+		served := diversifier.Diversify(rankedRecs(cats, nil), 5, factor)
+		require.Len(t, served, 5)
+		share := topCategoryShare(served, photography)
+		assert.LessOrEqual(t, share, previous, "factor %.1f", factor)
+		previous = share
+	}
+
+	assert.Equal(t, 5, topCategoryShare(diversifier.Diversify(rankedRecs(cats, nil), 5, 0), photography))
+	spread := diversifier.Diversify(rankedRecs(cats, nil), 5, 1)
+	assert.LessOrEqual(t, topCategoryShare(spread, photography), 3)
+	assert.Positive(t, topCategoryShare(spread, catering))
+	assert.Positive(t, topCategoryShare(spread, decor))
+}
+
+func TestDiversify_ZeroFactorKeepsRankedOrder(t *testing.T) {
+	cat := uuid.New()
+	recs := rankedRecs([]uuid.UUID{cat, cat, uuid.New(), cat}, nil)
+	want := []uuid.UUID{recs[0].ID, recs[1].ID, recs[2].ID, recs[3].ID}
+
+	served := recommendation.NewDiversifier(recommendation.DefaultConfig()).Arrange(recs, 0)
+
+	for i, r := range served {
+		assert.Equal(t, want[i], r.ID)
+		assert.Equal(t, i+1, r.Position)
+	}
+}
+
+func TestDiversify_NoVendorDominatesTopResults(t *testing.T) {
+	dominant := uuid.New()
+	cats := make([]uuid.UUID, 9)
+	vendors := make([]uuid.UUID, 9)
+	for i := range cats {
+		cats[i] = uuid.New()
+		vendors[i] = dominant
+		if i >= 6 {
+			vendors[i] = uuid.New()
+		}
+	}
+	config := recommendation.DefaultConfig()
+
+	served := recommendation.NewDiversifier(config).Diversify(rankedRecs(cats, vendors), 5, 0)
+
+	fromDominant := 0
+	for _, r := range served {
+		if r.Metadata["vendor_id"] == dominant {
+			fromDominant++
+		}
+	}
+	assert.Equal(t, config.MaxPerVendor, fromDominant)
+	assert.Equal(t, dominant, served[0].Metadata["vendor_id"], "the top result stays first")
+}
+
+func TestDiversify_SingleVendorStillFillsResults(t *testing.T) {
+	vendor := uuid.New()
+	cats := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+
+	served := recommendation.NewDiversifier(recommendation.DefaultConfig()).
+		Diversify(rankedRecs(cats, []uuid.UUID{vendor, vendor, vendor, vendor}), 4, 0.5)
+
+	assert.Len(t, served, 4)
+}
+
+// =============================================================================
+// SCORE BREAKDOWN TESTS
+// =============================================================================
+
+func breakdownTotal(breakdown map[string]float64) float64 {
+	total := 0.0
+	for _, v := range breakdown {
+		total += v
+	}
+	return total
+}
+
+func TestBreakdown_SumsToScore(t *testing.T) {
+	served, _ := servedRecommendations(t)
+	served = recommendation.AttachBreakdowns(served, true)
+
+	for _, rec := range served {
+		require.NotEmpty(t, rec.ScoreBreakdown)
+		assert.InDelta(t, rec.Score, breakdownTotal(rec.ScoreBreakdown), 1e-9)
+	}
+
+	top := served[0].ScoreBreakdown
+	assert.InDelta(t, 0.9*0.35, top["adjacency"], 1e-9)
+	assert.InDelta(t, 0.15*0.20, top["personalization"], 1e-9)
+	assert.Contains(t, top, "relevance")
+	assert.Contains(t, served[1].ScoreBreakdown, "event_fit")
+}
+
+func TestBreakdown_SumsToScoreAfterBlendAndFreshness(t *testing.T) {
+	served, _ := servedRecommendations(t)
+	weights := recommendation.BlendWeights{Base: 0.6, Collaborative: 0.2, Content: 0.2}
+	signals := &recommendation.BlendSignals{History: []uuid.UUID{uuid.New()}}
+	blended := recommendation.BlendScores(served, signals, weights)
+	now := time.Now()
+	lastActive := map[uuid.UUID]time.Time{served[0].EntityID: now, served[1].EntityID: now.AddDate(-2, 0, 0)}
+	fresh := recommendation.ApplyFreshness(blended, lastActive, recommendation.DefaultConfig().Freshness, now)
+
+	for _, rec := range recommendation.AttachBreakdowns(fresh, true) {
+		assert.InDelta(t, rec.Score, breakdownTotal(rec.ScoreBreakdown), 1e-9)
+		assert.Contains(t, rec.ScoreBreakdown, "freshness")
+	}
+
+	// A clamped pipeline score shows the clamp as an adjustment
+	clamped := recommendation.NewScorer(recommendation.DefaultConfig()).ScoreAll(context.Background(),
+		[]recommendation.Candidate{{EntityType: recommendation.EntityService, EntityID: uuid.New(), Source: recommendation.AdjacentService, BaseScore: 5}},
+		&recommendation.RecommendationRequest{}, &recommendation.UserContext{})
+	breakdown := recommendation.Breakdown(clamped[0])
+	assert.Negative(t, breakdown["clamp"])
+	assert.InDelta(t, 1.0, breakdownTotal(breakdown), 1e-9)
+}
+
+func TestBreakdown_OmittedUnlessExplained(t *testing.T) {
+	served, _ := servedRecommendations(t)
+	served = recommendation.AttachBreakdowns(served, true)
+	served = recommendation.AttachBreakdowns(served, false)
+
+	for _, rec := range served {
+		assert.Nil(t, rec.ScoreBreakdown)
+		assert.NotEmpty(t, rec.Reasons)
+		raw, err := json.Marshal(rec)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "score_breakdown")
+	}
+
+	// Degraded-mode recommendations were never scored
+	assert.Nil(t, recommendation.Breakdown(recommendation.Recommendation{ID: uuid.New(), Score: 0.5}))
+}
+
+// =============================================================================
+// FEEDBACK TESTS
+// =============================================================================
+
+func feedbackEngine() (*recommendation.Engine, *recommendation.MemoryFeedbackStore, uuid.UUID, recommendation.ServedRecommendation) {
+	store := recommendation.NewMemoryFeedbackStore()
+	recID := uuid.New()
+	served := recommendation.ServedRecommendation{UserID: uuid.New(), EntityID: uuid.New(), AlgorithmVersion: "v2.1.0"}
+	store.AddServed(recID, served)
+
+	engine := &recommendation.Engine{}
+	engine.SetFeedbackStore(store)
+	return engine, store, recID, served
+}
+
+func TestRecordFeedback_EachAction(t *testing.T) {
+	for _, action := range []recommendation.FeedbackAction{
+		recommendation.FeedbackView,
+		recommendation.FeedbackClick,
+		recommendation.FeedbackDismiss,
+		recommendation.FeedbackConvert,
+	} {
+		t.Run(string(action), func(t *testing.T) {
+			engine, store, recID, served := feedbackEngine()
+
+			feedback, err := engine.RecordFeedback(context.Background(), recommendation.FeedbackRequest{
+				RecommendationID: recID,
+				EntityID:         served.EntityID,
+				Action:           string(action),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, action, feedback.Action)
+			assert.Equal(t, "v2.1.0", feedback.AlgorithmVersion)
+			assert.Equal(t, served.UserID, feedback.UserID)
+
+			recorded := store.Feedback()
+			require.Len(t, recorded, 1)
+			assert.Equal(t, recID, recorded[0].RecommendationID)
+			assert.Equal(t, action, recorded[0].Action)
+		})
+	}
+}
+
+func TestRecordFeedback_RejectsUnknownAction(t *testing.T) {
+	engine, store, recID, served := feedbackEngine()
+
+	_, err := engine.RecordFeedback(context.Background(), recommendation.FeedbackRequest{
+		RecommendationID: recID,
+		EntityID:         served.EntityID,
+		Action:           "like",
+	})
+	assert.ErrorIs(t, err, recommendation.ErrInvalidFeedbackAction)
+	assert.Empty(t, store.Feedback())
+}
+
+func TestRecordFeedback_UnknownRecommendationOrEntity(t *testing.T) {
+	engine, store, recID, _ := feedbackEngine()
+
+	_, err := engine.RecordFeedback(context.Background(), recommendation.FeedbackRequest{
+		RecommendationID: uuid.New(), EntityID: uuid.New(), Action: "click",
+	})
+	assert.ErrorIs(t, err, recommendation.ErrRecommendationNotFound)
+
+	_, err = engine.RecordFeedback(context.Background(), recommendation.FeedbackRequest{
+		RecommendationID: recID, EntityID: uuid.New(), Action: "click",
+	})
+	assert.ErrorIs(t, err, recommendation.ErrFeedbackEntityMismatch)
+	assert.Empty(t, store.Feedback())
+}
+
+// =============================================================================
+// CANDIDATE POOL CACHE TESTS
+// =============================================================================
+
+func newCandidatePool(t *testing.T, ttl time.Duration) (*recommendation.CandidatePool, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return recommendation.NewCandidatePool(client, ttl), mr
+}
+
+// countingPool generates one adjacency candidate per call and counts calls
+func countingPool(calls *int) func(context.Context) ([]recommendation.Candidate, error) {
+	return func(context.Context) ([]recommendation.Candidate, error) {
+		*calls++
+		return []recommendation.Candidate{{
+			EntityType: recommendation.EntityService,
+			EntityID:   uuid.New(),
+			CategoryID: uuid.New(),
+			Source:     recommendation.AdjacentService,
+			BaseScore:  0.8,
+		}}, nil
+	}
+}
+
+func serviceRequest() *recommendation.RecommendationRequest {
+	return &recommendation.RecommendationRequest{
+		CurrentEntityType: recommendation.EntityService,
+		CurrentEntityID:   uuid.New(),
+		EventType:         "wedding",
+	}
+}
+
+func TestCandidatePool_CachesPerEntityAndEvent(t *testing.T) {
+	pool, _ := newCandidatePool(t, time.Minute)
+	req := serviceRequest()
+	calls := 0
+
+	first, hit, err := pool.Get(context.Background(), req, countingPool(&calls))
+	require.NoError(t, err)
+	assert.False(t, hit)
+
+	second, hit, err := pool.Get(context.Background(), req, countingPool(&calls))
+	require.NoError(t, err)
+	assert.True(t, hit)
+	assert.Equal(t, 1, calls)
+	require.Len(t, second, 1)
+	assert.Equal(t, first[0].EntityID, second[0].EntityID)
+
+	// Another event type is its own pool
+	other := *req
+	other.EventType = "birthday"
+	_, hit, _ = pool.Get(context.Background(), &other, countingPool(&calls))
+	assert.False(t, hit)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCandidatePool_ExpiresAfterTTL(t *testing.T) {
+	pool, mr := newCandidatePool(t, time.Minute)
+	req := serviceRequest()
+	calls := 0
+
+	pool.Get(context.Background(), req, countingPool(&calls))
+	mr.FastForward(59 * time.Second)
+	_, hit, _ := pool.Get(context.Background(), req, countingPool(&calls))
+	assert.True(t, hit)
+
+	mr.FastForward(2 * time.Second)
+	_, hit, _ = pool.Get(context.Background(), req, countingPool(&calls))
+	assert.False(t, hit)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCandidatePool_LocationRequestsBypassSharedCache(t *testing.T) {
+	pool, mr := newCandidatePool(t, time.Minute)
+	located := serviceRequest()
+	located.Location = &recommendation.GeoPoint{Latitude: 6.5244, Longitude: 3.3792}
+	calls := 0
+
+	for i := 0; i < 2; i++ {
+		_, hit, err := pool.Get(context.Background(), located, countingPool(&calls))
+		require.NoError(t, err)
+		assert.False(t, hit)
+	}
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, mr.Keys(), "located pools must not be cached")
+
+	// The same entity without a location is not served the located pool
+	unlocated := *located
+	unlocated.Location = nil
+	_, hit, _ := pool.Get(context.Background(), &unlocated, countingPool(&calls))
+	assert.False(t, hit)
+	assert.Equal(t, 3, calls)
+}
+
+func TestCandidatePool_Invalidate(t *testing.T) {
+	pool, mr := newCandidatePool(t, time.Minute)
+	a, b := serviceRequest(), serviceRequest()
+	calls := 0
+	pool.Get(context.Background(), a, countingPool(&calls))
+	pool.Get(context.Background(), b, countingPool(&calls))
+
+	require.NoError(t, pool.Invalidate(context.Background(), a.CurrentEntityType, a.CurrentEntityID))
+	_, hitA, _ := pool.Get(context.Background(), a, countingPool(&calls))
+	_, hitB, _ := pool.Get(context.Background(), b, countingPool(&calls))
+	assert.False(t, hitA)
+	assert.True(t, hitB)
+
+	require.NoError(t, pool.Invalidate(context.Background(), "", uuid.Nil))
+	assert.Empty(t, mr.Keys())
+}
+
+func TestCandidatePool_DisabledWithoutTTL(t *testing.T) {
+	pool, mr := newCandidatePool(t, 0)
+	calls := 0
+
+	pool.Get(context.Background(), serviceRequest(), countingPool(&calls))
+	assert.Empty(t, mr.Keys())
+}
+
+func TestFitPoolToUser(t *testing.T) {
+	booked, other := uuid.New(), uuid.New()
+	pool := []recommendation.Candidate{
+		{EntityID: uuid.New(), CategoryID: booked, Source: recommendation.EventBasedSuggest},
+		{EntityID: uuid.New(), CategoryID: other, Source: recommendation.EventBasedSuggest},
+		{EntityID: uuid.New(), CategoryID: other, Source: recommendation.AdjacentService,
+			Metadata: map[string]any{"source_category": booked.String()}},
+	}
+
+	fitted := recommendation.FitPoolToUser(pool, &recommendation.UserContext{AlreadyBookedCategories: []uuid.UUID{booked}})
+
+	require.Len(t, fitted, 2)
+	assert.Equal(t, other, fitted[0].CategoryID)
+	assert.Equal(t, true, fitted[1].Metadata["source_booked"])
+	assert.Equal(t, booked, fitted[1].Metadata["source_category"])
+}
@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/review"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Vendor Rating Recomputation
+
+// memoryRatingStore holds reviews and vendors' saved ratings in process
+type memoryRatingStore struct {
+	mu      sync.Mutex
+	reviews map[uuid.UUID]review.Review // by review
+	ratings map[uuid.UUID]review.VendorRating
+}
+
+func newMemoryRatingStore() *memoryRatingStore {
+	return &memoryRatingStore{
+		reviews: make(map[uuid.UUID]review.Review),
+		ratings: make(map[uuid.UUID]review.VendorRating),
+	}
+}
+
+func (m *memoryRatingStore) SaveReview(r review.Review) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reviews[r.ID] = r
+}
+
+func (m *memoryRatingStore) DeleteReview(reviewID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.reviews, reviewID)
+}
+
+// VendorRating returns the rating last saved for a vendor
+func (m *memoryRatingStore) VendorRating(vendorID uuid.UUID) review.VendorRating {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ratings[vendorID]
+}
+
+func (m *memoryRatingStore) RecomputeVendorRating(ctx context.Context, tx pgx.Tx, vendorID uuid.UUID, verifiedWeight float64) (review.VendorRating, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var reviews []review.Review
+	for _, r := range m.reviews {
+		if r.VendorID == vendorID {
+			reviews = append(reviews, r)
+		}
+	}
+	rating := review.NewVendorRating(reviews, verifiedWeight)
+	m.ratings[vendorID] = rating
+	return rating, nil
+}
+
+func newRatingService() (*review.Service, *memoryRatingStore) {
+	service := review.NewService(nil, nil)
+	store := newMemoryRatingStore()
+	service.SetRatingStore(store)
+	return service, store
+}
+
+func publishedReview(vendorID uuid.UUID, rating int) review.Review {
+	return review.Review{ID: uuid.New(), VendorID: vendorID, UserID: uuid.New(), Rating: rating, IsPublished: true}
+}
+
+func TestRecomputeVendorRating_AddingReview(t *testing.T) {
+	service, store := newRatingService()
+	ctx := context.Background()
+	vendorID := uuid.New()
+
+	store.SaveReview(publishedReview(vendorID, 5))
+	store.SaveReview(publishedReview(vendorID, 4))
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+	assert.Equal(t, review.VendorRating{Average: 4.5, Count: 2}, store.VendorRating(vendorID))
+
+	store.SaveReview(publishedReview(vendorID, 4))
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+	assert.Equal(t, review.VendorRating{Average: 4.3, Count: 3}, store.VendorRating(vendorID))
+
+	// Other vendors' reviews do not count
+	store.SaveReview(publishedReview(uuid.New(), 1))
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+	assert.Equal(t, 3, store.VendorRating(vendorID).Count)
+}
+
+func TestRecomputeVendorRating_DeletingReview(t *testing.T) {
+	service, store := newRatingService()
+	ctx := context.Background()
+	vendorID := uuid.New()
+
+	kept := publishedReview(vendorID, 5)
+	unpublished := publishedReview(vendorID, 1)
+	deleted := publishedReview(vendorID, 2)
+	for _, r := range []review.Review{kept, unpublished, deleted} {
+		store.SaveReview(r)
+	}
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+	assert.Equal(t, review.VendorRating{Average: 2.7, Count: 3}, store.VendorRating(vendorID))
+
+	unpublished.IsPublished = false
+	store.SaveReview(unpublished)
+	store.DeleteReview(deleted.ID)
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+
+	assert.Equal(t, review.VendorRating{Average: 5, Count: 1}, store.VendorRating(vendorID))
+}
+
+func TestRecomputeVendorRating_NoReviewsResetsToZero(t *testing.T) {
+	service, store := newRatingService()
+	ctx := context.Background()
+	vendorID := uuid.New()
+
+	only := publishedReview(vendorID, 3)
+	store.SaveReview(only)
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+	require.Equal(t, 1, store.VendorRating(vendorID).Count)
+
+	store.DeleteReview(only.ID)
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+
+	assert.Equal(t, review.VendorRating{}, store.VendorRating(vendorID))
+}
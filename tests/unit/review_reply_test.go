@@ -0,0 +1,145 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/review"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Vendor Replies
+
+// memoryReplyStore holds reviews' vendors and replies in process
+type memoryReplyStore struct {
+	mu      sync.Mutex
+	vendors map[uuid.UUID]uuid.UUID // review to vendor
+	replies map[uuid.UUID]review.ReviewReply
+}
+
+func newMemoryReplyStore() *memoryReplyStore {
+	return &memoryReplyStore{
+		vendors: make(map[uuid.UUID]uuid.UUID),
+		replies: make(map[uuid.UUID]review.ReviewReply),
+	}
+}
+
+// AddReview stores a review of vendorID that can be replied to
+func (m *memoryReplyStore) AddReview(reviewID, vendorID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.vendors[reviewID] = vendorID
+}
+
+func (m *memoryReplyStore) Reply(reviewID uuid.UUID) (review.ReviewReply, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reply, ok := m.replies[reviewID]
+	return reply, ok
+}
+
+func (m *memoryReplyStore) ReviewReply(ctx context.Context, reviewID uuid.UUID) (uuid.UUID, *review.ReviewReply, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vendorID, ok := m.vendors[reviewID]
+	if !ok {
+		return uuid.Nil, nil, review.ErrReviewNotFound
+	}
+	reply, ok := m.replies[reviewID]
+	if !ok {
+		return vendorID, nil, nil
+	}
+	return vendorID, &reply, nil
+}
+
+func (m *memoryReplyStore) CreateReply(ctx context.Context, reply *review.ReviewReply) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.replies[reply.ReviewID]; ok {
+		return review.ErrReplyExists
+	}
+	m.replies[reply.ReviewID] = *reply
+	return nil
+}
+
+func (m *memoryReplyStore) EditReply(ctx context.Context, reply *review.ReviewReply) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.replies[reply.ReviewID]; !ok {
+		return review.ErrReplyNotFound
+	}
+	m.replies[reply.ReviewID] = *reply
+	return nil
+}
+
+func newReplyService() (*review.Service, *memoryReplyStore) {
+	service := review.NewService(nil, nil)
+	store := newMemoryReplyStore()
+	service.SetReplyStore(store)
+	return service, store
+}
+
+func TestAddReply_ReviewedVendor(t *testing.T) {
+	service, store := newReplyService()
+	reviewID, vendorID := uuid.New(), uuid.New()
+	store.AddReview(reviewID, vendorID)
+
+	require.NoError(t, service.AddReply(context.Background(), reviewID, vendorID, "Thanks for the feedback"))
+
+	reply, ok := store.Reply(reviewID)
+	require.True(t, ok)
+	assert.Equal(t, vendorID, reply.VendorID)
+	assert.Equal(t, "Thanks for the feedback", reply.Body)
+	assert.WithinDuration(t, time.Now(), reply.CreatedAt, time.Second)
+	assert.Nil(t, reply.EditedAt)
+}
+
+func TestAddReply_OtherVendor(t *testing.T) {
+	service, store := newReplyService()
+	reviewID := uuid.New()
+	store.AddReview(reviewID, uuid.New())
+
+	err := service.AddReply(context.Background(), reviewID, uuid.New(), "Not my review")
+
+	assert.ErrorIs(t, err, review.ErrUnauthorized)
+	_, ok := store.Reply(reviewID)
+	assert.False(t, ok)
+}
+
+func TestAddReply_OnlyOnce(t *testing.T) {
+	service, store := newReplyService()
+	ctx := context.Background()
+	reviewID, vendorID := uuid.New(), uuid.New()
+	store.AddReview(reviewID, vendorID)
+
+	require.NoError(t, service.AddReply(ctx, reviewID, vendorID, "First"))
+	err := service.AddReply(ctx, reviewID, vendorID, "Second")
+
+	assert.ErrorIs(t, err, review.ErrReplyExists)
+	reply, _ := store.Reply(reviewID)
+	assert.Equal(t, "First", reply.Body)
+}
+
+func TestEditReply_WithinWindow(t *testing.T) {
+	service, store := newReplyService()
+	ctx := context.Background()
+	reviewID, vendorID := uuid.New(), uuid.New()
+	store.AddReview(reviewID, vendorID)
+
+	assert.ErrorIs(t, service.EditReply(ctx, reviewID, vendorID, "Edited"), review.ErrReplyNotFound)
+
+	require.NoError(t, service.AddReply(ctx, reviewID, vendorID, "First"))
+	require.NoError(t, service.EditReply(ctx, reviewID, vendorID, "Edited"))
+	reply, _ := store.Reply(reviewID)
+	assert.Equal(t, "Edited", reply.Body)
+	assert.NotNil(t, reply.EditedAt)
+
+	service.SetReplyEditWindow(0)
+	assert.ErrorIs(t, service.EditReply(ctx, reviewID, vendorID, "Too late"), review.ErrReplyEditClosed)
+	reply, _ = store.Reply(reviewID)
+	assert.Equal(t, "Edited", reply.Body)
+}
@@ -0,0 +1,171 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/BillyRonksGlobal/vendorplatform/internal/review"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Verified Purchase Reviews
+
+// memoryBookingLookup holds bookings in process
+type memoryBookingLookup struct {
+	mu       sync.Mutex
+	bookings map[uuid.UUID]review.ReviewBooking
+}
+
+func newMemoryBookingLookup() *memoryBookingLookup {
+	return &memoryBookingLookup{bookings: make(map[uuid.UUID]review.ReviewBooking)}
+}
+
+func (m *memoryBookingLookup) AddBooking(booking review.ReviewBooking) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bookings[booking.ID] = booking
+}
+
+func (m *memoryBookingLookup) FindBooking(ctx context.Context, bookingID uuid.UUID) (*review.ReviewBooking, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	booking, ok := m.bookings[bookingID]
+	if !ok {
+		return nil, review.ErrBookingNotFound
+	}
+	return &booking, nil
+}
+
+func (m *memoryBookingLookup) HasCompletedBooking(ctx context.Context, userID, vendorID uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, booking := range m.bookings {
+		if booking.UserID == userID && booking.VendorID == vendorID && booking.Status == "completed" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newVerifiedService() (*review.Service, *memoryBookingLookup) {
+	service := review.NewService(nil, nil)
+	bookings := newMemoryBookingLookup()
+	service.SetBookingLookup(bookings)
+	return service, bookings
+}
+
+func TestVerifyPurchase_CompletedBooking(t *testing.T) {
+	service, bookings := newVerifiedService()
+	booking := review.ReviewBooking{ID: uuid.New(), UserID: uuid.New(), VendorID: uuid.New(), Status: "completed"}
+	bookings.AddBooking(booking)
+
+	verified, err := service.VerifyPurchase(context.Background(), &review.CreateReviewRequest{
+		UserID:    booking.UserID,
+		VendorID:  booking.VendorID,
+		BookingID: &booking.ID,
+	})
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	// A completed booking verifies reviews that do not name it
+	verified, err = service.VerifyPurchase(context.Background(), &review.CreateReviewRequest{
+		UserID:   booking.UserID,
+		VendorID: booking.VendorID,
+	})
+	require.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestVerifyPurchase_NoBooking(t *testing.T) {
+	service, bookings := newVerifiedService()
+	ctx := context.Background()
+	userID, vendorID := uuid.New(), uuid.New()
+
+	// Bookings that are not completed, or with another vendor, do not verify
+	bookings.AddBooking(review.ReviewBooking{ID: uuid.New(), UserID: userID, VendorID: vendorID, Status: "cancelled"})
+	bookings.AddBooking(review.ReviewBooking{ID: uuid.New(), UserID: userID, VendorID: uuid.New(), Status: "completed"})
+
+	verified, err := service.VerifyPurchase(ctx, &review.CreateReviewRequest{UserID: userID, VendorID: vendorID})
+	require.NoError(t, err)
+	assert.False(t, verified)
+
+	missing := uuid.New()
+	_, err = service.VerifyPurchase(ctx, &review.CreateReviewRequest{UserID: userID, VendorID: vendorID, BookingID: &missing})
+	assert.ErrorIs(t, err, review.ErrBookingNotFound)
+}
+
+func TestVerifyPurchase_BookingChecks(t *testing.T) {
+	service, bookings := newVerifiedService()
+	ctx := context.Background()
+	confirmed := review.ReviewBooking{ID: uuid.New(), UserID: uuid.New(), VendorID: uuid.New(), Status: "confirmed"}
+	pending := review.ReviewBooking{ID: uuid.New(), UserID: confirmed.UserID, VendorID: confirmed.VendorID, Status: "pending"}
+	bookings.AddBooking(confirmed)
+	bookings.AddBooking(pending)
+
+	verified, err := service.VerifyPurchase(ctx, &review.CreateReviewRequest{
+		UserID: confirmed.UserID, VendorID: confirmed.VendorID, BookingID: &confirmed.ID,
+	})
+	require.NoError(t, err)
+	assert.False(t, verified)
+
+	_, err = service.VerifyPurchase(ctx, &review.CreateReviewRequest{
+		UserID: confirmed.UserID, VendorID: confirmed.VendorID, BookingID: &pending.ID,
+	})
+	assert.ErrorIs(t, err, review.ErrBookingNotCompleted)
+
+	_, err = service.VerifyPurchase(ctx, &review.CreateReviewRequest{
+		UserID: uuid.New(), VendorID: confirmed.VendorID, BookingID: &confirmed.ID,
+	})
+	assert.ErrorIs(t, err, review.ErrBookingNotFound)
+
+	_, err = service.VerifyPurchase(ctx, &review.CreateReviewRequest{
+		UserID: confirmed.UserID, VendorID: uuid.New(), BookingID: &confirmed.ID,
+	})
+	assert.ErrorIs(t, err, review.ErrBookingWrongVendor)
+}
+
+func TestNewVendorRating_WeightsVerifiedPurchases(t *testing.T) {
+	vendorID := uuid.New()
+	verified := publishedReview(vendorID, 5)
+	verified.VerifiedPurchase = true
+	reviews := []review.Review{verified, publishedReview(vendorID, 2)}
+
+	assert.Equal(t, review.VendorRating{Average: 3.5, Count: 2}, review.NewVendorRating(reviews, 1))
+	assert.Equal(t, review.VendorRating{Average: 4, Count: 2}, review.NewVendorRating(reviews, 2))
+	assert.Equal(t, review.VendorRating{Average: 4.4, Count: 2}, review.NewVendorRating(reviews, 4))
+}
+
+func TestRecomputeVendorRating_VerifiedWeight(t *testing.T) {
+	service, store := newRatingService()
+	ctx := context.Background()
+	vendorID := uuid.New()
+
+	verified := publishedReview(vendorID, 1)
+	verified.VerifiedPurchase = true
+	store.SaveReview(verified)
+	store.SaveReview(publishedReview(vendorID, 5))
+	store.SaveReview(publishedReview(vendorID, 4))
+
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+	assert.Equal(t, review.VendorRating{Average: 2.8, Count: 3}, store.VendorRating(vendorID))
+
+	service.SetVerifiedPurchaseWeight(1)
+	require.NoError(t, service.RecomputeVendorRating(ctx, vendorID))
+	assert.Equal(t, review.VendorRating{Average: 3.3, Count: 3}, store.VendorRating(vendorID))
+}
+
+func TestReview_JSONKeepsIsVerified(t *testing.T) {
+	r := review.Review{ID: uuid.New(), IsVerified: true, VerifiedPurchase: true}
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, true, fields["is_verified"])
+	assert.Equal(t, true, fields["verified_purchase"])
+}
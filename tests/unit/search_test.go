@@ -6,7 +6,16 @@
 package unit
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/BillyRonksGlobal/vendorplatform/internal/search"
 )
@@ -350,9 +359,8 @@ func TestSearchTypeValidation(t *testing.T) {
 
 func TestCacheKeyGeneration(t *testing.T) {
 	tests := []struct {
-		name     string
-		req      search.SearchRequest
-		expected string
+		name string
+		req  search.SearchRequest
 	}{
 		{
 			name: "Basic search",
@@ -362,7 +370,6 @@ func TestCacheKeyGeneration(t *testing.T) {
 				Page:     1,
 				PageSize: 20,
 			},
-			expected: "search:vendor:plumber:1:20",
 		},
 		{
 			name: "Different page",
@@ -372,7 +379,6 @@ func TestCacheKeyGeneration(t *testing.T) {
 				Page:     2,
 				PageSize: 20,
 			},
-			expected: "search:vendor:plumber:2:20",
 		},
 		{
 			name: "Different page size",
@@ -382,7 +388,6 @@ func TestCacheKeyGeneration(t *testing.T) {
 				Page:     1,
 				PageSize: 50,
 			},
-			expected: "search:vendor:plumber:1:50",
 		},
 		{
 			name: "Service search",
@@ -392,22 +397,140 @@ func TestCacheKeyGeneration(t *testing.T) {
 				Page:     1,
 				PageSize: 20,
 			},
-			expected: "search:service:catering:1:20",
+		},
+		{
+			name: "With filters",
+			req: search.SearchRequest{
+				Query:    "plumber",
+				Type:     search.TypeVendor,
+				Page:     1,
+				PageSize: 20,
+				Filters:  map[string]interface{}{"city": "Lagos"},
+			},
+		},
+		{
+			name: "With location",
+			req: search.SearchRequest{
+				Query:    "plumber",
+				Type:     search.TypeVendor,
+				Page:     1,
+				PageSize: 20,
+				Location: &search.Location{Lat: 6.5244, Lon: 3.3792},
+				RadiusKM: 10,
+			},
 		},
 	}
 
+	seen := make(map[string]string)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Simulate cache key generation
-			cacheKey := "search:" + string(tt.req.Type) + ":" + tt.req.Query + ":" +
-				string(rune(tt.req.Page+'0')) + ":" + string(rune(tt.req.PageSize/10+'0')) + string(rune(tt.req.PageSize%10+'0'))
-
-			// Note: This is a simplified version. The actual implementation uses fmt.Sprintf
-			// For proper testing, we'd need to import the actual function
+			cacheKey := search.CacheKey(tt.req)
+			assert.True(t, strings.HasPrefix(cacheKey, "search:"))
+			assert.Equal(t, cacheKey, search.CacheKey(tt.req), "keys are stable")
+			if other, dup := seen[cacheKey]; dup {
+				t.Errorf("%q shares a cache key with %q", tt.name, other)
+			}
+			seen[cacheKey] = tt.name
 		})
 	}
 }
 
+func TestCacheKeyNormalization(t *testing.T) {
+	base := search.SearchRequest{
+		Query:    "photographer in Lagos",
+		Type:     search.TypeVendor,
+		Page:     1,
+		PageSize: 20,
+		Filters:  map[string]interface{}{"city": "Lagos", "min_rating": 4.0},
+		Location: &search.Location{Lat: 6.52441, Lon: 3.37921},
+		RadiusKM: 10,
+	}
+	same := base
+	same.Query = "  Photographer   IN lagos "
+	same.Filters = map[string]interface{}{"min_rating": 4.0, "city": "Lagos"}
+	same.Location = &search.Location{Lat: 6.52438, Lon: 3.37919}
+
+	assert.Equal(t, search.CacheKey(base), search.CacheKey(same))
+}
+
+// =============================================================================
+// RESULT CACHING TESTS
+// =============================================================================
+
+// memoryResultCache records what the search service caches
+type memoryResultCache struct {
+	entries map[string][]byte
+	hits    int
+}
+
+func (c *memoryResultCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	v, ok := c.entries[key]
+	if ok {
+		c.hits++
+	}
+	return v, ok
+}
+
+func (c *memoryResultCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	c.entries[key] = value
+}
+
+// newCachedSearch starts a fake Elasticsearch that counts the queries it
+// answers, and a search service in front of it caching into memory
+func newCachedSearch(t *testing.T) (*search.Service, *memoryResultCache, *int) {
+	queries := 0
+	es := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_id":"` + uuid.NewString() + `","_index":"vendors","_score":1.5,"_source":{"name":"Lens & Light"}}]}}`))
+	}))
+	t.Cleanup(es.Close)
+
+	cache := &memoryResultCache{entries: make(map[string][]byte)}
+	svc := search.NewService(nil, nil, &search.Config{ElasticsearchURL: es.URL})
+	svc.SetResultCache(cache)
+	return svc, cache, &queries
+}
+
+func TestSearchCache_AnonymousRepeatHitsCache(t *testing.T) {
+	svc, cache, queries := newCachedSearch(t)
+	ctx := context.Background()
+
+	first, err := svc.Search(ctx, search.SearchRequest{Query: "photographer in Lagos", Type: search.TypeVendor})
+	require.NoError(t, err)
+	again, err := svc.Search(ctx, search.SearchRequest{Query: "Photographer in lagos", Type: search.TypeVendor})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, *queries, "the repeat is served without querying Elasticsearch")
+	assert.Equal(t, 1, cache.hits)
+	require.Len(t, again.Results, 1)
+	assert.Equal(t, first.Results[0].ID, again.Results[0].ID)
+	assert.Equal(t, "Lens & Light", again.Results[0].Title)
+}
+
+func TestSearchCache_PersonalizedBypassesCache(t *testing.T) {
+	svc, cache, queries := newCachedSearch(t)
+	ctx := context.Background()
+	req := search.SearchRequest{
+		Query:      "photographer in Lagos",
+		Type:       search.TypeVendor,
+		ExcludeIDs: []uuid.UUID{uuid.New()},
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.Search(ctx, req)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, *queries, "personalized searches always query Elasticsearch")
+	assert.Zero(t, cache.hits)
+	assert.Empty(t, cache.entries, "personalized results are never cached")
+
+	history := search.SearchRequest{Query: "photographer in Lagos", Type: search.TypeVendor, BoostIDs: []uuid.UUID{uuid.New()}}
+	assert.True(t, history.Personalized())
+	assert.False(t, search.SearchRequest{Query: "photographer in Lagos"}.Personalized())
+}
+
 // =============================================================================
 // FILTER VALIDATION TESTS
 // =============================================================================
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	vendornetapi "github.com/BillyRonksGlobal/vendorplatform/api/vendornet"
+	"github.com/BillyRonksGlobal/vendorplatform/internal/vendornet"
+)
+
+func TestExportReferrals_CSVContent(t *testing.T) {
+	vendorID := uuid.New()
+	partnerID := uuid.New()
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	referrals := []*vendornet.Referral{
+		{
+			ID:             uuid.New(),
+			SourceVendorID: vendorID,
+			DestVendorID:   partnerID,
+			ClientName:     stringPtr("Ada Obi"),
+			EventType:      stringPtr("wedding"),
+			EstimatedValue: int64Ptr(250000),
+			Status:         "converted",
+			StatusHistory:  []byte(`[{"status":"pending"},{"status":"converted"}]`),
+			TrackingCode:   "REF-12345678",
+			CreatedAt:      createdAt,
+			UpdatedAt:      createdAt,
+		},
+		{
+			ID:             uuid.New(),
+			SourceVendorID: partnerID,
+			DestVendorID:   vendorID,
+			Status:         "pending",
+			TrackingCode:   "REF-87654321",
+			CreatedAt:      createdAt,
+			UpdatedAt:      createdAt,
+		},
+	}
+
+	var buf bytes.Buffer
+	writer, err := vendornet.NewExportWriter(vendornet.ExportFormatCSV, &buf)
+	require.NoError(t, err)
+	columns, err := vendornet.ExportColumns(vendornet.ExportReferrals)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Begin(columns))
+	require.NoError(t, vendornet.WriteReferralExport(writer, vendorID, referrals))
+	require.NoError(t, writer.End())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, columns, records[0])
+
+	row := map[string]string{}
+	for i, col := range columns {
+		row[col] = records[1][i]
+	}
+	assert.Equal(t, "sent", row["direction"])
+	assert.Equal(t, "Ada Obi", row["client_name"])
+	assert.Equal(t, "250000", row["estimated_value"])
+	assert.Equal(t, "converted", row["status"])
+	assert.Equal(t, `[{"status":"pending"},{"status":"converted"}]`, row["status_history"])
+	assert.Equal(t, "2024-05-01T10:00:00Z", row["created_at"])
+
+	assert.Equal(t, "received", records[2][1])
+}
+
+func TestExportReferrals_ScopedToVendor(t *testing.T) {
+	vendorID := uuid.New()
+	otherA, otherB := uuid.New(), uuid.New()
+
+	referrals := []*vendornet.Referral{
+		{ID: uuid.New(), SourceVendorID: vendorID, DestVendorID: otherA, Status: "pending"},
+		{ID: uuid.New(), SourceVendorID: otherA, DestVendorID: otherB, Status: "pending"},
+	}
+
+	var buf bytes.Buffer
+	writer, err := vendornet.NewExportWriter(vendornet.ExportFormatJSON, &buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.Begin(nil))
+	require.NoError(t, vendornet.WriteReferralExport(writer, vendorID, referrals))
+	require.NoError(t, writer.End())
+
+	var exported []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	require.Len(t, exported, 1)
+	assert.Equal(t, referrals[0].ID.String(), exported[0]["id"])
+	assert.Equal(t, "sent", exported[0]["direction"])
+}
+
+func TestExportValidation(t *testing.T) {
+	_, err := vendornet.ExportColumns("customers")
+	assert.ErrorIs(t, err, vendornet.ErrInvalidExportType)
+
+	_, err = vendornet.NewExportWriter("xml", &bytes.Buffer{})
+	assert.ErrorIs(t, err, vendornet.ErrInvalidExportFormat)
+}
+
+func TestExportAccount_CanExportOnlyOnActivePaidTiers(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		account vendornet.ExportAccount
+		want    bool
+	}{
+		{"free tier", vendornet.ExportAccount{SubscriptionTier: "free"}, false},
+		{"basic tier", vendornet.ExportAccount{SubscriptionTier: "basic"}, false},
+		{"no tier", vendornet.ExportAccount{}, false},
+		{"pro without end date", vendornet.ExportAccount{SubscriptionTier: "pro"}, true},
+		{"business until later", vendornet.ExportAccount{SubscriptionTier: "business", SubscriptionEnds: &future}, true},
+		{"enterprise expired", vendornet.ExportAccount{SubscriptionTier: "enterprise", SubscriptionEnds: &past}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.account.CanExport(now), tt.name)
+	}
+}
+
+func TestExportNetwork_RejectsHeaderOnlyIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := vendornetapi.NewHandler(nil, nil, zap.NewNop())
+	handler.SetAuthMiddleware(bearerAuth())
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/vendornet/export?type=referrals", nil)
+	req.Header.Set("X-User-ID", uuid.New().String())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestExportNetwork_UnavailableWithoutAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := vendornetapi.NewHandler(nil, nil, zap.NewNop())
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/vendornet/export?type=referrals", nil)
+	req.Header.Set("X-User-ID", uuid.New().String())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,672 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	vendornetapi "github.com/BillyRonksGlobal/vendorplatform/api/vendornet"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newPendingReferral(value float64) *vendornetapi.Referral {
+	source := uuid.New()
+	return &vendornetapi.Referral{
+		ID:             uuid.New(),
+		SourceVendorID: source,
+		DestVendorID:   uuid.New(),
+		EstimatedValue: value,
+		Status:         vendornetapi.ReferralPending,
+		StatusHistory: []vendornetapi.StatusChange{
+			{Status: vendornetapi.ReferralPending, ChangedAt: time.Now(), ChangedBy: source},
+		},
+	}
+}
+
+// Test Referral Acceptance Preferences
+
+func TestReferralPreferences_AutoAccept(t *testing.T) {
+	referral := newPendingReferral(500000)
+
+	vendornetapi.ApplyReferralPreferences(referral, vendornetapi.ReferralPrefs{}, true, time.Now())
+
+	assert.Equal(t, vendornetapi.ReferralAccepted, referral.Status)
+	require.Len(t, referral.StatusHistory, 2)
+	assert.Equal(t, referral.DestVendorID, referral.StatusHistory[1].ChangedBy)
+}
+
+func TestReferralPreferences_AutoAcceptAndContact(t *testing.T) {
+	referral := newPendingReferral(500000)
+	prefs := vendornetapi.ReferralPrefs{AutoContactClient: true}
+
+	vendornetapi.ApplyReferralPreferences(referral, prefs, true, time.Now())
+
+	assert.Equal(t, vendornetapi.ReferralContacted, referral.Status)
+	require.Len(t, referral.StatusHistory, 3)
+	assert.Equal(t, vendornetapi.ReferralAccepted, referral.StatusHistory[1].Status)
+}
+
+func TestReferralPreferences_RequireApprovalStaysPending(t *testing.T) {
+	referral := newPendingReferral(500000)
+	prefs := vendornetapi.ReferralPrefs{RequireApproval: true}
+
+	vendornetapi.ApplyReferralPreferences(referral, prefs, true, time.Now())
+
+	assert.Equal(t, vendornetapi.ReferralPending, referral.Status)
+	assert.Len(t, referral.StatusHistory, 1)
+}
+
+func TestReferralPreferences_BelowMinimumAutoDeclined(t *testing.T) {
+	referral := newPendingReferral(20000)
+	prefs := vendornetapi.ReferralPrefs{MinReferralValue: 50000}
+
+	vendornetapi.ApplyReferralPreferences(referral, prefs, true, time.Now())
+
+	assert.Equal(t, vendornetapi.ReferralDeclined, referral.Status)
+	require.Len(t, referral.StatusHistory, 2)
+	assert.Contains(t, referral.StatusHistory[1].Notes, "below minimum")
+}
+
+// Test Referral Response SLA
+
+func TestReferralSLA_UnansweredReferralExpires(t *testing.T) {
+	referral := newPendingReferral(500000)
+	referral.CreatedAt = time.Now().Add(-50 * time.Hour)
+	metrics := vendornetapi.ReferralResponseMetrics{Responded: 9}
+
+	expired := vendornetapi.ExpireOverdueReferral(referral, nil, &metrics, time.Now())
+
+	assert.True(t, expired)
+	assert.Equal(t, vendornetapi.ReferralExpired, referral.Status)
+	require.Len(t, referral.StatusHistory, 2)
+	assert.Contains(t, referral.StatusHistory[1].Notes, "48 hours")
+	assert.Equal(t, 1, metrics.SLAMisses)
+	assert.InDelta(t, 0.9, metrics.ResponseRate(), 0.001)
+}
+
+func TestReferralSLA_PartnershipResponseTime(t *testing.T) {
+	referral := newPendingReferral(500000)
+	referral.CreatedAt = time.Now().Add(-30 * time.Hour)
+	metrics := vendornetapi.ReferralResponseMetrics{}
+
+	// Within the platform default, but past the partnership's 24 hours
+	assert.False(t, vendornetapi.ExpireOverdueReferral(referral, nil, &metrics, time.Now()))
+	terms := &vendornetapi.PartnershipTerms{ResponseTimeHours: 24}
+	assert.True(t, vendornetapi.ExpireOverdueReferral(referral, terms, &metrics, time.Now()))
+	assert.Equal(t, 1, metrics.SLAMisses)
+}
+
+func TestReferralSLA_AnsweredReferralUntouched(t *testing.T) {
+	referral := newPendingReferral(500000)
+	referral.CreatedAt = time.Now().Add(-72 * time.Hour)
+	vendornetapi.ApplyReferralPreferences(referral, vendornetapi.ReferralPrefs{}, true, time.Now())
+	metrics := vendornetapi.ReferralResponseMetrics{Responded: 1}
+
+	assert.False(t, vendornetapi.ExpireOverdueReferral(referral, nil, &metrics, time.Now()))
+	assert.Equal(t, vendornetapi.ReferralAccepted, referral.Status)
+	assert.Equal(t, 0, metrics.SLAMisses)
+	assert.Equal(t, 1.0, metrics.ResponseRate())
+}
+
+// Test Partner Blocklist
+
+func TestPartnerMatching_BlockedVendorExcluded(t *testing.T) {
+	blocked := uuid.New()
+	allowed := uuid.New()
+	prefs := vendornetapi.PartnershipPrefs{BlockedVendors: []uuid.UUID{blocked}}
+
+	candidates := vendornetapi.ExcludeBlockedCandidates([]vendornetapi.CandidateVendor{
+		{VendorID: blocked, VendorName: "Blocked Decor"},
+		{VendorID: allowed, VendorName: "Lagos Lights"},
+	}, prefs)
+
+	require.Len(t, candidates, 1)
+	assert.Equal(t, allowed, candidates[0].VendorID)
+}
+
+func TestConnectionRequest_BlockedSenderRejected(t *testing.T) {
+	sender := uuid.New()
+	recipient := uuid.New()
+	req := vendornetapi.ConnectionRequest{FromVendorID: sender, ToVendorID: recipient}
+
+	recipientPrefs := vendornetapi.PartnershipPrefs{BlockedVendors: []uuid.UUID{sender}}
+	err := vendornetapi.CheckConnectionRequest(req, vendornetapi.PartnershipPrefs{}, recipientPrefs, nil)
+	assert.True(t, errors.Is(err, vendornetapi.ErrVendorBlocked))
+
+	// A connection already marked blocked also stops the request
+	existing := &vendornetapi.Connection{VendorAID: recipient, VendorBID: sender, Status: vendornetapi.ConnectionBlocked}
+	err = vendornetapi.CheckConnectionRequest(req, vendornetapi.PartnershipPrefs{}, vendornetapi.PartnershipPrefs{}, existing)
+	assert.True(t, errors.Is(err, vendornetapi.ErrVendorBlocked))
+}
+
+func TestConnectionRequest_AllowedWhenNotBlocked(t *testing.T) {
+	req := vendornetapi.ConnectionRequest{FromVendorID: uuid.New(), ToVendorID: uuid.New()}
+	prefs := vendornetapi.PartnershipPrefs{BlockedVendors: []uuid.UUID{uuid.New()}}
+
+	assert.NoError(t, vendornetapi.CheckConnectionRequest(req, prefs, prefs, nil))
+
+	existing := &vendornetapi.Connection{Status: vendornetapi.ConnectionPending}
+	err := vendornetapi.CheckConnectionRequest(req, prefs, prefs, existing)
+	assert.True(t, errors.Is(err, vendornetapi.ErrConnectionExists))
+}
+
+// Test Multi-Touch Attribution
+
+func multiTouchJourney() (first, last vendornetapi.ReferralTouch) {
+	dest := uuid.New()
+	start := time.Now().Add(-72 * time.Hour)
+	first = vendornetapi.ReferralTouch{
+		ReferralID:     uuid.New(),
+		SourceVendorID: uuid.New(),
+		DestVendorID:   dest,
+		Channel:        "referral",
+		TouchedAt:      start,
+	}
+	last = vendornetapi.ReferralTouch{
+		ReferralID:     uuid.New(),
+		SourceVendorID: uuid.New(),
+		DestVendorID:   dest,
+		Channel:        "link",
+		TouchedAt:      start.Add(48 * time.Hour),
+	}
+	return first, last
+}
+
+func TestAttribution_FirstVersusLastTouch(t *testing.T) {
+	first, last := multiTouchJourney()
+	// Touches arrive out of order; attribution goes by time, not position
+	touches := []vendornetapi.ReferralTouch{last, first}
+
+	credits := vendornetapi.AttributeConversion(touches, vendornetapi.AttributionFirstTouch, 50000)
+	require.Len(t, credits, 1)
+	assert.Equal(t, first.SourceVendorID, credits[0].SourceVendorID)
+	assert.Equal(t, 50000.0, credits[0].Fee)
+
+	credits = vendornetapi.AttributeConversion(touches, vendornetapi.AttributionLastTouch, 50000)
+	require.Len(t, credits, 1)
+	assert.Equal(t, last.SourceVendorID, credits[0].SourceVendorID)
+	assert.Equal(t, last.ReferralID, credits[0].ReferralID)
+	assert.Equal(t, vendornetapi.AttributionLastTouch, credits[0].Model)
+}
+
+func TestAttribution_SplitSharesFee(t *testing.T) {
+	first, last := multiTouchJourney()
+	repeat := first
+	repeat.TouchedAt = last.TouchedAt.Add(time.Hour)
+
+	credits := vendornetapi.AttributeConversion([]vendornetapi.ReferralTouch{first, last, repeat}, vendornetapi.AttributionSplit, 30000)
+
+	require.Len(t, credits, 2)
+	assert.Equal(t, first.SourceVendorID, credits[0].SourceVendorID)
+	assert.InDelta(t, 2.0/3.0, credits[0].Share, 0.0001)
+	assert.InDelta(t, 20000, credits[0].Fee, 0.01)
+	assert.InDelta(t, 10000, credits[1].Fee, 0.01)
+}
+
+func TestAttribution_UnknownModelFallsBackToFirstTouch(t *testing.T) {
+	first, last := multiTouchJourney()
+
+	credits := vendornetapi.AttributeConversion([]vendornetapi.ReferralTouch{first, last}, "", 1000)
+
+	require.Len(t, credits, 1)
+	assert.Equal(t, vendornetapi.AttributionFirstTouch, credits[0].Model)
+	assert.Equal(t, first.SourceVendorID, credits[0].SourceVendorID)
+	assert.Empty(t, vendornetapi.AttributeConversion(nil, vendornetapi.AttributionSplit, 1000))
+}
+
+// Test Referral Fee Recommendations
+
+func TestReferralFee_ConversionShiftsSuggestion(t *testing.T) {
+	norms := vendornetapi.CategoryReferralNorms{MedianFeePercent: 10, AcceptanceRate: 0.8, ConversionRate: 0.25}
+
+	high := vendornetapi.RecommendReferralFee(vendornetapi.ReferralPerformance{Received: 60, Accepted: 55, Converted: 30}, norms)
+	low := vendornetapi.RecommendReferralFee(vendornetapi.ReferralPerformance{Received: 60, Accepted: 30, Converted: 3}, norms)
+
+	assert.Less(t, high.SuggestedValue, 10.0)
+	assert.Greater(t, low.SuggestedValue, 10.0)
+	assert.NotEqual(t, high.SuggestedValue, low.SuggestedValue)
+	assert.Equal(t, vendornetapi.FeePercentage, high.FeeType)
+	assert.Greater(t, high.VendorYield, high.CategoryYield)
+	assert.LessOrEqual(t, low.SuggestedValue, 15.0, "suggestion is capped at 1.5x the median")
+}
+
+func TestReferralFee_ThinHistoryStaysNearMedian(t *testing.T) {
+	norms := vendornetapi.CategoryReferralNorms{MedianFeePercent: 12, AcceptanceRate: 0.8, ConversionRate: 0.25}
+
+	fresh := vendornetapi.RecommendReferralFee(vendornetapi.ReferralPerformance{}, norms)
+	assert.Equal(t, 12.0, fresh.SuggestedValue)
+
+	oneWin := vendornetapi.RecommendReferralFee(vendornetapi.ReferralPerformance{Received: 1, Accepted: 1, Converted: 1}, norms)
+	longRecord := vendornetapi.RecommendReferralFee(vendornetapi.ReferralPerformance{Received: 50, Accepted: 50, Converted: 50}, norms)
+	assert.Less(t, oneWin.SuggestedValue, 12.0)
+	assert.Greater(t, oneWin.SuggestedValue, longRecord.SuggestedValue)
+}
+
+func TestReferralFee_NoCategoryDataUsesDefault(t *testing.T) {
+	rec := vendornetapi.RecommendReferralFee(vendornetapi.ReferralPerformance{Received: 5, Converted: 4}, vendornetapi.CategoryReferralNorms{})
+
+	assert.Equal(t, vendornetapi.DefaultReferralFeePercent, rec.SuggestedValue)
+	assert.NotEmpty(t, rec.Rationale)
+}
+
+// Test Referral Pool
+
+func newPooledReferral(category uuid.UUID) *vendornetapi.PooledReferral {
+	return &vendornetapi.PooledReferral{
+		ID:              uuid.New(),
+		SourceVendorID:  uuid.New(),
+		ServiceCategory: category,
+		City:            "Lagos",
+		State:           "Lagos",
+		ClientName:      "Ada Obi",
+		EstimatedValue:  400000,
+		FeeType:         vendornetapi.FeePercentage,
+		FeeValue:        12,
+		CreatedAt:       time.Now().Add(-time.Hour),
+		ExpiresAt:       time.Now().Add(24 * time.Hour),
+	}
+}
+
+func TestReferralPool_EligibleVendorClaims(t *testing.T) {
+	category := uuid.New()
+	pooled := newPooledReferral(category)
+	claimant := &vendornetapi.VendorProfile{
+		VendorID:            uuid.New(),
+		PrimaryCategory:     uuid.New(),
+		SecondaryCategories: []uuid.UUID{category},
+		ServiceAreas:        []vendornetapi.ServiceArea{{City: "lagos", State: "Lagos"}},
+		AcceptingReferrals:  true,
+	}
+
+	require.NoError(t, vendornetapi.CheckPoolClaim(pooled, claimant, vendornetapi.PartnershipPrefs{}, time.Now()))
+
+	referral := vendornetapi.NewReferralFromPool(pooled, claimant.VendorID, time.Now())
+	assert.Equal(t, pooled.SourceVendorID, referral.SourceVendorID)
+	assert.Equal(t, claimant.VendorID, referral.DestVendorID)
+	assert.Equal(t, vendornetapi.ReferralAccepted, referral.Status)
+	assert.Equal(t, vendornetapi.FeePercentage, referral.FeeType)
+	assert.Equal(t, 12.0, referral.FeeValue)
+
+	// Once claimed, nobody else can take it
+	pooled.ClaimedBy = &claimant.VendorID
+	other := *claimant
+	other.VendorID = uuid.New()
+	err := vendornetapi.CheckPoolClaim(pooled, &other, vendornetapi.PartnershipPrefs{}, time.Now())
+	assert.ErrorIs(t, err, vendornetapi.ErrPoolReferralClaimed)
+}
+
+func TestReferralPool_StatewideAreaCoversCity(t *testing.T) {
+	category := uuid.New()
+	claimant := &vendornetapi.VendorProfile{
+		VendorID:           uuid.New(),
+		PrimaryCategory:    category,
+		ServiceAreas:       []vendornetapi.ServiceArea{{State: "Lagos"}},
+		AcceptingReferrals: true,
+	}
+
+	assert.NoError(t, vendornetapi.CheckPoolClaim(newPooledReferral(category), claimant, vendornetapi.PartnershipPrefs{}, time.Now()))
+}
+
+func TestReferralPool_IneligibleVendorsRejected(t *testing.T) {
+	category := uuid.New()
+	pooled := newPooledReferral(category)
+	eligible := vendornetapi.VendorProfile{
+		VendorID:           uuid.New(),
+		PrimaryCategory:    category,
+		ServiceAreas:       []vendornetapi.ServiceArea{{City: "Lagos", State: "Lagos"}},
+		AcceptingReferrals: true,
+	}
+
+	wrongCategory := eligible
+	wrongCategory.PrimaryCategory = uuid.New()
+	wrongArea := eligible
+	wrongArea.ServiceAreas = []vendornetapi.ServiceArea{{City: "Abuja", State: "FCT"}}
+	notAccepting := eligible
+	notAccepting.AcceptingReferrals = false
+	poster := eligible
+	poster.VendorID = pooled.SourceVendorID
+
+	for name, claimant := range map[string]vendornetapi.VendorProfile{
+		"wrong category": wrongCategory,
+		"wrong area":     wrongArea,
+		"not accepting":  notAccepting,
+		"poster":         poster,
+	} {
+		err := vendornetapi.CheckPoolClaim(pooled, &claimant, vendornetapi.PartnershipPrefs{}, time.Now())
+		assert.ErrorIs(t, err, vendornetapi.ErrNotEligibleForPool, name)
+	}
+
+	blocked := vendornetapi.PartnershipPrefs{BlockedVendors: []uuid.UUID{eligible.VendorID}}
+	assert.ErrorIs(t, vendornetapi.CheckPoolClaim(pooled, &eligible, blocked, time.Now()), vendornetapi.ErrVendorBlocked)
+
+	assert.ErrorIs(t, vendornetapi.CheckPoolClaim(pooled, &eligible, vendornetapi.PartnershipPrefs{}, time.Now().Add(48*time.Hour)),
+		vendornetapi.ErrPoolReferralExpired)
+}
+
+// Test Partner Match Weights
+
+func TestMatchWeights_ReorderSameCandidates(t *testing.T) {
+	profile := &vendornetapi.VendorProfile{VendorID: uuid.New(), PrimaryCategory: uuid.New()}
+	complementary := []uuid.UUID{uuid.New()}
+	trusted := vendornetapi.CandidateVendor{VendorID: uuid.New(), CategoryID: uuid.New(), TrustScore: 95, Rating: 3.0, ResponseRate: 0.6, ReferralSuccess: 0.6}
+	topRated := vendornetapi.CandidateVendor{VendorID: uuid.New(), CategoryID: uuid.New(), TrustScore: 40, Rating: 5.0, ResponseRate: 0.6, ReferralSuccess: 0.6}
+
+	rank := func(weights vendornetapi.MatchWeights) []uuid.UUID {
+		var matches []vendornetapi.PartnerMatch
+		for _, c := range []vendornetapi.CandidateVendor{trusted, topRated} {
+			matches = append(matches, vendornetapi.ScoreCandidate(profile, c, complementary, weights))
+		}
+		sort.Slice(matches, func(i, j int) bool { return matches[i].MatchScore > matches[j].MatchScore })
+		return []uuid.UUID{matches[0].VendorID, matches[1].VendorID}
+	}
+
+	trustHeavy := vendornetapi.MatchWeights{Complementary: 0.1, Trust: 0.6, Performance: 0.1, Rating: 0.1, Verification: 0.1}
+	ratingHeavy := vendornetapi.MatchWeights{Complementary: 0.1, Trust: 0.1, Performance: 0.1, Rating: 0.6, Verification: 0.1}
+	require.NoError(t, trustHeavy.Validate())
+	require.NoError(t, ratingHeavy.Validate())
+
+	assert.Equal(t, []uuid.UUID{trusted.VendorID, topRated.VendorID}, rank(trustHeavy))
+	assert.Equal(t, []uuid.UUID{topRated.VendorID, trusted.VendorID}, rank(ratingHeavy))
+}
+
+func TestMatchWeights_ProfileFallsBackToDefault(t *testing.T) {
+	photography := uuid.New()
+	custom := vendornetapi.MatchWeights{Complementary: 0.5, Trust: 0.2, Performance: 0.1, Rating: 0.1, Verification: 0.1}
+	profiles := vendornetapi.WeightProfiles{photography: custom}
+
+	assert.Equal(t, custom, profiles.For(photography))
+	assert.Equal(t, vendornetapi.DefaultMatchWeights, profiles.For(uuid.New()))
+	assert.NoError(t, vendornetapi.DefaultMatchWeights.Validate())
+}
+
+func TestMatchWeights_RejectsBadSums(t *testing.T) {
+	engine := vendornetapi.NewPartnershipMatchingEngine(nil, nil)
+
+	tooMuch := vendornetapi.MatchWeights{Complementary: 0.5, Trust: 0.5, Performance: 0.2}
+	assert.ErrorIs(t, tooMuch.Validate(), vendornetapi.ErrInvalidMatchWeights)
+	negative := vendornetapi.MatchWeights{Complementary: 1.2, Trust: -0.2}
+	assert.ErrorIs(t, negative.Validate(), vendornetapi.ErrInvalidMatchWeights)
+
+	err := engine.SetWeightProfiles(vendornetapi.WeightProfiles{uuid.New(): tooMuch})
+	assert.ErrorIs(t, err, vendornetapi.ErrInvalidMatchWeights)
+	assert.NoError(t, engine.SetWeightProfiles(vendornetapi.WeightProfiles{uuid.New(): vendornetapi.DefaultMatchWeights}))
+}
+
+// Test Referral Expiry Sweep
+
+func TestExpireStaleReferrals_ExpiresPastDueOnly(t *testing.T) {
+	store := vendornetapi.NewMemoryReferralExpiryStore()
+	engine := &vendornetapi.ReferralEngine{}
+	engine.SetExpiryStore(store)
+
+	stalePending := newPendingReferral(100000)
+	stalePending.ExpiresAt = time.Now().Add(-time.Hour)
+	staleAccepted := newPendingReferral(100000)
+	staleAccepted.Status = vendornetapi.ReferralAccepted
+	staleAccepted.ExpiresAt = time.Now().Add(-time.Hour)
+	converted := newPendingReferral(100000)
+	converted.Status = vendornetapi.ReferralConverted
+	converted.ExpiresAt = time.Now().Add(-time.Hour)
+	current := newPendingReferral(100000)
+	current.ExpiresAt = time.Now().Add(24 * time.Hour)
+	for _, r := range []*vendornetapi.Referral{stalePending, staleAccepted, converted, current} {
+		store.Put(*r)
+	}
+
+	count, err := engine.ExpireStaleReferrals(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	expired, _ := store.Get(stalePending.ID)
+	assert.Equal(t, vendornetapi.ReferralExpired, expired.Status)
+	require.Len(t, expired.StatusHistory, 2)
+	last := expired.StatusHistory[1]
+	assert.Equal(t, vendornetapi.ReferralExpired, last.Status)
+	assert.Equal(t, uuid.Nil, last.ChangedBy, "expiry is recorded as a system change")
+
+	untouched, _ := store.Get(converted.ID)
+	assert.Equal(t, vendornetapi.ReferralConverted, untouched.Status)
+	assert.Len(t, untouched.StatusHistory, 1)
+	open, _ := store.Get(current.ID)
+	assert.Equal(t, vendornetapi.ReferralPending, open.Status)
+}
+
+func TestExpireStaleReferrals_SecondRunIsNoOp(t *testing.T) {
+	store := vendornetapi.NewMemoryReferralExpiryStore()
+	engine := &vendornetapi.ReferralEngine{}
+	engine.SetExpiryStore(store)
+
+	stale := newPendingReferral(50000)
+	stale.ExpiresAt = time.Now().Add(-time.Minute)
+	store.Put(*stale)
+
+	count, err := engine.ExpireStaleReferrals(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = engine.ExpireStaleReferrals(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	expired, _ := store.Get(stale.ID)
+	assert.Len(t, expired.StatusHistory, 2, "history is appended once")
+}
+
+// Test Collaborative Bid Confirmation
+
+func newTeamBid(status vendornetapi.BidStatus, members ...uuid.UUID) *vendornetapi.CollaborativeBid {
+	bid := &vendornetapi.CollaborativeBid{
+		ID:           uuid.New(),
+		LeadVendorID: uuid.New(),
+		Status:       status,
+		DeadlineAt:   time.Now().Add(72 * time.Hour),
+	}
+	for _, m := range members {
+		bid.TeamMembers = append(bid.TeamMembers, vendornetapi.BidTeamMember{VendorID: m, Role: "partner"})
+		bid.SplitAgreement = append(bid.SplitAgreement, vendornetapi.RevenueSplit{VendorID: m, Percentage: 100 / float64(len(members))})
+	}
+	return bid
+}
+
+func TestConfirmBidMember_PartialConfirmationStaysPending(t *testing.T) {
+	caterer, decorator := uuid.New(), uuid.New()
+	bid := newTeamBid(vendornetapi.BidPending, caterer, decorator)
+
+	submitted, err := vendornetapi.ConfirmBidMember(bid, caterer, time.Now())
+	require.NoError(t, err)
+	assert.False(t, submitted)
+	assert.Equal(t, vendornetapi.BidPending, bid.Status)
+	assert.True(t, bid.TeamMembers[0].Confirmed)
+	assert.NotNil(t, bid.TeamMembers[0].ConfirmedAt)
+	assert.False(t, bid.TeamMembers[1].Confirmed)
+	assert.Nil(t, bid.SubmittedAt)
+}
+
+func TestConfirmBidMember_FullTeamSubmitsPendingBid(t *testing.T) {
+	caterer, decorator := uuid.New(), uuid.New()
+	bid := newTeamBid(vendornetapi.BidPending, caterer, decorator)
+	now := time.Now()
+
+	_, err := vendornetapi.ConfirmBidMember(bid, caterer, now)
+	require.NoError(t, err)
+	submitted, err := vendornetapi.ConfirmBidMember(bid, decorator, now)
+	require.NoError(t, err)
+
+	assert.True(t, submitted)
+	assert.Equal(t, vendornetapi.BidSubmitted, bid.Status)
+	require.NotNil(t, bid.SubmittedAt)
+	assert.Equal(t, now, *bid.SubmittedAt)
+}
+
+func TestConfirmBidMember_DraftBidIsNotSubmitted(t *testing.T) {
+	caterer := uuid.New()
+	bid := newTeamBid(vendornetapi.BidDraft, caterer)
+
+	submitted, err := vendornetapi.ConfirmBidMember(bid, caterer, time.Now())
+	require.NoError(t, err)
+	assert.False(t, submitted)
+	assert.Equal(t, vendornetapi.BidDraft, bid.Status)
+	assert.True(t, vendornetapi.BidTeamConfirmed(bid))
+}
+
+func TestConfirmBidMember_Rejections(t *testing.T) {
+	caterer := uuid.New()
+
+	late := newTeamBid(vendornetapi.BidPending, caterer)
+	_, err := vendornetapi.ConfirmBidMember(late, caterer, late.DeadlineAt.Add(time.Minute))
+	assert.ErrorIs(t, err, vendornetapi.ErrBidDeadlinePassed)
+	assert.False(t, late.TeamMembers[0].Confirmed)
+
+	_, err = vendornetapi.ConfirmBidMember(newTeamBid(vendornetapi.BidPending, caterer), uuid.New(), time.Now())
+	assert.ErrorIs(t, err, vendornetapi.ErrNotBidMember)
+
+	_, err = vendornetapi.ConfirmBidMember(newTeamBid(vendornetapi.BidWithdrawn, caterer), caterer, time.Now())
+	assert.ErrorIs(t, err, vendornetapi.ErrBidNotAwaitingTeam)
+}
+
+// Test Revenue Split Validation
+
+func TestValidateSplit_PercentagesOnly(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	bid := &vendornetapi.CollaborativeBid{TotalBidAmount: 900000, SplitAgreement: []vendornetapi.RevenueSplit{
+		{VendorID: a, Percentage: 33.33},
+		{VendorID: b, Percentage: 33.33},
+		{VendorID: c, Percentage: 33.34},
+	}}
+	assert.NoError(t, bid.ValidateSplit())
+
+	bid.SplitAgreement[2].Percentage = 28.34
+	err := bid.ValidateSplit()
+	assert.ErrorIs(t, err, vendornetapi.ErrInvalidSplit)
+	assert.Contains(t, err.Error(), "5% short")
+
+	bid.SplitAgreement[2].Percentage = 40
+	err = bid.ValidateSplit()
+	assert.ErrorIs(t, err, vendornetapi.ErrInvalidSplit)
+	assert.Contains(t, err.Error(), "over 100%")
+}
+
+func TestValidateSplit_FixedPlusPercentageOfRemainder(t *testing.T) {
+	lead, caterer, decorator := uuid.New(), uuid.New(), uuid.New()
+	bid := &vendornetapi.CollaborativeBid{TotalBidAmount: 1000000, SplitAgreement: []vendornetapi.RevenueSplit{
+		{VendorID: lead, FixedAmount: 200000},
+		{VendorID: caterer, Percentage: 60},
+		{VendorID: decorator, Percentage: 40},
+	}}
+	assert.NoError(t, bid.ValidateSplit())
+
+	bid.SplitAgreement[2].Percentage = 30
+	err := bid.ValidateSplit()
+	assert.ErrorIs(t, err, vendornetapi.ErrInvalidSplit)
+	assert.Contains(t, err.Error(), "leave 80000.00")
+
+	allFixed := &vendornetapi.CollaborativeBid{TotalBidAmount: 500000, SplitAgreement: []vendornetapi.RevenueSplit{
+		{VendorID: lead, FixedAmount: 300000},
+		{VendorID: caterer, FixedAmount: 200000},
+	}}
+	assert.NoError(t, allFixed.ValidateSplit())
+
+	allFixed.SplitAgreement[1].FixedAmount = 250000
+	err = allFixed.ValidateSplit()
+	assert.ErrorIs(t, err, vendornetapi.ErrInvalidSplit)
+	assert.Contains(t, err.Error(), "by 50000.00")
+}
+
+func TestConfirmBidMember_UnreconciledSplitBlocksSubmission(t *testing.T) {
+	caterer, decorator := uuid.New(), uuid.New()
+	bid := newTeamBid(vendornetapi.BidPending, caterer, decorator)
+	bid.SplitAgreement[1].Percentage = 20
+
+	_, err := vendornetapi.ConfirmBidMember(bid, caterer, time.Now())
+	require.NoError(t, err, "a partial team does not need a final split yet")
+
+	submitted, err := vendornetapi.ConfirmBidMember(bid, decorator, time.Now())
+	assert.ErrorIs(t, err, vendornetapi.ErrInvalidSplit)
+	assert.False(t, submitted)
+	assert.False(t, bid.TeamMembers[1].Confirmed)
+	assert.Equal(t, vendornetapi.BidPending, bid.Status)
+}
+
+// Test Partner Match Endpoint
+
+type stubPartnerMatcher struct {
+	matches  []vendornetapi.PartnerMatch
+	err      error
+	vendorID uuid.UUID
+	limit    int
+}
+
+func (s *stubPartnerMatcher) FindPartnerMatches(ctx context.Context, vendorID uuid.UUID, limit int) ([]vendornetapi.PartnerMatch, error) {
+	s.vendorID, s.limit = vendorID, limit
+	return s.matches, s.err
+}
+
+func servePartnerMatches(matcher *stubPartnerMatcher, path string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	vendornetapi.NewHandler(nil, matcher, zap.NewNop()).RegisterRoutes(router.Group("/api/v1"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestPartnerMatchEndpoint_ReturnsMatches(t *testing.T) {
+	vendorID, partnerID := uuid.New(), uuid.New()
+	matcher := &stubPartnerMatcher{matches: []vendornetapi.PartnerMatch{{
+		VendorID:       partnerID,
+		VendorName:     "Lagos Lights",
+		MatchScore:     0.82,
+		PotentialValue: 450000,
+		MatchReasons:   []vendornetapi.MatchReason{{Type: "verified", Description: "Verified business identity", Score: 1}},
+	}}}
+
+	w := servePartnerMatches(matcher, "/api/v1/vendornet/vendors/"+vendorID.String()+"/matches?limit=5")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, vendorID, matcher.vendorID)
+	assert.Equal(t, 5, matcher.limit)
+
+	var body struct {
+		Data struct {
+			Matches []vendornetapi.PartnerMatch `json:"matches"`
+			Count   int                         `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, 1, body.Data.Count)
+	match := body.Data.Matches[0]
+	assert.Equal(t, partnerID, match.VendorID)
+	assert.Equal(t, 450000.0, match.PotentialValue)
+	require.Len(t, match.MatchReasons, 1)
+	assert.Equal(t, "verified", match.MatchReasons[0].Type)
+}
+
+func TestPartnerMatchEndpoint_DefaultLimitAndEmptyList(t *testing.T) {
+	matcher := &stubPartnerMatcher{}
+
+	w := servePartnerMatches(matcher, "/api/v1/vendornet/vendors/"+uuid.New().String()+"/matches")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 10, matcher.limit)
+	assert.Contains(t, w.Body.String(), `"matches":[]`)
+}
+
+func TestPartnerMatchEndpoint_Errors(t *testing.T) {
+	vendorPath := "/api/v1/vendornet/vendors/" + uuid.New().String() + "/matches"
+
+	assert.Equal(t, http.StatusBadRequest, servePartnerMatches(&stubPartnerMatcher{}, "/api/v1/vendornet/vendors/not-a-uuid/matches").Code)
+	assert.Equal(t, http.StatusBadRequest, servePartnerMatches(&stubPartnerMatcher{}, vendorPath+"?limit=0").Code)
+	assert.Equal(t, http.StatusBadRequest, servePartnerMatches(&stubPartnerMatcher{}, vendorPath+"?limit=abc").Code)
+	assert.Equal(t, http.StatusBadRequest, servePartnerMatches(&stubPartnerMatcher{}, vendorPath+"?limit=500").Code)
+
+	assert.Equal(t, http.StatusNotFound, servePartnerMatches(&stubPartnerMatcher{err: vendornetapi.ErrNoNetworkProfile}, vendorPath).Code)
+	assert.Equal(t, http.StatusInternalServerError, servePartnerMatches(&stubPartnerMatcher{err: errors.New("db down")}, vendorPath).Code)
+}